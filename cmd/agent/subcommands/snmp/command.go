@@ -40,6 +40,7 @@ import (
 const (
 	defaultTimeout                 = 10 // Timeout better suited to walking
 	defaultRetries                 = 3
+	defaultPort                    = 161
 	defaultUseUnconnectedUDPSocket = false
 )
 
@@ -114,28 +115,7 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 			return nil
 		},
 	}
-	snmpWalkCmd.Flags().VarP(Flag(&snmpparse.VersionOpts, &connParams.Version), "snmp-version", "v",
-		fmt.Sprintf("Specify SNMP version to use (%s)", snmpparse.VersionOpts.OptsStr()))
-
-	// snmp v1 or v2c specific
-	snmpWalkCmd.Flags().StringVarP(&connParams.CommunityString, "community-string", "C", "", "Set the community string")
-
-	// snmp v3 specific
-	snmpWalkCmd.Flags().VarP(Flag(&snmpparse.AuthOpts, &connParams.AuthProtocol), "auth-protocol", "a",
-		fmt.Sprintf("Set authentication protocol (%s)", snmpparse.AuthOpts.OptsStr()))
-	snmpWalkCmd.Flags().StringVarP(&connParams.AuthKey, "auth-key", "A", "", "Set authentication protocol pass phrase")
-	snmpWalkCmd.Flags().VarP(Flag(&snmpparse.LevelOpts, &connParams.SecurityLevel), "security-level", "l",
-		fmt.Sprintf("Set security level (%s)", snmpparse.LevelOpts.OptsStr()))
-	snmpWalkCmd.Flags().StringVarP(&connParams.Context, "context", "N", "", "Set context name")
-	snmpWalkCmd.Flags().StringVarP(&connParams.Username, "user-name", "u", "", "Set security name")
-	snmpWalkCmd.Flags().VarP(Flag(&snmpparse.PrivOpts, &connParams.PrivProtocol), "priv-protocol", "x",
-		fmt.Sprintf("Set privacy protocol (%s)", snmpparse.PrivOpts.OptsStr()))
-	snmpWalkCmd.Flags().StringVarP(&connParams.PrivKey, "priv-key", "X", "", "Set privacy protocol pass phrase")
-
-	// general communication options
-	snmpWalkCmd.Flags().IntVarP(&connParams.Retries, "retries", "r", defaultRetries, "Set the number of retries")
-	snmpWalkCmd.Flags().IntVarP(&connParams.Timeout, "timeout", "t", defaultTimeout, "Set the request timeout (in seconds)")
-	snmpWalkCmd.Flags().BoolVar(&connParams.UseUnconnectedUDPSocket, "use-unconnected-udp-socket", defaultUseUnconnectedUDPSocket, "If specified, changes net connection to be unconnected UDP socket")
+	registerConnectionFlags(snmpWalkCmd, connParams)
 
 	snmpCmd.AddCommand(snmpWalkCmd)
 
@@ -181,34 +161,40 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 	}
 
 	logLevelDefaultOff.Register(snmpScanCmd)
-	// TODO is there a way to merge these flags with snmpWalkCmd flags, without cobra changing the docs to mark them as "global flags"?
-	snmpScanCmd.Flags().VarP(Flag(&snmpparse.VersionOpts, &connParams.Version), "snmp-version", "v",
+	registerConnectionFlags(snmpScanCmd, connParams)
+
+	// This command does nothing until the backend supports it, so it isn't enabled yet.
+	snmpCmd.AddCommand(snmpScanCmd)
+
+	snmpCmd.AddCommand(profileCommand(globalParams, connParams))
+
+	return []*cobra.Command{snmpCmd}
+}
+
+// registerConnectionFlags registers the flags used to connect to a device.
+func registerConnectionFlags(cmd *cobra.Command, connParams *snmpparse.SNMPConfig) {
+	cmd.Flags().VarP(Flag(&snmpparse.VersionOpts, &connParams.Version), "snmp-version", "v",
 		fmt.Sprintf("Specify SNMP version to use (%s)", snmpparse.VersionOpts.OptsStr()))
 
 	// snmp v1 or v2c specific
-	snmpScanCmd.Flags().StringVarP(&connParams.CommunityString, "community-string", "C", "", "Set the community string")
+	cmd.Flags().StringVarP(&connParams.CommunityString, "community-string", "C", "", "Set the community string")
 
 	// snmp v3 specific
-	snmpScanCmd.Flags().VarP(Flag(&snmpparse.AuthOpts, &connParams.AuthProtocol), "auth-protocol", "a",
+	cmd.Flags().VarP(Flag(&snmpparse.AuthOpts, &connParams.AuthProtocol), "auth-protocol", "a",
 		fmt.Sprintf("Set authentication protocol (%s)", snmpparse.AuthOpts.OptsStr()))
-	snmpScanCmd.Flags().StringVarP(&connParams.AuthKey, "auth-key", "A", "", "Set authentication protocol pass phrase")
-	snmpScanCmd.Flags().VarP(Flag(&snmpparse.LevelOpts, &connParams.SecurityLevel), "security-level", "l",
+	cmd.Flags().StringVarP(&connParams.AuthKey, "auth-key", "A", "", "Set authentication protocol pass phrase")
+	cmd.Flags().VarP(Flag(&snmpparse.LevelOpts, &connParams.SecurityLevel), "security-level", "l",
 		fmt.Sprintf("Set security level (%s)", snmpparse.LevelOpts.OptsStr()))
-	snmpScanCmd.Flags().StringVarP(&connParams.Context, "context", "N", "", "Set context name")
-	snmpScanCmd.Flags().StringVarP(&connParams.Username, "user-name", "u", "", "Set security name")
-	snmpScanCmd.Flags().VarP(Flag(&snmpparse.PrivOpts, &connParams.PrivProtocol), "priv-protocol", "x",
+	cmd.Flags().StringVarP(&connParams.Context, "context", "N", "", "Set context name")
+	cmd.Flags().StringVarP(&connParams.Username, "user-name", "u", "", "Set security name")
+	cmd.Flags().VarP(Flag(&snmpparse.PrivOpts, &connParams.PrivProtocol), "priv-protocol", "x",
 		fmt.Sprintf("Set privacy protocol (%s)", snmpparse.PrivOpts.OptsStr()))
-	snmpScanCmd.Flags().StringVarP(&connParams.PrivKey, "priv-key", "X", "", "Set privacy protocol pass phrase")
+	cmd.Flags().StringVarP(&connParams.PrivKey, "priv-key", "X", "", "Set privacy protocol pass phrase")
 
 	// general communication options
-	snmpScanCmd.Flags().IntVarP(&connParams.Retries, "retries", "r", defaultRetries, "Set the number of retries")
-	snmpScanCmd.Flags().IntVarP(&connParams.Timeout, "timeout", "t", defaultTimeout, "Set the request timeout (in seconds)")
-	snmpScanCmd.Flags().BoolVar(&connParams.UseUnconnectedUDPSocket, "use-unconnected-udp-socket", defaultUseUnconnectedUDPSocket, "If specified, changes net connection to be unconnected UDP socket")
-
-	// This command does nothing until the backend supports it, so it isn't enabled yet.
-	snmpCmd.AddCommand(snmpScanCmd)
-
-	return []*cobra.Command{snmpCmd}
+	cmd.Flags().IntVarP(&connParams.Retries, "retries", "r", defaultRetries, "Set the number of retries")
+	cmd.Flags().IntVarP(&connParams.Timeout, "timeout", "t", defaultTimeout, "Set the request timeout (in seconds)")
+	cmd.Flags().BoolVar(&connParams.UseUnconnectedUDPSocket, "use-unconnected-udp-socket", defaultUseUnconnectedUDPSocket, "If specified, changes net connection to be unconnected UDP socket")
 }
 
 // maybeSplitIP splits an address into a host and port if possible.
@@ -62,6 +62,29 @@ func TestScanCommand(t *testing.T) {
 		})
 }
 
+func TestProfileTestCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "profile", "test", "my-device.yaml", "--walk-file", "my-device.snmprec", "--json"},
+		profileTest,
+		func(cliParams *snmpparse.SNMPConfig, testParams *profileTestParams, args argsType) {
+			require.Equal(t, argsType{"my-device.yaml"}, args)
+			require.Equal(t, "my-device.snmprec", testParams.walkFile)
+			require.True(t, testParams.jsonOutput)
+		})
+
+	fxutil.TestOneShotSubcommand(t,
+		Commands(&command.GlobalParams{}),
+		[]string{"snmp", "profile", "test", "my-device.yaml", "1.2.3.4", "-v", "2c", "-C", "public"},
+		profileTest,
+		func(cliParams *snmpparse.SNMPConfig, testParams *profileTestParams, args argsType) {
+			require.Equal(t, argsType{"my-device.yaml", "1.2.3.4"}, args)
+			require.Equal(t, "2c", cliParams.Version)
+			require.Equal(t, "public", cliParams.CommunityString)
+			require.Empty(t, testParams.walkFile)
+		})
+}
+
 func TestSplitIP(t *testing.T) {
 	for _, tc := range []struct {
 		addr    string
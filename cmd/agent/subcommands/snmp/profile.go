@@ -0,0 +1,191 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package snmp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/command"
+	"github.com/DataDog/datadog-agent/comp/core"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/profiletest"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmpparse"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+// profileTestParams holds the flags of `snmp profile test`.
+type profileTestParams struct {
+	walkFile   string
+	jsonOutput bool
+}
+
+// profileCommand returns the 'snmp profile' command.
+func profileCommand(globalParams *command.GlobalParams, connParams *snmpparse.SNMPConfig) *cobra.Command {
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "SNMP profile development tools",
+		Long:  ``,
+	}
+
+	testParams := &profileTestParams{}
+	logLevelDefaultOff := command.LogLevelDefaultOff{}
+	profileTestCmd := &cobra.Command{
+		Use:   "test <profile.yaml> [<IP Address>[:Port]]",
+		Short: "Test a profile against a device or a recorded walk.",
+		Long: `Collect a device with the given profile, and print the metrics, tags and device metadata the SNMP check would submit.
+		OIDs of the profile for which the device returned nothing, and table rows whose index_transform doesn't match, are reported.
+		The device is either a live device, or a recorded walk (snmpwalk -On output or .snmprec file) given with --walk-file.
+		Flags that aren't specified will be pulled from the agent SNMP config if possible.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := fxutil.OneShot(profileTest,
+				fx.Supply(connParams, testParams),
+				fx.Provide(func() argsType { return args }),
+				fx.Supply(core.BundleParams{
+					ConfigParams: config.NewAgentParams(globalParams.ConfFilePath, config.WithExtraConfFiles(globalParams.ExtraConfFilePath), config.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+					SecretParams: secrets.NewEnabledParams(),
+					LogParams:    log.ForOneShot(command.LoggerName, logLevelDefaultOff.Value(), true)}),
+				core.Bundle(),
+			)
+			if err != nil {
+				var ue configErr
+				if errors.As(err, &ue) {
+					fmt.Println("Usage:", cmd.UseLine())
+				}
+				return err
+			}
+			return nil
+		},
+	}
+	logLevelDefaultOff.Register(profileTestCmd)
+	registerConnectionFlags(profileTestCmd, connParams)
+	profileTestCmd.Flags().StringVar(&testParams.walkFile, "walk-file", "", "Serve the device from a recorded walk instead of querying it")
+	profileTestCmd.Flags().BoolVar(&testParams.jsonOutput, "json", false, "Print the result as JSON")
+
+	profileCmd.AddCommand(profileTestCmd)
+	return profileCmd
+}
+
+// profileTest runs a profile against a device or a recorded walk.
+func profileTest(connParams *snmpparse.SNMPConfig, testParams *profileTestParams, args argsType, conf config.Component) error {
+	if len(args) == 0 {
+		return confErrf("missing argument: profile")
+	}
+	testConfig := profiletest.Config{
+		ProfilePath: args[0],
+		WalkFile:    testParams.walkFile,
+		Namespace:   conf.GetString("network_devices.namespace"),
+	}
+	switch {
+	case testParams.walkFile != "" && len(args) > 1:
+		return confErrf("a device IP address and --walk-file cannot be used together")
+	case testParams.walkFile == "" && len(args) == 1:
+		return confErrf("missing argument: either a device IP address or --walk-file is required")
+	case len(args) > 2:
+		return confErrf("the number of arguments must be between 1 and 2. %d arguments were given.", len(args))
+	}
+	if len(args) == 2 {
+		connParams.IPAddress, connParams.Port, _ = maybeSplitIP(args[1])
+		agentErr := setDefaultsFromAgent(connParams, conf)
+		if agentErr != nil {
+			// Warn that we couldn't contact the agent, but keep going in case the
+			// user provided enough arguments to do this anyway.
+			_, _ = fmt.Fprintf(os.Stderr, "Warning: %v\n", agentErr)
+		}
+		if connParams.Port == 0 {
+			connParams.Port = defaultPort
+		}
+		testConfig.Device = connParams
+	}
+
+	result, err := profiletest.Run(testConfig)
+	if err != nil {
+		return fmt.Errorf("unable to test profile %s: %w", args[0], err)
+	}
+	if testParams.jsonOutput {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	printProfileTestResult(os.Stdout, result)
+	return nil
+}
+
+// printProfileTestResult prints a profile test result in a human-readable way.
+func printProfileTestResult(w io.Writer, result *profiletest.Result) {
+	fmt.Fprintf(w, "Profile: %s\n", result.Profile)
+	if result.SysObjectID != "" {
+		fmt.Fprintf(w, "sysObjectID: %s\n", result.SysObjectID)
+	}
+
+	fmt.Fprintf(w, "\nTags (%d):\n", len(result.Tags))
+	for _, tag := range result.Tags {
+		fmt.Fprintf(w, "  %s\n", tag)
+	}
+
+	fmt.Fprintf(w, "\nMetrics (%d):\n", len(result.Metrics))
+	for _, metric := range result.Metrics {
+		fmt.Fprintf(w, "  %s (%s) = %v [%s]\n", metric.Name, metric.Type, metric.Value, strings.Join(metric.Tags, ", "))
+	}
+
+	for _, payload := range result.Metadata {
+		for _, device := range payload.Devices {
+			fmt.Fprintf(w, "\nDevice metadata:\n")
+			data, _ := json.MarshalIndent(device, "  ", "  ")
+			fmt.Fprintf(w, "  %s\n", data)
+		}
+		if len(payload.Interfaces) > 0 {
+			fmt.Fprintf(w, "\nInterfaces (%d):\n", len(payload.Interfaces))
+			for _, itf := range payload.Interfaces {
+				fmt.Fprintf(w, "  %d: name=%q alias=%q mac=%q admin_status=%s oper_status=%s\n",
+					itf.Index, itf.Name, itf.Alias, itf.MacAddress, itf.AdminStatus.AsString(), itf.OperStatus.AsString())
+			}
+		}
+		if len(payload.IPAddresses) > 0 {
+			fmt.Fprintf(w, "\nIP addresses (%d):\n", len(payload.IPAddresses))
+			for _, ip := range payload.IPAddresses {
+				fmt.Fprintf(w, "  %s/%d (interface %s)\n", ip.IPAddress, ip.Prefixlen, ip.InterfaceID)
+			}
+		}
+		if len(payload.Links) > 0 {
+			fmt.Fprintf(w, "\nTopology links (%d):\n", len(payload.Links))
+			for _, link := range payload.Links {
+				fmt.Fprintf(w, "  %s (%s)\n", link.ID, link.SourceType)
+			}
+		}
+	}
+
+	if len(result.MissingOIDs) > 0 {
+		fmt.Fprintf(w, "\nOIDs without values (%d):\n", len(result.MissingOIDs))
+		for _, missing := range result.MissingOIDs {
+			kind := "scalar"
+			if missing.Column {
+				kind = "column"
+			}
+			fmt.Fprintf(w, "  %s %s (%s)\n", missing.OID, kind, strings.Join(missing.Names, ", "))
+		}
+	}
+
+	if len(result.IndexTransformFailures) > 0 {
+		fmt.Fprintf(w, "\nFailed index transforms (%d):\n", len(result.IndexTransformFailures))
+		for _, failure := range result.IndexTransformFailures {
+			fmt.Fprintf(w, "  table %s, tag %s: no value for index %s (transformed to %q)\n",
+				failure.Table, failure.Tag, failure.Index, failure.TransformedIndex)
+		}
+	}
+}
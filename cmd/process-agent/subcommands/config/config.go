@@ -8,6 +8,7 @@ package config
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/fx"
@@ -102,6 +103,21 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 		},
 	)
 
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "explain [setting]",
+			Short: "Show every source defining a value for a given configuration setting, and the one in effect",
+			Long:  ``,
+			RunE: func(_ *cobra.Command, args []string) error {
+				return fxutil.OneShot(explainConfigValue,
+					fx.Supply(globalParams, args, command.GetCoreBundleParamsForOneShot(globalParams)),
+					core.Bundle(),
+					process.Bundle(),
+				)
+			},
+		},
+	)
+
 	return []*cobra.Command{cmd}
 }
 
@@ -180,6 +196,26 @@ func getConfigValue(deps dependencies, args []string) error {
 	return nil
 }
 
+func explainConfigValue(deps dependencies, args []string) error {
+	c, err := getClient(deps.Config)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return fmt.Errorf("a single setting name must be specified")
+	}
+
+	explanation, err := c.Explain(args[0])
+	if err != nil {
+		return err
+	}
+
+	settings.PrintExplanation(os.Stdout, explanation)
+
+	return nil
+}
+
 func getClient(cfg model.Reader) (settings.Client, error) {
 	err := util.SetAuthToken(cfg)
 	if err != nil {
@@ -0,0 +1,143 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+)
+
+// AWSClient reads secrets from AWS Secrets Manager and SSM Parameter Store,
+// with the credentials and the region of the default AWS configuration.
+type AWSClient struct {
+	// Config is loaded from the environment, the shared configuration files
+	// and the instance metadata on the first request when nil
+	Config     *aws.Config
+	HTTPClient *http.Client
+
+	configErr error
+}
+
+// NewAWSClient returns an AWSClient using the default AWS configuration
+func NewAWSClient() *AWSClient {
+	return &AWSClient{HTTPClient: newHTTPClient()}
+}
+
+// ReadAWSSecret reads a secret from AWS Secrets Manager. The id is the name or
+// the ARN of the secret, optionally followed by a key when the secret is a
+// JSON object, like "prod/db#password".
+func ReadAWSSecret(client *AWSClient, id string) secrets.SecretVal {
+	secretID, key, hasKey := strings.Cut(id, "#")
+	if secretID == "" || (hasKey && key == "") {
+		return secrets.SecretVal{ErrorMsg: "invalid format. Use: \"secret-id\" or \"secret-id#key\""}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+	defer cancel()
+
+	cfg, region, err := client.resolve(ctx, secretID)
+	if err != nil {
+		return secrets.SecretVal{ErrorMsg: err.Error()}
+	}
+	resp, err := secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+		o.Region = region
+		o.HTTPClient = client.HTTPClient
+	}).GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretID)})
+	if err != nil {
+		return secrets.SecretVal{ErrorMsg: err.Error()}
+	}
+
+	value := string(resp.SecretBinary)
+	if resp.SecretString != nil {
+		value = *resp.SecretString
+	}
+	if !hasKey {
+		return secrets.SecretVal{Value: value}
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return secrets.SecretVal{ErrorMsg: fmt.Sprintf("secret %s is not a JSON object: %s", secretID, err)}
+	}
+	field, found := fields[key]
+	if !found {
+		return secrets.SecretVal{ErrorMsg: fmt.Sprintf("key %s not found in secret %s", key, secretID)}
+	}
+	if s, ok := field.(string); ok {
+		return secrets.SecretVal{Value: s}
+	}
+	encoded, _ := json.Marshal(field)
+	return secrets.SecretVal{Value: string(encoded)}
+}
+
+// ReadAWSParameter reads a parameter from AWS SSM Parameter Store, decrypting
+// it if it is a SecureString. The name is the name or the ARN of the parameter.
+func ReadAWSParameter(client *AWSClient, name string) secrets.SecretVal {
+	if name == "" {
+		return secrets.SecretVal{ErrorMsg: "invalid format. Use: \"/parameter/name\""}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+	defer cancel()
+
+	cfg, region, err := client.resolve(ctx, name)
+	if err != nil {
+		return secrets.SecretVal{ErrorMsg: err.Error()}
+	}
+	resp, err := ssm.NewFromConfig(cfg, func(o *ssm.Options) {
+		o.Region = region
+		o.HTTPClient = client.HTTPClient
+	}).GetParameter(ctx, &ssm.GetParameterInput{Name: aws.String(name), WithDecryption: aws.Bool(true)})
+	if err != nil {
+		return secrets.SecretVal{ErrorMsg: err.Error()}
+	}
+	return secrets.SecretVal{Value: aws.ToString(resp.Parameter.Value)}
+}
+
+func (c *AWSClient) loadConfig(ctx context.Context) (aws.Config, error) {
+	if c.Config == nil && c.configErr == nil {
+		cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithHTTPClient(c.HTTPClient))
+		if err != nil {
+			c.configErr = fmt.Errorf("failed to load AWS configuration: %w", err)
+		} else {
+			c.Config = &cfg
+		}
+	}
+	if c.configErr != nil {
+		return aws.Config{}, c.configErr
+	}
+	return *c.Config, nil
+}
+
+// resolve returns the AWS configuration and the region of a resource, which
+// is the region of its ARN if any, the configured region otherwise.
+func (c *AWSClient) resolve(ctx context.Context, resource string) (aws.Config, string, error) {
+	cfg, err := c.loadConfig(ctx)
+	if err != nil {
+		return aws.Config{}, "", err
+	}
+
+	region := cfg.Region
+	if resourceARN, err := arn.Parse(resource); err == nil && resourceARN.Region != "" {
+		region = resourceARN.Region
+	}
+	if region == "" {
+		return aws.Config{}, "", errors.New("no AWS region configured")
+	}
+	return cfg, region, nil
+}
@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package providers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+// newAWSServer returns a stand-in for the Secrets Manager and SSM APIs
+func newAWSServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		var input map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "secretsmanager.GetSecretValue":
+			switch input["SecretId"] {
+			case "some_name":
+				w.Write([]byte(`{"SecretString": "{\"username\": \"some_user\", \"port\": 5432}"}`))
+			case "some_binary":
+				w.Write([]byte(`{"SecretBinary": "c29tZV92YWx1ZQ=="}`))
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"__type": "ResourceNotFoundException"}`))
+			}
+		case "AmazonSSM.GetParameter":
+			if input["Name"] != "/some/parameter" || input["WithDecryption"] != true {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"__type": "ParameterNotFound"}`))
+				return
+			}
+			w.Write([]byte(`{"Parameter": {"Value": "some_value"}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestAWSClient(server *httptest.Server) *AWSClient {
+	return &AWSClient{
+		Config: &aws.Config{
+			Region:       "us-east-1",
+			Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+			BaseEndpoint: aws.String(server.URL),
+		},
+		HTTPClient: server.Client(),
+	}
+}
+
+func TestReadAWSSecret(t *testing.T) {
+	server := newAWSServer(t)
+	client := newTestAWSClient(server)
+
+	tests := []struct {
+		name          string
+		secretID      string
+		expectedValue string
+		expectedError string
+	}{
+		{
+			name:          "invalid format",
+			secretID:      "some_name#",
+			expectedError: "invalid format. Use: \"secret-id\" or \"secret-id#key\"",
+		},
+		{
+			name:          "whole secret",
+			secretID:      "some_name",
+			expectedValue: `{"username": "some_user", "port": 5432}`,
+		},
+		{
+			name:          "key of a secret",
+			secretID:      "some_name#username",
+			expectedValue: "some_user",
+		},
+		{
+			name:          "non-string key of a secret",
+			secretID:      "some_name#port",
+			expectedValue: "5432",
+		},
+		{
+			name:          "key does not exist",
+			secretID:      "some_name#password",
+			expectedError: "key password not found in secret some_name",
+		},
+		{
+			name:          "binary secret",
+			secretID:      "some_binary",
+			expectedValue: "some_value",
+		},
+		{
+			name:          "secret does not exist",
+			secretID:      "another_name",
+			expectedError: "ResourceNotFoundException",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			secret := ReadAWSSecret(client, test.secretID)
+			assert.Equal(t, test.expectedValue, secret.Value)
+			if test.expectedError == "" {
+				assert.Empty(t, secret.ErrorMsg)
+			} else {
+				assert.Contains(t, secret.ErrorMsg, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestReadAWSParameter(t *testing.T) {
+	server := newAWSServer(t)
+	client := newTestAWSClient(server)
+
+	secret := ReadAWSParameter(client, "/some/parameter")
+	assert.Equal(t, "some_value", secret.Value)
+	assert.Empty(t, secret.ErrorMsg)
+
+	secret = ReadAWSParameter(client, "/another/parameter")
+	assert.Empty(t, secret.Value)
+	assert.Contains(t, secret.ErrorMsg, "ParameterNotFound")
+
+	client.Config.Region = ""
+	secret = ReadAWSParameter(client, "/some/parameter")
+	assert.Equal(t, "no AWS region configured", secret.ErrorMsg)
+
+	secret = ReadAWSParameter(client, "arn:aws:ssm:eu-west-1:123456789012:parameter/some/parameter")
+	assert.Contains(t, secret.ErrorMsg, "ParameterNotFound", "the region of the ARN is used")
+}
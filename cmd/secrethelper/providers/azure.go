@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package providers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+)
+
+const (
+	azureKeyVaultAPIVersion = "7.4"
+	azureKeyVaultScope      = "https://vault.azure.net"
+	azureAuthorityHost      = "https://login.microsoftonline.com"
+	azureIMDSTokenEndpoint  = "http://169.254.169.254/metadata/identity/oauth2/token"
+)
+
+// AzureKeyVaultClient reads secrets from Azure Key Vault. It authenticates with
+// the client credentials of a service principal when they are set, and with
+// the managed identity of the host otherwise.
+type AzureKeyVaultClient struct {
+	// TenantID, ClientID and ClientSecret are the credentials of the service
+	// principal. ClientID alone selects a user-assigned managed identity.
+	TenantID     string
+	ClientID     string
+	ClientSecret string
+
+	// VaultURL returns the URL of a vault from its name, it defaults to
+	// https://<name>.vault.azure.net
+	VaultURL func(name string) string
+	// AuthorityHost and IMDSEndpoint are used to get tokens, they default to
+	// the public cloud ones
+	AuthorityHost string
+	IMDSEndpoint  string
+
+	HTTPClient *http.Client
+
+	token string
+}
+
+// NewAzureKeyVaultClientFromEnv returns an AzureKeyVaultClient configured with
+// the same environment variables as the Azure SDKs: AZURE_TENANT_ID,
+// AZURE_CLIENT_ID, AZURE_CLIENT_SECRET and AZURE_AUTHORITY_HOST.
+func NewAzureKeyVaultClientFromEnv() *AzureKeyVaultClient {
+	return &AzureKeyVaultClient{
+		TenantID:      os.Getenv("AZURE_TENANT_ID"),
+		ClientID:      os.Getenv("AZURE_CLIENT_ID"),
+		ClientSecret:  os.Getenv("AZURE_CLIENT_SECRET"),
+		AuthorityHost: os.Getenv("AZURE_AUTHORITY_HOST"),
+		HTTPClient:    newHTTPClient(),
+	}
+}
+
+// ReadAzureKeyVaultSecret reads a secret from Azure Key Vault. The path is the
+// name of the vault followed by the name of the secret and optionally its
+// version, like "my-vault/db-password".
+func ReadAzureKeyVaultSecret(client *AzureKeyVaultClient, path string) secrets.SecretVal {
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return secrets.SecretVal{ErrorMsg: "invalid format. Use: \"vault/secret\" or \"vault/secret/version\""}
+	}
+
+	token, err := client.getToken()
+	if err != nil {
+		return secrets.SecretVal{ErrorMsg: fmt.Sprintf("failed to authenticate to azure: %s", err)}
+	}
+
+	secretURL := client.vaultURL(parts[0]) + "/secrets/" + url.PathEscape(parts[1])
+	if len(parts) == 3 && parts[2] != "" {
+		secretURL += "/" + url.PathEscape(parts[2])
+	}
+	req, err := http.NewRequest(http.MethodGet, secretURL+"?api-version="+azureKeyVaultAPIVersion, nil)
+	if err != nil {
+		return secrets.SecretVal{ErrorMsg: err.Error()}
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var resp struct {
+		Value string `json:"value"`
+	}
+	if err := doJSONRequest(client.HTTPClient, req, &resp); err != nil {
+		return secrets.SecretVal{ErrorMsg: err.Error()}
+	}
+	return secrets.SecretVal{Value: resp.Value}
+}
+
+func (c *AzureKeyVaultClient) vaultURL(name string) string {
+	if c.VaultURL != nil {
+		return strings.TrimSuffix(c.VaultURL(name), "/")
+	}
+	return fmt.Sprintf("https://%s.vault.azure.net", name)
+}
+
+// getToken returns a token for Key Vault, requested on the first call
+func (c *AzureKeyVaultClient) getToken() (string, error) {
+	if c.token != "" {
+		return c.token, nil
+	}
+
+	var req *http.Request
+	var err error
+	if c.TenantID != "" && c.ClientSecret != "" {
+		authorityHost := c.AuthorityHost
+		if authorityHost == "" {
+			authorityHost = azureAuthorityHost
+		}
+		form := url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {c.ClientID},
+			"client_secret": {c.ClientSecret},
+			"scope":         {azureKeyVaultScope + "/.default"},
+		}
+		tokenURL := strings.TrimSuffix(authorityHost, "/") + "/" + url.PathEscape(c.TenantID) + "/oauth2/v2.0/token"
+		req, err = http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		endpoint := c.IMDSEndpoint
+		if endpoint == "" {
+			endpoint = azureIMDSTokenEndpoint
+		}
+		query := url.Values{
+			"api-version": {"2018-02-01"},
+			"resource":    {azureKeyVaultScope},
+		}
+		if c.ClientID != "" {
+			query.Set("client_id", c.ClientID)
+		}
+		req, err = http.NewRequest(http.MethodGet, endpoint+"?"+query.Encode(), nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Metadata", "true")
+	}
+
+	var resp struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doJSONRequest(c.HTTPClient, req, &resp); err != nil {
+		return "", err
+	}
+	if resp.AccessToken == "" {
+		return "", fmt.Errorf("no access token returned by %s", req.URL.Redacted())
+	}
+	c.token = resp.AccessToken
+	return c.token, nil
+}
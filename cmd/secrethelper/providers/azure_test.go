@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package providers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAzureServer returns a stand-in for the Microsoft identity platform, the
+// instance metadata service and a vault named "some-vault"
+func newAzureServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/some-tenant/oauth2/v2.0/token":
+			if r.PostFormValue("client_secret") != "some_client_secret" || r.PostFormValue("scope") != "https://vault.azure.net/.default" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": "invalid_client"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token": "some_token"}`))
+			return
+		case "/metadata/identity/oauth2/token":
+			if r.Header.Get("Metadata") != "true" || r.URL.Query().Get("resource") != "https://vault.azure.net" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"access_token": "some_token"}`))
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer some_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/some-vault/secrets/some-secret":
+			w.Write([]byte(`{"value": "some_value"}`))
+		case "/some-vault/secrets/some-secret/some-version":
+			w.Write([]byte(`{"value": "previous_value"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": "SecretNotFound"}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestReadAzureKeyVaultSecret(t *testing.T) {
+	server := newAzureServer(t)
+	vaultURL := func(name string) string { return server.URL + "/" + name }
+
+	tests := []struct {
+		name          string
+		client        *AzureKeyVaultClient
+		secretPath    string
+		expectedValue string
+		expectedError string
+	}{
+		{
+			name:          "invalid path format",
+			client:        &AzureKeyVaultClient{},
+			secretPath:    "some-vault",
+			expectedError: "invalid format. Use: \"vault/secret\" or \"vault/secret/version\"",
+		},
+		{
+			name:          "client credentials",
+			client:        &AzureKeyVaultClient{TenantID: "some-tenant", ClientID: "some-client", ClientSecret: "some_client_secret"},
+			secretPath:    "some-vault/some-secret",
+			expectedValue: "some_value",
+		},
+		{
+			name:          "invalid client credentials",
+			client:        &AzureKeyVaultClient{TenantID: "some-tenant", ClientID: "some-client", ClientSecret: "wrong_secret"},
+			secretPath:    "some-vault/some-secret",
+			expectedError: "failed to authenticate to azure: POST " + server.URL + "/some-tenant/oauth2/v2.0/token: unexpected status 401 Unauthorized: {\"error\": \"invalid_client\"}",
+		},
+		{
+			name:          "managed identity",
+			client:        &AzureKeyVaultClient{IMDSEndpoint: server.URL + "/metadata/identity/oauth2/token"},
+			secretPath:    "some-vault/some-secret/some-version",
+			expectedValue: "previous_value",
+		},
+		{
+			name:          "secret does not exist",
+			client:        &AzureKeyVaultClient{IMDSEndpoint: server.URL + "/metadata/identity/oauth2/token"},
+			secretPath:    "some-vault/another-secret",
+			expectedError: "GET " + server.URL + "/some-vault/secrets/another-secret?api-version=7.4: unexpected status 404 Not Found: {\"error\": {\"code\": \"SecretNotFound\"}}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.client.AuthorityHost = server.URL
+			test.client.VaultURL = vaultURL
+			test.client.HTTPClient = server.Client()
+			secret := ReadAzureKeyVaultSecret(test.client, test.secretPath)
+			assert.Equal(t, test.expectedValue, secret.Value)
+			assert.Equal(t, test.expectedError, secret.ErrorMsg)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package providers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	httpTimeout = 10 * time.Second
+	// maxResponseSize bounds the size of the responses read from the secret managers
+	maxResponseSize = 1 << 20
+)
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: httpTimeout}
+}
+
+// newHTTPClientWithCACert returns an HTTP client trusting the PEM encoded CA
+// certificates of caCertPath instead of the system ones.
+func newHTTPClientWithCACert(caCertPath string) (*http.Client, error) {
+	pem, err := os.ReadFile(caCertPath)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", caCertPath)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	return &http.Client{Timeout: httpTimeout, Transport: transport}, nil
+}
+
+// doJSONRequest sends the request and decodes its JSON response in out. The
+// errors reported by the server are returned with the status of the response.
+func doJSONRequest(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s: unexpected status %s: %s", req.Method, req.URL.Redacted(), resp.Status, body)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("%s %s: failed to decode response: %w", req.Method, req.URL.Redacted(), err)
+	}
+	return nil
+}
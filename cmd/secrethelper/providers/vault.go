@@ -0,0 +1,210 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+)
+
+const defaultKubernetesServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// VaultClient reads secrets from the KV secrets engines of HashiCorp Vault. It
+// authenticates with a token, or logs in with the AppRole or Kubernetes auth
+// methods on its first request.
+type VaultClient struct {
+	// Addr is the address of the Vault server, like https://vault:8200
+	Addr string
+	// Namespace is the Vault Enterprise namespace, if any
+	Namespace string
+
+	// Token is used to authenticate when set
+	Token string
+	// RoleID and SecretID are used to log in with the AppRole auth method
+	RoleID   string
+	SecretID string
+	// KubernetesRole is used to log in with the Kubernetes auth method, with
+	// the service account token read from KubernetesTokenPath
+	KubernetesRole      string
+	KubernetesTokenPath string
+	// AuthMountPath is the path the auth method is mounted on, it defaults
+	// to the name of the auth method
+	AuthMountPath string
+
+	HTTPClient *http.Client
+
+	loginErr error
+}
+
+// NewVaultClientFromEnv returns a VaultClient configured with the same
+// environment variables as the Vault CLI: VAULT_ADDR, VAULT_CACERT,
+// VAULT_NAMESPACE and VAULT_TOKEN, and VAULT_ROLE_ID, VAULT_SECRET_ID,
+// VAULT_K8S_ROLE, VAULT_K8S_TOKEN_PATH and VAULT_AUTH_PATH for the other auth
+// methods.
+func NewVaultClientFromEnv() *VaultClient {
+	client := &VaultClient{
+		Addr:                os.Getenv("VAULT_ADDR"),
+		Namespace:           os.Getenv("VAULT_NAMESPACE"),
+		Token:               os.Getenv("VAULT_TOKEN"),
+		RoleID:              os.Getenv("VAULT_ROLE_ID"),
+		SecretID:            os.Getenv("VAULT_SECRET_ID"),
+		KubernetesRole:      os.Getenv("VAULT_K8S_ROLE"),
+		KubernetesTokenPath: os.Getenv("VAULT_K8S_TOKEN_PATH"),
+		AuthMountPath:       os.Getenv("VAULT_AUTH_PATH"),
+		HTTPClient:          newHTTPClient(),
+	}
+	if caCertPath := os.Getenv("VAULT_CACERT"); caCertPath != "" {
+		httpClient, err := newHTTPClientWithCACert(caCertPath)
+		if err != nil {
+			// reported by every request, like the login errors
+			client.loginErr = fmt.Errorf("failed to load VAULT_CACERT: %w", err)
+		} else {
+			client.HTTPClient = httpClient
+		}
+	}
+	return client
+}
+
+type vaultResponse struct {
+	Data json.RawMessage `json:"data"`
+	Auth struct {
+		ClientToken string `json:"client_token"`
+	} `json:"auth"`
+}
+
+// ReadVaultSecret reads a key of a secret stored in Vault. The path is the API
+// path of the secret followed by the key, like "secret/data/app#password" for
+// a KV v2 secrets engine mounted on "secret/", or "kv/app#password" for a KV
+// v1 one.
+func ReadVaultSecret(client *VaultClient, path string) secrets.SecretVal {
+	secretPath, key, ok := strings.Cut(path, "#")
+	if !ok || secretPath == "" || key == "" {
+		return secrets.SecretVal{ErrorMsg: "invalid format. Use: \"path/to/secret#key\""}
+	}
+
+	if err := client.login(); err != nil {
+		return secrets.SecretVal{ErrorMsg: fmt.Sprintf("failed to authenticate to vault: %s", err)}
+	}
+
+	var resp vaultResponse
+	if err := client.do(http.MethodGet, strings.TrimPrefix(secretPath, "/"), nil, &resp); err != nil {
+		return secrets.SecretVal{ErrorMsg: err.Error()}
+	}
+
+	data, err := decodeVaultSecretData(resp.Data)
+	if err != nil {
+		return secrets.SecretVal{ErrorMsg: fmt.Sprintf("failed to decode secret %s: %s", secretPath, err)}
+	}
+
+	value, found := data[key]
+	if !found {
+		return secrets.SecretVal{ErrorMsg: fmt.Sprintf("key %s not found in secret %s", key, secretPath)}
+	}
+	switch v := value.(type) {
+	case string:
+		return secrets.SecretVal{Value: v}
+	default:
+		encoded, _ := json.Marshal(v)
+		return secrets.SecretVal{Value: string(encoded)}
+	}
+}
+
+// decodeVaultSecretData returns the key/value pairs of a secret. KV v2 secrets
+// engines nest them in a "data" field, next to their "metadata".
+func decodeVaultSecretData(raw json.RawMessage) (map[string]interface{}, error) {
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			return nested, nil
+		}
+	}
+	return data, nil
+}
+
+// login gets a token with the AppRole or Kubernetes auth methods, unless a
+// token was given. It is only attempted once.
+func (c *VaultClient) login() error {
+	if c.Addr == "" {
+		return errors.New("VAULT_ADDR is not set")
+	}
+	if c.Token != "" || c.loginErr != nil {
+		return c.loginErr
+	}
+
+	var method string
+	var body map[string]string
+	switch {
+	case c.RoleID != "":
+		method = "approle"
+		body = map[string]string{"role_id": c.RoleID, "secret_id": c.SecretID}
+	case c.KubernetesRole != "":
+		method = "kubernetes"
+		tokenPath := c.KubernetesTokenPath
+		if tokenPath == "" {
+			tokenPath = defaultKubernetesServiceAccountTokenPath
+		}
+		jwt, err := os.ReadFile(tokenPath)
+		if err != nil {
+			c.loginErr = fmt.Errorf("failed to read service account token: %w", err)
+			return c.loginErr
+		}
+		body = map[string]string{"role": c.KubernetesRole, "jwt": strings.TrimSpace(string(jwt))}
+	default:
+		c.loginErr = errors.New("no token nor auth method configured")
+		return c.loginErr
+	}
+
+	mountPath := c.AuthMountPath
+	if mountPath == "" {
+		mountPath = method
+	}
+
+	var resp vaultResponse
+	if err := c.do(http.MethodPost, "auth/"+strings.Trim(mountPath, "/")+"/login", body, &resp); err != nil {
+		c.loginErr = err
+		return c.loginErr
+	}
+	if resp.Auth.ClientToken == "" {
+		c.loginErr = fmt.Errorf("no token returned by the %s auth method", method)
+		return c.loginErr
+	}
+	c.Token = resp.Auth.ClientToken
+	return nil
+}
+
+func (c *VaultClient) do(method string, path string, body interface{}, out interface{}) error {
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.Addr, "/")+"/v1/"+path, &reqBody)
+	if err != nil {
+		return err
+	}
+	if c.Token != "" {
+		req.Header.Set("X-Vault-Token", c.Token)
+	}
+	if c.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return doJSONRequest(c.HTTPClient, req, out)
+}
@@ -0,0 +1,173 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package providers
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVaultServer returns a stand-in for a Vault server with a KV v1 secrets
+// engine mounted on "kv/" and a KV v2 one mounted on "secret/"
+func newVaultServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/approle/login", "/v1/auth/kubernetes/login", "/v1/auth/custom/login":
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || (body["secret_id"] != "some_secret_id" && body["jwt"] != "some_jwt") {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"errors": ["invalid credentials"]}`))
+				return
+			}
+			w.Write([]byte(`{"auth": {"client_token": "some_token"}}`))
+			return
+		}
+
+		if r.Header.Get("X-Vault-Token") != "some_token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors": ["permission denied"]}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/kv/some_name":
+			w.Write([]byte(`{"data": {"some_key": "some_value", "port": 5432}}`))
+		case "/v1/secret/data/some_name":
+			w.Write([]byte(`{"data": {"data": {"some_key": "another_value"}, "metadata": {"version": 3}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": []}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestReadVaultSecret(t *testing.T) {
+	server := newVaultServer(t)
+
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte("some_jwt\n"), 0600))
+
+	tests := []struct {
+		name          string
+		client        *VaultClient
+		secretPath    string
+		expectedValue string
+		expectedError string
+	}{
+		{
+			name:          "invalid path format",
+			client:        &VaultClient{Addr: server.URL, Token: "some_token"},
+			secretPath:    "kv/some_name",
+			expectedError: "invalid format. Use: \"path/to/secret#key\"",
+		},
+		{
+			name:          "no address",
+			client:        &VaultClient{Token: "some_token"},
+			secretPath:    "kv/some_name#some_key",
+			expectedError: "failed to authenticate to vault: VAULT_ADDR is not set",
+		},
+		{
+			name:          "no auth method",
+			client:        &VaultClient{Addr: server.URL},
+			secretPath:    "kv/some_name#some_key",
+			expectedError: "failed to authenticate to vault: no token nor auth method configured",
+		},
+		{
+			name:          "KV v1 secret with a token",
+			client:        &VaultClient{Addr: server.URL, Token: "some_token"},
+			secretPath:    "kv/some_name#some_key",
+			expectedValue: "some_value",
+		},
+		{
+			name:          "KV v1 secret with a non-string value",
+			client:        &VaultClient{Addr: server.URL, Token: "some_token"},
+			secretPath:    "kv/some_name#port",
+			expectedValue: "5432",
+		},
+		{
+			name:          "KV v2 secret",
+			client:        &VaultClient{Addr: server.URL, Token: "some_token"},
+			secretPath:    "secret/data/some_name#some_key",
+			expectedValue: "another_value",
+		},
+		{
+			name:          "key does not exist",
+			client:        &VaultClient{Addr: server.URL, Token: "some_token"},
+			secretPath:    "secret/data/some_name#another_key",
+			expectedError: "key another_key not found in secret secret/data/some_name",
+		},
+		{
+			name:          "secret does not exist",
+			client:        &VaultClient{Addr: server.URL, Token: "some_token"},
+			secretPath:    "secret/data/another_name#some_key",
+			expectedError: "GET " + server.URL + "/v1/secret/data/another_name: unexpected status 404 Not Found: {\"errors\": []}",
+		},
+		{
+			name:          "AppRole auth method",
+			client:        &VaultClient{Addr: server.URL, RoleID: "some_role", SecretID: "some_secret_id"},
+			secretPath:    "kv/some_name#some_key",
+			expectedValue: "some_value",
+		},
+		{
+			name:          "AppRole auth method with invalid credentials",
+			client:        &VaultClient{Addr: server.URL, RoleID: "some_role", SecretID: "wrong_secret_id"},
+			secretPath:    "kv/some_name#some_key",
+			expectedError: "failed to authenticate to vault: POST " + server.URL + "/v1/auth/approle/login: unexpected status 400 Bad Request: {\"errors\": [\"invalid credentials\"]}",
+		},
+		{
+			name:          "Kubernetes auth method on a custom mount path",
+			client:        &VaultClient{Addr: server.URL, KubernetesRole: "some_role", KubernetesTokenPath: tokenPath, AuthMountPath: "custom"},
+			secretPath:    "kv/some_name#some_key",
+			expectedValue: "some_value",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.client.HTTPClient = server.Client()
+			secret := ReadVaultSecret(test.client, test.secretPath)
+			assert.Equal(t, test.expectedValue, secret.Value)
+			assert.Equal(t, test.expectedError, secret.ErrorMsg)
+		})
+	}
+}
+
+func TestNewVaultClientFromEnvCACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"data": {"some_key": "some_value"}}`))
+	}))
+	t.Cleanup(server.Close)
+
+	caCertPath := filepath.Join(t.TempDir(), "ca.pem")
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caCertPath, caCert, 0600))
+
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "some_token")
+
+	// the certificate of the server is not trusted by default
+	secret := ReadVaultSecret(NewVaultClientFromEnv(), "kv/some_name#some_key")
+	assert.Contains(t, secret.ErrorMsg, "certificate")
+
+	t.Setenv("VAULT_CACERT", caCertPath)
+	secret = ReadVaultSecret(NewVaultClientFromEnv(), "kv/some_name#some_key")
+	assert.Empty(t, secret.ErrorMsg)
+	assert.Equal(t, "some_value", secret.Value)
+
+	t.Setenv("VAULT_CACERT", filepath.Join(t.TempDir(), "missing.pem"))
+	secret = ReadVaultSecret(NewVaultClientFromEnv(), "kv/some_name#some_key")
+	assert.Contains(t, secret.ErrorMsg, "failed to load VAULT_CACERT")
+}
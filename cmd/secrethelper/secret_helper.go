@@ -11,10 +11,16 @@
 //
 // 1) With the "--with-provider-prefixes" option enabled. Each input secret
 // should follow this format: "providerPrefix/some/path". The provider prefix
-// indicates where to fetch the secrets from. At the moment, we support "file"
-// and "k8s_secret". The path can mean different things depending on the
-// provider. In "file" it's a file system path. In "k8s_secret", it follows this
-// format: "namespace/name/key".
+// indicates where to fetch the secrets from. At the moment, we support "file",
+// "k8s_secret", "vault", "aws_secret", "aws_ssm" and "azure_keyvault". The path
+// can mean different things depending on the provider. In "file" it's a file
+// system path. In "k8s_secret", it follows this format: "namespace/name/key".
+// In "vault", it's the API path of the secret followed by its key:
+// "secret/data/name#key". In "aws_secret", it's the name or the ARN of the
+// secret, optionally followed by a key: "name#key". In "aws_ssm", it's the name
+// of the parameter. In "azure_keyvault", it follows this format:
+// "vault/secret[/version]". Their clients are configured from the environment
+// variables of the respective CLIs and SDKs.
 //
 // 2) Without the "--with-provider-prefixes" option. The program expects a root
 // path in the arguments and input secrets are just paths relative to the root
@@ -22,6 +28,10 @@
 // "/some/path", the fetched value of the secret will be the contents of
 // "/some/path/my_secret". This option was offered before introducing
 // "--with-provider-prefixes" and is kept to avoid breaking compatibility.
+//
+// With the "--audit-file" option, every fetch is recorded, without the value
+// of the secret, in the given file, which is pruned and rotated like the audit
+// file of the Agent.
 package secrethelper
 
 import (
@@ -31,32 +41,49 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/fx"
 
 	"github.com/DataDog/datadog-agent/cmd/secrethelper/providers"
 	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	"github.com/DataDog/datadog-agent/comp/core/secrets/ndrecords"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 )
 
 const (
 	providerPrefixesFlag    = "with-provider-prefixes"
+	auditFileFlag           = "audit-file"
 	providerPrefixSeparator = "@"
 	filePrefix              = "file"
 	k8sSecretPrefix         = "k8s_secret"
+	vaultPrefix             = "vault"
+	awsSecretPrefix         = "aws_secret"
+	awsSSMPrefix            = "aws_ssm"
+	azureKeyVaultPrefix     = "azure_keyvault"
 )
 
 // cliParams are the command-line arguments for this subcommand
 type cliParams struct {
 	usePrefixes bool
+	auditFile   string
 
 	// args are the positional command-line arguments
 	args []string
 }
 
+// secretProviders are the clients used to fetch the secrets with a provider prefix
+type secretProviders struct {
+	kubeSecretGetter providers.KubeSecretGetter
+	vault            *providers.VaultClient
+	aws              *providers.AWSClient
+	azureKeyVault    *providers.AzureKeyVaultClient
+}
+
 // Commands returns a slice of subcommands of the parent command.
 func Commands() []*cobra.Command {
 	cliParams := &cliParams{}
@@ -72,7 +99,8 @@ func Commands() []*cobra.Command {
 			)
 		},
 	}
-	cmd.PersistentFlags().BoolVarP(&cliParams.usePrefixes, providerPrefixesFlag, "", false, "Use prefixes to select the secrets provider (file, k8s_secret)")
+	cmd.PersistentFlags().BoolVarP(&cliParams.usePrefixes, providerPrefixesFlag, "", false, "Use prefixes to select the secrets provider (file, k8s_secret, vault, aws_secret, aws_ssm, azure_keyvault)")
+	cmd.PersistentFlags().StringVarP(&cliParams.auditFile, auditFileFlag, "", "", "Record the fetched secrets, without their values, in this file")
 
 	secretHelperCmd := &cobra.Command{
 		Use:   "secret-helper",
@@ -95,20 +123,58 @@ func readCmd(cliParams *cliParams) error {
 		dir = cliParams.args[0]
 	}
 
-	return readSecrets(os.Stdin, os.Stdout, dir, cliParams.usePrefixes, apiserver.GetKubeSecret)
+	clients := secretProviders{
+		kubeSecretGetter: apiserver.GetKubeSecret,
+		vault:            providers.NewVaultClientFromEnv(),
+		aws:              providers.NewAWSClient(),
+		azureKeyVault:    providers.NewAzureKeyVaultClientFromEnv(),
+	}
+	var audit *ndrecords.RotatingNDRecords
+	if cliParams.auditFile != "" {
+		audit = ndrecords.NewRotatingNDRecords(cliParams.auditFile, ndrecords.Config{})
+	}
+	return readSecrets(os.Stdin, os.Stdout, dir, cliParams.usePrefixes, clients, audit)
 }
 
-func readSecrets(r io.Reader, w io.Writer, dir string, usePrefixes bool, kubeSecretGetter providers.KubeSecretGetter) error {
+// readSecrets fetches the secrets listed in r and writes them to w. The
+// fetches are recorded in audit, if not nil.
+func readSecrets(r io.Reader, w io.Writer, dir string, usePrefixes bool, clients secretProviders, audit *ndrecords.RotatingNDRecords) error {
 	inputSecrets, err := parseInputSecrets(r)
 	if err != nil {
 		return err
 	}
 
+	var fetchedSecrets map[string]secrets.SecretVal
 	if usePrefixes {
-		return writeFetchedSecrets(w, readSecretsUsingPrefixes(inputSecrets, dir, kubeSecretGetter))
+		fetchedSecrets = readSecretsUsingPrefixes(inputSecrets, dir, clients)
+	} else {
+		fetchedSecrets = readSecretsFromFile(inputSecrets, dir)
+	}
+
+	if audit != nil {
+		if err := audit.Add(time.Now().UTC(), newAuditRecords(fetchedSecrets)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write audit file: %s\n", err)
+		}
 	}
+	return writeFetchedSecrets(w, fetchedSecrets)
+}
 
-	return writeFetchedSecrets(w, readSecretsFromFile(inputSecrets, dir))
+type auditRecord struct {
+	Handle string `json:"handle"`
+	Error  string `json:"error,omitempty"`
+}
+
+// newAuditRecords returns the audit records of the fetched secrets, sorted by
+// handle. The values of the secrets are never recorded.
+func newAuditRecords(fetchedSecrets map[string]secrets.SecretVal) []auditRecord {
+	records := make([]auditRecord, 0, len(fetchedSecrets))
+	for handle, secret := range fetchedSecrets {
+		records = append(records, auditRecord{Handle: handle, Error: secret.ErrorMsg})
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Handle < records[j].Handle
+	})
+	return records
 }
 
 func parseInputSecrets(r io.Reader) ([]string, error) {
@@ -156,7 +222,7 @@ func readSecretsFromFile(secretsList []string, dir string) map[string]secrets.Se
 	return res
 }
 
-func readSecretsUsingPrefixes(secretsList []string, rootPath string, kubeSecretGetter providers.KubeSecretGetter) map[string]secrets.SecretVal {
+func readSecretsUsingPrefixes(secretsList []string, rootPath string, clients secretProviders) map[string]secrets.SecretVal {
 	res := make(map[string]secrets.SecretVal)
 
 	for _, secretID := range secretsList {
@@ -170,7 +236,15 @@ func readSecretsUsingPrefixes(secretsList []string, rootPath string, kubeSecretG
 		case filePrefix:
 			res[secretID] = providers.ReadSecretFile(id)
 		case k8sSecretPrefix:
-			res[secretID] = providers.ReadKubernetesSecret(kubeSecretGetter, id)
+			res[secretID] = providers.ReadKubernetesSecret(clients.kubeSecretGetter, id)
+		case vaultPrefix:
+			res[secretID] = providers.ReadVaultSecret(clients.vault, id)
+		case awsSecretPrefix:
+			res[secretID] = providers.ReadAWSSecret(clients.aws, id)
+		case awsSSMPrefix:
+			res[secretID] = providers.ReadAWSParameter(clients.aws, id)
+		case azureKeyVaultPrefix:
+			res[secretID] = providers.ReadAzureKeyVaultSecret(clients.azureKeyVault, id)
 		default:
 			res[secretID] = secrets.SecretVal{Value: "", ErrorMsg: fmt.Sprintf("provider not supported: %s", prefix)}
 		}
@@ -8,7 +8,11 @@ package secrethelper
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -18,6 +22,8 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 
+	"github.com/DataDog/datadog-agent/cmd/secrethelper/providers"
+	"github.com/DataDog/datadog-agent/comp/core/secrets/ndrecords"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 )
 
@@ -38,6 +44,24 @@ func TestReadSecrets(t *testing.T) {
 		return secret.Data, nil
 	}
 
+	vaultServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "some_token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/some_name" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data": {"data": {"some_key": "some_value"}, "metadata": {"version": 1}}}`))
+	}))
+	defer vaultServer.Close()
+
+	clients := secretProviders{
+		kubeSecretGetter: newKubeClientFunc,
+		vault:            &providers.VaultClient{Addr: vaultServer.URL, Token: "some_token", HTTPClient: vaultServer.Client()},
+	}
+
 	tests := []struct {
 		name        string
 		in          string
@@ -92,6 +116,27 @@ func TestReadSecrets(t *testing.T) {
 				}
 			}`,
 		},
+		{
+			name: "valid input, reading from vault provider",
+			in: `
+			{
+				"version": "1.0",
+				"secrets": [
+					"vault@secret/data/some_name#some_key",
+					"vault@secret/data/some_name#another_key"
+				]
+			}`,
+			out: `
+			{
+				"vault@secret/data/some_name#some_key": {
+					"value": "some_value"
+				},
+				"vault@secret/data/some_name#another_key": {
+					"error": "key another_key not found in secret secret/data/some_name"
+				}
+			}`,
+			usePrefixes: true,
+		},
 		{
 			name: "valid input, reading from file and k8s providers",
 			in: fmt.Sprintf(`
@@ -165,7 +210,7 @@ func TestReadSecrets(t *testing.T) {
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			var w bytes.Buffer
-			err := readSecrets(strings.NewReader(test.in), &w, path, test.usePrefixes, newKubeClientFunc)
+			err := readSecrets(strings.NewReader(test.in), &w, path, test.usePrefixes, clients, nil)
 			out := w.String()
 
 			if test.out != "" {
@@ -183,6 +228,29 @@ func TestReadSecrets(t *testing.T) {
 	}
 }
 
+func TestReadSecretsAuditFile(t *testing.T) {
+	auditFile := filepath.Join(t.TempDir(), "secret-helper-audit.json")
+	audit := ndrecords.NewRotatingNDRecords(auditFile, ndrecords.Config{})
+	secretPath, _ := filepath.Abs(filepath.Join("testdata", "read-secrets", "secret1"))
+	in, _ := json.Marshal(secretsRequest{Version: "1.0", Secrets: []string{"file@" + secretPath, "invalid_provider@some/id"}})
+
+	var w bytes.Buffer
+	err := readSecrets(bytes.NewReader(in), &w, "", true, secretProviders{}, audit)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(auditFile)
+	assert.NoError(t, err)
+	var record struct {
+		Data []auditRecord `json:"data"`
+	}
+	assert.NoError(t, json.Unmarshal(content, &record))
+	assert.Equal(t, []auditRecord{
+		{Handle: "file@" + secretPath},
+		{Handle: "invalid_provider@some/id", Error: "provider not supported: invalid_provider"},
+	}, record.Data)
+	assert.NotContains(t, string(content), "secret1-value")
+}
+
 func secretAbsPath(secretName string) string {
 	testdataPath := filepath.Join("testdata", "read-secrets", secretName)
 	absPath, _ := filepath.Abs(testdataPath)
@@ -58,6 +58,7 @@ func (a *Agent) SetupHandlers(r *mux.Router) {
 	// FIXME: this returns the entire datadog.yaml and not just security-agent.yaml config
 	r.HandleFunc("/config/by-source", a.settings.GetFullConfigBySource()).Methods("GET")
 	r.HandleFunc("/config/list-runtime", a.settings.ListConfigurable).Methods("GET")
+	r.HandleFunc("/config/explain/{setting}", a.settings.Explain).Methods("GET")
 	r.HandleFunc("/config/{setting}", a.settings.GetValue).Methods("GET")
 	r.HandleFunc("/config/{setting}", a.settings.SetValue).Methods("POST")
 	r.HandleFunc("/workload-list", func(w http.ResponseWriter, r *http.Request) {
@@ -10,6 +10,7 @@ package config
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/fx"
@@ -144,6 +145,26 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 		},
 	)
 
+	// explain returns a cobra command to show the sources of a config value.
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "explain [setting]",
+			Short: "Show every source defining a value for a given configuration setting, and the one in effect",
+			Long:  ``,
+			RunE: func(_ *cobra.Command, _ []string) error {
+				return fxutil.OneShot(
+					explainConfigValue,
+					fx.Supply(cliParams),
+					fx.Supply(core.BundleParams{
+						ConfigParams: config.NewSecurityAgentParams(globalParams.ConfigFilePaths, config.WithFleetPoliciesDirPath(globalParams.FleetPoliciesDirPath)),
+						SecretParams: secrets.NewEnabledParams(),
+						LogParams:    log.ForOneShot(command.LoggerName, "off", true)}),
+					core.Bundle(),
+				)
+			},
+		},
+	)
+
 	return []*cobra.Command{cmd}
 }
 func getSettingsClient(_ *cobra.Command, _ []string) (settings.Client, error) {
@@ -212,6 +233,26 @@ func getConfigValue(_ log.Component, _ config.Component, _ secrets.Component, pa
 	return nil
 }
 
+func explainConfigValue(_ log.Component, _ config.Component, _ secrets.Component, params *cliParams) error {
+	if len(params.args) != 1 {
+		return fmt.Errorf("a single setting name must be specified")
+	}
+
+	c, err := params.getClient(params.command, params.args)
+	if err != nil {
+		return err
+	}
+
+	explanation, err := c.Explain(params.args[0])
+	if err != nil {
+		return err
+	}
+
+	settings.PrintExplanation(os.Stdout, explanation)
+
+	return nil
+}
+
 func showRuntimeConfigurationBySource(_ log.Component, _ config.Component, _ secrets.Component, params *cliParams) error {
 	c, err := params.getClient(params.command, params.args)
 	if err != nil {
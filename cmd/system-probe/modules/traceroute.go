@@ -127,6 +127,14 @@ func parseParams(req *http.Request) (tracerouteutil.Config, error) {
 	if err != nil {
 		return tracerouteutil.Config{}, fmt.Errorf("invalid timeout: %s", err)
 	}
+	numPaths, err := parseUint(query, "num_paths", 16)
+	if err != nil {
+		return tracerouteutil.Config{}, fmt.Errorf("invalid num_paths: %s", err)
+	}
+	probesPerHop, err := parseUint(query, "probes_per_hop", 8)
+	if err != nil {
+		return tracerouteutil.Config{}, fmt.Errorf("invalid probes_per_hop: %s", err)
+	}
 	protocol := query.Get("protocol")
 	tcpMethod := query.Get("tcp_method")
 
@@ -137,6 +145,8 @@ func parseParams(req *http.Request) (tracerouteutil.Config, error) {
 		Timeout:      time.Duration(timeout),
 		Protocol:     payload.Protocol(protocol),
 		TCPMethod:    payload.TCPMethod(tcpMethod),
+		NumPaths:     uint16(numPaths),
+		ProbesPerHop: uint8(probesPerHop),
 	}, nil
 }
 
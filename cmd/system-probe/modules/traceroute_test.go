@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
 	tracerouteutil "github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/config"
 
 	"github.com/gorilla/mux"
@@ -50,6 +51,29 @@ func TestParseParams(t *testing.T) {
 				Timeout:      1000,
 			},
 		},
+		{
+			name: "multipath icmp",
+			host: "1.2.3.4",
+			params: map[string]string{
+				"protocol":       "ICMP",
+				"num_paths":      "8",
+				"probes_per_hop": "3",
+			},
+			expectedConfig: tracerouteutil.Config{
+				DestHostname: "1.2.3.4",
+				Protocol:     payload.ProtocolICMP,
+				NumPaths:     8,
+				ProbesPerHop: 3,
+			},
+		},
+		{
+			name: "invalid num_paths",
+			host: "1.2.3.4",
+			params: map[string]string{
+				"num_paths": "70000",
+			},
+			expectedError: "invalid num_paths: strconv.ParseUint: parsing \"70000\": value out of range",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(_ *testing.T) {
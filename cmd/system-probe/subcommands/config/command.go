@@ -8,6 +8,7 @@ package config
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/fx"
@@ -100,6 +101,15 @@ func Commands(globalParams *command.GlobalParams) []*cobra.Command {
 			RunE:  oneShotRunE(getConfigValue),
 		},
 	)
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "explain [setting]",
+			Short: "Show every source defining a value for a given configuration setting, and the one in effect",
+			Long:  ``,
+			Args:  cobra.ExactArgs(1),
+			RunE:  oneShotRunE(explainConfigValue),
+		},
+	)
 
 	return []*cobra.Command{cmd}
 }
@@ -193,3 +203,19 @@ func getConfigValue(sysprobeconfig sysprobeconfig.Component, cliParams *cliParam
 
 	return nil
 }
+
+func explainConfigValue(sysprobeconfig sysprobeconfig.Component, cliParams *cliParams) error {
+	c, err := getClient(sysprobeconfig)
+	if err != nil {
+		return err
+	}
+
+	explanation, err := c.Explain(cliParams.args[0])
+	if err != nil {
+		return err
+	}
+
+	settings.PrintExplanation(os.Stdout, explanation)
+
+	return nil
+}
@@ -0,0 +1,209 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package inspect implements the 'trace-agent inspect' cli, which tails the trace
+// inspector of a running trace-agent.
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/cmd/trace-agent/subcommands"
+	coreconfig "github.com/DataDog/datadog-agent/comp/core/config"
+	ipc "github.com/DataDog/datadog-agent/comp/core/ipc/def"
+	ipcfx "github.com/DataDog/datadog-agent/comp/core/ipc/fx"
+	ipchttp "github.com/DataDog/datadog-agent/comp/core/ipc/httphelpers"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	logfx "github.com/DataDog/datadog-agent/comp/core/log/fx"
+	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	"github.com/DataDog/datadog-agent/comp/core/secrets/secretsimpl"
+	nooptagger "github.com/DataDog/datadog-agent/comp/core/tagger/fx-noop"
+	"github.com/DataDog/datadog-agent/comp/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/api"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+	"github.com/DataDog/datadog-agent/pkg/util/option"
+)
+
+// maxRate is the maximum number of chunks per second the trace-agent streams.
+const maxRate = 50
+
+// cliParams are the command-line arguments for this subcommand.
+type cliParams struct {
+	*subcommands.GlobalParams
+
+	filters  api.TraceInspectorFilters
+	duration time.Duration
+	json     bool
+}
+
+// MakeCommand returns the inspect subcommand for the 'trace-agent' command.
+func MakeCommand(globalParamsGetter func() *subcommands.GlobalParams) *cobra.Command {
+	params := &cliParams{}
+	cmd := &cobra.Command{
+		Use:     "inspect",
+		Aliases: []string{"tail"},
+		Short:   "Tail the trace chunks processed by a running trace-agent along with their sampling decisions",
+		Long: `Use this to understand why traces are kept or dropped. Each chunk processed by the running
+trace-agent and matching the filters is printed along with the decisions of the samplers,
+the filters applied to it and the changes made by normalization and truncation.`,
+		PreRunE: func(*cobra.Command, []string) error {
+			if params.filters.Rate <= 0 || params.filters.Rate > maxRate {
+				return fmt.Errorf("rate must be greater than 0 and at most %d", maxRate)
+			}
+			if params.duration < 0 {
+				return fmt.Errorf("duration must be a positive value")
+			}
+			return nil
+		},
+		RunE: func(*cobra.Command, []string) error {
+			params.GlobalParams = globalParamsGetter()
+			return fxutil.OneShot(inspectTraces,
+				fx.Supply(params),
+				config.Module(),
+				fx.Supply(coreconfig.NewAgentParams(params.ConfPath, coreconfig.WithFleetPoliciesDirPath(params.FleetPoliciesDirPath))),
+				fx.Supply(log.ForOneShot(params.LoggerName, "off", true)),
+				fx.Supply(option.None[secrets.Component]()),
+				fx.Supply(secrets.NewEnabledParams()),
+				coreconfig.Module(),
+				secretsimpl.Module(),
+				nooptagger.Module(),
+				ipcfx.ModuleReadOnly(),
+				logfx.Module(),
+			)
+		},
+		SilenceUsage: true,
+	}
+	cmd.Flags().StringVar(&params.filters.Service, "service", "", "Only show chunks whose root span has this service")
+	cmd.Flags().StringVar(&params.filters.Resource, "resource", "", "Only show chunks whose root span resource contains this string")
+	cmd.Flags().Float64Var(&params.filters.Rate, "rate", 5, fmt.Sprintf("Maximum number of chunks shown per second (at most %d)", maxRate))
+	cmd.Flags().DurationVarP(&params.duration, "duration", "d", 0, "Duration of the inspection (default: 0, until interrupted)")
+	cmd.Flags().BoolVar(&params.json, "json", false, "Print the chunks as newline-delimited JSON")
+	return cmd
+}
+
+func inspectTraces(config config.Component, client ipc.Component, params *cliParams) error {
+	tracecfg := config.Object()
+	if tracecfg == nil {
+		return fmt.Errorf("Unable to successfully parse config")
+	}
+	if tracecfg.DebugServerPort == 0 {
+		return fmt.Errorf("the debug server is disabled (apm_config.debug.port: 0), traces can not be inspected")
+	}
+	body, err := json.Marshal(&params.filters)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if params.duration > 0 {
+		ctx, cancel = context.WithTimeout(ctx, params.duration)
+		defer cancel()
+	}
+
+	url := fmt.Sprintf("https://127.0.0.1:%d/debug/traces", tracecfg.DebugServerPort)
+	lw := &lineWriter{onLine: func(line []byte) {
+		if params.json {
+			fmt.Println(string(line))
+			return
+		}
+		var c api.InspectedChunk
+		if err := json.Unmarshal(line, &c); err != nil {
+			fmt.Fprintf(os.Stderr, "Unexpected response from the trace-agent: %s\n", line)
+			return
+		}
+		printChunk(os.Stdout, &c)
+	}}
+	err = client.GetClient().PostChunk(url, "application/json", bytes.NewReader(body), lw.write, ipchttp.WithContext(ctx))
+	if err != nil && ctx.Err() == nil {
+		return fmt.Errorf("could not reach the trace-agent debug server on port %d, make sure the trace-agent is running: %v", tracecfg.DebugServerPort, err)
+	}
+	return nil
+}
+
+// lineWriter reassembles the lines split across the chunks of a streamed response.
+type lineWriter struct {
+	buf    []byte
+	onLine func([]byte)
+}
+
+func (lw *lineWriter) write(chunk []byte) {
+	lw.buf = append(lw.buf, chunk...)
+	for {
+		i := bytes.IndexByte(lw.buf, '\n')
+		if i < 0 {
+			return
+		}
+		if line := bytes.TrimSpace(lw.buf[:i]); len(line) > 0 {
+			lw.onLine(line)
+		}
+		lw.buf = lw.buf[i+1:]
+	}
+}
+
+// printChunk prints a human readable description of the inspected chunk.
+func printChunk(w io.Writer, c *api.InspectedChunk) {
+	fmt.Fprintf(w, "%s trace_id=%d service=%q name=%q", c.Time.Format(time.RFC3339), c.TraceID, c.Service, c.Name)
+	if c.Resource != "" {
+		fmt.Fprintf(w, " resource=%q", c.Resource)
+	}
+	if c.Env != "" {
+		fmt.Fprintf(w, " env=%q", c.Env)
+	}
+	fmt.Fprintf(w, " spans=%d\n", c.Spans)
+	if c.Dropped != "" {
+		fmt.Fprintf(w, "  DROPPED (%s): %s\n", c.Dropped, c.Reason)
+	} else {
+		verdict := "DROPPED"
+		if c.Kept {
+			verdict = "KEPT"
+		}
+		fmt.Fprintf(w, "  %s by the %s sampler", verdict, c.Sampler)
+		if c.Priority != nil {
+			fmt.Fprintf(w, ", priority=%d", *c.Priority)
+		}
+		if c.DecisionMaker != "" {
+			fmt.Fprintf(w, ", decision_maker=%s", c.DecisionMaker)
+		}
+		fmt.Fprintf(w, ", %d span(s) sent\n", c.KeptSpans)
+	}
+	if len(c.Rates) > 0 {
+		rates := make([]string, 0, len(c.Rates))
+		for k, v := range c.Rates {
+			rates = append(rates, fmt.Sprintf("%s=%g", k, v))
+		}
+		sort.Strings(rates)
+		fmt.Fprintf(w, "  rates: %s\n", strings.Join(rates, " "))
+	}
+	if len(c.Verdicts) > 0 {
+		verdicts := make([]string, 0, len(c.Verdicts))
+		for _, v := range c.Verdicts {
+			verdicts = append(verdicts, fmt.Sprintf("%s=%t", v.Sampler, v.Keep))
+		}
+		fmt.Fprintf(w, "  samplers: %s\n", strings.Join(verdicts, " "))
+	}
+	if p := c.Probabilistic; p != nil {
+		fmt.Fprintf(w, "  probabilistic: hash=%d threshold=%d keep=%t\n", p.Hash, p.Threshold, p.Keep)
+	}
+	for _, n := range c.Normalized {
+		fmt.Fprintf(w, "  normalized: %s\n", n)
+	}
+	for _, t := range c.Truncated {
+		fmt.Fprintf(w, "  truncated: %s\n", t)
+	}
+}
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package inspect
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/trace-agent/subcommands"
+	"github.com/DataDog/datadog-agent/pkg/trace/api"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+func TestInspectCommand(t *testing.T) {
+	fxutil.TestOneShotSubcommand(t,
+		[]*cobra.Command{MakeCommand(func() *subcommands.GlobalParams {
+			return &subcommands.GlobalParams{}
+		})},
+		[]string{"inspect", "--service", "web", "--rate", "10"},
+		inspectTraces,
+		func(params *cliParams) {
+			require.Equal(t, "web", params.filters.Service)
+			require.Equal(t, 10.0, params.filters.Rate)
+		})
+}
+
+func TestInspectCommandInvalidRate(t *testing.T) {
+	cmd := MakeCommand(func() *subcommands.GlobalParams {
+		return &subcommands.GlobalParams{}
+	})
+	cmd.SetArgs([]string{"--rate", "500"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	assert.ErrorContains(t, cmd.Execute(), "rate must be")
+}
+
+func TestLineWriter(t *testing.T) {
+	var lines []string
+	lw := &lineWriter{onLine: func(line []byte) {
+		lines = append(lines, string(line))
+	}}
+	lw.write([]byte(`{"a":1}` + "\n" + `{"b"`))
+	lw.write([]byte(`:2}` + "\n\n"))
+	lw.write([]byte(`{"c":3}`))
+	assert.Equal(t, []string{`{"a":1}`, `{"b":2}`}, lines)
+}
+
+func TestPrintChunk(t *testing.T) {
+	priority := int32(1)
+	var buf bytes.Buffer
+	printChunk(&buf, &api.InspectedChunk{
+		Time:          time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		TraceID:       42,
+		Service:       "web",
+		Name:          "http.request",
+		Resource:      "GET /users",
+		Spans:         3,
+		Sampler:       "priority",
+		Verdicts:      []api.SamplerVerdict{{Sampler: "rare", Keep: false}, {Sampler: "priority", Keep: true}},
+		Priority:      &priority,
+		DecisionMaker: "-1",
+		Kept:          true,
+		KeptSpans:     3,
+		Rates:         map[string]float64{"_dd.agent_psr": 0.5},
+		Normalized:    []string{`span 1: service "Web" -> "web"`},
+	})
+	assert.Equal(t, `2024-01-02T03:04:05Z trace_id=42 service="web" name="http.request" resource="GET /users" spans=3
+  KEPT by the priority sampler, priority=1, decision_maker=-1, 3 span(s) sent
+  rates: _dd.agent_psr=0.5
+  samplers: rare=false priority=true
+  normalized: span 1: service "Web" -> "web"
+`, buf.String())
+
+	buf.Reset()
+	printChunk(&buf, &api.InspectedChunk{
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		TraceID: 42,
+		Service: "web",
+		Name:    "http.request",
+		Spans:   1,
+		Dropped: "ignore_resources",
+		Reason:  "GET /health",
+	})
+	assert.Equal(t, `2024-01-02T03:04:05Z trace_id=42 service="web" name="http.request" spans=1
+  DROPPED (ignore_resources): GET /health
+`, buf.String())
+}
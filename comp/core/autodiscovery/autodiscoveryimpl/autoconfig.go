@@ -12,6 +12,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"maps"
 	"net/http"
 	"sort"
 	"sync"
@@ -191,7 +192,7 @@ func newAutoConfig(deps dependencies) autodiscovery.Component {
 
 // createNewAutoConfig creates an AutoConfig instance (without starting).
 func createNewAutoConfig(schedulerController *scheduler.Controller, secretResolver secrets.Component, wmeta option.Option[workloadmeta.Component], taggerComp tagger.Component, logs logComp.Component, telemetryComp telemetry.Component) *AutoConfig {
-	cfgMgr := newReconcilingConfigManager(secretResolver)
+	cfgMgr := newReconcilingConfigManager(secretResolver, wmeta)
 	ac := &AutoConfig{
 		configPollers:            make([]*configPoller, 0, 9),
 		listenerCandidates:       make(map[string]*listenerCandidate),
@@ -211,6 +212,7 @@ func createNewAutoConfig(schedulerController *scheduler.Controller, secretResolv
 		logs:                     logs,
 		telemetryStore:           acTelemetry.NewStore(telemetryComp),
 	}
+	secretResolver.SubscribeToRefresh(ac.processSecretRefresh)
 	return ac
 }
 
@@ -218,9 +220,24 @@ func createNewAutoConfig(schedulerController *scheduler.Controller, secretResolv
 // It waits for service events to trigger template resolution and
 // checks the tags on existing services are up to date.
 func (ac *AutoConfig) serviceListening() {
+	// namespace events are only received when workloadmeta is available, the
+	// nil channel otherwise blocks forever
+	var namespaceEvents chan workloadmeta.EventBundle
+	wmeta, wmetaFound := ac.wmeta.Get()
+	if wmetaFound {
+		filter := workloadmeta.NewFilterBuilder().
+			AddKind(workloadmeta.KindKubernetesMetadata).
+			Build()
+		namespaceEvents = wmeta.Subscribe("ad-namespaces", workloadmeta.NormalPriority, filter)
+	}
+	namespaces := map[string]*workloadmeta.KubernetesMetadata{}
+
 	for {
 		select {
 		case <-ac.listenerStop:
+			if wmetaFound {
+				wmeta.Unsubscribe(namespaceEvents)
+			}
 			ac.healthListening.Deregister() //nolint:errcheck
 			return
 		case <-ac.healthListening.C: // To be considered healthy
@@ -228,7 +245,36 @@ func (ac *AutoConfig) serviceListening() {
 			ac.processNewService(svc)
 		case svc := <-ac.delService:
 			ac.processDelService(svc)
+		case evBundle, ok := <-namespaceEvents:
+			if !ok {
+				namespaceEvents = nil
+				continue
+			}
+			evBundle.Acknowledge()
+			ac.processNamespaceEvents(evBundle, namespaces)
+		}
+	}
+}
+
+// processNamespaceEvents matches the templates having selectors against the
+// services of the namespaces whose labels or annotations changed.  namespaces
+// holds the last metadata seen for each namespace.
+func (ac *AutoConfig) processNamespaceEvents(evBundle workloadmeta.EventBundle, namespaces map[string]*workloadmeta.KubernetesMetadata) {
+	for _, event := range evBundle.Events {
+		metadata, ok := event.Entity.(*workloadmeta.KubernetesMetadata)
+		if !ok || metadata.GVR == nil || metadata.GVR.Resource != "namespaces" {
+			continue
 		}
+		if event.Type == workloadmeta.EventTypeUnset {
+			delete(namespaces, metadata.Name)
+			continue
+		}
+		previous, found := namespaces[metadata.Name]
+		namespaces[metadata.Name] = metadata
+		if found && maps.Equal(previous.Labels, metadata.Labels) && maps.Equal(previous.Annotations, metadata.Annotations) {
+			continue
+		}
+		ac.applyChanges(ac.cfgMgr.processNamespaceUpdate(metadata.Name))
 	}
 }
 
@@ -641,6 +687,15 @@ func (ac *AutoConfig) processRemovedConfigs(configs []integration.Config) {
 	ac.deleteMappingsOfCheckIDsWithSecrets(changes.Unschedule)
 }
 
+// processSecretRefresh reschedules the configs using a secret whose value
+// changed.
+func (ac *AutoConfig) processSecretRefresh(handle string, origins []string) {
+	changes, changedIDsOfSecretsWithConfigs := ac.cfgMgr.processSecretRefresh(handle, origins)
+	ac.applyChanges(changes)
+	ac.deleteMappingsOfCheckIDsWithSecrets(changes.Unschedule)
+	ac.store.setIDsOfChecksWithSecrets(changedIDsOfSecretsWithConfigs)
+}
+
 // getUnresolvedTemplates returns all templates in the cache, in their unresolved
 // state.
 func (ac *AutoConfig) getUnresolvedTemplates() map[string][]integration.Config {
@@ -7,6 +7,8 @@ package autodiscoveryimpl
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"sync"
 
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/configresolver"
@@ -14,8 +16,10 @@ import (
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/listeners"
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/providers/names"
 	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
 	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/option"
 )
 
 // configManager implements the logic of handling additions and removals of
@@ -40,6 +44,16 @@ type configManager interface {
 	// interface apply to only one config.
 	processDelConfigs(configs []integration.Config) integration.ConfigChanges
 
+	// processNamespaceUpdate handles a change of the labels or annotations of
+	// a namespace, matching the templates having selectors against the
+	// services of the namespace again.
+	processNamespaceUpdate(namespace string) integration.ConfigChanges
+
+	// processSecretRefresh handles the rotation of a secret, rescheduling the
+	// configs using it, and the configs resolved from the templates using it,
+	// with its new value.
+	processSecretRefresh(handle string, origins []string) (integration.ConfigChanges, map[checkid.ID]checkid.ID)
+
 	// mapOverLoadedConfigs calls the given function with a map of all
 	// loaded configs (those which have been scheduled but not unscheduled).
 	// The call is made with the manager's lock held, so callers should perform
@@ -59,7 +73,7 @@ type reconcilingConfigManager struct {
 	// updates to this data structure work from the top down:
 	//
 	//  1. update orctiveConfigs / activeServices
-	//  2. update templatesByADID or servicesByADID to match
+	//  2. update templatesByADID, templatesBySelector or servicesByADID to match
 	//  3. update serviceResolutions, generating changes (see reconcileService)
 	//  4. update scheduledConfigs
 	//
@@ -83,6 +97,12 @@ type reconcilingConfigManager struct {
 	// identifiers.  It is an index to activeConfigs.
 	templatesByADID multimap
 
+	// templatesBySelector contains the parsed selectors of the templates
+	// having some, keyed by template digest.  It is an index to
+	// activeConfigs.  Templates with selectors are matched against all the
+	// active services.
+	templatesBySelector map[string][]*integration.Selector
+
 	// servicesByADID catalogs serviceIDs for all services, indexed by their AD
 	// identifiers.  It is an index to activeServices.
 	servicesByADID multimap
@@ -91,6 +111,10 @@ type reconcilingConfigManager struct {
 	// that service: serviceID -> template digest -> resolved config digest.
 	serviceResolutions map[string]map[string]string
 
+	// configResolutions maps the digest of a non-template config to the
+	// digest of the scheduled config, with its secrets resolved.
+	configResolutions map[string]string
+
 	// scheduledConfigs contains an entry for each scheduled config, keyed
 	// by its digest.  This is a mix of resolved templates and non-template
 	// configs.  The returned integration.ConfigChanges from interface
@@ -98,20 +122,27 @@ type reconcilingConfigManager struct {
 	scheduledConfigs map[string]integration.Config
 
 	secretResolver secrets.Component
+
+	// wmeta is used to look up the attributes of the entities behind the
+	// services when matching selectors.
+	wmeta option.Option[workloadmeta.Component]
 }
 
 var _ configManager = &reconcilingConfigManager{}
 
 // newReconcilingConfigManager creates a new, empty reconcilingConfigManager.
-func newReconcilingConfigManager(secretResolver secrets.Component) configManager {
+func newReconcilingConfigManager(secretResolver secrets.Component, wmeta option.Option[workloadmeta.Component]) configManager {
 	return &reconcilingConfigManager{
-		activeConfigs:      map[string]integration.Config{},
-		activeServices:     map[string]serviceAndADIDs{},
-		templatesByADID:    newMultimap(),
-		servicesByADID:     newMultimap(),
-		serviceResolutions: map[string]map[string]string{},
-		scheduledConfigs:   map[string]integration.Config{},
-		secretResolver:     secretResolver,
+		activeConfigs:       map[string]integration.Config{},
+		activeServices:      map[string]serviceAndADIDs{},
+		templatesByADID:     newMultimap(),
+		templatesBySelector: map[string][]*integration.Selector{},
+		servicesByADID:      newMultimap(),
+		serviceResolutions:  map[string]map[string]string{},
+		configResolutions:   map[string]string{},
+		scheduledConfigs:    map[string]integration.Config{},
+		secretResolver:      secretResolver,
+		wmeta:               wmeta,
 	}
 }
 
@@ -197,7 +228,7 @@ func (cm *reconcilingConfigManager) processNewConfig(config integration.Config)
 
 	var changes integration.ConfigChanges
 	if config.IsTemplate() {
-		//  2. update templatesByADID or servicesByADID to match
+		//  2. update templatesByADID, templatesBySelector or servicesByADID to match
 		matchingServices := map[string]struct{}{}
 		for _, adID := range config.ADIdentifiers {
 			cm.templatesByADID.insert(adID, digest)
@@ -206,6 +237,17 @@ func (cm *reconcilingConfigManager) processNewConfig(config integration.Config)
 			}
 		}
 
+		selectors, err := config.Selectors()
+		if err != nil {
+			log.Errorf("Ignoring the selectors of config %q: %s", config.Name, err)
+			errorStats.setConfigError(config.Name, err.Error())
+		} else if len(selectors) > 0 {
+			cm.templatesBySelector[digest] = selectors
+			for svcID := range cm.activeServices {
+				matchingServices[svcID] = struct{}{}
+			}
+		}
+
 		//  3. update serviceResolutions, generating changes
 		for svcID := range matchingServices {
 			changes.Merge(cm.reconcileService(svcID))
@@ -229,6 +271,7 @@ func (cm *reconcilingConfigManager) processNewConfig(config integration.Config)
 		}
 
 		changes.ScheduleConfig(decryptedConfig)
+		cm.configResolutions[digest] = decryptedConfig.Digest()
 	}
 
 	//  4. update scheduledConfigs
@@ -255,7 +298,7 @@ func (cm *reconcilingConfigManager) processDelConfigs(configs []integration.Conf
 
 		var changes integration.ConfigChanges
 		if config.IsTemplate() {
-			//  2. update templatesByADID or servicesByADID to match
+			//  2. update templatesByADID, templatesBySelector or servicesByADID to match
 			matchingServices := map[string]struct{}{}
 			for _, adID := range config.ADIdentifiers {
 				cm.templatesByADID.remove(adID, digest)
@@ -264,6 +307,15 @@ func (cm *reconcilingConfigManager) processDelConfigs(configs []integration.Conf
 				}
 			}
 
+			if _, found := cm.templatesBySelector[digest]; found {
+				delete(cm.templatesBySelector, digest)
+				for svcID, resolutions := range cm.serviceResolutions {
+					if _, found := resolutions[digest]; found {
+						matchingServices[svcID] = struct{}{}
+					}
+				}
+			}
+
 			//  3. update serviceResolutions, generating changes
 			for svcID := range matchingServices {
 				changes.Merge(cm.reconcileService(svcID))
@@ -277,6 +329,7 @@ func (cm *reconcilingConfigManager) processDelConfigs(configs []integration.Conf
 			}
 
 			changes.UnscheduleConfig(config)
+			delete(cm.configResolutions, digest)
 		}
 
 		//  4. update scheduledConfigs
@@ -286,6 +339,84 @@ func (cm *reconcilingConfigManager) processDelConfigs(configs []integration.Conf
 	return allChanges
 }
 
+// processNamespaceUpdate implements configManager#processNamespaceUpdate.
+func (cm *reconcilingConfigManager) processNamespaceUpdate(namespace string) integration.ConfigChanges {
+	cm.m.Lock()
+	defer cm.m.Unlock()
+
+	if len(cm.templatesBySelector) == 0 {
+		return integration.ConfigChanges{}
+	}
+
+	//  3. update serviceResolutions, generating changes
+	var changes integration.ConfigChanges
+	for svcID, svcAndADIDs := range cm.activeServices {
+		pod := newServiceAttributes(svcAndADIDs.svc, cm.wmeta).getPod()
+		if pod == nil || pod.Namespace != namespace {
+			continue
+		}
+		changes.Merge(cm.reconcileService(svcID))
+	}
+
+	//  4. update scheduledConfigs
+	return cm.applyChanges(changes)
+}
+
+// processSecretRefresh implements configManager#processSecretRefresh.
+func (cm *reconcilingConfigManager) processSecretRefresh(handle string, origins []string) (integration.ConfigChanges, map[checkid.ID]checkid.ID) {
+	cm.m.Lock()
+	defer cm.m.Unlock()
+
+	changedIDsOfSecretsWithConfigs := make(map[checkid.ID]checkid.ID)
+
+	var changes integration.ConfigChanges
+	for digest, config := range cm.activeConfigs {
+		if !slices.Contains(origins, config.Name) || !configUsesSecret(config, handle) {
+			continue
+		}
+
+		if config.IsTemplate() {
+			// forget the resolutions of the template so that reconcileService
+			// resolves it again
+			for svcID, resolutions := range cm.serviceResolutions {
+				resolvedDigest, found := resolutions[digest]
+				if !found {
+					continue
+				}
+				log.Infof("Secret '%s' was rotated, rescheduling config '%s' for service %s", handle, config.Name, svcID)
+				changes.UnscheduleConfig(cm.scheduledConfigs[resolvedDigest])
+				delete(resolutions, digest)
+				changes.Merge(cm.reconcileService(svcID))
+			}
+			continue
+		}
+
+		resolvedDigest, found := cm.configResolutions[digest]
+		if !found {
+			continue
+		}
+		decryptedConfig, err := decryptConfig(config, cm.secretResolver)
+		if err != nil {
+			log.Errorf("Unable to resolve secrets for config '%s', keeping the previous check configuration, err: %s", config.Name, err.Error())
+			continue
+		}
+		if decryptedConfig.Digest() == resolvedDigest {
+			continue
+		}
+
+		log.Infof("Secret '%s' was rotated, rescheduling config '%s'", handle, config.Name)
+		changes.UnscheduleConfig(cm.scheduledConfigs[resolvedDigest])
+		changes.ScheduleConfig(decryptedConfig)
+		cm.configResolutions[digest] = decryptedConfig.Digest()
+
+		if config.Provider == names.ClusterChecks {
+			maps.Copy(changedIDsOfSecretsWithConfigs, changedCheckIDs(config, decryptedConfig))
+		}
+	}
+
+	return cm.applyChanges(changes), changedIDsOfSecretsWithConfigs
+}
+
 // mapOverLoadedConfigs implements configManager#mapOverLoadedConfigs.
 func (cm *reconcilingConfigManager) mapOverLoadedConfigs(f func(map[string]integration.Config)) {
 	cm.m.Lock()
@@ -325,9 +456,18 @@ func (cm *reconcilingConfigManager) reconcileService(svcID string) integration.C
 		}
 	}
 
-	// allow the service to filter those templates, unless we are removing
-	// the service, in which case no resolutions are expected.
+	// add the templates whose selectors match the service, and allow the
+	// service to filter those templates, unless we are removing the service,
+	// in which case no resolutions are expected.
 	if svc != nil {
+		if len(cm.templatesBySelector) > 0 {
+			attributes := newServiceAttributes(svc, cm.wmeta)
+			for digest, selectors := range cm.templatesBySelector {
+				if matchesAnySelector(selectors, attributes.get) {
+					expectedResolutions[digest] = cm.activeConfigs[digest]
+				}
+			}
+		}
 		svc.FilterTemplates(expectedResolutions)
 	}
 
@@ -19,7 +19,9 @@ import (
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/listeners"
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/providers/names"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
 	checkid "github.com/DataDog/datadog-agent/pkg/collector/check/id"
+	"github.com/DataDog/datadog-agent/pkg/util/option"
 	"github.com/DataDog/datadog-agent/pkg/util/testutil"
 )
 
@@ -226,6 +228,89 @@ func (suite *ConfigManagerSuite) TestNewClusterCheckWithSecretsScheduled() {
 	require.True(suite.T(), strings.Contains(string(changes.Unschedule[0].Instances[0]), "barDecoded"))
 }
 
+// A non-template config using a rotated secret is rescheduled with its new
+// value, while the configs not using it are left untouched
+func (suite *ConfigManagerSuite) TestSecretRefreshReschedulesNonTemplate() {
+	mockResolver := MockSecretResolver{suite.T(), []mockSecretScenario{
+		{
+			expectedData:   []byte("foo: ENC[bar]"),
+			expectedOrigin: nonTemplateConfigWithSecrets.Name,
+			returnedData:   []byte("foo: barDecoded"),
+			returnedError:  nil,
+		},
+		{
+			expectedData:   []byte{},
+			expectedOrigin: nonTemplateConfigWithSecrets.Name,
+			returnedData:   []byte{},
+			returnedError:  nil,
+		},
+	}}
+	cm := suite.cm.(*reconcilingConfigManager)
+	cm.secretResolver = &mockResolver
+
+	changes, _ := suite.cm.processNewConfig(deepcopy.Copy(nonTemplateConfigWithSecrets).(integration.Config))
+	assertConfigsMatch(suite.T(), changes.Schedule, matchName(nonTemplateConfigWithSecrets.Name))
+	oldDigest := changes.Schedule[0].Digest()
+
+	// the secret is rotated
+	mockResolver.scenarios[0].returnedData = []byte("foo: barRotated")
+
+	// configs not using the handle are not rescheduled
+	changes, _ = suite.cm.processSecretRefresh("baz", []string{nonTemplateConfigWithSecrets.Name})
+	assert.True(suite.T(), changes.IsEmpty())
+	changes, _ = suite.cm.processSecretRefresh("bar", []string{"another-config"})
+	assert.True(suite.T(), changes.IsEmpty())
+
+	changes, _ = suite.cm.processSecretRefresh("bar", []string{nonTemplateConfigWithSecrets.Name})
+	assertConfigsMatch(suite.T(), changes.Unschedule, matchDigest(oldDigest))
+	assertConfigsMatch(suite.T(), changes.Schedule, matchName(nonTemplateConfigWithSecrets.Name))
+	require.True(suite.T(), strings.Contains(string(changes.Schedule[0].Instances[0]), "barRotated"))
+	newDigest := changes.Schedule[0].Digest()
+
+	// refreshing again without a new value does nothing
+	changes, _ = suite.cm.processSecretRefresh("bar", []string{nonTemplateConfigWithSecrets.Name})
+	assert.True(suite.T(), changes.IsEmpty())
+
+	changes = suite.cm.processDelConfigs([]integration.Config{deepcopy.Copy(nonTemplateConfigWithSecrets).(integration.Config)})
+	assertConfigsMatch(suite.T(), changes.Unschedule, matchDigest(newDigest))
+}
+
+// The configs resolved from a template using a rotated secret are rescheduled
+// with its new value
+func (suite *ConfigManagerSuite) TestSecretRefreshReschedulesTemplate() {
+	tpl := integration.Config{Name: "template-with-secrets", LogsConfig: []byte("source: ENC[bar]"), ADIdentifiers: []string{"my-service"}}
+	mockResolver := MockSecretResolver{suite.T(), []mockSecretScenario{
+		{
+			expectedData:   []byte("source: ENC[bar]\n"),
+			expectedOrigin: tpl.Name,
+			returnedData:   []byte("source: barDecoded\n"),
+			returnedError:  nil,
+		},
+		{
+			expectedData:   []byte{},
+			expectedOrigin: tpl.Name,
+			returnedData:   []byte{},
+			returnedError:  nil,
+		},
+	}}
+	cm := suite.cm.(*reconcilingConfigManager)
+	cm.secretResolver = &mockResolver
+
+	suite.cm.processNewConfig(tpl)
+	changes := suite.cm.processNewService(myService)
+	assertConfigsMatch(suite.T(), changes.Schedule, matchAll(matchName(tpl.Name), matchLogsConfig("source: barDecoded\n")))
+
+	// the secret is rotated
+	mockResolver.scenarios[0].returnedData = []byte("source: barRotated\n")
+
+	changes, _ = suite.cm.processSecretRefresh("bar", []string{tpl.Name})
+	assertConfigsMatch(suite.T(), changes.Unschedule, matchAll(matchName(tpl.Name), matchLogsConfig("source: barDecoded\n")))
+	assertConfigsMatch(suite.T(), changes.Schedule, matchAll(matchName(tpl.Name), matchLogsConfig("source: barRotated\n")))
+
+	changes = suite.cm.processDelService(myService)
+	assertConfigsMatch(suite.T(), changes.Unschedule, matchAll(matchName(tpl.Name), matchLogsConfig("source: barRotated\n")))
+}
+
 // A new template config is not scheduled when there is no matching service, and
 // not unscheduled when removed
 func (suite *ConfigManagerSuite) TestNewTemplateNotScheduled() {
@@ -542,7 +627,7 @@ func TestReconcilingConfigManagement(t *testing.T) {
 	mockResolver := MockSecretResolver{}
 	suite.Run(t, &ReconcilingConfigManagerSuite{
 		ConfigManagerSuite{factory: func() configManager {
-			return newReconcilingConfigManager(&mockResolver)
+			return newReconcilingConfigManager(&mockResolver, option.None[workloadmeta.Component]())
 		}},
 	})
 }
@@ -6,7 +6,9 @@
 package autodiscoveryimpl
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/comp/core/secrets"
@@ -54,3 +56,42 @@ func decryptConfig(conf integration.Config, secretResolver secrets.Component) (i
 
 	return conf, nil
 }
+
+// configUsesSecret returns whether the config contains the given secret
+// handle.  Templates also use the `k8s_secret@<namespace>/<name>/<key>`
+// handles of their %%secret_<name>/<key>%% template variables, resolved in the
+// namespace of each service.
+func configUsesSecret(conf integration.Config, handle string) bool {
+	patterns := [][]byte{[]byte("ENC[" + handle + "]")}
+	if conf.IsTemplate() {
+		patterns = append(patterns, secretTemplateVariables(handle)...)
+	}
+
+	data := append([]integration.Data{conf.InitConfig, conf.MetricConfig, conf.LogsConfig}, conf.Instances...)
+	for _, d := range data {
+		for _, pattern := range patterns {
+			if bytes.Contains(d, pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// secretTemplateVariables returns the %%secret_<name>/<key>%% template
+// variables resolving to the handle, with and without a default value, or nil
+// if the handle is not a kubernetes secret.
+func secretTemplateVariables(handle string) [][]byte {
+	ref, found := strings.CutPrefix(handle, "k8s_secret@")
+	if !found {
+		return nil
+	}
+	_, nameAndKey, found := strings.Cut(ref, "/")
+	if !found {
+		return nil
+	}
+	return [][]byte{
+		[]byte("%%secret_" + nameAndKey + "%%"),
+		[]byte("%%secret_" + nameAndKey + "|"),
+	}
+}
@@ -55,6 +55,9 @@ func (m *MockSecretResolver) Resolve(data []byte, origin string) ([]byte, error)
 func (m *MockSecretResolver) SubscribeToChanges(_ secrets.SecretChangeCallback) {
 }
 
+func (m *MockSecretResolver) SubscribeToRefresh(_ secrets.SecretRefreshCallback) {
+}
+
 func (m *MockSecretResolver) Refresh() (string, error) {
 	return "", nil
 }
@@ -144,3 +147,36 @@ func TestSkipSecretResolve(t *testing.T) {
 
 	assert.True(t, mockResolve.haveAllScenariosNotCalled())
 }
+
+func TestConfigUsesSecret(t *testing.T) {
+	tpl := integration.Config{
+		Name:          "redis",
+		ADIdentifiers: []string{"redis"},
+		Instances:     []integration.Data{integration.Data("password: %%secret_redis-auth/password%%\nuser: %%secret_redis-auth/user|default%%\ntoken: ENC[token]")},
+	}
+	nonTemplate := integration.Config{
+		Name:      "redis",
+		Instances: []integration.Data{integration.Data("password: ENC[k8s_secret@payments/redis-auth/password]")},
+	}
+
+	tests := []struct {
+		name     string
+		config   integration.Config
+		handle   string
+		expected bool
+	}{
+		{name: "template with the handle", config: tpl, handle: "token", expected: true},
+		{name: "template with another handle", config: tpl, handle: "other", expected: false},
+		{name: "template variable", config: tpl, handle: "k8s_secret@payments/redis-auth/password", expected: true},
+		{name: "template variable with a default", config: tpl, handle: "k8s_secret@shop/redis-auth/user", expected: true},
+		{name: "template variable of another key", config: tpl, handle: "k8s_secret@payments/redis-auth/pass", expected: false},
+		{name: "template variable of another secret", config: tpl, handle: "k8s_secret@payments/postgres-auth/password", expected: false},
+		{name: "non-template config with the handle", config: nonTemplate, handle: "k8s_secret@payments/redis-auth/password", expected: true},
+		{name: "non-template config with another namespace", config: nonTemplate, handle: "k8s_secret@shop/redis-auth/password", expected: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, configUsesSecret(test.config, test.handle))
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package autodiscoveryimpl
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/listeners"
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta/collectors/util"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
+	"github.com/DataDog/datadog-agent/pkg/util/option"
+)
+
+// serviceAttributes returns the attributes of a service matched by template
+// selectors.  The workloadmeta entities behind the service are looked up
+// lazily, the first time an attribute needs them.
+type serviceAttributes struct {
+	svc   listeners.Service
+	wmeta option.Option[workloadmeta.Component]
+
+	entitiesResolved bool
+	container        *workloadmeta.Container
+	pod              *workloadmeta.KubernetesPod
+
+	namespaceResolved bool
+	namespace         *workloadmeta.KubernetesMetadata
+}
+
+func newServiceAttributes(svc listeners.Service, wmeta option.Option[workloadmeta.Component]) *serviceAttributes {
+	return &serviceAttributes{svc: svc, wmeta: wmeta}
+}
+
+// matchesAnySelector returns whether the attributes meet at least one of the selectors
+func matchesAnySelector(selectors []*integration.Selector, getAttribute func(string) ([]string, bool)) bool {
+	for _, selector := range selectors {
+		if selector.Matches(getAttribute) {
+			return true
+		}
+	}
+	return false
+}
+
+// get returns the values of an attribute, see integration.SelectorAttributes
+func (a *serviceAttributes) get(attribute string) ([]string, bool) {
+	switch attribute {
+	case "service.id":
+		return stringAttribute(a.svc.GetServiceID())
+	case "service.ad_identifiers":
+		adIDs := a.svc.GetADIdentifiers()
+		return adIDs, len(adIDs) > 0
+	case "service.hostname":
+		hostname, err := a.svc.GetHostname()
+		if err != nil {
+			return nil, false
+		}
+		return stringAttribute(hostname)
+	}
+	if key, ok := strings.CutPrefix(attribute, "tags."); ok {
+		return a.tag(key)
+	}
+	if strings.HasPrefix(attribute, "container.") {
+		return a.containerAttribute(attribute)
+	}
+	if strings.HasPrefix(attribute, "pod.") {
+		return a.podAttribute(attribute)
+	}
+	if strings.HasPrefix(attribute, "namespace.") {
+		return a.namespaceAttribute(attribute)
+	}
+	return nil, false
+}
+
+func (a *serviceAttributes) tag(key string) ([]string, bool) {
+	tags, err := a.svc.GetTags()
+	if err != nil {
+		return nil, false
+	}
+	var values []string
+	for _, tag := range tags {
+		if value, ok := strings.CutPrefix(tag, key+":"); ok {
+			values = append(values, value)
+		}
+	}
+	return values, len(values) > 0
+}
+
+func (a *serviceAttributes) containerAttribute(attribute string) ([]string, bool) {
+	container := a.getContainer()
+	if container == nil {
+		return nil, false
+	}
+	switch attribute {
+	case "container.id":
+		return stringAttribute(container.ID)
+	case "container.name":
+		return stringAttribute(container.Name)
+	case "container.runtime":
+		return stringAttribute(string(container.Runtime))
+	case "container.image":
+		return stringAttribute(container.Image.RawName)
+	case "container.image.name":
+		return stringAttribute(container.Image.Name)
+	case "container.image.short_name":
+		return stringAttribute(container.Image.ShortName)
+	case "container.image.tag":
+		return stringAttribute(container.Image.Tag)
+	}
+	if key, ok := strings.CutPrefix(attribute, "container.labels."); ok {
+		return mapAttribute(container.Labels, key)
+	}
+	return nil, false
+}
+
+func (a *serviceAttributes) podAttribute(attribute string) ([]string, bool) {
+	pod := a.getPod()
+	if pod == nil {
+		return nil, false
+	}
+	switch attribute {
+	case "pod.name":
+		return stringAttribute(pod.Name)
+	case "pod.namespace":
+		return stringAttribute(pod.Namespace)
+	}
+	if key, ok := strings.CutPrefix(attribute, "pod.labels."); ok {
+		return mapAttribute(pod.Labels, key)
+	}
+	if key, ok := strings.CutPrefix(attribute, "pod.annotations."); ok {
+		return mapAttribute(pod.Annotations, key)
+	}
+	return nil, false
+}
+
+func (a *serviceAttributes) namespaceAttribute(attribute string) ([]string, bool) {
+	pod := a.getPod()
+	if pod == nil {
+		return nil, false
+	}
+
+	// the namespace metadata is the most up to date, as templates are matched
+	// again when it changes, while the copy attached to pods is only updated
+	// with the pods, when the collection of namespace labels or annotations as
+	// tags is configured
+	labels, annotations := pod.NamespaceLabels, pod.NamespaceAnnotations
+	if namespace := a.getNamespace(pod.Namespace); namespace != nil {
+		labels, annotations = namespace.Labels, namespace.Annotations
+	}
+
+	if key, ok := strings.CutPrefix(attribute, "namespace.labels."); ok {
+		return mapAttribute(labels, key)
+	}
+	if key, ok := strings.CutPrefix(attribute, "namespace.annotations."); ok {
+		return mapAttribute(annotations, key)
+	}
+	return nil, false
+}
+
+func (a *serviceAttributes) getContainer() *workloadmeta.Container {
+	a.resolveEntities()
+	return a.container
+}
+
+func (a *serviceAttributes) getPod() *workloadmeta.KubernetesPod {
+	a.resolveEntities()
+	return a.pod
+}
+
+// resolveEntities looks up the container or the pod behind the service from
+// its service ID, and the pod of the container if any.
+func (a *serviceAttributes) resolveEntities() {
+	if a.entitiesResolved {
+		return
+	}
+	a.entitiesResolved = true
+
+	wmeta, ok := a.wmeta.Get()
+	if !ok {
+		return
+	}
+
+	prefix, id := containers.SplitEntityName(a.svc.GetServiceID())
+	if id == "" {
+		return
+	}
+
+	if prefix == kubelet.KubePodEntityName {
+		if pod, err := wmeta.GetKubernetesPod(id); err == nil {
+			a.pod = pod
+		}
+		return
+	}
+
+	container, err := wmeta.GetContainer(id)
+	if err != nil {
+		return
+	}
+	a.container = container
+	if pod, err := wmeta.GetKubernetesPodForContainer(id); err == nil {
+		a.pod = pod
+	}
+}
+
+func (a *serviceAttributes) getNamespace(name string) *workloadmeta.KubernetesMetadata {
+	if a.namespaceResolved {
+		return a.namespace
+	}
+	a.namespaceResolved = true
+
+	wmeta, ok := a.wmeta.Get()
+	if !ok || name == "" {
+		return nil
+	}
+	namespace, err := wmeta.GetKubernetesMetadata(util.GenerateKubeMetadataEntityID("", "namespaces", "", name))
+	if err == nil {
+		a.namespace = namespace
+	}
+	return a.namespace
+}
+
+func stringAttribute(value string) ([]string, bool) {
+	if value == "" {
+		return nil, false
+	}
+	return []string{value}, true
+}
+
+func mapAttribute(m map[string]string, key string) ([]string, bool) {
+	value, found := m[key]
+	if !found {
+		return nil, false
+	}
+	return []string{value}, true
+}
@@ -0,0 +1,235 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package autodiscoveryimpl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	workloadmetafxmock "github.com/DataDog/datadog-agent/comp/core/workloadmeta/fx-mock"
+	workloadmetamock "github.com/DataDog/datadog-agent/comp/core/workloadmeta/mock"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+	"github.com/DataDog/datadog-agent/pkg/util/option"
+)
+
+func newSelectorTestStore(t *testing.T) workloadmetamock.Mock {
+	store := fxutil.Test[workloadmetamock.Mock](t, fx.Options(
+		config.MockModule(),
+		fx.Provide(func() log.Component { return logmock.New(t) }),
+		workloadmetafxmock.MockModule(workloadmeta.NewParams()),
+	))
+
+	store.Set(&workloadmeta.KubernetesPod{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindKubernetesPod, ID: "pod-uid"},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:      "redis-0",
+			Namespace: "payments",
+			Labels:    map[string]string{"app": "redis"},
+		},
+	})
+	store.Set(&workloadmeta.KubernetesMetadata{
+		EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindKubernetesMetadata, ID: "/namespaces//payments"},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name:   "payments",
+			Labels: map[string]string{"team": "payments"},
+		},
+	})
+	store.Set(&workloadmeta.Container{
+		EntityID:   workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: "redis"},
+		EntityMeta: workloadmeta.EntityMeta{Name: "redis"},
+		Image: workloadmeta.ContainerImage{
+			RawName:   "docker.io/library/redis:7",
+			Name:      "docker.io/library/redis",
+			ShortName: "redis",
+			Tag:       "7",
+		},
+		Runtime: workloadmeta.ContainerRuntimeContainerd,
+		Owner:   &workloadmeta.EntityID{Kind: workloadmeta.KindKubernetesPod, ID: "pod-uid"},
+	})
+	store.Set(&workloadmeta.Container{
+		EntityID:   workloadmeta.EntityID{Kind: workloadmeta.KindContainer, ID: "nginx"},
+		EntityMeta: workloadmeta.EntityMeta{Name: "nginx"},
+		Image: workloadmeta.ContainerImage{
+			RawName:   "docker.io/library/nginx:latest",
+			Name:      "docker.io/library/nginx",
+			ShortName: "nginx",
+			Tag:       "latest",
+		},
+		Runtime: workloadmeta.ContainerRuntimeContainerd,
+	})
+	return store
+}
+
+func TestServiceAttributes(t *testing.T) {
+	store := newSelectorTestStore(t)
+	wmeta := option.New[workloadmeta.Component](store)
+
+	tests := []struct {
+		name       string
+		svc        *dummyService
+		attribute  string
+		wantValues []string
+		wantFound  bool
+	}{
+		{
+			name:       "service attribute",
+			svc:        &dummyService{ID: "containerd://redis", ADIdentifiers: []string{"redis", "docker.io/library/redis"}},
+			attribute:  "service.ad_identifiers",
+			wantValues: []string{"redis", "docker.io/library/redis"},
+			wantFound:  true,
+		},
+		{
+			name:       "container image",
+			svc:        &dummyService{ID: "containerd://redis"},
+			attribute:  "container.image",
+			wantValues: []string{"docker.io/library/redis:7"},
+			wantFound:  true,
+		},
+		{
+			name:       "pod of a container",
+			svc:        &dummyService{ID: "containerd://redis"},
+			attribute:  "pod.labels.app",
+			wantValues: []string{"redis"},
+			wantFound:  true,
+		},
+		{
+			name:       "namespace of the pod of a container",
+			svc:        &dummyService{ID: "containerd://redis"},
+			attribute:  "namespace.labels.team",
+			wantValues: []string{"payments"},
+			wantFound:  true,
+		},
+		{
+			name:       "pod service",
+			svc:        &dummyService{ID: "kubernetes_pod://pod-uid"},
+			attribute:  "pod.name",
+			wantValues: []string{"redis-0"},
+			wantFound:  true,
+		},
+		{
+			name:      "container attribute of a pod service",
+			svc:       &dummyService{ID: "kubernetes_pod://pod-uid"},
+			attribute: "container.image",
+		},
+		{
+			name:      "container without pod",
+			svc:       &dummyService{ID: "containerd://nginx"},
+			attribute: "pod.name",
+		},
+		{
+			name:      "missing label",
+			svc:       &dummyService{ID: "containerd://redis"},
+			attribute: "pod.labels.missing",
+		},
+		{
+			name:      "unknown entity",
+			svc:       &dummyService{ID: "containerd://unknown"},
+			attribute: "container.name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, found := newServiceAttributes(tt.svc, wmeta).get(tt.attribute)
+			assert.Equal(t, tt.wantFound, found)
+			assert.Equal(t, tt.wantValues, values)
+		})
+	}
+}
+
+func TestSelectorTemplates(t *testing.T) {
+	store := newSelectorTestStore(t)
+	cm := newReconcilingConfigManager(&MockSecretResolver{}, option.New[workloadmeta.Component](store))
+
+	redisSvc := &dummyService{ID: "containerd://redis", ADIdentifiers: []string{"redis"}}
+	nginxSvc := &dummyService{ID: "containerd://nginx", ADIdentifiers: []string{"nginx"}}
+	cm.processNewService(redisSvc)
+
+	tpl := integration.Config{
+		Name:       "redisdb",
+		LogsConfig: []byte("source: redis"),
+		AdvancedADIdentifiers: []integration.AdvancedADIdentifier{
+			{Selector: `container.image matches "*/redis:*" && namespace.labels.team == payments`},
+		},
+	}
+
+	// templates are matched against the existing services
+	changes, _ := cm.processNewConfig(tpl)
+	assertConfigsMatch(t, changes.Schedule, matchAll(matchName("redisdb"), matchSvc("containerd://redis")))
+	assertConfigsMatch(t, changes.Unschedule)
+
+	// and against the new ones
+	changes = cm.processNewService(nginxSvc)
+	assertConfigsMatch(t, changes.Schedule)
+	assertConfigsMatch(t, changes.Unschedule)
+
+	changes = cm.processDelConfigs([]integration.Config{tpl})
+	assertConfigsMatch(t, changes.Schedule)
+	assertConfigsMatch(t, changes.Unschedule, matchAll(matchName("redisdb"), matchSvc("containerd://redis")))
+	assertLoadedConfigsMatch(t, cm)
+}
+
+func TestSelectorTemplatesNamespaceUpdate(t *testing.T) {
+	store := newSelectorTestStore(t)
+	cm := newReconcilingConfigManager(&MockSecretResolver{}, option.New[workloadmeta.Component](store))
+	cm.processNewService(&dummyService{ID: "containerd://redis", ADIdentifiers: []string{"redis"}})
+
+	tpl := integration.Config{
+		Name:       "redisdb",
+		LogsConfig: []byte("source: redis"),
+		AdvancedADIdentifiers: []integration.AdvancedADIdentifier{
+			{Selector: `namespace.labels.tier == critical`},
+		},
+	}
+	changes, _ := cm.processNewConfig(tpl)
+	assertConfigsMatch(t, changes.Schedule)
+
+	setNamespaceLabels := func(labels map[string]string) {
+		store.Set(&workloadmeta.KubernetesMetadata{
+			EntityID: workloadmeta.EntityID{Kind: workloadmeta.KindKubernetesMetadata, ID: "/namespaces//payments"},
+			EntityMeta: workloadmeta.EntityMeta{
+				Name:   "payments",
+				Labels: labels,
+			},
+		})
+	}
+
+	// the services of other namespaces are left untouched
+	setNamespaceLabels(map[string]string{"team": "payments", "tier": "critical"})
+	changes = cm.processNamespaceUpdate("default")
+	assertConfigsMatch(t, changes.Schedule)
+	assertConfigsMatch(t, changes.Unschedule)
+
+	changes = cm.processNamespaceUpdate("payments")
+	assertConfigsMatch(t, changes.Schedule, matchAll(matchName("redisdb"), matchSvc("containerd://redis")))
+	assertConfigsMatch(t, changes.Unschedule)
+
+	setNamespaceLabels(map[string]string{"team": "payments"})
+	changes = cm.processNamespaceUpdate("payments")
+	assertConfigsMatch(t, changes.Schedule)
+	assertConfigsMatch(t, changes.Unschedule, matchAll(matchName("redisdb"), matchSvc("containerd://redis")))
+}
+
+func TestSelectorTemplatesInvalid(t *testing.T) {
+	cm := newReconcilingConfigManager(&MockSecretResolver{}, option.None[workloadmeta.Component]())
+	cm.processNewService(&dummyService{ID: "containerd://redis", ADIdentifiers: []string{"redis"}})
+
+	tpl := integration.Config{
+		Name: "invalid-selector",
+		AdvancedADIdentifiers: []integration.AdvancedADIdentifier{
+			{Selector: `container.image ~ redis`},
+		},
+	}
+	changes, _ := cm.processNewConfig(tpl)
+	assertConfigsMatch(t, changes.Schedule)
+	assert.Contains(t, GetConfigErrors()["invalid-selector"], `unknown operator "~"`)
+}
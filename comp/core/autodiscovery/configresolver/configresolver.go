@@ -34,13 +34,17 @@ import (
 type variableGetter func(key string, svc listeners.Service) (string, error)
 
 var templateVariables = map[string]variableGetter{
-	"host":     getHost,
-	"pid":      getPid,
-	"port":     getPort,
-	"hostname": getHostname,
-	"env":      getEnvvar,
-	"extra":    getAdditionalTplVariables,
-	"kube":     getAdditionalTplVariables,
+	"host":         getHost,
+	"pid":          getPid,
+	"port":         getPort,
+	"hostname":     getHostname,
+	"env":          getEnvvar,
+	"extra":        getAdditionalTplVariables,
+	"kube":         getAdditionalTplVariables,
+	"label":        getLabel,
+	"annotation":   getAnnotation,
+	"containerenv": getContainerEnvvar,
+	"secret":       getSecret,
 }
 
 // NoServiceError represents an error that indicates that there's a problem with a service
@@ -384,10 +388,22 @@ func resolveStringWithTemplateVars(in string, svc listeners.Service) (out interf
 	return resolvedStringWithIPv6, err
 }
 
-var varPattern = regexp.MustCompile(`‰(.+?)(?:_(.+?))?‰`)
+// varPattern matches the `‰var_param|default+N‰` patterns, where the param, the
+// default value and the arithmetic operation are optional.
+var varPattern = regexp.MustCompile(`‰(.+?)(?:_(.+?))?(?:\|(.*?))?(?:([+-])(\d+))?‰`)
+
+// arithmeticVariables are the template variables resolving to integers, which
+// support the `+N` and `-N` operations. For the other variables, the operation
+// is part of their param or default value, e.g. `‰label_tier-1‰`.
+var arithmeticVariables = map[string]bool{
+	"port": true,
+	"pid":  true,
+}
 
 // resolveStringWithAdHocTemplateVars takes a string as input and replaces all the `‰var_param‰` patterns by the value returned by the appropriate variable getter.
 // The variable getters are passed as last parameter.
+// When the pattern has a `|default` suffix, the default value is used if the variable getter fails.
+// When the pattern of an integer variable ends with `+N` or `-N`, e.g. `‰port+1‰`, N is added to or subtracted from the value.
 // If the input string is composed of *only* a `‰var_param‰` pattern and the result of the substitution is a boolean or a number, then the function returns a boolean or a number instead of a string.
 func resolveStringWithAdHocTemplateVars(in string, svc listeners.Service, templateVariables map[string]variableGetter) (out interface{}, err error) {
 	varIndexes := varPattern.FindAllStringSubmatchIndex(in, -1)
@@ -404,24 +420,44 @@ func resolveStringWithAdHocTemplateVars(in string, svc listeners.Service, templa
 			sb.WriteString(in[varIndexes[i-1][1]:varIndexes[i][0]])
 		}
 
-		varName := in[varIndexes[i][2]:varIndexes[i][3]]
+		idx := varIndexes[i]
+		if idx[8] != -1 && !arithmeticVariables[in[idx[2]:idx[3]]] {
+			idx = withoutArithmetic(idx)
+		}
+
+		varName := in[idx[2]:idx[3]]
 		varKey := ""
-		if varIndexes[i][4] != -1 {
-			varKey = in[varIndexes[i][4]:varIndexes[i][5]]
+		if idx[4] != -1 {
+			varKey = in[idx[4]:idx[5]]
 		}
 
 		if f, found := templateVariables[varName]; found {
+			if idx[8] != -1 && idx[4] != -1 && idx[6] == -1 {
+				// a param like `http-2` is a name rather than an operation when it exists
+				if resolvedVar, e := f(in[idx[4]:idx[11]], svc); e == nil {
+					sb.WriteString(resolvedVar)
+					continue
+				}
+			}
 			resolvedVar, e := f(varKey, svc)
 			if e != nil {
-				err = e
+				var noServiceErr *NoServiceError
+				if idx[6] != -1 && !errors.As(e, &noServiceErr) {
+					resolvedVar = in[idx[6]:idx[7]]
+				} else {
+					err = e
+				}
+			}
+			if idx[8] != -1 {
+				resolvedVar = applyArithmetic(resolvedVar, in[idx[8]:idx[9]], in[idx[10]:idx[11]])
 			}
 			sb.WriteString(resolvedVar)
 		} else {
-			endTagIdx := varIndexes[i][5]
+			endTagIdx := idx[5]
 			if endTagIdx == -1 {
-				endTagIdx = varIndexes[i][3]
+				endTagIdx = idx[3]
 			}
-			err := fmt.Errorf("invalid %%%%%s%%%% tag", in[varIndexes[i][2]:endTagIdx])
+			err := fmt.Errorf("invalid %%%%%s%%%% tag", in[idx[2]:endTagIdx])
 			if svc != nil {
 				err = fmt.Errorf("unable to add tags for service '%s', err: %w", svc.GetServiceID(), err)
 			}
@@ -447,6 +483,41 @@ func resolveStringWithAdHocTemplateVars(in string, svc listeners.Service, templa
 	return
 }
 
+// withoutArithmetic returns the submatch indexes of a varPattern match with the
+// arithmetic operation merged into the default value, the param or the name,
+// whichever precedes it.
+func withoutArithmetic(idx []int) []int {
+	merged := append([]int(nil), idx...)
+	end := merged[11]
+	switch {
+	case merged[6] != -1:
+		merged[7] = end
+	case merged[4] != -1:
+		merged[5] = end
+	default:
+		merged[3] = end
+	}
+	merged[8], merged[9], merged[10], merged[11] = -1, -1, -1, -1
+	return merged
+}
+
+// applyArithmetic adds the operand to or subtracts it from the value if it is
+// an integer.  Otherwise, the operation is kept as is after the value.
+func applyArithmetic(value string, operator string, operand string) string {
+	v, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return value + operator + operand
+	}
+	n, err := strconv.ParseInt(operand, 10, 64)
+	if err != nil {
+		return value + operator + operand
+	}
+	if operator == "-" {
+		n = -n
+	}
+	return strconv.FormatInt(v+n, 10)
+}
+
 func tagsAdder(tags []string) func(interface{}) error {
 	return func(tree interface{}) error {
 		if len(tags) == 0 {
@@ -658,3 +729,64 @@ func allowEnvVar(envVar string) bool {
 		return strings.EqualFold(env, envVar)
 	})
 }
+
+// getLabel returns the value of a label of the pod or container of the service
+func getLabel(key string, svc listeners.Service) (string, error) {
+	return getServiceMetadata("label", key, svc, listeners.MetadataService.GetLabel)
+}
+
+// getAnnotation returns the value of an annotation of the pod or container of the service
+func getAnnotation(key string, svc listeners.Service) (string, error) {
+	return getServiceMetadata("annotation", key, svc, listeners.MetadataService.GetAnnotation)
+}
+
+// getContainerEnvvar returns the value of an environment variable from the
+// container spec of the service, unlike getEnvvar which looks it up in the
+// environment of the agent
+func getContainerEnvvar(name string, svc listeners.Service) (string, error) {
+	return getServiceMetadata("containerenv", name, svc, listeners.MetadataService.GetContainerEnv)
+}
+
+func getServiceMetadata(tplVar string, key string, svc listeners.Service, lookup func(listeners.MetadataService, string) (string, bool)) (string, error) {
+	if svc == nil {
+		return "", newNoServiceError(fmt.Sprintf("No service. %%%%%s_*%%%% is not allowed", tplVar))
+	}
+	if key == "" {
+		return "", fmt.Errorf("%s name is missing, skipping service %s", tplVar, svc.GetServiceID())
+	}
+
+	metadataSvc, ok := svc.(listeners.MetadataService)
+	if !ok {
+		return "", fmt.Errorf("%%%%%s_*%%%% is not supported by service %s", tplVar, svc.GetServiceID())
+	}
+
+	value, found := lookup(metadataSvc, key)
+	if !found {
+		return "", fmt.Errorf("%s %s not found, skipping service %s", tplVar, key, svc.GetServiceID())
+	}
+	return value, nil
+}
+
+// getSecret returns a secret handle referencing a key of a kubernetes secret
+// in the namespace of the service, formatted as `<name>/<key>`.  The handle is
+// resolved by the secrets backend, which must support the `k8s_secret` prefix
+// (see cmd/secrethelper), like the other `ENC[]` handles of the config.
+func getSecret(tplVar string, svc listeners.Service) (string, error) {
+	if svc == nil {
+		return "", newNoServiceError("No service. %%%%secret_*%%%% is not allowed")
+	}
+
+	name, key, ok := strings.Cut(tplVar, "/")
+	if !ok || name == "" || key == "" || strings.Contains(key, "/") {
+		return "", fmt.Errorf("invalid secret %q, expected <name>/<key>, skipping service %s", tplVar, svc.GetServiceID())
+	}
+
+	// secrets are only looked up in the namespace of the service, so that
+	// a workload can't reference the secrets of other namespaces
+	namespace, err := svc.GetExtraConfig("namespace")
+	if err != nil || namespace == "" {
+		return "", fmt.Errorf("failed to get the namespace of service %s to resolve secret %s", svc.GetServiceID(), tplVar)
+	}
+
+	return fmt.Sprintf("ENC[k8s_secret@%s/%s/%s]", namespace, name, key), nil
+}
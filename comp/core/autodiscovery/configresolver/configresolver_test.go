@@ -98,6 +98,32 @@ func (s *dummyService) GetExtraConfig(key string) (string, error) {
 func (s *dummyService) FilterTemplates(map[string]integration.Config) {
 }
 
+// dummyMetadataService is a dummyService exposing the metadata of its workload
+type dummyMetadataService struct {
+	dummyService
+	Labels       map[string]string
+	Annotations  map[string]string
+	ContainerEnv map[string]string
+}
+
+// GetLabel returns a dummy label
+func (s *dummyMetadataService) GetLabel(key string) (string, bool) {
+	value, found := s.Labels[key]
+	return value, found
+}
+
+// GetAnnotation returns a dummy annotation
+func (s *dummyMetadataService) GetAnnotation(key string) (string, bool) {
+	value, found := s.Annotations[key]
+	return value, found
+}
+
+// GetContainerEnv returns a dummy container environment variable
+func (s *dummyMetadataService) GetContainerEnv(name string) (string, bool) {
+	value, found := s.ContainerEnv[name]
+	return value, found
+}
+
 func TestGetFallbackHost(t *testing.T) {
 	ip, err := getFallbackHost(map[string]string{"bridge": "172.17.0.1"})
 	assert.Equal(t, "172.17.0.1", ip)
@@ -771,6 +797,171 @@ func TestResolve(t *testing.T) {
 				ServiceID:     "a5901276aed1",
 			},
 		},
+		//// %%label_*%%, %%annotation_*%% and %%containerenv_*%% testing
+		{
+			testName: "%%label_*%%, %%annotation_*%% and %%containerenv_*%%",
+			svc: &dummyMetadataService{
+				dummyService: dummyService{
+					ID:            "a5901276aed1",
+					ADIdentifiers: []string{"redis"},
+				},
+				Labels:       map[string]string{"app": "redis", "app.kubernetes.io/version": "7"},
+				Annotations:  map[string]string{"team": "payments"},
+				ContainerEnv: map[string]string{"REDIS_PORT": "6380"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("app: %%label_app%%\nversion: \"%%label_app.kubernetes.io/version%%\"\nteam: %%annotation_team%%\nport: %%containerenv_REDIS_PORT%%")},
+			},
+			out: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("app: redis\nport: 6380\ntags:\n- foo:bar\nteam: payments\nversion: 7\n")},
+				ServiceID:     "a5901276aed1",
+			},
+		},
+		{
+			testName: "%%label_*%% not found",
+			svc: &dummyMetadataService{
+				dummyService: dummyService{
+					ID:            "a5901276aed1",
+					ADIdentifiers: []string{"redis"},
+				},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("app: %%label_app%%")},
+			},
+			errorString: "label app not found, skipping service a5901276aed1",
+		},
+		{
+			testName: "%%label_*%% not supported by service",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("app: %%label_app%%")},
+			},
+			errorString: "%%label_*%% is not supported by service a5901276aed1",
+		},
+		//// default values testing
+		{
+			testName: "default values",
+			svc: &dummyMetadataService{
+				dummyService: dummyService{
+					ID:            "a5901276aed1",
+					ADIdentifiers: []string{"redis"},
+				},
+				Labels: map[string]string{"app": "redis"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("app: %%label_app|default%%\ntier: %%label_tier|backend%%\nport: %%port|6379%%\nempty: \"%%annotation_team|%%\"\nenv: %%env_test_envvar_not_set|fallback_value%%")},
+			},
+			out: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("app: redis\nempty: \"\"\nenv: fallback_value\nport: 6379\ntags:\n- foo:bar\ntier: backend\n")},
+				ServiceID:     "a5901276aed1",
+			},
+		},
+		//// arithmetic testing
+		{
+			testName: "%%port%% arithmetic",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				Hosts:         map[string]string{"bridge": "127.0.0.1"},
+				Ports:         newFakeContainerPorts(),
+				Hostname:      "redis",
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("port: %%port+1%%\nfoo: %%port_foo-1%%\nurl: http://%%host%%:%%port_bar+10%%/metrics\nsentinel: %%port_missing|26378+1%%\nname: %%hostname%%-1\nsuffix: %%port%%+1")},
+			},
+			out: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("foo: 0\nname: redis-1\nport: 4\nsentinel: 26379\nsuffix: 3+1\ntags:\n- foo:bar\nurl: http://127.0.0.1:12/metrics\n")},
+				ServiceID:     "a5901276aed1",
+			},
+		},
+		{
+			testName: "non-integer variables keep their -N suffix",
+			svc: &dummyMetadataService{
+				dummyService: dummyService{
+					ID:            "a5901276aed1",
+					ADIdentifiers: []string{"redis"},
+					Ports:         []listeners.ContainerPort{{Port: 6379, Name: "redis"}, {Port: 8080, Name: "http-2"}},
+				},
+				Labels: map[string]string{"tier-1": "frontend"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("tier: %%label_tier-1%%\nzone: %%label_zone|eu-1%%\nhttp: %%port_http-2%%\nredis: %%port_redis-1%%")},
+			},
+			out: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("http: 8080\nredis: 6378\ntags:\n- foo:bar\ntier: frontend\nzone: eu-1\n")},
+				ServiceID:     "a5901276aed1",
+			},
+		},
+		//// %%secret_*%% testing
+		{
+			testName: "%%secret_*%%",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				ExtraConfig:   map[string]string{"namespace": "payments"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("password: %%secret_redis-auth/password%%")},
+			},
+			out: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("password: ENC[k8s_secret@payments/redis-auth/password]\ntags:\n- foo:bar\n")},
+				ServiceID:     "a5901276aed1",
+			},
+		},
+		{
+			testName: "%%secret_*%% from another namespace",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+				ExtraConfig:   map[string]string{"namespace": "payments"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("password: %%secret_billing/redis-auth/password%%")},
+			},
+			errorString: `invalid secret "billing/redis-auth/password", expected <name>/<key>, skipping service a5901276aed1`,
+		},
+		{
+			testName: "%%secret_*%% without namespace",
+			svc: &dummyService{
+				ID:            "a5901276aed1",
+				ADIdentifiers: []string{"redis"},
+			},
+			tpl: integration.Config{
+				Name:          "redis",
+				ADIdentifiers: []string{"redis"},
+				Instances:     []integration.Data{integration.Data("password: %%secret_redis-auth/password%%")},
+			},
+			errorString: "failed to get the namespace of service a5901276aed1 to resolve secret redis-auth/password",
+		},
 		{
 			testName: "IPv6 %%host%%",
 			svc: &dummyService{
@@ -943,6 +1134,21 @@ func TestResolve(t *testing.T) {
 	}
 }
 
+func TestSubstituteTemplateEnvVarsDefaults(t *testing.T) {
+	t.Setenv("test_envvar_key", "test_value")
+
+	config := integration.Config{
+		Name:      "redis",
+		Instances: []integration.Data{integration.Data("env: %%env_test_envvar_key|default%%\napp: %%label_app|default%%")},
+	}
+
+	// the default values of service template variables are only used when
+	// resolving the template for a service
+	err := SubstituteTemplateEnvVars(&config)
+	assert.IsType(t, &NoServiceError{}, err)
+	assert.Equal(t, "env: %%env_test_envvar_key|default%%\napp: %%label_app|default%%", string(config.Instances[0]))
+}
+
 func newFakeContainerPorts() []listeners.ContainerPort {
 	return []listeners.ContainerPort{
 		{Port: 1, Name: "foo"},
@@ -73,7 +73,7 @@ type Config struct {
 
 	// AdvancedADIdentifiers is the list of advanced AutoDiscovery identifiers;
 	// see ADIdentifiers.  (optional)
-	AdvancedADIdentifiers []AdvancedADIdentifier `json:"advanced_ad_identifiers"` // (include in digest: false)
+	AdvancedADIdentifiers []AdvancedADIdentifier `json:"advanced_ad_identifiers"` // (include in digest: selectors only)
 
 	// Provider is the name of the config provider that issued the config.  If
 	// this is "", then the config is a service config, representing a service
@@ -128,10 +128,17 @@ type CommonGlobalConfig struct {
 }
 
 // AdvancedADIdentifier contains user-defined autodiscovery information
-// It replaces ADIdentifiers for advanced use-cases. Typically, file-based k8s service and endpoint checks.
+// It replaces ADIdentifiers for advanced use-cases. Typically, file-based k8s service and endpoint checks,
+// or templates matching the services whose attributes meet a selector expression (see Selector).
 type AdvancedADIdentifier struct {
 	KubeService   KubeNamespacedName `yaml:"kube_service,omitempty"`
 	KubeEndpoints KubeNamespacedName `yaml:"kube_endpoints,omitempty"`
+	Selector      string             `yaml:"selector,omitempty"`
+}
+
+// IsKube returns true if the identifier targets a kubernetes service or endpoints
+func (a AdvancedADIdentifier) IsKube() bool {
+	return !a.KubeService.IsEmpty() || !a.KubeEndpoints.IsEmpty()
 }
 
 // KubeNamespacedName identifies a kubernetes object.
@@ -199,6 +206,22 @@ func (c *Config) IsTemplate() bool {
 	return len(c.ADIdentifiers) > 0 || len(c.AdvancedADIdentifiers) > 0
 }
 
+// Selectors parses the selector expressions of the advanced AD identifiers of the config
+func (c *Config) Selectors() ([]*Selector, error) {
+	var selectors []*Selector
+	for _, advancedID := range c.AdvancedADIdentifiers {
+		if advancedID.Selector == "" {
+			continue
+		}
+		selector, err := ParseSelector(advancedID.Selector)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+	return selectors, nil
+}
+
 // IsCheckConfig returns true if the config is a node-agent check configuration,
 func (c *Config) IsCheckConfig() bool {
 	return !c.ClusterCheck && len(c.Instances) > 0
@@ -416,6 +439,9 @@ func (c *Config) IntDigest() uint64 {
 	for _, i := range c.ADIdentifiers {
 		_, _ = h.Write([]byte(i))
 	}
+	for _, i := range c.AdvancedADIdentifiers {
+		_, _ = h.Write([]byte(i.Selector))
+	}
 	_, _ = h.Write([]byte(c.NodeName))
 	_, _ = h.Write([]byte(c.LogsConfig))
 	_, _ = h.Write([]byte(c.ServiceID))
@@ -439,6 +465,9 @@ func (c *Config) FastDigest() uint64 {
 	for _, i := range c.ADIdentifiers {
 		_, _ = h.Write([]byte(i))
 	}
+	for _, i := range c.AdvancedADIdentifiers {
+		_, _ = h.Write([]byte(i.Selector))
+	}
 	_, _ = h.Write([]byte(c.NodeName))
 	_, _ = h.Write([]byte(c.LogsConfig))
 	_, _ = h.Write([]byte(c.ServiceID))
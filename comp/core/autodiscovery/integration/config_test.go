@@ -245,6 +245,22 @@ func TestDigest(t *testing.T) {
 
 	// assert the ClusterCheck field is not taken into account
 	assert.NotEqual(t, simpleConfig.Digest(), simpleIngoreADTagsConfig.Digest())
+
+	kubeServiceConfig := &Config{
+		Name:                  "foo",
+		InitConfig:            Data(""),
+		AdvancedADIdentifiers: []AdvancedADIdentifier{{KubeService: KubeNamespacedName{Name: "svc", Namespace: "ns"}}},
+	}
+	selectorConfig := &Config{
+		Name:                  "foo",
+		InitConfig:            Data(""),
+		AdvancedADIdentifiers: []AdvancedADIdentifier{{Selector: "container.image.short_name == redis"}},
+	}
+
+	// assert only selectors are taken into account in advanced AD identifiers
+	assert.Equal(t, simpleConfig.Digest(), kubeServiceConfig.Digest())
+	assert.NotEqual(t, simpleConfig.Digest(), selectorConfig.Digest())
+	assert.NotEqual(t, simpleConfig.FastDigest(), selectorConfig.FastDigest())
 }
 
 func TestGetNameForInstance(t *testing.T) {
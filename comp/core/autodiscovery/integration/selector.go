@@ -0,0 +1,297 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package integration
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// SelectorAttributes lists the attributes that can be used in selector
+// expressions, those ending with a dot are maps whose keys follow the dot
+// (e.g. `pod.labels.app`).
+var SelectorAttributes = []string{
+	"service.id",
+	"service.ad_identifiers",
+	"service.hostname",
+	"container.id",
+	"container.name",
+	"container.runtime",
+	"container.image",
+	"container.image.name",
+	"container.image.short_name",
+	"container.image.tag",
+	"container.labels.",
+	"pod.name",
+	"pod.namespace",
+	"pod.labels.",
+	"pod.annotations.",
+	"namespace.labels.",
+	"namespace.annotations.",
+	"tags.",
+}
+
+// Selector matches services based on their attributes. It is parsed from an
+// expression made of requirements joined by `&&`, all of which must be met:
+//
+//	container.image matches "*/redis:*" && namespace.labels.team == payments
+//
+// The supported requirements are:
+//
+//	attr == value, attr != value        equality (`=` is an alias of `==`)
+//	attr matches pattern                glob pattern, `*` matches any sequence of characters
+//	attr in (v1, v2), attr notin (v1)   set membership
+//	attr, !attr                         existence
+//
+// Values can be quoted with double quotes, and must be when they contain
+// whitespaces or any of `,()=!"`. Attributes with several values (e.g.
+// `service.ad_identifiers`) meet a requirement if any of their values does;
+// negative requirements (`!=`, `notin`) are met if none of them does.
+type Selector struct {
+	expression   string
+	requirements []selectorRequirement
+}
+
+type selectorOperator int
+
+const (
+	selectorEquals selectorOperator = iota
+	selectorNotEquals
+	selectorMatches
+	selectorIn
+	selectorNotIn
+	selectorExists
+	selectorNotExists
+)
+
+type selectorRequirement struct {
+	attribute string
+	operator  selectorOperator
+	values    []string
+	pattern   glob.Glob
+}
+
+// ParseSelector parses a selector expression
+func ParseSelector(expression string) (*Selector, error) {
+	selector := &Selector{expression: expression}
+	for _, clause := range splitSelectorClauses(expression) {
+		requirement, err := parseSelectorRequirement(clause)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %q: %w", expression, err)
+		}
+		selector.requirements = append(selector.requirements, requirement)
+	}
+	return selector, nil
+}
+
+// String returns the expression of the selector
+func (s *Selector) String() string {
+	return s.expression
+}
+
+// Matches returns whether the attributes returned by the given function meet
+// all the requirements of the selector. The function returns false when the
+// attribute is not set.
+func (s *Selector) Matches(getAttribute func(attribute string) ([]string, bool)) bool {
+	for _, requirement := range s.requirements {
+		values, found := getAttribute(requirement.attribute)
+		if !requirement.matches(values, found) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r selectorRequirement) matches(values []string, found bool) bool {
+	switch r.operator {
+	case selectorExists:
+		return found
+	case selectorNotExists:
+		return !found
+	case selectorEquals, selectorIn:
+		return slices.ContainsFunc(values, func(v string) bool { return slices.Contains(r.values, v) })
+	case selectorNotEquals, selectorNotIn:
+		return !slices.ContainsFunc(values, func(v string) bool { return slices.Contains(r.values, v) })
+	case selectorMatches:
+		return slices.ContainsFunc(values, r.pattern.Match)
+	}
+	return false
+}
+
+// splitSelectorClauses splits an expression on the `&&` that are not quoted
+func splitSelectorClauses(expression string) []string {
+	var clauses []string
+	quoted := false
+	start := 0
+	for i := 0; i < len(expression); i++ {
+		switch {
+		case expression[i] == '\\' && quoted:
+			i++
+		case expression[i] == '"':
+			quoted = !quoted
+		case !quoted && strings.HasPrefix(expression[i:], "&&"):
+			clauses = append(clauses, expression[start:i])
+			start = i + 2
+			i++
+		}
+	}
+	return append(clauses, expression[start:])
+}
+
+type selectorToken struct {
+	text   string
+	quoted bool
+}
+
+// is returns whether the token is the given unquoted keyword or symbol
+func (t selectorToken) is(text string) bool {
+	return !t.quoted && t.text == text
+}
+
+func tokenizeSelectorClause(clause string) ([]selectorToken, error) {
+	var tokens []selectorToken
+	for i := 0; i < len(clause); {
+		c := clause[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(clause) && clause[end] != '"' {
+				if clause[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(clause) {
+				return nil, fmt.Errorf("unterminated quoted value in %q", strings.TrimSpace(clause))
+			}
+			value, err := strconv.Unquote(clause[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value %s: %w", clause[i:end+1], err)
+			}
+			tokens = append(tokens, selectorToken{text: value, quoted: true})
+			i = end + 1
+		case strings.HasPrefix(clause[i:], "==") || strings.HasPrefix(clause[i:], "!="):
+			tokens = append(tokens, selectorToken{text: clause[i : i+2]})
+			i += 2
+		case strings.ContainsRune(",()=!", rune(c)):
+			tokens = append(tokens, selectorToken{text: clause[i : i+1]})
+			i++
+		default:
+			end := i
+			for end < len(clause) && !strings.ContainsRune(" \t\n\",()=!", rune(clause[end])) {
+				end++
+			}
+			tokens = append(tokens, selectorToken{text: clause[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+func parseSelectorRequirement(clause string) (selectorRequirement, error) {
+	tokens, err := tokenizeSelectorClause(clause)
+	if err != nil {
+		return selectorRequirement{}, err
+	}
+	if len(tokens) == 0 {
+		return selectorRequirement{}, fmt.Errorf("empty requirement")
+	}
+
+	var requirement selectorRequirement
+	if tokens[0].is("!") {
+		if len(tokens) != 2 {
+			return selectorRequirement{}, fmt.Errorf("invalid requirement %q: expected `!attribute`", strings.TrimSpace(clause))
+		}
+		requirement = selectorRequirement{attribute: tokens[1].text, operator: selectorNotExists}
+		return requirement, validateSelectorAttribute(requirement.attribute)
+	}
+
+	requirement.attribute = tokens[0].text
+	if err := validateSelectorAttribute(requirement.attribute); err != nil {
+		return selectorRequirement{}, err
+	}
+	if len(tokens) == 1 {
+		requirement.operator = selectorExists
+		return requirement, nil
+	}
+
+	operator := tokens[1]
+	switch {
+	case operator.is("==") || operator.is("=") || operator.is("!=") || operator.is("matches"):
+		if len(tokens) != 3 {
+			return selectorRequirement{}, fmt.Errorf("invalid requirement %q: expected a single value after `%s`", strings.TrimSpace(clause), operator.text)
+		}
+		requirement.values = []string{tokens[2].text}
+		switch operator.text {
+		case "!=":
+			requirement.operator = selectorNotEquals
+		case "matches":
+			requirement.operator = selectorMatches
+			requirement.pattern, err = glob.Compile(tokens[2].text)
+			if err != nil {
+				return selectorRequirement{}, fmt.Errorf("invalid pattern %q: %w", tokens[2].text, err)
+			}
+		default:
+			requirement.operator = selectorEquals
+		}
+	case operator.is("in") || operator.is("notin"):
+		requirement.operator = selectorIn
+		if operator.text == "notin" {
+			requirement.operator = selectorNotIn
+		}
+		requirement.values, err = parseSelectorValueSet(tokens[2:])
+		if err != nil {
+			return selectorRequirement{}, fmt.Errorf("invalid requirement %q: %w", strings.TrimSpace(clause), err)
+		}
+	default:
+		return selectorRequirement{}, fmt.Errorf("invalid requirement %q: unknown operator %q", strings.TrimSpace(clause), operator.text)
+	}
+	return requirement, nil
+}
+
+// parseSelectorValueSet parses a list of values like `(v1, v2)`
+func parseSelectorValueSet(tokens []selectorToken) ([]string, error) {
+	if len(tokens) < 3 || !tokens[0].is("(") || !tokens[len(tokens)-1].is(")") {
+		return nil, fmt.Errorf("expected a list of values between parentheses")
+	}
+	var values []string
+	for i, token := range tokens[1 : len(tokens)-1] {
+		if i%2 == 1 {
+			if !token.is(",") {
+				return nil, fmt.Errorf("expected `,` between values, got %q", token.text)
+			}
+			continue
+		}
+		if token.is(",") || token.is("(") || token.is(")") {
+			return nil, fmt.Errorf("expected a value, got %q", token.text)
+		}
+		values = append(values, token.text)
+	}
+	if len(tokens)%2 == 0 {
+		// the list ends with a comma
+		return nil, fmt.Errorf("expected a value after the last `,`")
+	}
+	return values, nil
+}
+
+func validateSelectorAttribute(attribute string) error {
+	for _, known := range SelectorAttributes {
+		if strings.HasSuffix(known, ".") {
+			if strings.HasPrefix(attribute, known) && len(attribute) > len(known) {
+				return nil
+			}
+		} else if attribute == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown attribute %q", attribute)
+}
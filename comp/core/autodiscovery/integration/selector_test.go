@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSelectorErrors(t *testing.T) {
+	tests := []struct {
+		expression string
+		wantErr    string
+	}{
+		{``, "empty requirement"},
+		{`container.image == redis &&`, "empty requirement"},
+		{`container.unknown == redis`, `unknown attribute "container.unknown"`},
+		{`pod.labels. == redis`, `unknown attribute "pod.labels."`},
+		{`container.image ~ redis`, `unknown operator "~"`},
+		{`container.image == redis nginx`, "expected a single value"},
+		{`container.image == "redis`, "unterminated quoted value"},
+		{`container.image in redis`, "expected a list of values between parentheses"},
+		{`container.image in (redis nginx)`, "expected `,` between values"},
+		{`container.image in (redis,)`, "expected a value after the last `,`"},
+		{`container.image in (,redis)`, `expected a value, got ","`},
+		{`container.image matches "[redis"`, `invalid pattern "[redis"`},
+		{`!container.image == redis`, "expected `!attribute`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			_, err := ParseSelector(tt.expression)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestSelectorMatches(t *testing.T) {
+	attributes := map[string][]string{
+		"container.image":        {"docker.io/library/redis:7"},
+		"service.ad_identifiers": {"redis", "docker.io/library/redis"},
+		"pod.labels.app":         {"redis"},
+		"namespace.labels.team":  {"payments"},
+		"pod.annotations.note":   {"a value, with (symbols)"},
+	}
+	getAttribute := func(attribute string) ([]string, bool) {
+		values, found := attributes[attribute]
+		return values, found
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{`container.image matches "*/redis:*" && namespace.labels.team == payments`, true},
+		{`container.image matches "*/redis:*" && namespace.labels.team == billing`, false},
+		{`container.image matches */nginx:*`, false},
+		{`pod.labels.app = redis`, true},
+		{`pod.labels.app != redis`, false},
+		{`pod.labels.tier != backend`, true},
+		{`service.ad_identifiers == redis`, true},
+		{`service.ad_identifiers != redis`, false},
+		{`service.ad_identifiers in (nginx, docker.io/library/redis)`, true},
+		{`service.ad_identifiers notin (nginx, redis)`, false},
+		{`namespace.labels.team notin (billing)`, true},
+		{`pod.labels.app && !pod.labels.tier`, true},
+		{`pod.labels.tier`, false},
+		{`!pod.labels.app`, false},
+		{`pod.annotations.note == "a value, with (symbols)"`, true},
+		{`pod.annotations.note == "a value && more"`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			selector, err := ParseSelector(tt.expression)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expression, selector.String())
+			assert.Equal(t, tt.want, selector.Matches(getAttribute))
+		})
+	}
+}
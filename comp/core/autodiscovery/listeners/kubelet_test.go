@@ -249,6 +249,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: basicContainer,
+						pod:    pod,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"gcr.io/foobar:latest",
@@ -282,6 +283,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: recentlyStoppedContainer,
+						pod:    pod,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"foobar",
@@ -338,6 +340,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: runningContainerWithFinishedAtTime,
+						pod:    pod,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"foobar",
@@ -370,6 +373,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: multiplePortsContainer,
+						pod:    pod,
 						adIdentifiers: []string{
 							"docker://foobarquux",
 							"foobar",
@@ -411,6 +415,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: customIDsContainer,
+						pod:    podWithAnnotations,
 						adIdentifiers: []string{
 							"customid",
 							"docker://foobarquux",
@@ -456,6 +461,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: customIDsContainer,
+						pod:    podWithMetricsExcludeAnnotation,
 						adIdentifiers: []string{
 							"customid",
 							"docker://foobarquux",
@@ -492,6 +498,7 @@ func TestKubeletCreateContainerService(t *testing.T) {
 					parent: "kubernetes_pod://foobar",
 					service: &service{
 						entity: customIDsContainer,
+						pod:    podWithLogsExcludeAnnotation,
 						adIdentifiers: []string{
 							"customid",
 							"docker://foobarquux",
@@ -15,6 +15,7 @@ const (
 	kubeletListenerName         = "kubelet"
 	snmpListenerName            = "snmp"
 	staticConfigListenerName    = "static config"
+	systemdListenerName         = "systemd"
 	dbmAuroraListenerName       = "database-monitoring-aurora"
 )
 
@@ -29,5 +30,6 @@ func RegisterListeners(serviceListenerFactories map[string]ServiceListenerFactor
 	Register(kubeletListenerName, NewKubeletListener, serviceListenerFactories)
 	Register(snmpListenerName, NewSNMPListener, serviceListenerFactories)
 	Register(staticConfigListenerName, NewStaticConfigListener, serviceListenerFactories)
+	Register(systemdListenerName, NewSystemdListener, serviceListenerFactories)
 	Register(dbmAuroraListenerName, NewDBMAuroraListener, serviceListenerFactories)
 }
@@ -21,10 +21,15 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+// systemdUnitPrefix is the prefix of the AD identifiers of the systemd units,
+// e.g. systemd://nginx.service
+const systemdUnitPrefix = "systemd://"
+
 // service implements the Service interface and stores data collected from
 // workloadmeta.Store.
 type service struct {
 	entity          workloadmeta.Entity
+	pod             *workloadmeta.KubernetesPod
 	tagsHash        string
 	adIdentifiers   []string
 	hosts           map[string]string
@@ -40,6 +45,7 @@ type service struct {
 }
 
 var _ Service = &service{}
+var _ MetadataService = &service{}
 
 // Equal returns whether the two service are equal
 func (s *service) Equal(o Service) bool {
@@ -66,6 +72,8 @@ func (s *service) GetServiceID() string {
 		return containers.BuildEntityName(string(e.Runtime), e.ID)
 	case *workloadmeta.KubernetesPod:
 		return kubelet.PodUIDToEntityName(e.ID)
+	case *workloadmeta.SystemdUnit:
+		return systemdUnitPrefix + e.ID
 	default:
 		entityID := s.entity.GetID()
 		log.Errorf("cannot build AD entity ID for kind %q, ID %q", entityID.Kind, entityID.ID)
@@ -214,3 +222,44 @@ func (s *service) GetExtraConfig(key string) (string, error) {
 
 	return result, nil
 }
+
+// GetLabel returns the value of a label of the service's pod or container.
+func (s *service) GetLabel(key string) (string, bool) {
+	return s.lookupMetadata(key, func(meta workloadmeta.EntityMeta) map[string]string { return meta.Labels })
+}
+
+// GetAnnotation returns the value of an annotation of the service's pod or container.
+func (s *service) GetAnnotation(key string) (string, bool) {
+	return s.lookupMetadata(key, func(meta workloadmeta.EntityMeta) map[string]string { return meta.Annotations })
+}
+
+// GetContainerEnv returns the value of an environment variable of the service's container.
+func (s *service) GetContainerEnv(name string) (string, bool) {
+	container, ok := s.entity.(*workloadmeta.Container)
+	if !ok {
+		return "", false
+	}
+	value, found := container.EnvVars[name]
+	return value, found
+}
+
+// lookupMetadata looks a key up in the metadata of the pod of the service
+// first, then in the metadata of its container.
+func (s *service) lookupMetadata(key string, getMap func(workloadmeta.EntityMeta) map[string]string) (string, bool) {
+	metas := make([]workloadmeta.EntityMeta, 0, 2)
+	if s.pod != nil {
+		metas = append(metas, s.pod.EntityMeta)
+	}
+	switch e := s.entity.(type) {
+	case *workloadmeta.Container:
+		metas = append(metas, e.EntityMeta)
+	case *workloadmeta.KubernetesPod:
+		metas = append(metas, e.EntityMeta)
+	}
+	for _, meta := range metas {
+		if value, found := getMap(meta)[key]; found {
+			return value, true
+		}
+	}
+	return "", false
+}
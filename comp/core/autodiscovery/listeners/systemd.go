@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+
+package listeners
+
+import (
+	"errors"
+
+	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+)
+
+// SystemdListener listens to the systemd units running on the host through a
+// subscription to the workloadmeta store.
+type SystemdListener struct {
+	workloadmetaListener
+	tagger tagger.Component
+}
+
+// NewSystemdListener returns a new SystemdListener.
+func NewSystemdListener(options ServiceListernerDeps) (ServiceListener, error) {
+	const name = "ad-systemdlistener"
+	l := &SystemdListener{}
+	filter := workloadmeta.NewFilterBuilder().
+		SetSource(workloadmeta.SourceAll).
+		AddKind(workloadmeta.KindSystemdUnit).Build()
+
+	wmetaInstance, ok := options.Wmeta.Get()
+	if !ok {
+		return nil, errors.New("workloadmeta store is not initialized")
+	}
+	var err error
+	l.workloadmetaListener, err = newWorkloadmetaListener(name, filter, l.createSystemdService, wmetaInstance, options.Telemetry)
+	if err != nil {
+		return nil, err
+	}
+	l.tagger = options.Tagger
+
+	return l, nil
+}
+
+func (l *SystemdListener) createSystemdService(entity workloadmeta.Entity) {
+	unit := entity.(*workloadmeta.SystemdUnit)
+
+	svc := &service{
+		entity:        unit,
+		tagsHash:      l.tagger.GetEntityHash(types.NewEntityID(types.SystemdUnit, unit.ID), types.ChecksConfigCardinality),
+		adIdentifiers: []string{systemdUnitPrefix + unit.ID},
+		pid:           unit.MainPID,
+		// the checks of a service are scheduled once it has started
+		ready:  unit.ActiveState == "active",
+		tagger: l.tagger,
+	}
+
+	svcID := buildSvcID(unit.GetID())
+	l.AddService(svcID, svc, "")
+}
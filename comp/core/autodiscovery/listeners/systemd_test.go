@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build !serverless
+
+package listeners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
+	taggerfxmock "github.com/DataDog/datadog-agent/comp/core/tagger/fx-mock"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+)
+
+func TestCreateSystemdService(t *testing.T) {
+	taggerComponent := taggerfxmock.SetupFakeTagger(t)
+
+	runningUnit := &workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   "nginx.service",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "nginx.service",
+		},
+		ActiveState: "active",
+		SubState:    "running",
+		MainPID:     42,
+	}
+
+	startingUnit := &workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   "redis.service",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "redis.service",
+		},
+		ActiveState: "activating",
+		SubState:    "start",
+	}
+
+	tests := []struct {
+		name             string
+		unit             *workloadmeta.SystemdUnit
+		expectedServices map[string]wlmListenerSvc
+	}{
+		{
+			name: "running unit",
+			unit: runningUnit,
+			expectedServices: map[string]wlmListenerSvc{
+				"systemd_unit://nginx.service": {
+					service: &service{
+						entity:        runningUnit,
+						adIdentifiers: []string{"systemd://nginx.service"},
+						pid:           42,
+						ready:         true,
+						tagger:        taggerComponent,
+					},
+				},
+			},
+		},
+		{
+			name: "starting unit",
+			unit: startingUnit,
+			expectedServices: map[string]wlmListenerSvc{
+				"systemd_unit://redis.service": {
+					service: &service{
+						entity:        startingUnit,
+						adIdentifiers: []string{"systemd://redis.service"},
+						ready:         false,
+						tagger:        taggerComponent,
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			listener, wlm := newSystemdListener(t, taggerComponent)
+
+			listener.createSystemdService(tt.unit)
+
+			wlm.assertServices(tt.expectedServices)
+			for _, svc := range wlm.services {
+				assert.Equal(t, "systemd://"+tt.unit.ID, svc.service.GetServiceID())
+			}
+		})
+	}
+}
+
+func newSystemdListener(t *testing.T, tagger tagger.Component) (*SystemdListener, *testWorkloadmetaListener) {
+	wlm := newTestWorkloadmetaListener(t)
+
+	return &SystemdListener{workloadmetaListener: wlm, tagger: tagger}, wlm
+}
@@ -50,6 +50,21 @@ type Service interface {
 	FilterTemplates(map[string]integration.Config)
 }
 
+// MetadataService is implemented by the services exposing the metadata of
+// the workload they represent.  It is used to resolve the %%label_*%%,
+// %%annotation_*%% and %%containerenv_*%% template variables.
+type MetadataService interface {
+	// GetLabel returns the value of a label of the pod of the service,
+	// falling back to the labels of its container.
+	GetLabel(key string) (string, bool)
+	// GetAnnotation returns the value of an annotation of the pod of the
+	// service, falling back to the annotations of its container.
+	GetAnnotation(key string) (string, bool)
+	// GetContainerEnv returns the value of an environment variable of the
+	// container spec of the service.
+	GetContainerEnv(name string) (string, bool)
+}
+
 // ServiceListener monitors running services and triggers check (un)scheduling
 //
 // It holds a cache of running services, listens to new/killed services and
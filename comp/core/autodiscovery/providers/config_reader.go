@@ -100,11 +100,23 @@ type FilterFunc func(integration.Config) bool
 // GetAll makes ReadConfigFiles return all the configurations found.
 var GetAll FilterFunc = func(_ integration.Config) bool { return true }
 
-// WithAdvancedADOnly makes ReadConfigFiles return the configurations with AdvancedADIdentifiers only.
-var WithAdvancedADOnly FilterFunc = func(c integration.Config) bool { return len(c.AdvancedADIdentifiers) > 0 }
-
-// WithoutAdvancedAD makes ReadConfigFiles return the all configurations except the ones with AdvancedADIdentifiers.
-var WithoutAdvancedAD FilterFunc = func(c integration.Config) bool { return len(c.AdvancedADIdentifiers) == 0 }
+// WithAdvancedADOnly makes ReadConfigFiles return the configurations with AdvancedADIdentifiers
+// targeting kubernetes services or endpoints only.
+var WithAdvancedADOnly FilterFunc = func(c integration.Config) bool { return hasKubeAdvancedAD(c) }
+
+// WithoutAdvancedAD makes ReadConfigFiles return the all configurations except the ones with AdvancedADIdentifiers
+// targeting kubernetes services or endpoints. Configurations with selectors only are returned, they are
+// matched against services by autodiscovery.
+var WithoutAdvancedAD FilterFunc = func(c integration.Config) bool { return !hasKubeAdvancedAD(c) }
+
+func hasKubeAdvancedAD(c integration.Config) bool {
+	for _, advancedID := range c.AdvancedADIdentifiers {
+		if advancedID.IsKube() {
+			return true
+		}
+	}
+	return false
+}
 
 // ReadConfigFiles returns integration configs read from config files, a mapping integration config error strings and an error.
 // The filter argument allows returing a subset of configs depending on the caller preferences.
@@ -10,11 +10,11 @@ package providers
 import (
 	"context"
 	"fmt"
-	"math"
 	"net/url"
 	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	consul "github.com/hashicorp/consul/api"
 
@@ -45,14 +45,33 @@ func (c *consulWrapper) KV() consulKVBackend {
 	return c.client.KV()
 }
 
+// consulWaitTime is the maximum duration of a blocking query, consul
+// answers with an unchanged index once it expires.
+const consulWaitTime = 5 * time.Minute
+
+// consulMinQueryInterval is the minimum delay between two reads of the
+// template directory, so that a burst of writes or a consul server answering
+// blocking queries right away doesn't make the agent hammer it.
+const consulMinQueryInterval = time.Second
+
 // ConsulConfigProvider implements the Config Provider interface
-// It should be called periodically and returns templates from consul for AutoConf.
+// It streams templates from consul for AutoConf.
 type ConsulConfigProvider struct {
 	Client      consulBackend
 	TemplateDir string
-	cache       *providerCache
+
+	// lastIndex is the consul index of the template directory at the last
+	// collect, used as the WaitIndex of blocking queries.
+	lastIndex uint64
+	// lastQuery is the time the template directory was last read.
+	lastQuery time.Time
+	// minQueryInterval is the minimum delay between two reads of the
+	// template directory.
+	minQueryInterval time.Duration
 }
 
+var _ StreamingConfigProvider = &ConsulConfigProvider{}
+
 // NewConsulConfigProvider creates a client connection to consul and create a new ConsulConfigProvider
 func NewConsulConfigProvider(providerConfig *pkgconfigsetup.ConfigurationProviders, _ *telemetry.Store) (ConfigProvider, error) {
 	if providerConfig == nil {
@@ -88,7 +107,6 @@ func NewConsulConfigProvider(providerConfig *pkgconfigsetup.ConfigurationProvide
 		}
 		clientCfg.HttpAuth = auth
 	}
-	cache := newProviderCache()
 	cli, err := consul.NewClient(clientCfg)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to instantiate the consul client: %s", err)
@@ -99,9 +117,9 @@ func NewConsulConfigProvider(providerConfig *pkgconfigsetup.ConfigurationProvide
 	}
 
 	return &ConsulConfigProvider{
-		Client:      c,
-		TemplateDir: providerConfig.TemplateDir,
-		cache:       cache,
+		Client:           c,
+		TemplateDir:      providerConfig.TemplateDir,
+		minQueryInterval: consulMinQueryInterval,
 	}, nil
 
 }
@@ -111,70 +129,90 @@ func (p *ConsulConfigProvider) String() string {
 	return names.Consul
 }
 
-// Collect retrieves templates from consul, builds Config objects and returns them
-func (p *ConsulConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+// Stream sends the templates found in consul and then watches the template
+// directory with blocking queries to send incremental updates.
+func (p *ConsulConfigProvider) Stream(ctx context.Context) <-chan integration.ConfigChanges {
+	return streamKVConfigs(ctx, p)
+}
+
+// collect retrieves templates from consul, builds Config objects and returns them
+func (p *ConsulConfigProvider) collect(ctx context.Context) ([]integration.Config, map[string]struct{}, error) {
 	configs := make([]integration.Config, 0)
-	identifiers := p.getIdentifiers(ctx, p.TemplateDir)
+	failed := make(map[string]struct{})
+	p.lastQuery = time.Now()
+	identifiers, index, err := p.getIdentifiers(ctx, p.TemplateDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.lastIndex = index
+
 	log.Debugf("identifiers found in backend: %v", identifiers)
 	for _, id := range identifiers {
-		templates := p.getTemplates(ctx, id)
+		source := "consul:" + id
+		templates, err := p.getTemplates(ctx, id)
+		if err != nil {
+			log.Warnf("Failed to read the templates of %s: %s", id, err)
+			failed[source] = struct{}{}
+			continue
+		}
 
 		for idx := range templates {
-			templates[idx].Source = "consul:" + id
+			templates[idx].Source = source
 		}
 
 		configs = append(configs, templates...)
 	}
-	return configs, nil
+	return configs, failed, nil
 }
 
-// IsUpToDate updates the list of AD templates versions in the Agent's cache and checks the list is up to date compared to Consul's data.
-func (p *ConsulConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
+// waitForChange runs blocking queries on the template directory until its
+// index moves past the one seen by the last collect. Queries are at least
+// minQueryInterval apart from each other and from the last collect.
+func (p *ConsulConfigProvider) waitForChange(ctx context.Context) error {
 	kv := p.Client.KV()
-	adListUpdated := false
-	dateIdx := p.cache.mostRecentMod
+	for {
+		if delay := p.minQueryInterval - time.Since(p.lastQuery); delay > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+		p.lastQuery = time.Now()
 
-	queryOptions := &consul.QueryOptions{}
-	queryOptions = queryOptions.WithContext(ctx)
-	identifiers, _, err := kv.List(p.TemplateDir, queryOptions)
-	if err != nil {
-		return false, err
-	}
-	if p.cache.count != len(identifiers) {
-		if p.cache.count == 0 {
-			log.Infof("Initializing cache for %v", p.String())
+		queryOptions := &consul.QueryOptions{WaitIndex: p.lastIndex, WaitTime: consulWaitTime}
+		queryOptions = queryOptions.WithContext(ctx)
+		_, meta, err := kv.Keys(p.TemplateDir, "", queryOptions)
+		if err != nil {
+			return fmt.Errorf("blocking query on %s failed: %s", p.TemplateDir, err)
 		}
-		log.Debugf("List of AD Template was modified, updating cache.")
-		p.cache.count = len(identifiers)
-		adListUpdated = true
-	}
 
-	for _, identifier := range identifiers {
-		dateIdx = math.Max(float64(identifier.ModifyIndex), dateIdx)
-	}
-	if dateIdx > p.cache.mostRecentMod || adListUpdated {
-		log.Debugf("Cache Index was %v and is now %v", p.cache.mostRecentMod, dateIdx)
-		p.cache.mostRecentMod = dateIdx
-		log.Infof("Cache updated for %v", p.String())
-		return false, nil
+		// The query timed out without any change when the index is the same.
+		// A different index, even a lower one after a consul state reset,
+		// means the whole directory has to be read again.
+		if meta.LastIndex != p.lastIndex {
+			return nil
+		}
 	}
-	return true, nil
 }
 
 // getIdentifiers gets folders at the root of the TemplateDir
 // verifies they have the right content to be a valid template
 // and return their names.
-func (p *ConsulConfigProvider) getIdentifiers(ctx context.Context, prefix string) []string {
+func (p *ConsulConfigProvider) getIdentifiers(ctx context.Context, prefix string) ([]string, uint64, error) {
 	kv := p.Client.KV()
 	queryOptions := &consul.QueryOptions{}
 	queryOptions = queryOptions.WithContext(ctx)
 
 	identifiers := make([]string, 0)
-	// TODO: decide on the query parameters.
-	keys, _, err := kv.Keys(prefix, "", queryOptions)
+	keys, meta, err := kv.Keys(prefix, "", queryOptions)
 	if err != nil {
-		log.Error("Can't get templates keys from consul: ", err)
-		return identifiers
+		return nil, 0, fmt.Errorf("can't get templates keys from consul: %s", err)
+	}
+
+	var index uint64
+	if meta != nil {
+		index = meta.LastIndex
 	}
 
 	criteriaFound := make(map[string]int)
@@ -209,36 +247,50 @@ func (p *ConsulConfigProvider) getIdentifiers(ctx context.Context, prefix string
 
 	// this doesn't trigger often and list should be small
 	sort.Strings(identifiers)
-	return identifiers
+	return identifiers, index, nil
 }
 
 // getTemplates takes a path and returns a slice of templates if it finds
-// sufficient data under this path to build one.
-func (p *ConsulConfigProvider) getTemplates(ctx context.Context, key string) []integration.Config {
+// sufficient data under this path to build one. It only returns an error
+// when the data can't be read from consul.
+func (p *ConsulConfigProvider) getTemplates(ctx context.Context, key string) ([]integration.Config, error) {
 	templates := make([]integration.Config, 0)
 
 	checkNameKey := buildStoreKey(key, checkNamePath)
 	initKey := buildStoreKey(key, initConfigPath)
 	instanceKey := buildStoreKey(key, instancePath)
 
-	checkNames, err := p.getCheckNames(ctx, checkNameKey)
+	rawNames, err := p.getValue(ctx, checkNameKey)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get check names from consul: %s", err)
+	}
+	rawInitConfigs, err := p.getValue(ctx, initKey)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get key %s from consul: %s", initKey, err)
+	}
+	rawInstances, err := p.getValue(ctx, instanceKey)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get key %s from consul: %s", instanceKey, err)
+	}
+
+	checkNames, err := utils.ParseCheckNames(string(rawNames))
 	if err != nil {
 		log.Errorf("Failed to retrieve check names at %s. Error: %s", checkNameKey, err)
-		return templates
+		return templates, nil
 	}
 
-	initConfigs, err := p.getJSONValue(ctx, initKey)
+	initConfigs, err := utils.ParseJSONValue(string(rawInitConfigs))
 	if err != nil {
 		log.Errorf("Failed to retrieve init configs at %s. Error: %s", initKey, err)
-		return templates
+		return templates, nil
 	}
 
-	instances, err := p.getJSONValue(ctx, instanceKey)
+	instances, err := utils.ParseJSONValue(string(rawInstances))
 	if err != nil {
 		log.Errorf("Failed to retrieve instances at %s. Error: %s", instanceKey, err)
-		return templates
+		return templates, nil
 	}
-	return utils.BuildTemplates(key, checkNames, initConfigs, instances, false, "")
+	return utils.BuildTemplates(key, checkNames, initConfigs, instances, false, ""), nil
 }
 
 // getValue returns value, error
@@ -254,35 +306,6 @@ func (p *ConsulConfigProvider) getValue(ctx context.Context, key string) ([]byte
 	return pair.Value, err
 }
 
-func (p *ConsulConfigProvider) getCheckNames(ctx context.Context, key string) ([]string, error) {
-	raw, err := p.getValue(ctx, key)
-	if err != nil {
-		err := fmt.Errorf("couldn't get check names from consul: %s", err)
-		return nil, err
-	}
-
-	names := string(raw)
-	if names == "" {
-		err = fmt.Errorf("check_names is empty")
-		return nil, err
-	}
-
-	checks, err := utils.ParseCheckNames(names)
-	return checks, err
-}
-
-func (p *ConsulConfigProvider) getJSONValue(ctx context.Context, key string) ([][]integration.Data, error) {
-	rawValue, err := p.getValue(ctx, key)
-	if err != nil {
-		err := fmt.Errorf("Couldn't get key %s from consul: %s", key, err)
-		return nil, err
-	}
-
-	r, err := utils.ParseJSONValue(string(rawValue))
-
-	return r, err
-}
-
 // isTemplateField verifies the key
 // the needed information to build a config template
 func isTemplateField(key string) bool {
@@ -9,8 +9,16 @@ package providers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	consul "github.com/hashicorp/consul/api"
 	"github.com/stretchr/testify/assert"
@@ -100,11 +108,13 @@ func TestConsulGetIdentifiers(t *testing.T) {
 		TemplateDir: "/datadog/tpl",
 	}
 
-	ids := consulCli.getIdentifiers(ctx, "/datadog/foo")
+	ids, _, err := consulCli.getIdentifiers(ctx, "/datadog/foo")
+	assert.NoError(t, err)
 	assert.NotNil(t, ids)
 	assert.Len(t, ids, 0)
 
-	ids = consulCli.getIdentifiers(ctx, "/datadog/tpl")
+	ids, _, err = consulCli.getIdentifiers(ctx, "/datadog/tpl")
+	assert.NoError(t, err)
 	assert.NotNil(t, ids)
 	assert.Len(t, ids, 2)
 
@@ -113,7 +123,8 @@ func TestConsulGetIdentifiers(t *testing.T) {
 
 	assert.Len(t, ids, 2)
 
-	ids = consulCli.getIdentifiers(ctx, "/datadog/tpl/")
+	ids, _, err = consulCli.getIdentifiers(ctx, "/datadog/tpl/")
+	assert.NoError(t, err)
 	assert.NotNil(t, ids)
 	assert.Len(t, ids, 2)
 
@@ -166,7 +177,8 @@ func TestConsulGetTemplates(t *testing.T) {
 		TemplateDir: "/datadog/tpl",
 	}
 
-	res := consulCli.getTemplates(ctx, "nginx")
+	res, err := consulCli.getTemplates(ctx, "nginx")
+	require.NoError(t, err)
 	require.Len(t, res, 2)
 
 	assert.Len(t, res[0].ADIdentifiers, 1)
@@ -187,8 +199,10 @@ func TestConsulGetTemplates(t *testing.T) {
 	kv.On("Get", "/datadog/tpl/nginx_aux/init_configs", queryOptions).Return(kvNginxInit, nil, nil).Times(1)
 	kv.On("Get", "/datadog/tpl/nginx_aux/instances", queryOptions).Return(nil, nil, errors.New("unavailable")).Times(1)
 
-	res = consulCli.getTemplates(ctx, "nginx_aux")
-	require.Len(t, res, 0)
+	// a read error is returned so that the previous templates are kept
+	res, err = consulCli.getTemplates(ctx, "nginx_aux")
+	assert.Error(t, err)
+	assert.Empty(t, res)
 
 	provider.AssertExpectations(t)
 	kv.AssertExpectations(t)
@@ -265,8 +279,9 @@ func TestConsulCollect(t *testing.T) {
 		TemplateDir: "/datadog/tpl",
 	}
 
-	res, err := consulCli.Collect(ctx)
+	res, failed, err := consulCli.collect(ctx)
 	assert.Nil(t, err)
+	assert.Empty(t, failed)
 	assert.Len(t, res, 3)
 
 	assert.Len(t, res[0].ADIdentifiers, 1)
@@ -297,74 +312,177 @@ func TestConsulCollect(t *testing.T) {
 	kv.AssertExpectations(t)
 }
 
-func TestIsUpToDate(t *testing.T) {
-	// We want to check:
-	// The cache is properly initialized
-	// mostRecentMod and count are properly set
-	// If the number of ADTemplate is modified we update
-	// If nothing changed we don't update
+// consulKVServer is an in-process stand-in for the consul KV HTTP API
+// supporting blocking queries.
+type consulKVServer struct {
+	*httptest.Server
 
-	ctx := context.Background()
-	kv := &consulKVMock{}
-	provider := &consulMock{kv: kv}
-
-	queryOptions := &consul.QueryOptions{}
-	queryOptions = queryOptions.WithContext(ctx)
+	mu      sync.Mutex
+	index   uint64
+	values  map[string]*consul.KVPair
+	changed chan struct{}
+}
 
-	kvNginx := &consul.KVPair{
-		Key:         "/datadog/check_configs/nginx",
-		CreateIndex: 0,
-		ModifyIndex: 9,
-		Value:       []byte(""),
+func newConsulKVServer(t *testing.T) *consulKVServer {
+	s := &consulKVServer{
+		index:   1,
+		values:  map[string]*consul.KVPair{},
+		changed: make(chan struct{}),
 	}
-	kvNginxNames := &consul.KVPair{
-		Key:         "/datadog/check_configs/nginx/check_names",
-		CreateIndex: 1,
-		ModifyIndex: 10,
-		Value:       []byte("[\"nginx\", \"haproxy\"]"),
+	s.Server = httptest.NewServer(s)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *consulKVServer) write(key string, value *string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.index++
+	if value == nil {
+		delete(s.values, key)
+	} else {
+		s.values[key] = &consul.KVPair{Key: key, Value: []byte(*value), ModifyIndex: s.index}
 	}
-	kvNginxInit := &consul.KVPair{
-		Key:         "/datadog/check_configs/nginx/init_configs",
-		CreateIndex: 2,
-		ModifyIndex: 11,
-		Value:       []byte("[{}, {}]"),
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *consulKVServer) put(key, value string) {
+	s.write(key, &value)
+}
+
+func (s *consulKVServer) delete(key string) {
+	s.write(key, nil)
+}
+
+func (s *consulKVServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	query := r.URL.Query()
+
+	if waitIndex, err := strconv.ParseUint(query.Get("index"), 10, 64); err == nil {
+		wait, _ := time.ParseDuration(query.Get("wait"))
+		timeout := time.After(wait)
+		for {
+			s.mu.Lock()
+			index, changed := s.index, s.changed
+			s.mu.Unlock()
+			if index != waitIndex {
+				break
+			}
+			select {
+			case <-changed:
+				continue
+			case <-timeout:
+			case <-r.Context().Done():
+				return
+			}
+			break
+		}
 	}
-	kvNginxInstances := &consul.KVPair{
-		Key:         "/datadog/check_configs/nginx/instances",
-		CreateIndex: 3,
-		ModifyIndex: 12,
-		Value:       []byte("[{\"port\": 21, \"host\": \"localhost\"}, {\"port\": \"21\", \"pool\": {\"server\": \"foo\"}}]"),
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(s.index, 10))
+
+	var body interface{}
+	if query.Has("keys") {
+		keys := []string{}
+		for k := range s.values {
+			if strings.HasPrefix(k, key) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		body = keys
+	} else if pair, found := s.values[key]; found {
+		body = consul.KVPairs{pair}
+	} else {
+		w.WriteHeader(http.StatusNotFound)
+		return
 	}
-	kv.On("List", "/datadog/check_configs", queryOptions).Return(consul.KVPairs{kvNginx, kvNginxNames, kvNginxInit, kvNginxInstances}, nil, nil).Times(1)
+	_ = json.NewEncoder(w).Encode(body)
+}
 
-	cache := newProviderCache()
-	consulCli := ConsulConfigProvider{
-		Client:      provider,
-		TemplateDir: "/datadog/check_configs",
-		cache:       cache,
+func TestConsulStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockConfig := configmock.New(t)
+	mockConfig.SetWithoutSource("autoconf_template_dir", "/datadog/tpl")
+
+	server := newConsulKVServer(t)
+	server.put("datadog/tpl/nginx/check_names", "[\"nginx\"]")
+	server.put("datadog/tpl/nginx/init_configs", "[{}]")
+	server.put("datadog/tpl/nginx/instances", "[{\"port\": 80}]")
+
+	cli, err := consul.NewClient(&consul.Config{Address: strings.TrimPrefix(server.URL, "http://")})
+	require.NoError(t, err)
+
+	// consul keys don't start with a slash
+	consulCli := &ConsulConfigProvider{
+		Client:      &consulWrapper{client: cli},
+		TemplateDir: "datadog/tpl",
 	}
-	assert.Equal(t, int(0), consulCli.cache.count)
-	assert.Equal(t, float64(0), consulCli.cache.mostRecentMod)
-
-	update, _ := consulCli.IsUpToDate(ctx)
-	assert.False(t, update)
-	assert.Equal(t, int(4), consulCli.cache.count)
-	assert.Equal(t, float64(12), consulCli.cache.mostRecentMod)
-
-	kvNewTemplate := &consul.KVPair{
-		Key:         "/datadog/check_configs/new",
-		CreateIndex: 15,
-		ModifyIndex: 15,
-		Value:       []byte(""),
+	ch := consulCli.Stream(ctx)
+
+	changes := receiveChanges(t, ch)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, "nginx", changes.Schedule[0].Name)
+	assert.Equal(t, "consul:nginx", changes.Schedule[0].Source)
+	assert.Empty(t, changes.Unschedule)
+
+	// updating a value replaces the config
+	server.put("datadog/tpl/nginx/instances", "[{\"port\": 8080}]")
+	changes = receiveChanges(t, ch)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, "{\"port\":8080}", string(changes.Schedule[0].Instances[0]))
+	require.Len(t, changes.Unschedule, 1)
+	assert.Equal(t, "{\"port\":80}", string(changes.Unschedule[0].Instances[0]))
+
+	server.put("datadog/tpl/redis/check_names", "[\"redisdb\"]")
+	server.put("datadog/tpl/redis/init_configs", "[{}]")
+	server.put("datadog/tpl/redis/instances", "[{}]")
+	changes = receiveChanges(t, ch)
+	assert.Equal(t, []string{"redisdb"}, configNames(changes.Schedule))
+	assert.Empty(t, changes.Unschedule)
+
+	server.delete("datadog/tpl/nginx/check_names")
+	changes = receiveChanges(t, ch)
+	assert.Empty(t, changes.Schedule)
+	assert.Equal(t, []string{"nginx"}, configNames(changes.Unschedule))
+}
+
+func TestConsulStreamMinQueryInterval(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockConfig := configmock.New(t)
+	mockConfig.SetWithoutSource("autoconf_template_dir", "/datadog/tpl")
+
+	server := newConsulKVServer(t)
+	server.put("datadog/tpl/nginx/check_names", "[\"nginx\"]")
+	server.put("datadog/tpl/nginx/init_configs", "[{}]")
+	server.put("datadog/tpl/nginx/instances", "[{\"port\": 80}]")
+
+	cli, err := consul.NewClient(&consul.Config{Address: strings.TrimPrefix(server.URL, "http://")})
+	require.NoError(t, err)
+
+	interval := 500 * time.Millisecond
+	consulCli := &ConsulConfigProvider{
+		Client:           &consulWrapper{client: cli},
+		TemplateDir:      "datadog/tpl",
+		minQueryInterval: interval,
 	}
-	kv.On("List", "/datadog/check_configs", queryOptions).Return(consul.KVPairs{kvNewTemplate, kvNginx, kvNginxNames, kvNginxInit, kvNginxInstances}, nil, nil)
-	update, _ = consulCli.IsUpToDate(ctx)
-	assert.False(t, update)
-	assert.Equal(t, int(5), consulCli.cache.count)
-	assert.Equal(t, float64(15), consulCli.cache.mostRecentMod)
-
-	update, _ = consulCli.IsUpToDate(ctx)
-	assert.True(t, update)
-	provider.AssertExpectations(t)
-	kv.AssertExpectations(t)
+	start := time.Now()
+	ch := consulCli.Stream(ctx)
+
+	changes := receiveChanges(t, ch)
+	require.Len(t, changes.Schedule, 1)
+
+	// the change is only read once the interval since the last read elapsed
+	server.put("datadog/tpl/nginx/instances", "[{\"port\": 8080}]")
+	changes = receiveChanges(t, ch)
+	assert.GreaterOrEqual(t, time.Since(start), interval)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, "{\"port\":8080}", string(changes.Schedule[0].Instances[0]))
 }
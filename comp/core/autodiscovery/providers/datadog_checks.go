@@ -0,0 +1,498 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/providers/names"
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/telemetry"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/util/flavor"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	datadogCheckScopeCluster = "cluster"
+	datadogCheckScopeNode    = "node"
+
+	// datadogCheckConditionValid is the status condition reporting whether
+	// the spec of a DatadogCheck could be turned into a check config.
+	datadogCheckConditionValid = "Valid"
+
+	// datadogCheckServicePrefix prefixes the service ID identifying the
+	// DatadogCheck a config comes from.
+	datadogCheckServicePrefix = "datadog_check://"
+)
+
+var gvrDatadogCheck = schema.GroupVersionResource{
+	Group:    "datadoghq.com",
+	Version:  "v1alpha1",
+	Resource: "datadogchecks",
+}
+
+// datadogCheck is a DatadogCheck custom resource, it holds the configuration
+// of a single check.
+type datadogCheck struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   datadogCheckSpec   `json:"spec"`
+	Status datadogCheckStatus `json:"status,omitempty"`
+}
+
+type datadogCheckSpec struct {
+	// CheckName is the name of the check to run.
+	CheckName string `json:"checkName"`
+
+	// Scope is `cluster` (the default) to dispatch the check to a cluster
+	// check runner, or `node` to run it on node agents.
+	Scope string `json:"scope,omitempty"`
+
+	InitConfig json.RawMessage   `json:"initConfig,omitempty"`
+	Instances  []json.RawMessage `json:"instances,omitempty"`
+	Logs       []json.RawMessage `json:"logs,omitempty"`
+
+	// Selectors turn the config into a template resolved for the matching
+	// services. Node scoped checks require adIdentifiers or expressions,
+	// cluster scoped checks are scheduled as-is without kubeServices.
+	Selectors datadogCheckSelectors `json:"selectors,omitempty"`
+}
+
+type datadogCheckSelectors struct {
+	// ADIdentifiers are autodiscovery identifiers, e.g. container image
+	// short names (node scope only).
+	ADIdentifiers []string `json:"adIdentifiers,omitempty"`
+
+	// Expressions are autodiscovery selector expressions (node scope only).
+	Expressions []string `json:"expressions,omitempty"`
+
+	// KubeServices are the kubernetes services to run the check against,
+	// they default to the namespace of the DatadogCheck (cluster scope only).
+	KubeServices []datadogCheckObjectReference `json:"kubeServices,omitempty"`
+}
+
+type datadogCheckObjectReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type datadogCheckStatus struct {
+	ObservedGeneration int64              `json:"observedGeneration,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// DatadogCheckConfigProvider implements the ConfigProvider interface for
+// DatadogCheck custom resources. The cluster agent schedules the cluster
+// scoped ones, which are dispatched as cluster checks, and reports the
+// validity of every resource in its status. Node agents schedule the node
+// scoped ones.
+//
+// Every node agent lists and watches DatadogCheck resources in all the
+// namespaces, which adds one watch per node on the apiserver and requires
+// the node agents to be granted list and watch on datadogchecks
+// cluster-wide. The resources are few and rarely updated, so the load is
+// comparable to the one of the other informers of the node agent.
+type DatadogCheckConfigProvider struct {
+	informerClient dynamic.Interface
+	client         dynamic.Interface
+	scope          string
+	// isLeader is nil when the status of resources must not be updated
+	isLeader       func() bool
+	telemetryStore *telemetry.Store
+
+	mu           sync.RWMutex
+	configs      map[string][]integration.Config
+	configErrors map[string]ErrorMsgSet
+}
+
+var _ StreamingConfigProvider = &DatadogCheckConfigProvider{}
+
+// NewDatadogCheckConfigProvider returns a new ConfigProvider watching DatadogCheck resources.
+func NewDatadogCheckConfigProvider(_ *pkgconfigsetup.ConfigurationProviders, telemetryStore *telemetry.Store) (ConfigProvider, error) {
+	// Using GetAPIClient() (no retry)
+	ac, err := apiserver.GetAPIClient()
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to apiserver: %s", err)
+	}
+
+	if flavor.GetFlavor() != flavor.ClusterAgent {
+		return newDatadogCheckConfigProvider(ac.DynamicInformerCl, ac.DynamicCl, datadogCheckScopeNode, nil, telemetryStore), nil
+	}
+
+	isLeader := func() bool { return true }
+	if pkgconfigsetup.Datadog().GetBool("leader_election") {
+		isLeader = func() bool {
+			engine, err := leaderelection.GetLeaderEngine()
+			return err == nil && engine.IsLeader()
+		}
+	}
+	return newDatadogCheckConfigProvider(ac.DynamicInformerCl, ac.DynamicCl, datadogCheckScopeCluster, isLeader, telemetryStore), nil
+}
+
+func newDatadogCheckConfigProvider(informerClient, client dynamic.Interface, scope string, isLeader func() bool, telemetryStore *telemetry.Store) *DatadogCheckConfigProvider {
+	return &DatadogCheckConfigProvider{
+		informerClient: informerClient,
+		client:         client,
+		scope:          scope,
+		isLeader:       isLeader,
+		telemetryStore: telemetryStore,
+		configs:        make(map[string][]integration.Config),
+		configErrors:   make(map[string]ErrorMsgSet),
+	}
+}
+
+// String returns a string representation of the DatadogCheckConfigProvider
+func (p *DatadogCheckConfigProvider) String() string {
+	return names.DatadogChecks
+}
+
+// Stream watches DatadogCheck resources and sends the configs of the ones
+// matching the scope of the agent as they are added, updated or deleted.
+func (p *DatadogCheckConfigProvider) Stream(ctx context.Context) <-chan integration.ConfigChanges {
+	outCh := make(chan integration.ConfigChanges)
+
+	informer := dynamicinformer.NewFilteredDynamicInformer(p.informerClient, gvrDatadogCheck, metav1.NamespaceAll, 0, cache.Indexers{}, nil).Informer()
+	queue := workqueue.NewTypedRateLimitingQueueWithConfig(
+		workqueue.DefaultTypedControllerRateLimiter[string](),
+		workqueue.TypedRateLimitingQueueConfig[string]{Name: "datadogchecks"},
+	)
+
+	enqueue := func(obj interface{}) {
+		key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+		if err != nil {
+			log.Debugf("Unable to get the key of a DatadogCheck: %v", err)
+			return
+		}
+		queue.Add(key)
+	}
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    enqueue,
+		UpdateFunc: func(_, obj interface{}) { enqueue(obj) },
+		DeleteFunc: enqueue,
+	}); err != nil {
+		log.Errorf("Cannot add event handler to the DatadogCheck informer: %s", err)
+	}
+
+	go func() {
+		<-ctx.Done()
+		queue.ShutDown()
+	}()
+
+	go func() {
+		go informer.Run(ctx.Done())
+
+		// the informer never syncs when the CRD is not installed, the
+		// config poller must not wait for it forever
+		syncTimeout := pkgconfigsetup.Datadog().GetDuration("kube_cache_sync_timeout_seconds") * time.Second
+		syncCtx, cancel := context.WithTimeout(ctx, syncTimeout)
+		synced := cache.WaitForCacheSync(syncCtx.Done(), informer.HasSynced)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if !synced {
+			log.Warnf("Couldn't sync the DatadogCheck informer in %s, is the DatadogCheck CRD installed?", syncTimeout)
+		}
+
+		// the first changes hold every known config, the config poller
+		// waits for them
+		var changes integration.ConfigChanges
+		for _, key := range informer.GetStore().ListKeys() {
+			// status update errors are retried when the key is processed
+			// from the queue
+			_ = p.reconcile(ctx, informer.GetStore(), key, &changes)
+		}
+		select {
+		case outCh <- changes:
+		case <-ctx.Done():
+			return
+		}
+
+		for {
+			key, shutdown := queue.Get()
+			if shutdown {
+				return
+			}
+
+			var changes integration.ConfigChanges
+			if err := p.reconcile(ctx, informer.GetStore(), key, &changes); err != nil {
+				log.Warnf("Unable to update the status of DatadogCheck %s, will retry: %s", key, err)
+				queue.AddRateLimited(key)
+			} else {
+				queue.Forget(key)
+			}
+			queue.Done(key)
+
+			if changes.IsEmpty() {
+				continue
+			}
+			select {
+			case outCh <- changes:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return outCh
+}
+
+// reconcile adds to changes the configs added or removed since the last time
+// the DatadogCheck identified by key was seen, and updates its status.
+func (p *DatadogCheckConfigProvider) reconcile(ctx context.Context, store cache.Store, key string, changes *integration.ConfigChanges) error {
+	item, exists, err := store.GetByKey(key)
+	if err != nil {
+		return err
+	}
+
+	var obj *unstructured.Unstructured
+	var configs []integration.Config
+	var parseErr error
+	if exists {
+		var ok bool
+		if obj, ok = item.(*unstructured.Unstructured); !ok {
+			return fmt.Errorf("unexpected object type %T", item)
+		}
+		var check *datadogCheck
+		check, configs, parseErr = parseDatadogCheck(obj)
+		if parseErr != nil {
+			log.Errorf("Cannot parse DatadogCheck %s: %s", key, parseErr)
+		} else if check.scope() != p.scope {
+			configs = nil
+		}
+	}
+
+	p.mu.Lock()
+	previous := p.configs[key]
+	if len(configs) > 0 {
+		p.configs[key] = configs
+	} else {
+		delete(p.configs, key)
+	}
+	if parseErr != nil {
+		p.configErrors[key] = ErrorMsgSet{parseErr.Error(): struct{}{}}
+	} else {
+		delete(p.configErrors, key)
+	}
+	if p.telemetryStore != nil {
+		p.telemetryStore.Errors.Set(float64(len(p.configErrors)), names.DatadogChecks)
+	}
+	p.mu.Unlock()
+
+	diffDatadogCheckConfigs(previous, configs, changes)
+
+	if obj == nil || p.isLeader == nil || !p.isLeader() {
+		return nil
+	}
+	return p.updateStatus(ctx, obj, parseErr)
+}
+
+// updateStatus sets the Valid condition of a DatadogCheck if it changed.
+func (p *DatadogCheckConfigProvider) updateStatus(ctx context.Context, obj *unstructured.Unstructured, parseErr error) error {
+	var status datadogCheckStatus
+	if rawStatus, found := obj.Object["status"].(map[string]interface{}); found {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(rawStatus, &status); err != nil {
+			log.Debugf("Ignoring the invalid status of DatadogCheck %s/%s: %s", obj.GetNamespace(), obj.GetName(), err)
+			status = datadogCheckStatus{}
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               datadogCheckConditionValid,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             "ConfigAccepted",
+	}
+	if parseErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "InvalidSpec"
+		condition.Message = parseErr.Error()
+	}
+
+	changed := apimeta.SetStatusCondition(&status.Conditions, condition)
+	if !changed && status.ObservedGeneration == obj.GetGeneration() {
+		return nil
+	}
+	status.ObservedGeneration = obj.GetGeneration()
+
+	rawStatus, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return err
+	}
+	updated := obj.DeepCopy()
+	updated.Object["status"] = rawStatus
+
+	_, err = p.client.Resource(gvrDatadogCheck).Namespace(obj.GetNamespace()).UpdateStatus(ctx, updated, metav1.UpdateOptions{})
+	return err
+}
+
+// GetConfigErrors returns a map of configuration errors for each DatadogCheck
+func (p *DatadogCheckConfigProvider) GetConfigErrors() map[string]ErrorMsgSet {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	errors := make(map[string]ErrorMsgSet, len(p.configErrors))
+	for key, errs := range p.configErrors {
+		errors[key] = errs
+	}
+	return errors
+}
+
+// scope returns the scope of the check, cluster by default.
+func (c *datadogCheck) scope() string {
+	if c.Spec.Scope == "" {
+		return datadogCheckScopeCluster
+	}
+	return c.Spec.Scope
+}
+
+// parseDatadogCheck validates a DatadogCheck and builds its config.
+func parseDatadogCheck(obj *unstructured.Unstructured) (*datadogCheck, []integration.Config, error) {
+	raw, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, nil, err
+	}
+	check := &datadogCheck{}
+	if err := json.Unmarshal(raw, check); err != nil {
+		return nil, nil, fmt.Errorf("invalid DatadogCheck: %s", err)
+	}
+	spec := check.Spec
+
+	if spec.CheckName == "" {
+		return nil, nil, errors.New("checkName is required")
+	}
+	if len(spec.Instances) == 0 && len(spec.Logs) == 0 {
+		return nil, nil, errors.New("at least one instance or logs config is required")
+	}
+
+	config := integration.Config{
+		Name:       spec.CheckName,
+		InitConfig: integration.Data("{}"),
+		Source:     "datadog_checks:" + obj.GetNamespace() + "/" + obj.GetName(),
+	}
+
+	if len(spec.InitConfig) > 0 && string(spec.InitConfig) != "null" {
+		if !isJSONObject(spec.InitConfig) {
+			return nil, nil, errors.New("initConfig must be an object")
+		}
+		config.InitConfig = integration.Data(spec.InitConfig)
+	}
+	for i, instance := range spec.Instances {
+		if !isJSONObject(instance) {
+			return nil, nil, fmt.Errorf("instance %d must be an object", i)
+		}
+		config.Instances = append(config.Instances, integration.Data(instance))
+	}
+	if len(spec.Logs) > 0 {
+		for i, logs := range spec.Logs {
+			if !isJSONObject(logs) {
+				return nil, nil, fmt.Errorf("logs config %d must be an object", i)
+			}
+		}
+		logs, err := json.Marshal(spec.Logs)
+		if err != nil {
+			return nil, nil, err
+		}
+		config.LogsConfig = integration.Data(logs)
+	}
+
+	selectors := spec.Selectors
+	switch check.scope() {
+	case datadogCheckScopeCluster:
+		if len(selectors.ADIdentifiers) > 0 || len(selectors.Expressions) > 0 {
+			return nil, nil, errors.New("adIdentifiers and expressions selectors require the node scope")
+		}
+		config.ClusterCheck = true
+		for _, svc := range selectors.KubeServices {
+			if svc.Name == "" {
+				return nil, nil, errors.New("kubeServices selectors require a name")
+			}
+			namespace := svc.Namespace
+			if namespace == "" {
+				namespace = obj.GetNamespace()
+			}
+			config.ADIdentifiers = append(config.ADIdentifiers, apiserver.EntityForServiceWithNames(namespace, svc.Name))
+		}
+
+	case datadogCheckScopeNode:
+		if len(selectors.KubeServices) > 0 {
+			return nil, nil, errors.New("kubeServices selectors require the cluster scope")
+		}
+		// without selectors, the check would run on every node agent of
+		// the cluster
+		if len(selectors.ADIdentifiers) == 0 && len(selectors.Expressions) == 0 {
+			return nil, nil, errors.New("the node scope requires adIdentifiers or expressions selectors")
+		}
+		config.ADIdentifiers = selectors.ADIdentifiers
+		for _, expression := range selectors.Expressions {
+			if _, err := integration.ParseSelector(expression); err != nil {
+				return nil, nil, fmt.Errorf("invalid selector expression %q: %s", expression, err)
+			}
+			config.AdvancedADIdentifiers = append(config.AdvancedADIdentifiers, integration.AdvancedADIdentifier{Selector: expression})
+		}
+
+	default:
+		return nil, nil, fmt.Errorf("unknown scope %q, expected %q or %q", spec.Scope, datadogCheckScopeCluster, datadogCheckScopeNode)
+	}
+
+	// The digest of a config doesn't cover its source, the DatadogCheck is
+	// part of the service ID so that identical specs in two resources are
+	// different configs. Resolved templates get the ID of their service
+	// instead. The logs scheduler expects a container entity as the service
+	// ID of the other configs, it's left empty when they collect logs.
+	if config.IsTemplate() || len(config.LogsConfig) == 0 {
+		config.ServiceID = datadogCheckServicePrefix + obj.GetNamespace() + "/" + obj.GetName()
+	}
+
+	return check, []integration.Config{config}, nil
+}
+
+// diffDatadogCheckConfigs adds to changes the configs of previous missing
+// from current and the other way around.
+func diffDatadogCheckConfigs(previous, current []integration.Config, changes *integration.ConfigChanges) {
+	currentDigests := make(map[string]struct{}, len(current))
+	for _, config := range current {
+		currentDigests[config.Digest()] = struct{}{}
+	}
+	previousDigests := make(map[string]struct{}, len(previous))
+	for _, config := range previous {
+		previousDigests[config.Digest()] = struct{}{}
+		if _, found := currentDigests[config.Digest()]; !found {
+			changes.UnscheduleConfig(config)
+		}
+	}
+	for _, config := range current {
+		if _, found := previousDigests[config.Digest()]; !found {
+			changes.ScheduleConfig(config)
+		}
+	}
+}
+
+func isJSONObject(raw json.RawMessage) bool {
+	var obj map[string]interface{}
+	return json.Unmarshal(raw, &obj) == nil && obj != nil
+}
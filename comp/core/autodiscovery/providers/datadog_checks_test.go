@@ -0,0 +1,298 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build kubeapiserver
+
+package providers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+)
+
+func newDatadogCheckObject(namespace, name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "datadoghq.com/v1alpha1",
+			"kind":       "DatadogCheck",
+			"metadata": map[string]interface{}{
+				"namespace":  namespace,
+				"name":       name,
+				"generation": int64(1),
+			},
+			"spec": spec,
+		},
+	}
+}
+
+func TestParseDatadogCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		spec        map[string]interface{}
+		expected    integration.Config
+		expectedErr string
+	}{
+		{
+			name: "cluster check",
+			spec: map[string]interface{}{
+				"checkName":  "http_check",
+				"initConfig": map[string]interface{}{"proxy": "none"},
+				"instances":  []interface{}{map[string]interface{}{"url": "http://example.com"}},
+			},
+			expected: integration.Config{
+				Name:         "http_check",
+				InitConfig:   integration.Data(`{"proxy":"none"}`),
+				Instances:    []integration.Data{integration.Data(`{"url":"http://example.com"}`)},
+				ServiceID:    "datadog_check://default/http",
+				ClusterCheck: true,
+				Source:       "datadog_checks:default/http",
+			},
+		},
+		{
+			name: "cluster check on kube services",
+			spec: map[string]interface{}{
+				"checkName": "nginx",
+				"scope":     "cluster",
+				"instances": []interface{}{map[string]interface{}{"nginx_status_url": "http://%%host%%/status"}},
+				"selectors": map[string]interface{}{
+					"kubeServices": []interface{}{
+						map[string]interface{}{"name": "nginx"},
+						map[string]interface{}{"name": "nginx", "namespace": "web"},
+					},
+				},
+			},
+			expected: integration.Config{
+				Name:          "nginx",
+				InitConfig:    integration.Data("{}"),
+				Instances:     []integration.Data{integration.Data(`{"nginx_status_url":"http://%%host%%/status"}`)},
+				ADIdentifiers: []string{"kube_service://default/nginx", "kube_service://web/nginx"},
+				ServiceID:     "datadog_check://default/http",
+				ClusterCheck:  true,
+				Source:        "datadog_checks:default/http",
+			},
+		},
+		{
+			name: "node check with logs",
+			spec: map[string]interface{}{
+				"checkName": "redisdb",
+				"scope":     "node",
+				"instances": []interface{}{map[string]interface{}{"host": "%%host%%"}},
+				"logs":      []interface{}{map[string]interface{}{"source": "redis"}},
+				"selectors": map[string]interface{}{
+					"adIdentifiers": []interface{}{"redis"},
+					"expressions":   []interface{}{"pod.labels.app == redis"},
+				},
+			},
+			expected: integration.Config{
+				Name:                  "redisdb",
+				InitConfig:            integration.Data("{}"),
+				Instances:             []integration.Data{integration.Data(`{"host":"%%host%%"}`)},
+				LogsConfig:            integration.Data(`[{"source":"redis"}]`),
+				ADIdentifiers:         []string{"redis"},
+				AdvancedADIdentifiers: []integration.AdvancedADIdentifier{{Selector: "pod.labels.app == redis"}},
+				ServiceID:             "datadog_check://default/http",
+				Source:                "datadog_checks:default/http",
+			},
+		},
+		{
+			name: "cluster check with logs",
+			spec: map[string]interface{}{
+				"checkName": "http_check",
+				"instances": []interface{}{map[string]interface{}{"url": "http://example.com"}},
+				"logs":      []interface{}{map[string]interface{}{"type": "tcp", "port": 10514}},
+			},
+			expected: integration.Config{
+				Name:         "http_check",
+				InitConfig:   integration.Data("{}"),
+				Instances:    []integration.Data{integration.Data(`{"url":"http://example.com"}`)},
+				LogsConfig:   integration.Data(`[{"port":10514,"type":"tcp"}]`),
+				ClusterCheck: true,
+				Source:       "datadog_checks:default/http",
+			},
+		},
+		{
+			name:        "missing check name",
+			spec:        map[string]interface{}{"instances": []interface{}{map[string]interface{}{}}},
+			expectedErr: "checkName is required",
+		},
+		{
+			name:        "no instances",
+			spec:        map[string]interface{}{"checkName": "http_check"},
+			expectedErr: "at least one instance or logs config is required",
+		},
+		{
+			name: "instance not an object",
+			spec: map[string]interface{}{
+				"checkName": "http_check",
+				"instances": []interface{}{"url"},
+			},
+			expectedErr: "instance 0 must be an object",
+		},
+		{
+			name: "unknown scope",
+			spec: map[string]interface{}{
+				"checkName": "http_check",
+				"scope":     "pod",
+				"instances": []interface{}{map[string]interface{}{}},
+			},
+			expectedErr: `unknown scope "pod", expected "cluster" or "node"`,
+		},
+		{
+			name: "node selectors on a cluster check",
+			spec: map[string]interface{}{
+				"checkName": "redisdb",
+				"instances": []interface{}{map[string]interface{}{}},
+				"selectors": map[string]interface{}{"adIdentifiers": []interface{}{"redis"}},
+			},
+			expectedErr: "adIdentifiers and expressions selectors require the node scope",
+		},
+		{
+			name: "kube services on a node check",
+			spec: map[string]interface{}{
+				"checkName": "nginx",
+				"scope":     "node",
+				"instances": []interface{}{map[string]interface{}{}},
+				"selectors": map[string]interface{}{"kubeServices": []interface{}{map[string]interface{}{"name": "nginx"}}},
+			},
+			expectedErr: "kubeServices selectors require the cluster scope",
+		},
+		{
+			name: "node check without selectors",
+			spec: map[string]interface{}{
+				"checkName": "redisdb",
+				"scope":     "node",
+				"instances": []interface{}{map[string]interface{}{}},
+			},
+			expectedErr: "the node scope requires adIdentifiers or expressions selectors",
+		},
+		{
+			name: "invalid expression",
+			spec: map[string]interface{}{
+				"checkName": "redisdb",
+				"scope":     "node",
+				"instances": []interface{}{map[string]interface{}{}},
+				"selectors": map[string]interface{}{"expressions": []interface{}{"pod.labels.app in redis"}},
+			},
+			expectedErr: `invalid selector expression "pod.labels.app in redis"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, configs, err := parseDatadogCheck(newDatadogCheckObject("default", "http", tc.spec))
+			if tc.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, configs, 1)
+			assert.Equal(t, tc.expected, configs[0])
+		})
+	}
+}
+
+func TestParseDatadogCheckDigest(t *testing.T) {
+	spec := map[string]interface{}{
+		"checkName": "http_check",
+		"instances": []interface{}{map[string]interface{}{"url": "http://example.com"}},
+	}
+
+	// identical specs in two namespaces are different configs
+	_, defaultConfigs, err := parseDatadogCheck(newDatadogCheckObject("default", "http", spec))
+	require.NoError(t, err)
+	_, webConfigs, err := parseDatadogCheck(newDatadogCheckObject("web", "http", spec))
+	require.NoError(t, err)
+	assert.NotEqual(t, defaultConfigs[0].Digest(), webConfigs[0].Digest())
+}
+
+func TestDatadogCheckStream(t *testing.T) {
+	mockConfig := configmock.New(t)
+	mockConfig.SetWithoutSource("kube_cache_sync_timeout_seconds", 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clusterCheck := newDatadogCheckObject("default", "http", map[string]interface{}{
+		"checkName": "http_check",
+		"instances": []interface{}{map[string]interface{}{"url": "http://example.com"}},
+	})
+	nodeCheck := newDatadogCheckObject("default", "redis", map[string]interface{}{
+		"checkName": "redisdb",
+		"scope":     "node",
+		"instances": []interface{}{map[string]interface{}{"host": "%%host%%"}},
+		"selectors": map[string]interface{}{"adIdentifiers": []interface{}{"redis"}},
+	})
+
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(
+		runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvrDatadogCheck: "DatadogCheckList"},
+		clusterCheck, nodeCheck,
+	)
+	resources := client.Resource(gvrDatadogCheck).Namespace("default")
+	provider := newDatadogCheckConfigProvider(client, client, datadogCheckScopeCluster, func() bool { return true }, nil)
+	ch := provider.Stream(ctx)
+
+	// only cluster scoped checks are scheduled by the cluster agent
+	changes := receiveChanges(t, ch)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, "http_check", changes.Schedule[0].Name)
+	assert.True(t, changes.Schedule[0].ClusterCheck)
+	assert.Empty(t, changes.Unschedule)
+
+	// every check is validated and reported in its status
+	assertValidCondition := func(name string, status metav1.ConditionStatus, message string) {
+		t.Helper()
+		assert.EventuallyWithT(t, func(c *assert.CollectT) {
+			obj, err := resources.Get(ctx, name, metav1.GetOptions{})
+			require.NoError(c, err)
+			conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+			require.Len(c, conditions, 1)
+			condition := conditions[0].(map[string]interface{})
+			assert.Equal(c, datadogCheckConditionValid, condition["type"])
+			assert.Equal(c, string(status), condition["status"])
+			assert.Contains(c, condition["message"], message)
+		}, 5*time.Second, 50*time.Millisecond)
+	}
+	assertValidCondition("http", metav1.ConditionTrue, "")
+	assertValidCondition("redis", metav1.ConditionTrue, "")
+
+	// updating a check replaces its config
+	updated, err := resources.Get(ctx, "http", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NoError(t, unstructured.SetNestedSlice(updated.Object, []interface{}{map[string]interface{}{"url": "http://example.org"}}, "spec", "instances"))
+	_, err = resources.Update(ctx, updated, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	changes = receiveChanges(t, ch)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, `{"url":"http://example.org"}`, string(changes.Schedule[0].Instances[0]))
+	require.Len(t, changes.Unschedule, 1)
+	assert.Equal(t, `{"url":"http://example.com"}`, string(changes.Unschedule[0].Instances[0]))
+
+	// an invalid check is reported in its status and the config errors
+	_, err = resources.Create(ctx, newDatadogCheckObject("default", "invalid", map[string]interface{}{
+		"instances": []interface{}{map[string]interface{}{}},
+	}), metav1.CreateOptions{})
+	require.NoError(t, err)
+	assertValidCondition("invalid", metav1.ConditionFalse, "checkName is required")
+	assert.Contains(t, provider.GetConfigErrors(), "default/invalid")
+
+	require.NoError(t, resources.Delete(ctx, "http", metav1.DeleteOptions{}))
+	changes = receiveChanges(t, ch)
+	assert.Empty(t, changes.Schedule)
+	require.Len(t, changes.Unschedule, 1)
+	assert.Equal(t, "http_check", changes.Unschedule[0].Name)
+}
@@ -10,7 +10,6 @@ package providers
 import (
 	"context"
 	"fmt"
-	"math"
 	"strings"
 	"time"
 
@@ -26,16 +25,22 @@ import (
 
 type etcdBackend interface {
 	Get(ctx context.Context, key string, opts *client.GetOptions) (*client.Response, error)
+	Watcher(key string, opts *client.WatcherOptions) client.Watcher
 }
 
 // EtcdConfigProvider implements the Config Provider interface
-// It should be called periodically and returns templates from etcd for AutoConf.
+// It streams templates from etcd for AutoConf.
 type EtcdConfigProvider struct {
 	Client      etcdBackend
 	templateDir string
-	cache       *providerCache
+
+	// lastIndex is the etcd index at the last collect, watches start
+	// right after it.
+	lastIndex uint64
 }
 
+var _ StreamingConfigProvider = &EtcdConfigProvider{}
+
 // NewEtcdConfigProvider creates a client connection to etcd and create a new EtcdConfigProvider
 func NewEtcdConfigProvider(providerConfig *pkgconfigsetup.ConfigurationProviders, _ *telemetry.Store) (ConfigProvider, error) {
 	if providerConfig == nil {
@@ -57,37 +62,68 @@ func NewEtcdConfigProvider(providerConfig *pkgconfigsetup.ConfigurationProviders
 	if err != nil {
 		return nil, fmt.Errorf("Unable to instantiate the etcd client: %s", err)
 	}
-	cache := newProviderCache()
 	c := client.NewKeysAPI(cl)
-	return &EtcdConfigProvider{Client: c, templateDir: providerConfig.TemplateDir, cache: cache}, nil
+	return &EtcdConfigProvider{Client: c, templateDir: providerConfig.TemplateDir}, nil
+}
+
+// Stream sends the templates found in etcd and then watches the template
+// directory to send incremental updates.
+func (p *EtcdConfigProvider) Stream(ctx context.Context) <-chan integration.ConfigChanges {
+	return streamKVConfigs(ctx, p)
 }
 
-// Collect retrieves templates from etcd, builds Config objects and returns them
-// TODO: cache templates and last-modified index to avoid future full crawl if no template changed.
-func (p *EtcdConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+// collect retrieves templates from etcd, builds Config objects and returns them
+func (p *EtcdConfigProvider) collect(ctx context.Context) ([]integration.Config, map[string]struct{}, error) {
 	configs := make([]integration.Config, 0)
-	identifiers := p.getIdentifiers(ctx, p.templateDir)
+	failed := make(map[string]struct{})
+	identifiers, index, err := p.getIdentifiers(ctx, p.templateDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.lastIndex = index
+
 	for _, id := range identifiers {
-		templates := p.getTemplates(ctx, id)
+		source := "etcd:" + id
+		templates, err := p.getTemplates(ctx, id)
+		if err != nil {
+			log.Warnf("Failed to read the templates of %s: %s", id, err)
+			failed[source] = struct{}{}
+			continue
+		}
 
 		for idx := range templates {
-			templates[idx].Source = "etcd:" + id
+			templates[idx].Source = source
 		}
 
 		configs = append(configs, templates...)
 	}
-	return configs, nil
+	return configs, failed, nil
+}
+
+// waitForChange watches the template directory for any change made after
+// the last collect.
+func (p *EtcdConfigProvider) waitForChange(ctx context.Context) error {
+	watcher := p.Client.Watcher(p.templateDir, &client.WatcherOptions{AfterIndex: p.lastIndex, Recursive: true})
+	_, err := watcher.Next(ctx)
+	if err != nil {
+		// etcd only keeps a limited event history, a watch starting too far
+		// in the past is answered by this error and a new read is needed.
+		if etcdErr, ok := err.(client.Error); ok && etcdErr.Code == client.ErrorCodeEventIndexCleared {
+			return nil
+		}
+		return fmt.Errorf("watch on %s failed: %s", p.templateDir, err)
+	}
+	return nil
 }
 
 // getIdentifiers gets folders at the root of the TemplateDir
 // verifies they have the right content to be a valid template
-// and return their names.
-func (p *EtcdConfigProvider) getIdentifiers(ctx context.Context, key string) []string {
+// and return their names along with the current etcd index.
+func (p *EtcdConfigProvider) getIdentifiers(ctx context.Context, key string) ([]string, uint64, error) {
 	identifiers := make([]string, 0)
 	resp, err := p.Client.Get(ctx, key, &client.GetOptions{Recursive: true})
 	if err != nil {
-		log.Error("Can't get templates keys from etcd: ", err)
-		return identifiers
+		return nil, 0, fmt.Errorf("can't get templates keys from etcd: %s", err)
 	}
 	children := resp.Node.Nodes
 	for _, node := range children {
@@ -96,105 +132,63 @@ func (p *EtcdConfigProvider) getIdentifiers(ctx context.Context, key string) []s
 			identifiers = append(identifiers, split[len(split)-1])
 		}
 	}
-	return identifiers
+	return identifiers, resp.Index, nil
 }
 
 // getTemplates takes a path and returns a slice of templates if it finds
-// sufficient data under this path to build one.
-func (p *EtcdConfigProvider) getTemplates(ctx context.Context, key string) []integration.Config {
+// sufficient data under this path to build one. It only returns an error
+// when the data can't be read from etcd.
+func (p *EtcdConfigProvider) getTemplates(ctx context.Context, key string) ([]integration.Config, error) {
 	checkNameKey := buildStoreKey(key, checkNamePath)
 	initKey := buildStoreKey(key, initConfigPath)
 	instanceKey := buildStoreKey(key, instancePath)
 
-	checkNames, err := p.getCheckNames(ctx, checkNameKey)
+	rawNames, err := p.getEtcdValue(ctx, checkNameKey)
 	if err != nil {
-		log.Errorf("Failed to retrieve check names at %s. Error: %s", checkNameKey, err)
-		return nil
+		return nil, err
 	}
-
-	initConfigs, err := p.getJSONValue(ctx, initKey)
+	rawInitConfigs, err := p.getEtcdValue(ctx, initKey)
 	if err != nil {
-		log.Errorf("Failed to retrieve init configs at %s. Error: %s", initKey, err)
-		return nil
+		return nil, err
 	}
-
-	instances, err := p.getJSONValue(ctx, instanceKey)
+	rawInstances, err := p.getEtcdValue(ctx, instanceKey)
 	if err != nil {
-		log.Errorf("Failed to retrieve instances at %s. Error: %s", instanceKey, err)
-		return nil
+		return nil, err
 	}
 
-	return utils.BuildTemplates(key, checkNames, initConfigs, instances, false, "")
-}
-
-// getEtcdValue retrieves content from etcd
-func (p *EtcdConfigProvider) getEtcdValue(ctx context.Context, key string) (string, error) {
-	resp, err := p.Client.Get(ctx, key, nil)
+	checkNames, err := utils.ParseCheckNames(rawNames)
 	if err != nil {
-		return "", fmt.Errorf("Failed to retrieve %s from etcd: %s", key, err)
+		log.Errorf("Failed to retrieve check names at %s. Error: %s", checkNameKey, err)
+		return nil, nil
 	}
 
-	return resp.Node.Value, nil
-}
-
-func (p *EtcdConfigProvider) getCheckNames(ctx context.Context, key string) ([]string, error) {
-	rawNames, err := p.getEtcdValue(ctx, key)
+	initConfigs, err := utils.ParseJSONValue(rawInitConfigs)
 	if err != nil {
-		err := fmt.Errorf("Couldn't get check names from etcd: %s", err)
-		return nil, err
+		log.Errorf("Failed to retrieve init configs at %s. Error: %s", initKey, err)
+		return nil, nil
 	}
 
-	return utils.ParseCheckNames(rawNames)
-}
-
-func (p *EtcdConfigProvider) getJSONValue(ctx context.Context, key string) ([][]integration.Data, error) {
-	rawValue, err := p.getEtcdValue(ctx, key)
+	instances, err := utils.ParseJSONValue(rawInstances)
 	if err != nil {
-		return nil, fmt.Errorf("Couldn't get key %s from etcd: %s", key, err)
+		log.Errorf("Failed to retrieve instances at %s. Error: %s", instanceKey, err)
+		return nil, nil
 	}
 
-	return utils.ParseJSONValue(rawValue)
+	return utils.BuildTemplates(key, checkNames, initConfigs, instances, false, ""), nil
 }
 
-// IsUpToDate updates the list of AD templates versions in the Agent's cache and checks the list is up to date compared to ETCD's data.
-func (p *EtcdConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
-
-	adListUpdated := false
-	dateIdx := p.cache.mostRecentMod
-
-	resp, err := p.Client.Get(ctx, p.templateDir, &client.GetOptions{Recursive: true})
-	if err != nil {
-		return false, err
+// getEtcdValue retrieves content from etcd. A key removed since the
+// identifiers were listed is read as an empty value.
+func (p *EtcdConfigProvider) getEtcdValue(ctx context.Context, key string) (string, error) {
+	resp, err := p.Client.Get(ctx, key, nil)
+	if client.IsKeyNotFound(err) {
+		return "", nil
 	}
-	identifiers := resp.Node.Nodes
-
-	// When a node is deleted the Modified time of the children processed isn't changed.
-	if p.cache.count != len(identifiers) {
-		if p.cache.count != 0 {
-			log.Debugf("List of AD Template was modified, updating cache.")
-			adListUpdated = true
-		}
-		log.Debugf("Initializing cache for %v", p.String())
-		p.cache.count = len(identifiers)
+	if err != nil {
+		return "", fmt.Errorf("Failed to retrieve %s from etcd: %s", key, err)
 	}
 
-	for _, identifier := range identifiers {
-		if len(identifier.Nodes) != 3 {
-			log.Infof("%v does not have a correct format to be considered in the cache", identifier.Key)
-			continue
-		}
-		for _, tplkey := range identifier.Nodes {
-			dateIdx = math.Max(float64(tplkey.ModifiedIndex), dateIdx)
-		}
-	}
-	if dateIdx > p.cache.mostRecentMod || adListUpdated {
-		log.Debugf("Idx was %v and is now %v", p.cache.mostRecentMod, dateIdx)
-		p.cache.mostRecentMod = dateIdx
-		log.Infof("cache updated for %v", p.String())
-		return false, nil
-	}
-	log.Infof("cache up to date for %v", p.String())
-	return true, nil
+	return resp.Node.Value, nil
 }
 
 // String returns a string representation of the EtcdConfigProvider
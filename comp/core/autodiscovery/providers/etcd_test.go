@@ -9,11 +9,20 @@ package providers
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.etcd.io/etcd/client/v2"
+
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
 )
 
 type etcdTest struct {
@@ -29,6 +38,11 @@ func (m *etcdTest) Get(ctx context.Context, key string, opts *client.GetOptions)
 	return nil, args.Error(1)
 }
 
+func (m *etcdTest) Watcher(key string, opts *client.WatcherOptions) client.Watcher {
+	args := m.Called(key, opts)
+	return args.Get(0).(client.Watcher)
+}
+
 func createTestNode(key string) *client.Node {
 	return &client.Node{
 		Key:           key,
@@ -80,11 +94,14 @@ func TestGetIdentifiers(t *testing.T) {
 	adTemplate := []*client.Node{nginx}
 	configPath.Nodes = adTemplate
 	resp.Node = configPath
+	resp.Index = 42
 
 	backend.On("Get", context.Background(), "/datadog/check_configs", &client.GetOptions{Recursive: true}).Return(resp, nil).Times(1)
 	etcd := EtcdConfigProvider{Client: backend, templateDir: "/datadog/check_configs"}
-	array := etcd.getIdentifiers(ctx, "/datadog/check_configs")
+	array, index, err := etcd.getIdentifiers(ctx, "/datadog/check_configs")
 
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(42), index)
 	assert.Len(t, array, 1)
 	assert.Equal(t, array, []string{"nginx"})
 
@@ -101,77 +118,162 @@ func TestGetIdentifiers(t *testing.T) {
 	resp.Node = badConf
 	backend.On("Get", context.Background(), "/datadog/check_configs", &client.GetOptions{Recursive: true}).Return(resp, nil)
 
-	errArray := etcd.getIdentifiers(ctx, "/datadog/check_configs")
+	errArray, _, err := etcd.getIdentifiers(ctx, "/datadog/check_configs")
 
+	assert.NoError(t, err)
 	assert.Len(t, errArray, 0)
 	assert.Equal(t, errArray, []string{})
 
 	backend.AssertExpectations(t)
 }
 
-func TestETCDIsUpToDate(t *testing.T) {
-	// We want to check:
-	// The cache is properly initialized
-	// mostRecentMod and count are properly set
-	// If the number of ADTemplate is modified we update
-	// If nothing changed we don't update
+// etcdKeysServer is an in-process stand-in for the etcd v2 keys API
+// supporting recursive reads and watches.
+type etcdKeysServer struct {
+	*httptest.Server
 
-	ctx := context.Background()
-	backend := &etcdTest{}
-	resp := new(client.Response)
+	mu      sync.Mutex
+	index   uint64
+	values  map[string]*client.Node
+	events  []*client.Response
+	changed chan struct{}
+}
 
-	configPath := new(client.Node)
-	node1 := createTestNode("check_names")
-	node2 := createTestNode("init_configs")
-	node3 := createTestNode("instances")
-	nodes := []*client.Node{node1, node2, node3}
-	configPath.Key = "/datadog/check_configs/"
-	nginx := &client.Node{
-		Key:   "/datadog/check_configs/nginx",
-		Dir:   true,
-		Nodes: nodes,
+func newEtcdKeysServer(t *testing.T) *etcdKeysServer {
+	s := &etcdKeysServer{
+		values:  map[string]*client.Node{},
+		changed: make(chan struct{}),
 	}
-	adTemplate := []*client.Node{nginx}
-	configPath.Nodes = adTemplate
-	resp.Node = configPath
+	s.Server = httptest.NewServer(s)
+	t.Cleanup(s.Close)
+	return s
+}
 
-	backend.On("Get", context.Background(), "/datadog/check_configs", &client.GetOptions{Recursive: true}).Return(resp, nil).Times(1)
-	cache := newProviderCache()
-	etcd := EtcdConfigProvider{Client: backend, templateDir: "/datadog/check_configs", cache: cache}
-	update, _ := etcd.IsUpToDate(ctx)
-
-	assert.False(t, update)
-	assert.Equal(t, float64(123456), etcd.cache.mostRecentMod)
-	assert.Equal(t, 1, etcd.cache.count)
-
-	node4 := &client.Node{
-		Key:           "instances",
-		Value:         "val",
-		CreatedIndex:  123457,
-		ModifiedIndex: 9000000,
-		TTL:           123456789,
+func (s *etcdKeysServer) record(action, key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.index++
+	node := &client.Node{Key: key, Value: value, ModifiedIndex: s.index}
+	if action == "delete" {
+		delete(s.values, key)
+	} else {
+		s.values[key] = node
 	}
-	nodes = []*client.Node{node1, node2, node4}
-	apache := &client.Node{
-		Key:   "/datadog/check_configs/nginx",
-		Dir:   true,
-		Nodes: nodes,
+	s.events = append(s.events, &client.Response{Action: action, Node: node})
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *etcdKeysServer) set(key, value string) {
+	s.record("set", key, value)
+}
+
+func (s *etcdKeysServer) delete(key string) {
+	s.record("delete", key, "")
+}
+
+// dir builds the directory node of key from the stored values.
+func (s *etcdKeysServer) dir(key string) *client.Node {
+	node := &client.Node{Key: key, Dir: true}
+	children := map[string]struct{}{}
+	for k := range s.values {
+		if rest, found := strings.CutPrefix(k, key+"/"); found {
+			children[key+"/"+strings.Split(rest, "/")[0]] = struct{}{}
+		}
 	}
-	adTemplate = []*client.Node{nginx, apache}
-	configPath.Nodes = adTemplate
-	resp.Node = configPath
-	backend.On("Get", context.Background(), "/datadog/check_configs", &client.GetOptions{Recursive: true}).Return(resp, nil).Times(1)
-	update, _ = etcd.IsUpToDate(ctx)
+	for child := range children {
+		if value, found := s.values[child]; found {
+			node.Nodes = append(node.Nodes, value)
+		} else {
+			node.Nodes = append(node.Nodes, s.dir(child))
+		}
+	}
+	return node
+}
 
-	assert.False(t, update)
-	assert.Equal(t, float64(9000000), etcd.cache.mostRecentMod)
-	assert.Equal(t, 2, etcd.cache.count)
+func (s *etcdKeysServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/v2/keys")
+	query := r.URL.Query()
 
-	backend.On("Get", context.Background(), "/datadog/check_configs", &client.GetOptions{Recursive: true}).Return(resp, nil).Times(1)
-	update, _ = etcd.IsUpToDate(ctx)
+	var resp *client.Response
+	var index uint64
+	for resp == nil {
+		s.mu.Lock()
+		var changed chan struct{}
+		index, changed = s.index, s.changed
+		if query.Get("wait") == "true" {
+			waitIndex, _ := strconv.ParseUint(query.Get("waitIndex"), 10, 64)
+			for _, event := range s.events {
+				if event.Node.ModifiedIndex >= waitIndex && strings.HasPrefix(event.Node.Key, key+"/") {
+					resp = event
+					break
+				}
+			}
+		} else if value, found := s.values[key]; found {
+			resp = &client.Response{Action: "get", Node: value}
+		} else if node := s.dir(key); len(node.Nodes) > 0 {
+			resp = &client.Response{Action: "get", Node: node}
+		} else {
+			s.mu.Unlock()
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(client.Error{Code: client.ErrorCodeKeyNotFound, Message: "Key not found", Cause: key, Index: index})
+			return
+		}
+		s.mu.Unlock()
 
-	assert.True(t, update)
-	assert.Equal(t, float64(9000000), etcd.cache.mostRecentMod)
-	assert.Equal(t, 2, etcd.cache.count)
-	backend.AssertExpectations(t)
+		if resp == nil {
+			select {
+			case <-changed:
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+
+	w.Header().Set("X-Etcd-Index", strconv.FormatUint(index, 10))
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func TestEtcdStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockConfig := configmock.New(t)
+	mockConfig.SetWithoutSource("autoconf_template_dir", "/datadog/tpl")
+
+	server := newEtcdKeysServer(t)
+	server.set("/datadog/tpl/nginx/check_names", "[\"nginx\"]")
+	server.set("/datadog/tpl/nginx/init_configs", "[{}]")
+	server.set("/datadog/tpl/nginx/instances", "[{\"port\": 80}]")
+
+	cl, err := client.New(client.Config{Endpoints: []string{server.URL}, Transport: client.DefaultTransport})
+	require.NoError(t, err)
+	etcd := &EtcdConfigProvider{Client: client.NewKeysAPI(cl), templateDir: "/datadog/tpl"}
+	ch := etcd.Stream(ctx)
+
+	changes := receiveChanges(t, ch)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, "nginx", changes.Schedule[0].Name)
+	assert.Equal(t, "etcd:nginx", changes.Schedule[0].Source)
+	assert.Empty(t, changes.Unschedule)
+
+	// updating a value replaces the config
+	server.set("/datadog/tpl/nginx/instances", "[{\"port\": 8080}]")
+	changes = receiveChanges(t, ch)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, "{\"port\":8080}", string(changes.Schedule[0].Instances[0]))
+	require.Len(t, changes.Unschedule, 1)
+	assert.Equal(t, "{\"port\":80}", string(changes.Unschedule[0].Instances[0]))
+
+	server.set("/datadog/tpl/redis/check_names", "[\"redisdb\"]")
+	server.set("/datadog/tpl/redis/init_configs", "[{}]")
+	server.set("/datadog/tpl/redis/instances", "[{}]")
+	changes = receiveChanges(t, ch)
+	assert.Equal(t, []string{"redisdb"}, configNames(changes.Schedule))
+	assert.Empty(t, changes.Unschedule)
+
+	server.delete("/datadog/tpl/nginx/check_names")
+	changes = receiveChanges(t, ch)
+	assert.Empty(t, changes.Schedule)
+	assert.Equal(t, []string{"nginx"}, configNames(changes.Unschedule))
 }
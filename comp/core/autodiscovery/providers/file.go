@@ -28,7 +28,8 @@ func NewFileConfigProvider(telemetryStore *telemetry.Store) *FileConfigProvider
 }
 
 // Collect returns the check configurations defined in Yaml files.
-// Configs with advanced AD identifiers are filtered-out. They're handled by other file-based config providers.
+// Configs with advanced AD identifiers targeting kubernetes services or endpoints are filtered-out.
+// They're handled by other file-based config providers.
 //
 //nolint:revive // TODO(AML) Fix revive linter
 func (c *FileConfigProvider) Collect(_ context.Context) ([]integration.Config, error) {
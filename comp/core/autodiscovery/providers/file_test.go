@@ -50,6 +50,10 @@ func TestCollect(t *testing.T) {
 	assert.Equal(t, 3, len(get("testcheck")))
 	assert.Equal(t, 1, len(get("ad")))
 
+	// configs with selectors only are collected, unlike the ones targeting kubernetes services
+	assert.Equal(t, 1, len(get("selector_ad")))
+	assert.Equal(t, 0, len(get("advanced_ad")))
+
 	// default configs must be picked up
 	assert.Equal(t, 1, len(get("bar")))
 
@@ -82,7 +86,7 @@ func TestCollect(t *testing.T) {
 	assert.Equal(t, 0, len(get("ignored")))
 
 	// total number of configurations found
-	assert.Equal(t, 17, len(configs))
+	assert.Equal(t, 18, len(configs))
 
 	// incorrect configs get saved in the Errors map (invalid.yaml & notaconfig.yaml & ad_deprecated.yaml & null_instances.yml)
 	assert.Equal(t, 4, len(provider.Errors))
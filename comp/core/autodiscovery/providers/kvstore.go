@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build consul || etcd || zk
+
+package providers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/util/backoff"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// kvBackoffMinFactor, kvBackoffBase and kvBackoffMax (in seconds)
+	// control how fast a provider retries after losing its key/value store.
+	kvBackoffMinFactor = 2
+	kvBackoffBase      = 1
+	kvBackoffMax       = 60
+)
+
+// kvWatcher is implemented by the providers reading templates from a
+// key/value store (Consul, etcd, Zookeeper).
+type kvWatcher interface {
+	fmt.Stringer
+
+	// collect reads every template from the store and remembers the store
+	// revision they were read at. The sources of the templates that couldn't
+	// be read are returned apart so that their previous configs are kept.
+	collect(ctx context.Context) (configs []integration.Config, failed map[string]struct{}, err error)
+
+	// waitForChange blocks until the store moves past the revision
+	// remembered by the last call to collect.
+	waitForChange(ctx context.Context) error
+}
+
+// streamKVConfigs reads the templates of a key/value store and sends the
+// configs added or removed since the previous read every time the store
+// changes. Errors are retried with an exponential backoff, and so are the
+// templates that couldn't be read, whose previous configs are kept until then.
+// A first (possibly empty) set of changes is always sent so that the config
+// poller does not block when the store is unreachable at startup.
+func streamKVConfigs(ctx context.Context, w kvWatcher) <-chan integration.ConfigChanges {
+	outCh := make(chan integration.ConfigChanges)
+
+	go func() {
+		policy := backoff.NewExpBackoffPolicy(kvBackoffMinFactor, kvBackoffBase, kvBackoffMax, 0, true)
+		numErrors := 0
+		sentOnce := false
+		current := map[string]integration.Config{}
+
+		send := func(changes integration.ConfigChanges) bool {
+			select {
+			case outCh <- changes:
+				sentOnce = true
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			configs, failed, err := w.collect(ctx)
+			if err == nil {
+				changes := diffKVConfigs(current, configs, failed)
+				if (!changes.IsEmpty() || !sentOnce) && !send(changes) {
+					return
+				}
+
+				if len(failed) > 0 {
+					err = fmt.Errorf("failed to read the templates of %d identifiers", len(failed))
+				} else {
+					numErrors = policy.DecError(numErrors)
+					err = w.waitForChange(ctx)
+				}
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err != nil {
+				if !sentOnce && !send(integration.ConfigChanges{}) {
+					return
+				}
+
+				numErrors = policy.IncError(numErrors)
+				delay := policy.GetBackoffDuration(numErrors)
+				log.Warnf("%s provider: %s, retrying in %s", w, err, delay)
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(delay):
+				}
+			}
+		}
+	}()
+
+	return outCh
+}
+
+// diffKVConfigs updates current, indexed by digest, to match configs and
+// returns the corresponding changes. The configs of the failed sources are
+// kept as they are.
+func diffKVConfigs(current map[string]integration.Config, configs []integration.Config, failed map[string]struct{}) integration.ConfigChanges {
+	var changes integration.ConfigChanges
+
+	seen := make(map[string]struct{}, len(configs))
+	for _, config := range configs {
+		digest := config.Digest()
+		seen[digest] = struct{}{}
+		if _, found := current[digest]; !found {
+			current[digest] = config
+			changes.ScheduleConfig(config)
+		}
+	}
+
+	for digest, config := range current {
+		if _, found := failed[config.Source]; found {
+			continue
+		}
+		if _, found := seen[digest]; !found {
+			delete(current, digest)
+			changes.UnscheduleConfig(config)
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build consul || etcd || zk
+
+package providers
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
+)
+
+type kvWatcherMock struct {
+	collects chan kvCollectResult
+	changes  chan struct{}
+}
+
+type kvCollectResult struct {
+	configs []integration.Config
+	failed  map[string]struct{}
+	err     error
+}
+
+func (w *kvWatcherMock) String() string {
+	return "mock"
+}
+
+func (w *kvWatcherMock) collect(ctx context.Context) ([]integration.Config, map[string]struct{}, error) {
+	select {
+	case res := <-w.collects:
+		return res.configs, res.failed, res.err
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+func (w *kvWatcherMock) waitForChange(ctx context.Context) error {
+	select {
+	case <-w.changes:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// configNames returns the sorted names of configs.
+func configNames(configs []integration.Config) []string {
+	names := make([]string, 0, len(configs))
+	for _, config := range configs {
+		names = append(names, config.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestDiffKVConfigs(t *testing.T) {
+	nginx := integration.Config{Name: "nginx", ADIdentifiers: []string{"nginx"}, Instances: []integration.Data{integration.Data("{}")}}
+	redis := integration.Config{Name: "redis", ADIdentifiers: []string{"redis"}, Instances: []integration.Data{integration.Data("{}")}, Source: "kv:redis"}
+	redisUpdated := integration.Config{Name: "redis", ADIdentifiers: []string{"redis"}, Instances: []integration.Data{integration.Data("{\"port\":6380}")}, Source: "kv:redis"}
+
+	current := map[string]integration.Config{}
+
+	changes := diffKVConfigs(current, []integration.Config{nginx, redis}, nil)
+	assert.Equal(t, []string{"nginx", "redis"}, configNames(changes.Schedule))
+	assert.Empty(t, changes.Unschedule)
+
+	changes = diffKVConfigs(current, []integration.Config{nginx, redis}, nil)
+	assert.True(t, changes.IsEmpty())
+
+	changes = diffKVConfigs(current, []integration.Config{nginx, redisUpdated}, nil)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, redisUpdated.Digest(), changes.Schedule[0].Digest())
+	require.Len(t, changes.Unschedule, 1)
+	assert.Equal(t, redis.Digest(), changes.Unschedule[0].Digest())
+
+	// the configs of the templates that couldn't be read are kept
+	changes = diffKVConfigs(current, []integration.Config{nginx}, map[string]struct{}{"kv:redis": {}})
+	assert.True(t, changes.IsEmpty())
+
+	changes = diffKVConfigs(current, nil, nil)
+	assert.Empty(t, changes.Schedule)
+	assert.Equal(t, []string{"nginx", "redis"}, configNames(changes.Unschedule))
+	assert.Empty(t, current)
+}
+
+func TestStreamKVConfigs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	nginx := integration.Config{Name: "nginx", ADIdentifiers: []string{"nginx"}, Source: "kv:nginx"}
+	redis := integration.Config{Name: "redis", ADIdentifiers: []string{"redis"}, Source: "kv:redis"}
+
+	w := &kvWatcherMock{
+		collects: make(chan kvCollectResult, 1),
+		changes:  make(chan struct{}, 1),
+	}
+	ch := streamKVConfigs(ctx, w)
+
+	// an unreachable store must not block the config poller
+	w.collects <- kvCollectResult{err: errors.New("connection refused")}
+	changes := receiveChanges(t, ch)
+	assert.True(t, changes.IsEmpty())
+
+	// the collect is retried after a backoff
+	w.collects <- kvCollectResult{configs: []integration.Config{nginx}}
+	changes = receiveChanges(t, ch)
+	assert.Equal(t, []string{"nginx"}, configNames(changes.Schedule))
+	assert.Empty(t, changes.Unschedule)
+
+	// a change without any template update sends nothing
+	w.changes <- struct{}{}
+	w.collects <- kvCollectResult{configs: []integration.Config{nginx}}
+
+	w.changes <- struct{}{}
+	w.collects <- kvCollectResult{configs: []integration.Config{redis}}
+	changes = receiveChanges(t, ch)
+	assert.Equal(t, []string{"redis"}, configNames(changes.Schedule))
+	assert.Equal(t, []string{"nginx"}, configNames(changes.Unschedule))
+
+	// a template that couldn't be read is kept and read again after a
+	// backoff, without waiting for a change
+	w.changes <- struct{}{}
+	w.collects <- kvCollectResult{configs: []integration.Config{nginx}, failed: map[string]struct{}{"kv:redis": {}}}
+	changes = receiveChanges(t, ch)
+	assert.Equal(t, []string{"nginx"}, configNames(changes.Schedule))
+	assert.Empty(t, changes.Unschedule)
+
+	w.collects <- kvCollectResult{configs: []integration.Config{nginx}}
+	changes = receiveChanges(t, ch)
+	assert.Empty(t, changes.Schedule)
+	assert.Equal(t, []string{"redis"}, configNames(changes.Unschedule))
+}
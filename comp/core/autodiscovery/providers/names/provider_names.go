@@ -13,6 +13,7 @@ const (
 	Container          = "container"
 	CloudFoundryBBS    = "cloudfoundry-bbs"
 	ClusterChecks      = "cluster-checks"
+	DatadogChecks      = "datadog-checks"
 	EndpointsChecks    = "endpoints-checks"
 	Etcd               = "etcd"
 	File               = "file"
@@ -37,6 +38,7 @@ const (
 const (
 	ConsulRegisterName             = "consul"
 	ClusterChecksRegisterName      = "clusterchecks"
+	DatadogChecksRegisterName      = "datadog_checks"
 	EndpointsChecksRegisterName    = "endpointschecks"
 	EtcdRegisterName               = "etcd"
 	KubeletRegisterName            = "kubelet"
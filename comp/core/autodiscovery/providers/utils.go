@@ -42,40 +42,9 @@ func GetPollInterval(cp pkgconfigsetup.ConfigurationProviders) time.Duration {
 	return pkgconfigsetup.Datadog().GetDuration("ad_config_poll_interval") * time.Second
 }
 
-// providerCache supports monitoring a service for changes either to the number
-// of things being monitored, or to one of those things being modified.  This
-// can be used to determine IsUpToDate() and avoid full Collect() calls when
-// nothing has changed.
-// nolint needed as this type is defined in a file without a build tag,
-// but only used in multiple files with different build tags, none of which
-// are used in the IoT Agent.
-//
-//nolint:unused
-type providerCache struct {
-	// mostRecentMod is the most recent modification timestamp of a
-	// monitored thing
-	mostRecentMod float64
-
-	// count is the number of monitored things
-	count int
-}
-
 // ErrorMsgSet contains a list of unique configuration errors for a provider
 type ErrorMsgSet map[string]struct{}
 
-// newProviderCache instantiate a ProviderCache.
-// nolint needed as this function is defined in a file without a build tag,
-// but only used in multiple files with different build tags, none of which
-// are used in the IoT Agent.
-//
-//nolint:deadcode,unused
-func newProviderCache() *providerCache {
-	return &providerCache{
-		mostRecentMod: 0,
-		count:         0,
-	}
-}
-
 // ignoreADTagsFromAnnotations returns of the `ad.datadoghq.com/{endpoints,service}.ignore_autodiscovery_tags` annotation
 // TODO(CINT)(Agent 7.53+) Remove support for hybrid scenarios
 //
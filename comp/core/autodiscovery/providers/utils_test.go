@@ -10,7 +10,9 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/DataDog/datadog-agent/comp/core/autodiscovery/integration"
 	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
 )
 
@@ -39,3 +41,15 @@ func TestGetPollInterval(t *testing.T) {
 	}
 	assert.Equal(t, GetPollInterval(cp), 1*time.Second)
 }
+
+// receiveChanges waits for the next changes sent by a streaming provider.
+func receiveChanges(t *testing.T, ch <-chan integration.ConfigChanges) integration.ConfigChanges {
+	t.Helper()
+	select {
+	case changes := <-ch:
+		return changes
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "timeout waiting for config changes")
+	}
+	return integration.ConfigChanges{}
+}
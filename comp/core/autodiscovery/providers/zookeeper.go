@@ -9,9 +9,10 @@ package providers
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math"
 	"path"
+	"reflect"
 	"strings"
 	"time"
 
@@ -29,17 +30,32 @@ const sessionTimeout = 1 * time.Second
 
 type zkBackend interface {
 	Get(key string) ([]byte, *zk.Stat, error)
+	GetW(key string) ([]byte, *zk.Stat, <-chan zk.Event, error)
 	Children(key string) ([]string, *zk.Stat, error)
+	ChildrenW(key string) ([]string, *zk.Stat, <-chan zk.Event, error)
 }
 
-// ZookeeperConfigProvider implements the Config Provider interface It should
-// be called periodically and returns templates from Zookeeper for AutoConf.
+// zkWatch identifies a watch on the data or on the children of a node
+type zkWatch struct {
+	path     string
+	children bool
+}
+
+// ZookeeperConfigProvider implements the Config Provider interface It
+// streams templates from Zookeeper for AutoConf.
 type ZookeeperConfigProvider struct {
 	client      zkBackend
 	templateDir string
-	cache       *providerCache
+
+	// watches are set on every node read by collect, any of them firing
+	// means the templates have to be read again. Zookeeper watches are
+	// one-shot and can't be removed, so a node is only watched again once
+	// its pending watch fired.
+	watches map[zkWatch]<-chan zk.Event
 }
 
+var _ StreamingConfigProvider = &ZookeeperConfigProvider{}
+
 // NewZookeeperConfigProvider returns a new Client connected to a Zookeeper backend.
 func NewZookeeperConfigProvider(providerConfig *pkgconfigsetup.ConfigurationProviders, _ *telemetry.Store) (ConfigProvider, error) {
 	if providerConfig == nil {
@@ -52,11 +68,9 @@ func NewZookeeperConfigProvider(providerConfig *pkgconfigsetup.ConfigurationProv
 	if err != nil {
 		return nil, fmt.Errorf("ZookeeperConfigProvider: couldn't connect to %q (%s): %s", providerConfig.TemplateURL, strings.Join(urls, ", "), err)
 	}
-	cache := newProviderCache()
 	return &ZookeeperConfigProvider{
 		client:      c,
 		templateDir: providerConfig.TemplateDir,
-		cache:       cache,
 	}, nil
 }
 
@@ -65,92 +79,99 @@ func (z *ZookeeperConfigProvider) String() string {
 	return names.Zookeeper
 }
 
-// Collect retrieves templates from Zookeeper, builds Config objects and returns them
-// TODO: cache templates and last-modified index to avoid future full crawl if no template changed.
-//
-//nolint:revive // TODO(CINT) Fix revive linter
-func (z *ZookeeperConfigProvider) Collect(ctx context.Context) ([]integration.Config, error) {
+// Stream sends the templates found in Zookeeper and then watches the nodes
+// they were read from to send incremental updates.
+func (z *ZookeeperConfigProvider) Stream(ctx context.Context) <-chan integration.ConfigChanges {
+	return streamKVConfigs(ctx, z)
+}
+
+// collect retrieves templates from Zookeeper, builds Config objects and
+// returns them. Every node read is watched.
+func (z *ZookeeperConfigProvider) collect(_ context.Context) ([]integration.Config, map[string]struct{}, error) {
 	configs := make([]integration.Config, 0)
-	identifiers, err := z.getIdentifiers(z.templateDir)
+	failed := make(map[string]struct{})
+	identifiers, unreadable, err := z.getIdentifiers(z.templateDir)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	for _, id := range unreadable {
+		failed["zookeeper:"+id] = struct{}{}
 	}
 	for _, id := range identifiers {
-		c := z.getTemplates(id)
+		source := "zookeeper:" + id
+		c, err := z.getTemplates(id)
+		if err != nil {
+			log.Warnf("Failed to read the templates of '%s': %s", id, err)
+			failed[source] = struct{}{}
+			continue
+		}
 
 		for idx := range c {
-			c[idx].Source = "zookeeper:" + id
+			c[idx].Source = source
 		}
 
 		configs = append(configs, c...)
 	}
-	return configs, nil
+	return configs, failed, nil
 }
 
-// IsUpToDate updates the list of AD templates versions in the Agent's cache and checks the list is up to date compared to Zookeeper's data.
-//
-//nolint:revive // TODO(CINT) Fix revive linter
-func (z *ZookeeperConfigProvider) IsUpToDate(ctx context.Context) (bool, error) {
-
-	identifiers, err := z.getIdentifiers(z.templateDir)
-	if err != nil {
-		return false, err
+// waitForChange blocks until one of the pending watches fires. Only the
+// watch that fired is set again by the next collect.
+func (z *ZookeeperConfigProvider) waitForChange(ctx context.Context) error {
+	keys := make([]zkWatch, 0, len(z.watches))
+	cases := make([]reflect.SelectCase, 0, len(z.watches)+1)
+	cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+	for key, watch := range z.watches {
+		keys = append(keys, key)
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(watch)})
 	}
-	outdated := z.cache.mostRecentMod
-	adListUpdated := false
 
-	if z.cache.count != len(identifiers) {
-		if z.cache.count == 0 {
-			log.Infof("Initializing cache for %v", z.String())
-		}
-		log.Debugf("List of AD Template was modified, updating cache.")
-		adListUpdated = true
-		z.cache.count = len(identifiers)
+	chosen, value, ok := reflect.Select(cases)
+	if chosen == 0 {
+		return ctx.Err()
+	}
+	delete(z.watches, keys[chosen-1])
+	if !ok {
+		return nil
 	}
 
-	for _, identifier := range identifiers {
-		gChildren, _, err := z.client.Children(identifier)
-
-		if err != nil {
-			return false, err
-		}
-		for _, gcn := range gChildren {
-			gcnPath := path.Join(identifier, gcn)
-			_, stat, err := z.client.Get(gcnPath)
-			if err != nil {
-				return false, fmt.Errorf("couldn't get key '%s' from zookeeper: %s", identifier, err)
-			}
-			outdated = math.Max(float64(stat.Mtime), outdated)
-		}
+	event := value.Interface().(zk.Event)
+	if event.Type == zk.EventNotWatching {
+		// the client drops every watch when its session expires or when
+		// it's closed, all the nodes have to be watched again.
+		z.watches = nil
 	}
-	if outdated > z.cache.mostRecentMod || adListUpdated {
-		log.Debugf("Idx was %v and is now %v", z.cache.mostRecentMod, outdated)
-		z.cache.mostRecentMod = outdated
-		log.Infof("cache updated for %v", z.String())
-		return false, nil
+	if event.Err != nil {
+		return fmt.Errorf("watch on '%s' failed: %s", event.Path, event.Err)
 	}
-	log.Infof("cache up to date for %v", z.String())
-	return true, nil
+	log.Debugf("zookeeper event %s on '%s', reading templates again", event.Type, event.Path)
+	return nil
 }
 
 // getIdentifiers gets folders at the root of the template dir
 // verifies they have the right content to be a valid template
-// and return their names.
-func (z *ZookeeperConfigProvider) getIdentifiers(key string) ([]string, error) {
+// and return their names, along with the folders that couldn't be listed.
+func (z *ZookeeperConfigProvider) getIdentifiers(key string) ([]string, []string, error) {
 	identifiers := []string{}
+	unreadable := []string{}
 
-	children, _, err := z.client.Children(key)
+	children, err := z.getChildren(key)
 	if err != nil {
-		return nil, fmt.Errorf("Failed to list '%s' to get identifiers from zookeeper: %s", key, err)
+		return nil, nil, fmt.Errorf("Failed to list '%s' to get identifiers from zookeeper: %s", key, err)
 	}
 
 	for _, child := range children {
 		nodePath := path.Join(key, child)
-		nodes, _, err := z.client.Children(nodePath)
+		nodes, err := z.getChildren(nodePath)
+		if errors.Is(err, zk.ErrNoNode) {
+			continue
+		}
 		if err != nil {
 			log.Warnf("could not list keys in '%s': %s", nodePath, err)
+			unreadable = append(unreadable, nodePath)
 			continue
-		} else if len(nodes) < 3 {
+		}
+		if len(nodes) < 3 {
 			continue
 		}
 
@@ -164,50 +185,100 @@ func (z *ZookeeperConfigProvider) getIdentifiers(key string) ([]string, error) {
 			identifiers = append(identifiers, nodePath)
 		}
 	}
-	return identifiers, nil
+	return identifiers, unreadable, nil
 }
 
 // getTemplates takes a path and returns a slice of templates if it finds
-// sufficient data under this path to build one.
-func (z *ZookeeperConfigProvider) getTemplates(key string) []integration.Config {
+// sufficient data under this path to build one. It only returns an error
+// when the nodes can't be read from zookeeper.
+func (z *ZookeeperConfigProvider) getTemplates(key string) ([]integration.Config, error) {
 	checkNameKey := path.Join(key, checkNamePath)
 	initKey := path.Join(key, initConfigPath)
 	instanceKey := path.Join(key, instancePath)
 
-	rawNames, _, err := z.client.Get(checkNameKey)
+	rawNames, err := z.getValue(checkNameKey)
 	if err != nil {
-		log.Errorf("Couldn't get check names from key '%s' in zookeeper: %s", key, err)
-		return nil
+		return nil, fmt.Errorf("Couldn't get check names from key '%s' in zookeeper: %s", key, err)
+	}
+	rawInitConfigs, err := z.getValue(initKey)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get key '%s' from zookeeper: %s", initKey, err)
+	}
+	rawInstances, err := z.getValue(instanceKey)
+	if err != nil {
+		return nil, fmt.Errorf("Couldn't get key '%s' from zookeeper: %s", instanceKey, err)
 	}
 
 	checkNames, err := utils.ParseCheckNames(string(rawNames))
 	if err != nil {
 		log.Errorf("Failed to retrieve check names at %s. Error: %s", checkNameKey, err)
-		return nil
+		return nil, nil
 	}
 
-	initConfigs, err := z.getJSONValue(initKey)
+	initConfigs, err := utils.ParseJSONValue(string(rawInitConfigs))
 	if err != nil {
 		log.Errorf("Failed to retrieve init configs at %s. Error: %s", initKey, err)
-		return nil
+		return nil, nil
 	}
 
-	instances, err := z.getJSONValue(instanceKey)
+	instances, err := utils.ParseJSONValue(string(rawInstances))
 	if err != nil {
 		log.Errorf("Failed to retrieve instances at %s. Error: %s", instanceKey, err)
-		return nil
+		return nil, nil
 	}
 
-	return utils.BuildTemplates(key, checkNames, initConfigs, instances, false, "")
+	return utils.BuildTemplates(key, checkNames, initConfigs, instances, false, ""), nil
 }
 
-func (z *ZookeeperConfigProvider) getJSONValue(key string) ([][]integration.Data, error) {
-	rawValue, _, err := z.client.Get(key)
+// getChildren lists the children of a node and watches them for changes,
+// unless a watch is already pending.
+func (z *ZookeeperConfigProvider) getChildren(key string) ([]string, error) {
+	watchKey := zkWatch{path: key, children: true}
+	if _, found := z.watches[watchKey]; found {
+		children, _, err := z.client.Children(key)
+		return children, err
+	}
+
+	children, _, watch, err := z.client.ChildrenW(key)
 	if err != nil {
-		return nil, fmt.Errorf("Couldn't get key '%s' from zookeeper: %s", key, err)
+		return nil, err
 	}
+	z.addWatch(watchKey, watch)
+	return children, nil
+}
 
-	return utils.ParseJSONValue(string(rawValue))
+// getValue reads a node and watches it for changes, unless a watch is
+// already pending. A node removed since the identifiers were listed is read
+// as an empty value.
+func (z *ZookeeperConfigProvider) getValue(key string) ([]byte, error) {
+	watchKey := zkWatch{path: key}
+	if _, found := z.watches[watchKey]; found {
+		value, _, err := z.client.Get(key)
+		if errors.Is(err, zk.ErrNoNode) {
+			return nil, nil
+		}
+		return value, err
+	}
+
+	value, _, watch, err := z.client.GetW(key)
+	if errors.Is(err, zk.ErrNoNode) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	z.addWatch(watchKey, watch)
+	return value, nil
+}
+
+func (z *ZookeeperConfigProvider) addWatch(key zkWatch, watch <-chan zk.Event) {
+	if watch == nil {
+		return
+	}
+	if z.watches == nil {
+		z.watches = make(map[zkWatch]<-chan zk.Event)
+	}
+	z.watches[key] = watch
 }
 
 // GetConfigErrors is not implemented for the ZookeeperConfigProvider
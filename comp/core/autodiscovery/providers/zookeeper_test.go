@@ -10,7 +10,11 @@ package providers
 import (
 	"context"
 	"fmt"
+	"path"
+	"sort"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
 	"github.com/stretchr/testify/assert"
@@ -26,30 +30,40 @@ type zkTest struct {
 	mock.Mock
 }
 
-func (m *zkTest) Get(key string) ([]byte, *zk.Stat, error) {
+func (m *zkTest) GetW(key string) ([]byte, *zk.Stat, <-chan zk.Event, error) {
 	args := m.Called(key)
 	array, arrOK := args.Get(0).([]byte)
 	stats, statsOK := args.Get(1).(*zk.Stat)
 	if arrOK && statsOK {
-		return array, stats, args.Error(2)
+		return array, stats, nil, args.Error(2)
 	}
 	if arrOK {
-		return array, nil, args.Error(2)
+		return array, nil, nil, args.Error(2)
 	}
-	return nil, nil, args.Error(2)
+	return nil, nil, nil, args.Error(2)
+}
+
+func (m *zkTest) Get(key string) ([]byte, *zk.Stat, error) {
+	value, stat, _, err := m.GetW(key)
+	return value, stat, err
 }
 
 func (m *zkTest) Children(key string) ([]string, *zk.Stat, error) {
+	children, stat, _, err := m.ChildrenW(key)
+	return children, stat, err
+}
+
+func (m *zkTest) ChildrenW(key string) ([]string, *zk.Stat, <-chan zk.Event, error) {
 	args := m.Called(key)
 	array, arrOK := args.Get(0).([]string)
 	stats, statsOK := args.Get(1).(*zk.Stat)
 	if arrOK && statsOK {
-		return array, stats, args.Error(2)
+		return array, stats, nil, args.Error(2)
 	}
 	if arrOK {
-		return array, nil, args.Error(2)
+		return array, nil, nil, args.Error(2)
 	}
-	return nil, nil, args.Error(2)
+	return nil, nil, nil, args.Error(2)
 }
 
 //
@@ -59,65 +73,73 @@ func (m *zkTest) Children(key string) ([]string, *zk.Stat, error) {
 func TestZKGetIdentifiers(t *testing.T) {
 	backend := &zkTest{}
 
-	backend.On("Children", "/test/").Return(nil, nil, fmt.Errorf("some error")).Times(1)
-	backend.On("Children", "/datadog/tpl").Return([]string{"nginx", "redis", "incomplete", "error"}, nil, nil).Times(1)
+	backend.On("ChildrenW", "/test/").Return(nil, nil, fmt.Errorf("some error")).Times(1)
+	backend.On("ChildrenW", "/datadog/tpl").Return([]string{"nginx", "redis", "incomplete", "error"}, nil, nil).Times(1)
 
 	expectedKeys := []string{checkNamePath, initConfigPath, instancePath}
-	backend.On("Children", "/datadog/tpl/nginx").Return(expectedKeys, nil, nil).Times(1)
-	backend.On("Children", "/datadog/tpl/redis").Return(append(expectedKeys, "an extra one"), nil, nil).Times(1)
-	backend.On("Children", "/datadog/tpl/incomplete").Return([]string{checkNamePath, "other one"}, nil, nil).Times(1)
-	backend.On("Children", "/datadog/tpl/error").Return(nil, nil, fmt.Errorf("some error")).Times(1)
+	backend.On("ChildrenW", "/datadog/tpl/nginx").Return(expectedKeys, nil, nil).Times(1)
+	backend.On("ChildrenW", "/datadog/tpl/redis").Return(append(expectedKeys, "an extra one"), nil, nil).Times(1)
+	backend.On("ChildrenW", "/datadog/tpl/incomplete").Return([]string{checkNamePath, "other one"}, nil, nil).Times(1)
+	backend.On("ChildrenW", "/datadog/tpl/error").Return(nil, nil, fmt.Errorf("some error")).Times(1)
 
 	zk := ZookeeperConfigProvider{client: backend}
 
-	res, err := zk.getIdentifiers("/test/")
+	res, unreadable, err := zk.getIdentifiers("/test/")
 	assert.Nil(t, res)
+	assert.Nil(t, unreadable)
 	assert.NotNil(t, err)
 
-	res, err = zk.getIdentifiers("/datadog/tpl")
+	res, unreadable, err = zk.getIdentifiers("/datadog/tpl")
 	require.Nil(t, err)
 
 	assert.Len(t, res, 2)
 	assert.Equal(t, []string{"/datadog/tpl/nginx", "/datadog/tpl/redis"}, res)
+	assert.Equal(t, []string{"/datadog/tpl/error"}, unreadable)
 	backend.AssertExpectations(t)
 }
 
 func TestZKGetTemplates(t *testing.T) {
 	backend := &zkTest{}
 
-	backend.On("Get", "/error1/check_names").Return(nil, nil, fmt.Errorf("some error")).Times(1)
+	backend.On("GetW", "/error1/check_names").Return(nil, nil, fmt.Errorf("some error")).Times(1)
 	zk := ZookeeperConfigProvider{client: backend}
-	res := zk.getTemplates("/error1/")
+	res, err := zk.getTemplates("/error1/")
+	assert.Error(t, err)
 	assert.Nil(t, res)
 
-	backend.On("Get", "/error2/check_names").Return([]byte("[\"first_name\"]"), nil, nil).Times(1)
-	backend.On("Get", "/error2/init_configs").Return(nil, nil, fmt.Errorf("some error")).Times(1)
-	res = zk.getTemplates("/error2/")
+	backend.On("GetW", "/error2/check_names").Return([]byte("[\"first_name\"]"), nil, nil).Times(1)
+	backend.On("GetW", "/error2/init_configs").Return(nil, nil, fmt.Errorf("some error")).Times(1)
+	res, err = zk.getTemplates("/error2/")
+	assert.Error(t, err)
 	assert.Nil(t, res)
 
-	backend.On("Get", "/error3/check_names").Return([]byte("[\"first_name\"]"), nil, nil).Times(1)
-	backend.On("Get", "/error3/init_configs").Return([]byte("[{}]"), nil, nil).Times(1)
-	backend.On("Get", "/error3/instances").Return(nil, nil, fmt.Errorf("some error")).Times(1)
-	res = zk.getTemplates("/error3/")
+	backend.On("GetW", "/error3/check_names").Return([]byte("[\"first_name\"]"), nil, nil).Times(1)
+	backend.On("GetW", "/error3/init_configs").Return([]byte("[{}]"), nil, nil).Times(1)
+	backend.On("GetW", "/error3/instances").Return(nil, nil, fmt.Errorf("some error")).Times(1)
+	res, err = zk.getTemplates("/error3/")
+	assert.Error(t, err)
 	assert.Nil(t, res)
 
-	backend.On("Get", "/error4/check_names").Return([]byte("[\"first_name\"]"), nil, nil).Times(1)
-	backend.On("Get", "/error4/instances").Return([]byte("[{}]"), nil, nil).Times(1)
-	backend.On("Get", "/error4/init_configs").Return([]byte("[{}, {}]"), nil, nil).Times(1)
-	res = zk.getTemplates("/error4/")
+	backend.On("GetW", "/error4/check_names").Return([]byte("[\"first_name\"]"), nil, nil).Times(1)
+	backend.On("GetW", "/error4/instances").Return([]byte("[{}]"), nil, nil).Times(1)
+	backend.On("GetW", "/error4/init_configs").Return([]byte("[{}, {}]"), nil, nil).Times(1)
+	res, err = zk.getTemplates("/error4/")
+	assert.NoError(t, err)
 	assert.Len(t, res, 0)
 
-	backend.On("Get", "/error5/check_names").Return([]byte(""), nil, nil).Times(1)
-	backend.On("Get", "/error5/instances").Return([]byte("[{}]"), nil, nil).Times(1)
-	backend.On("Get", "/error5/init_configs").Return([]byte("[{}]"), nil, nil).Times(1)
-	res = zk.getTemplates("/error5/")
+	backend.On("GetW", "/error5/check_names").Return([]byte(""), nil, nil).Times(1)
+	backend.On("GetW", "/error5/instances").Return([]byte("[{}]"), nil, nil).Times(1)
+	backend.On("GetW", "/error5/init_configs").Return([]byte("[{}]"), nil, nil).Times(1)
+	res, err = zk.getTemplates("/error5/")
+	assert.NoError(t, err)
 	assert.Len(t, res, 0)
 
-	backend.On("Get", "/config/check_names").Return([]byte("[\"first_name\", \"second_name\"]"), nil, nil).Times(1)
-	backend.On("Get", "/config/instances").Return([]byte("[{\"test\": 21, \"test2\": \"data\"}, {\"data1\": \"21\", \"data2\": {\"number\": 21}}]"), nil, nil).Times(1)
-	backend.On("Get", "/config/init_configs").Return([]byte("[{\"a\": \"b\"}, {}]"), nil, nil).Times(1)
+	backend.On("GetW", "/config/check_names").Return([]byte("[\"first_name\", \"second_name\"]"), nil, nil).Times(1)
+	backend.On("GetW", "/config/instances").Return([]byte("[{\"test\": 21, \"test2\": \"data\"}, {\"data1\": \"21\", \"data2\": {\"number\": 21}}]"), nil, nil).Times(1)
+	backend.On("GetW", "/config/init_configs").Return([]byte("[{\"a\": \"b\"}, {}]"), nil, nil).Times(1)
 	//zk = ZookeeperConfigProvider{client: backend}
-	res = zk.getTemplates("/config/")
+	res, err = zk.getTemplates("/config/")
+	assert.NoError(t, err)
 	assert.NotNil(t, res)
 	assert.Len(t, res, 2)
 
@@ -140,23 +162,24 @@ func TestZKCollect(t *testing.T) {
 	ctx := context.Background()
 	backend := &zkTest{}
 
-	backend.On("Children", "/datadog/check_configs").Return([]string{"other", "config_folder_1", "config_folder_2"}, nil, nil).Times(1)
-	backend.On("Children", "/datadog/check_configs/other").Return([]string{"test", "check_names"}, nil, nil).Times(1)
+	backend.On("ChildrenW", "/datadog/check_configs").Return([]string{"other", "config_folder_1", "config_folder_2"}, nil, nil).Times(1)
+	backend.On("ChildrenW", "/datadog/check_configs/other").Return([]string{"test", "check_names"}, nil, nil).Times(1)
 
-	backend.On("Children", "/datadog/check_configs/config_folder_1").Return([]string{"check_names", "instances", "init_configs"}, nil, nil).Times(1)
-	backend.On("Get", "/datadog/check_configs/config_folder_1/check_names").Return([]byte("[\"first_name\", \"second_name\"]"), nil, nil).Times(1)
-	backend.On("Get", "/datadog/check_configs/config_folder_1/instances").Return([]byte("[{}, {}]"), nil, nil).Times(1)
-	backend.On("Get", "/datadog/check_configs/config_folder_1/init_configs").Return([]byte("[{}, {}]"), nil, nil).Times(1)
+	backend.On("ChildrenW", "/datadog/check_configs/config_folder_1").Return([]string{"check_names", "instances", "init_configs"}, nil, nil).Times(1)
+	backend.On("GetW", "/datadog/check_configs/config_folder_1/check_names").Return([]byte("[\"first_name\", \"second_name\"]"), nil, nil).Times(1)
+	backend.On("GetW", "/datadog/check_configs/config_folder_1/instances").Return([]byte("[{}, {}]"), nil, nil).Times(1)
+	backend.On("GetW", "/datadog/check_configs/config_folder_1/init_configs").Return([]byte("[{}, {}]"), nil, nil).Times(1)
 
-	backend.On("Children", "/datadog/check_configs/config_folder_2").Return([]string{"check_names", "instances", "init_configs", "test"}, nil, nil).Times(1)
-	backend.On("Get", "/datadog/check_configs/config_folder_2/check_names").Return([]byte("[\"third_name\"]"), nil, nil).Times(1)
-	backend.On("Get", "/datadog/check_configs/config_folder_2/instances").Return([]byte("[{}]"), nil, nil).Times(1)
-	backend.On("Get", "/datadog/check_configs/config_folder_2/init_configs").Return([]byte("[{}]"), nil, nil).Times(1)
+	backend.On("ChildrenW", "/datadog/check_configs/config_folder_2").Return([]string{"check_names", "instances", "init_configs", "test"}, nil, nil).Times(1)
+	backend.On("GetW", "/datadog/check_configs/config_folder_2/check_names").Return([]byte("[\"third_name\"]"), nil, nil).Times(1)
+	backend.On("GetW", "/datadog/check_configs/config_folder_2/instances").Return([]byte("[{}]"), nil, nil).Times(1)
+	backend.On("GetW", "/datadog/check_configs/config_folder_2/init_configs").Return([]byte("[{}]"), nil, nil).Times(1)
 
 	zk := ZookeeperConfigProvider{client: backend, templateDir: "/datadog/check_configs"}
 
-	res, err := zk.Collect(ctx)
+	res, failed, err := zk.collect(ctx)
 	assert.Nil(t, err)
+	assert.Empty(t, failed)
 	assert.Len(t, res, 3)
 
 	assert.Len(t, res[0].ADIdentifiers, 1)
@@ -184,46 +207,247 @@ func TestZKCollect(t *testing.T) {
 	assert.Equal(t, "{}", string(res[2].Instances[0]))
 }
 
-func TestZKIsUpToDate(t *testing.T) {
-	// We want to check:
-	// The cache is properly initialized
-	// If one Mtime is newer than what was in cache we update
-	// If the number of ADTemplate is modified we update
-	// If nothing changed we don't update
+// zkTree is an in-process stand-in for a Zookeeper ensemble holding a tree of
+// nodes, firing one-shot watches and expiring sessions like Zookeeper does. It
+// stands in for the client rather than for a server: go-zookeeper doesn't ship
+// an embedded server, its test cluster needs a Java Zookeeper install.
+type zkTree struct {
+	sync.Mutex
+	nodes        map[string][]byte
+	dataWatches  map[string][]chan zk.Event
+	childWatches map[string][]chan zk.Event
+
+	// failures are returned by the reads of their node
+	failures    map[string]error
+	failedReads int
+}
 
-	ctx := context.Background()
-	backend := &zkTest{}
-	z := new(zk.Stat)
-	backend.On("Children", "/datadog/check_configs").Return([]string{"config_folder_1"}, nil, nil).Times(1)
-	expectedKeys := []string{checkNamePath, initConfigPath, instancePath}
-	backend.On("Children", "/datadog/check_configs/config_folder_1").Return(expectedKeys, nil, nil)
-	backend.On("Get", "/datadog/check_configs/config_folder_1/check_names").Return([]byte("[\"first_name\", \"second_name\"]"), z, nil)
-	backend.On("Get", "/datadog/check_configs/config_folder_1/instances").Return([]byte("[{}, {}]"), z, nil)
-	z.Mtime = int64(709662600)
-	backend.On("Get", "/datadog/check_configs/config_folder_1/init_configs").Return([]byte("[{}, {}]"), z, nil)
+func newZKTree() *zkTree {
+	return &zkTree{
+		nodes:        map[string][]byte{},
+		dataWatches:  map[string][]chan zk.Event{},
+		childWatches: map[string][]chan zk.Event{},
+		failures:     map[string]error{},
+	}
+}
 
-	cache := newProviderCache()
+// read returns the error of a failing node or zk.ErrNoNode if it's missing
+func (z *zkTree) read(key string) error {
+	if err, found := z.failures[key]; found {
+		z.failedReads++
+		return err
+	}
+	if _, found := z.nodes[key]; !found {
+		return zk.ErrNoNode
+	}
+	return nil
+}
 
-	zkr := ZookeeperConfigProvider{client: backend, templateDir: "/datadog/check_configs", cache: cache}
-	assert.Equal(t, float64(0), zkr.cache.mostRecentMod)
-	assert.Equal(t, int(0), zkr.cache.count)
+// fail makes the reads of a node fail with err, or succeed again if err is nil
+func (z *zkTree) fail(key string, err error) {
+	z.Lock()
+	defer z.Unlock()
+	if err == nil {
+		delete(z.failures, key)
+	} else {
+		z.failures[key] = err
+	}
+}
 
-	update, _ := zkr.IsUpToDate(ctx)
-	assert.False(t, update)
-	assert.Equal(t, float64(709662600), zkr.cache.mostRecentMod)
+func (z *zkTree) getFailedReads() int {
+	z.Lock()
+	defer z.Unlock()
+	return z.failedReads
+}
 
-	backend.On("Children", "/datadog/check_configs").Return([]string{"config_folder_1", "config_folder_2"}, nil, nil)
-	backend.On("Children", "/datadog/check_configs/config_folder_2").Return([]string{"check_names", "instances", "init_configs"}, z, nil)
+// expire drops every watch like the client does when its session expires.
+func (z *zkTree) expire() {
+	z.Lock()
+	defer z.Unlock()
+	for _, watches := range []map[string][]chan zk.Event{z.dataWatches, z.childWatches} {
+		for key, nodeWatches := range watches {
+			for _, watch := range nodeWatches {
+				watch <- zk.Event{Type: zk.EventNotWatching, State: zk.StateDisconnected, Path: key, Err: zk.ErrSessionExpired}
+				close(watch)
+			}
+			delete(watches, key)
+		}
+	}
+}
 
-	backend.On("Get", "/datadog/check_configs/config_folder_2/check_names").Return([]byte("[\"third_name\"]"), z, nil)
-	backend.On("Get", "/datadog/check_configs/config_folder_2/instances").Return([]byte("[{}]"), z, nil)
-	backend.On("Get", "/datadog/check_configs/config_folder_2/init_configs").Return([]byte("[{}]"), z, nil)
+func (z *zkTree) children(key string) []string {
+	children := []string{}
+	for p := range z.nodes {
+		if path.Dir(p) == key {
+			children = append(children, path.Base(p))
+		}
+	}
+	sort.Strings(children)
+	return children
+}
 
-	update, _ = zkr.IsUpToDate(ctx)
-	assert.False(t, update)
-	assert.Equal(t, int(2), zkr.cache.count)
+func (z *zkTree) Get(key string) ([]byte, *zk.Stat, error) {
+	z.Lock()
+	defer z.Unlock()
+	if err := z.read(key); err != nil {
+		return nil, nil, err
+	}
+	return z.nodes[key], &zk.Stat{}, nil
+}
 
-	update, _ = zkr.IsUpToDate(ctx)
-	assert.True(t, update)
-	backend.AssertExpectations(t)
+func (z *zkTree) Children(key string) ([]string, *zk.Stat, error) {
+	z.Lock()
+	defer z.Unlock()
+	if err := z.read(key); err != nil {
+		return nil, nil, err
+	}
+	return z.children(key), &zk.Stat{}, nil
+}
+
+// maxPendingWatches returns the highest number of watches pending on a node
+func (z *zkTree) maxPendingWatches() int {
+	z.Lock()
+	defer z.Unlock()
+	pending := 0
+	for _, watches := range []map[string][]chan zk.Event{z.dataWatches, z.childWatches} {
+		for _, nodeWatches := range watches {
+			pending = max(pending, len(nodeWatches))
+		}
+	}
+	return pending
+}
+
+func (z *zkTree) GetW(key string) ([]byte, *zk.Stat, <-chan zk.Event, error) {
+	z.Lock()
+	defer z.Unlock()
+	if err := z.read(key); err != nil {
+		return nil, nil, nil, err
+	}
+	watch := make(chan zk.Event, 1)
+	z.dataWatches[key] = append(z.dataWatches[key], watch)
+	return z.nodes[key], &zk.Stat{}, watch, nil
+}
+
+func (z *zkTree) ChildrenW(key string) ([]string, *zk.Stat, <-chan zk.Event, error) {
+	z.Lock()
+	defer z.Unlock()
+	if err := z.read(key); err != nil {
+		return nil, nil, nil, err
+	}
+	watch := make(chan zk.Event, 1)
+	z.childWatches[key] = append(z.childWatches[key], watch)
+	return z.children(key), &zk.Stat{}, watch, nil
+}
+
+func (z *zkTree) fire(watches map[string][]chan zk.Event, key string, eventType zk.EventType) {
+	for _, watch := range watches[key] {
+		watch <- zk.Event{Type: eventType, Path: key}
+	}
+	delete(watches, key)
+}
+
+// set creates or updates a node and its missing parents.
+func (z *zkTree) set(key, value string) {
+	z.Lock()
+	defer z.Unlock()
+	for p := key; p != "/"; p = path.Dir(p) {
+		if _, found := z.nodes[p]; found {
+			break
+		}
+		z.nodes[p] = nil
+		z.fire(z.childWatches, path.Dir(p), zk.EventNodeChildrenChanged)
+	}
+	z.nodes[key] = []byte(value)
+	z.fire(z.dataWatches, key, zk.EventNodeDataChanged)
+}
+
+func (z *zkTree) delete(key string) {
+	z.Lock()
+	defer z.Unlock()
+	delete(z.nodes, key)
+	z.fire(z.dataWatches, key, zk.EventNodeDeleted)
+	z.fire(z.childWatches, key, zk.EventNodeDeleted)
+	z.fire(z.childWatches, path.Dir(key), zk.EventNodeChildrenChanged)
+}
+
+func TestZKStream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tree := newZKTree()
+	tree.set("/datadog/check_configs/nginx/check_names", "[\"nginx\"]")
+	tree.set("/datadog/check_configs/nginx/init_configs", "[{}]")
+	tree.set("/datadog/check_configs/nginx/instances", "[{\"port\": 80}]")
+
+	zkr := &ZookeeperConfigProvider{client: tree, templateDir: "/datadog/check_configs"}
+	ch := zkr.Stream(ctx)
+
+	changes := receiveChanges(t, ch)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, "nginx", changes.Schedule[0].Name)
+	assert.Equal(t, "zookeeper:/datadog/check_configs/nginx", changes.Schedule[0].Source)
+	assert.Empty(t, changes.Unschedule)
+
+	// updating a value replaces the config
+	tree.set("/datadog/check_configs/nginx/instances", "[{\"port\": 8080}]")
+	changes = receiveChanges(t, ch)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, "{\"port\":8080}", string(changes.Schedule[0].Instances[0]))
+	require.Len(t, changes.Unschedule, 1)
+	assert.Equal(t, "{\"port\":80}", string(changes.Unschedule[0].Instances[0]))
+
+	// a new template is only complete once its three keys are set
+	tree.set("/datadog/check_configs/redis/check_names", "[\"redisdb\"]")
+	tree.set("/datadog/check_configs/redis/init_configs", "[{}]")
+	tree.set("/datadog/check_configs/redis/instances", "[{}]")
+	changes = receiveChanges(t, ch)
+	assert.Equal(t, []string{"redisdb"}, configNames(changes.Schedule))
+	assert.Empty(t, changes.Unschedule)
+
+	tree.delete("/datadog/check_configs/redis/instances")
+	changes = receiveChanges(t, ch)
+	assert.Empty(t, changes.Schedule)
+	assert.Equal(t, []string{"redisdb"}, configNames(changes.Unschedule))
+
+	// only the watches that fired are set again
+	assert.Equal(t, 1, tree.maxPendingWatches())
+}
+
+func TestZKStreamReadErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tree := newZKTree()
+	tree.set("/datadog/check_configs/nginx/check_names", "[\"nginx\"]")
+	tree.set("/datadog/check_configs/nginx/init_configs", "[{}]")
+	tree.set("/datadog/check_configs/nginx/instances", "[{\"port\": 80}]")
+
+	zkr := &ZookeeperConfigProvider{client: tree, templateDir: "/datadog/check_configs"}
+	ch := zkr.Stream(ctx)
+
+	changes := receiveChanges(t, ch)
+	require.Len(t, changes.Schedule, 1)
+
+	// the config of a template that can't be read is kept until it's read
+	// again
+	tree.fail("/datadog/check_configs/nginx/instances", zk.ErrConnectionClosed)
+	tree.set("/datadog/check_configs/nginx/init_configs", "[{}]")
+	require.Eventually(t, func() bool { return tree.getFailedReads() > 0 }, 10*time.Second, 10*time.Millisecond)
+	tree.fail("/datadog/check_configs/nginx/instances", nil)
+	tree.set("/datadog/check_configs/nginx/instances", "[{\"port\": 8080}]")
+
+	changes = receiveChanges(t, ch)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, "{\"port\":8080}", string(changes.Schedule[0].Instances[0]))
+	require.Len(t, changes.Unschedule, 1)
+	assert.Equal(t, "{\"port\":80}", string(changes.Unschedule[0].Instances[0]))
+
+	// every node is watched again once the session expired
+	tree.expire()
+	require.Eventually(t, func() bool { return tree.maxPendingWatches() == 1 }, 10*time.Second, 10*time.Millisecond)
+	tree.set("/datadog/check_configs/nginx/instances", "[{\"port\": 9090}]")
+
+	changes = receiveChanges(t, ch)
+	require.Len(t, changes.Schedule, 1)
+	assert.Equal(t, "{\"port\":9090}", string(changes.Schedule[0].Instances[0]))
 }
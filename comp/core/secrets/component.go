@@ -36,6 +36,8 @@ type Component interface {
 	Resolve(data []byte, origin string) ([]byte, error)
 	// SubscribeToChanges registers a callback to be invoked whenever secrets are resolved or refreshed
 	SubscribeToChanges(callback SecretChangeCallback)
+	// SubscribeToRefresh registers a callback to be invoked with the handles whose value changed when secrets are refreshed
+	SubscribeToRefresh(callback SecretRefreshCallback)
 	// Refresh will resolve secret handles again, notifying any subscribers of changed values
 	Refresh() (string, error)
 }
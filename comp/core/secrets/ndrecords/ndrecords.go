@@ -3,7 +3,10 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-present Datadog, Inc.
 
-package secretsimpl
+// Package ndrecords appends timestamped records to newline-delimited JSON files,
+// pruning old records and rotating the files that grow too large. It is used
+// to write the audit trails of the secrets.
+package ndrecords
 
 import (
 	"bufio"
@@ -22,33 +25,33 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
-// rotatingNDRecords allows adding timestamped entries to a file
+// RotatingNDRecords allows adding timestamped entries to a file
 // Adding an entry is efficient and simply appends it to a file, using newlines as a separator.
 // We keep this file relatively small in two ways: (1) old entries are pruned from the front of
 // the file, and (2) if the file size passes a threshold, it gets rotated to a file name that
 // looks like this: "file.000000.txt", "file.000001.txt", etc
-
-type rotatingNDRecords struct {
+type RotatingNDRecords struct {
 	filename string
-	cfg      config
+	cfg      Config
 	// time of the earliest entry in the file
 	firstEntry time.Time
 	// mtime of the oldest rotated file, nil for uninitialized, zero for "no files"
 	oldestFileMtime *time.Time
 }
 
-type config struct {
-	// how many spaces to use in rotated filenames
-	spacer int
-	// limit of each file, not to be exceeded
-	sizeLimit int64
-	// how long to retain old entries
-	retention time.Duration
+// Config holds the limits of a RotatingNDRecords, zero values select the defaults
+type Config struct {
+	// Spacer is how many digits to use in rotated filenames, 6 by default
+	Spacer int
+	// SizeLimit is the limit of each file, not to be exceeded, 250kB by default
+	SizeLimit int64
+	// Retention is how long to retain old entries, 90 days by default
+	Retention time.Duration
 }
 
-// newRotatingNDRecords returns a new rotatingNDRecords
-func newRotatingNDRecords(filename string, cfg config) *rotatingNDRecords {
-	return &rotatingNDRecords{
+// NewRotatingNDRecords returns a new RotatingNDRecords
+func NewRotatingNDRecords(filename string, cfg Config) *RotatingNDRecords {
+	return &RotatingNDRecords{
 		filename: filename,
 		cfg:      cfg,
 	}
@@ -66,17 +69,17 @@ type ndRecord struct {
 
 // Add adds a new record to the file with the given time and payload
 // old entries will be pruned, and the file will be rotated if it gets too large
-func (r *rotatingNDRecords) Add(t time.Time, payload interface{}) error {
+func (r *RotatingNDRecords) Add(t time.Time, payload interface{}) error {
 	r.ensureDefaults()
 
 	// prune old entries
-	if !r.firstEntry.IsZero() && t.Sub(r.firstEntry) > r.cfg.retention {
+	if !r.firstEntry.IsZero() && t.Sub(r.firstEntry) > r.cfg.Retention {
 		if err := r.pruneOldEntries(t); err != nil {
 			log.Error(err)
 		}
 	}
 	// remove old files that were already rotated
-	if !r.oldestFileMtime.IsZero() && t.Sub(*r.oldestFileMtime) > r.cfg.retention {
+	if !r.oldestFileMtime.IsZero() && t.Sub(*r.oldestFileMtime) > r.cfg.Retention {
 		if err := r.removeOldFiles(t); err != nil {
 			log.Error(err)
 		}
@@ -93,7 +96,7 @@ func (r *rotatingNDRecords) Add(t time.Time, payload interface{}) error {
 
 	// if new entry will push file over size limit, rotate the file
 	if stat, err := os.Stat(r.filename); err == nil {
-		if stat.Size()+int64(len(recordData.Bytes())) > r.cfg.sizeLimit {
+		if stat.Size()+int64(len(recordData.Bytes())) > r.cfg.SizeLimit {
 			r.rotateFile()
 		}
 	}
@@ -109,9 +112,9 @@ func (r *rotatingNDRecords) Add(t time.Time, payload interface{}) error {
 }
 
 // RotatedFiles returns list of rotated files
-func (r *rotatingNDRecords) RotatedFiles() []string {
+func (r *RotatingNDRecords) RotatedFiles() []string {
 	dir := filepath.Dir(r.filename)
-	re, err := buildRotationRegex(r.filename, r.cfg.spacer)
+	re, err := buildRotationRegex(r.filename, r.cfg.Spacer)
 	if err != nil {
 		log.Error(err)
 		return nil
@@ -131,18 +134,18 @@ func (r *rotatingNDRecords) RotatedFiles() []string {
 	return matches
 }
 
-func (r *rotatingNDRecords) ensureDefaults() {
-	if r.cfg.retention == 0 {
+func (r *RotatingNDRecords) ensureDefaults() {
+	if r.cfg.Retention == 0 {
 		// default: 90 days
-		r.cfg.retention = 90 * 24 * time.Hour
+		r.cfg.Retention = 90 * 24 * time.Hour
 	}
-	if r.cfg.spacer == 0 {
+	if r.cfg.Spacer == 0 {
 		// default: 6 spacer characters
-		r.cfg.spacer = 6
+		r.cfg.Spacer = 6
 	}
-	if r.cfg.sizeLimit == 0 {
+	if r.cfg.SizeLimit == 0 {
 		// default: 250kb
-		r.cfg.sizeLimit = 250000
+		r.cfg.SizeLimit = 250000
 	}
 	if r.firstEntry.IsZero() {
 		if f, err := os.OpenFile(r.filename, os.O_RDONLY, 0640); err == nil {
@@ -171,7 +174,7 @@ func (r *rotatingNDRecords) ensureDefaults() {
 	}
 }
 
-func (r *rotatingNDRecords) pruneOldEntries(now time.Time) error {
+func (r *RotatingNDRecords) pruneOldEntries(now time.Time) error {
 	var rec ndRecord
 	f, err := os.OpenFile(r.filename, os.O_RDONLY, 0640)
 	if err != nil {
@@ -187,7 +190,7 @@ func (r *rotatingNDRecords) pruneOldEntries(now time.Time) error {
 		}
 		// entries that cannot be parsed, or that have no "time" field, will be pruned
 		if err = json.Unmarshal(line, &rec); err == nil {
-			if !rec.Time.IsZero() && now.Sub(rec.Time) <= r.cfg.retention {
+			if !rec.Time.IsZero() && now.Sub(rec.Time) <= r.cfg.Retention {
 				r.firstEntry = rec.Time
 				break
 			}
@@ -208,10 +211,10 @@ func (r *rotatingNDRecords) pruneOldEntries(now time.Time) error {
 }
 
 // remove any old rotated files that are past the retention time
-func (r *rotatingNDRecords) removeOldFiles(t time.Time) error {
+func (r *RotatingNDRecords) removeOldFiles(t time.Time) error {
 	for _, filename := range r.RotatedFiles() {
 		if stat, err := os.Stat(filename); err == nil {
-			if t.Sub(stat.ModTime()) > r.cfg.retention {
+			if t.Sub(stat.ModTime()) > r.cfg.Retention {
 				log.Infof("removing old rotated file '%s'", filename)
 				os.Remove(filename)
 				r.oldestFileMtime = nil
@@ -224,8 +227,8 @@ func (r *rotatingNDRecords) removeOldFiles(t time.Time) error {
 }
 
 // rotate the current file to the next available name
-func (r *rotatingNDRecords) rotateFile() {
-	rotateDestFilename, err := nextRotateFilename(r.filename, r.cfg.spacer)
+func (r *RotatingNDRecords) rotateFile() {
+	rotateDestFilename, err := nextRotateFilename(r.filename, r.cfg.Spacer)
 	if err != nil {
 		log.Errorf("could not find rotation filename: %s", err)
 		return
@@ -3,7 +3,7 @@
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
 // Copyright 2016-present Datadog, Inc.
 
-package secretsimpl
+package ndrecords
 
 import (
 	"fmt"
@@ -18,6 +18,11 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+type testRecord struct {
+	Handle string `json:"handle"`
+	Value  string `json:"value,omitempty"`
+}
+
 func setupRecordsTest(t *testing.T) (string, *clock.Mock, func()) {
 	tmpdir, err := os.MkdirTemp("", "rotating*")
 	if err != nil {
@@ -41,10 +46,10 @@ func TestAppendNewFile(t *testing.T) {
 	tmpFileName, mockClock, cleanupFunc := setupRecordsTest(t)
 	defer cleanupFunc()
 
-	records := newRotatingNDRecords(tmpFileName, config{})
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "apple"}})
+	records := NewRotatingNDRecords(tmpFileName, Config{})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "apple"}})
 	mockClock.Add(10 * time.Second)
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "banana"}})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "banana"}})
 
 	data, _ := os.ReadFile(tmpFileName)
 	expect := `{"time":"2014-02-04T10:30:00Z","data":[{"handle":"apple"}]}
@@ -63,9 +68,9 @@ func TestAppendExistingFile(t *testing.T) {
 `)
 	os.WriteFile(tmpFileName, startingData, 0640)
 
-	records := newRotatingNDRecords(tmpFileName, config{})
+	records := NewRotatingNDRecords(tmpFileName, Config{})
 	mockClock.Add(20 * time.Second)
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "cherry"}})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "cherry"}})
 
 	data, _ := os.ReadFile(tmpFileName)
 	expect := `{"time":"2014-02-04T10:30:00Z","data":[{"payload":"apple"}]}
@@ -80,10 +85,10 @@ func TestAppendNameWithValue(t *testing.T) {
 	tmpFileName, mockClock, cleanupFunc := setupRecordsTest(t)
 	defer cleanupFunc()
 
-	records := newRotatingNDRecords(tmpFileName, config{})
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "apple", Value: "red"}})
+	records := NewRotatingNDRecords(tmpFileName, Config{})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "apple", Value: "red"}})
 	mockClock.Add(10 * time.Second)
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "banana", Value: "yellow"}})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "banana", Value: "yellow"}})
 
 	data, _ := os.ReadFile(tmpFileName)
 	expect := `{"time":"2014-02-04T10:30:00Z","data":[{"handle":"apple","value":"red"}]}
@@ -97,8 +102,8 @@ func TestAppendMultipleRecords(t *testing.T) {
 	tmpFileName, mockClock, cleanupFunc := setupRecordsTest(t)
 	defer cleanupFunc()
 
-	records := newRotatingNDRecords(tmpFileName, config{})
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "apple"}, {Handle: "banana"}})
+	records := NewRotatingNDRecords(tmpFileName, Config{})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "apple"}, {Handle: "banana"}})
 
 	data, _ := os.ReadFile(tmpFileName)
 	expect := `{"time":"2014-02-04T10:30:00Z","data":[{"handle":"apple"},{"handle":"banana"}]}
@@ -117,9 +122,9 @@ func TestPruneOldEntries(t *testing.T) {
 `)
 	os.WriteFile(tmpFileName, startingData, 0640)
 
-	records := newRotatingNDRecords(tmpFileName, config{retention: time.Second * 15})
+	records := NewRotatingNDRecords(tmpFileName, Config{Retention: time.Second * 15})
 	mockClock.Add(30 * time.Second)
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "donut"}})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "donut"}})
 
 	// test that 2 entries have been pruned and 2 remain
 	data, _ := os.ReadFile(tmpFileName)
@@ -142,9 +147,9 @@ not json text
 `)
 	os.WriteFile(tmpFileName, startingData, 0640)
 
-	records := newRotatingNDRecords(tmpFileName, config{retention: time.Second * 15})
+	records := NewRotatingNDRecords(tmpFileName, Config{Retention: time.Second * 15})
 	mockClock.Add(30 * time.Second)
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "donut"}})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "donut"}})
 
 	data, _ := os.ReadFile(tmpFileName)
 	expect := `{"time":"2014-02-04T10:30:20Z","data":[{"handle":"cherry"}]}
@@ -162,9 +167,9 @@ func TestPruneEverything(t *testing.T) {
 `)
 	os.WriteFile(tmpFileName, startingData, 0640)
 
-	records := newRotatingNDRecords(tmpFileName, config{retention: time.Second * 15})
+	records := NewRotatingNDRecords(tmpFileName, Config{Retention: time.Second * 15})
 	mockClock.Add(30 * time.Second)
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "donut"}})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "donut"}})
 
 	data, _ := os.ReadFile(tmpFileName)
 	expect := `{"time":"2014-02-04T10:30:30Z","data":[{"handle":"donut"}]}
@@ -184,9 +189,9 @@ func TestRotateLargeFile(t *testing.T) {
 	os.WriteFile(tmpFileName, startingData, 0640)
 
 	// create with a size limit would be reached by the next row
-	records := newRotatingNDRecords(tmpFileName, config{sizeLimit: 150})
+	records := NewRotatingNDRecords(tmpFileName, Config{SizeLimit: 150})
 	mockClock.Add(30 * time.Second)
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "donut"}})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "donut"}})
 
 	// only 1 record in the current file, the latest
 	data, _ := os.ReadFile(tmpFileName)
@@ -212,12 +217,12 @@ func TestPrunePreventsRotation(t *testing.T) {
 	os.WriteFile(tmpFileName, startingData, 0640)
 
 	// create with a size limit would be reached by the next row
-	records := newRotatingNDRecords(tmpFileName, config{
-		sizeLimit: 150,
-		retention: time.Second * 15,
+	records := NewRotatingNDRecords(tmpFileName, Config{
+		SizeLimit: 150,
+		Retention: time.Second * 15,
 	})
 	mockClock.Add(30 * time.Second)
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "donut"}})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "donut"}})
 
 	// only 2 records in the current file, old were pruned
 	data, _ := os.ReadFile(tmpFileName)
@@ -251,9 +256,9 @@ func TestRotateWithExistingFiles(t *testing.T) {
 	}
 
 	// create with a size limit would be reached by the next row
-	records := newRotatingNDRecords(tmpFileName, config{sizeLimit: 150})
+	records := NewRotatingNDRecords(tmpFileName, Config{SizeLimit: 150})
 	mockClock.Add(30 * time.Second)
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "donut"}})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "donut"}})
 
 	// only 1 record in the current file, the latest
 	data, _ := os.ReadFile(tmpFileName)
@@ -289,8 +294,8 @@ func TestRemoveOldFiles(t *testing.T) {
 		mockClock.Add(10 * time.Second)
 	}
 
-	records := newRotatingNDRecords(tmpFileName, config{retention: time.Second * 35})
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "apple"}})
+	records := NewRotatingNDRecords(tmpFileName, Config{Retention: time.Second * 35})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "apple"}})
 
 	// only 1 record in the current file, the latest
 	data, _ := os.ReadFile(tmpFileName)
@@ -318,9 +323,9 @@ func TestOldFilesGetRemovedOverTime(t *testing.T) {
 	os.WriteFile(tmpFileName, startingData, 0640)
 
 	// create with a size limit would be reached by the next row
-	records := newRotatingNDRecords(tmpFileName, config{sizeLimit: 150, retention: 35 * time.Second})
+	records := NewRotatingNDRecords(tmpFileName, Config{SizeLimit: 150, Retention: 35 * time.Second})
 	mockClock.Add(30 * time.Second)
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "donut"}})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "donut"}})
 
 	// there is 1 rotated file, set its mtime so it will be removed soon
 	rotated := records.RotatedFiles()
@@ -335,7 +340,7 @@ func TestOldFilesGetRemovedOverTime(t *testing.T) {
 
 	// advance time and add another record
 	mockClock.Add(20 * time.Second)
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "egg"}})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "egg"}})
 
 	// there are 2 records in the audit file
 	data, _ = os.ReadFile(tmpFileName)
@@ -350,7 +355,7 @@ func TestOldFilesGetRemovedOverTime(t *testing.T) {
 
 	// advance time again and add another record
 	mockClock.Add(20 * time.Second)
-	records.Add(mockClock.Now(), []auditRecord{{Handle: "fruit"}})
+	records.Add(mockClock.Now(), []testRecord{{Handle: "fruit"}})
 
 	// there are 0 rotated files because the last append removed the old rotated file
 	rotated = records.RotatedFiles()
@@ -13,6 +13,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -159,3 +160,27 @@ Secrets handle resolved:
 
 	assert.Equal(t, expectedResult, buffer.String())
 }
+
+func TestDebugInfoRotationHistory(t *testing.T) {
+	tel := fxutil.Test[telemetry.Component](t, nooptelemetry.Module())
+	resolver := newEnabledSecretResolver(tel)
+	resolver.backendCommand = "some_command"
+	resolver.rotations = map[string][]time.Time{
+		"pass1": {time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), time.Date(2024, 1, 3, 3, 4, 5, 0, time.UTC)},
+		"pass2": {time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+	}
+
+	var buffer bytes.Buffer
+	resolver.GetDebugInfo(&buffer)
+
+	expectedHistory := `
+=== Secrets rotation history ===
+
+- 'pass1' rotated 2 time(s):
+	at 2024-01-02T03:04:05Z
+	at 2024-01-03T03:04:05Z
+- 'pass2' rotated 1 time(s):
+	at 2024-01-02T03:04:05Z
+`
+	assert.Contains(t, buffer.String(), expectedHistory)
+}
@@ -33,6 +33,7 @@ import (
 	api "github.com/DataDog/datadog-agent/comp/api/api/def"
 	flaretypes "github.com/DataDog/datadog-agent/comp/core/flare/types"
 	"github.com/DataDog/datadog-agent/comp/core/secrets"
+	"github.com/DataDog/datadog-agent/comp/core/secrets/ndrecords"
 	"github.com/DataDog/datadog-agent/comp/core/status"
 	"github.com/DataDog/datadog-agent/comp/core/telemetry"
 	template "github.com/DataDog/datadog-agent/pkg/template/text"
@@ -43,6 +44,9 @@ import (
 
 const auditFileBasename = "secret-audit-file.json"
 
+// maxRotationHistory is the number of rotations kept per handle for the debug information
+const maxRotationHistory = 10
+
 var newClock = clock.New
 
 type provides struct {
@@ -103,9 +107,13 @@ type secretResolver struct {
 	// filename to write audit records to
 	auditFilename    string
 	auditFileMaxSize int
-	auditRotRecs     *rotatingNDRecords
+	auditRotRecs     *ndrecords.RotatingNDRecords
 	// subscriptions want to be notified about changes to the secrets
 	subscriptions []secrets.SecretChangeCallback
+	// refreshSubscriptions want to be notified about the handles changed by a refresh
+	refreshSubscriptions []secrets.SecretRefreshCallback
+	// rotations contains the last times the value of each handle changed
+	rotations map[string][]time.Time
 
 	// can be overridden for testing purposes
 	commandHookFunc func(string) ([]byte, error)
@@ -124,6 +132,7 @@ func newEnabledSecretResolver(telemetry telemetry.Component) *secretResolver {
 	return &secretResolver{
 		cache:                   make(map[string]string),
 		origin:                  make(handleToContext),
+		rotations:               make(map[string][]time.Time),
 		enabled:                 true,
 		tlmSecretBackendElapsed: telemetry.NewGauge("secret_backend", "elapsed_ms", []string{"command", "exit_code"}, "Elapsed time of secret backend invocation"),
 		tlmSecretUnmarshalError: telemetry.NewCounter("secret_backend", "unmarshal_errors_count", []string{}, "Count of errors when unmarshalling the output of the secret binary"),
@@ -289,6 +298,15 @@ func (r *secretResolver) SubscribeToChanges(cb secrets.SecretChangeCallback) {
 	r.subscriptions = append(r.subscriptions, cb)
 }
 
+// SubscribeToRefresh adds this callback to the list that get notified of the handles changed by a refresh
+func (r *secretResolver) SubscribeToRefresh(cb secrets.SecretRefreshCallback) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.startRefreshRoutine()
+	r.refreshSubscriptions = append(r.refreshSubscriptions, cb)
+}
+
 // Resolve replaces all encoded secrets in data by executing "secret_backend_command" once if all secrets aren't
 // present in the cache.
 func (r *secretResolver) Resolve(data []byte, origin string) ([]byte, error) {
@@ -395,7 +413,7 @@ func (r *secretResolver) Resolve(data []byte, origin string) ([]byte, error) {
 }
 
 // allowlistPaths restricts what config settings may be updated. Any secrets linked to a settings containing any of the
-// following strings will be refreshed.
+// following strings will be refreshed. The refresh subscriptions are notified about every handle regardless.
 //
 // For example, allowing "additional_endpoints" will trigger notifications for:
 //   - "additional_endpoints"
@@ -451,18 +469,25 @@ func (r *secretResolver) matchesAllowlist(handle string) bool {
 
 // for all secrets returned by the backend command, notify subscribers (if allowlist lets them),
 // and return the handles that have received new values compared to what was in the cache,
-// and where those handles appear
-func (r *secretResolver) processSecretResponse(secretResponse map[string]string, useAllowlist bool) secretRefreshInfo {
+// and where those handles appear, as well as all the handles that were rotated
+func (r *secretResolver) processSecretResponse(secretResponse map[string]string, useAllowlist bool) (secretRefreshInfo, []string) {
 	var handleInfoList []handleInfo
+	var rotatedHandles []string
 
 	// notify subscriptions about the changes to secrets
 	for handle, secretValue := range secretResponse {
-		oldValue := r.cache[handle]
+		oldValue, known := r.cache[handle]
 		// if value hasn't changed, don't send notifications
 		if oldValue == secretValue {
 			continue
 		}
 
+		// a handle already in the cache was rotated, as opposed to being resolved for the first time
+		if known {
+			rotatedHandles = append(rotatedHandles, handle)
+			r.addRotation(handle)
+		}
+
 		// if allowlist is enabled and the config setting path is not contained in it, skip it
 		if useAllowlist && !r.matchesAllowlist(handle) {
 			continue
@@ -491,17 +516,48 @@ func (r *secretResolver) processSecretResponse(secretResponse map[string]string,
 	sort.Slice(handleInfoList, func(i, j int) bool {
 		return handleInfoList[i].Name < handleInfoList[j].Name
 	})
-	return secretRefreshInfo{Handles: handleInfoList}
+	sort.Strings(rotatedHandles)
+	return secretRefreshInfo{Handles: handleInfoList}, rotatedHandles
+}
+
+// addRotation records that the value of the handle changed, keeping the last maxRotationHistory rotations
+func (r *secretResolver) addRotation(handle string) {
+	rotations := append(r.rotations[handle], r.clk.Now())
+	if len(rotations) > maxRotationHistory {
+		rotations = rotations[len(rotations)-maxRotationHistory:]
+	}
+	r.rotations[handle] = rotations
 }
 
 // Refresh the secrets after they have been Resolved by fetching them from the backend again
 func (r *secretResolver) Refresh() (string, error) {
+	report, rotatedHandles, refreshSubscriptions, err := r.refresh()
+
+	// notify the refresh subscriptions without holding the lock, as they are likely to resolve secrets again
+	for _, rotated := range rotatedHandles {
+		for _, sub := range refreshSubscriptions {
+			sub(rotated.handle, rotated.origins)
+		}
+	}
+	return report, err
+}
+
+type rotatedHandle struct {
+	handle  string
+	origins []string
+}
+
+// refresh fetches the secrets again and notifies the subscriptions to changes, returning the handles that were
+// rotated for the refresh subscriptions to be notified
+func (r *secretResolver) refresh() (string, []rotatedHandle, []secrets.SecretRefreshCallback, error) {
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
-	// get handles from the cache that match the allowlist
+	// get handles from the cache that match the allowlist, unless the refresh subscriptions want to be notified
+	// about all of them: they resolve their whole configurations again, while the allowlist restricts the
+	// settings updated in place by the change subscriptions
 	newHandles := maps.Keys(r.cache)
-	if isAllowlistEnabled() {
+	if isAllowlistEnabled() && len(r.refreshSubscriptions) == 0 {
 		filteredHandles := make([]string, 0, len(newHandles))
 		for _, handle := range newHandles {
 			if r.matchesAllowlist(handle) {
@@ -511,7 +567,7 @@ func (r *secretResolver) Refresh() (string, error) {
 		newHandles = filteredHandles
 	}
 	if len(newHandles) == 0 {
-		return "", nil
+		return "", nil, nil, nil
 	}
 
 	log.Infof("Refreshing secrets for %d handles", len(newHandles))
@@ -525,13 +581,23 @@ func (r *secretResolver) Refresh() (string, error) {
 		secretResponse, err = r.fetchSecret(newHandles)
 	}
 	if err != nil {
-		return "", err
+		return "", nil, nil, err
 	}
 
 	var auditRecordErr error
 	// when Refreshing secrets, only update what the allowlist allows by passing `true`
-	refreshResult := r.processSecretResponse(secretResponse, true)
-	if len(refreshResult.Handles) > 0 {
+	refreshResult, rotated := r.processSecretResponse(secretResponse, true)
+	rotatedHandles := make([]rotatedHandle, 0, len(rotated))
+	for _, handle := range rotated {
+		var origins []string
+		for _, secretCtx := range r.origin[handle] {
+			if !slices.Contains(origins, secretCtx.origin) {
+				origins = append(origins, secretCtx.origin)
+			}
+		}
+		rotatedHandles = append(rotatedHandles, rotatedHandle{handle: handle, origins: origins})
+	}
+	if len(refreshResult.Handles) > 0 || len(rotatedHandles) > 0 {
 		// add the results to the audit file, if any secrets have new values
 		if err := r.addToAuditFile(secretResponse); err != nil {
 			log.Error(err)
@@ -543,13 +609,13 @@ func (r *secretResolver) Refresh() (string, error) {
 	t := template.New("secret_refresh")
 	t, err = t.Parse(secretRefreshTmpl)
 	if err != nil {
-		return "", err
+		return "", nil, nil, err
 	}
 	b := new(strings.Builder)
 	if err = t.Execute(b, refreshResult); err != nil {
-		return "", err
+		return "", nil, nil, err
 	}
-	return b.String(), auditRecordErr
+	return b.String(), rotatedHandles, slices.Clone(r.refreshSubscriptions), auditRecordErr
 }
 
 type auditRecord struct {
@@ -563,7 +629,7 @@ func (r *secretResolver) addToAuditFile(secretResponse map[string]string) error
 		return nil
 	}
 	if r.auditRotRecs == nil {
-		r.auditRotRecs = newRotatingNDRecords(r.auditFilename, config{})
+		r.auditRotRecs = ndrecords.NewRotatingNDRecords(r.auditFilename, ndrecords.Config{})
 	}
 
 	// iterate keys in deterministic order by sorting
@@ -610,6 +676,7 @@ type secretInfo struct {
 	ExecutablePermissionsDetails interface{}
 	ExecutablePermissionsError   string
 	Handles                      map[string][][]string
+	Rotations                    map[string][]string
 }
 
 type secretRefreshInfo struct {
@@ -669,6 +736,7 @@ func (r *secretResolver) GetDebugInfo(w io.Writer) {
 		ExecutablePermissions:        permissions,
 		ExecutablePermissionsDetails: details,
 		Handles:                      map[string][][]string{},
+		Rotations:                    map[string][]string{},
 	}
 	if err != nil {
 		info.ExecutablePermissionsError = err.Error()
@@ -690,6 +758,12 @@ func (r *secretResolver) GetDebugInfo(w io.Writer) {
 		info.Handles[handle] = details
 	}
 
+	for handle, rotations := range r.rotations {
+		for _, rotation := range rotations {
+			info.Rotations[handle] = append(info.Rotations[handle], rotation.UTC().Format(time.RFC3339))
+		}
+	}
+
 	err = t.Execute(w, info)
 	if err != nil {
 		fmt.Fprintf(w, "error rendering secret info: %s\n", err)
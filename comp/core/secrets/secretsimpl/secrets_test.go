@@ -754,6 +754,81 @@ func TestRefreshAllowlistAppliesToEachSettingPath(t *testing.T) {
 	assert.Equal(t, changedPaths, []string{"instances/0/password"})
 }
 
+// test that the refresh subscriptions are notified of the rotated handles, which are subject to the allowlist
+func TestRefreshNotifiesRefreshSubscriptions(t *testing.T) {
+	tel := fxutil.Test[telemetry.Component](t, nooptelemetry.Module())
+	resolver := newEnabledSecretResolver(tel)
+	resolver.backendCommand = "some_command"
+	mockClock := clock.NewMock()
+	resolver.clk = mockClock
+
+	resolver.fetchHookFunc = func([]string) (map[string]string, error) {
+		return map[string]string{
+			"pass1": "password1",
+		}, nil
+	}
+	_, err := resolver.Resolve(testMultiUsageConf, "test")
+	require.NoError(t, err)
+	_, err = resolver.Resolve(testMultiUsageConf, "test2")
+	require.NoError(t, err)
+
+	changedPaths := []string{}
+	resolver.SubscribeToChanges(func(_, _ string, path []string, _, _ any) {
+		changedPaths = append(changedPaths, strings.Join(path, "/"))
+	})
+
+	resolver.fetchHookFunc = func([]string) (map[string]string, error) {
+		return map[string]string{
+			"pass1": "second_password",
+		}, nil
+	}
+	mockClock.Set(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	// without refresh subscriptions, the handles whose settings are not in the allowlist are not refreshed
+	_, err = resolver.Refresh()
+	require.NoError(t, err)
+	assert.Empty(t, changedPaths)
+	assert.Empty(t, resolver.rotations)
+
+	refreshedHandles := map[string][]string{}
+	resolver.SubscribeToRefresh(func(handle string, origins []string) {
+		refreshedHandles[handle] = origins
+	})
+
+	// the refresh subscriptions are notified, while the settings outside of the allowlist are left untouched
+	_, err = resolver.Refresh()
+	require.NoError(t, err)
+	assert.Empty(t, changedPaths)
+	assert.Equal(t, map[string][]string{"pass1": {"test", "test2"}}, refreshedHandles)
+	assert.Equal(t, map[string][]time.Time{"pass1": {time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}}, resolver.rotations)
+
+	// refreshing without new values notifies nobody
+	refreshedHandles = map[string][]string{}
+	_, err = resolver.Refresh()
+	require.NoError(t, err)
+	assert.Empty(t, refreshedHandles)
+
+	// the configurations resolved again get the new value
+	resolved, err := resolver.Resolve(testMultiUsageConf, "test")
+	require.NoError(t, err)
+	assert.Equal(t, strings.ReplaceAll(testMultiUsageConfResolved, "password1", "second_password"), string(resolved))
+}
+
+func TestRotationHistoryIsBounded(t *testing.T) {
+	tel := fxutil.Test[telemetry.Component](t, nooptelemetry.Module())
+	resolver := newEnabledSecretResolver(tel)
+	mockClock := clock.NewMock()
+	resolver.clk = mockClock
+
+	for i := 0; i < maxRotationHistory+5; i++ {
+		mockClock.Add(time.Minute)
+		resolver.addRotation("pass1")
+	}
+	require.Len(t, resolver.rotations["pass1"], maxRotationHistory)
+	assert.Equal(t, mockClock.Now(), resolver.rotations["pass1"][maxRotationHistory-1])
+	assert.Equal(t, mockClock.Now().Add(-(maxRotationHistory-1)*time.Minute), resolver.rotations["pass1"][0])
+}
+
 // test that adding to the audit file stops working when the file gets too large
 func TestRefreshAddsToAuditFile(t *testing.T) {
 	tmpfile, err := os.CreateTemp("", "")
@@ -20,5 +20,13 @@ type SecretVal struct {
 // `newValue`: the new value that the secret has resolved to
 type SecretChangeCallback func(handle, origin string, path []string, oldValue, newValue any)
 
+// SecretRefreshCallback is the callback type used by SubscribeToRefresh to send notifications
+// This callback will be called once for each handle whose value changed when secrets are refreshed, once the refresh
+// is done. Unlike the change callbacks, it covers every handle, including those not appearing in a setting matching
+// the allowlist, as the subscriber is expected to resolve again the configurations using the handle.
+// `handle`: the handle of the secret (example: `ENC[api_key]` the handle is `api_key`)
+// `origins`: origin files of the configurations where the handle appears
+type SecretRefreshCallback func(handle string, origins []string)
+
 // PayloadVersion defines the current payload version sent to a secret backend
 const PayloadVersion = "1.0"
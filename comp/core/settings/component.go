@@ -9,6 +9,7 @@ package settings
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	"github.com/DataDog/datadog-agent/pkg/config/model"
@@ -32,6 +33,28 @@ type RuntimeSettingResponse struct {
 	Hidden      bool
 }
 
+// SettingLayer is the value of a setting in one of the configuration sources
+type SettingLayer struct {
+	Source model.Source `json:"source"`
+	Value  interface{}  `json:"value"`
+}
+
+// SettingExplanation details where the effective value of a setting comes from. Values are scrubbed.
+type SettingExplanation struct {
+	Setting string `json:"setting"`
+	// Source is the source of the effective value of the setting
+	Source model.Source `json:"source"`
+	Value  interface{}  `json:"value"`
+	// Layers lists the sources defining a value for the setting, from the lowest to the highest priority
+	Layers []SettingLayer `json:"layers"`
+	// RawValue is the value of the setting before secret resolution, only set when it uses a secret
+	RawValue interface{} `json:"raw_value,omitempty"`
+	// LastRuntimeChange is the time of the last change of the setting since the process started, with the source of
+	// the change
+	LastRuntimeChange       *time.Time   `json:"last_runtime_change,omitempty"`
+	LastRuntimeChangeSource model.Source `json:"last_runtime_change_source,omitempty"`
+}
+
 // Params that the settings component need
 type Params struct {
 	// Settings define the runtime settings the component would understand
@@ -64,6 +87,8 @@ type Component interface {
 	SetValue(w http.ResponseWriter, r *http.Request)
 	// ListConfigurable returns the list of configurable setting at runtime
 	ListConfigurable(w http.ResponseWriter, r *http.Request)
+	// Explain returns every source defining a value for a setting and the one that wins
+	Explain(w http.ResponseWriter, r *http.Request)
 }
 
 // RuntimeSetting represents a setting that can be changed and read at runtime.
@@ -82,3 +82,6 @@ func (m mock) SetValue(http.ResponseWriter, *http.Request) {}
 
 // ListConfigurable returns the list of configurable setting at runtime
 func (m mock) ListConfigurable(http.ResponseWriter, *http.Request) {}
+
+// Explain returns the sources of a setting
+func (m mock) Explain(http.ResponseWriter, *http.Request) {}
@@ -7,10 +7,13 @@
 package settingsimpl
 
 import (
+	"fmt"
 	"html"
 	"maps"
 	"net/http"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
 	json "github.com/json-iterator/go"
@@ -38,11 +41,12 @@ func Module() fxutil.Module {
 type provides struct {
 	fx.Out
 
-	Comp         settings.Component
-	FullEndpoint api.AgentEndpointProvider
-	ListEndpoint api.AgentEndpointProvider
-	GetEndpoint  api.AgentEndpointProvider
-	SetEndpoint  api.AgentEndpointProvider
+	Comp            settings.Component
+	FullEndpoint    api.AgentEndpointProvider
+	ListEndpoint    api.AgentEndpointProvider
+	GetEndpoint     api.AgentEndpointProvider
+	SetEndpoint     api.AgentEndpointProvider
+	ExplainEndpoint api.AgentEndpointProvider
 }
 
 type dependencies struct {
@@ -57,6 +61,15 @@ type settingsRegistry struct {
 	settings map[string]settings.RuntimeSetting
 	log      log.Component
 	config   config.Component
+
+	changesMutex sync.Mutex
+	// lastChanges contains the last change of each setting updated since the process started
+	lastChanges map[string]runtimeChange
+}
+
+type runtimeChange struct {
+	time   time.Time
+	source model.Source
 }
 
 // RuntimeSettings returns all runtime configurable settings
@@ -240,17 +253,98 @@ func (s *settingsRegistry) SetValue(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// recordRuntimeChange is called by the config each time a setting is updated, by a runtime setting, remote
+// configuration, a secret refresh or the synchronization of the config with the core agent
+func (s *settingsRegistry) recordRuntimeChange(setting string, _, _ any) {
+	source := s.config.GetSource(setting)
+
+	s.changesMutex.Lock()
+	defer s.changesMutex.Unlock()
+	s.lastChanges[setting] = runtimeChange{time: time.Now(), source: source}
+}
+
+func (s *settingsRegistry) explain(setting string) settings.SettingExplanation {
+	explanation := settings.SettingExplanation{
+		Setting: setting,
+		Source:  s.config.GetSource(setting),
+		Value:   scrubValue(setting, s.config.Get(setting)),
+		Layers:  []settings.SettingLayer{},
+	}
+
+	for _, layer := range s.config.GetAllSources(setting) {
+		if layer.Value == nil {
+			continue
+		}
+		explanation.Layers = append(explanation.Layers, settings.SettingLayer{
+			Source: layer.Source,
+			Value:  scrubValue(setting, layer.Value),
+		})
+		// secrets are resolved into the agent-runtime layer, the layers where the setting was defined keep the
+		// secret handles
+		if raw := fmt.Sprint(layer.Value); strings.Contains(raw, "ENC[") {
+			explanation.RawValue = scrubber.ScrubLine(raw)
+		}
+	}
+
+	s.changesMutex.Lock()
+	if change, found := s.lastChanges[setting]; found {
+		explanation.LastRuntimeChange = &change.time
+		explanation.LastRuntimeChangeSource = change.source
+	}
+	s.changesMutex.Unlock()
+
+	return explanation
+}
+
+// scrubValue scrubs a value of a setting, applying the rules based on the name of the setting too
+func scrubValue(setting string, value interface{}) interface{} {
+	name := setting[strings.LastIndex(setting, ".")+1:]
+	var data interface{} = map[string]interface{}{name: deepcopy.Copy(value)}
+	scrubber.ScrubDataObj(&data)
+
+	scrubbed := data.(map[string]interface{})[name]
+	if str, ok := scrubbed.(string); ok {
+		return scrubber.ScrubLine(str)
+	}
+	return scrubbed
+}
+
+func (s *settingsRegistry) Explain(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	setting := strings.ToLower(vars["setting"])
+
+	if !s.config.IsKnown(setting) {
+		body, _ := json.Marshal(map[string]string{"error": (&settings.SettingNotFoundError{Name: setting}).Error()})
+		http.Error(w, string(body), http.StatusBadRequest)
+		return
+	}
+
+	body, err := json.Marshal(s.explain(setting))
+	if err != nil {
+		s.log.Errorf("Unable to marshal setting explanation response: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), http.StatusInternalServerError)
+		return
+	}
+	_, _ = w.Write(body)
+}
+
 func newSettings(deps dependencies) provides {
 	s := &settingsRegistry{
-		settings: deps.Params.Settings,
-		log:      deps.Log,
-		config:   deps.Params.Config,
+		settings:    deps.Params.Settings,
+		log:         deps.Log,
+		config:      deps.Params.Config,
+		lastChanges: map[string]runtimeChange{},
+	}
+	if s.config != nil {
+		s.config.OnUpdate(s.recordRuntimeChange)
 	}
 	return provides{
-		Comp:         s,
-		FullEndpoint: api.NewAgentEndpointProvider(s.GetFullConfig(deps.Params.Namespaces...), "/config", "GET"),
-		ListEndpoint: api.NewAgentEndpointProvider(s.ListConfigurable, "/config/list-runtime", "GET"),
-		GetEndpoint:  api.NewAgentEndpointProvider(s.GetValue, "/config/{setting}", "GET"),
-		SetEndpoint:  api.NewAgentEndpointProvider(s.SetValue, "/config/{setting}", "POST"),
+		Comp:            s,
+		FullEndpoint:    api.NewAgentEndpointProvider(s.GetFullConfig(deps.Params.Namespaces...), "/config", "GET"),
+		ListEndpoint:    api.NewAgentEndpointProvider(s.ListConfigurable, "/config/list-runtime", "GET"),
+		GetEndpoint:     api.NewAgentEndpointProvider(s.GetValue, "/config/{setting}", "GET"),
+		SetEndpoint:     api.NewAgentEndpointProvider(s.SetValue, "/config/{setting}", "POST"),
+		ExplainEndpoint: api.NewAgentEndpointProvider(s.Explain, "/config/explain/{setting}", "GET"),
 	}
 }
@@ -304,6 +304,48 @@ func TestRuntimeSettings(t *testing.T) {
 				assert.Equal(t, "{\"value\":{\"Value\":\"fancy\",\"Source\":\"cli\"}}", string(body))
 			},
 		},
+		{
+			"Explain",
+			func(t *testing.T, comp settings.Component) {
+				config := comp.(*settingsRegistry).config
+				config.Set("api_key", "ENC[api_key_handle]", model.SourceFile)
+				config.Set("api_key", "abcdefabcdefabcdefabcdefabcdef12", model.SourceAgentRuntime)
+
+				router := mux.NewRouter()
+				router.HandleFunc("/config/explain/{setting}", comp.Explain).Methods("GET")
+				ts := httptest.NewServer(router)
+				defer ts.Close()
+
+				resp, err := ts.Client().Get(ts.URL + "/config/explain/api_key")
+				require.NoError(t, err)
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				require.Equal(t, 200, resp.StatusCode)
+
+				var explanation settings.SettingExplanation
+				require.NoError(t, json.Unmarshal(body, &explanation))
+
+				assert.Equal(t, "api_key", explanation.Setting)
+				assert.Equal(t, model.SourceAgentRuntime, explanation.Source)
+				assert.Equal(t, "***************************def12", explanation.Value)
+				assert.Equal(t, "ENC[api_key_handle]", explanation.RawValue)
+				// api_key has no default value
+				require.Len(t, explanation.Layers, 2)
+				assert.Equal(t, model.SourceFile, explanation.Layers[0].Source)
+				assert.Equal(t, model.SourceAgentRuntime, explanation.Layers[1].Source)
+				assert.Equal(t, "***************************def12", explanation.Layers[1].Value)
+				require.NotNil(t, explanation.LastRuntimeChange)
+				assert.Equal(t, model.SourceAgentRuntime, explanation.LastRuntimeChangeSource)
+
+				resp, err = ts.Client().Get(ts.URL + "/config/explain/non_existing")
+				require.NoError(t, err)
+				body, _ = io.ReadAll(resp.Body)
+				resp.Body.Close()
+
+				assert.Equal(t, 400, resp.StatusCode)
+				assert.Equal(t, "{\"error\":\"setting non_existing not found\"}\n", string(body))
+			},
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -157,6 +157,8 @@ func (c *WorkloadMetaCollector) processEvents(evBundle workloadmeta.EventBundle)
 				tagInfos = append(tagInfos, c.handleKubeDeployment(ev)...)
 			case workloadmeta.KindGPU:
 				tagInfos = append(tagInfos, c.handleGPU(ev)...)
+			case workloadmeta.KindSystemdUnit:
+				tagInfos = append(tagInfos, c.handleSystemdUnit(ev)...)
 			default:
 				log.Errorf("cannot handle event for entity %q with kind %q", entityID.ID, entityID.Kind)
 			}
@@ -647,6 +649,32 @@ func (c *WorkloadMetaCollector) handleGPU(ev workloadmeta.Event) []*types.TagInf
 	return tagInfos
 }
 
+func (c *WorkloadMetaCollector) handleSystemdUnit(ev workloadmeta.Event) []*types.TagInfo {
+	unit := ev.Entity.(*workloadmeta.SystemdUnit)
+
+	tagList := taglist.NewTagList()
+
+	tagList.AddLow(tags.SystemdUnit, unit.Name)
+	tagList.AddStandard(tags.Service, unit.Service)
+	tagList.AddStandard(tags.Env, unit.Env)
+	tagList.AddStandard(tags.Version, unit.Version)
+
+	low, orch, high, standard := tagList.Compute()
+
+	tagInfos := []*types.TagInfo{
+		{
+			Source:               systemdUnitSource,
+			EntityID:             common.BuildTaggerEntityID(unit.EntityID),
+			HighCardTags:         high,
+			OrchestratorCardTags: orch,
+			LowCardTags:          low,
+			StandardTags:         standard,
+		},
+	}
+
+	return tagInfos
+}
+
 func (c *WorkloadMetaCollector) extractTagsFromPodLabels(pod *workloadmeta.KubernetesPod, tagList *taglist.TagList) {
 	for name, value := range pod.Labels {
 		switch name {
@@ -37,6 +37,7 @@ const (
 	kubeMetadataSource   = workloadmetaCollectorName + "-" + string(workloadmeta.KindKubernetesMetadata)
 	deploymentSource     = workloadmetaCollectorName + "-" + string(workloadmeta.KindKubernetesDeployment)
 	gpuSource            = workloadmetaCollectorName + "-" + string(workloadmeta.KindGPU)
+	systemdUnitSource    = workloadmetaCollectorName + "-" + string(workloadmeta.KindSystemdUnit)
 
 	clusterTagNamePrefix = tags.KubeClusterName
 )
@@ -2400,6 +2400,89 @@ func TestHandleGPU(t *testing.T) {
 	}
 }
 
+func TestHandleSystemdUnit(t *testing.T) {
+	entityID := workloadmeta.EntityID{
+		Kind: workloadmeta.KindSystemdUnit,
+		ID:   "nginx.service",
+	}
+
+	taggerEntityID := types.NewEntityID(types.SystemdUnit, entityID.ID)
+
+	tests := []struct {
+		name     string
+		unit     workloadmeta.SystemdUnit
+		expected []*types.TagInfo
+	}{
+		{
+			name: "basic",
+			unit: workloadmeta.SystemdUnit{
+				EntityID: entityID,
+				EntityMeta: workloadmeta.EntityMeta{
+					Name: entityID.ID,
+				},
+				MainPID: 42,
+			},
+			expected: []*types.TagInfo{
+				{
+					Source:               systemdUnitSource,
+					EntityID:             taggerEntityID,
+					HighCardTags:         []string{},
+					OrchestratorCardTags: []string{},
+					LowCardTags: []string{
+						"systemd_unit:nginx.service",
+					},
+					StandardTags: []string{},
+				},
+			},
+		},
+		{
+			name: "unified service tagging",
+			unit: workloadmeta.SystemdUnit{
+				EntityID: entityID,
+				EntityMeta: workloadmeta.EntityMeta{
+					Name: entityID.ID,
+				},
+				Service: "web",
+				Env:     "prod",
+				Version: "1.25",
+			},
+			expected: []*types.TagInfo{
+				{
+					Source:               systemdUnitSource,
+					EntityID:             taggerEntityID,
+					HighCardTags:         []string{},
+					OrchestratorCardTags: []string{},
+					LowCardTags: []string{
+						"systemd_unit:nginx.service",
+						"service:web",
+						"env:prod",
+						"version:1.25",
+					},
+					StandardTags: []string{
+						"service:web",
+						"env:prod",
+						"version:1.25",
+					},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := configmock.New(t)
+			collector := NewWorkloadMetaCollector(context.Background(), cfg, nil, nil)
+
+			actual := collector.handleSystemdUnit(workloadmeta.Event{
+				Type:   workloadmeta.EventTypeSet,
+				Entity: &tt.unit,
+			})
+
+			assertTagInfoListEqual(t, tt.expected, actual)
+		})
+	}
+}
+
 func TestHandleDelete(t *testing.T) {
 	const (
 		podName       = "datadog-agent-foobar"
@@ -171,6 +171,13 @@ func newLocalTagger(cfg config.Component, wmeta workloadmeta.Component, log log.
 	telemetryStore := telemetry.NewStore(telemetryComp)
 	if tagStore == nil {
 		tagStore = tagstore.NewTagStore(telemetryStore)
+
+		rules, err := tagstore.GetRules(cfg)
+		if err != nil {
+			log.Errorf("Invalid tagger_rules, tags will not be transformed: %s", err)
+		} else {
+			tagStore.SetRules(rules)
+		}
 	}
 
 	// we use to pull tagger metrics in dogstatsd. Pulling it later in the
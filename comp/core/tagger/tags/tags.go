@@ -112,6 +112,9 @@ const (
 	// GPUDriverVersion is the tag for the GPU driver version
 	GPUDriverVersion = "gpu_driver_version"
 
+	// SystemdUnit is the tag for the name of a systemd unit
+	SystemdUnit = "systemd_unit"
+
 	// OpenshiftDeploymentConfig is the tag for the OpenShift deployment config name
 	OpenshiftDeploymentConfig = "oshift_deployment_config"
 
@@ -60,20 +60,27 @@ type EntityTags interface {
 type EntityTagsWithMultipleSources struct {
 	entityID           types.EntityID
 	sourceTags         map[string]sourceTags
+	rules              []*Rule
 	cacheValid         bool
 	cachedAll          tagset.HashedTags // Low + orchestrator + high
 	cachedOrchestrator tagset.HashedTags // Low + orchestrator (subslice of cachedAll)
 	cachedLow          tagset.HashedTags // Sub-slice of cachedAll
+	cachedStandard     []string
 }
 
-func newEntityTags(entityID types.EntityID, source string) EntityTags {
+// newEntityTags returns the tags of an entity, transformed by the rules once
+// the tags of its sources are merged
+func newEntityTags(entityID types.EntityID, source string, rules []*Rule) EntityTags {
 	if flavor.GetFlavor() == flavor.ClusterAgent {
-		return newEntityTagsWithSingleSource(entityID, source)
+		entityTags := newEntityTagsWithSingleSource(entityID, source)
+		entityTags.rules = rules
+		return entityTags
 	}
 
 	return &EntityTagsWithMultipleSources{
 		entityID:   entityID,
 		sourceTags: make(map[string]sourceTags),
+		rules:      rules,
 		cacheValid: true,
 	}
 }
@@ -100,11 +107,9 @@ func (e *EntityTagsWithMultipleSources) toEntity() types.Entity {
 }
 
 func (e *EntityTagsWithMultipleSources) getStandard() []string {
-	tags := []string{}
-	for _, t := range e.sourceTags {
-		tags = append(tags, t.standardTags...)
-	}
-	return tags
+	e.computeCache()
+
+	return append([]string{}, e.cachedStandard...)
 }
 
 func (e *EntityTagsWithMultipleSources) getHashedTags(cardinality types.TagCardinality) tagset.HashedTags {
@@ -163,23 +168,35 @@ func (e *EntityTagsWithMultipleSources) computeCache() {
 		}
 	}
 
+	var standardTags []string
 	for _, source := range sources {
 		tags := e.sourceTags[source]
 		insertWithPriority(source, tags.lowCardTags, types.LowCardinality)
 		insertWithPriority(source, tags.orchestratorCardTags, types.OrchestratorCardinality)
 		insertWithPriority(source, tags.highCardTags, types.HighCardinality)
+		standardTags = append(standardTags, tags.standardTags...)
 	}
 
-	tags := append(tagList[types.LowCardinality], tagList[types.OrchestratorCardinality]...)
-	tags = append(tags, tagList[types.HighCardinality]...)
+	// the rules apply to the tags of the entity, whichever source reports
+	// them
+	merged := applyRules(e.rules, sourceTags{
+		lowCardTags:          tagList[types.LowCardinality],
+		orchestratorCardTags: tagList[types.OrchestratorCardinality],
+		highCardTags:         tagList[types.HighCardinality],
+		standardTags:         standardTags,
+	})
+
+	tags := append(merged.lowCardTags, merged.orchestratorCardTags...)
+	tags = append(tags, merged.highCardTags...)
 
 	cached := tagset.NewHashedTagsFromSlice(tags)
 
-	lowCardTags := len(tagList[types.LowCardinality])
-	orchCardTags := len(tagList[types.OrchestratorCardinality])
+	lowCardTags := len(merged.lowCardTags)
+	orchCardTags := len(merged.orchestratorCardTags)
 
 	// Write cache
 	e.cacheValid = true
+	e.cachedStandard = merged.standardTags
 	e.cachedAll = cached
 	e.cachedLow = cached.Slice(0, lowCardTags)
 	e.cachedOrchestrator = cached.Slice(0, lowCardTags+orchCardTags)
@@ -268,6 +285,10 @@ type EntityTagsWithSingleSource struct {
 	cachedOrchestrator tagset.HashedTags // Low + orchestrator (subslice of cachedAll)
 	cachedLow          tagset.HashedTags // Sub-slice of cachedAll
 	isExpired          bool
+	rules              []*Rule
+	// rawTags are the tags reported by the source, only kept when rules
+	// transform them
+	rawTags *sourceTags
 }
 
 func newEntityTagsWithSingleSource(entityID types.EntityID, source string) *EntityTagsWithSingleSource {
@@ -320,6 +341,12 @@ func (e *EntityTagsWithSingleSource) tagsForSource(source string) *sourceTags {
 		return nil
 	}
 
+	if e.rawTags != nil {
+		tags := *e.rawTags
+		tags.expiryDate = e.expiryDate
+		return &tags
+	}
+
 	return &sourceTags{
 		lowCardTags:          e.cachedLow.Get(),
 		orchestratorCardTags: e.cachedAll.Slice(e.cachedLow.Len(), e.cachedOrchestrator.Len()).Get(),
@@ -339,6 +366,12 @@ func (e *EntityTagsWithSingleSource) setTagsForSource(source string, tags source
 		return
 	}
 
+	if len(e.rules) > 0 {
+		rawTags := tags
+		e.rawTags = &rawTags
+		tags = applyRules(e.rules, tags)
+	}
+
 	e.standardTags = tags.standardTags
 
 	all := make([]string, 0, len(tags.lowCardTags)+len(tags.orchestratorCardTags)+len(tags.highCardTags))
@@ -49,6 +49,24 @@ func TestToEntity(t *testing.T) {
 	)
 }
 
+func TestSetTagsForSourceWithRules(t *testing.T) {
+	rules := []*Rule{{Type: RenameRule, Name: "owner", Tag: "team", Target: "owner"}}
+	assert.NoError(t, CompileRules(rules))
+	entityTags := newEntityTagsWithSingleSource(testEntityID, testSource)
+	entityTags.rules = rules
+
+	reported := sourceTags{
+		lowCardTags:  []string{"team:payments", "service:s1"},
+		standardTags: []string{"service:s1"},
+	}
+	entityTags.setTagsForSource(testSource, reported)
+
+	// the entity gets the transformed tags, while the reported ones are kept
+	// to detect changes
+	assert.Equal(t, []string{"owner:payments", "service:s1"}, entityTags.toEntity().LowCardinalityTags)
+	assert.Equal(t, &reported, entityTags.tagsForSource(testSource))
+}
+
 func TestGetStandard(t *testing.T) {
 	entityTags := newEntityTagsWithSingleSource(testEntityID, testSource)
 
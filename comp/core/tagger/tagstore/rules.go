@@ -0,0 +1,271 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tagstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+	"github.com/DataDog/datadog-agent/pkg/config/structure"
+)
+
+// Tag rule types
+const (
+	// DeriveRule adds a tag whose value is extracted from the value of another tag
+	DeriveRule = "derive"
+	// LookupRule adds a tag whose value is looked up from the value of another tag
+	LookupRule = "lookup"
+	// RenameRule renames a tag
+	RenameRule = "rename"
+	// DropRule removes tags
+	DropRule = "drop"
+)
+
+// ruleCardinalities are the cardinalities of the tags reported by the collectors
+var ruleCardinalities = []types.TagCardinality{types.LowCardinality, types.OrchestratorCardinality, types.HighCardinality}
+
+// Rule defines a transformation of the tags of the entities. The rules are
+// applied in order by the TagStore to the tags of the entities, merged from
+// the tags reported by the collectors, so that every pipeline gets the same
+// tags. A rule can use the tags created by the previous ones.
+type Rule struct {
+	Type string
+	Name string
+	// Tag is the name of the tag the rule applies to
+	Tag string
+	// Pattern is a regular expression. Derive rules extract the value of the
+	// new tag from the value of Tag with it, and copy the value when it is
+	// empty. Drop rules remove the tags whose value matches it, or the whole
+	// tag when Tag is empty.
+	Pattern string
+	// Target is the name of the tag added by derive and lookup rules, or the
+	// new name of the tag for rename rules
+	Target string
+	// Value is the value of the tag added by derive rules. It can reference the
+	// capture groups of Pattern, like "$1" or "${team}", and defaults to the
+	// first capture group, or to the whole match when Pattern has none.
+	Value string
+	// Values maps the values of Tag to the values of Target for lookup rules
+	Values map[string]string
+	// Default is the value of Target for lookup rules when the value of Tag is
+	// not in Values. No tag is added when it is empty.
+	Default string
+	// Cardinality restricts the rule to the tags of a cardinality: low,
+	// orchestrator or high. Tags added by the rule get the cardinality of the
+	// tag they are created from.
+	Cardinality string
+
+	regex       *regexp.Regexp
+	cardinality types.TagCardinality
+}
+
+// GetRules returns the tag rules defined by the tagger_rules setting
+func GetRules(cfg model.Reader) ([]*Rule, error) {
+	var rules []*Rule
+	var err error
+	raw := cfg.Get("tagger_rules")
+	if raw == nil {
+		return rules, nil
+	}
+	if s, ok := raw.(string); ok && s != "" {
+		err = json.Unmarshal([]byte(s), &rules)
+	} else {
+		err = structure.UnmarshalKey(cfg, "tagger_rules", &rules, structure.ConvertEmptyStringToNil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := CompileRules(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// CompileRules validates the rules and compiles their regular expressions. It
+// returns an error if one is misconfigured.
+func CompileRules(rules []*Rule) error {
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return fmt.Errorf("all tag rules must have a name")
+		}
+
+		switch rule.Type {
+		case DeriveRule, RenameRule:
+			if rule.Tag == "" || rule.Target == "" {
+				return fmt.Errorf("tag and target must be set for tag rule `%s`", rule.Name)
+			}
+		case LookupRule:
+			if rule.Tag == "" || rule.Target == "" || len(rule.Values) == 0 {
+				return fmt.Errorf("tag, target and values must be set for tag rule `%s`", rule.Name)
+			}
+		case DropRule:
+			if rule.Tag == "" && rule.Pattern == "" {
+				return fmt.Errorf("tag or pattern must be set for tag rule `%s`", rule.Name)
+			}
+		case "":
+			return fmt.Errorf("type must be set for tag rule `%s`", rule.Name)
+		default:
+			return fmt.Errorf("type %s is not supported for tag rule `%s`", rule.Type, rule.Name)
+		}
+
+		if rule.Pattern != "" {
+			if rule.Type != DeriveRule && rule.Type != DropRule {
+				return fmt.Errorf("pattern is not supported by %s rules, in tag rule `%s`", rule.Type, rule.Name)
+			}
+			re, err := regexp.Compile(rule.Pattern)
+			if err != nil {
+				return fmt.Errorf("invalid pattern %s for tag rule `%s`: %w", rule.Pattern, rule.Name, err)
+			}
+			rule.regex = re
+		}
+
+		if rule.Type == DeriveRule {
+			if rule.regex == nil {
+				rule.regex = regexp.MustCompile("^.*$")
+			}
+			if rule.Value == "" {
+				rule.Value = "$0"
+				if rule.regex.NumSubexp() > 0 {
+					rule.Value = "$1"
+				}
+			}
+		}
+
+		if rule.Cardinality != "" {
+			cardinality, err := types.StringToTagCardinality(rule.Cardinality)
+			if err != nil || cardinality == types.NoneCardinality {
+				return fmt.Errorf("cardinality %s is not supported for tag rule `%s`", rule.Cardinality, rule.Name)
+			}
+			rule.cardinality = cardinality
+		}
+	}
+	return nil
+}
+
+// applyRules returns the tags transformed by the rules. The tags reported by
+// the collector are not modified.
+func applyRules(rules []*Rule, tags sourceTags) sourceTags {
+	if len(rules) == 0 {
+		return tags
+	}
+
+	tagsByCardinality := [][]string{
+		slices.Clone(tags.lowCardTags),
+		slices.Clone(tags.orchestratorCardTags),
+		slices.Clone(tags.highCardTags),
+	}
+	hasTag := func(name string) bool {
+		for _, cardinalityTags := range tagsByCardinality {
+			for _, tag := range cardinalityTags {
+				if tagName, _ := splitTag(tag); tagName == name {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for _, rule := range rules {
+		for i, cardinality := range ruleCardinalities {
+			if rule.Cardinality != "" && rule.cardinality != cardinality {
+				continue
+			}
+			tagsByCardinality[i] = rule.apply(tagsByCardinality[i], hasTag)
+		}
+	}
+
+	result := sourceTags{
+		lowCardTags:          tagsByCardinality[0],
+		orchestratorCardTags: tagsByCardinality[1],
+		highCardTags:         tagsByCardinality[2],
+		expiryDate:           tags.expiryDate,
+	}
+
+	// standard tags are a subset of the other tags, keep the ones that were
+	// not renamed or dropped
+	for _, tag := range tags.standardTags {
+		for _, cardinalityTags := range tagsByCardinality {
+			if slices.Contains(cardinalityTags, tag) {
+				result.standardTags = append(result.standardTags, tag)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// apply returns the tags of a cardinality transformed by the rule. Derive and
+// lookup rules don't add a tag when the entity already has a tag with the
+// target name.
+func (r *Rule) apply(tags []string, hasTag func(name string) bool) []string {
+	result := make([]string, 0, len(tags))
+	var added []string
+
+	for _, tag := range tags {
+		name, value := splitTag(tag)
+
+		switch r.Type {
+		case DropRule:
+			if r.Tag != "" && name != r.Tag {
+				break
+			}
+			if r.regex == nil {
+				continue
+			}
+			matched := tag
+			if r.Tag != "" {
+				matched = value
+			}
+			if r.regex.MatchString(matched) {
+				continue
+			}
+		case RenameRule:
+			if name == r.Tag {
+				tag = r.Target + ":" + value
+			}
+		case DeriveRule, LookupRule:
+			if name != r.Tag || len(added) > 0 || hasTag(r.Target) {
+				break
+			}
+			if newValue, ok := r.targetValue(value); ok {
+				added = append(added, r.Target+":"+newValue)
+			}
+		}
+
+		result = append(result, tag)
+	}
+
+	return append(result, added...)
+}
+
+// targetValue returns the value of the tag added by derive and lookup rules
+func (r *Rule) targetValue(value string) (string, bool) {
+	if r.Type == LookupRule {
+		if newValue, found := r.Values[value]; found {
+			return newValue, true
+		}
+		return r.Default, r.Default != ""
+	}
+
+	match := r.regex.FindStringSubmatchIndex(value)
+	if match == nil {
+		return "", false
+	}
+	newValue := string(r.regex.ExpandString(nil, r.Value, value, match))
+	return newValue, newValue != ""
+}
+
+// splitTag returns the name and the value of a tag
+func splitTag(tag string) (string, string) {
+	name, value, _ := strings.Cut(tag, ":")
+	return name, value
+}
@@ -0,0 +1,181 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package tagstore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+)
+
+func TestApplyRules(t *testing.T) {
+	tags := sourceTags{
+		lowCardTags:          []string{"image_name:registry.example.com/payments/api", "team:payments", "kube_namespace:prod", "env:prod"},
+		orchestratorCardTags: []string{"pod_name:api-6d4b7"},
+		highCardTags:         []string{"container_id:abcdef"},
+		standardTags:         []string{"env:prod"},
+	}
+
+	tests := []struct {
+		name     string
+		rules    []*Rule
+		expected sourceTags
+	}{
+		{
+			name: "derive with a regex capture",
+			rules: []*Rule{
+				{Type: DeriveRule, Name: "registry", Tag: "image_name", Pattern: `^([^/]+)/`, Target: "registry"},
+				{Type: DeriveRule, Name: "project", Tag: "image_name", Pattern: `^[^/]+/(?P<project>[^/]+)/(?P<app>.+)$`, Target: "project", Value: "${project}-${app}"},
+				{Type: DeriveRule, Name: "no match", Tag: "image_name", Pattern: `^docker\.io/`, Target: "public"},
+			},
+			expected: sourceTags{
+				lowCardTags:          []string{"image_name:registry.example.com/payments/api", "team:payments", "kube_namespace:prod", "env:prod", "registry:registry.example.com", "project:payments-api"},
+				orchestratorCardTags: []string{"pod_name:api-6d4b7"},
+				highCardTags:         []string{"container_id:abcdef"},
+				standardTags:         []string{"env:prod"},
+			},
+		},
+		{
+			name: "copy and rename",
+			rules: []*Rule{
+				{Type: DeriveRule, Name: "owner", Tag: "team", Target: "owner"},
+				{Type: RenameRule, Name: "namespace", Tag: "kube_namespace", Target: "namespace"},
+				{Type: RenameRule, Name: "environment", Tag: "env", Target: "environment"},
+			},
+			expected: sourceTags{
+				lowCardTags:          []string{"image_name:registry.example.com/payments/api", "team:payments", "namespace:prod", "environment:prod", "owner:payments"},
+				orchestratorCardTags: []string{"pod_name:api-6d4b7"},
+				highCardTags:         []string{"container_id:abcdef"},
+			},
+		},
+		{
+			name: "lookup",
+			rules: []*Rule{
+				{Type: LookupRule, Name: "cost center", Tag: "kube_namespace", Target: "cost_center", Values: map[string]string{"prod": "cc-1234"}},
+				{Type: LookupRule, Name: "tier", Tag: "kube_namespace", Target: "tier", Values: map[string]string{"dev": "2"}, Default: "1"},
+				{Type: LookupRule, Name: "no default", Tag: "team", Target: "budget", Values: map[string]string{"search": "b-1"}},
+			},
+			expected: sourceTags{
+				lowCardTags:          []string{"image_name:registry.example.com/payments/api", "team:payments", "kube_namespace:prod", "env:prod", "cost_center:cc-1234", "tier:1"},
+				orchestratorCardTags: []string{"pod_name:api-6d4b7"},
+				highCardTags:         []string{"container_id:abcdef"},
+				standardTags:         []string{"env:prod"},
+			},
+		},
+		{
+			name: "existing tags are not overridden",
+			rules: []*Rule{
+				{Type: DeriveRule, Name: "team", Tag: "kube_namespace", Target: "team"},
+			},
+			expected: tags,
+		},
+		{
+			name: "drop",
+			rules: []*Rule{
+				{Type: DropRule, Name: "image", Tag: "image_name"},
+				{Type: DropRule, Name: "prod", Tag: "kube_namespace", Pattern: "^prod"},
+				{Type: DropRule, Name: "env", Pattern: "^env:"},
+			},
+			expected: sourceTags{
+				lowCardTags:          []string{"team:payments"},
+				orchestratorCardTags: []string{"pod_name:api-6d4b7"},
+				highCardTags:         []string{"container_id:abcdef"},
+			},
+		},
+		{
+			name: "cardinality",
+			rules: []*Rule{
+				{Type: DropRule, Name: "pod name", Tag: "pod_name", Cardinality: "low"},
+				{Type: DropRule, Name: "container id", Tag: "container_id", Cardinality: "high"},
+				{Type: DeriveRule, Name: "pod", Tag: "pod_name", Target: "pod", Cardinality: "orchestrator"},
+			},
+			expected: sourceTags{
+				lowCardTags:          []string{"image_name:registry.example.com/payments/api", "team:payments", "kube_namespace:prod", "env:prod"},
+				orchestratorCardTags: []string{"pod_name:api-6d4b7", "pod:api-6d4b7"},
+				highCardTags:         []string{},
+				standardTags:         []string{"env:prod"},
+			},
+		},
+		{
+			name: "rules use the tags of the previous ones",
+			rules: []*Rule{
+				{Type: DeriveRule, Name: "team", Tag: "image_name", Pattern: `^[^/]+/([^/]+)/`, Target: "owner"},
+				{Type: LookupRule, Name: "cost center", Tag: "owner", Target: "cost_center", Values: map[string]string{"payments": "cc-42"}},
+				{Type: DropRule, Name: "image", Tag: "image_name"},
+			},
+			expected: sourceTags{
+				lowCardTags:          []string{"team:payments", "kube_namespace:prod", "env:prod", "owner:payments", "cost_center:cc-42"},
+				orchestratorCardTags: []string{"pod_name:api-6d4b7"},
+				highCardTags:         []string{"container_id:abcdef"},
+				standardTags:         []string{"env:prod"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.NoError(t, CompileRules(test.rules))
+			assert.Equal(t, test.expected, applyRules(test.rules, tags))
+		})
+	}
+
+	assert.Equal(t, tags, applyRules(nil, tags))
+}
+
+func TestCompileRulesErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  *Rule
+		error string
+	}{
+		{name: "no name", rule: &Rule{Type: DropRule, Tag: "foo"}, error: "all tag rules must have a name"},
+		{name: "no type", rule: &Rule{Name: "r", Tag: "foo"}, error: "type must be set for tag rule `r`"},
+		{name: "unknown type", rule: &Rule{Type: "replace", Name: "r"}, error: "type replace is not supported for tag rule `r`"},
+		{name: "derive without target", rule: &Rule{Type: DeriveRule, Name: "r", Tag: "foo"}, error: "tag and target must be set for tag rule `r`"},
+		{name: "lookup without values", rule: &Rule{Type: LookupRule, Name: "r", Tag: "foo", Target: "bar"}, error: "tag, target and values must be set for tag rule `r`"},
+		{name: "empty drop", rule: &Rule{Type: DropRule, Name: "r"}, error: "tag or pattern must be set for tag rule `r`"},
+		{name: "pattern on rename", rule: &Rule{Type: RenameRule, Name: "r", Tag: "foo", Target: "bar", Pattern: "x"}, error: "pattern is not supported by rename rules, in tag rule `r`"},
+		{name: "invalid pattern", rule: &Rule{Type: DropRule, Name: "r", Pattern: "("}, error: "invalid pattern ( for tag rule `r`: error parsing regexp: missing closing ): `(`"},
+		{name: "invalid cardinality", rule: &Rule{Type: DropRule, Name: "r", Tag: "foo", Cardinality: "none"}, error: "cardinality none is not supported for tag rule `r`"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.EqualError(t, CompileRules([]*Rule{test.rule}), test.error)
+		})
+	}
+}
+
+func TestGetRules(t *testing.T) {
+	cfg := configmock.New(t)
+
+	rules, err := GetRules(cfg)
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	cfg.SetWithoutSource("tagger_rules", []map[string]interface{}{
+		{"type": "lookup", "name": "cost center", "tag": "kube_namespace", "target": "cost_center", "values": map[string]interface{}{"prod": "cc-1"}},
+		{"type": "drop", "name": "pod name", "tag": "pod_name", "cardinality": "low"},
+	})
+	rules, err = GetRules(cfg)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, map[string]string{"prod": "cc-1"}, rules[0].Values)
+	assert.Equal(t, "low", rules[1].Cardinality)
+
+	cfg.SetWithoutSource("tagger_rules", `[{"type": "rename", "name": "owner", "tag": "team", "target": "owner"}]`)
+	rules, err = GetRules(cfg)
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "owner", rules[0].Target)
+
+	cfg.SetWithoutSource("tagger_rules", `[{"type": "rename", "name": "owner"}]`)
+	_, err = GetRules(cfg)
+	assert.Error(t, err)
+}
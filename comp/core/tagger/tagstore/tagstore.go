@@ -45,6 +45,8 @@ type TagStore struct {
 	clock clock.Clock
 
 	telemetryStore *telemetry.Store
+
+	rules []*Rule
 }
 
 // NewTagStore creates new LocalTaggerTagStore.
@@ -64,6 +66,15 @@ func newTagStoreWithClock(clock clock.Clock, telemetryStore *telemetry.Store) *T
 	}
 }
 
+// SetRules sets the rules applied to the tags of the entities, once the tags
+// reported by the collectors are merged. It must be called before the
+// collectors start, as the entities already stored are not updated.
+func (s *TagStore) SetRules(rules []*Rule) {
+	s.Lock()
+	defer s.Unlock()
+	s.rules = rules
+}
+
 // Run performs background maintenance for TagStore.
 func (s *TagStore) Run(ctx context.Context) {
 	pruneTicker := time.NewTicker(1 * time.Minute)
@@ -135,7 +146,7 @@ func (s *TagStore) ProcessTagInfo(tagInfos []*types.TagInfo) {
 			}
 		} else {
 			eventType = types.EventTypeAdded
-			storedTags = newEntityTags(info.EntityID, info.Source)
+			storedTags = newEntityTags(info.EntityID, info.Source, s.rules)
 			s.store.Set(info.EntityID, storedTags)
 		}
 
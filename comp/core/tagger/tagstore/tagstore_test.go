@@ -64,6 +64,55 @@ func (s *StoreTestSuite) TestIngest() {
 	assert.Len(s.T(), storedTags.sources(), 2)
 }
 
+func (s *StoreTestSuite) TestIngestWithRules() {
+	entityID := types.NewEntityID(types.ContainerID, "test")
+	rules := []*Rule{
+		{Type: LookupRule, Name: "cost center", Tag: "kube_namespace", Target: "cost_center", Values: map[string]string{"prod": "cc-1"}},
+		{Type: DropRule, Name: "pod name", Tag: "pod_name"},
+	}
+	require.NoError(s.T(), CompileRules(rules))
+	s.tagstore.SetRules(rules)
+
+	s.tagstore.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:               "source1",
+			EntityID:             entityID,
+			LowCardTags:          []string{"kube_namespace:prod"},
+			OrchestratorCardTags: []string{"pod_name:foo"},
+		},
+	})
+
+	assert.ElementsMatch(s.T(), []string{"kube_namespace:prod", "cost_center:cc-1"}, s.tagstore.Lookup(entityID, types.HighCardinality))
+}
+
+func (s *StoreTestSuite) TestIngestWithRulesMergesSources() {
+	entityID := types.NewEntityID(types.ContainerID, "test")
+	rules := []*Rule{
+		{Type: LookupRule, Name: "cost center", Tag: "kube_namespace", Target: "cost_center", Values: map[string]string{"prod": "cc-1"}},
+		{Type: DeriveRule, Name: "team", Tag: "kube_deployment", Pattern: "^([a-z]+)-", Target: "team"},
+	}
+	require.NoError(s.T(), CompileRules(rules))
+	s.tagstore.SetRules(rules)
+
+	// the rules see the tags of all the sources: the cost center is set by
+	// source2, and the deployment reported by source2 gets the namespace of
+	// source1
+	s.tagstore.ProcessTagInfo([]*types.TagInfo{
+		{
+			Source:      "source1",
+			EntityID:    entityID,
+			LowCardTags: []string{"kube_namespace:prod"},
+		},
+		{
+			Source:      "source2",
+			EntityID:    entityID,
+			LowCardTags: []string{"cost_center:manual", "kube_deployment:payments-api"},
+		},
+	})
+
+	assert.ElementsMatch(s.T(), []string{"kube_namespace:prod", "cost_center:manual", "kube_deployment:payments-api", "team:payments"}, s.tagstore.Lookup(entityID, types.LowCardinality))
+}
+
 func (s *StoreTestSuite) TestLookup() {
 	entityID := types.NewEntityID(types.ContainerID, "test")
 	s.tagstore.ProcessTagInfo([]*types.TagInfo{
@@ -23,6 +23,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/core/workloadmeta/collectors/internal/podman"
 	"github.com/DataDog/datadog-agent/comp/core/workloadmeta/collectors/internal/processlanguage"
 	remoteprocesscollector "github.com/DataDog/datadog-agent/comp/core/workloadmeta/collectors/internal/remote/processcollector"
+	"github.com/DataDog/datadog-agent/comp/core/workloadmeta/collectors/internal/systemd"
 )
 
 func getCollectorOptions() []fx.Option {
@@ -40,5 +41,6 @@ func getCollectorOptions() []fx.Option {
 		remoteprocesscollector.GetFxOptions(),
 		processlanguage.GetFxOptions(),
 		nvml.GetFxOptions(),
+		systemd.GetFxOptions(),
 	}
 }
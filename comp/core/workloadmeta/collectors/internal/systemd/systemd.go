@@ -0,0 +1,284 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux && systemd
+
+package systemd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"go.uber.org/fx"
+
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+	"github.com/DataDog/datadog-agent/pkg/errors"
+	"github.com/DataDog/datadog-agent/pkg/util/kernel"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	systemdutil "github.com/DataDog/datadog-agent/pkg/util/systemd"
+)
+
+const (
+	collectorID   = "systemd"
+	componentName = "workloadmeta-systemd"
+
+	serviceSuffix = ".service"
+)
+
+// systemdConn is the subset of the D-Bus connection to systemd used by the
+// collector
+type systemdConn interface {
+	ListUnitsContext(ctx context.Context) ([]dbus.UnitStatus, error)
+	GetUnitTypePropertiesContext(ctx context.Context, unit string, unitType string) (map[string]interface{}, error)
+	Close()
+}
+
+// unitSignals is the subscription to the signals systemd sends when units are
+// created, removed or changed
+type unitSignals interface {
+	ChangedUnits() (map[string]struct{}, error)
+	Close()
+}
+
+type collector struct {
+	id      string
+	catalog workloadmeta.AgentType
+	store   workloadmeta.Component
+
+	connect func() (systemdConn, error)
+	conn    systemdConn
+	// subscribe subscribes to the signals of systemd, so that the properties
+	// of the units are only queried when they change
+	subscribe func() (unitSignals, error)
+	signals   unitSignals
+	// resync makes the next pull query the properties of all the units, as
+	// their signals may have been missed
+	resync bool
+	// readEnviron returns the environment of a process
+	readEnviron func(pid int) ([]string, error)
+	units       map[string]*workloadmeta.SystemdUnit
+}
+
+// NewCollector returns a new systemd collector provider and an error
+func NewCollector() (workloadmeta.CollectorProvider, error) {
+	return workloadmeta.CollectorProvider{
+		Collector: &collector{
+			id:          collectorID,
+			catalog:     workloadmeta.NodeAgent,
+			connect:     connect,
+			subscribe:   subscribe,
+			readEnviron: readEnviron,
+			units:       make(map[string]*workloadmeta.SystemdUnit),
+		},
+	}, nil
+}
+
+// GetFxOptions returns the FX framework options for the collector
+func GetFxOptions() fx.Option {
+	return fx.Provide(NewCollector)
+}
+
+// Start connects to systemd
+func (c *collector) Start(_ context.Context, store workloadmeta.Component) error {
+	if !pkgconfigsetup.Datadog().GetBool("workloadmeta.systemd_collector.enabled") {
+		return errors.NewDisabled(componentName, "systemd unit collection is disabled")
+	}
+
+	conn, err := c.connect()
+	if err != nil {
+		return fmt.Errorf("unable to connect to systemd: %w", err)
+	}
+
+	c.store = store
+	c.conn = conn
+
+	return nil
+}
+
+// Pull lists the services running on the host and notifies the store of the
+// ones that started, changed or stopped since the previous pull
+func (c *collector) Pull(ctx context.Context) error {
+	if c.conn == nil {
+		conn, err := c.connect()
+		if err != nil {
+			return fmt.Errorf("unable to connect to systemd: %w", err)
+		}
+		c.conn = conn
+	}
+
+	// the subscription is done before listing the units so that no change
+	// is missed
+	if c.signals == nil {
+		signals, err := c.subscribe()
+		if err != nil {
+			return fmt.Errorf("unable to subscribe to the systemd signals: %w", err)
+		}
+		c.signals = signals
+		c.resync = true
+	}
+	changed, err := c.signals.ChangedUnits()
+	if err != nil {
+		// subscribe again on the next pull
+		log.Debugf("Lost the subscription to the systemd signals: %v", err)
+		c.signals.Close()
+		c.signals = nil
+	}
+
+	units, err := c.conn.ListUnitsContext(ctx)
+	if err != nil {
+		// the connection can't be reused once systemd restarted, connect
+		// again on the next pull, which handles the changes received since
+		c.conn.Close()
+		c.conn = nil
+		c.resync = true
+		return fmt.Errorf("unable to list the systemd units: %w", err)
+	}
+
+	var events []workloadmeta.CollectorEvent
+	seen := make(map[string]struct{}, len(c.units))
+
+	for _, unit := range units {
+		if !isCollected(unit) {
+			continue
+		}
+		seen[unit.Name] = struct{}{}
+
+		// the properties are only queried when systemd reports that the
+		// unit changed, like when its main process changes on restart, as
+		// there can be hundreds of services on a host
+		_, unitChanged := changed[unit.Name]
+		if cached, found := c.units[unit.Name]; found && !c.resync && !unitChanged && cached.ActiveState == unit.ActiveState && cached.SubState == unit.SubState {
+			continue
+		}
+
+		entity := c.buildUnit(ctx, unit)
+		c.units[unit.Name] = entity
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeSet,
+			Source: workloadmeta.SourceSystemd,
+			Entity: entity,
+		})
+	}
+
+	for name, entity := range c.units {
+		if _, found := seen[name]; found {
+			continue
+		}
+		delete(c.units, name)
+		events = append(events, workloadmeta.CollectorEvent{
+			Type:   workloadmeta.EventTypeUnset,
+			Source: workloadmeta.SourceSystemd,
+			Entity: entity,
+		})
+	}
+
+	if len(events) > 0 {
+		c.store.Notify(events)
+	}
+	c.resync = false
+
+	return nil
+}
+
+func (c *collector) GetID() string {
+	return c.id
+}
+
+func (c *collector) GetTargetCatalog() workloadmeta.AgentType {
+	return c.catalog
+}
+
+// isCollected returns whether a unit is a loaded service that is running or
+// about to run
+func isCollected(unit dbus.UnitStatus) bool {
+	if !strings.HasSuffix(unit.Name, serviceSuffix) || unit.LoadState != "loaded" {
+		return false
+	}
+	switch unit.ActiveState {
+	case "active", "activating", "reloading":
+		return true
+	}
+	return false
+}
+
+func (c *collector) buildUnit(ctx context.Context, unit dbus.UnitStatus) *workloadmeta.SystemdUnit {
+	entity := &workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   unit.Name,
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: unit.Name,
+		},
+		Description: unit.Description,
+		ActiveState: unit.ActiveState,
+		SubState:    unit.SubState,
+	}
+
+	properties, err := c.conn.GetUnitTypePropertiesContext(ctx, unit.Name, "Service")
+	if err != nil {
+		log.Debugf("Unable to get the properties of the systemd unit %s: %v", unit.Name, err)
+		return entity
+	}
+
+	if mainPID, ok := properties["MainPID"].(uint32); ok {
+		entity.MainPID = int(mainPID)
+	}
+	if cgroup, ok := properties["ControlGroup"].(string); ok {
+		entity.CGroup = cgroup
+	}
+
+	// the environment of the main process also contains the variables set
+	// with EnvironmentFile, it has precedence over the Environment property
+	environment, _ := properties["Environment"].([]string)
+	if entity.MainPID > 0 {
+		if environ, err := c.readEnviron(entity.MainPID); err == nil {
+			environment = append(environment, environ...)
+		} else {
+			log.Debugf("Unable to read the environment of the main process of the systemd unit %s: %v", unit.Name, err)
+		}
+	}
+	for _, variable := range environment {
+		name, value, _ := strings.Cut(variable, "=")
+		switch name {
+		case "DD_SERVICE":
+			entity.Service = value
+		case "DD_ENV":
+			entity.Env = value
+		case "DD_VERSION":
+			entity.Version = value
+		}
+	}
+
+	return entity
+}
+
+func connect() (systemdConn, error) {
+	return systemdutil.NewConnection(pkgconfigsetup.Datadog().GetString("workloadmeta.systemd_collector.private_socket"))
+}
+
+func subscribe() (unitSignals, error) {
+	return systemdutil.SubscribeToUnitSignals(pkgconfigsetup.Datadog().GetString("workloadmeta.systemd_collector.private_socket"))
+}
+
+func readEnviron(pid int) ([]string, error) {
+	content, err := os.ReadFile(kernel.HostProc(strconv.Itoa(pid), "environ"))
+	if err != nil {
+		return nil, err
+	}
+	var environ []string
+	for _, variable := range bytes.Split(content, []byte{0}) {
+		if len(variable) > 0 {
+			environ = append(environ, string(variable))
+		}
+	}
+	return environ, nil
+}
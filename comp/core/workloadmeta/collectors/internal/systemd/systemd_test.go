@@ -0,0 +1,231 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build linux && systemd
+
+package systemd
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/coreos/go-systemd/v22/dbus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx"
+
+	"github.com/DataDog/datadog-agent/comp/core"
+	workloadmeta "github.com/DataDog/datadog-agent/comp/core/workloadmeta/def"
+	workloadmetafxmock "github.com/DataDog/datadog-agent/comp/core/workloadmeta/fx-mock"
+	workloadmetamock "github.com/DataDog/datadog-agent/comp/core/workloadmeta/mock"
+	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
+)
+
+type fakeConn struct {
+	units      []dbus.UnitStatus
+	properties map[string]map[string]interface{}
+	listErr    error
+	queried    []string
+	closed     bool
+}
+
+func (f *fakeConn) ListUnitsContext(_ context.Context) ([]dbus.UnitStatus, error) {
+	return f.units, f.listErr
+}
+
+func (f *fakeConn) GetUnitTypePropertiesContext(_ context.Context, unit string, _ string) (map[string]interface{}, error) {
+	f.queried = append(f.queried, unit)
+	properties, found := f.properties[unit]
+	if !found {
+		return nil, errors.New("unit not found")
+	}
+	return properties, nil
+}
+
+func (f *fakeConn) Close() {
+	f.closed = true
+}
+
+type fakeSignals struct {
+	changed map[string]struct{}
+	err     error
+	closed  bool
+}
+
+func (f *fakeSignals) ChangedUnits() (map[string]struct{}, error) {
+	changed := f.changed
+	f.changed = nil
+	return changed, f.err
+}
+
+func (f *fakeSignals) Close() {
+	f.closed = true
+}
+
+func newTestCollector(t *testing.T, conn *fakeConn, signals *fakeSignals) (*collector, workloadmetamock.Mock) {
+	store := fxutil.Test[workloadmetamock.Mock](t, fx.Options(
+		core.MockBundle(),
+		workloadmetafxmock.MockModule(workloadmeta.NewParams()),
+	))
+
+	return &collector{
+		id:        collectorID,
+		catalog:   workloadmeta.NodeAgent,
+		store:     store,
+		connect:   func() (systemdConn, error) { return conn, nil },
+		conn:      conn,
+		subscribe: func() (unitSignals, error) { return signals, nil },
+		readEnviron: func(pid int) ([]string, error) {
+			if pid == 42 {
+				return []string{"PATH=/usr/bin", "DD_ENV=prod", "DD_VERSION=1.25"}, nil
+			}
+			return nil, errors.New("permission denied")
+		},
+		units: make(map[string]*workloadmeta.SystemdUnit),
+	}, store
+}
+
+func TestPull(t *testing.T) {
+	conn := &fakeConn{
+		units: []dbus.UnitStatus{
+			{Name: "nginx.service", Description: "A high performance web server", LoadState: "loaded", ActiveState: "active", SubState: "running"},
+			{Name: "redis.service", Description: "Redis", LoadState: "loaded", ActiveState: "active", SubState: "running"},
+			{Name: "cron.service", LoadState: "loaded", ActiveState: "inactive", SubState: "dead"},
+			{Name: "missing.service", LoadState: "not-found", ActiveState: "active", SubState: "running"},
+			{Name: "sshd.socket", LoadState: "loaded", ActiveState: "active", SubState: "listening"},
+		},
+		properties: map[string]map[string]interface{}{
+			"nginx.service": {
+				"MainPID":      uint32(42),
+				"ControlGroup": "/system.slice/nginx.service",
+				"Environment":  []string{"DD_SERVICE=web", "DD_ENV=staging"},
+			},
+			"redis.service": {
+				"MainPID":      uint32(43),
+				"ControlGroup": "/system.slice/redis.service",
+				"Environment":  []string{"DD_SERVICE=cache"},
+			},
+		},
+	}
+	signals := &fakeSignals{}
+	c, store := newTestCollector(t, conn, signals)
+
+	require.NoError(t, c.Pull(context.Background()))
+
+	nginx, err := store.GetSystemdUnit("nginx.service")
+	require.NoError(t, err)
+	assert.Equal(t, &workloadmeta.SystemdUnit{
+		EntityID: workloadmeta.EntityID{
+			Kind: workloadmeta.KindSystemdUnit,
+			ID:   "nginx.service",
+		},
+		EntityMeta: workloadmeta.EntityMeta{
+			Name: "nginx.service",
+		},
+		Description: "A high performance web server",
+		ActiveState: "active",
+		SubState:    "running",
+		MainPID:     42,
+		CGroup:      "/system.slice/nginx.service",
+		Service:     "web",
+		Env:         "prod",
+		Version:     "1.25",
+	}, nginx)
+
+	redis, err := store.GetSystemdUnit("redis.service")
+	require.NoError(t, err)
+	assert.Equal(t, 43, redis.MainPID)
+	assert.Equal(t, "cache", redis.Service)
+	assert.Empty(t, redis.Env)
+
+	assert.Len(t, store.ListSystemdUnits(), 2)
+	assert.ElementsMatch(t, []string{"nginx.service", "redis.service"}, conn.queried)
+
+	// the properties of the units that did not change are not queried
+	// again, and the stopped units are removed
+	conn.queried = nil
+	conn.units[1].ActiveState = "deactivating"
+	require.NoError(t, c.Pull(context.Background()))
+
+	assert.Empty(t, conn.queried)
+	_, err = store.GetSystemdUnit("redis.service")
+	assert.Error(t, err)
+	assert.Len(t, store.ListSystemdUnits(), 1)
+
+	conn.units[0].SubState = "reloading"
+	require.NoError(t, c.Pull(context.Background()))
+
+	assert.Equal(t, []string{"nginx.service"}, conn.queried)
+	nginx, err = store.GetSystemdUnit("nginx.service")
+	require.NoError(t, err)
+	assert.Equal(t, "reloading", nginx.SubState)
+
+	// a service restarted between two pulls has a new main process, which
+	// systemd signals
+	conn.queried = nil
+	conn.properties["nginx.service"]["MainPID"] = uint32(44)
+	signals.changed = map[string]struct{}{"nginx.service": {}}
+	require.NoError(t, c.Pull(context.Background()))
+
+	assert.Equal(t, []string{"nginx.service"}, conn.queried)
+	nginx, err = store.GetSystemdUnit("nginx.service")
+	require.NoError(t, err)
+	assert.Equal(t, 44, nginx.MainPID)
+	// the environment of the new main process is not readable
+	assert.Equal(t, "staging", nginx.Env)
+}
+
+func TestPullReconnects(t *testing.T) {
+	conn := &fakeConn{listErr: errors.New("connection closed")}
+	c, _ := newTestCollector(t, conn, &fakeSignals{})
+
+	require.Error(t, c.Pull(context.Background()))
+	assert.True(t, conn.closed)
+	assert.Nil(t, c.conn)
+
+	conn.listErr = nil
+	require.NoError(t, c.Pull(context.Background()))
+	assert.Equal(t, conn, c.conn)
+}
+
+func TestPullResubscribes(t *testing.T) {
+	conn := &fakeConn{
+		units: []dbus.UnitStatus{
+			{Name: "nginx.service", LoadState: "loaded", ActiveState: "active", SubState: "running"},
+		},
+		properties: map[string]map[string]interface{}{
+			"nginx.service": {"MainPID": uint32(42)},
+		},
+	}
+	signals := &fakeSignals{}
+	c, store := newTestCollector(t, conn, signals)
+	require.NoError(t, c.Pull(context.Background()))
+
+	// the signals received until the connection was lost are handled
+	conn.queried = nil
+	signals.err = errors.New("connection closed")
+	signals.changed = map[string]struct{}{"nginx.service": {}}
+	require.NoError(t, c.Pull(context.Background()))
+	assert.True(t, signals.closed)
+	assert.Nil(t, c.signals)
+	assert.Equal(t, []string{"nginx.service"}, conn.queried)
+
+	// the units may have changed without being signaled before subscribing
+	// again, so they are all queried
+	conn.queried = nil
+	conn.properties["nginx.service"]["MainPID"] = uint32(44)
+	signals.err = nil
+	require.NoError(t, c.Pull(context.Background()))
+	assert.Equal(t, signals, c.signals)
+	assert.Equal(t, []string{"nginx.service"}, conn.queried)
+	nginx, err := store.GetSystemdUnit("nginx.service")
+	require.NoError(t, err)
+	assert.Equal(t, 44, nginx.MainPID)
+
+	conn.queried = nil
+	require.NoError(t, c.Pull(context.Background()))
+	assert.Empty(t, conn.queried)
+}
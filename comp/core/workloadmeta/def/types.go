@@ -47,6 +47,7 @@ const (
 	KindContainerImageMetadata Kind = "container_image_metadata"
 	KindProcess                Kind = "process"
 	KindGPU                    Kind = "gpu"
+	KindSystemdUnit            Kind = "systemd_unit"
 )
 
 // Source is the source name of an entity.
@@ -95,6 +96,10 @@ const (
 	// SourceLocalProcessCollector reprents processes entities detected
 	// by the LocalProcessCollector.
 	SourceLocalProcessCollector Source = "local_process_collector"
+
+	// SourceSystemd represents units detected by querying systemd on the
+	// host. `systemd` uses this.
+	SourceSystemd Source = "systemd"
 )
 
 // ContainerRuntime is the container runtime used by a container.
@@ -1516,6 +1521,77 @@ func (gcc GPUComputeCapability) String() string {
 	return fmt.Sprintf("%d.%d", gcc.Major, gcc.Minor)
 }
 
+// SystemdUnit is an Entity representing a systemd unit running on the host,
+// like a service. Its ID is the name of the unit (e.g. nginx.service).
+type SystemdUnit struct {
+	EntityID
+	EntityMeta
+	// Description is the description of the unit from its unit file
+	Description string
+	// ActiveState and SubState are the states of the unit as reported by
+	// systemd (e.g. active and running)
+	ActiveState string
+	SubState    string
+	// MainPID is the PID of the main process of the service, 0 if there is none
+	MainPID int
+	// CGroup is the path of the control group of the unit
+	CGroup string
+	// Service, Env and Version are the unified service tagging values set
+	// with the DD_SERVICE, DD_ENV and DD_VERSION environment variables of
+	// the unit
+	Service string
+	Env     string
+	Version string
+}
+
+var _ Entity = &SystemdUnit{}
+
+// GetID implements Entity#GetID.
+func (u SystemdUnit) GetID() EntityID {
+	return u.EntityID
+}
+
+// Merge implements Entity#Merge.
+func (u *SystemdUnit) Merge(e Entity) error {
+	uu, ok := e.(*SystemdUnit)
+	if !ok {
+		return fmt.Errorf("cannot merge SystemdUnit with different kind %T", e)
+	}
+
+	return merge(u, uu)
+}
+
+// DeepCopy implements Entity#DeepCopy.
+func (u SystemdUnit) DeepCopy() Entity {
+	cp := deepcopy.Copy(u).(SystemdUnit)
+	return &cp
+}
+
+// String implements Entity#String.
+func (u SystemdUnit) String(verbose bool) string {
+	var sb strings.Builder
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity ID -----------")
+	_, _ = fmt.Fprintln(&sb, u.EntityID.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Entity Meta -----------")
+	_, _ = fmt.Fprint(&sb, u.EntityMeta.String(verbose))
+
+	_, _ = fmt.Fprintln(&sb, "----------- Unit Info -----------")
+	_, _ = fmt.Fprintln(&sb, "Description:", u.Description)
+	_, _ = fmt.Fprintln(&sb, "Active State:", u.ActiveState)
+	_, _ = fmt.Fprintln(&sb, "Sub State:", u.SubState)
+	_, _ = fmt.Fprintln(&sb, "Main PID:", u.MainPID)
+	if verbose {
+		_, _ = fmt.Fprintln(&sb, "CGroup:", u.CGroup)
+		_, _ = fmt.Fprintln(&sb, "Service:", u.Service)
+		_, _ = fmt.Fprintln(&sb, "Env:", u.Env)
+		_, _ = fmt.Fprintln(&sb, "Version:", u.Version)
+	}
+
+	return sb.String()
+}
+
 // CollectorStatus is the status of collector which is used to determine if the collectors
 // are not started, starting, started (pulled once)
 type CollectorStatus uint8
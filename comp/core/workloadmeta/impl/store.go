@@ -425,6 +425,28 @@ func (w *workloadmeta) ListGPUs() []*wmdef.GPU {
 	return gpuList
 }
 
+// GetSystemdUnit implements Store#GetSystemdUnit.
+func (w *workloadmeta) GetSystemdUnit(name string) (*wmdef.SystemdUnit, error) {
+	entity, err := w.getEntityByKind(wmdef.KindSystemdUnit, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.(*wmdef.SystemdUnit), nil
+}
+
+// ListSystemdUnits implements Store#ListSystemdUnits.
+func (w *workloadmeta) ListSystemdUnits() []*wmdef.SystemdUnit {
+	entities := w.listEntitiesByKind(wmdef.KindSystemdUnit)
+
+	unitList := make([]*wmdef.SystemdUnit, 0, len(entities))
+	for i := range entities {
+		unitList = append(unitList, entities[i].(*wmdef.SystemdUnit))
+	}
+
+	return unitList
+}
+
 // Notify implements Store#Notify
 func (w *workloadmeta) Notify(events []wmdef.CollectorEvent) {
 	if len(events) > 0 {
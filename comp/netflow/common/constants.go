@@ -26,4 +26,33 @@ const (
 
 	// DefaultPrometheusListenerAddress is the default goflow prometheus listener address
 	DefaultPrometheusListenerAddress = "localhost:9090"
+
+	// DefaultAggregatorTopTalkersCapacityFactor is the default number of talkers tracked per
+	// exporter, relatively to the number of top talkers reported
+	DefaultAggregatorTopTalkersCapacityFactor = 10
 )
+
+// Fields of the flow aggregation key that can be selected with `aggregator_keys`.
+// The namespace and exporter IP address are always part of the aggregation key.
+const (
+	AggregationKeySourceIP         = "source.ip"
+	AggregationKeySourcePort       = "source.port"
+	AggregationKeyDestinationIP    = "destination.ip"
+	AggregationKeyDestinationPort  = "destination.port"
+	AggregationKeyIPProtocol       = "ip_protocol"
+	AggregationKeyTos              = "tos"
+	AggregationKeyIngressInterface = "ingress.interface"
+)
+
+// GetAllAggregationKeys returns all the fields of the flow aggregation key that can be selected
+func GetAllAggregationKeys() []string {
+	return []string{
+		AggregationKeySourceIP,
+		AggregationKeySourcePort,
+		AggregationKeyDestinationIP,
+		AggregationKeyDestinationPort,
+		AggregationKeyIPProtocol,
+		AggregationKeyTos,
+		AggregationKeyIngressInterface,
+	}
+}
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package common
+
+// SFlowCounterSample contains the counter records of an sFlow counter sample.
+// Only the records of known formats are set.
+type SFlowCounterSample struct {
+	Namespace    string
+	ExporterAddr []byte // sFlow agent address
+
+	Interface  *InterfaceCounters
+	HostCPU    *HostCPUCounters
+	HostMemory *HostMemoryCounters
+}
+
+// InterfaceCounters contains generic interface counters (sFlow counter record format 1)
+type InterfaceCounters struct {
+	Index     uint32
+	Type      uint32
+	Speed     uint64 // in bits per second
+	Direction uint32 // 0 = unknown, 1 = full-duplex, 2 = half-duplex, 3 = in, 4 = out
+	Status    uint32 // bit 0 = admin status up, bit 1 = oper status up
+
+	InOctets         uint64
+	InUcastPkts      uint32
+	InMulticastPkts  uint32
+	InBroadcastPkts  uint32
+	InDiscards       uint32
+	InErrors         uint32
+	InUnknownProtos  uint32
+	OutOctets        uint64
+	OutUcastPkts     uint32
+	OutMulticastPkts uint32
+	OutBroadcastPkts uint32
+	OutDiscards      uint32
+	OutErrors        uint32
+}
+
+// HostCPUCounters contains host CPU counters (sFlow counter record format 2003)
+type HostCPUCounters struct {
+	LoadOne     float32
+	LoadFive    float32
+	LoadFifteen float32
+	ProcRun     uint32
+	ProcTotal   uint32
+	CPUNum      uint32
+	CPUSpeed    uint32 // in MHz
+	Uptime      uint32 // in seconds
+	// CPU times, in milliseconds
+	CPUUser    uint32
+	CPUNice    uint32
+	CPUSystem  uint32
+	CPUIdle    uint32
+	CPUWio     uint32
+	CPUIntr    uint32
+	CPUSIntr   uint32
+	Interrupts uint32
+	Contexts   uint32
+}
+
+// HostMemoryCounters contains host memory counters (sFlow counter record format 2004), in bytes
+type HostMemoryCounters struct {
+	MemTotal   uint64
+	MemFree    uint64
+	MemShared  uint64
+	MemBuffers uint64
+	MemCached  uint64
+	SwapTotal  uint64
+	SwapFree   uint64
+	PageIn     uint32
+	PageOut    uint32
+	SwapIn     uint32
+	SwapOut    uint32
+}
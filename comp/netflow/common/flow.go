@@ -67,11 +67,39 @@ type Flow struct {
 	SrcReverseDNSHostname string
 	DstReverseDNSHostname string
 
+	// GeoIP and ASN enrichment added during Flow aggregation processing
+	SrcCountry        string // ISO 3166-1 country code
+	DstCountry        string
+	SrcASN            uint32
+	DstASN            uint32
+	SrcASOrganization string
+	DstASOrganization string
+
+	// CIDR tags enrichment added during Flow aggregation processing
+	SrcTags []string
+	DstTags []string
+
+	// Interface names enrichment added during Flow aggregation processing
+	InputInterfaceName  string
+	OutputInterfaceName string
+
 	// Ethernet information
 	Tos uint32 // FLOW KEY
 
 	NextHop []byte // FLOW KEY
 
+	// VLAN and MPLS information, decoded from sFlow sampled headers
+	VlanID     uint32
+	MPLSLabels []uint32
+
+	// Tunnel information, when the flow is decoded from the inner headers of a
+	// tunneled packet, e.g. VXLAN or GRE. TunnelSrcAddr and TunnelDstAddr are
+	// the addresses of the outer headers.
+	TunnelType    string
+	TunnelID      uint32 // VXLAN network identifier or GRE key
+	TunnelSrcAddr []byte
+	TunnelDstAddr []byte
+
 	// Configured fields
 	AdditionalFields AdditionalFields
 }
@@ -8,6 +8,8 @@ package config
 
 import (
 	"fmt"
+	"net/netip"
+	"slices"
 
 	"github.com/DataDog/datadog-agent/comp/core/config"
 	log "github.com/DataDog/datadog-agent/comp/core/log/def"
@@ -32,10 +34,41 @@ type NetflowConfig struct {
 	// AggregatorRollupTrackerRefreshInterval is useful to speed up testing to avoid wait for 1h default
 	AggregatorRollupTrackerRefreshInterval uint `mapstructure:"aggregator_rollup_tracker_refresh_interval"`
 
+	// AggregatorKeys are the fields used to aggregate flows, all fields are used when empty
+	AggregatorKeys []string `mapstructure:"aggregator_keys"`
+	// AggregatorIPv4PrefixLength and AggregatorIPv6PrefixLength aggregate flow endpoints by network, e.g. /24
+	AggregatorIPv4PrefixLength int `mapstructure:"aggregator_ipv4_prefix_length"`
+	AggregatorIPv6PrefixLength int `mapstructure:"aggregator_ipv6_prefix_length"`
+
+	// AggregatorTopTalkers is the number of top source and destination talkers reported per exporter, 0 to disable
+	AggregatorTopTalkers int `mapstructure:"aggregator_top_talkers"`
+	// AggregatorTopTalkersCapacity is the number of talkers tracked per exporter to find the top talkers
+	AggregatorTopTalkersCapacity int `mapstructure:"aggregator_top_talkers_capacity"`
+
 	PrometheusListenerAddress string `mapstructure:"prometheus_listener_address"` // Example `localhost:9090`
 	PrometheusListenerEnabled bool   `mapstructure:"prometheus_listener_enabled"`
 
 	ReverseDNSEnrichmentEnabled bool `mapstructure:"reverse_dns_enrichment_enabled"`
+
+	Enrichment EnrichmentConfig `mapstructure:"enrichment"`
+}
+
+// EnrichmentConfig contains configuration for the enrichment of flows
+type EnrichmentConfig struct {
+	// GeoIPDatabasePath is the path of a MaxMind DB file with countries, e.g. GeoLite2-Country.mmdb
+	GeoIPDatabasePath string `mapstructure:"geoip_database_path"`
+	// ASNDatabasePath is the path of a MaxMind DB file with autonomous systems, e.g. GeoLite2-ASN.mmdb
+	ASNDatabasePath string `mapstructure:"asn_database_path"`
+	// CIDRTags are tags added to the flow endpoints in the given networks
+	CIDRTags []CIDRTagsConfig `mapstructure:"cidr_tags"`
+	// InterfaceNamesEnabled enables resolving interface indexes to names using SNMP device metadata
+	InterfaceNamesEnabled bool `mapstructure:"interface_names_enabled"`
+}
+
+// CIDRTagsConfig contains the tags of the flow endpoints in a network
+type CIDRTagsConfig struct {
+	CIDR string   `mapstructure:"cidr"`
+	Tags []string `mapstructure:"tags"`
 }
 
 // ListenerConfig contains configuration for a single flow listener
@@ -113,6 +146,30 @@ func (mainConfig *NetflowConfig) SetDefaults(namespace string, logger log.Compon
 		}
 	}
 
+	for _, cidrTags := range mainConfig.Enrichment.CIDRTags {
+		if _, err := netip.ParsePrefix(cidrTags.CIDR); err != nil {
+			return fmt.Errorf("invalid cidr `%s` in enrichment cidr_tags: %s", cidrTags.CIDR, err)
+		}
+	}
+
+	for _, key := range mainConfig.AggregatorKeys {
+		if !slices.Contains(common.GetAllAggregationKeys(), key) {
+			return fmt.Errorf("the provided aggregator key `%s` is not valid (valid keys: %v)", key, common.GetAllAggregationKeys())
+		}
+	}
+	if mainConfig.AggregatorIPv4PrefixLength < 0 || mainConfig.AggregatorIPv4PrefixLength > 32 {
+		return fmt.Errorf("invalid aggregator_ipv4_prefix_length `%d`, must be between 0 and 32", mainConfig.AggregatorIPv4PrefixLength)
+	}
+	if mainConfig.AggregatorIPv6PrefixLength < 0 || mainConfig.AggregatorIPv6PrefixLength > 128 {
+		return fmt.Errorf("invalid aggregator_ipv6_prefix_length `%d`, must be between 0 and 128", mainConfig.AggregatorIPv6PrefixLength)
+	}
+	if mainConfig.AggregatorTopTalkers > 0 && mainConfig.AggregatorTopTalkersCapacity < mainConfig.AggregatorTopTalkers {
+		if mainConfig.AggregatorTopTalkersCapacity != 0 {
+			logger.Warnf("aggregator_top_talkers_capacity (%d) is lower than aggregator_top_talkers (%d), using default capacity", mainConfig.AggregatorTopTalkersCapacity, mainConfig.AggregatorTopTalkers)
+		}
+		mainConfig.AggregatorTopTalkersCapacity = mainConfig.AggregatorTopTalkers * common.DefaultAggregatorTopTalkersCapacityFactor
+	}
+
 	if mainConfig.StopTimeout == 0 {
 		mainConfig.StopTimeout = common.DefaultStopTimeout
 	}
@@ -170,6 +170,74 @@ network_devices:
 `,
 			expectedError: "invalid namespace `abcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefgabcdefg` error: namespace is too long, should contain less than 100 characters",
 		},
+		{
+			name: "invalid enrichment cidr",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    listeners:
+      - flow_type: netflow9
+    enrichment:
+      cidr_tags:
+        - cidr: 10.0.0.0/33
+          tags:
+            - site:paris
+`,
+			expectedError: "invalid cidr `10.0.0.0/33` in enrichment cidr_tags",
+		},
+		{
+			name: "invalid aggregator key",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    aggregator_keys:
+      - source.ip
+      - source.mac
+`,
+			expectedError: "the provided aggregator key `source.mac` is not valid",
+		},
+		{
+			name: "invalid aggregator ipv4 prefix length",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    aggregator_ipv4_prefix_length: 33
+`,
+			expectedError: "invalid aggregator_ipv4_prefix_length `33`, must be between 0 and 32",
+		},
+		{
+			name: "aggregation keys and top talkers",
+			configYaml: `
+network_devices:
+  netflow:
+    enabled: true
+    aggregator_keys:
+      - source.ip
+      - destination.ip
+      - ip_protocol
+    aggregator_ipv4_prefix_length: 24
+    aggregator_ipv6_prefix_length: 64
+    aggregator_top_talkers: 5
+`,
+			expectedConfig: NetflowConfig{
+				Enabled:                                true,
+				StopTimeout:                            5,
+				AggregatorBufferSize:                   10000,
+				AggregatorFlushInterval:                300,
+				AggregatorFlowContextTTL:               300,
+				AggregatorPortRollupThreshold:          10,
+				AggregatorRollupTrackerRefreshInterval: 300,
+				AggregatorKeys:                         []string{"source.ip", "destination.ip", "ip_protocol"},
+				AggregatorIPv4PrefixLength:             24,
+				AggregatorIPv6PrefixLength:             64,
+				AggregatorTopTalkers:                   5,
+				AggregatorTopTalkersCapacity:           50,
+				PrometheusListenerAddress:              "localhost:9090",
+			},
+		},
 		{
 			name: "invalid default field mapping type",
 			configYaml: `
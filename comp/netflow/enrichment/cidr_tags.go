@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/config"
+)
+
+type cidrTags struct {
+	prefix netip.Prefix
+	tags   []string
+}
+
+// CIDRTagsEnricher adds user-defined tags, e.g. `site:paris`, to the flow
+// endpoints belonging to the configured networks.
+type CIDRTagsEnricher struct {
+	// networks is sorted from the least to the most specific network.
+	networks []cidrTags
+}
+
+// NewCIDRTagsEnricher returns a CIDRTagsEnricher for the given networks.
+func NewCIDRTagsEnricher(configs []config.CIDRTagsConfig) (*CIDRTagsEnricher, error) {
+	enricher := &CIDRTagsEnricher{}
+	for _, conf := range configs {
+		prefix, err := netip.ParsePrefix(conf.CIDR)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr `%s`: %w", conf.CIDR, err)
+		}
+		enricher.networks = append(enricher.networks, cidrTags{prefix: prefix.Masked(), tags: conf.Tags})
+	}
+	sort.SliceStable(enricher.networks, func(i, j int) bool {
+		return enricher.networks[i].prefix.Bits() < enricher.networks[j].prefix.Bits()
+	})
+	return enricher, nil
+}
+
+// Enrich sets the tags of the flow endpoints.
+func (e *CIDRTagsEnricher) Enrich(flow *common.Flow) {
+	flow.SrcTags = e.tags(flow.SrcAddr)
+	flow.DstTags = e.tags(flow.DstAddr)
+}
+
+// tags returns the tags of all the networks containing the address, from the
+// least to the most specific network.
+func (e *CIDRTagsEnricher) tags(addr []byte) []string {
+	ip, ok := netip.AddrFromSlice(addr)
+	if !ok {
+		return nil
+	}
+	ip = ip.Unmap()
+	var tags []string
+	seen := make(map[string]struct{})
+	for _, network := range e.networks {
+		if !network.prefix.Contains(ip) {
+			continue
+		}
+		for _, tag := range network.tags {
+			if _, ok := seen[tag]; !ok {
+				seen[tag] = struct{}{}
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/config"
+)
+
+func TestCIDRTagsEnricher_Enrich(t *testing.T) {
+	enricher, err := NewCIDRTagsEnricher([]config.CIDRTagsConfig{
+		{CIDR: "10.1.0.0/16", Tags: []string{"site:paris", "env:prod"}},
+		{CIDR: "10.0.0.0/8", Tags: []string{"network:internal", "env:prod"}},
+		{CIDR: "2001:db8::/32", Tags: []string{"network:ipv6"}},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		addr     []byte
+		expected []string
+	}{
+		{
+			name:     "most specific network tags come last",
+			addr:     []byte{10, 1, 2, 3},
+			expected: []string{"network:internal", "env:prod", "site:paris"},
+		},
+		{
+			name:     "single network",
+			addr:     []byte{10, 2, 2, 3},
+			expected: []string{"network:internal", "env:prod"},
+		},
+		{
+			name:     "ipv6",
+			addr:     []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+			expected: []string{"network:ipv6"},
+		},
+		{
+			name:     "ipv4-mapped ipv6",
+			addr:     []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff, 10, 2, 2, 3},
+			expected: []string{"network:internal", "env:prod"},
+		},
+		{
+			name: "no network",
+			addr: []byte{192, 168, 1, 1},
+		},
+		{
+			name: "invalid address",
+			addr: []byte{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := &common.Flow{SrcAddr: tt.addr, DstAddr: tt.addr}
+			enricher.Enrich(flow)
+			assert.Equal(t, tt.expected, flow.SrcTags)
+			assert.Equal(t, tt.expected, flow.DstTags)
+		})
+	}
+}
+
+func TestNewCIDRTagsEnricher_invalidCIDR(t *testing.T) {
+	_, err := NewCIDRTagsEnricher([]config.CIDRTagsConfig{{CIDR: "10.0.0.0"}})
+	assert.ErrorContains(t, err, "invalid cidr `10.0.0.0`")
+}
@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package enrichment adds information to flows before they are sent, such as
+// the country and autonomous system of their endpoints.
+package enrichment
+
+import (
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/config"
+	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+// Enricher adds information to flows.
+type Enricher interface {
+	Enrich(flow *common.Flow)
+}
+
+// Chain is an Enricher applying several enrichers in order.
+type Chain []Enricher
+
+// Enrich applies the enrichers of the chain to the flow.
+func (c Chain) Enrich(flow *common.Flow) {
+	for _, enricher := range c {
+		enricher.Enrich(flow)
+	}
+}
+
+// Close releases the resources held by the enrichers of the chain.
+func (c Chain) Close() {
+	for _, enricher := range c {
+		Close(enricher)
+	}
+}
+
+// Close releases the resources held by the enricher, e.g. its databases. It
+// does nothing for enrichers not holding any, or if the enricher is nil.
+func Close(enricher Enricher) {
+	if closer, ok := enricher.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
+// NewEnricher returns the enricher for the configuration, or nil if no
+// enrichment is configured. Interface names are looked up in the given devices.
+// Enrichers that can't be loaded, e.g. because of a missing database, are
+// logged and skipped.
+func NewEnricher(conf config.EnrichmentConfig, devices *devicemetadata.DeviceRegistry, logger log.Component) Enricher {
+	var chain Chain
+
+	if conf.GeoIPDatabasePath != "" || conf.ASNDatabasePath != "" {
+		geoIP, err := NewGeoIPEnricher(conf.GeoIPDatabasePath, conf.ASNDatabasePath)
+		if err != nil {
+			logger.Errorf("Unable to load GeoIP enrichment: %s", err)
+		} else {
+			chain = append(chain, geoIP)
+		}
+	}
+
+	if len(conf.CIDRTags) > 0 {
+		cidrTags, err := NewCIDRTagsEnricher(conf.CIDRTags)
+		if err != nil {
+			logger.Errorf("Unable to load CIDR tags enrichment: %s", err)
+		} else {
+			chain = append(chain, cidrTags)
+		}
+	}
+
+	if conf.InterfaceNamesEnabled && devices != nil {
+		chain = append(chain, NewInterfaceNamesEnricher(devices))
+	}
+
+	if len(chain) == 0 {
+		return nil
+	}
+	return chain
+}
@@ -0,0 +1,50 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/config"
+	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+type closingEnricher struct {
+	closed bool
+}
+
+func (e *closingEnricher) Enrich(_ *common.Flow) {}
+
+func (e *closingEnricher) Close() {
+	e.closed = true
+}
+
+func TestClose(t *testing.T) {
+	closing := &closingEnricher{}
+	Close(Chain{NewInterfaceNamesEnricher(devicemetadata.NewDeviceRegistry(devicemetadata.DeviceRegistryTTL)), closing})
+	assert.True(t, closing.closed)
+
+	// enrichers without resources, and nil enrichers, are ignored
+	Close(NewInterfaceNamesEnricher(devicemetadata.NewDeviceRegistry(devicemetadata.DeviceRegistryTTL)))
+	Close(nil)
+}
+
+func TestNewEnricher_interfaceNames(t *testing.T) {
+	logger := logmock.New(t)
+	conf := config.EnrichmentConfig{InterfaceNamesEnabled: true}
+	devices := devicemetadata.NewDeviceRegistry(devicemetadata.DeviceRegistryTTL)
+
+	enricher := NewEnricher(conf, devices, logger)
+	if assert.IsType(t, Chain{}, enricher) {
+		assert.Equal(t, &InterfaceNamesEnricher{devices: devices}, enricher.(Chain)[0])
+	}
+	// interface names need the registry of monitored devices
+	assert.Nil(t, NewEnricher(conf, nil, logger))
+}
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+// GeoIPEnricher adds the country and the autonomous system of flow endpoints,
+// from MaxMind DB files such as GeoLite2-Country and GeoLite2-ASN.
+type GeoIPEnricher struct {
+	countryDB *maxminddb.Reader
+	asnDB     *maxminddb.Reader
+}
+
+// countryRecord holds the fields of GeoIP2/GeoLite2 country records used by the enricher.
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// asnRecord holds the fields of GeoIP2/GeoLite2 ASN records.
+type asnRecord struct {
+	AutonomousSystemNumber       uint32 `maxminddb:"autonomous_system_number"`
+	AutonomousSystemOrganization string `maxminddb:"autonomous_system_organization"`
+}
+
+// NewGeoIPEnricher opens the given country and ASN databases, which are
+// memory-mapped rather than loaded. Either path can be empty.
+func NewGeoIPEnricher(countryDatabasePath string, asnDatabasePath string) (*GeoIPEnricher, error) {
+	enricher := &GeoIPEnricher{}
+	var err error
+	if countryDatabasePath != "" {
+		if enricher.countryDB, err = maxminddb.Open(countryDatabasePath); err != nil {
+			return nil, err
+		}
+	}
+	if asnDatabasePath != "" {
+		if enricher.asnDB, err = maxminddb.Open(asnDatabasePath); err != nil {
+			enricher.Close()
+			return nil, err
+		}
+	}
+	return enricher, nil
+}
+
+// Close unmaps the databases.
+func (e *GeoIPEnricher) Close() {
+	for _, db := range []*maxminddb.Reader{e.countryDB, e.asnDB} {
+		if db != nil {
+			_ = db.Close()
+		}
+	}
+}
+
+// Enrich sets the country and autonomous system of the flow endpoints.
+func (e *GeoIPEnricher) Enrich(flow *common.Flow) {
+	flow.SrcCountry = e.country(flow.SrcAddr)
+	flow.DstCountry = e.country(flow.DstAddr)
+	flow.SrcASN, flow.SrcASOrganization = e.autonomousSystem(flow.SrcAddr)
+	flow.DstASN, flow.DstASOrganization = e.autonomousSystem(flow.DstAddr)
+}
+
+// country returns the ISO code of the country of the address, falling back to
+// the country the network is registered in.
+func (e *GeoIPEnricher) country(addr []byte) string {
+	var record countryRecord
+	if !lookupRecord(e.countryDB, addr, &record) {
+		return ""
+	}
+	if record.Country.ISOCode != "" {
+		return record.Country.ISOCode
+	}
+	return record.RegisteredCountry.ISOCode
+}
+
+func (e *GeoIPEnricher) autonomousSystem(addr []byte) (uint32, string) {
+	var record asnRecord
+	if !lookupRecord(e.asnDB, addr, &record) {
+		return 0, ""
+	}
+	return record.AutonomousSystemNumber, record.AutonomousSystemOrganization
+}
+
+// lookupRecord decodes the record of the network containing the address into
+// result, and returns false if there is none.
+func lookupRecord(db *maxminddb.Reader, addr []byte, result any) bool {
+	if db == nil || (len(addr) != net.IPv4len && len(addr) != net.IPv6len) {
+		return false
+	}
+	_, ok, err := db.LookupNetwork(net.IP(addr), result)
+	return err == nil && ok
+}
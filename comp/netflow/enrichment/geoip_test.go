@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+func TestGeoIPEnricher_Enrich(t *testing.T) {
+	countryPath := writeTestMaxMindDB(t, map[string]map[string]any{
+		"8.8.8.0/24": {
+			"country": map[string]any{"iso_code": "US"},
+		},
+		"1.1.1.0/24": {
+			"registered_country": map[string]any{"iso_code": "AU"},
+		},
+	})
+	asnPath := writeTestMaxMindDB(t, map[string]map[string]any{
+		"8.8.8.0/24": {
+			"autonomous_system_number":       uint32(15169),
+			"autonomous_system_organization": "GOOGLE",
+		},
+	})
+
+	enricher, err := NewGeoIPEnricher(countryPath, asnPath)
+	require.NoError(t, err)
+
+	flow := &common.Flow{
+		SrcAddr: []byte{10, 0, 0, 1},
+		DstAddr: []byte{8, 8, 8, 8},
+	}
+	enricher.Enrich(flow)
+	assert.Equal(t, "", flow.SrcCountry)
+	assert.Equal(t, uint32(0), flow.SrcASN)
+	assert.Equal(t, "", flow.SrcASOrganization)
+	assert.Equal(t, "US", flow.DstCountry)
+	assert.Equal(t, uint32(15169), flow.DstASN)
+	assert.Equal(t, "GOOGLE", flow.DstASOrganization)
+
+	flow = &common.Flow{
+		SrcAddr: []byte{1, 1, 1, 1},
+		DstAddr: []byte{10, 0, 0, 1},
+	}
+	enricher.Enrich(flow)
+	assert.Equal(t, "AU", flow.SrcCountry)
+	assert.Equal(t, uint32(0), flow.SrcASN)
+}
+
+func TestGeoIPEnricher_countryOnly(t *testing.T) {
+	countryPath := writeTestMaxMindDB(t, map[string]map[string]any{
+		"8.8.8.0/24": {
+			"country": map[string]any{"iso_code": "US"},
+		},
+	})
+	enricher, err := NewGeoIPEnricher(countryPath, "")
+	require.NoError(t, err)
+
+	flow := &common.Flow{
+		SrcAddr: []byte{8, 8, 8, 8},
+		DstAddr: []byte{},
+	}
+	enricher.Enrich(flow)
+	assert.Equal(t, "US", flow.SrcCountry)
+	assert.Equal(t, uint32(0), flow.SrcASN)
+	assert.Equal(t, "", flow.DstCountry)
+}
+
+func TestNewGeoIPEnricher_missingDatabase(t *testing.T) {
+	_, err := NewGeoIPEnricher("", "/does/not/exist.mmdb")
+	assert.Error(t, err)
+}
+
+func TestNewGeoIPEnricher_invalidDatabase(t *testing.T) {
+	countryPath := writeTestMaxMindDB(t, map[string]map[string]any{
+		"8.8.8.0/24": {"country": map[string]any{"iso_code": "US"}},
+	})
+	invalidPath := filepath.Join(t.TempDir(), "invalid.mmdb")
+	require.NoError(t, os.WriteFile(invalidPath, []byte("not a database"), 0o600))
+
+	_, err := NewGeoIPEnricher(countryPath, invalidPath)
+	assert.Error(t, err)
+}
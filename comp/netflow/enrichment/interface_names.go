@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/format"
+	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+// InterfaceNamesEnricher adds the names of the input and output interfaces of
+// flows, when the exporter is also monitored by the SNMP integration.
+type InterfaceNamesEnricher struct {
+	devices *devicemetadata.DeviceRegistry
+}
+
+// NewInterfaceNamesEnricher returns an InterfaceNamesEnricher using the interfaces of the given devices.
+func NewInterfaceNamesEnricher(devices *devicemetadata.DeviceRegistry) *InterfaceNamesEnricher {
+	return &InterfaceNamesEnricher{devices: devices}
+}
+
+// Enrich sets the names of the flow interfaces.
+func (e *InterfaceNamesEnricher) Enrich(flow *common.Flow) {
+	exporterIP := format.IPAddr(flow.ExporterAddr)
+	now := time.Now()
+	if itf, ok := e.devices.GetInterface(flow.Namespace, exporterIP, flow.InputInterface, now); ok {
+		flow.InputInterfaceName = itf.Name
+	}
+	if itf, ok := e.devices.GetInterface(flow.Namespace, exporterIP, flow.OutputInterface, now); ok {
+		flow.OutputInterfaceName = itf.Name
+	}
+}
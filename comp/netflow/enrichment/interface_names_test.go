@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+)
+
+func TestInterfaceNamesEnricher_Enrich(t *testing.T) {
+	devices := devicemetadata.NewDeviceRegistry(devicemetadata.DeviceRegistryTTL)
+	devices.Update(devicemetadata.MonitoredDevice{
+		ID:        "default:127.0.0.1",
+		Namespace: "default",
+		IPAddress: "127.0.0.1",
+		Interfaces: []devicemetadata.InterfaceMetadata{
+			{Index: 1, Name: "eth0"},
+			{Index: 2, Name: "eth1"},
+		},
+		LastUpdate: time.Now(),
+	})
+	enricher := NewInterfaceNamesEnricher(devices)
+
+	flow := &common.Flow{
+		Namespace:       "default",
+		ExporterAddr:    []byte{127, 0, 0, 1},
+		InputInterface:  1,
+		OutputInterface: 2,
+	}
+	enricher.Enrich(flow)
+	assert.Equal(t, "eth0", flow.InputInterfaceName)
+	assert.Equal(t, "eth1", flow.OutputInterfaceName)
+
+	// unknown interface
+	flow = &common.Flow{
+		Namespace:       "default",
+		ExporterAddr:    []byte{127, 0, 0, 1},
+		InputInterface:  1,
+		OutputInterface: 3,
+	}
+	enricher.Enrich(flow)
+	assert.Equal(t, "eth0", flow.InputInterfaceName)
+	assert.Equal(t, "", flow.OutputInterfaceName)
+
+	// other namespace
+	flow = &common.Flow{
+		Namespace:       "other",
+		ExporterAddr:    []byte{127, 0, 0, 1},
+		InputInterface:  1,
+		OutputInterface: 2,
+	}
+	enricher.Enrich(flow)
+	assert.Equal(t, "", flow.InputInterfaceName)
+	assert.Equal(t, "", flow.OutputInterfaceName)
+}
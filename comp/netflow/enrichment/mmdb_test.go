@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package enrichment
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// mmdbMetadataMarker precedes the metadata section at the end of MaxMind DB files.
+var mmdbMetadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// mmdbDataSectionSeparatorSize is the size of the zeroes between the search tree and the data section.
+const mmdbDataSectionSeparatorSize = 16
+
+// MaxMind DB data field types used by the tests
+const (
+	mmdbTypeString = 2
+	mmdbTypeUint32 = 6
+	mmdbTypeMap    = 7
+	mmdbTypeArray  = 11
+)
+
+// encodeMMDBValue encodes a value of a MaxMind DB data section. Only the types
+// used by the tests are supported.
+func encodeMMDBValue(value any) []byte {
+	ctrl := func(fieldType byte, size int) []byte {
+		var sizeBytes []byte
+		switch {
+		case size < 29:
+		case size < 29+256:
+			sizeBytes = []byte{byte(size - 29)}
+			size = 29
+		default:
+			panic("size not supported")
+		}
+		buf := []byte{fieldType<<5 | byte(size)}
+		if fieldType > 7 {
+			buf = []byte{byte(size), fieldType - 7}
+		}
+		return append(buf, sizeBytes...)
+	}
+	switch v := value.(type) {
+	case string:
+		return append(ctrl(mmdbTypeString, len(v)), v...)
+	case uint32:
+		data := binary.BigEndian.AppendUint32(nil, v)
+		return append(ctrl(mmdbTypeUint32, len(data)), data...)
+	case []any:
+		buf := ctrl(mmdbTypeArray, len(v))
+		for _, item := range v {
+			buf = append(buf, encodeMMDBValue(item)...)
+		}
+		return buf
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf := ctrl(mmdbTypeMap, len(v))
+		for _, key := range keys {
+			buf = append(buf, encodeMMDBValue(key)...)
+			buf = append(buf, encodeMMDBValue(v[key])...)
+		}
+		return buf
+	}
+	panic("type not supported")
+}
+
+// buildTestMaxMindDB returns an IPv4 MaxMind DB, with 24 bits records, mapping
+// the networks to their records.
+func buildTestMaxMindDB(records map[string]map[string]any) []byte {
+	// nodes hold the records of the search tree: -1 for no data, -2-i for the
+	// i-th data record, and the node index otherwise
+	nodes := [][2]int{{-1, -1}}
+	var data [][]byte
+	for network, record := range records {
+		prefix := netip.MustParsePrefix(network)
+		ip := prefix.Addr().As4()
+		data = append(data, encodeMMDBValue(record))
+		node := 0
+		for i := 0; i < prefix.Bits(); i++ {
+			bit := (ip[i/8] >> (7 - uint(i%8))) & 1
+			if i == prefix.Bits()-1 {
+				nodes[node][bit] = -2 - (len(data) - 1)
+				break
+			}
+			if nodes[node][bit] < 0 {
+				nodes = append(nodes, [2]int{-1, -1})
+				nodes[node][bit] = len(nodes) - 1
+			}
+			node = nodes[node][bit]
+		}
+	}
+
+	var dataSection []byte
+	dataOffsets := make([]int, len(data))
+	for i, value := range data {
+		dataOffsets[i] = len(dataSection)
+		dataSection = append(dataSection, value...)
+	}
+
+	nodeCount := len(nodes)
+	var buf []byte
+	for _, node := range nodes {
+		for _, record := range node {
+			value := record
+			switch {
+			case record == -1:
+				value = nodeCount
+			case record < -1:
+				value = nodeCount + mmdbDataSectionSeparatorSize + dataOffsets[-2-record]
+			}
+			buf = append(buf, byte(value>>16), byte(value>>8), byte(value))
+		}
+	}
+	buf = append(buf, make([]byte, mmdbDataSectionSeparatorSize)...)
+	buf = append(buf, dataSection...)
+	buf = append(buf, mmdbMetadataMarker...)
+	buf = append(buf, encodeMMDBValue(map[string]any{
+		"node_count":                  uint32(nodeCount),
+		"record_size":                 uint32(24),
+		"ip_version":                  uint32(4),
+		"database_type":               "Test-DB",
+		"binary_format_major_version": uint32(2),
+	})...)
+	return buf
+}
+
+func writeTestMaxMindDB(t *testing.T, records map[string]map[string]any) string {
+	path := filepath.Join(t.TempDir(), "test.mmdb")
+	require.NoError(t, os.WriteFile(path, buildTestMaxMindDB(records), 0o600))
+	return path
+}
@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"net/netip"
+	"slices"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/portrollup"
+)
+
+// aggregationKey reduces the fields of flows used for aggregation, to lower the
+// number of aggregated flows of high cardinality exporters.
+// Fields excluded from the aggregation key are reset, and endpoint addresses
+// can be replaced by their network.
+type aggregationKey struct {
+	srcAddr        bool
+	srcPort        bool
+	dstAddr        bool
+	dstPort        bool
+	ipProtocol     bool
+	tos            bool
+	inputInterface bool
+	ipv4PrefixBits int
+	ipv6PrefixBits int
+}
+
+// newAggregationKey returns the aggregation key using the given fields, or nil
+// if flows are aggregated using all fields.
+func newAggregationKey(keys []string, ipv4PrefixLength int, ipv6PrefixLength int) *aggregationKey {
+	if len(keys) == 0 {
+		keys = common.GetAllAggregationKeys()
+	}
+	k := &aggregationKey{
+		srcAddr:        slices.Contains(keys, common.AggregationKeySourceIP),
+		srcPort:        slices.Contains(keys, common.AggregationKeySourcePort),
+		dstAddr:        slices.Contains(keys, common.AggregationKeyDestinationIP),
+		dstPort:        slices.Contains(keys, common.AggregationKeyDestinationPort),
+		ipProtocol:     slices.Contains(keys, common.AggregationKeyIPProtocol),
+		tos:            slices.Contains(keys, common.AggregationKeyTos),
+		inputInterface: slices.Contains(keys, common.AggregationKeyIngressInterface),
+		ipv4PrefixBits: ipv4PrefixLength,
+		ipv6PrefixBits: ipv6PrefixLength,
+	}
+	if k.srcAddr && k.srcPort && k.dstAddr && k.dstPort && k.ipProtocol && k.tos && k.inputInterface &&
+		k.ipv4PrefixBits == 0 && k.ipv6PrefixBits == 0 {
+		return nil
+	}
+	return k
+}
+
+// reduce resets the fields of the flow that are not part of the aggregation key.
+func (k *aggregationKey) reduce(flow *common.Flow) {
+	if k.srcAddr {
+		flow.SrcAddr = k.network(flow.SrcAddr)
+	} else {
+		flow.SrcAddr = nil
+	}
+	if k.dstAddr {
+		flow.DstAddr = k.network(flow.DstAddr)
+	} else {
+		flow.DstAddr = nil
+	}
+	if !k.srcPort {
+		flow.SrcPort = portrollup.EphemeralPort
+	}
+	if !k.dstPort {
+		flow.DstPort = portrollup.EphemeralPort
+	}
+	if !k.ipProtocol {
+		flow.IPProtocol = 0
+	}
+	if !k.tos {
+		flow.Tos = 0
+	}
+	if !k.inputInterface {
+		flow.InputInterface = 0
+	}
+}
+
+// network returns the address of the network of the configured prefix length
+// containing addr, or addr if no prefix length is configured.
+func (k *aggregationKey) network(addr []byte) []byte {
+	bits := k.prefixBits(addr)
+	if bits == 0 {
+		return addr
+	}
+	ip, _ := netip.AddrFromSlice(addr)
+	prefix, err := ip.Prefix(bits)
+	if err != nil {
+		return addr
+	}
+	return prefix.Addr().AsSlice()
+}
+
+// prefixBits returns the prefix length addr is replaced by its network with, or
+// 0 if it is kept as is.
+func (k *aggregationKey) prefixBits(addr []byte) int {
+	ip, ok := netip.AddrFromSlice(addr)
+	if !ok {
+		return 0
+	}
+	if ip.Is4() {
+		return k.ipv4PrefixBits
+	}
+	return k.ipv6PrefixBits
+}
@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+func Test_newAggregationKey_default(t *testing.T) {
+	assert.Nil(t, newAggregationKey(nil, 0, 0))
+	assert.Nil(t, newAggregationKey(common.GetAllAggregationKeys(), 0, 0))
+	assert.NotNil(t, newAggregationKey(nil, 24, 0))
+}
+
+func Test_aggregationKey_reduce(t *testing.T) {
+	newFlow := func() *common.Flow {
+		return &common.Flow{
+			Namespace:       "default",
+			ExporterAddr:    []byte{127, 0, 0, 1},
+			SrcAddr:         []byte{10, 1, 2, 3},
+			DstAddr:         []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1},
+			SrcPort:         2000,
+			DstPort:         80,
+			IPProtocol:      6,
+			Tos:             1,
+			InputInterface:  10,
+			OutputInterface: 20,
+		}
+	}
+
+	tests := []struct {
+		name             string
+		keys             []string
+		ipv4PrefixLength int
+		ipv6PrefixLength int
+		expectedFlow     func(flow *common.Flow)
+	}{
+		{
+			name: "drop source port",
+			keys: []string{"source.ip", "destination.ip", "destination.port", "ip_protocol", "tos", "ingress.interface"},
+			expectedFlow: func(flow *common.Flow) {
+				flow.SrcPort = -1
+			},
+		},
+		{
+			name:             "aggregate endpoints by network",
+			ipv4PrefixLength: 24,
+			ipv6PrefixLength: 32,
+			expectedFlow: func(flow *common.Flow) {
+				flow.SrcAddr = []byte{10, 1, 2, 0}
+				flow.DstAddr = []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+			},
+		},
+		{
+			name: "exporter, interface and protocol only",
+			keys: []string{"ingress.interface", "ip_protocol"},
+			expectedFlow: func(flow *common.Flow) {
+				flow.SrcAddr = nil
+				flow.DstAddr = nil
+				flow.SrcPort = -1
+				flow.DstPort = -1
+				flow.Tos = 0
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := newFlow()
+			newAggregationKey(tt.keys, tt.ipv4PrefixLength, tt.ipv6PrefixLength).reduce(flow)
+			expectedFlow := newFlow()
+			tt.expectedFlow(expectedFlow)
+			assert.Equal(t, expectedFlow, flow)
+		})
+	}
+}
@@ -29,6 +29,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/comp/netflow/common"
 	"github.com/DataDog/datadog-agent/comp/netflow/config"
+	"github.com/DataDog/datadog-agent/comp/netflow/enrichment"
 	"github.com/DataDog/datadog-agent/comp/netflow/goflowlib"
 )
 
@@ -38,9 +39,12 @@ const metricPrefix = "datadog.netflow."
 // FlowAggregator is used for space and time aggregation of NetFlow flows
 type FlowAggregator struct {
 	flowIn                       chan *common.Flow
+	counterIn                    chan *common.SFlowCounterSample
 	FlushFlowsToSendInterval     time.Duration // interval for checking flows to flush and send them to EP Forwarder
 	rollupTrackerRefreshInterval time.Duration
 	flowAcc                      *flowAccumulator
+	topTalkers                   *topTalkers // nil when top talkers are disabled
+	sflowCounters                *sflowCounters
 	sender                       sender.Sender
 	epForwarder                  eventplatform.Forwarder
 	stopChan                     chan struct{}
@@ -78,14 +82,23 @@ var maxNegativeSequenceDiffToReset = map[common.FlowType]int{
 	common.TypeIPFIX:    -100,
 }
 
-// NewFlowAggregator returns a new FlowAggregator
-func NewFlowAggregator(sender sender.Sender, epForwarder eventplatform.Forwarder, config *config.NetflowConfig, hostname string, logger log.Component, rdnsQuerier rdnsquerier.Component) *FlowAggregator {
+// NewFlowAggregator returns a new FlowAggregator, enriching flows with the names
+// of the interfaces of the given devices.
+func NewFlowAggregator(sender sender.Sender, epForwarder eventplatform.Forwarder, config *config.NetflowConfig, hostname string, logger log.Component, rdnsQuerier rdnsquerier.Component, devices *metadata.DeviceRegistry) *FlowAggregator {
 	flushInterval := time.Duration(config.AggregatorFlushInterval) * time.Second
 	flowContextTTL := time.Duration(config.AggregatorFlowContextTTL) * time.Second
 	rollupTrackerRefreshInterval := time.Duration(config.AggregatorRollupTrackerRefreshInterval) * time.Second
+	aggregationKey := newAggregationKey(config.AggregatorKeys, config.AggregatorIPv4PrefixLength, config.AggregatorIPv6PrefixLength)
+	var talkers *topTalkers
+	if config.AggregatorTopTalkers > 0 {
+		talkers = newTopTalkers(config.AggregatorTopTalkers, config.AggregatorTopTalkersCapacity)
+	}
 	return &FlowAggregator{
 		flowIn:                       make(chan *common.Flow, config.AggregatorBufferSize),
-		flowAcc:                      newFlowAccumulator(flushInterval, flowContextTTL, config.AggregatorPortRollupThreshold, config.AggregatorPortRollupDisabled, logger, rdnsQuerier),
+		counterIn:                    make(chan *common.SFlowCounterSample, config.AggregatorBufferSize),
+		flowAcc:                      newFlowAccumulator(flushInterval, flowContextTTL, config.AggregatorPortRollupThreshold, config.AggregatorPortRollupDisabled, logger, rdnsQuerier, enrichment.NewEnricher(config.Enrichment, devices, logger), aggregationKey),
+		topTalkers:                   talkers,
+		sflowCounters:                newSFlowCounters(),
 		FlushFlowsToSendInterval:     flushFlowsToSendInterval,
 		rollupTrackerRefreshInterval: rollupTrackerRefreshInterval,
 		sender:                       sender,
@@ -115,6 +128,7 @@ func (agg *FlowAggregator) Stop() {
 	close(agg.stopChan)
 	<-agg.flushLoopDone
 	<-agg.runDone
+	enrichment.Close(agg.flowAcc.enricher)
 }
 
 // GetFlowInChan returns flow input chan
@@ -122,6 +136,11 @@ func (agg *FlowAggregator) GetFlowInChan() chan *common.Flow {
 	return agg.flowIn
 }
 
+// GetCounterInChan returns sFlow counter samples input chan
+func (agg *FlowAggregator) GetCounterInChan() chan *common.SFlowCounterSample {
+	return agg.counterIn
+}
+
 func (agg *FlowAggregator) run() {
 	for {
 		select {
@@ -131,7 +150,13 @@ func (agg *FlowAggregator) run() {
 			return
 		case flow := <-agg.flowIn:
 			agg.receivedFlowCount.Inc()
+			if agg.topTalkers != nil {
+				// top talkers are tracked before the flow is reduced to its aggregation key
+				agg.topTalkers.add(flow)
+			}
 			agg.flowAcc.add(flow)
+		case counterSample := <-agg.counterIn:
+			agg.sflowCounters.add(counterSample)
 		}
 	}
 }
@@ -269,6 +294,11 @@ func (agg *FlowAggregator) flush() int {
 		}
 	}
 
+	if agg.topTalkers != nil {
+		agg.submitTopTalkers()
+	}
+	agg.submitSFlowCounters()
+
 	// TODO: Add flush stats to agent telemetry e.g. aggregator newFlushCountStats()
 	if len(flowsToFlush) > 0 {
 		agg.sendFlows(flowsToFlush, flushTime)
@@ -341,6 +371,19 @@ func (agg *FlowAggregator) getSequenceDelta(flowsToFlush []*common.Flow) map[seq
 	return sequenceDeltaPerExporter
 }
 
+// submitTopTalkers submits the bytes of the top source and destination talkers per exporter since the last flush
+func (agg *FlowAggregator) submitTopTalkers() {
+	sources, destinations := agg.topTalkers.flush()
+	for _, talker := range sources {
+		tags := []string{"device_namespace:" + talker.namespace, "exporter_ip:" + talker.exporterIP, "source_ip:" + talker.ip}
+		agg.sender.Count(metricPrefix+"top_talkers.source.bytes", float64(talker.bytes), "", tags)
+	}
+	for _, talker := range destinations {
+		tags := []string{"device_namespace:" + talker.namespace, "exporter_ip:" + talker.exporterIP, "destination_ip:" + talker.ip}
+		agg.sender.Count(metricPrefix+"top_talkers.destination.bytes", float64(talker.bytes), "", tags)
+	}
+}
+
 func (agg *FlowAggregator) rollupTrackersRefresh() {
 	agg.logger.Debugf("Rollup tracker refresh: use new store as current store")
 	agg.flowAcc.portRollup.UseNewStoreAsCurrentStore()
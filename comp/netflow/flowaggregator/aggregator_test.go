@@ -170,7 +170,7 @@ func TestAggregator(t *testing.T) {
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
 
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 	aggregator.FlushFlowsToSendInterval = 1 * time.Second
 	aggregator.TimeNowFunction = func() time.Time {
 		return flushTime
@@ -273,7 +273,7 @@ func TestAggregator_withMockPayload(t *testing.T) {
 
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 	aggregator.FlushFlowsToSendInterval = 1 * time.Second
 	aggregator.TimeNowFunction = func() time.Time {
 		return flushTime
@@ -294,7 +294,7 @@ func TestAggregator_withMockPayload(t *testing.T) {
 	listenerErr := atomic.NewString("")
 	listenerFlowCount := atomic.NewInt64(0)
 
-	flowState, err := goflowlib.StartFlowRoutine(common.TypeNetFlow5, "127.0.0.1", port, 1, "default", nil, aggregator.GetFlowInChan(), logger, listenerErr, listenerFlowCount)
+	flowState, err := goflowlib.StartFlowRoutine(common.TypeNetFlow5, "127.0.0.1", port, 1, "default", nil, aggregator.GetFlowInChan(), aggregator.GetCounterInChan(), logger, listenerErr, listenerFlowCount)
 	assert.NoError(t, err)
 
 	time.Sleep(100 * time.Millisecond) // wait to make sure goflow listener is started before sending
@@ -366,7 +366,7 @@ func TestFlowAggregator_flush_submitCollectorMetrics_error(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	epForwarder := eventplatformimpl.NewMockEventPlatformForwarder(ctrl)
 
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 	aggregator.goflowPrometheusGatherer = prometheus.GathererFunc(func() ([]*promClient.MetricFamily, error) {
 		return nil, fmt.Errorf("some prometheus gatherer error")
 	})
@@ -407,7 +407,7 @@ func TestFlowAggregator_submitCollectorMetrics(t *testing.T) {
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
 
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 	aggregator.goflowPrometheusGatherer = prometheus.GathererFunc(func() ([]*promClient.MetricFamily, error) {
 		return []*promClient.MetricFamily{
 			{
@@ -484,7 +484,7 @@ func TestFlowAggregator_submitCollectorMetrics_error(t *testing.T) {
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
 
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 	aggregator.goflowPrometheusGatherer = prometheus.GathererFunc(func() ([]*promClient.MetricFamily, error) {
 		return nil, fmt.Errorf("some prometheus gatherer error")
 	})
@@ -519,7 +519,7 @@ func TestFlowAggregator_sendExporterMetadata_multiplePayloads(t *testing.T) {
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
 
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 
 	var flows []*common.Flow
 	for i := 1; i <= 250; i++ {
@@ -605,7 +605,7 @@ func TestFlowAggregator_sendExporterMetadata_noPayloads(t *testing.T) {
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
 
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 
 	var flows []*common.Flow
 	now := time.Unix(1681295467, 0)
@@ -638,7 +638,7 @@ func TestFlowAggregator_sendExporterMetadata_invalidIPIgnored(t *testing.T) {
 
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 
 	now := time.Unix(1681295467, 0)
 	flows := []*common.Flow{
@@ -724,7 +724,7 @@ func TestFlowAggregator_sendExporterMetadata_multipleNamespaces(t *testing.T) {
 
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 
 	now := time.Unix(1681295467, 0)
 	flows := []*common.Flow{
@@ -830,7 +830,7 @@ func TestFlowAggregator_sendExporterMetadata_singleExporterIpWithMultipleFlowTyp
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
 
-	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
 
 	now := time.Unix(1681295467, 0)
 	flows := []*common.Flow{
@@ -1183,10 +1183,97 @@ func TestFlowAggregator_getSequenceDelta(t *testing.T) {
 				AggregatorPortRollupThreshold:          10,
 				AggregatorRollupTrackerRefreshInterval: 3600,
 			}
-			agg := NewFlowAggregator(sender, nil, &conf, "my-hostname", logger, rdnsQuerier)
+			agg := NewFlowAggregator(sender, nil, &conf, "my-hostname", logger, rdnsQuerier, nil)
 			for roundNum, testRound := range tt.rounds {
 				assert.Equal(t, testRound.expectedSequenceDelta, agg.getSequenceDelta(testRound.flowsToFlush), fmt.Sprintf("Test Round %d", roundNum))
 			}
 		})
 	}
 }
+
+func TestFlowAggregator_submitTopTalkers(t *testing.T) {
+	logger := logmock.New(t)
+	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
+	sender := mocksender.NewMockSender("")
+	sender.On("Count", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	conf := config.NetflowConfig{
+		AggregatorBufferSize:                   20,
+		AggregatorFlushInterval:                1,
+		AggregatorPortRollupThreshold:          10,
+		AggregatorRollupTrackerRefreshInterval: 3600,
+		AggregatorTopTalkers:                   1,
+		AggregatorTopTalkersCapacity:           10,
+	}
+	ctrl := gomock.NewController(t)
+	epForwarder := eventplatformimpl.NewMockEventPlatformForwarder(ctrl)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
+
+	for _, flow := range []*common.Flow{
+		{Namespace: "default", ExporterAddr: []byte{127, 0, 0, 1}, SrcAddr: []byte{10, 0, 0, 1}, DstAddr: []byte{10, 0, 0, 2}, Bytes: 100, SamplingRate: 10},
+		{Namespace: "default", ExporterAddr: []byte{127, 0, 0, 1}, SrcAddr: []byte{10, 0, 0, 3}, DstAddr: []byte{10, 0, 0, 2}, Bytes: 50},
+		{Namespace: "default", ExporterAddr: []byte{127, 0, 0, 1}, SrcAddr: []byte{10, 0, 0, 3}, DstAddr: []byte{10, 0, 0, 4}, Bytes: 20},
+	} {
+		aggregator.topTalkers.add(flow)
+	}
+	aggregator.submitTopTalkers()
+
+	sender.AssertNumberOfCalls(t, "Count", 2)
+	sender.AssertMetric(t, "Count", "datadog.netflow.top_talkers.source.bytes", 1000, "", []string{"device_namespace:default", "exporter_ip:127.0.0.1", "source_ip:10.0.0.1"})
+	sender.AssertMetric(t, "Count", "datadog.netflow.top_talkers.destination.bytes", 1050, "", []string{"device_namespace:default", "exporter_ip:127.0.0.1", "destination_ip:10.0.0.2"})
+
+	// talkers are reset after each flush
+	aggregator.submitTopTalkers()
+	sender.AssertNumberOfCalls(t, "Count", 2)
+}
+
+func TestFlowAggregator_submitSFlowCounters(t *testing.T) {
+	logger := logmock.New(t)
+	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
+	sender := mocksender.NewMockSender("")
+	sender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("MonotonicCount", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	conf := config.NetflowConfig{
+		AggregatorBufferSize:                   20,
+		AggregatorFlushInterval:                1,
+		AggregatorPortRollupThreshold:          10,
+		AggregatorRollupTrackerRefreshInterval: 3600,
+	}
+	ctrl := gomock.NewController(t)
+	epForwarder := eventplatformimpl.NewMockEventPlatformForwarder(ctrl)
+	aggregator := NewFlowAggregator(sender, epForwarder, &conf, "my-hostname", logger, rdnsQuerier, nil)
+
+	aggregator.sflowCounters.add(&common.SFlowCounterSample{
+		Namespace:    "default",
+		ExporterAddr: []byte{127, 0, 0, 1},
+		Interface:    &common.InterfaceCounters{Index: 2, Speed: 100, Status: 3, InOctets: 500},
+	})
+	// only the latest counters of an interface are kept
+	aggregator.sflowCounters.add(&common.SFlowCounterSample{
+		Namespace:    "default",
+		ExporterAddr: []byte{127, 0, 0, 1},
+		Interface:    &common.InterfaceCounters{Index: 2, Speed: 100, Status: 1, InOctets: 800, OutOctets: 300},
+		HostCPU:      &common.HostCPUCounters{LoadOne: 1.5, CPUNum: 2, CPUUser: 40},
+		HostMemory:   &common.HostMemoryCounters{MemTotal: 4096, MemFree: 1024},
+	})
+	aggregator.submitSFlowCounters()
+
+	interfaceTags := []string{"device_namespace:default", "exporter_ip:127.0.0.1", "interface_index:2"}
+	hostTags := []string{"device_namespace:default", "exporter_ip:127.0.0.1"}
+	sender.AssertMetric(t, "Gauge", "datadog.netflow.sflow.interface.speed", 100, "", interfaceTags)
+	sender.AssertMetric(t, "Gauge", "datadog.netflow.sflow.interface.admin_status", 1, "", interfaceTags)
+	sender.AssertMetric(t, "Gauge", "datadog.netflow.sflow.interface.oper_status", 0, "", interfaceTags)
+	sender.AssertMetric(t, "MonotonicCount", "datadog.netflow.sflow.interface.in_octets", 800, "", interfaceTags)
+	sender.AssertMetric(t, "MonotonicCount", "datadog.netflow.sflow.interface.out_octets", 300, "", interfaceTags)
+	sender.AssertMetric(t, "Gauge", "datadog.netflow.sflow.host.cpu.load_one", 1.5, "", hostTags)
+	sender.AssertMetric(t, "Gauge", "datadog.netflow.sflow.host.cpu.num", 2, "", hostTags)
+	sender.AssertMetric(t, "MonotonicCount", "datadog.netflow.sflow.host.cpu.user", 40, "", hostTags)
+	sender.AssertMetric(t, "Gauge", "datadog.netflow.sflow.host.memory.total", 4096, "", hostTags)
+	sender.AssertMetric(t, "Gauge", "datadog.netflow.sflow.host.memory.free", 1024, "", hostTags)
+	sender.AssertNumberOfCalls(t, "Gauge", 3+7+7)
+	sender.AssertNumberOfCalls(t, "MonotonicCount", 13+7)
+
+	// counters are reset after each flush
+	aggregator.submitSFlowCounters()
+	sender.AssertNumberOfCalls(t, "Gauge", 3+7+7)
+	sender.AssertNumberOfCalls(t, "MonotonicCount", 13+7)
+}
@@ -14,6 +14,15 @@ import (
 )
 
 func buildPayload(aggFlow *common.Flow, hostname string, flushTime time.Time) payload.FlowPayload {
+	var tunnel *payload.Tunnel
+	if aggFlow.TunnelType != "" {
+		tunnel = &payload.Tunnel{
+			Type:          aggFlow.TunnelType,
+			ID:            aggFlow.TunnelID,
+			SourceIP:      format.IPAddr(aggFlow.TunnelSrcAddr),
+			DestinationIP: format.IPAddr(aggFlow.TunnelDstAddr),
+		}
+	}
 	return payload.FlowPayload{
 		// TODO: Implement Tos
 		FlushTimestamp: flushTime.UnixMilli(),
@@ -38,6 +47,10 @@ func buildPayload(aggFlow *common.Flow, hostname string, flushTime time.Time) pa
 			Mac:                format.MacAddress(aggFlow.SrcMac),
 			Mask:               format.CIDR(aggFlow.SrcAddr, aggFlow.SrcMask),
 			ReverseDNSHostname: aggFlow.SrcReverseDNSHostname,
+			Country:            aggFlow.SrcCountry,
+			ASN:                aggFlow.SrcASN,
+			ASOrganization:     aggFlow.SrcASOrganization,
+			Tags:               aggFlow.SrcTags,
 		},
 		Destination: payload.Endpoint{
 			IP:                 format.IPAddr(aggFlow.DstAddr),
@@ -45,15 +58,21 @@ func buildPayload(aggFlow *common.Flow, hostname string, flushTime time.Time) pa
 			Mac:                format.MacAddress(aggFlow.DstMac),
 			Mask:               format.CIDR(aggFlow.DstAddr, aggFlow.DstMask),
 			ReverseDNSHostname: aggFlow.DstReverseDNSHostname,
+			Country:            aggFlow.DstCountry,
+			ASN:                aggFlow.DstASN,
+			ASOrganization:     aggFlow.DstASOrganization,
+			Tags:               aggFlow.DstTags,
 		},
 		Ingress: payload.ObservationPoint{
 			Interface: payload.Interface{
 				Index: aggFlow.InputInterface,
+				Name:  aggFlow.InputInterfaceName,
 			},
 		},
 		Egress: payload.ObservationPoint{
 			Interface: payload.Interface{
 				Index: aggFlow.OutputInterface,
+				Name:  aggFlow.OutputInterfaceName,
 			},
 		},
 		Host:     hostname,
@@ -61,6 +80,9 @@ func buildPayload(aggFlow *common.Flow, hostname string, flushTime time.Time) pa
 		NextHop: payload.NextHop{
 			IP: format.IPAddr(aggFlow.NextHop),
 		},
+		VlanID:           aggFlow.VlanID,
+		MPLSLabels:       aggFlow.MPLSLabels,
+		Tunnel:           tunnel,
 		AdditionalFields: aggFlow.AdditionalFields,
 	}
 }
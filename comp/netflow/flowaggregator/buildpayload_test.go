@@ -54,6 +54,20 @@ func Test_Endpoint_reverseDNS(t *testing.T) {
 			},
 			expected: "{\"ip\":\"192.168.0.1\",\"port\":\"80\",\"mac\":\"00:00:00:00:00:01\",\"mask\":\"128.0.0.0/1\",\"reverse_dns_hostname\":\"test_hostname\"}",
 		},
+		{
+			name: "enrichment",
+			endpoint: payload.Endpoint{
+				IP:             "8.8.8.8",
+				Port:           "53",
+				Mac:            "00:00:00:00:00:01",
+				Mask:           "8.8.8.0/24",
+				Country:        "US",
+				ASN:            15169,
+				ASOrganization: "GOOGLE",
+				Tags:           []string{"site:external"},
+			},
+			expected: "{\"ip\":\"8.8.8.8\",\"port\":\"53\",\"mac\":\"00:00:00:00:00:01\",\"mask\":\"8.8.8.0/24\",\"country\":\"US\",\"asn\":15169,\"as_organization\":\"GOOGLE\",\"tags\":[\"site:external\"]}",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -281,6 +295,76 @@ func Test_buildPayload(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "sflow vlan, mpls and tunnel",
+			flow: common.Flow{
+				Namespace:      "my-namespace",
+				FlowType:       common.TypeSFlow5,
+				SamplingRate:   10,
+				ExporterAddr:   []byte{127, 0, 0, 1},
+				StartTimestamp: 1234568,
+				EndTimestamp:   1234568,
+				Bytes:          10,
+				Packets:        1,
+				SrcAddr:        []byte{10, 10, 10, 10},
+				DstAddr:        []byte{10, 10, 10, 20},
+				EtherType:      uint32(0x0800),
+				IPProtocol:     uint32(17),
+				SrcPort:        2000,
+				DstPort:        53,
+				VlanID:         100,
+				MPLSLabels:     []uint32{16, 17},
+				TunnelType:     "vxlan",
+				TunnelID:       4660,
+				TunnelSrcAddr:  []byte{192, 168, 0, 1},
+				TunnelDstAddr:  []byte{192, 168, 0, 2},
+			},
+			expectedPayload: payload.FlowPayload{
+				FlushTimestamp: curTime.UnixMilli(),
+				FlowType:       "sflow5",
+				SamplingRate:   10,
+				Direction:      "ingress",
+				Start:          1234568,
+				End:            1234568,
+				Bytes:          10,
+				Packets:        1,
+				EtherType:      "IPv4",
+				IPProtocol:     "UDP",
+				Device: payload.Device{
+					Namespace: "my-namespace",
+				},
+				Exporter: payload.Exporter{
+					IP: "127.0.0.1",
+				},
+				Source: payload.Endpoint{
+					IP:   "10.10.10.10",
+					Port: "2000",
+					Mac:  "00:00:00:00:00:00",
+					Mask: "0.0.0.0/0",
+				},
+				Destination: payload.Endpoint{
+					IP:   "10.10.10.20",
+					Port: "53",
+					Mac:  "00:00:00:00:00:00",
+					Mask: "0.0.0.0/0",
+				},
+				Ingress:  payload.ObservationPoint{Interface: payload.Interface{Index: 0}},
+				Egress:   payload.ObservationPoint{Interface: payload.Interface{Index: 0}},
+				Host:     "my-hostname",
+				TCPFlags: []string(nil),
+				NextHop: payload.NextHop{
+					IP: "",
+				},
+				VlanID:     100,
+				MPLSLabels: []uint32{16, 17},
+				Tunnel: &payload.Tunnel{
+					Type:          "vxlan",
+					ID:            4660,
+					SourceIP:      "192.168.0.1",
+					DestinationIP: "192.168.0.2",
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -11,6 +11,7 @@ import (
 
 	log "github.com/DataDog/datadog-agent/comp/core/log/def"
 	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/enrichment"
 	"github.com/DataDog/datadog-agent/comp/netflow/portrollup"
 	rdnsquerier "github.com/DataDog/datadog-agent/comp/rdnsquerier/def"
 	"go.uber.org/atomic"
@@ -43,6 +44,10 @@ type flowAccumulator struct {
 
 	logger      log.Component
 	rdnsQuerier rdnsquerier.Component
+	// enricher is nil when no enrichment is configured
+	enricher enrichment.Enricher
+	// aggregationKey is nil when flows are aggregated using all fields
+	aggregationKey *aggregationKey
 }
 
 func newFlowContext(flow *common.Flow) flowContext {
@@ -53,7 +58,7 @@ func newFlowContext(flow *common.Flow) flowContext {
 	}
 }
 
-func newFlowAccumulator(aggregatorFlushInterval time.Duration, aggregatorFlowContextTTL time.Duration, portRollupThreshold int, portRollupDisabled bool, logger log.Component, rdnsQuerier rdnsquerier.Component) *flowAccumulator {
+func newFlowAccumulator(aggregatorFlushInterval time.Duration, aggregatorFlowContextTTL time.Duration, portRollupThreshold int, portRollupDisabled bool, logger log.Component, rdnsQuerier rdnsquerier.Component, enricher enrichment.Enricher, aggregationKey *aggregationKey) *flowAccumulator {
 	return &flowAccumulator{
 		flows:                  make(map[uint64]flowContext),
 		flowFlushInterval:      aggregatorFlushInterval,
@@ -64,6 +69,8 @@ func newFlowAccumulator(aggregatorFlushInterval time.Duration, aggregatorFlowCon
 		hashCollisionFlowCount: atomic.NewUint64(0),
 		logger:                 logger,
 		rdnsQuerier:            rdnsQuerier,
+		enricher:               enricher,
+		aggregationKey:         aggregationKey,
 	}
 }
 
@@ -122,18 +129,21 @@ func (f *flowAccumulator) add(flowToAdd *common.Flow) {
 	f.flowsMutex.Lock()
 	defer f.flowsMutex.Unlock()
 
-	aggHash := flowToAdd.AggregationHash()
+	aggHash := f.reduce(flowToAdd).AggregationHash()
 	aggFlow, ok := f.flows[aggHash]
 	if !ok {
-		f.flows[aggHash] = newFlowContext(flowToAdd)
-		f.addRDNSEnrichment(aggHash, flowToAdd.SrcAddr, flowToAdd.DstAddr)
+		reduced := f.reduceAndEnrich(flowToAdd)
+		f.flows[aggHash] = newFlowContext(reduced)
+		f.addRDNSEnrichment(aggHash, f.hostAddr(reduced.SrcAddr), f.hostAddr(reduced.DstAddr))
 		return
 	}
 	if aggFlow.flow == nil {
 		// flowToAdd is for the same hash as an aggregated flow that has been flushed
-		aggFlow.flow = flowToAdd
-		f.addRDNSEnrichment(aggHash, flowToAdd.SrcAddr, flowToAdd.DstAddr)
+		aggFlow.flow = f.reduceAndEnrich(flowToAdd)
+		f.addRDNSEnrichment(aggHash, f.hostAddr(aggFlow.flow.SrcAddr), f.hostAddr(aggFlow.flow.DstAddr))
 	} else {
+		flowToAdd = f.reduce(flowToAdd)
+
 		// use go routine for hash collision detection to avoid blocking critical path
 		go f.detectHashCollision(aggHash, *aggFlow.flow, *flowToAdd)
 
@@ -161,6 +171,40 @@ func (f *flowAccumulator) add(flowToAdd *common.Flow) {
 	f.flows[aggHash] = aggFlow
 }
 
+// reduce returns the flow with the fields that aren't part of the aggregation
+// key reset, or the flow itself when flows are aggregated using all fields.
+func (f *flowAccumulator) reduce(flow *common.Flow) *common.Flow {
+	if f.aggregationKey == nil {
+		return flow
+	}
+	reduced := *flow
+	f.aggregationKey.reduce(&reduced)
+	return &reduced
+}
+
+// reduceAndEnrich reduces a flow starting a new aggregated flow, then applies the
+// configured enrichment to it. Enrichers see the fields of the aggregation key
+// rather than the ones of the first flow, so that the enrichment holds for all
+// the flows accumulated into the aggregated flow, e.g. no interface name is set
+// when the input interface isn't part of the key.
+func (f *flowAccumulator) reduceAndEnrich(flow *common.Flow) *common.Flow {
+	reduced := f.reduce(flow)
+	if f.enricher != nil {
+		f.enricher.Enrich(reduced)
+	}
+	return reduced
+}
+
+// hostAddr returns the address of an aggregated flow endpoint if it is the
+// address of a host, or nil if it was reset or replaced by its network by the
+// aggregation key, in which case it has no reverse DNS hostname.
+func (f *flowAccumulator) hostAddr(addr []byte) []byte {
+	if f.aggregationKey == nil || f.aggregationKey.prefixBits(addr) == 0 {
+		return addr
+	}
+	return nil
+}
+
 func (f *flowAccumulator) setSrcReverseDNSHostname(aggHash uint64, hostname string, acquireLock bool) {
 	if hostname == "" {
 		return
@@ -194,6 +238,15 @@ func (f *flowAccumulator) setDstReverseDNSHostname(aggHash uint64, hostname stri
 }
 
 func (f *flowAccumulator) addRDNSEnrichment(aggHash uint64, srcAddr []byte, dstAddr []byte) {
+	if srcAddr != nil {
+		f.addSrcRDNSEnrichment(aggHash, srcAddr)
+	}
+	if dstAddr != nil {
+		f.addDstRDNSEnrichment(aggHash, dstAddr)
+	}
+}
+
+func (f *flowAccumulator) addSrcRDNSEnrichment(aggHash uint64, srcAddr []byte) {
 	err := f.rdnsQuerier.GetHostnameAsync(
 		srcAddr,
 		// Sync callback, lock is already held
@@ -212,8 +265,10 @@ func (f *flowAccumulator) addRDNSEnrichment(aggHash uint64, srcAddr []byte, dstA
 	if err != nil {
 		f.logger.Debugf("Error requesting reverse DNS enrichment for source IP address: %v error: %v", srcAddr, err)
 	}
+}
 
-	err = f.rdnsQuerier.GetHostnameAsync(
+func (f *flowAccumulator) addDstRDNSEnrichment(aggHash uint64, dstAddr []byte) {
+	err := f.rdnsQuerier.GetHostnameAsync(
 		dstAddr,
 		// Sync callback, lock is held
 		func(hostname string) {
@@ -6,6 +6,7 @@
 package flowaggregator
 
 import (
+	"sort"
 	"testing"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // MockTimeNow mocks time.Now
@@ -92,7 +94,7 @@ func Test_flowAccumulator_add(t *testing.T) {
 	}
 
 	// When
-	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier)
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier, nil, nil)
 	acc.add(flowA1)
 	acc.add(flowA2)
 	acc.add(flowB1)
@@ -115,6 +117,144 @@ func Test_flowAccumulator_add(t *testing.T) {
 	assert.Equal(t, []byte{10, 10, 10, 30}, wrappedFlowB.flow.DstAddr)
 }
 
+type countingEnricher struct {
+	count int
+}
+
+func (e *countingEnricher) Enrich(flow *common.Flow) {
+	e.count++
+	flow.SrcCountry = "FR"
+	flow.DstTags = []string{"site:paris"}
+}
+
+func Test_flowAccumulator_enrichment(t *testing.T) {
+	logger := logmock.New(t)
+	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
+	enricher := &countingEnricher{}
+
+	flow := func(bytes uint64) *common.Flow {
+		return &common.Flow{
+			FlowType:     common.TypeNetFlow9,
+			ExporterAddr: []byte{127, 0, 0, 1},
+			Bytes:        bytes,
+			Packets:      1,
+			SrcAddr:      []byte{10, 10, 10, 10},
+			DstAddr:      []byte{10, 10, 10, 20},
+			IPProtocol:   uint32(6),
+			SrcPort:      2000,
+			DstPort:      80,
+		}
+	}
+	flowA1 := flow(10)
+
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier, enricher, nil)
+	acc.add(flowA1)
+	acc.add(flow(20))
+
+	// the enrichment is applied once per aggregated flow
+	assert.Equal(t, 1, enricher.count)
+	wrappedFlow := acc.flows[flowA1.AggregationHash()]
+	assert.Equal(t, uint64(30), wrappedFlow.flow.Bytes)
+	assert.Equal(t, "FR", wrappedFlow.flow.SrcCountry)
+	assert.Equal(t, []string{"site:paris"}, wrappedFlow.flow.DstTags)
+}
+
+func Test_flowAccumulator_aggregationKey(t *testing.T) {
+	logger := logmock.New(t)
+	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
+
+	flow := func(srcAddr []byte, srcPort int32) *common.Flow {
+		return &common.Flow{
+			FlowType:     common.TypeNetFlow9,
+			ExporterAddr: []byte{127, 0, 0, 1},
+			Bytes:        10,
+			Packets:      1,
+			SrcAddr:      srcAddr,
+			DstAddr:      []byte{10, 10, 10, 20},
+			IPProtocol:   uint32(6),
+			SrcPort:      srcPort,
+			DstPort:      80,
+		}
+	}
+
+	aggKey := newAggregationKey([]string{"source.ip", "destination.ip", "destination.port", "ip_protocol"}, 24, 0)
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, true, logger, rdnsQuerier, nil, aggKey)
+	acc.add(flow([]byte{10, 10, 10, 10}, 2000))
+	acc.add(flow([]byte{10, 10, 10, 11}, 2001))
+	acc.add(flow([]byte{10, 10, 11, 10}, 2002))
+
+	assert.Equal(t, 2, len(acc.flows))
+	flows := acc.flush()
+	sort.Slice(flows, func(i, j int) bool {
+		return flows[i].SrcAddr[2] < flows[j].SrcAddr[2]
+	})
+	assert.Equal(t, []byte{10, 10, 10, 0}, flows[0].SrcAddr)
+	assert.Equal(t, int32(-1), flows[0].SrcPort)
+	assert.Equal(t, uint64(20), flows[0].Bytes)
+	assert.Equal(t, []byte{10, 10, 11, 0}, flows[1].SrcAddr)
+	assert.Equal(t, uint64(10), flows[1].Bytes)
+}
+
+// addrEnricher records the addresses and interfaces of the flows it enriches.
+type addrEnricher struct {
+	srcAddrs        [][]byte
+	inputInterfaces []uint32
+}
+
+func (e *addrEnricher) Enrich(flow *common.Flow) {
+	e.srcAddrs = append(e.srcAddrs, flow.SrcAddr)
+	e.inputInterfaces = append(e.inputInterfaces, flow.InputInterface)
+	flow.SrcCountry = "FR"
+}
+
+func Test_flowAccumulator_aggregationKeyEnrichment(t *testing.T) {
+	logger := logmock.New(t)
+	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
+	enricher := &addrEnricher{}
+
+	flow := func(srcAddr []byte) *common.Flow {
+		return &common.Flow{
+			FlowType:       common.TypeNetFlow9,
+			ExporterAddr:   []byte{127, 0, 0, 1},
+			Bytes:          10,
+			Packets:        1,
+			SrcAddr:        srcAddr,
+			DstAddr:        []byte{10, 10, 10, 20},
+			IPProtocol:     uint32(6),
+			SrcPort:        2000,
+			DstPort:        80,
+			InputInterface: 3,
+		}
+	}
+
+	aggKey := newAggregationKey([]string{"source.ip", "destination.ip"}, 24, 0)
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, true, logger, rdnsQuerier, enricher, aggKey)
+	acc.add(flow([]byte{10, 10, 10, 10}))
+	acc.add(flow([]byte{10, 10, 10, 11}))
+
+	// the aggregated flow is enriched from its aggregation key, not from its first flow
+	assert.Equal(t, [][]byte{{10, 10, 10, 0}}, enricher.srcAddrs)
+	assert.Equal(t, []uint32{0}, enricher.inputInterfaces)
+
+	flows := acc.flush()
+	require.Len(t, flows, 1)
+	assert.Equal(t, []byte{10, 10, 10, 0}, flows[0].SrcAddr)
+	assert.Equal(t, uint32(0), flows[0].InputInterface)
+	assert.Equal(t, uint64(20), flows[0].Bytes)
+	assert.Equal(t, "FR", flows[0].SrcCountry)
+	// networks have no reverse DNS hostname
+	assert.Equal(t, "", flows[0].SrcReverseDNSHostname)
+	assert.Equal(t, "", flows[0].DstReverseDNSHostname)
+
+	// addresses kept as is by the aggregation key are resolved
+	aggKey = newAggregationKey([]string{"source.ip", "destination.ip"}, 0, 0)
+	acc = newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, common.DefaultAggregatorPortRollupThreshold, true, logger, rdnsQuerier, nil, aggKey)
+	acc.add(flow([]byte{10, 10, 10, 10}))
+	flows = acc.flush()
+	require.Len(t, flows, 1)
+	assert.Equal(t, "hostname-10.10.10.20", flows[0].DstReverseDNSHostname)
+}
+
 func Test_flowAccumulator_portRollUp(t *testing.T) {
 	logger := logmock.New(t)
 	rdnsQuerier := fxutil.Test[rdnsquerier.Component](t, rdnsquerierfxmock.MockModule())
@@ -166,7 +306,7 @@ func Test_flowAccumulator_portRollUp(t *testing.T) {
 	}
 
 	// When
-	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, 3, false, logger, rdnsQuerier)
+	acc := newFlowAccumulator(common.DefaultAggregatorFlushInterval, common.DefaultAggregatorFlushInterval, 3, false, logger, rdnsQuerier, nil, nil)
 	acc.add(flowA1)
 	acc.add(flowA2)
 
@@ -243,7 +383,7 @@ func Test_flowAccumulator_flush(t *testing.T) {
 	}
 
 	// When
-	acc := newFlowAccumulator(flushInterval, flowContextTTL, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier)
+	acc := newFlowAccumulator(flushInterval, flowContextTTL, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier, nil, nil)
 	acc.add(flow)
 
 	// Then
@@ -359,7 +499,7 @@ func Test_flowAccumulator_detectHashCollision(t *testing.T) {
 	}
 
 	// When
-	acc := newFlowAccumulator(flushInterval, flowContextTTL, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier)
+	acc := newFlowAccumulator(flushInterval, flowContextTTL, common.DefaultAggregatorPortRollupThreshold, false, logger, rdnsQuerier, nil, nil)
 
 	// Then
 	assert.Equal(t, uint64(0), acc.hashCollisionFlowCount.Load())
@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/format"
+)
+
+type sflowExporterKey struct {
+	namespace  string
+	exporterIP string
+}
+
+type sflowInterfaceKey struct {
+	sflowExporterKey
+	index uint32
+}
+
+// sflowCounters holds the latest counters received from sFlow counter samples
+// since the last flush.
+type sflowCounters struct {
+	mu         sync.Mutex
+	interfaces map[sflowInterfaceKey]common.InterfaceCounters
+	hostCPU    map[sflowExporterKey]common.HostCPUCounters
+	hostMemory map[sflowExporterKey]common.HostMemoryCounters
+}
+
+func newSFlowCounters() *sflowCounters {
+	return &sflowCounters{
+		interfaces: make(map[sflowInterfaceKey]common.InterfaceCounters),
+		hostCPU:    make(map[sflowExporterKey]common.HostCPUCounters),
+		hostMemory: make(map[sflowExporterKey]common.HostMemoryCounters),
+	}
+}
+
+func (c *sflowCounters) add(sample *common.SFlowCounterSample) {
+	exporterKey := sflowExporterKey{namespace: sample.Namespace, exporterIP: format.IPAddr(sample.ExporterAddr)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if sample.Interface != nil {
+		c.interfaces[sflowInterfaceKey{sflowExporterKey: exporterKey, index: sample.Interface.Index}] = *sample.Interface
+	}
+	if sample.HostCPU != nil {
+		c.hostCPU[exporterKey] = *sample.HostCPU
+	}
+	if sample.HostMemory != nil {
+		c.hostMemory[exporterKey] = *sample.HostMemory
+	}
+}
+
+// flush returns the latest counters and resets them
+func (c *sflowCounters) flush() (map[sflowInterfaceKey]common.InterfaceCounters, map[sflowExporterKey]common.HostCPUCounters, map[sflowExporterKey]common.HostMemoryCounters) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	interfaces, hostCPU, hostMemory := c.interfaces, c.hostCPU, c.hostMemory
+	c.interfaces = make(map[sflowInterfaceKey]common.InterfaceCounters)
+	c.hostCPU = make(map[sflowExporterKey]common.HostCPUCounters)
+	c.hostMemory = make(map[sflowExporterKey]common.HostMemoryCounters)
+	return interfaces, hostCPU, hostMemory
+}
+
+// submitSFlowCounters submits the counters received from sFlow counter samples as metrics.
+// sFlow counters are cumulative and are submitted as monotonic counts.
+func (agg *FlowAggregator) submitSFlowCounters() {
+	interfaces, hostCPU, hostMemory := agg.sflowCounters.flush()
+
+	for key, counters := range interfaces {
+		tags := []string{"device_namespace:" + key.namespace, "exporter_ip:" + key.exporterIP, "interface_index:" + strconv.Itoa(int(key.index))}
+		agg.sender.Gauge(metricPrefix+"sflow.interface.speed", float64(counters.Speed), "", tags)
+		agg.sender.Gauge(metricPrefix+"sflow.interface.admin_status", float64(counters.Status&1), "", tags)
+		agg.sender.Gauge(metricPrefix+"sflow.interface.oper_status", float64(counters.Status>>1&1), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.interface.in_octets", float64(counters.InOctets), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.interface.in_ucast_pkts", float64(counters.InUcastPkts), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.interface.in_multicast_pkts", float64(counters.InMulticastPkts), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.interface.in_broadcast_pkts", float64(counters.InBroadcastPkts), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.interface.in_discards", float64(counters.InDiscards), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.interface.in_errors", float64(counters.InErrors), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.interface.in_unknown_protos", float64(counters.InUnknownProtos), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.interface.out_octets", float64(counters.OutOctets), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.interface.out_ucast_pkts", float64(counters.OutUcastPkts), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.interface.out_multicast_pkts", float64(counters.OutMulticastPkts), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.interface.out_broadcast_pkts", float64(counters.OutBroadcastPkts), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.interface.out_discards", float64(counters.OutDiscards), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.interface.out_errors", float64(counters.OutErrors), "", tags)
+	}
+
+	for key, cpu := range hostCPU {
+		tags := []string{"device_namespace:" + key.namespace, "exporter_ip:" + key.exporterIP}
+		agg.sender.Gauge(metricPrefix+"sflow.host.cpu.load_one", float64(cpu.LoadOne), "", tags)
+		agg.sender.Gauge(metricPrefix+"sflow.host.cpu.load_five", float64(cpu.LoadFive), "", tags)
+		agg.sender.Gauge(metricPrefix+"sflow.host.cpu.load_fifteen", float64(cpu.LoadFifteen), "", tags)
+		agg.sender.Gauge(metricPrefix+"sflow.host.cpu.num", float64(cpu.CPUNum), "", tags)
+		agg.sender.Gauge(metricPrefix+"sflow.host.proc_run", float64(cpu.ProcRun), "", tags)
+		agg.sender.Gauge(metricPrefix+"sflow.host.proc_total", float64(cpu.ProcTotal), "", tags)
+		agg.sender.Gauge(metricPrefix+"sflow.host.uptime", float64(cpu.Uptime), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.host.cpu.user", float64(cpu.CPUUser), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.host.cpu.nice", float64(cpu.CPUNice), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.host.cpu.system", float64(cpu.CPUSystem), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.host.cpu.idle", float64(cpu.CPUIdle), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.host.cpu.iowait", float64(cpu.CPUWio), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.host.cpu.interrupt", float64(cpu.CPUIntr), "", tags)
+		agg.sender.MonotonicCount(metricPrefix+"sflow.host.cpu.softirq", float64(cpu.CPUSIntr), "", tags)
+	}
+
+	for key, memory := range hostMemory {
+		tags := []string{"device_namespace:" + key.namespace, "exporter_ip:" + key.exporterIP}
+		agg.sender.Gauge(metricPrefix+"sflow.host.memory.total", float64(memory.MemTotal), "", tags)
+		agg.sender.Gauge(metricPrefix+"sflow.host.memory.free", float64(memory.MemFree), "", tags)
+		agg.sender.Gauge(metricPrefix+"sflow.host.memory.shared", float64(memory.MemShared), "", tags)
+		agg.sender.Gauge(metricPrefix+"sflow.host.memory.buffers", float64(memory.MemBuffers), "", tags)
+		agg.sender.Gauge(metricPrefix+"sflow.host.memory.cached", float64(memory.MemCached), "", tags)
+		agg.sender.Gauge(metricPrefix+"sflow.host.swap.total", float64(memory.SwapTotal), "", tags)
+		agg.sender.Gauge(metricPrefix+"sflow.host.swap.free", float64(memory.SwapFree), "", tags)
+	}
+}
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"sync"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+	"github.com/DataDog/datadog-agent/comp/netflow/format"
+	"github.com/DataDog/datadog-agent/comp/netflow/heavyhitters"
+)
+
+type topTalkersExporterKey struct {
+	namespace  string
+	exporterIP string
+}
+
+type exporterTalkers struct {
+	sources      *heavyhitters.Sketch
+	destinations *heavyhitters.Sketch
+}
+
+// topTalker is a source or destination IP address among the top talkers of an exporter
+type topTalker struct {
+	namespace  string
+	exporterIP string
+	ip         string
+	bytes      uint64
+}
+
+// topTalkers tracks the IP addresses sending and receiving the most bytes per
+// exporter, with a bounded number of tracked addresses per exporter.
+type topTalkers struct {
+	count    int
+	capacity int
+
+	mu        sync.Mutex
+	exporters map[topTalkersExporterKey]*exporterTalkers
+}
+
+func newTopTalkers(count int, capacity int) *topTalkers {
+	return &topTalkers{
+		count:     count,
+		capacity:  capacity,
+		exporters: make(map[topTalkersExporterKey]*exporterTalkers),
+	}
+}
+
+// add adds the bytes of the flow, scaled by its sampling rate, to its source and destination
+func (t *topTalkers) add(flow *common.Flow) {
+	bytes := flow.Bytes
+	if flow.SamplingRate > 1 {
+		bytes *= flow.SamplingRate
+	}
+	key := topTalkersExporterKey{namespace: flow.Namespace, exporterIP: format.IPAddr(flow.ExporterAddr)}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	talkers, ok := t.exporters[key]
+	if !ok {
+		talkers = &exporterTalkers{
+			sources:      heavyhitters.NewSketch(t.capacity),
+			destinations: heavyhitters.NewSketch(t.capacity),
+		}
+		t.exporters[key] = talkers
+	}
+	if srcIP := format.IPAddr(flow.SrcAddr); srcIP != "" {
+		talkers.sources.Add(srcIP, bytes)
+	}
+	if dstIP := format.IPAddr(flow.DstAddr); dstIP != "" {
+		talkers.destinations.Add(dstIP, bytes)
+	}
+}
+
+// flush returns the top source and destination talkers of each exporter since
+// the last flush, and resets the tracked talkers.
+func (t *topTalkers) flush() (sources []topTalker, destinations []topTalker) {
+	t.mu.Lock()
+	exporters := t.exporters
+	t.exporters = make(map[topTalkersExporterKey]*exporterTalkers)
+	t.mu.Unlock()
+
+	for key, talkers := range exporters {
+		sources = append(sources, t.top(key, talkers.sources)...)
+		destinations = append(destinations, t.top(key, talkers.destinations)...)
+	}
+	return sources, destinations
+}
+
+func (t *topTalkers) top(key topTalkersExporterKey, sketch *heavyhitters.Sketch) []topTalker {
+	var talkers []topTalker
+	for _, item := range sketch.Top(t.count) {
+		talkers = append(talkers, topTalker{
+			namespace:  key.namespace,
+			exporterIP: key.exporterIP,
+			ip:         item.Key,
+			bytes:      item.Count,
+		})
+	}
+	return talkers
+}
@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package flowaggregator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+func Test_topTalkers(t *testing.T) {
+	talkers := newTopTalkers(2, 10)
+	for _, flow := range []*common.Flow{
+		{Namespace: "ns", ExporterAddr: []byte{127, 0, 0, 1}, SrcAddr: []byte{10, 0, 0, 1}, DstAddr: []byte{10, 0, 0, 9}, Bytes: 10},
+		{Namespace: "ns", ExporterAddr: []byte{127, 0, 0, 1}, SrcAddr: []byte{10, 0, 0, 2}, DstAddr: []byte{10, 0, 0, 9}, Bytes: 30},
+		{Namespace: "ns", ExporterAddr: []byte{127, 0, 0, 1}, SrcAddr: []byte{10, 0, 0, 3}, DstAddr: []byte{10, 0, 0, 9}, Bytes: 20},
+		{Namespace: "ns", ExporterAddr: []byte{127, 0, 0, 2}, SrcAddr: []byte{10, 0, 0, 1}, DstAddr: []byte{10, 0, 0, 8}, Bytes: 5, SamplingRate: 100},
+	} {
+		talkers.add(flow)
+	}
+
+	sources, destinations := talkers.flush()
+	assert.ElementsMatch(t, []topTalker{
+		{namespace: "ns", exporterIP: "127.0.0.1", ip: "10.0.0.2", bytes: 30},
+		{namespace: "ns", exporterIP: "127.0.0.1", ip: "10.0.0.3", bytes: 20},
+		{namespace: "ns", exporterIP: "127.0.0.2", ip: "10.0.0.1", bytes: 500},
+	}, sources)
+	assert.ElementsMatch(t, []topTalker{
+		{namespace: "ns", exporterIP: "127.0.0.1", ip: "10.0.0.9", bytes: 60},
+		{namespace: "ns", exporterIP: "127.0.0.2", ip: "10.0.0.8", bytes: 500},
+	}, destinations)
+
+	sources, destinations = talkers.flush()
+	assert.Empty(t, sources)
+	assert.Empty(t, destinations)
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/comp/netflow/config"
 	"github.com/DataDog/datadog-agent/comp/netflow/goflowlib/netflowstate"
+	"github.com/DataDog/datadog-agent/comp/netflow/goflowlib/sflowstate"
 
 	"github.com/netsampler/goflow2/decoders/netflow/templates"
 	"go.uber.org/atomic"
@@ -52,12 +53,13 @@ func StartFlowRoutine(
 	namespace string,
 	fieldMappings []config.Mapping,
 	flowInChan chan *common.Flow,
+	counterInChan chan *common.SFlowCounterSample,
 	logger log.Component,
 	atomicErr *atomic.String,
 	listenerFlowCount *atomic.Int64) (*FlowStateWrapper, error) {
 	var flowState FlowRunnableState
 
-	formatDriver := NewAggregatorFormatDriver(flowInChan, namespace, listenerFlowCount)
+	formatDriver := NewAggregatorFormatDriver(flowInChan, counterInChan, namespace, listenerFlowCount)
 	goflowLogger := &GoflowLoggerAdapter{logger}
 	ctx := context.Background()
 
@@ -75,7 +77,7 @@ func StartFlowRoutine(
 		state.TemplateSystem = templateSystem
 		flowState = state
 	case common.TypeSFlow5:
-		state := utils.NewStateSFlow()
+		state := sflowstate.NewStateSFlow()
 		state.Format = formatDriver
 		state.Logger = goflowLogger
 		flowState = state
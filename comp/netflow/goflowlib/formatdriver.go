@@ -18,14 +18,16 @@ import (
 type AggregatorFormatDriver struct {
 	namespace         string
 	flowAggIn         chan *common.Flow
+	counterAggIn      chan *common.SFlowCounterSample
 	listenerFlowCount *atomic.Int64
 }
 
 // NewAggregatorFormatDriver returns a new AggregatorFormatDriver
-func NewAggregatorFormatDriver(flowAgg chan *common.Flow, namespace string, listenerFlowCount *atomic.Int64) *AggregatorFormatDriver {
+func NewAggregatorFormatDriver(flowAgg chan *common.Flow, counterAgg chan *common.SFlowCounterSample, namespace string, listenerFlowCount *atomic.Int64) *AggregatorFormatDriver {
 	return &AggregatorFormatDriver{
 		namespace:         namespace,
 		flowAggIn:         flowAgg,
+		counterAggIn:      counterAgg,
 		listenerFlowCount: listenerFlowCount,
 	}
 }
@@ -49,8 +51,17 @@ func (d *AggregatorFormatDriver) Format(data interface{}) ([]byte, []byte, error
 	case *common.FlowMessageWithAdditionalFields:
 		d.listenerFlowCount.Add(1)
 		d.flowAggIn <- ConvertFlowWithAdditionalFields(flow, d.namespace)
+	case *common.Flow:
+		d.listenerFlowCount.Add(1)
+		flow.Namespace = d.namespace
+		d.flowAggIn <- flow
+	case *common.SFlowCounterSample:
+		if d.counterAggIn != nil {
+			flow.Namespace = d.namespace
+			d.counterAggIn <- flow
+		}
 	default:
-		return nil, nil, fmt.Errorf("message is not flowpb.FlowMessage or common.FlowMessageWithAdditionalFields")
+		return nil, nil, fmt.Errorf("message is not flowpb.FlowMessage, common.FlowMessageWithAdditionalFields, common.Flow or common.SFlowCounterSample")
 	}
 
 	return nil, nil, nil
@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package sflowstate
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/netsampler/goflow2/decoders/sflow"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+// sFlow counter record formats not decoded by goflow
+const (
+	counterFormatHostCPU    = 2003
+	counterFormatHostMemory = 2004
+)
+
+// hostCPURecord and hostMemoryRecord are the XDR layouts of the host_cpu and
+// host_memory counter records, see https://sflow.org/sflow_host.txt
+type hostCPURecord struct {
+	LoadOne     float32
+	LoadFive    float32
+	LoadFifteen float32
+	ProcRun     uint32
+	ProcTotal   uint32
+	CPUNum      uint32
+	CPUSpeed    uint32
+	Uptime      uint32
+	CPUUser     uint32
+	CPUNice     uint32
+	CPUSystem   uint32
+	CPUIdle     uint32
+	CPUWio      uint32
+	CPUIntr     uint32
+	CPUSIntr    uint32
+	Interrupts  uint32
+	Contexts    uint32
+}
+
+type hostMemoryRecord struct {
+	MemTotal   uint64
+	MemFree    uint64
+	MemShared  uint64
+	MemBuffers uint64
+	MemCached  uint64
+	SwapTotal  uint64
+	SwapFree   uint64
+	PageIn     uint32
+	PageOut    uint32
+	SwapIn     uint32
+	SwapOut    uint32
+}
+
+// convertCounterSample converts the records of a counter sample, or returns nil
+// if none of its records has a known format.
+func convertCounterSample(packet sflow.Packet, sample sflow.CounterSample) *common.SFlowCounterSample {
+	counterSample := &common.SFlowCounterSample{
+		ExporterAddr: packet.AgentIP,
+	}
+	for _, record := range sample.Records {
+		switch recordData := record.Data.(type) {
+		case sflow.IfCounters:
+			counterSample.Interface = &common.InterfaceCounters{
+				Index:            recordData.IfIndex,
+				Type:             recordData.IfType,
+				Speed:            recordData.IfSpeed,
+				Direction:        recordData.IfDirection,
+				Status:           recordData.IfStatus,
+				InOctets:         recordData.IfInOctets,
+				InUcastPkts:      recordData.IfInUcastPkts,
+				InMulticastPkts:  recordData.IfInMulticastPkts,
+				InBroadcastPkts:  recordData.IfInBroadcastPkts,
+				InDiscards:       recordData.IfInDiscards,
+				InErrors:         recordData.IfInErrors,
+				InUnknownProtos:  recordData.IfInUnknownProtos,
+				OutOctets:        recordData.IfOutOctets,
+				OutUcastPkts:     recordData.IfOutUcastPkts,
+				OutMulticastPkts: recordData.IfOutMulticastPkts,
+				OutBroadcastPkts: recordData.IfOutBroadcastPkts,
+				OutDiscards:      recordData.IfOutDiscards,
+				OutErrors:        recordData.IfOutErrors,
+			}
+		case *sflow.FlowRecordRaw:
+			switch record.Header.DataFormat {
+			case counterFormatHostCPU:
+				var cpu hostCPURecord
+				if err := binary.Read(bytes.NewReader(recordData.Data), binary.BigEndian, &cpu); err == nil {
+					hostCPU := common.HostCPUCounters(cpu)
+					counterSample.HostCPU = &hostCPU
+				}
+			case counterFormatHostMemory:
+				var memory hostMemoryRecord
+				if err := binary.Read(bytes.NewReader(recordData.Data), binary.BigEndian, &memory); err == nil {
+					hostMemory := common.HostMemoryCounters(memory)
+					counterSample.HostMemory = &hostMemory
+				}
+			}
+		}
+	}
+	if counterSample.Interface == nil && counterSample.HostCPU == nil && counterSample.HostMemory == nil {
+		return nil
+	}
+	return counterSample
+}
@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package sflowstate
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/netsampler/goflow2/decoders/sflow"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+func rawRecord(t *testing.T, dataFormat uint32, record interface{}) sflow.CounterRecord {
+	buf := new(bytes.Buffer)
+	require.NoError(t, binary.Write(buf, binary.BigEndian, record))
+	return sflow.CounterRecord{
+		Header: sflow.RecordHeader{DataFormat: dataFormat, Length: uint32(buf.Len())},
+		Data:   &sflow.FlowRecordRaw{Data: buf.Bytes()},
+	}
+}
+
+func Test_convertCounterSample(t *testing.T) {
+	packet := sflow.Packet{AgentIP: []byte{127, 0, 0, 1}}
+
+	tests := []struct {
+		name           string
+		records        []sflow.CounterRecord
+		expectedSample *common.SFlowCounterSample
+	}{
+		{
+			name: "interface counters",
+			records: []sflow.CounterRecord{
+				{
+					Header: sflow.RecordHeader{DataFormat: 1},
+					Data: sflow.IfCounters{
+						IfIndex:       3,
+						IfType:        6,
+						IfSpeed:       1000000000,
+						IfDirection:   1,
+						IfStatus:      3,
+						IfInOctets:    1000,
+						IfInUcastPkts: 10,
+						IfInErrors:    1,
+						IfOutOctets:   2000,
+						IfOutDiscards: 2,
+					},
+				},
+			},
+			expectedSample: &common.SFlowCounterSample{
+				ExporterAddr: []byte{127, 0, 0, 1},
+				Interface: &common.InterfaceCounters{
+					Index:       3,
+					Type:        6,
+					Speed:       1000000000,
+					Direction:   1,
+					Status:      3,
+					InOctets:    1000,
+					InUcastPkts: 10,
+					InErrors:    1,
+					OutOctets:   2000,
+					OutDiscards: 2,
+				},
+			},
+		},
+		{
+			name: "host counters",
+			records: []sflow.CounterRecord{
+				rawRecord(t, counterFormatHostCPU, hostCPURecord{LoadOne: 0.5, CPUNum: 4, Uptime: 3600, CPUUser: 100, CPUIdle: 900}),
+				rawRecord(t, counterFormatHostMemory, hostMemoryRecord{MemTotal: 8 << 30, MemFree: 1 << 30, SwapTotal: 2 << 30}),
+			},
+			expectedSample: &common.SFlowCounterSample{
+				ExporterAddr: []byte{127, 0, 0, 1},
+				HostCPU:      &common.HostCPUCounters{LoadOne: 0.5, CPUNum: 4, Uptime: 3600, CPUUser: 100, CPUIdle: 900},
+				HostMemory:   &common.HostMemoryCounters{MemTotal: 8 << 30, MemFree: 1 << 30, SwapTotal: 2 << 30},
+			},
+		},
+		{
+			name: "truncated host cpu record",
+			records: []sflow.CounterRecord{
+				{
+					Header: sflow.RecordHeader{DataFormat: counterFormatHostCPU, Length: 4},
+					Data:   &sflow.FlowRecordRaw{Data: []byte{0, 0, 0, 0}},
+				},
+			},
+		},
+		{
+			name: "unknown records",
+			records: []sflow.CounterRecord{
+				{
+					Header: sflow.RecordHeader{DataFormat: 2},
+					Data:   sflow.EthernetCounters{Dot3StatsAlignmentErrors: 1},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sample := sflow.CounterSample{Records: tt.records}
+			assert.Equal(t, tt.expectedSample, convertCounterSample(packet, sample))
+		})
+	}
+}
@@ -0,0 +1,280 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package sflowstate
+
+import (
+	"encoding/binary"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+// EtherType values
+const (
+	etherTypeIPv4                = 0x0800
+	etherTypeIPv6                = 0x86dd
+	etherTypeVLAN                = 0x8100
+	etherTypeQinQ                = 0x88a8
+	etherTypeMPLS                = 0x8847
+	etherTypeTransparentEthernet = 0x6558
+)
+
+// IP protocol and IPv6 extension header numbers
+const (
+	ipProtocolHopByHop    = 0
+	ipProtocolICMP        = 1
+	ipProtocolTCP         = 6
+	ipProtocolUDP         = 17
+	ipProtocolRouting     = 43
+	ipProtocolFragment    = 44
+	ipProtocolGRE         = 47
+	ipProtocolAH          = 51
+	ipProtocolICMPv6      = 58
+	ipProtocolDestOptions = 60
+)
+
+const (
+	vxlanPort       = 4789
+	vxlanHeaderSize = 8
+
+	// maxTunnelDepth is the maximum number of nested tunnels decoded
+	maxTunnelDepth = 2
+)
+
+// Sampled headers are truncated, usually to 128 bytes: each decoding step
+// checks the remaining length and stops when a header is incomplete, keeping
+// the fields decoded so far.
+
+// decodeEthernet decodes an Ethernet frame and its VLAN tags.
+func decodeEthernet(flow *common.Flow, data []byte, tunnelDepth int) {
+	if len(data) < 14 {
+		return
+	}
+	flow.DstMac = macAddress(data[0:6])
+	flow.SrcMac = macAddress(data[6:12])
+	etherType := binary.BigEndian.Uint16(data[12:14])
+	data = data[14:]
+
+	// 802.1Q and 802.1ad (QinQ) tags, the outermost VLAN is kept
+	for etherType == etherTypeVLAN || etherType == etherTypeQinQ {
+		if len(data) < 4 {
+			return
+		}
+		if flow.VlanID == 0 {
+			flow.VlanID = uint32(binary.BigEndian.Uint16(data[0:2]) & 0x0fff)
+		}
+		etherType = binary.BigEndian.Uint16(data[2:4])
+		data = data[4:]
+	}
+	decodeEtherType(flow, etherType, data, tunnelDepth)
+}
+
+func decodeEtherType(flow *common.Flow, etherType uint16, data []byte, tunnelDepth int) {
+	switch etherType {
+	case etherTypeIPv4:
+		decodeIPv4(flow, data, tunnelDepth)
+	case etherTypeIPv6:
+		decodeIPv6(flow, data, tunnelDepth)
+	case etherTypeMPLS:
+		decodeMPLS(flow, data, tunnelDepth)
+	default:
+		flow.EtherType = uint32(etherType)
+	}
+}
+
+// decodeMPLS decodes a MPLS label stack, followed by an IPv4 or IPv6 packet.
+func decodeMPLS(flow *common.Flow, data []byte, tunnelDepth int) {
+	flow.EtherType = etherTypeMPLS
+	flow.MPLSLabels = nil
+	for len(data) >= 4 {
+		entry := binary.BigEndian.Uint32(data[0:4])
+		data = data[4:]
+		flow.MPLSLabels = append(flow.MPLSLabels, entry>>12)
+		if entry&0x100 != 0 { // bottom of stack
+			break
+		}
+	}
+	if len(data) == 0 {
+		return
+	}
+	// the payload type is not part of MPLS headers, guess it from the IP version
+	switch data[0] >> 4 {
+	case 4:
+		decodeIPv4(flow, data, tunnelDepth)
+	case 6:
+		decodeIPv6(flow, data, tunnelDepth)
+	}
+}
+
+func decodeIPv4(flow *common.Flow, data []byte, tunnelDepth int) {
+	if len(data) < 20 {
+		return
+	}
+	headerLength := int(data[0]&0x0f) * 4
+	if headerLength < 20 || len(data) < headerLength {
+		return
+	}
+	flow.EtherType = etherTypeIPv4
+	flow.Tos = uint32(data[1])
+	flow.SrcAddr = append([]byte(nil), data[12:16]...)
+	flow.DstAddr = append([]byte(nil), data[16:20]...)
+	protocol := data[9]
+	flow.IPProtocol = uint32(protocol)
+
+	fragmentOffset := binary.BigEndian.Uint16(data[6:8]) & 0x1fff
+	if fragmentOffset != 0 {
+		// only the first fragment contains the transport header
+		return
+	}
+	decodeTransport(flow, protocol, data[headerLength:], tunnelDepth)
+}
+
+func decodeIPv6(flow *common.Flow, data []byte, tunnelDepth int) {
+	if len(data) < 40 {
+		return
+	}
+	flow.EtherType = etherTypeIPv6
+	flow.Tos = uint32(binary.BigEndian.Uint16(data[0:2])>>4) & 0xff
+	flow.SrcAddr = append([]byte(nil), data[8:24]...)
+	flow.DstAddr = append([]byte(nil), data[24:40]...)
+	nextHeader := data[6]
+	data = data[40:]
+
+	// skip extension headers to find the transport protocol
+	for {
+		var headerLength int
+		switch nextHeader {
+		case ipProtocolHopByHop, ipProtocolRouting, ipProtocolDestOptions:
+			if len(data) < 2 {
+				flow.IPProtocol = uint32(nextHeader)
+				return
+			}
+			headerLength = (int(data[1]) + 1) * 8
+		case ipProtocolAH:
+			if len(data) < 2 {
+				flow.IPProtocol = uint32(nextHeader)
+				return
+			}
+			headerLength = (int(data[1]) + 2) * 4
+		case ipProtocolFragment:
+			if len(data) < 8 {
+				flow.IPProtocol = uint32(nextHeader)
+				return
+			}
+			if binary.BigEndian.Uint16(data[2:4])>>3 != 0 {
+				// only the first fragment contains the transport header
+				flow.IPProtocol = uint32(data[0])
+				return
+			}
+			headerLength = 8
+		default:
+			flow.IPProtocol = uint32(nextHeader)
+			decodeTransport(flow, nextHeader, data, tunnelDepth)
+			return
+		}
+		if len(data) < headerLength {
+			flow.IPProtocol = uint32(data[0])
+			return
+		}
+		nextHeader = data[0]
+		data = data[headerLength:]
+	}
+}
+
+func decodeTransport(flow *common.Flow, protocol byte, data []byte, tunnelDepth int) {
+	switch protocol {
+	case ipProtocolTCP:
+		if len(data) >= 4 {
+			flow.SrcPort = int32(binary.BigEndian.Uint16(data[0:2]))
+			flow.DstPort = int32(binary.BigEndian.Uint16(data[2:4]))
+		}
+		if len(data) >= 14 {
+			flow.TCPFlags = uint32(data[13])
+		}
+	case ipProtocolUDP:
+		if len(data) < 8 {
+			if len(data) >= 4 {
+				flow.SrcPort = int32(binary.BigEndian.Uint16(data[0:2]))
+				flow.DstPort = int32(binary.BigEndian.Uint16(data[2:4]))
+			}
+			return
+		}
+		flow.SrcPort = int32(binary.BigEndian.Uint16(data[0:2]))
+		flow.DstPort = int32(binary.BigEndian.Uint16(data[2:4]))
+		if flow.DstPort == vxlanPort && tunnelDepth < maxTunnelDepth {
+			decodeVXLAN(flow, data[8:], tunnelDepth)
+		}
+	case ipProtocolGRE:
+		if tunnelDepth < maxTunnelDepth {
+			decodeGRE(flow, data, tunnelDepth)
+		}
+	case ipProtocolICMP, ipProtocolICMPv6:
+		// ICMP type and code are not reported
+	}
+}
+
+// decodeVXLAN decodes a VXLAN header and the encapsulated Ethernet frame.
+func decodeVXLAN(flow *common.Flow, data []byte, tunnelDepth int) {
+	if len(data) < vxlanHeaderSize || data[0]&0x08 == 0 { // the I flag is set for valid VNIs
+		return
+	}
+	vni := binary.BigEndian.Uint32(data[4:8]) >> 8
+	startTunnel(flow, "vxlan", vni)
+	decodeEthernet(flow, data[vxlanHeaderSize:], tunnelDepth+1)
+}
+
+// decodeGRE decodes a GRE header and the encapsulated packet.
+func decodeGRE(flow *common.Flow, data []byte, tunnelDepth int) {
+	if len(data) < 4 {
+		return
+	}
+	flags := binary.BigEndian.Uint16(data[0:2])
+	protocol := binary.BigEndian.Uint16(data[2:4])
+	offset := 4
+	if flags&0x8000 != 0 { // checksum present
+		offset += 4
+	}
+	var key uint32
+	if flags&0x2000 != 0 { // key present
+		if len(data) < offset+4 {
+			return
+		}
+		key = binary.BigEndian.Uint32(data[offset : offset+4])
+		offset += 4
+	}
+	if flags&0x1000 != 0 { // sequence number present
+		offset += 4
+	}
+	if len(data) < offset {
+		return
+	}
+	startTunnel(flow, "gre", key)
+	if protocol == etherTypeTransparentEthernet {
+		decodeEthernet(flow, data[offset:], tunnelDepth+1)
+	} else {
+		decodeEtherType(flow, protocol, data[offset:], tunnelDepth+1)
+	}
+}
+
+// startTunnel keeps the outer addresses of the flow as tunnel addresses, and
+// resets the fields to be decoded from the inner headers.
+func startTunnel(flow *common.Flow, tunnelType string, tunnelID uint32) {
+	flow.TunnelType = tunnelType
+	flow.TunnelID = tunnelID
+	flow.TunnelSrcAddr = flow.SrcAddr
+	flow.TunnelDstAddr = flow.DstAddr
+	flow.SrcPort = 0
+	flow.DstPort = 0
+	flow.TCPFlags = 0
+	flow.IPProtocol = 0
+}
+
+func macAddress(data []byte) uint64 {
+	var mac uint64
+	for _, b := range data {
+		mac = mac<<8 | uint64(b)
+	}
+	return mac
+}
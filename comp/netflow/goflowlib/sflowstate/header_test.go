@@ -0,0 +1,256 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package sflowstate
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+var (
+	testDstMac = []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}
+	testSrcMac = []byte{0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb}
+)
+
+func ethernetHeader(etherType uint16) []byte {
+	data := append(append([]byte{}, testDstMac...), testSrcMac...)
+	return binary.BigEndian.AppendUint16(data, etherType)
+}
+
+func vlanTag(vlanID uint16, etherType uint16) []byte {
+	data := binary.BigEndian.AppendUint16(nil, vlanID)
+	return binary.BigEndian.AppendUint16(data, etherType)
+}
+
+func mplsEntry(label uint32, bottom bool) []byte {
+	entry := label << 12
+	if bottom {
+		entry |= 0x100
+	}
+	return binary.BigEndian.AppendUint32(nil, entry|64)
+}
+
+func ipv4Header(protocol byte, tos byte, src []byte, dst []byte) []byte {
+	data := []byte{0x45, tos, 0, 0, 0, 0, 0, 0, 64, protocol, 0, 0}
+	data = append(data, src...)
+	return append(data, dst...)
+}
+
+func ipv6Header(nextHeader byte, trafficClass byte, src []byte, dst []byte) []byte {
+	data := []byte{0x60 | trafficClass>>4, trafficClass << 4, 0, 0, 0, 0, nextHeader, 64}
+	data = append(data, src...)
+	return append(data, dst...)
+}
+
+func tcpHeader(srcPort uint16, dstPort uint16, flags byte) []byte {
+	data := binary.BigEndian.AppendUint16(nil, srcPort)
+	data = binary.BigEndian.AppendUint16(data, dstPort)
+	data = append(data, make([]byte, 9)...)
+	data = append(data, flags)
+	return append(data, make([]byte, 6)...)
+}
+
+func udpHeader(srcPort uint16, dstPort uint16) []byte {
+	data := binary.BigEndian.AppendUint16(nil, srcPort)
+	data = binary.BigEndian.AppendUint16(data, dstPort)
+	return append(data, 0, 0, 0, 0)
+}
+
+func concat(parts ...[]byte) []byte {
+	var data []byte
+	for _, part := range parts {
+		data = append(data, part...)
+	}
+	return data
+}
+
+var (
+	ipv6Src = []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+	ipv6Dst = []byte{0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2}
+)
+
+func Test_decodeEthernet(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		expectedFlow common.Flow
+	}{
+		{
+			name: "ipv4 tcp",
+			data: concat(ethernetHeader(etherTypeIPv4), ipv4Header(6, 0x10, []byte{10, 0, 0, 1}, []byte{10, 0, 0, 2}), tcpHeader(2000, 443, 0x12)),
+			expectedFlow: common.Flow{
+				DstMac:     0x001122334455,
+				SrcMac:     0x66778899aabb,
+				EtherType:  etherTypeIPv4,
+				Tos:        0x10,
+				SrcAddr:    []byte{10, 0, 0, 1},
+				DstAddr:    []byte{10, 0, 0, 2},
+				IPProtocol: 6,
+				SrcPort:    2000,
+				DstPort:    443,
+				TCPFlags:   0x12,
+			},
+		},
+		{
+			name: "qinq vlan tags",
+			data: concat(ethernetHeader(etherTypeQinQ), vlanTag(100, etherTypeVLAN), vlanTag(200, etherTypeIPv4), ipv4Header(17, 0, []byte{10, 0, 0, 1}, []byte{10, 0, 0, 2}), udpHeader(5000, 53)),
+			expectedFlow: common.Flow{
+				DstMac:     0x001122334455,
+				SrcMac:     0x66778899aabb,
+				VlanID:     100,
+				EtherType:  etherTypeIPv4,
+				SrcAddr:    []byte{10, 0, 0, 1},
+				DstAddr:    []byte{10, 0, 0, 2},
+				IPProtocol: 17,
+				SrcPort:    5000,
+				DstPort:    53,
+			},
+		},
+		{
+			name: "mpls ipv6 with extension headers",
+			data: concat(
+				ethernetHeader(etherTypeMPLS), mplsEntry(16, false), mplsEntry(17, true),
+				ipv6Header(ipProtocolHopByHop, 0xb8, ipv6Src, ipv6Dst),
+				[]byte{ipProtocolDestOptions, 0, 1, 4, 0, 0, 0, 0},                      // hop-by-hop options, 8 bytes
+				[]byte{ipProtocolFragment, 1, 1, 4, 0, 0, 0, 0, 1, 6, 0, 0, 0, 0, 0, 0}, // destination options, 16 bytes
+				[]byte{ipProtocolTCP, 0, 0, 1, 0, 0, 0, 1},                              // first fragment
+				tcpHeader(1234, 80, 0x02),
+			),
+			expectedFlow: common.Flow{
+				DstMac:     0x001122334455,
+				SrcMac:     0x66778899aabb,
+				MPLSLabels: []uint32{16, 17},
+				EtherType:  etherTypeIPv6,
+				Tos:        0xb8,
+				SrcAddr:    ipv6Src,
+				DstAddr:    ipv6Dst,
+				IPProtocol: 6,
+				SrcPort:    1234,
+				DstPort:    80,
+				TCPFlags:   0x02,
+			},
+		},
+		{
+			name: "ipv6 non-first fragment",
+			data: concat(
+				ethernetHeader(etherTypeIPv6),
+				ipv6Header(ipProtocolFragment, 0, ipv6Src, ipv6Dst),
+				[]byte{ipProtocolUDP, 0, 0x05, 0xa8, 0, 0, 0, 1},
+				udpHeader(1, 2),
+			),
+			expectedFlow: common.Flow{
+				DstMac:     0x001122334455,
+				SrcMac:     0x66778899aabb,
+				EtherType:  etherTypeIPv6,
+				SrcAddr:    ipv6Src,
+				DstAddr:    ipv6Dst,
+				IPProtocol: 17,
+			},
+		},
+		{
+			name: "vxlan",
+			data: concat(
+				ethernetHeader(etherTypeVLAN), vlanTag(10, etherTypeIPv4),
+				ipv4Header(17, 0, []byte{192, 168, 0, 1}, []byte{192, 168, 0, 2}), udpHeader(54321, vxlanPort),
+				[]byte{0x08, 0, 0, 0, 0, 0x12, 0x34, 0},
+				ethernetHeader(etherTypeVLAN), vlanTag(20, etherTypeIPv4),
+				ipv4Header(6, 0, []byte{10, 0, 0, 1}, []byte{10, 0, 0, 2}), tcpHeader(2000, 22, 0x10),
+			),
+			expectedFlow: common.Flow{
+				DstMac:        0x001122334455,
+				SrcMac:        0x66778899aabb,
+				VlanID:        10,
+				EtherType:     etherTypeIPv4,
+				SrcAddr:       []byte{10, 0, 0, 1},
+				DstAddr:       []byte{10, 0, 0, 2},
+				IPProtocol:    6,
+				SrcPort:       2000,
+				DstPort:       22,
+				TCPFlags:      0x10,
+				TunnelType:    "vxlan",
+				TunnelID:      0x1234,
+				TunnelSrcAddr: []byte{192, 168, 0, 1},
+				TunnelDstAddr: []byte{192, 168, 0, 2},
+			},
+		},
+		{
+			name: "gre with key",
+			data: concat(
+				ethernetHeader(etherTypeIPv4),
+				ipv4Header(ipProtocolGRE, 0, []byte{192, 168, 0, 1}, []byte{192, 168, 0, 2}),
+				[]byte{0x20, 0, 0x08, 0, 0, 0, 0, 42},
+				ipv4Header(17, 0, []byte{10, 0, 0, 1}, []byte{10, 0, 0, 2}), udpHeader(5000, 53),
+			),
+			expectedFlow: common.Flow{
+				DstMac:        0x001122334455,
+				SrcMac:        0x66778899aabb,
+				EtherType:     etherTypeIPv4,
+				SrcAddr:       []byte{10, 0, 0, 1},
+				DstAddr:       []byte{10, 0, 0, 2},
+				IPProtocol:    17,
+				SrcPort:       5000,
+				DstPort:       53,
+				TunnelType:    "gre",
+				TunnelID:      42,
+				TunnelSrcAddr: []byte{192, 168, 0, 1},
+				TunnelDstAddr: []byte{192, 168, 0, 2},
+			},
+		},
+		{
+			name: "truncated transport header",
+			data: concat(ethernetHeader(etherTypeIPv4), ipv4Header(6, 0, []byte{10, 0, 0, 1}, []byte{10, 0, 0, 2}), []byte{0x07, 0xd0}),
+			expectedFlow: common.Flow{
+				DstMac:     0x001122334455,
+				SrcMac:     0x66778899aabb,
+				EtherType:  etherTypeIPv4,
+				SrcAddr:    []byte{10, 0, 0, 1},
+				DstAddr:    []byte{10, 0, 0, 2},
+				IPProtocol: 6,
+			},
+		},
+		{
+			name: "truncated ethernet header",
+			data: []byte{0x00, 0x11, 0x22},
+		},
+		{
+			name: "arp",
+			data: concat(ethernetHeader(0x0806), make([]byte, 28)),
+			expectedFlow: common.Flow{
+				DstMac:    0x001122334455,
+				SrcMac:    0x66778899aabb,
+				EtherType: 0x0806,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := common.Flow{}
+			decodeEthernet(&flow, tt.data, 0)
+			assert.Equal(t, tt.expectedFlow, flow)
+		})
+	}
+}
+
+func Test_decodeEthernet_truncatedEverywhere(t *testing.T) {
+	data := concat(
+		ethernetHeader(etherTypeVLAN), vlanTag(10, etherTypeIPv4),
+		ipv4Header(17, 0, []byte{192, 168, 0, 1}, []byte{192, 168, 0, 2}), udpHeader(54321, vxlanPort),
+		[]byte{0x08, 0, 0, 0, 0, 0x12, 0x34, 0},
+		ethernetHeader(etherTypeMPLS), mplsEntry(16, true),
+		ipv6Header(ipProtocolHopByHop, 0, ipv6Src, ipv6Dst),
+		[]byte{ipProtocolTCP, 0, 1, 4, 0, 0, 0, 0},
+		tcpHeader(2000, 22, 0x10),
+	)
+	// decoding any truncated header must not panic
+	for i := 0; i <= len(data); i++ {
+		flow := common.Flow{}
+		assert.NotPanics(t, func() { decodeEthernet(&flow, data[:i], 0) })
+	}
+}
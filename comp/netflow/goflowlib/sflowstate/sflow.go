@@ -0,0 +1,216 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+// Package sflowstate provides a sFlow state manager replacing the goflow
+// default producer, to collect counter samples and fully decode sampled
+// packet headers.
+package sflowstate
+
+import (
+	"bytes"
+	"net"
+	"time"
+
+	"github.com/netsampler/goflow2/decoders/sflow"
+	"github.com/netsampler/goflow2/format"
+	"github.com/netsampler/goflow2/utils"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/DataDog/datadog-agent/comp/netflow/common"
+)
+
+// sFlow sampled header protocols
+const (
+	headerProtocolEthernet = 1
+	headerProtocolIPv4     = 11
+	headerProtocolIPv6     = 12
+)
+
+// StateSFlow holds a sFlow producer
+type StateSFlow struct {
+	stopper
+
+	// Format receives *common.Flow for flow samples and *common.SFlowCounterSample for counter samples
+	Format format.FormatInterface
+	Logger utils.Logger
+}
+
+// NewStateSFlow initializes a new sFlow producer
+func NewStateSFlow() *StateSFlow {
+	return &StateSFlow{}
+}
+
+// DecodeFlow decodes a sFlow packet into common.Flow and common.SFlowCounterSample
+func (s *StateSFlow) DecodeFlow(msg interface{}) error {
+	pkt := msg.(utils.BaseMessage)
+	buf := bytes.NewBuffer(pkt.Payload)
+	key := pkt.Src.String()
+
+	ts := uint64(time.Now().UTC().Unix())
+	if pkt.SetTime {
+		ts = uint64(pkt.RecvTime.UTC().Unix())
+	}
+
+	timeTrackStart := time.Now()
+	msgDec, err := sflow.DecodeMessage(buf)
+	if err != nil {
+		errorLabel := "error_decoding"
+		switch err.(type) {
+		case *sflow.ErrorVersion:
+			errorLabel = "error_version"
+		case *sflow.ErrorIPVersion:
+			errorLabel = "error_ip_version"
+		case *sflow.ErrorDataFormat:
+			errorLabel = "error_data_format"
+		}
+		utils.SFlowErrors.With(
+			prometheus.Labels{
+				"router": key,
+				"error":  errorLabel,
+			}).
+			Inc()
+		return err
+	}
+
+	packet, ok := msgDec.(sflow.Packet)
+	if !ok {
+		return nil
+	}
+	s.sendTelemetryMetrics(packet, key)
+
+	var messages []interface{}
+	for _, sample := range packet.Samples {
+		switch sample := sample.(type) {
+		case sflow.FlowSample:
+			messages = append(messages, convertFlowSample(packet, sample.SamplingRate, sample.Input, sample.Output, sample.Records, ts))
+		case sflow.ExpandedFlowSample:
+			messages = append(messages, convertFlowSample(packet, sample.SamplingRate, sample.InputIfValue, sample.OutputIfValue, sample.Records, ts))
+		case sflow.CounterSample:
+			if counterSample := convertCounterSample(packet, sample); counterSample != nil {
+				messages = append(messages, counterSample)
+			}
+		}
+	}
+
+	timeTrackStop := time.Now()
+	utils.DecoderTime.With(
+		prometheus.Labels{
+			"name": "sFlow",
+		}).
+		Observe(float64((timeTrackStop.Sub(timeTrackStart)).Nanoseconds()) / 1000)
+
+	if s.Format == nil {
+		return nil
+	}
+	for _, message := range messages {
+		_, _, err := s.Format.Format(message)
+		if err != nil && s.Logger != nil {
+			s.Logger.Error(err)
+		}
+	}
+	return nil
+}
+
+// convertFlowSample converts the records of a flow sample to a flow
+func convertFlowSample(packet sflow.Packet, samplingRate uint32, input uint32, output uint32, records []sflow.FlowRecord, ts uint64) *common.Flow {
+	flow := &common.Flow{
+		FlowType:        common.TypeSFlow5,
+		SequenceNum:     packet.SequenceNumber,
+		SamplingRate:    uint64(samplingRate),
+		ExporterAddr:    packet.AgentIP,
+		StartTimestamp:  ts,
+		EndTimestamp:    ts,
+		Packets:         1,
+		InputInterface:  input,
+		OutputInterface: output,
+	}
+	for _, record := range records {
+		switch recordData := record.Data.(type) {
+		case sflow.SampledHeader:
+			flow.Bytes = uint64(recordData.FrameLength)
+			switch recordData.Protocol {
+			case headerProtocolEthernet:
+				decodeEthernet(flow, recordData.HeaderData, 0)
+			case headerProtocolIPv4:
+				decodeIPv4(flow, recordData.HeaderData, 0)
+			case headerProtocolIPv6:
+				decodeIPv6(flow, recordData.HeaderData, 0)
+			}
+		case sflow.SampledIPv4:
+			flow.SrcAddr = recordData.Base.SrcIP
+			flow.DstAddr = recordData.Base.DstIP
+			flow.Bytes = uint64(recordData.Base.Length)
+			flow.IPProtocol = recordData.Base.Protocol
+			flow.SrcPort = int32(recordData.Base.SrcPort)
+			flow.DstPort = int32(recordData.Base.DstPort)
+			flow.Tos = recordData.Tos
+			flow.EtherType = etherTypeIPv4
+		case sflow.SampledIPv6:
+			flow.SrcAddr = recordData.Base.SrcIP
+			flow.DstAddr = recordData.Base.DstIP
+			flow.Bytes = uint64(recordData.Base.Length)
+			flow.IPProtocol = recordData.Base.Protocol
+			flow.SrcPort = int32(recordData.Base.SrcPort)
+			flow.DstPort = int32(recordData.Base.DstPort)
+			flow.Tos = recordData.Priority
+			flow.EtherType = etherTypeIPv6
+		case sflow.ExtendedRouter:
+			flow.NextHop = recordData.NextHop
+			flow.SrcMask = recordData.SrcMaskLen
+			flow.DstMask = recordData.DstMaskLen
+		case sflow.ExtendedSwitch:
+			if flow.VlanID == 0 {
+				flow.VlanID = recordData.SrcVlan
+			}
+		}
+	}
+	return flow
+}
+
+// FlowRoutine starts a goflow flow routine
+func (s *StateSFlow) FlowRoutine(workers int, addr string, port int, reuseport bool) error {
+	if err := s.start(); err != nil {
+		return err
+	}
+	return utils.UDPStoppableRoutine(s.stopCh, "sFlow", s.DecodeFlow, workers, addr, port, reuseport, s.Logger)
+}
+
+func (s *StateSFlow) sendTelemetryMetrics(packet sflow.Packet, key string) {
+	agentStr := net.IP(packet.AgentIP).String()
+	utils.SFlowStats.With(
+		prometheus.Labels{
+			"router":  key,
+			"agent":   agentStr,
+			"version": "5",
+		}).
+		Inc()
+
+	for _, sample := range packet.Samples {
+		typeStr := "unknown"
+		countRec := 0
+		switch sample := sample.(type) {
+		case sflow.FlowSample:
+			typeStr = "FlowSample"
+			countRec = len(sample.Records)
+		case sflow.CounterSample:
+			typeStr = "CounterSample"
+			if sample.Header.Format == 4 {
+				typeStr = "Expanded" + typeStr
+			}
+			countRec = len(sample.Records)
+		case sflow.ExpandedFlowSample:
+			typeStr = "ExpandedFlowSample"
+			countRec = len(sample.Records)
+		}
+		labels := prometheus.Labels{
+			"router":  key,
+			"agent":   agentStr,
+			"version": "5",
+			"type":    typeStr,
+		}
+		utils.SFlowSampleStatsSum.With(labels).Inc()
+		utils.SFlowSampleRecordsStatsSum.With(labels).Add(float64(countRec))
+	}
+}
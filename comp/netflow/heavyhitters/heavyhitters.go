@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+// Package heavyhitters provides a sketch tracking the most frequent items of a
+// stream, e.g. the IP addresses sending the most bytes, using bounded memory.
+package heavyhitters
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Item is an item tracked by a Sketch.
+type Item struct {
+	Key string
+	// Count is an upper bound of the total weight of the item.
+	Count uint64
+	// Error is the maximum overestimation of Count.
+	Error uint64
+}
+
+// Sketch implements the Space-Saving algorithm (Metwally et al., 2005).
+// It tracks at most `capacity` items: when a new item is added to a full
+// sketch, it replaces the item with the lowest count and inherits its count.
+// Any item with a total weight higher than `total weight / capacity` is
+// guaranteed to be tracked.
+//
+// Sketch is not safe for concurrent use.
+type Sketch struct {
+	capacity int
+	items    map[string]*entry
+	minHeap  entryHeap
+}
+
+type entry struct {
+	Item
+	index int // index in the heap
+}
+
+// NewSketch returns a Sketch tracking at most capacity items.
+func NewSketch(capacity int) *Sketch {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Sketch{
+		capacity: capacity,
+		items:    make(map[string]*entry, capacity),
+		minHeap:  make(entryHeap, 0, capacity),
+	}
+}
+
+// Add adds the weight to the count of the item.
+func (s *Sketch) Add(key string, weight uint64) {
+	if e, ok := s.items[key]; ok {
+		e.Count += weight
+		heap.Fix(&s.minHeap, e.index)
+		return
+	}
+	if len(s.minHeap) < s.capacity {
+		e := &entry{Item: Item{Key: key, Count: weight}}
+		s.items[key] = e
+		heap.Push(&s.minHeap, e)
+		return
+	}
+	// replace the item with the lowest count
+	e := s.minHeap[0]
+	delete(s.items, e.Key)
+	e.Key = key
+	e.Error = e.Count
+	e.Count += weight
+	s.items[key] = e
+	heap.Fix(&s.minHeap, e.index)
+}
+
+// Top returns the n items with the highest counts, from the highest to the lowest.
+func (s *Sketch) Top(n int) []Item {
+	items := make([]Item, 0, len(s.minHeap))
+	for _, e := range s.minHeap {
+		items = append(items, e.Item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Key < items[j].Key
+	})
+	if n < len(items) {
+		items = items[:n]
+	}
+	return items
+}
+
+// Len returns the number of tracked items.
+func (s *Sketch) Len() int {
+	return len(s.minHeap)
+}
+
+// entryHeap is a min-heap of entries by count, implementing heap.Interface.
+type entryHeap []*entry
+
+func (h entryHeap) Len() int           { return len(h) }
+func (h entryHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+func (h entryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *entryHeap) Push(x any) {
+	e := x.(*entry)
+	e.index = len(*h)
+	*h = append(*h, e)
+}
+
+func (h *entryHeap) Pop() any {
+	old := *h
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return e
+}
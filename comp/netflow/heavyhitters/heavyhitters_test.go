@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package heavyhitters
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSketch_exact(t *testing.T) {
+	s := NewSketch(10)
+	s.Add("a", 10)
+	s.Add("b", 30)
+	s.Add("c", 20)
+	s.Add("a", 15)
+
+	assert.Equal(t, 3, s.Len())
+	assert.Equal(t, []Item{
+		{Key: "b", Count: 30},
+		{Key: "a", Count: 25},
+	}, s.Top(2))
+	assert.Len(t, s.Top(10), 3)
+}
+
+func TestSketch_replaceLowestCount(t *testing.T) {
+	s := NewSketch(2)
+	s.Add("a", 10)
+	s.Add("b", 5)
+	s.Add("c", 1)
+
+	assert.Equal(t, 2, s.Len())
+	assert.Equal(t, []Item{
+		{Key: "a", Count: 10},
+		{Key: "c", Count: 6, Error: 5},
+	}, s.Top(2))
+}
+
+func TestSketch_heavyHittersAreTracked(t *testing.T) {
+	s := NewSketch(20)
+	// 1000 light items interleaved with 3 heavy items
+	for i := 0; i < 1000; i++ {
+		s.Add(fmt.Sprintf("light-%d", i), 1)
+		if i%10 == 0 {
+			s.Add("heavy-1", 30)
+			s.Add("heavy-2", 20)
+			s.Add("heavy-3", 10)
+		}
+	}
+
+	assert.Equal(t, 20, s.Len())
+	top := s.Top(3)
+	assert.Equal(t, "heavy-1", top[0].Key)
+	assert.Equal(t, "heavy-2", top[1].Key)
+	assert.Equal(t, "heavy-3", top[2].Key)
+	for _, item := range top {
+		// counts are overestimated by at most the error
+		assert.LessOrEqual(t, item.Count-item.Error, uint64(3000))
+	}
+	assert.GreaterOrEqual(t, top[0].Count, uint64(3000))
+}
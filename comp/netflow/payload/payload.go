@@ -20,11 +20,15 @@ type Exporter struct {
 
 // Endpoint contains source or destination endpoint details
 type Endpoint struct {
-	IP                 string `json:"ip"`
-	Port               string `json:"port"` // Port number can be zero/positive or `*` (ephemeral port)
-	Mac                string `json:"mac"`
-	Mask               string `json:"mask"`
-	ReverseDNSHostname string `json:"reverse_dns_hostname,omitempty"`
+	IP                 string   `json:"ip"`
+	Port               string   `json:"port"` // Port number can be zero/positive or `*` (ephemeral port)
+	Mac                string   `json:"mac"`
+	Mask               string   `json:"mask"`
+	ReverseDNSHostname string   `json:"reverse_dns_hostname,omitempty"`
+	Country            string   `json:"country,omitempty"`
+	ASN                uint32   `json:"asn,omitempty"`
+	ASOrganization     string   `json:"as_organization,omitempty"`
+	Tags               []string `json:"tags,omitempty"`
 }
 
 // NextHop contains next hop details
@@ -35,6 +39,15 @@ type NextHop struct {
 // Interface contains interface details
 type Interface struct {
 	Index uint32 `json:"index"`
+	Name  string `json:"name,omitempty"`
+}
+
+// Tunnel contains the outer headers details of tunneled flows
+type Tunnel struct {
+	Type          string `json:"type"`
+	ID            uint32 `json:"id,omitempty"`
+	SourceIP      string `json:"source_ip"`
+	DestinationIP string `json:"destination_ip"`
 }
 
 // ObservationPoint contains ingress or egress observation point
@@ -66,6 +79,9 @@ type FlowPayload struct {
 	Host             string           `json:"host"`
 	TCPFlags         []string         `json:"tcp_flags,omitempty"`
 	NextHop          NextHop          `json:"next_hop,omitempty"`
+	VlanID           uint32           `json:"vlan_id,omitempty"`
+	MPLSLabels       []uint32         `json:"mpls_labels,omitempty"`
+	Tunnel           *Tunnel          `json:"tunnel,omitempty"`
 	AdditionalFields AdditionalFields `json:"additional_fields,omitempty"`
 }
 
@@ -101,6 +117,17 @@ func (p FlowPayload) MarshalJSON() ([]byte, error) {
 		fields["tcp_flags"] = p.TCPFlags
 	}
 
+	// omit empty
+	if p.VlanID != 0 {
+		fields["vlan_id"] = p.VlanID
+	}
+	if p.MPLSLabels != nil {
+		fields["mpls_labels"] = p.MPLSLabels
+	}
+	if p.Tunnel != nil {
+		fields["tunnel"] = p.Tunnel
+	}
+
 	// Adding additional fields
 	for k, v := range p.AdditionalFields {
 		if _, ok := fields[k]; ok {
@@ -23,6 +23,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/netflow/flowaggregator"
 	rdnsquerier "github.com/DataDog/datadog-agent/comp/rdnsquerier/def"
 	rdnsquerierimplnone "github.com/DataDog/datadog-agent/comp/rdnsquerier/impl-none"
+	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
 )
 
 type dependencies struct {
@@ -63,7 +64,7 @@ func newServer(lc fx.Lifecycle, deps dependencies) (provides, error) {
 		deps.Logger.Infof("Reverse DNS Enrichment is disabled for NDM NetFlow")
 	}
 
-	flowAgg := flowaggregator.NewFlowAggregator(sender, deps.Forwarder, conf, deps.Hostname.GetSafe(context.Background()), deps.Logger, rdnsQuerier)
+	flowAgg := flowaggregator.NewFlowAggregator(sender, deps.Forwarder, conf, deps.Hostname.GetSafe(context.Background()), deps.Logger, rdnsQuerier, devicemetadata.MonitoredDevices)
 
 	server := &Server{
 		config:  conf,
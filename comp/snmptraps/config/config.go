@@ -6,8 +6,13 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"hash/fnv"
+	"net"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gosnmp/gosnmp"
 
@@ -23,6 +28,10 @@ const (
 	defaultPort        = uint16(9162) // Standard UDP port for traps.
 	defaultStopTimeout = 5
 	packetsChanSize    = 100
+
+	defaultRelayPort    = uint16(162) // Standard UDP port for trap receivers.
+	defaultRelayVersion = "2c"
+	relayTimeout        = 2 * time.Second
 )
 
 // UserV3 contains the definition of one SNMPv3 user with its username and its auth
@@ -36,17 +45,77 @@ type UserV3 struct {
 	PrivProtocol   string `mapstructure:"privProtocol" yaml:"privProtocol"`
 }
 
+// RelayTarget contains the definition of a downstream receiver to which received
+// traps are relayed, with the credentials used to re-encode them and the filters
+// selecting which traps are relayed.
+type RelayTarget struct {
+	Host            string   `mapstructure:"host" yaml:"host"`
+	Port            uint16   `mapstructure:"port" yaml:"port"`
+	Version         string   `mapstructure:"version" yaml:"version"`
+	CommunityString string   `mapstructure:"community_string" yaml:"community_string"`
+	User            string   `mapstructure:"user" yaml:"user"`
+	AuthKey         string   `mapstructure:"authKey" yaml:"authKey"`
+	AuthProtocol    string   `mapstructure:"authProtocol" yaml:"authProtocol"`
+	PrivKey         string   `mapstructure:"privKey" yaml:"privKey"`
+	PrivProtocol    string   `mapstructure:"privProtocol" yaml:"privProtocol"`
+	TrapOIDs        []string `mapstructure:"trap_oids" yaml:"trap_oids"`
+	Sources         []string `mapstructure:"sources" yaml:"sources"`
+}
+
+// Addr returns the host:port address of the relay target.
+func (t *RelayTarget) Addr() string {
+	return net.JoinHostPort(t.Host, strconv.Itoa(int(t.Port)))
+}
+
+func (t *RelayTarget) setDefaults() error {
+	if t.Host == "" {
+		return errors.New("host is required")
+	}
+	if t.Port == 0 {
+		t.Port = defaultRelayPort
+	}
+	if t.Version == "" {
+		t.Version = defaultRelayVersion
+	}
+	version, err := parseRelayVersion(t.Version)
+	if err != nil {
+		return err
+	}
+	if version == gosnmp.Version3 {
+		if t.User == "" {
+			return errors.New("user is required for SNMPv3 targets")
+		}
+	} else if t.CommunityString == "" {
+		return errors.New("community_string is required for SNMPv1 and SNMPv2c targets")
+	}
+	return nil
+}
+
+func parseRelayVersion(version string) (gosnmp.SnmpVersion, error) {
+	switch strings.ToLower(version) {
+	case "1":
+		return gosnmp.Version1, nil
+	case "2", "2c":
+		return gosnmp.Version2c, nil
+	case "3":
+		return gosnmp.Version3, nil
+	default:
+		return 0, fmt.Errorf("unsupported SNMP version: %s", version)
+	}
+}
+
 // TrapsConfig contains configuration for SNMP trap listeners.
 // YAML field tags provided for test marshalling purposes.
 type TrapsConfig struct {
-	Enabled               bool     `mapstructure:"enabled" yaml:"enabled"`
-	Port                  uint16   `mapstructure:"port" yaml:"port"`
-	Users                 []UserV3 `mapstructure:"users" yaml:"users"`
-	CommunityStrings      []string `mapstructure:"community_strings" yaml:"community_strings"`
-	BindHost              string   `mapstructure:"bind_host" yaml:"bind_host"`
-	StopTimeout           int      `mapstructure:"stop_timeout" yaml:"stop_timeout"`
-	Namespace             string   `mapstructure:"namespace" yaml:"namespace"`
-	authoritativeEngineID string   `mapstructure:"-" yaml:"-"`
+	Enabled               bool          `mapstructure:"enabled" yaml:"enabled"`
+	Port                  uint16        `mapstructure:"port" yaml:"port"`
+	Users                 []UserV3      `mapstructure:"users" yaml:"users"`
+	CommunityStrings      []string      `mapstructure:"community_strings" yaml:"community_strings"`
+	BindHost              string        `mapstructure:"bind_host" yaml:"bind_host"`
+	StopTimeout           int           `mapstructure:"stop_timeout" yaml:"stop_timeout"`
+	Namespace             string        `mapstructure:"namespace" yaml:"namespace"`
+	RelayTargets          []RelayTarget `mapstructure:"relay_targets" yaml:"relay_targets"`
+	authoritativeEngineID string        `mapstructure:"-" yaml:"-"`
 }
 
 // ReadConfig builds the traps configuration from the Agent configuration.
@@ -98,6 +167,12 @@ func (c *TrapsConfig) SetDefaults(host string, namespace string) error {
 		return fmt.Errorf("invalid config: %w", err)
 	}
 
+	for i := range c.RelayTargets {
+		if err := c.RelayTargets[i].setDefaults(); err != nil {
+			return fmt.Errorf("invalid config: relay target %d: %w", i, err)
+		}
+	}
+
 	return nil
 }
 
@@ -160,6 +235,66 @@ func (c *TrapsConfig) BuildSNMPParams(logger log.Component) (*gosnmp.GoSNMP, err
 	}, nil
 }
 
+// BuildRelayParams returns the GoSNMP params used to relay traps to the given
+// target. The Agent is the authoritative engine of the SNMPv3 traps it sends.
+func (c *TrapsConfig) BuildRelayParams(target RelayTarget, logger log.Component) (*gosnmp.GoSNMP, error) {
+	var snmpLogger gosnmp.Logger
+	if logger != nil {
+		snmpLogger = gosnmp.NewLogger(snmplog.New(logger))
+	}
+	version, err := parseRelayVersion(target.Version)
+	if err != nil {
+		return nil, err
+	}
+	params := &gosnmp.GoSNMP{
+		Target:    target.Host,
+		Port:      target.Port,
+		Transport: "udp",
+		Version:   version,
+		Community: target.CommunityString,
+		Timeout:   relayTimeout, // Must be non-zero when sending traps.
+		Retries:   1,            // Must be non-zero when sending traps.
+		Logger:    snmpLogger,
+	}
+	if version != gosnmp.Version3 {
+		return params, nil
+	}
+
+	authProtocolName, privProtocolName := target.AuthProtocol, target.PrivProtocol
+	if target.AuthKey != "" && authProtocolName == "" {
+		authProtocolName = "md5"
+	}
+	if target.PrivKey != "" && privProtocolName == "" {
+		privProtocolName = "des"
+	}
+	authProtocol, err := gosnmplib.GetAuthProtocol(authProtocolName)
+	if err != nil {
+		return nil, err
+	}
+	privProtocol, err := gosnmplib.GetPrivProtocol(privProtocolName)
+	if err != nil {
+		return nil, err
+	}
+	msgFlags := gosnmp.NoAuthNoPriv
+	if privProtocol != gosnmp.NoPriv {
+		msgFlags = gosnmp.AuthPriv
+	} else if authProtocol != gosnmp.NoAuth {
+		msgFlags = gosnmp.AuthNoPriv
+	}
+
+	params.MsgFlags = msgFlags
+	params.SecurityModel = gosnmp.UserSecurityModel
+	params.SecurityParameters = &gosnmp.UsmSecurityParameters{
+		UserName:                 target.User,
+		AuthoritativeEngineID:    c.authoritativeEngineID,
+		AuthenticationProtocol:   authProtocol,
+		AuthenticationPassphrase: target.AuthKey,
+		PrivacyProtocol:          privProtocol,
+		PrivacyPassphrase:        target.PrivKey,
+	}
+	return params, nil
+}
+
 // GetPacketChannelSize returns the default size for the packets channel
 func (c *TrapsConfig) GetPacketChannelSize() int {
 	return packetsChanSize
@@ -290,3 +290,58 @@ func TestNamespaceSetBothGloballyAndLocally(t *testing.T) {
 
 	assert.Equal(t, "bar", config.Namespace)
 }
+
+func TestRelayTargets(t *testing.T) {
+	config := fxutil.Test[*TrapsConfig](t,
+		testOptions(t),
+		withConfig(t, &TrapsConfig{
+			RelayTargets: []RelayTarget{
+				{Host: "nms.local", CommunityString: "public"},
+				{Host: "10.0.0.1", Port: 1162, Version: "3", User: "user", AuthKey: "password", PrivKey: "password", PrivProtocol: "AES"},
+			},
+		}, ""),
+	)
+	require.Len(t, config.RelayTargets, 2)
+	assert.Equal(t, RelayTarget{Host: "nms.local", Port: 162, Version: "2c", CommunityString: "public"}, config.RelayTargets[0])
+	assert.Equal(t, "nms.local:162", config.RelayTargets[0].Addr())
+
+	params, err := config.BuildRelayParams(config.RelayTargets[0], nil)
+	require.NoError(t, err)
+	assert.Equal(t, "nms.local", params.Target)
+	assert.Equal(t, uint16(162), params.Port)
+	assert.Equal(t, gosnmp.Version2c, params.Version)
+	assert.Equal(t, "public", params.Community)
+
+	params, err = config.BuildRelayParams(config.RelayTargets[1], nil)
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.Version3, params.Version)
+	assert.Equal(t, gosnmp.AuthPriv, params.MsgFlags)
+	assert.Equal(t, &gosnmp.UsmSecurityParameters{
+		UserName:                 "user",
+		AuthoritativeEngineID:    expectedEngineID,
+		AuthenticationProtocol:   gosnmp.MD5,
+		AuthenticationPassphrase: "password",
+		PrivacyProtocol:          gosnmp.AES,
+		PrivacyPassphrase:        "password",
+	}, params.SecurityParameters)
+}
+
+func TestInvalidRelayTargets(t *testing.T) {
+	tests := []struct {
+		name          string
+		target        RelayTarget
+		expectedError string
+	}{
+		{"missing host", RelayTarget{CommunityString: "public"}, "relay target 0: host is required"},
+		{"invalid version", RelayTarget{Host: "nms", Version: "4"}, "relay target 0: unsupported SNMP version: 4"},
+		{"missing community", RelayTarget{Host: "nms", Version: "1"}, "relay target 0: community_string is required for SNMPv1 and SNMPv2c targets"},
+		{"missing user", RelayTarget{Host: "nms", Version: "3"}, "relay target 0: user is required for SNMPv3 targets"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &TrapsConfig{RelayTargets: []RelayTarget{tt.target}}
+			err := c.SetDefaults("host", "default")
+			assert.EqualError(t, err, "invalid config: "+tt.expectedError)
+		})
+	}
+}
@@ -4,7 +4,8 @@
 // Copyright 2023-present Datadog, Inc.
 
 // Package forwarder defines a component that receives trap data from the
-// listener component, formats it properly, and sends it to the backend.
+// listener component, formats it properly, and sends it to the backend. Traps
+// can also be relayed to downstream receivers.
 package forwarder
 
 // team: ndm-core
@@ -34,11 +34,12 @@ func Module() fxutil.Module {
 // trapForwarder consumes SNMP packets, formats traps and send them as EventPlatformEvents
 // The trapForwarder is an intermediate step between the listener and the epforwarder in order to limit the processing of the listener
 // to the minimum. The forwarder process payloads received by the listener via the trapsIn channel, formats them and finally
-// give them to the epforwarder for sending it to Datadog.
+// give them to the epforwarder for sending it to Datadog. Traps are also relayed to the configured downstream receivers.
 type trapForwarder struct {
 	trapsIn   packet.PacketsChannel
 	formatter formatter.Component
 	sender    sender.Sender
+	relay     *trapRelay
 	stopChan  chan struct{}
 	logger    log.Component
 }
@@ -58,14 +59,19 @@ func newTrapForwarder(lc fx.Lifecycle, dep dependencies) (forwarder.Component, e
 	if err != nil {
 		return nil, err
 	}
+	conf := dep.Config.Get()
+	relay, err := newTrapRelay(conf, sender, dep.Logger)
+	if err != nil {
+		return nil, err
+	}
 	tf := &trapForwarder{
 		trapsIn:   dep.Listener.Packets(),
 		formatter: dep.Formatter,
 		sender:    sender,
+		relay:     relay,
 		stopChan:  make(chan struct{}, 1),
 		logger:    dep.Logger,
 	}
-	conf := dep.Config.Get()
 	if conf.Enabled {
 		lc.Append(fx.Hook{
 			OnStart: func(_ context.Context) error {
@@ -85,6 +91,7 @@ func newTrapForwarder(lc fx.Lifecycle, dep dependencies) (forwarder.Component, e
 // Start the TrapForwarder instance. Need to Stop it manually.
 func (tf *trapForwarder) Start() {
 	tf.logger.Info("Starting TrapForwarder")
+	tf.relay.start()
 	go tf.run()
 }
 
@@ -100,6 +107,7 @@ func (tf *trapForwarder) Stop() {
 func (tf *trapForwarder) run() {
 	flushTicker := time.NewTicker(10 * time.Second)
 	defer flushTicker.Stop()
+	defer tf.relay.close()
 	for {
 		select {
 		case <-tf.stopChan:
@@ -107,6 +115,7 @@ func (tf *trapForwarder) run() {
 			return
 		case packet := <-tf.trapsIn:
 			tf.sendTrap(packet)
+			tf.relay.relay(packet)
 		case <-flushTicker.C:
 			tf.sender.Commit() // Commit metrics
 		}
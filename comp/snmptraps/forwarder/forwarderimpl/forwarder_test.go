@@ -6,15 +6,18 @@
 package forwarderimpl
 
 import (
+	"fmt"
 	"net"
 	"testing"
 	"time"
 
 	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/fx"
 
 	"github.com/DataDog/datadog-agent/comp/forwarder/eventplatform"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/config"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/config/configimpl"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/formatter"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/formatter/formatterimpl"
@@ -24,6 +27,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
 	"github.com/DataDog/datadog-agent/comp/snmptraps/senderhelper"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	ndmtestutils "github.com/DataDog/datadog-agent/pkg/networkdevice/testutils"
 	"github.com/DataDog/datadog-agent/pkg/util/fxutil"
 )
 
@@ -37,7 +41,7 @@ type services struct {
 	Forwarder forwarder.Component
 }
 
-func setUp(t *testing.T) *services {
+func setUp(t *testing.T, opts ...fx.Option) *services {
 	t.Helper()
 	s := fxutil.Test[services](t,
 		configimpl.MockModule(),
@@ -45,6 +49,7 @@ func setUp(t *testing.T) *services {
 		formatterimpl.MockModule(),
 		listenerimpl.MockModule(),
 		Module(),
+		fx.Options(opts...),
 	)
 	return &s
 }
@@ -98,3 +103,62 @@ func TestForwarderTelemetry(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 	s.Sender.AssertMetric(t, "Count", "datadog.snmp_traps.forwarded", 1, "", []string{"snmp_device:1.1.1.1", "device_namespace:totoro", "snmp_version:2"})
 }
+
+// startReceiver starts a trap listener standing for a downstream NMS, and
+// returns the channel of the traps it receives.
+func startReceiver(t *testing.T, port uint16) chan *gosnmp.SnmpPacket {
+	received := make(chan *gosnmp.SnmpPacket, 10)
+	receiver := gosnmp.NewTrapListener()
+	receiver.Params = &gosnmp.GoSNMP{Port: port, Transport: "udp", Version: gosnmp.Version1, Logger: gosnmp.NewLogger(nil)}
+	receiver.OnNewTrap = func(p *gosnmp.SnmpPacket, _ *net.UDPAddr) {
+		received <- p
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- receiver.Listen(fmt.Sprintf("127.0.0.1:%d", port))
+	}()
+	select {
+	case <-receiver.Listening():
+	case err := <-errs:
+		require.NoError(t, err)
+	}
+	t.Cleanup(receiver.Close)
+	return received
+}
+
+func TestTrapsAreRelayed(t *testing.T) {
+	port, err := ndmtestutils.GetFreePort()
+	require.NoError(t, err)
+	received := startReceiver(t, port)
+
+	s := setUp(t, fx.Replace(&config.TrapsConfig{
+		Enabled: true,
+		RelayTargets: []config.RelayTarget{
+			{Host: "127.0.0.1", Port: port, Version: "1", CommunityString: "nms"},
+			{Host: "127.0.0.1", Port: port, CommunityString: "filtered", TrapOIDs: []string{"1.3.6.1.6.3.1.1.5"}},
+			{Host: "127.0.0.1", Port: port, CommunityString: "other-source", Sources: []string{"10.0.0.0/8"}},
+		},
+	}))
+	s.Listener.Send(makeSnmpPacket(packet.NetSNMPExampleHeartbeatNotification))
+
+	select {
+	case trap := <-received:
+		assert.Equal(t, gosnmp.Version1, trap.Version)
+		assert.Equal(t, "nms", trap.Community)
+		assert.Equal(t, ".1.3.6.1.4.1.8072.2.3", trap.Enterprise)
+		assert.Equal(t, 6, trap.GenericTrap)
+		assert.Equal(t, 1, trap.SpecificTrap)
+		assert.Equal(t, uint(1000), trap.Timestamp)
+		assert.Equal(t, "1.1.1.1", trap.AgentAddress)
+		require.Len(t, trap.Variables, 2)
+		assert.Equal(t, ".1.3.6.1.4.1.8072.2.3.2.1", trap.Variables[0].Name)
+	case <-time.After(time.Second):
+		require.Fail(t, "trap not relayed")
+	}
+	select {
+	case trap := <-received:
+		assert.Fail(t, "unexpected trap relayed", "community: %s", trap.Community)
+	case <-time.After(100 * time.Millisecond):
+	}
+	s.Sender.AssertMetric(t, "Count", "datadog.snmp_traps.relayed", 1, "", []string{"snmp_device:1.1.1.1", "device_namespace:totoro", "snmp_version:2", fmt.Sprintf("relay_target:127.0.0.1:%d", port)})
+}
@@ -0,0 +1,343 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package forwarderimpl
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/config"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+)
+
+const (
+	sysUpTimeInstanceOID  = "1.3.6.1.2.1.1.3.0"
+	snmpTrapOID           = "1.3.6.1.6.3.1.1.4.1.0"
+	snmpTrapEnterpriseOID = "1.3.6.1.6.3.1.1.4.3.0"
+	snmpTrapAddressOID    = "1.3.6.1.6.3.18.1.3.0"
+	genericTrapOID        = "1.3.6.1.6.3.1.1.5"
+
+	telemetryRelayed     = "datadog.snmp_traps.relayed"
+	telemetryRelayErrors = "datadog.snmp_traps.relay_errors"
+	telemetryRelayDrops  = "datadog.snmp_traps.relay_dropped"
+
+	// relayQueueSize is the number of traps waiting to be relayed above which
+	// traps are dropped, so that slow targets don't hold up forwarding to Datadog.
+	relayQueueSize = 1000
+)
+
+// relayTarget is a downstream receiver to which traps are relayed
+type relayTarget struct {
+	addr     string
+	params   *gosnmp.GoSNMP
+	trapOIDs []string
+	sources  []netip.Prefix
+}
+
+// trapRelay re-emits received traps to downstream receivers, re-encoded with
+// the SNMP version and the credentials of each of them. Informs are relayed as
+// traps since the relay doesn't wait for acknowledgements. Traps are sent from
+// their own goroutine, and dropped when too many are waiting to be sent.
+type trapRelay struct {
+	targets []*relayTarget
+	queue   chan *packet.SnmpPacket
+	done    chan struct{}
+	sender  sender.Sender
+	logger  log.Component
+}
+
+func newTrapRelay(conf *config.TrapsConfig, sender sender.Sender, logger log.Component) (*trapRelay, error) {
+	relay := &trapRelay{
+		queue:  make(chan *packet.SnmpPacket, relayQueueSize),
+		done:   make(chan struct{}),
+		sender: sender,
+		logger: logger,
+	}
+	for _, targetConfig := range conf.RelayTargets {
+		params, err := conf.BuildRelayParams(targetConfig, logger)
+		if err != nil {
+			return nil, fmt.Errorf("invalid relay target %s: %w", targetConfig.Addr(), err)
+		}
+		target := &relayTarget{addr: targetConfig.Addr(), params: params}
+		for _, oid := range targetConfig.TrapOIDs {
+			oid = oidresolver.NormalizeOID(oid)
+			if !oidresolver.IsValidOID(oid) {
+				return nil, fmt.Errorf("invalid relay target %s: invalid trap OID %q", target.addr, oid)
+			}
+			target.trapOIDs = append(target.trapOIDs, oid)
+		}
+		for _, source := range targetConfig.Sources {
+			prefix, err := parseSource(source)
+			if err != nil {
+				return nil, fmt.Errorf("invalid relay target %s: %w", target.addr, err)
+			}
+			target.sources = append(target.sources, prefix)
+		}
+		relay.targets = append(relay.targets, target)
+	}
+	return relay, nil
+}
+
+// parseSource parses an IP address or a CIDR range
+func parseSource(source string) (netip.Prefix, error) {
+	if strings.Contains(source, "/") {
+		prefix, err := netip.ParsePrefix(source)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid source %q: %w", source, err)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(source)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid source %q: %w", source, err)
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// matches returns whether a trap with the given OID and sent by the given
+// address must be relayed to the target.
+func (t *relayTarget) matches(trapOID string, source net.IP) bool {
+	if len(t.trapOIDs) > 0 {
+		matched := false
+		for _, oid := range t.trapOIDs {
+			if trapOID == oid || strings.HasPrefix(trapOID, oid+".") {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(t.sources) > 0 {
+		addr, ok := netip.AddrFromSlice(source)
+		if !ok {
+			return false
+		}
+		addr = addr.Unmap()
+		for _, prefix := range t.sources {
+			if prefix.Contains(addr) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func (t *relayTarget) send(trap gosnmp.SnmpTrap) error {
+	// connect lazily, so that a target that can't be resolved at startup is retried
+	if t.params.Conn == nil {
+		if err := t.params.Connect(); err != nil {
+			return err
+		}
+	}
+	_, err := t.params.SendTrap(trap)
+	return err
+}
+
+func (t *relayTarget) close() {
+	if t.params.Conn != nil {
+		t.params.Conn.Close()
+		t.params.Conn = nil
+	}
+}
+
+// start starts sending the queued traps, until the relay is closed.
+func (r *trapRelay) start() {
+	if len(r.targets) == 0 {
+		close(r.done)
+		return
+	}
+	go func() {
+		defer close(r.done)
+		for p := range r.queue {
+			r.send(p)
+		}
+		for _, target := range r.targets {
+			target.close()
+		}
+	}()
+}
+
+// relay queues the trap to be sent to the targets, or drops it if the queue is full.
+func (r *trapRelay) relay(p *packet.SnmpPacket) {
+	if len(r.targets) == 0 {
+		return
+	}
+	select {
+	case r.queue <- p:
+	default:
+		r.logger.Debugf("dropping trap from %s: too many traps waiting to be relayed", p.Addr.IP)
+		r.sender.Count(telemetryRelayDrops, 1, "", p.GetTags())
+	}
+}
+
+// close stops the relay once the queued traps are sent, and closes the connections to the targets.
+func (r *trapRelay) close() {
+	close(r.queue)
+	<-r.done
+}
+
+// send sends the trap to all the targets whose filters match it.
+func (r *trapRelay) send(p *packet.SnmpPacket) {
+	trapOID, err := getTrapOID(p.Content)
+	if err != nil {
+		r.logger.Debugf("unable to relay trap from %s: %s", p.Addr.IP, err)
+		return
+	}
+	for _, target := range r.targets {
+		if !target.matches(trapOID, p.Addr.IP) {
+			continue
+		}
+		tags := append(p.GetTags(), "relay_target:"+target.addr)
+		if err := target.send(convertTrap(p, target.params.Version)); err != nil {
+			r.logger.Debugf("failed to relay trap %s from %s to %s: %s", trapOID, p.Addr.IP, target.addr, err)
+			r.sender.Count(telemetryRelayErrors, 1, "", tags)
+			continue
+		}
+		r.sender.Count(telemetryRelayed, 1, "", tags)
+	}
+}
+
+// getTrapOID returns the trap OID of a packet. The OID of SNMPv1 traps is
+// built from their enterprise and generic and specific trap numbers.
+func getTrapOID(content *gosnmp.SnmpPacket) (string, error) {
+	if content.Version == gosnmp.Version1 {
+		if content.GenericTrap == 6 {
+			return fmt.Sprintf("%s.0.%d", oidresolver.NormalizeOID(content.Enterprise), content.SpecificTrap), nil
+		}
+		return fmt.Sprintf("%s.%d", genericTrapOID, content.GenericTrap+1), nil
+	}
+	if len(content.Variables) < 2 || oidresolver.NormalizeOID(content.Variables[1].Name) != snmpTrapOID {
+		return "", fmt.Errorf("missing %s variable", snmpTrapOID)
+	}
+	switch value := content.Variables[1].Value.(type) {
+	case string:
+		return oidresolver.NormalizeOID(value), nil
+	case []byte:
+		return oidresolver.NormalizeOID(string(value)), nil
+	default:
+		return "", fmt.Errorf("expected snmpTrapOID to be a string (got %v of type %T)", value, value)
+	}
+}
+
+// convertTrap re-encodes a received trap for the given SNMP version, following
+// the translation rules of RFC 3584.
+func convertTrap(p *packet.SnmpPacket, version gosnmp.SnmpVersion) gosnmp.SnmpTrap {
+	content := p.Content
+	if version == gosnmp.Version1 {
+		if content.Version == gosnmp.Version1 {
+			return gosnmp.SnmpTrap{
+				Variables:    content.Variables,
+				Enterprise:   content.Enterprise,
+				AgentAddress: content.AgentAddress,
+				GenericTrap:  content.GenericTrap,
+				SpecificTrap: content.SpecificTrap,
+				Timestamp:    content.Timestamp,
+			}
+		}
+		return toV1Trap(p)
+	}
+	if content.Version != gosnmp.Version1 {
+		return gosnmp.SnmpTrap{Variables: content.Variables}
+	}
+	return toV2Trap(p)
+}
+
+// toV2Trap translates a SNMPv1 trap into a SNMPv2 notification, see RFC 3584 section 3.1.
+func toV2Trap(p *packet.SnmpPacket) gosnmp.SnmpTrap {
+	content := p.Content
+	trapOID, _ := getTrapOID(content)
+	agentAddress := content.AgentAddress
+	if agentAddress == "" {
+		agentAddress = sourceAddress(p)
+	}
+	variables := make([]gosnmp.SnmpPDU, 0, len(content.Variables)+4)
+	variables = append(variables,
+		gosnmp.SnmpPDU{Name: sysUpTimeInstanceOID, Type: gosnmp.TimeTicks, Value: uint32(content.Timestamp)},
+		gosnmp.SnmpPDU{Name: snmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: trapOID},
+	)
+	variables = append(variables, content.Variables...)
+	variables = append(variables,
+		gosnmp.SnmpPDU{Name: snmpTrapAddressOID, Type: gosnmp.IPAddress, Value: agentAddress},
+		gosnmp.SnmpPDU{Name: snmpTrapEnterpriseOID, Type: gosnmp.ObjectIdentifier, Value: oidresolver.NormalizeOID(content.Enterprise)},
+	)
+	return gosnmp.SnmpTrap{Variables: variables}
+}
+
+// toV1Trap translates a SNMPv2 notification into a SNMPv1 trap, see RFC 3584 section 3.2.
+// Counter64 variables can't be represented in SNMPv1 and are dropped.
+func toV1Trap(p *packet.SnmpPacket) gosnmp.SnmpTrap {
+	content := p.Content
+	trap := gosnmp.SnmpTrap{AgentAddress: sourceAddress(p)}
+	trapOID, _ := getTrapOID(content)
+	var enterprise string
+	for i, variable := range content.Variables {
+		switch oidresolver.NormalizeOID(variable.Name) {
+		case sysUpTimeInstanceOID:
+			if uptime, ok := variable.Value.(uint32); ok && i == 0 {
+				trap.Timestamp = uint(uptime)
+				continue
+			}
+		case snmpTrapOID:
+			if i == 1 {
+				continue
+			}
+		case snmpTrapAddressOID:
+			if address, ok := variable.Value.(string); ok {
+				trap.AgentAddress = address
+			}
+		case snmpTrapEnterpriseOID:
+			if oid, ok := variable.Value.(string); ok {
+				enterprise = oidresolver.NormalizeOID(oid)
+			}
+		}
+		if variable.Type == gosnmp.Counter64 {
+			continue
+		}
+		trap.Variables = append(trap.Variables, variable)
+	}
+
+	if generic, ok := strings.CutPrefix(trapOID, genericTrapOID+"."); ok {
+		if n, err := strconv.Atoi(generic); err == nil && n >= 1 && n <= 6 {
+			if enterprise == "" {
+				enterprise = genericTrapOID
+			}
+			trap.Enterprise = enterprise
+			trap.GenericTrap = n - 1
+			return trap
+		}
+	}
+
+	// enterprise specific trap: the enterprise is the trap OID without its
+	// last sub-identifier, and without the next-to-last one when it is 0
+	trap.GenericTrap = 6
+	trap.Enterprise = trapOID
+	if i := strings.LastIndexByte(trapOID, '.'); i >= 0 {
+		specific, _ := strconv.Atoi(trapOID[i+1:])
+		trap.SpecificTrap = specific
+		trap.Enterprise = strings.TrimSuffix(trapOID[:i], ".0")
+	}
+	return trap
+}
+
+// sourceAddress returns the address of the device that sent a trap, SNMPv1
+// agent addresses can only be IPv4 addresses.
+func sourceAddress(p *packet.SnmpPacket) string {
+	if ip := p.Addr.IP.To4(); ip != nil {
+		return ip.String()
+	}
+	return "0.0.0.0"
+}
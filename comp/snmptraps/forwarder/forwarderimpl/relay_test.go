@@ -0,0 +1,192 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package forwarderimpl
+
+import (
+	"net"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/packet"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+)
+
+func TestConvertTrap(t *testing.T) {
+	v2Heartbeat := &gosnmp.SnmpPacket{
+		Version: gosnmp.Version2c,
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(1000)},
+			{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.8072.2.3.0.1"},
+			{Name: ".1.3.6.1.4.1.8072.2.3.2.1", Type: gosnmp.Integer, Value: 1024},
+			{Name: ".1.3.6.1.4.1.8072.2.3.2.3", Type: gosnmp.Counter64, Value: uint64(1 << 40)},
+		},
+	}
+	v2LinkDown := &gosnmp.SnmpPacket{
+		Version: gosnmp.Version2c,
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(2000)},
+			{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+			{Name: ".1.3.6.1.2.1.2.2.1.1", Type: gosnmp.Integer, Value: 2},
+			{Name: ".1.3.6.1.6.3.18.1.3.0", Type: gosnmp.IPAddress, Value: "10.0.0.5"},
+		},
+	}
+	v1LinkDown := &gosnmp.SnmpPacket{
+		Version: gosnmp.Version1,
+		SnmpTrap: gosnmp.SnmpTrap{
+			AgentAddress: "10.0.0.6",
+			Enterprise:   ".1.3.6.1.4.1.9",
+			GenericTrap:  2,
+			Timestamp:    3000,
+		},
+		Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.2.1.2.2.1.1", Type: gosnmp.Integer, Value: 2},
+		},
+	}
+	v1Specific := &gosnmp.SnmpPacket{
+		Version: gosnmp.Version1,
+		SnmpTrap: gosnmp.SnmpTrap{
+			Enterprise:   ".1.3.6.1.2.1.118",
+			GenericTrap:  6,
+			SpecificTrap: 2,
+			Timestamp:    4000,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		content  *gosnmp.SnmpPacket
+		version  gosnmp.SnmpVersion
+		expected gosnmp.SnmpTrap
+	}{
+		{
+			name:     "v2 to v3",
+			content:  v2Heartbeat,
+			version:  gosnmp.Version3,
+			expected: gosnmp.SnmpTrap{Variables: v2Heartbeat.Variables},
+		},
+		{
+			name:    "v2 enterprise specific to v1",
+			content: v2Heartbeat,
+			version: gosnmp.Version1,
+			expected: gosnmp.SnmpTrap{
+				AgentAddress: "1.1.1.1",
+				Enterprise:   "1.3.6.1.4.1.8072.2.3",
+				GenericTrap:  6,
+				SpecificTrap: 1,
+				Timestamp:    1000,
+				Variables: []gosnmp.SnmpPDU{
+					{Name: ".1.3.6.1.4.1.8072.2.3.2.1", Type: gosnmp.Integer, Value: 1024},
+				},
+			},
+		},
+		{
+			name:    "v2 generic to v1",
+			content: v2LinkDown,
+			version: gosnmp.Version1,
+			expected: gosnmp.SnmpTrap{
+				AgentAddress: "10.0.0.5",
+				Enterprise:   "1.3.6.1.6.3.1.1.5",
+				GenericTrap:  2,
+				Timestamp:    2000,
+				Variables:    v2LinkDown.Variables[2:],
+			},
+		},
+		{
+			name:    "v1 generic to v2",
+			content: v1LinkDown,
+			version: gosnmp.Version2c,
+			expected: gosnmp.SnmpTrap{
+				Variables: []gosnmp.SnmpPDU{
+					{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(3000)},
+					{Name: "1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: "1.3.6.1.6.3.1.1.5.3"},
+					{Name: ".1.3.6.1.2.1.2.2.1.1", Type: gosnmp.Integer, Value: 2},
+					{Name: "1.3.6.1.6.3.18.1.3.0", Type: gosnmp.IPAddress, Value: "10.0.0.6"},
+					{Name: "1.3.6.1.6.3.1.1.4.3.0", Type: gosnmp.ObjectIdentifier, Value: "1.3.6.1.4.1.9"},
+				},
+			},
+		},
+		{
+			name:    "v1 specific to v2",
+			content: v1Specific,
+			version: gosnmp.Version2c,
+			expected: gosnmp.SnmpTrap{
+				Variables: []gosnmp.SnmpPDU{
+					{Name: "1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(4000)},
+					{Name: "1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: "1.3.6.1.2.1.118.0.2"},
+					{Name: "1.3.6.1.6.3.18.1.3.0", Type: gosnmp.IPAddress, Value: "1.1.1.1"},
+					{Name: "1.3.6.1.6.3.1.1.4.3.0", Type: gosnmp.ObjectIdentifier, Value: "1.3.6.1.2.1.118"},
+				},
+			},
+		},
+		{
+			name:    "v1 to v1",
+			content: v1Specific,
+			version: gosnmp.Version1,
+			expected: gosnmp.SnmpTrap{
+				Enterprise:   ".1.3.6.1.2.1.118",
+				GenericTrap:  6,
+				SpecificTrap: 2,
+				Timestamp:    4000,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &packet.SnmpPacket{Content: tt.content, Addr: simpleUDPAddr}
+			assert.Equal(t, tt.expected, convertTrap(p, tt.version))
+		})
+	}
+}
+
+func TestRelayTargetMatches(t *testing.T) {
+	sources := []string{"10.0.0.0/8", "192.168.1.1", "2001:db8::/32"}
+	target := &relayTarget{trapOIDs: []string{"1.3.6.1.6.3.1.1.5", "1.3.6.1.4.1.9.9.41.2.0.1"}}
+	for _, source := range sources {
+		prefix, err := parseSource(source)
+		require.NoError(t, err)
+		target.sources = append(target.sources, prefix)
+	}
+
+	assert.True(t, target.matches("1.3.6.1.6.3.1.1.5.3", net.ParseIP("10.1.2.3")))
+	assert.True(t, target.matches("1.3.6.1.4.1.9.9.41.2.0.1", net.ParseIP("192.168.1.1")))
+	assert.True(t, target.matches("1.3.6.1.6.3.1.1.5.1", net.ParseIP("2001:db8::1")))
+	assert.False(t, target.matches("1.3.6.1.6.3.1.1.50", net.ParseIP("10.1.2.3")))
+	assert.False(t, target.matches("1.3.6.1.4.1.8072.2.3.0.1", net.ParseIP("10.1.2.3")))
+	assert.False(t, target.matches("1.3.6.1.6.3.1.1.5.3", net.ParseIP("192.168.1.2")))
+
+	assert.True(t, (&relayTarget{}).matches("1.3.6.1.4.1.8072.2.3.0.1", net.ParseIP("172.16.0.1")))
+
+	_, err := parseSource("10.0.0.0/33")
+	assert.Error(t, err)
+	_, err = parseSource("not-an-ip")
+	assert.Error(t, err)
+}
+
+func TestRelayDropsTrapsWhenQueueIsFull(t *testing.T) {
+	sender := mocksender.NewMockSender("")
+	sender.SetupAcceptAll()
+	relay := &trapRelay{
+		targets: []*relayTarget{{addr: "127.0.0.1:162"}},
+		queue:   make(chan *packet.SnmpPacket, 1),
+		done:    make(chan struct{}),
+		sender:  sender,
+		logger:  logmock.New(t),
+	}
+	p := &packet.SnmpPacket{Content: &gosnmp.SnmpPacket{Version: gosnmp.Version2c}, Addr: simpleUDPAddr, Namespace: "totoro"}
+
+	// the relay isn't started, so traps aren't taken off the queue
+	relay.relay(p)
+	relay.relay(p)
+	relay.relay(p)
+
+	assert.Len(t, relay.queue, 1)
+	sender.AssertMetric(t, "Count", "datadog.snmp_traps.relay_dropped", 1, "", []string{"snmp_device:1.1.1.1", "device_namespace:totoro", "snmp_version:2"})
+	sender.AssertNumberOfCalls(t, "Count", 2)
+}
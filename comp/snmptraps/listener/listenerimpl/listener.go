@@ -143,8 +143,13 @@ func (t *trapListener) stop() error {
 	return nil
 }
 
+// receiveTrap is called by gosnmp for each trap and inform received. gosnmp
+// acknowledges informs by sending back the packet passed to this callback once
+// it returns, so it is copied before being published, and informs which aren't
+// valid are turned into traps to not be acknowledged.
 func (t *trapListener) receiveTrap(p *gosnmp.SnmpPacket, u *net.UDPAddr) {
-	packet := &packet.SnmpPacket{Content: p, Addr: u, Timestamp: time.Now().UnixMilli(), Namespace: t.config.Namespace}
+	content := *p
+	packet := &packet.SnmpPacket{Content: &content, Addr: u, Timestamp: time.Now().UnixMilli(), Namespace: t.config.Namespace}
 	tags := packet.GetTags()
 
 	t.sender.Count("datadog.snmp_traps.received", 1, "", tags)
@@ -153,8 +158,17 @@ func (t *trapListener) receiveTrap(p *gosnmp.SnmpPacket, u *net.UDPAddr) {
 		t.logger.Debugf("Invalid credentials from %s on listener %s, dropping traps", u.String(), t.config.Addr())
 		t.status.AddTrapsPacketsUnknownCommunityString(1)
 		t.sender.Count("datadog.snmp_traps.invalid_packet", 1, "", append(tags, "reason:unknown_community_string"))
+		if p.PDUType == gosnmp.InformRequest {
+			p.PDUType = gosnmp.SNMPv2Trap
+		}
 		return
 	}
+	if p.PDUType == gosnmp.InformRequest {
+		t.logger.Debugf("Acknowledging inform received from %s on listener %s", u.String(), t.config.Addr())
+		// The response belongs to the unconfirmed class, its reportable flag must be zero, see RFC 3412 section 6.4
+		p.MsgFlags &^= gosnmp.Reportable
+		t.sender.Count("datadog.snmp_traps.informs_acknowledged", 1, "", tags)
+	}
 	t.logger.Debugf("Packet received from %s on listener %s", u.String(), t.config.Addr())
 	t.status.AddTrapsPackets(1)
 	t.packets <- packet
@@ -106,6 +106,37 @@ func TestServerV2BadCredentials(t *testing.T) {
 	s.Sender.AssertMetric(t, "Count", "datadog.snmp_traps.invalid_packet", 1, "", []string{"snmp_device:127.0.0.1", "device_namespace:totoro", "snmp_version:2", "reason:unknown_community_string"})
 }
 
+func TestServerV2Inform(t *testing.T) {
+	serverPort, err := ndmtestutils.GetFreePort()
+	require.NoError(t, err)
+	config := &config.TrapsConfig{Port: serverPort, CommunityStrings: []string{"public"}, Namespace: "totoro"}
+	s := listenerTestSetup(t, config)
+
+	response, err := sendTestV2Inform(t, config, "public")
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.GetResponse, response.PDUType)
+	assert.Equal(t, gosnmp.NoError, response.Error)
+
+	packet, err := receivePacket(s, defaultTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.InformRequest, packet.Content.PDUType)
+	assertIsValidV2Packet(t, packet, config)
+	assertVariables(t, packet)
+	s.Sender.AssertMetric(t, "Count", "datadog.snmp_traps.informs_acknowledged", 1, "", []string{"snmp_device:127.0.0.1", "device_namespace:totoro", "snmp_version:2"})
+}
+
+func TestServerV2InformBadCredentials(t *testing.T) {
+	serverPort, err := ndmtestutils.GetFreePort()
+	require.NoError(t, err)
+	config := &config.TrapsConfig{Port: serverPort, CommunityStrings: []string{"public"}}
+	s := listenerTestSetup(t, config)
+
+	// informs with an unknown community string must not be acknowledged
+	_, err = sendTestV2Inform(t, config, "wrong-community")
+	require.Error(t, err)
+	assertNoPacketReceived(t, s.Listener)
+}
+
 func TestServerV3(t *testing.T) {
 	serverPort, err := ndmtestutils.GetFreePort()
 	require.NoError(t, err)
@@ -126,6 +157,44 @@ func TestServerV3(t *testing.T) {
 	assertVariables(t, packet)
 }
 
+func TestServerV3Inform(t *testing.T) {
+	serverPort, err := ndmtestutils.GetFreePort()
+	require.NoError(t, err)
+	userV3 := config.UserV3{Username: "user", AuthKey: "password", AuthProtocol: "sha", PrivKey: "password", PrivProtocol: "aes"}
+	config := &config.TrapsConfig{Port: serverPort, Users: []config.UserV3{userV3}}
+	s := listenerTestSetup(t, config)
+
+	params, err := config.BuildSNMPParams(nil)
+	require.NoError(t, err)
+	// informs are sent to the engine ID of the agent, which is authoritative
+	engineID := params.SecurityParameters.(*gosnmp.UsmSecurityParameters).AuthoritativeEngineID
+	params.Target = "127.0.0.1"
+	params.MsgFlags = gosnmp.AuthPriv
+	params.SecurityParameters = &gosnmp.UsmSecurityParameters{
+		UserName:                 "user",
+		AuthoritativeEngineID:    engineID,
+		AuthenticationPassphrase: "password",
+		AuthenticationProtocol:   gosnmp.SHA,
+		PrivacyPassphrase:        "password",
+		PrivacyProtocol:          gosnmp.AES,
+	}
+	params.Timeout = 500 * time.Millisecond
+	params.Retries = 1
+	require.NoError(t, params.Connect())
+	defer params.Conn.Close()
+
+	trap := packetModule.NetSNMPExampleHeartbeatNotification
+	trap.IsInform = true
+	response, err := params.SendTrap(trap)
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.GetResponse, response.PDUType)
+
+	packet, err := receivePacket(s, defaultTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.InformRequest, packet.Content.PDUType)
+	assertVariables(t, packet)
+}
+
 var users = []config.UserV3{
 	{Username: "user", AuthKey: "password", AuthProtocol: "sha", PrivKey: "password", PrivProtocol: "aes"},
 	{Username: "user2", AuthKey: "password2", AuthProtocol: "md5", PrivKey: "password", PrivProtocol: "des"},
@@ -91,6 +91,23 @@ func sendTestV3Trap(t *testing.T, trapConfig *config.TrapsConfig, msgFlags gosnm
 	return params
 }
 
+func sendTestV2Inform(t *testing.T, trapConfig *config.TrapsConfig, community string) (*gosnmp.SnmpPacket, error) {
+	params, err := trapConfig.BuildSNMPParams(nil)
+	require.NoError(t, err)
+	params.Target = "127.0.0.1"
+	params.Community = community
+	params.Timeout = 500 * time.Millisecond // Informs wait for an acknowledgement.
+	params.Retries = 1
+
+	err = params.Connect()
+	require.NoError(t, err)
+	defer params.Conn.Close()
+
+	trap := packet.NetSNMPExampleHeartbeatNotification
+	trap.IsInform = true
+	return params.SendTrap(trap)
+}
+
 func assertIsValidV2Packet(t *testing.T, packet *packet.SnmpPacket, trapConfig *config.TrapsConfig) {
 	require.Equal(t, gosnmp.Version2c, packet.Content.Version)
 	communityValid := false
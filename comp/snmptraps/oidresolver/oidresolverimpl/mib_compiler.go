@@ -0,0 +1,417 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package oidresolverimpl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+)
+
+// mibCacheVersion is bumped whenever the compiled output changes, to invalidate existing caches.
+const mibCacheVersion = 1
+
+// smiRootOIDs are the roots of the OID tree, which are not defined by any module.
+var smiRootOIDs = map[string][]uint32{
+	"ccitt":           {0},
+	"iso":             {1},
+	"joint-iso-ccitt": {2},
+}
+
+// smiBaseModules are the modules defining the SMI itself. They are built into the compiler so that
+// they don't need to be provided along with the MIB files, and their definitions take precedence
+// over the ones of any file defining a module with the same name.
+var smiBaseModules = map[string]struct {
+	oids  map[string][]uint32
+	types map[string]*mibSyntax
+}{
+	"SNMPv2-SMI": {oids: smiV2OIDs},
+	"RFC1155-SMI": {oids: map[string][]uint32{
+		"internet":     {1, 3, 6, 1},
+		"directory":    {1, 3, 6, 1, 1},
+		"mgmt":         {1, 3, 6, 1, 2},
+		"experimental": {1, 3, 6, 1, 3},
+		"private":      {1, 3, 6, 1, 4},
+		"enterprises":  {1, 3, 6, 1, 4, 1},
+	}},
+	"RFC1065-SMI": {},
+	"SNMPv2-TC": {types: map[string]*mibSyntax{
+		"TruthValue":  {enum: map[int]string{1: "true", 2: "false"}},
+		"RowStatus":   {enum: map[int]string{1: "active", 2: "notInService", 3: "notReady", 4: "createAndGo", 5: "createAndWait", 6: "destroy"}},
+		"StorageType": {enum: map[int]string{1: "other", 2: "volatile", 3: "nonVolatile", 4: "permanent", 5: "readOnly"}},
+	}},
+	"SNMPv2-CONF": {},
+	"RFC-1212":    {},
+	"RFC-1215":    {},
+}
+
+var smiV2OIDs = map[string][]uint32{
+	"org":          {1, 3},
+	"dod":          {1, 3, 6},
+	"internet":     {1, 3, 6, 1},
+	"directory":    {1, 3, 6, 1, 1},
+	"mgmt":         {1, 3, 6, 1, 2},
+	"mib-2":        {1, 3, 6, 1, 2, 1},
+	"transmission": {1, 3, 6, 1, 2, 1, 10},
+	"experimental": {1, 3, 6, 1, 3},
+	"private":      {1, 3, 6, 1, 4},
+	"enterprises":  {1, 3, 6, 1, 4, 1},
+	"security":     {1, 3, 6, 1, 5},
+	"snmpV2":       {1, 3, 6, 1, 6},
+	"snmpDomains":  {1, 3, 6, 1, 6, 1},
+	"snmpProxys":   {1, 3, 6, 1, 6, 2},
+	"snmpModules":  {1, 3, 6, 1, 6, 3},
+	"zeroDotZero":  {0, 0},
+}
+
+// errMIBModuleNotFound is returned when a module imports symbols from a module which is neither
+// a base SMI module nor defined by any of the MIB files.
+var errMIBModuleNotFound = errors.New("module not found")
+
+// mibCompiler resolves the OIDs and types of a set of parsed MIB modules.
+type mibCompiler struct {
+	modules map[string]*mibModule
+	// checked holds the result of checkModule for each module, nil if its imports are resolved.
+	checked map[string]error
+	// resolving holds the definitions being resolved, to detect circular definitions.
+	resolving map[string]bool
+	oids      map[string][]uint32
+}
+
+// newMIBCompiler returns a compiler without any module.
+func newMIBCompiler() *mibCompiler {
+	return &mibCompiler{
+		modules:   make(map[string]*mibModule),
+		checked:   make(map[string]error),
+		resolving: make(map[string]bool),
+		oids:      make(map[string][]uint32),
+	}
+}
+
+// compileMIBDir parses all the MIB files of dir and returns the traps and variables they define.
+// Files or modules which cannot be loaded are skipped, and the returned errors list the reasons why.
+func compileMIBDir(dir string) (oidresolver.TrapDBFileContent, []error) {
+	content := oidresolver.TrapDBFileContent{Traps: oidresolver.TrapSpec{}, Variables: oidresolver.VariableSpec{}}
+	files, err := listMIBFiles(dir)
+	if err != nil {
+		return content, []error{err}
+	}
+
+	var errs []error
+	c := newMIBCompiler()
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to read MIB file %s: %w", file, err))
+			continue
+		}
+		modules, err := parseMIB(file, string(data))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to parse MIB file %s: %w", file, err))
+			continue
+		}
+		for _, module := range modules {
+			if _, isBase := smiBaseModules[module.name]; isBase {
+				continue
+			}
+			if other, exists := c.modules[module.name]; exists {
+				errs = append(errs, fmt.Errorf("MIB module %s is defined in both %s and %s, ignoring the latter", module.name, other.file, file))
+				continue
+			}
+			c.modules[module.name] = module
+		}
+	}
+
+	moduleNames := make([]string, 0, len(c.modules))
+	for name := range c.modules {
+		moduleNames = append(moduleNames, name)
+	}
+	sort.Strings(moduleNames)
+	for _, name := range moduleNames {
+		if err := c.checkModule(name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := c.compileModule(c.modules[name], &content); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return content, errs
+}
+
+// listMIBFiles returns the sorted names of the files of dir.
+func listMIBFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MIB dir `%s`: %w", dir, err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, entry.Name())
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// checkModule checks that all the imports of the named module, and of the modules it depends on,
+// can be resolved.
+func (c *mibCompiler) checkModule(name string) error {
+	if err, checked := c.checked[name]; checked {
+		return err
+	}
+	// mark the module as valid while checking it, to handle circular imports
+	c.checked[name] = nil
+	module := c.modules[name]
+	var err error
+	for _, symbol := range sortedKeys(module.imports) {
+		from := module.imports[symbol]
+		if _, isBase := smiBaseModules[from]; isBase {
+			continue
+		}
+		imported, found := c.modules[from]
+		if !found {
+			err = fmt.Errorf("MIB module %s (%s): unresolved import of %s from %s: %w", name, module.file, symbol, from, errMIBModuleNotFound)
+			break
+		}
+		if _, isNode := imported.nodes[symbol]; !isNode {
+			if _, isType := imported.types[symbol]; !isType {
+				err = fmt.Errorf("MIB module %s (%s): unresolved import of %s from %s: symbol not defined in %s", name, module.file, symbol, from, imported.file)
+				break
+			}
+		}
+		if c.checkModule(from) != nil {
+			// the reason is reported for the dependency itself
+			err = fmt.Errorf("MIB module %s (%s): unresolved import of %s from %s: %s could not be loaded", name, module.file, symbol, from, from)
+			break
+		}
+	}
+	c.checked[name] = err
+	return err
+}
+
+// compileModule adds the traps and variables of the module to content.
+func (c *mibCompiler) compileModule(module *mibModule, content *oidresolver.TrapDBFileContent) error {
+	var errs []error
+	for _, name := range sortedKeys(module.nodes) {
+		node := module.nodes[name]
+		if node.kind == mibNodeIdentifier {
+			continue
+		}
+		oid, err := c.resolveOID(module, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		oidStr := formatOID(oid)
+		switch node.kind {
+		case mibNodeObject:
+			variable := oidresolver.VariableMetadata{Name: node.name, Description: node.description}
+			if node.syntax != nil {
+				variable.Enumeration, variable.Bits = c.resolveSyntax(module, node.syntax, 0)
+			}
+			content.Variables[oidStr] = variable
+		case mibNodeNotification, mibNodeTrap:
+			content.Traps[oidStr] = oidresolver.TrapMetadata{Name: node.name, MIBName: module.name, Description: node.description}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("MIB module %s (%s): %w", module.name, module.file, errors.Join(errs...))
+	}
+	return nil
+}
+
+// resolveOID returns the OID of the named definition, as seen from module.
+func (c *mibCompiler) resolveOID(module *mibModule, name string) ([]uint32, error) {
+	if node, ok := module.nodes[name]; ok {
+		return c.resolveNodeOID(module, node)
+	}
+	if from, ok := module.imports[name]; ok {
+		if base, isBase := smiBaseModules[from]; isBase {
+			if oid, ok := base.oids[name]; ok {
+				return oid, nil
+			}
+			return nil, fmt.Errorf("%s is not an OID defined by %s", name, from)
+		}
+		imported, ok := c.modules[from]
+		if !ok {
+			return nil, fmt.Errorf("unresolved import of %s from %s: %w", name, from, errMIBModuleNotFound)
+		}
+		return c.resolveOID(imported, name)
+	}
+	if oid, ok := smiRootOIDs[name]; ok {
+		return oid, nil
+	}
+	return nil, fmt.Errorf("%s is not defined nor imported", name)
+}
+
+func (c *mibCompiler) resolveNodeOID(module *mibModule, node *mibNode) ([]uint32, error) {
+	key := module.name + "::" + node.name
+	if oid, ok := c.oids[key]; ok {
+		return oid, nil
+	}
+	if c.resolving[key] {
+		return nil, fmt.Errorf("circular definition of %s (line %d)", node.name, node.line)
+	}
+	c.resolving[key] = true
+	defer delete(c.resolving, key)
+
+	var oid []uint32
+	if node.kind == mibNodeTrap {
+		// SMIv1 traps are mapped to SMIv2 notifications as enterprise.0.number, see RFC 3584 section 3.1
+		enterprise, err := c.resolveOID(module, node.enterprise)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve the enterprise of %s (line %d): %w", node.name, node.line, err)
+		}
+		oid = append(append(oid, enterprise...), 0, node.trapNumber)
+	} else {
+		for i, component := range node.oid {
+			switch {
+			case i == 0 && component.name != "":
+				parent, err := c.resolveOID(module, component.name)
+				if err != nil {
+					return nil, fmt.Errorf("unable to resolve the OID of %s (line %d): %w", node.name, node.line, err)
+				}
+				oid = append(oid, parent...)
+			case component.hasNumber:
+				oid = append(oid, component.number)
+			default:
+				return nil, fmt.Errorf("unable to resolve the OID of %s (line %d): %s has no number", node.name, node.line, component.name)
+			}
+		}
+	}
+	c.oids[key] = oid
+	return oid, nil
+}
+
+// maxTypeDepth bounds the resolution of types referencing other types.
+const maxTypeDepth = 16
+
+// resolveSyntax returns the enumeration and bits of a syntax, following the types it references.
+// Types which cannot be resolved, such as the base SMI types, have neither.
+func (c *mibCompiler) resolveSyntax(module *mibModule, syntax *mibSyntax, depth int) (map[int]string, map[int]string) {
+	if syntax.enum != nil || syntax.bits != nil || syntax.typeName == "" || depth > maxTypeDepth {
+		return syntax.enum, syntax.bits
+	}
+	if typ, ok := module.types[syntax.typeName]; ok {
+		return c.resolveSyntax(module, typ, depth+1)
+	}
+	from, ok := module.imports[syntax.typeName]
+	if !ok {
+		return nil, nil
+	}
+	if base, isBase := smiBaseModules[from]; isBase {
+		if typ, ok := base.types[syntax.typeName]; ok {
+			return typ.enum, typ.bits
+		}
+		return nil, nil
+	}
+	if imported, ok := c.modules[from]; ok {
+		return c.resolveSyntax(imported, &mibSyntax{typeName: syntax.typeName}, depth+1)
+	}
+	return nil, nil
+}
+
+func formatOID(oid []uint32) string {
+	parts := make([]string, len(oid))
+	for i, n := range oid {
+		parts[i] = strconv.FormatUint(uint64(n), 10)
+	}
+	return strings.Join(parts, ".")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mibCache is the content of the cache of the compiled MIB files.
+type mibCache struct {
+	// Fingerprint identifies the MIB files the cache was compiled from.
+	Fingerprint string                        `json:"fingerprint"`
+	Content     oidresolver.TrapDBFileContent `json:"content"`
+	Errors      []string                      `json:"errors,omitempty"`
+}
+
+// mibDirFingerprint returns a hash of the names, sizes and modification times of the files of dir.
+func mibDirFingerprint(dir string) (string, error) {
+	files, err := listMIBFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "v%d\n", mibCacheVersion)
+	for _, file := range files {
+		info, err := os.Stat(filepath.Join(dir, file))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00%d\n", file, info.Size(), info.ModTime().UnixNano())
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// loadMIBDir returns the traps and variables defined by the MIB files of dir. The compiled result is
+// cached in cachePath, if not empty, and reused as long as the MIB files don't change.
+func loadMIBDir(dir string, cachePath string) (oidresolver.TrapDBFileContent, []error) {
+	fingerprint, err := mibDirFingerprint(dir)
+	if err != nil {
+		return oidresolver.TrapDBFileContent{}, []error{err}
+	}
+	if cachePath != "" {
+		if data, err := os.ReadFile(cachePath); err == nil {
+			var cache mibCache
+			if err := json.Unmarshal(data, &cache); err == nil && cache.Fingerprint == fingerprint {
+				errs := make([]error, 0, len(cache.Errors))
+				for _, e := range cache.Errors {
+					errs = append(errs, errors.New(e))
+				}
+				return cache.Content, errs
+			}
+		}
+	}
+
+	content, errs := compileMIBDir(dir)
+	if cachePath != "" {
+		cache := mibCache{Fingerprint: fingerprint, Content: content}
+		for _, e := range errs {
+			cache.Errors = append(cache.Errors, e.Error())
+		}
+		if err := writeMIBCache(cachePath, cache); err != nil {
+			errs = append(errs, fmt.Errorf("unable to write MIB cache %s: %w", cachePath, err))
+		}
+	}
+	return content, errs
+}
+
+func writeMIBCache(cachePath string, cache mibCache) error {
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return err
+	}
+	tmpPath := cachePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, cachePath)
+}
@@ -0,0 +1,232 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package oidresolverimpl
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	logmock "github.com/DataDog/datadog-agent/comp/core/log/mock"
+	"github.com/DataDog/datadog-agent/comp/snmptraps/oidresolver"
+)
+
+const testMIBsDir = "testdata/mibs"
+
+func TestParseMIB(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join(testMIBsDir, "ACME-TRAPS-MIB.txt"))
+	require.NoError(t, err)
+	modules, err := parseMIB("ACME-TRAPS-MIB.txt", string(data))
+	require.NoError(t, err)
+	require.Len(t, modules, 1)
+
+	module := modules[0]
+	assert.Equal(t, "ACME-TRAPS-MIB", module.name)
+	assert.Equal(t, "ACME-SMI", module.imports["AcmeStatus"])
+	assert.Equal(t, "SNMPv2-TC", module.imports["TruthValue"])
+	assert.Equal(t, "SNMPv2-SMI", module.imports["NOTIFICATION-TYPE"])
+
+	assert.Equal(t, mibNodeIdentifier, module.nodes["acmeTraps"].kind)
+	assert.Equal(t, []mibOIDComponent{{name: "acme"}, {number: 2, hasNumber: true}}, module.nodes["acmeTraps"].oid)
+	assert.Equal(t, mibNodeNotification, module.nodes["acmePortStatusChange"].kind)
+	assert.Equal(t, "Sent when the status of a port changes.", module.nodes["acmePortStatusChange"].description)
+
+	status := module.nodes["acmePortStatus"]
+	assert.Equal(t, mibNodeObject, status.kind)
+	assert.Equal(t, "The status of the port.", status.description)
+	assert.Equal(t, &mibSyntax{typeName: "AcmeStatus"}, status.syntax)
+	assert.Equal(t, map[int]string{0: "poe", 1: "uplink", 2: "lacp"}, module.nodes["acmePortFlags"].syntax.bits)
+	assert.Contains(t, module.types, "AcmePortEntry")
+}
+
+func TestParseMIBErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"unterminated string": `FOO-MIB DEFINITIONS ::= BEGIN foo OBJECT-TYPE DESCRIPTION "bar ::= { baz 1 } END`,
+		"missing END":         `FOO-MIB DEFINITIONS ::= BEGIN foo OBJECT IDENTIFIER ::= { baz 1 }`,
+		"missing BEGIN":       `FOO-MIB DEFINITIONS ::= foo OBJECT IDENTIFIER ::= { baz 1 } END`,
+		"invalid OID value":   `FOO-MIB DEFINITIONS ::= BEGIN foo OBJECT IDENTIFIER ::= { baz "1" } END`,
+		"no module":           `-- only a comment`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := parseMIB("FOO-MIB", content)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCompileMIBDir(t *testing.T) {
+	content, errs := compileMIBDir(testMIBsDir)
+
+	require.Len(t, errs, 2)
+	assert.ErrorIs(t, errs[0], errMIBModuleNotFound)
+	assert.EqualError(t, errs[0], "MIB module BROKEN-MIB (BROKEN-MIB.mib): unresolved import of vendorRoot from VENDOR-ROOT-MIB: module not found")
+	assert.EqualError(t, errs[1], "MIB module DEPENDS-ON-BROKEN-MIB (DEPENDS-ON-BROKEN-MIB.mib): unresolved import of brokenTrap from BROKEN-MIB: BROKEN-MIB could not be loaded")
+
+	assert.Equal(t, oidresolver.TrapSpec{
+		"1.3.6.1.4.1.99999.2.0.1": {Name: "acmePortStatusChange", MIBName: "ACME-TRAPS-MIB", Description: "Sent when the status of a port changes."},
+		"1.3.6.1.4.1.99998.0.3":   {Name: "acmeFanFailure", MIBName: "ACME-V1-MIB", Description: "Sent when a fan fails."},
+	}, content.Traps)
+	assert.Equal(t, oidresolver.VariableSpec{
+		"1.3.6.1.4.1.99999.2.1.1":     {Name: "acmePortTable", Description: "The ports."},
+		"1.3.6.1.4.1.99999.2.1.1.1":   {Name: "acmePortEntry", Description: "A port."},
+		"1.3.6.1.4.1.99999.2.1.1.1.1": {Name: "acmePortIndex", Description: "The index of the port."},
+		"1.3.6.1.4.1.99999.2.1.1.1.2": {Name: "acmePortStatus", Description: "The status of the port.", Enumeration: map[int]string{1: "up", 2: "down", 3: "degraded"}},
+		"1.3.6.1.4.1.99999.2.1.1.1.3": {Name: "acmePortFlags", Description: "The flags of the port.", Bits: map[int]string{0: "poe", 1: "uplink", 2: "lacp"}},
+		"1.3.6.1.4.1.99999.2.1.2":     {Name: "acmePortMonitored", Description: "Whether the port is monitored.", Enumeration: map[int]string{1: "true", 2: "false"}},
+		"1.3.6.1.4.1.99999.2.1.3":     {Name: "acmePortName", Description: "The name of the port."},
+		"1.3.6.1.4.1.99998.1":         {Name: "acmeFanSpeed", Description: "The speed of the fan.", Enumeration: map[int]string{1: "low", 2: "medium", 3: "high"}},
+	}, content.Variables)
+}
+
+func TestCompileMIBDirCircularDefinition(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "LOOP-MIB"), []byte(`LOOP-MIB DEFINITIONS ::= BEGIN
+IMPORTS OBJECT-TYPE FROM SNMPv2-SMI;
+a OBJECT IDENTIFIER ::= { b 1 }
+b OBJECT IDENTIFIER ::= { a 1 }
+c OBJECT-TYPE SYNTAX INTEGER MAX-ACCESS read-only STATUS current DESCRIPTION "c" ::= { a 2 }
+END`), 0644))
+
+	content, errs := compileMIBDir(dir)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Error(), "circular definition of a")
+	assert.Empty(t, content.Variables)
+}
+
+func TestLoadMIBDirCache(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{"ACME-SMI.mib", "ACME-TRAPS-MIB.txt"} {
+		data, err := os.ReadFile(filepath.Join(testMIBsDir, file))
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), data, 0644))
+	}
+	cachePath := filepath.Join(t.TempDir(), "snmp_traps", mibsCacheFileName)
+
+	content, errs := loadMIBDir(dir, cachePath)
+	require.Empty(t, errs)
+	require.Contains(t, content.Traps, "1.3.6.1.4.1.99999.2.0.1")
+	require.FileExists(t, cachePath)
+
+	// the cache is used as long as the MIB files don't change
+	var cache mibCache
+	data, err := os.ReadFile(cachePath)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &cache))
+	cache.Content.Traps["1.2.3"] = oidresolver.TrapMetadata{Name: "fromCache"}
+	cache.Errors = []string{"cached error"}
+	require.NoError(t, writeMIBCache(cachePath, cache))
+
+	content, errs = loadMIBDir(dir, cachePath)
+	assert.Equal(t, []error{errors.New("cached error")}, errs)
+	assert.Equal(t, "fromCache", content.Traps["1.2.3"].Name)
+	assert.Equal(t, map[int]string{1: "up", 2: "down", 3: "degraded"}, content.Variables["1.3.6.1.4.1.99999.2.1.1.1.2"].Enumeration)
+
+	// changing a MIB file invalidates the cache
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "ACME-SMI.mib"), later, later))
+	content, errs = loadMIBDir(dir, cachePath)
+	assert.Empty(t, errs)
+	assert.NotContains(t, content.Traps, "1.2.3")
+}
+
+func TestResolverWithMIBs(t *testing.T) {
+	confdPath := t.TempDir()
+	trapsDBRoot := filepath.Join(confdPath, "snmp.d", "traps_db")
+	require.NoError(t, os.MkdirAll(trapsDBRoot, 0755))
+	writeTrapDB := func(fileName string, trapDB oidresolver.TrapDBFileContent) {
+		data, err := json.Marshal(trapDB)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(trapsDBRoot, fileName), data, 0644))
+	}
+	// Datadog's database is overridden by the MIB files, which are overridden by user-provided trap db files
+	writeTrapDB("dd_traps_db.json", oidresolver.TrapDBFileContent{
+		Traps: oidresolver.TrapSpec{
+			"1.3.6.1.4.1.99999.2.0.1": {Name: "ddPortStatusChange", MIBName: "DD-MIB"},
+			"1.3.6.1.4.1.99998.0.3":   {Name: "ddFanFailure", MIBName: "DD-MIB"},
+		},
+	})
+	writeTrapDB("user_traps_db.json", oidresolver.TrapDBFileContent{
+		Traps: oidresolver.TrapSpec{"1.3.6.1.4.1.99998.0.3": {Name: "userFanFailure", MIBName: "USER-MIB"}},
+	})
+
+	resolver, err := newMultiFilesOIDResolver(confdPath, testMIBsDir, "", logmock.New(t))
+	require.NoError(t, err)
+
+	trap, err := resolver.GetTrapMetadata("1.3.6.1.4.1.99999.2.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "acmePortStatusChange", trap.Name)
+	assert.Equal(t, "ACME-TRAPS-MIB", trap.MIBName)
+
+	trap, err = resolver.GetTrapMetadata("1.3.6.1.4.1.99998.0.3")
+	require.NoError(t, err)
+	assert.Equal(t, "userFanFailure", trap.Name)
+
+	variable, err := resolver.GetVariableMetadata("1.3.6.1.4.1.99999.2.0.1", "1.3.6.1.4.1.99999.2.1.1.1.2.42")
+	require.NoError(t, err)
+	assert.Equal(t, "acmePortStatus", variable.Name)
+	assert.Equal(t, "degraded", variable.Enumeration[3])
+
+	// table and entry objects are intermediate nodes
+	_, err = resolver.GetVariableMetadata("1.3.6.1.4.1.99999.2.0.1", "1.3.6.1.4.1.99999.2.1.1.1.4")
+	assert.Error(t, err)
+
+	// the MIB dir is optional
+	_, err = newMultiFilesOIDResolver(confdPath, filepath.Join(confdPath, "snmp.d", "mibs"), "", logmock.New(t))
+	require.NoError(t, err)
+}
+
+func TestResolverWithMIBsOnly(t *testing.T) {
+	confdPath := t.TempDir()
+
+	resolver, err := newMultiFilesOIDResolver(confdPath, testMIBsDir, "", logmock.New(t))
+	require.NoError(t, err)
+	trap, err := resolver.GetTrapMetadata("1.3.6.1.4.1.99999.2.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "acmePortStatusChange", trap.Name)
+
+	// an empty traps_db dir is ignored as well
+	require.NoError(t, os.MkdirAll(filepath.Join(confdPath, "snmp.d", "traps_db"), 0755))
+	_, err = newMultiFilesOIDResolver(confdPath, testMIBsDir, "", logmock.New(t))
+	require.NoError(t, err)
+
+	// but at least one of them is required
+	_, err = newMultiFilesOIDResolver(confdPath, filepath.Join(confdPath, "snmp.d", "mibs"), "", logmock.New(t))
+	assert.Error(t, err)
+}
+
+func FuzzParseMIB(f *testing.F) {
+	files, err := listMIBFiles(testMIBsDir)
+	require.NoError(f, err)
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(testMIBsDir, file))
+		require.NoError(f, err)
+		f.Add(string(data))
+	}
+	f.Add(`FOO-MIB DEFINITIONS ::= BEGIN foo OBJECT IDENTIFIER ::= { baz 1 } END`)
+	f.Fuzz(func(_ *testing.T, content string) {
+		modules, err := parseMIB("FUZZ-MIB", content)
+		if err != nil {
+			return
+		}
+		// parsed modules must be usable by the compiler
+		c := newMIBCompiler()
+		for _, module := range modules {
+			c.modules[module.name] = module
+		}
+		compiled := oidresolver.TrapDBFileContent{Traps: oidresolver.TrapSpec{}, Variables: oidresolver.VariableSpec{}}
+		for _, module := range modules {
+			if c.checkModule(module.name) == nil {
+				_ = c.compileModule(module, &compiled)
+			}
+		}
+	})
+}
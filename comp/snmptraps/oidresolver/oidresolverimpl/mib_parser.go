@@ -0,0 +1,646 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2022-present Datadog, Inc.
+
+package oidresolverimpl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// This file implements a parser for the subset of ASN.1 used by SMIv1 (RFC 1155, 1212, 1215) and
+// SMIv2 (RFC 2578, 2579, 2580) MIB modules. It only extracts what the trap formatter needs: the
+// OID tree, the objects with their enumerations and bits, and the notifications.
+
+type mibTokenKind int
+
+const (
+	mibTokenEOF mibTokenKind = iota
+	mibTokenIdent
+	mibTokenNumber
+	mibTokenString
+	mibTokenSymbol
+)
+
+type mibToken struct {
+	kind mibTokenKind
+	text string
+	line int
+}
+
+func (t mibToken) String() string {
+	if t.kind == mibTokenEOF {
+		return "end of file"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// tokenizeMIB splits the content of a MIB file into tokens, dropping comments.
+func tokenizeMIB(content string) ([]mibToken, error) {
+	var tokens []mibToken
+	line := 1
+	i := 0
+	for i < len(content) {
+		c := content[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(rune(c)):
+			i++
+		case strings.HasPrefix(content[i:], "--"):
+			// comments end at the end of the line or at the next "--"
+			i += 2
+			for i < len(content) && content[i] != '\n' {
+				if strings.HasPrefix(content[i:], "--") {
+					i += 2
+					break
+				}
+				i++
+			}
+		case c == '"':
+			start, startLine := i+1, line
+			i++
+			for i < len(content) && content[i] != '"' {
+				if content[i] == '\n' {
+					line++
+				}
+				i++
+			}
+			if i == len(content) {
+				return nil, fmt.Errorf("line %d: unterminated string", startLine)
+			}
+			tokens = append(tokens, mibToken{kind: mibTokenString, text: content[start:i], line: startLine})
+			i++
+		case c == '\'':
+			// binary ('0101'B) or hexadecimal ('0A'H) strings
+			end := strings.IndexByte(content[i+1:], '\'')
+			if end == -1 {
+				return nil, fmt.Errorf("line %d: unterminated quoted string", line)
+			}
+			end += i + 2
+			if end < len(content) && (content[end] == 'B' || content[end] == 'b' || content[end] == 'H' || content[end] == 'h') {
+				end++
+			}
+			tokens = append(tokens, mibToken{kind: mibTokenString, text: content[i:end], line: line})
+			i = end
+		case strings.HasPrefix(content[i:], "::="):
+			tokens = append(tokens, mibToken{kind: mibTokenSymbol, text: "::=", line: line})
+			i += 3
+		case strings.HasPrefix(content[i:], ".."):
+			tokens = append(tokens, mibToken{kind: mibTokenSymbol, text: "..", line: line})
+			i += 2
+		case unicode.IsDigit(rune(c)) || (c == '-' && i+1 < len(content) && unicode.IsDigit(rune(content[i+1]))):
+			start := i
+			i++
+			for i < len(content) && unicode.IsDigit(rune(content[i])) {
+				i++
+			}
+			tokens = append(tokens, mibToken{kind: mibTokenNumber, text: content[start:i], line: line})
+		case unicode.IsLetter(rune(c)):
+			start := i
+			for i < len(content) {
+				r := rune(content[i])
+				if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || (r == '-' && !strings.HasPrefix(content[i:], "--")) {
+					i++
+					continue
+				}
+				break
+			}
+			tokens = append(tokens, mibToken{kind: mibTokenIdent, text: content[start:i], line: line})
+		default:
+			tokens = append(tokens, mibToken{kind: mibTokenSymbol, text: string(c), line: line})
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+type mibNodeKind int
+
+const (
+	// mibNodeIdentifier is a node of the OID tree without data, such as an OBJECT IDENTIFIER or a MODULE-IDENTITY.
+	mibNodeIdentifier mibNodeKind = iota
+	// mibNodeObject is an OBJECT-TYPE.
+	mibNodeObject
+	// mibNodeNotification is a SMIv2 NOTIFICATION-TYPE.
+	mibNodeNotification
+	// mibNodeTrap is a SMIv1 TRAP-TYPE.
+	mibNodeTrap
+)
+
+// mibOIDComponent is a component of an OID value, such as "iso", "org(3)" or "6".
+type mibOIDComponent struct {
+	name      string
+	number    uint32
+	hasNumber bool
+}
+
+// mibSyntax is the syntax of an object or of a type.
+type mibSyntax struct {
+	// typeName is the name of the referenced type, if the syntax does not define the values itself.
+	typeName string
+	enum     map[int]string
+	bits     map[int]string
+}
+
+// mibNode is a definition of the OID tree.
+type mibNode struct {
+	name        string
+	kind        mibNodeKind
+	line        int
+	oid         []mibOIDComponent
+	description string
+	syntax      *mibSyntax
+	// enterprise and trapNumber define the OID of a SMIv1 trap.
+	enterprise string
+	trapNumber uint32
+}
+
+// mibModule is a parsed MIB module.
+type mibModule struct {
+	name string
+	file string
+	// imports maps the imported symbols to the module they are imported from.
+	imports map[string]string
+	nodes   map[string]*mibNode
+	types   map[string]*mibSyntax
+}
+
+// mibParser parses the tokens of a MIB file.
+type mibParser struct {
+	tokens []mibToken
+	pos    int
+}
+
+// parseMIB parses all the modules defined in the content of a MIB file.
+func parseMIB(file string, content string) ([]*mibModule, error) {
+	tokens, err := tokenizeMIB(content)
+	if err != nil {
+		return nil, err
+	}
+	p := &mibParser{tokens: tokens}
+	var modules []*mibModule
+	for p.peek().kind != mibTokenEOF {
+		module, err := p.parseModule()
+		if err != nil {
+			return nil, err
+		}
+		module.file = file
+		modules = append(modules, module)
+	}
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no MIB module found")
+	}
+	return modules, nil
+}
+
+func (p *mibParser) peek() mibToken {
+	return p.peekN(0)
+}
+
+func (p *mibParser) peekN(n int) mibToken {
+	if p.pos+n >= len(p.tokens) {
+		line := 0
+		if len(p.tokens) > 0 {
+			line = p.tokens[len(p.tokens)-1].line
+		}
+		return mibToken{kind: mibTokenEOF, line: line}
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *mibParser) next() mibToken {
+	t := p.peek()
+	if t.kind != mibTokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *mibParser) errorf(t mibToken, format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", t.line, fmt.Sprintf(format, args...))
+}
+
+// expect consumes the next token, which must be the given identifier or symbol.
+func (p *mibParser) expect(text string) error {
+	t := p.next()
+	if t.text != text || t.kind == mibTokenString {
+		return p.errorf(t, "expected %q, found %s", text, t)
+	}
+	return nil
+}
+
+func (p *mibParser) expectIdent() (mibToken, error) {
+	t := p.next()
+	if t.kind != mibTokenIdent {
+		return t, p.errorf(t, "expected an identifier, found %s", t)
+	}
+	return t, nil
+}
+
+func (p *mibParser) expectNumber() (uint32, error) {
+	t := p.next()
+	if t.kind != mibTokenNumber {
+		return 0, p.errorf(t, "expected a number, found %s", t)
+	}
+	n, err := strconv.ParseUint(t.text, 10, 32)
+	if err != nil {
+		return 0, p.errorf(t, "invalid number %s", t)
+	}
+	return uint32(n), nil
+}
+
+// skipBlock skips a block delimited by open and close, the opening token having been consumed.
+func (p *mibParser) skipBlock(open, close string) error {
+	depth := 1
+	for depth > 0 {
+		t := p.next()
+		switch {
+		case t.kind == mibTokenEOF:
+			return p.errorf(t, "unterminated block, expected %q", close)
+		case t.kind == mibTokenSymbol && t.text == open:
+			depth++
+		case t.kind == mibTokenSymbol && t.text == close:
+			depth--
+		}
+	}
+	return nil
+}
+
+func (p *mibParser) parseModule() (*mibModule, error) {
+	name, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	module := &mibModule{
+		name:    name.text,
+		imports: make(map[string]string),
+		nodes:   make(map[string]*mibNode),
+		types:   make(map[string]*mibSyntax),
+	}
+	if p.peek().text == "{" {
+		// module identifier of ASN.1 modules, unused by SMI
+		p.next()
+		if err := p.skipBlock("{", "}"); err != nil {
+			return nil, err
+		}
+	}
+	for _, keyword := range []string{"DEFINITIONS", "::=", "BEGIN"} {
+		if err := p.expect(keyword); err != nil {
+			return nil, fmt.Errorf("module %s: %w", module.name, err)
+		}
+	}
+	for {
+		t := p.peek()
+		switch {
+		case t.kind == mibTokenEOF:
+			return nil, fmt.Errorf("module %s: %w", module.name, p.errorf(t, "missing END"))
+		case t.kind == mibTokenIdent && t.text == "END":
+			p.next()
+			return module, nil
+		case t.kind == mibTokenIdent && t.text == "IMPORTS":
+			p.next()
+			err = p.parseImports(module)
+		case t.kind == mibTokenIdent && t.text == "EXPORTS":
+			err = p.skipUntil(";")
+		default:
+			err = p.parseAssignment(module)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("module %s: %w", module.name, err)
+		}
+	}
+}
+
+// skipUntil skips tokens up to and including the given symbol.
+func (p *mibParser) skipUntil(symbol string) error {
+	for {
+		t := p.next()
+		if t.kind == mibTokenEOF {
+			return p.errorf(t, "expected %q", symbol)
+		}
+		if t.kind == mibTokenSymbol && t.text == symbol {
+			return nil
+		}
+	}
+}
+
+// parseImports parses "sym1, sym2 FROM MODULE-A sym3 FROM MODULE-B ;".
+func (p *mibParser) parseImports(module *mibModule) error {
+	var symbols []string
+	for {
+		t := p.next()
+		switch {
+		case t.kind == mibTokenSymbol && t.text == ";":
+			if len(symbols) > 0 {
+				return p.errorf(t, "missing FROM clause for imports %s", strings.Join(symbols, ", "))
+			}
+			return nil
+		case t.kind == mibTokenSymbol && t.text == ",":
+		case t.kind == mibTokenIdent && t.text == "FROM":
+			from, err := p.expectIdent()
+			if err != nil {
+				return err
+			}
+			for _, symbol := range symbols {
+				module.imports[symbol] = from.text
+			}
+			symbols = symbols[:0]
+		case t.kind == mibTokenIdent:
+			symbols = append(symbols, t.text)
+		default:
+			return p.errorf(t, "unexpected %s in IMPORTS", t)
+		}
+	}
+}
+
+// parseAssignment parses a type assignment, a macro definition or a value assignment.
+func (p *mibParser) parseAssignment(module *mibModule) error {
+	name, err := p.expectIdent()
+	if err != nil {
+		return err
+	}
+	t := p.peek()
+	switch {
+	case t.text == "MACRO":
+		// macro definitions of the SMI modules, the macros themselves are known
+		for {
+			t := p.next()
+			if t.kind == mibTokenEOF {
+				return p.errorf(t, "unterminated MACRO %s", name.text)
+			}
+			if t.kind == mibTokenIdent && t.text == "END" {
+				return nil
+			}
+		}
+	case t.text == "::=":
+		p.next()
+		syntax, err := p.parseTypeAssignment()
+		if err != nil {
+			return err
+		}
+		module.types[name.text] = syntax
+		return nil
+	case t.text == "OBJECT" && p.peekN(1).text == "IDENTIFIER":
+		p.next()
+		p.next()
+		if err := p.expect("::="); err != nil {
+			return err
+		}
+		oid, err := p.parseOIDValue()
+		if err != nil {
+			return err
+		}
+		module.nodes[name.text] = &mibNode{name: name.text, kind: mibNodeIdentifier, line: name.line, oid: oid}
+		return nil
+	case t.kind == mibTokenIdent:
+		node, err := p.parseMacroInvocation(name)
+		if err != nil {
+			return err
+		}
+		if node != nil {
+			module.nodes[name.text] = node
+		}
+		return nil
+	default:
+		return p.errorf(t, "unexpected %s after %s", t, name.text)
+	}
+}
+
+// parseTypeAssignment parses the right hand side of "Name ::= ...", either a TEXTUAL-CONVENTION or a type.
+func (p *mibParser) parseTypeAssignment() (*mibSyntax, error) {
+	if p.peek().text != "TEXTUAL-CONVENTION" {
+		return p.parseSyntax()
+	}
+	p.next()
+	// SYNTAX is the last clause of a TEXTUAL-CONVENTION
+	for {
+		t := p.next()
+		switch {
+		case t.kind == mibTokenEOF:
+			return nil, p.errorf(t, "missing SYNTAX in TEXTUAL-CONVENTION")
+		case t.kind == mibTokenIdent && t.text == "SYNTAX":
+			return p.parseSyntax()
+		}
+	}
+}
+
+// parseSyntax parses a type, keeping the enumerations and bits it defines.
+func (p *mibParser) parseSyntax() (*mibSyntax, error) {
+	syntax := &mibSyntax{}
+	if p.peek().text == "[" {
+		// tagged types of the SMI modules, such as "[APPLICATION 1] IMPLICIT INTEGER"
+		p.next()
+		if err := p.skipBlock("[", "]"); err != nil {
+			return nil, err
+		}
+		if t := p.peek().text; t == "IMPLICIT" || t == "EXPLICIT" {
+			p.next()
+		}
+	}
+	t, err := p.expectIdent()
+	if err != nil {
+		return nil, err
+	}
+	switch t.text {
+	case "BITS":
+		if err := p.expect("{"); err != nil {
+			return nil, err
+		}
+		if syntax.bits, err = p.parseNamedNumbers(); err != nil {
+			return nil, err
+		}
+	case "OCTET", "OBJECT":
+		// OCTET STRING, OBJECT IDENTIFIER
+		if _, err := p.expectIdent(); err != nil {
+			return nil, err
+		}
+	case "SEQUENCE", "CHOICE":
+		if p.peek().text == "OF" {
+			p.next()
+			if _, err := p.expectIdent(); err != nil {
+				return nil, err
+			}
+			return syntax, nil
+		}
+		if err := p.expect("{"); err != nil {
+			return nil, err
+		}
+		if err := p.skipBlock("{", "}"); err != nil {
+			return nil, err
+		}
+		return syntax, nil
+	default:
+		// INTEGER, Integer32 or a reference to another type
+		if t.text != "INTEGER" && t.text != "Integer32" {
+			syntax.typeName = t.text
+		}
+		if p.peek().text == "{" {
+			p.next()
+			if syntax.enum, err = p.parseNamedNumbers(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if p.peek().text == "(" {
+		// size and range constraints
+		p.next()
+		if err := p.skipBlock("(", ")"); err != nil {
+			return nil, err
+		}
+	}
+	return syntax, nil
+}
+
+// parseNamedNumbers parses "name1(1), name2(2) }", the opening brace having been consumed.
+func (p *mibParser) parseNamedNumbers() (map[int]string, error) {
+	values := make(map[int]string)
+	for {
+		name, err := p.expectIdent()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		t := p.next()
+		value, err := strconv.Atoi(t.text)
+		if t.kind != mibTokenNumber || err != nil {
+			return nil, p.errorf(t, "invalid value %s for %s", t, name.text)
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		values[value] = name.text
+		t = p.next()
+		if t.text == "}" {
+			return values, nil
+		}
+		if t.text != "," {
+			return nil, p.errorf(t, "expected \",\" or \"}\", found %s", t)
+		}
+	}
+}
+
+// parseOIDValue parses "{ parent 1 }", "{ iso org(3) dod(6) 1 }" or "{ 1 3 6 }".
+func (p *mibParser) parseOIDValue() ([]mibOIDComponent, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var oid []mibOIDComponent
+	for {
+		t := p.next()
+		switch {
+		case t.text == "}":
+			if len(oid) == 0 {
+				return nil, p.errorf(t, "empty OID value")
+			}
+			return oid, nil
+		case t.kind == mibTokenNumber:
+			n, err := strconv.ParseUint(t.text, 10, 32)
+			if err != nil {
+				return nil, p.errorf(t, "invalid OID component %s", t)
+			}
+			oid = append(oid, mibOIDComponent{number: uint32(n), hasNumber: true})
+		case t.kind == mibTokenIdent:
+			component := mibOIDComponent{name: t.text}
+			if p.peek().text == "(" {
+				p.next()
+				n, err := p.expectNumber()
+				if err != nil {
+					return nil, err
+				}
+				if err := p.expect(")"); err != nil {
+					return nil, err
+				}
+				component.number, component.hasNumber = n, true
+			}
+			oid = append(oid, component)
+		default:
+			return nil, p.errorf(t, "unexpected %s in OID value", t)
+		}
+	}
+}
+
+// mibMacroKinds maps the macros defining nodes of the OID tree to the kind of node they define.
+var mibMacroKinds = map[string]mibNodeKind{
+	"OBJECT-TYPE":        mibNodeObject,
+	"NOTIFICATION-TYPE":  mibNodeNotification,
+	"TRAP-TYPE":          mibNodeTrap,
+	"MODULE-IDENTITY":    mibNodeIdentifier,
+	"OBJECT-IDENTITY":    mibNodeIdentifier,
+	"OBJECT-GROUP":       mibNodeIdentifier,
+	"NOTIFICATION-GROUP": mibNodeIdentifier,
+	"MODULE-COMPLIANCE":  mibNodeIdentifier,
+	"AGENT-CAPABILITIES": mibNodeIdentifier,
+}
+
+// parseMacroInvocation parses "name MACRO-NAME clauses ::= value". It returns nil for value
+// assignments which do not define a node of the OID tree.
+func (p *mibParser) parseMacroInvocation(name mibToken) (*mibNode, error) {
+	macro := p.next()
+	kind, isNode := mibMacroKinds[macro.text]
+	node := &mibNode{name: name.text, kind: kind, line: name.line}
+	for {
+		t := p.next()
+		switch {
+		case t.kind == mibTokenEOF:
+			return nil, p.errorf(t, "missing \"::=\" in definition of %s", name.text)
+		case t.kind == mibTokenSymbol && t.text == "{":
+			if err := p.skipBlock("{", "}"); err != nil {
+				return nil, err
+			}
+		case t.kind == mibTokenSymbol && t.text == "(":
+			if err := p.skipBlock("(", ")"); err != nil {
+				return nil, err
+			}
+		case t.kind == mibTokenIdent && t.text == "SYNTAX" && node.syntax == nil:
+			syntax, err := p.parseSyntax()
+			if err != nil {
+				return nil, err
+			}
+			node.syntax = syntax
+		case t.kind == mibTokenIdent && t.text == "DESCRIPTION" && node.description == "":
+			d := p.next()
+			if d.kind != mibTokenString {
+				return nil, p.errorf(d, "expected a string after DESCRIPTION, found %s", d)
+			}
+			node.description = strings.Join(strings.Fields(d.text), " ")
+		case t.kind == mibTokenIdent && t.text == "ENTERPRISE":
+			enterprise, err := p.expectIdent()
+			if err != nil {
+				return nil, err
+			}
+			node.enterprise = enterprise.text
+		case t.kind == mibTokenSymbol && t.text == "::=":
+			if !isNode {
+				// value assignment, such as "name INTEGER ::= 1"
+				if p.peek().text == "{" {
+					p.next()
+					return nil, p.skipBlock("{", "}")
+				}
+				p.next()
+				return nil, nil
+			}
+			if kind == mibNodeTrap {
+				n, err := p.expectNumber()
+				if err != nil {
+					return nil, err
+				}
+				node.trapNumber = n
+				return node, nil
+			}
+			oid, err := p.parseOIDValue()
+			if err != nil {
+				return nil, err
+			}
+			node.oid = oid
+			return node, nil
+		}
+	}
+}
@@ -35,6 +35,9 @@ func Module() fxutil.Module {
 
 const ddTrapDBFileNamePrefix string = "dd_traps_db"
 
+// mibsCacheFileName is the name of the file caching the compiled MIB files, in the run path.
+const mibsCacheFileName string = "mibs_cache.json"
+
 var nodesOIDThatShouldNeverMatch = []string{
 	"1.3.6.1.4.1", // "iso.org.dod.internet.private.enterprises". This OID and all its parents are known "intermediate" nodes
 	"1.3.6.1.4",   // "iso.org.dod.internet.private"
@@ -58,34 +61,73 @@ type multiFilesOIDResolver struct {
 }
 
 func newResolver(conf config.Component, logger log.Component) (oidresolver.Component, error) {
-	return newMultiFilesOIDResolver(conf.GetString("confd_path"), logger)
+	confdPath := conf.GetString("confd_path")
+	mibsPath := conf.GetString("network_devices.snmp_traps.mibs_path")
+	if mibsPath == "" {
+		mibsPath = filepath.Join(confdPath, "snmp.d", "mibs")
+	}
+	var mibsCachePath string
+	if runPath := conf.GetString("run_path"); runPath != "" {
+		mibsCachePath = filepath.Join(runPath, "snmp_traps", mibsCacheFileName)
+	}
+	return newMultiFilesOIDResolver(confdPath, mibsPath, mibsCachePath, logger)
 }
 
 // newMultiFilesOIDResolver creates a new MultiFilesOIDResolver instance by loading json or yaml files
-// (optionnally gzipped) located in the directory snmp.d/traps_db/
-func newMultiFilesOIDResolver(confdPath string, logger log.Component) (*multiFilesOIDResolver, error) {
+// (optionnally gzipped) located in the directory snmp.d/traps_db/, and the MIB files located in mibsPath.
+// The MIB files take precedence over Datadog's own database, and user-provided trap db files take
+// precedence over the MIB files. Either the trap db files or the MIB files may be missing, but not both.
+func newMultiFilesOIDResolver(confdPath string, mibsPath string, mibsCachePath string, logger log.Component) (*multiFilesOIDResolver, error) {
 	oidResolver := &multiFilesOIDResolver{
 		traps:  make(oidresolver.TrapSpec),
 		logger: logger,
 	}
 	trapsDBRoot := filepath.Join(confdPath, "snmp.d", "traps_db")
 	files, err := os.ReadDir(trapsDBRoot)
-	if err != nil {
+	if err != nil && !os.IsNotExist(err) {
 		return nil, fmt.Errorf("failed to read dir `%s`: %w", trapsDBRoot, err)
 	}
 	if len(files) == 0 {
-		return nil, fmt.Errorf("dir `%s` does not contain any trap db file", trapsDBRoot)
+		if mibFiles, _ := listMIBFiles(mibsPath); len(mibFiles) == 0 {
+			if err != nil {
+				return nil, fmt.Errorf("failed to read dir `%s` and dir `%s` does not contain any MIB file: %w", trapsDBRoot, mibsPath, err)
+			}
+			return nil, fmt.Errorf("dir `%s` does not contain any trap db file and dir `%s` does not contain any MIB file", trapsDBRoot, mibsPath)
+		}
+		logger.Debugf("no trap db file found in %s, only loading MIB files", trapsDBRoot)
 	}
 	fileNames := getSortedFileNames(files, logger)
+	mibsLoaded := false
 	for _, fileName := range fileNames {
+		if !mibsLoaded && !strings.HasPrefix(fileName, ddTrapDBFileNamePrefix) {
+			oidResolver.updateFromMIBDir(mibsPath, mibsCachePath)
+			mibsLoaded = true
+		}
 		err := oidResolver.updateFromFile(filepath.Join(trapsDBRoot, fileName))
 		if err != nil {
 			logger.Warnf("unable to load trap db file %s: %s", fileName, err)
 		}
 	}
+	if !mibsLoaded {
+		oidResolver.updateFromMIBDir(mibsPath, mibsCachePath)
+	}
 	return oidResolver, nil
 }
 
+// updateFromMIBDir loads the traps and variables defined by the MIB files located in mibsPath, if it exists.
+func (or *multiFilesOIDResolver) updateFromMIBDir(mibsPath string, mibsCachePath string) {
+	if _, err := os.Stat(mibsPath); os.IsNotExist(err) {
+		or.logger.Debugf("not loading MIB files: dir %s does not exist", mibsPath)
+		return
+	}
+	trapData, errs := loadMIBDir(mibsPath, mibsCachePath)
+	for _, err := range errs {
+		or.logger.Warnf("unable to load MIB files from %s: %s", mibsPath, err)
+	}
+	or.logger.Debugf("loaded %d traps and %d variables from MIB files in %s", len(trapData.Traps), len(trapData.Variables), mibsPath)
+	or.updateResolverWithData(trapData)
+}
+
 // GetTrapMetadata returns TrapMetadata for a given trapOID
 func (or *multiFilesOIDResolver) GetTrapMetadata(trapOID string) (oidresolver.TrapMetadata, error) {
 	trapOID = strings.TrimSuffix(oidresolver.NormalizeOID(trapOID), ".0")
@@ -70,6 +70,9 @@ func runAgentSidekicks(ag component) error {
 	// trace-agent would largely increase the number of module pulled by OTEL when using the pkg/trace go-module.
 	ag.Agent.DebugServer.AddRoute("/config", ag.config.GetConfigHandler())
 	ag.Agent.DebugServer.AddRoute("/config/set", ag.config.SetHandler())
+	// The trace inspector streams the processed chunks along with the decisions taken on them.
+	// It is used by the `trace-agent inspect` command.
+	ag.Agent.DebugServer.AddRoute("/debug/traces", ag.ipc.HTTPMiddleware(ag.Agent.TraceInspector))
 	// The below endpoint is deprecated and has been replaced with /config/set on the debug server.
 	// It will be removed in a future version.
 	api.AttachEndpoint(api.Endpoint{
@@ -578,6 +578,11 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.Equal(t, 0.5, cfg.ExtraSampleRate)
 	assert.Equal(t, 5.0, cfg.TargetTPS)
 	assert.Equal(t, 50.0, cfg.MaxEPS)
+	assert.True(t, cfg.LatencySamplerEnabled)
+	assert.Equal(t, 0.95, cfg.LatencySamplerPercentile)
+	assert.Equal(t, 2.0, cfg.LatencySamplerTPS)
+	assert.Equal(t, 100, cfg.LatencySamplerCardinality)
+	assert.EqualValues(t, 10000000, cfg.BytesBudgetPerMinute)
 	assert.Equal(t, 0.5, cfg.MaxCPU)
 	assert.EqualValues(t, 123.4, cfg.MaxMemory)
 	assert.Equal(t, "0.0.0.0", cfg.ReceiverHost)
@@ -640,6 +645,13 @@ func TestFullYamlConfig(t *testing.T) {
 	assert.True(t, o.Redis.Enabled)
 	assert.True(t, o.Memcached.Enabled)
 	assert.True(t, o.Memcached.KeepCommand)
+	assert.True(t, o.GraphQL.Enabled)
+	assert.True(t, o.CQL.Enabled)
+	assert.True(t, o.CQL.RemoveKeyspace)
+	assert.True(t, o.DynamoDB.Enabled)
+	assert.EqualValues(t, []string{"TableName"}, o.DynamoDB.KeepValues)
+	assert.True(t, o.Kafka.Enabled)
+	assert.EqualValues(t, []string{"content-type"}, o.Kafka.KeepHeaders)
 	assert.True(t, o.CreditCards.Enabled)
 	assert.True(t, o.CreditCards.Luhn)
 	assert.True(t, o.Cache.Enabled)
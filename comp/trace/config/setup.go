@@ -303,6 +303,26 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 		c.RareSamplerCardinality = core.GetInt("apm_config.rare_sampler.cardinality")
 	}
 
+	if core.IsSet("apm_config.latency_sampler.enabled") {
+		c.LatencySamplerEnabled = core.GetBool("apm_config.latency_sampler.enabled")
+	}
+	if core.IsSet("apm_config.latency_sampler.percentile") {
+		if p := core.GetFloat64("apm_config.latency_sampler.percentile"); p > 0 && p < 1 {
+			c.LatencySamplerPercentile = p
+		} else {
+			log.Warnf("Invalid apm_config.latency_sampler.percentile %v: it must be between 0 and 1 (exclusive), using default %v", p, c.LatencySamplerPercentile)
+		}
+	}
+	if core.IsSet("apm_config.latency_sampler.tps") {
+		c.LatencySamplerTPS = core.GetFloat64("apm_config.latency_sampler.tps")
+	}
+	if core.IsSet("apm_config.latency_sampler.cardinality") {
+		c.LatencySamplerCardinality = core.GetInt("apm_config.latency_sampler.cardinality")
+	}
+	if core.IsSet("apm_config.bytes_budget_per_minute") {
+		c.BytesBudgetPerMinute = core.GetInt64("apm_config.bytes_budget_per_minute")
+	}
+
 	if core.IsSet("apm_config.probabilistic_sampler.enabled") {
 		c.ProbabilisticSamplerEnabled = core.GetBool("apm_config.probabilistic_sampler.enabled")
 	}
@@ -468,6 +488,13 @@ func applyDatadogConfig(c *config.AgentConfig, core corecompcfg.Component) error
 	c.Obfuscation.Redis.RemoveAllArgs = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.redis.remove_all_args")
 	c.Obfuscation.Valkey.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.valkey.enabled")
 	c.Obfuscation.Valkey.RemoveAllArgs = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.valkey.remove_all_args")
+	c.Obfuscation.GraphQL.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.graphql.enabled")
+	c.Obfuscation.CQL.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.cql.enabled")
+	c.Obfuscation.CQL.RemoveKeyspace = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.cql.remove_keyspace")
+	c.Obfuscation.DynamoDB.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.dynamodb.enabled")
+	c.Obfuscation.DynamoDB.KeepValues = pkgconfigsetup.Datadog().GetStringSlice("apm_config.obfuscation.dynamodb.keep_values")
+	c.Obfuscation.Kafka.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.kafka.enabled")
+	c.Obfuscation.Kafka.KeepHeaders = pkgconfigsetup.Datadog().GetStringSlice("apm_config.obfuscation.kafka.keep_headers")
 	c.Obfuscation.CreditCards.Enabled = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.credit_cards.enabled")
 	c.Obfuscation.CreditCards.Luhn = pkgconfigsetup.Datadog().GetBool("apm_config.obfuscation.credit_cards.luhn")
 	c.Obfuscation.CreditCards.KeepValues = pkgconfigsetup.Datadog().GetStringSlice("apm_config.obfuscation.credit_cards.keep_values")
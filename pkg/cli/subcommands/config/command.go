@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 
 	"go.uber.org/fx"
 
@@ -31,6 +32,12 @@ type cliParams struct {
 	// source enables detailed information about each source and its value
 	source bool
 
+	// strict makes the validation fail on warnings too
+	strict bool
+
+	// skipChecks disables the validation of the check configurations
+	skipChecks bool
+
 	// args are the positional command line args
 	args []string
 }
@@ -101,6 +108,14 @@ func MakeCommand(globalParamsGetter func() GlobalParams) *cobra.Command {
 	cmd.AddCommand(getCmd)
 	getCmd.Flags().BoolVarP(&cliParams.source, "source", "s", false, "print every source and its value")
 
+	explainCmd := &cobra.Command{
+		Use:   "explain [setting]",
+		Short: "Show every source defining a value for a given configuration setting, and the one in effect",
+		Long:  ``,
+		RunE:  oneShotRunE(explainConfigValue),
+	}
+	cmd.AddCommand(explainCmd)
+
 	otelCmd := &cobra.Command{
 		Use:   "otel-agent",
 		Short: "Otel-agent, prints out the read-only runtime configs of otel-agent if otel-agent is present and converter is enabled",
@@ -109,6 +124,18 @@ func MakeCommand(globalParamsGetter func() GlobalParams) *cobra.Command {
 	}
 	cmd.AddCommand(otelCmd)
 
+	validateCmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the agent configuration file and the check configurations",
+		Long: `Report the unknown, mistyped and deprecated settings of the agent configuration file, the settings overridden
+by environment variables, and the invalid options of the check configurations found in confd_path.
+The command exits with a non-zero status when errors are found, or warnings with --strict.`,
+		RunE: oneShotRunE(validateConfig),
+	}
+	cmd.AddCommand(validateCmd)
+	validateCmd.Flags().BoolVar(&cliParams.strict, "strict", false, "fail on warnings too")
+	validateCmd.Flags().BoolVar(&cliParams.skipChecks, "skip-checks", false, "do not validate the check configurations")
+
 	return cmd
 }
 
@@ -229,6 +256,31 @@ func getConfigValue(_ log.Component, config config.Component, cliParams *cliPara
 	return nil
 }
 
+func explainConfigValue(_ log.Component, config config.Component, cliParams *cliParams) error {
+	if len(cliParams.args) != 1 {
+		return fmt.Errorf("a single setting name must be specified")
+	}
+
+	err := util.SetAuthToken(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := cliParams.GlobalParams.SettingsClient()
+	if err != nil {
+		return err
+	}
+
+	explanation, err := c.Explain(cliParams.args[0])
+	if err != nil {
+		return err
+	}
+
+	settings.PrintExplanation(os.Stdout, explanation)
+
+	return nil
+}
+
 func otelAgentCfg(_ log.Component, config config.Component, cliParams *cliParams) error {
 	if !config.GetBool("otelcollector.enabled") {
 		return errors.New("otel-agent is not enabled")
@@ -83,3 +83,38 @@ func TestConfigGetCommand(t *testing.T) {
 			require.Equal(t, false, secretParams.Enabled)
 		})
 }
+
+func TestConfigExplainCommand(t *testing.T) {
+	commands := []*cobra.Command{
+		MakeCommand(func() GlobalParams {
+			return GlobalParams{}
+		}),
+	}
+
+	fxutil.TestOneShotSubcommand(t,
+		commands,
+		[]string{"config", "explain", "foo"},
+		explainConfigValue,
+		func(cliParams *cliParams, _ core.BundleParams, secretParams secrets.Params) {
+			require.Equal(t, []string{"foo"}, cliParams.args)
+			require.Equal(t, false, secretParams.Enabled)
+		})
+}
+
+func TestConfigValidateCommand(t *testing.T) {
+	commands := []*cobra.Command{
+		MakeCommand(func() GlobalParams {
+			return GlobalParams{}
+		}),
+	}
+
+	fxutil.TestOneShotSubcommand(t,
+		commands,
+		[]string{"config", "validate", "--strict"},
+		validateConfig,
+		func(cliParams *cliParams, _ core.BundleParams, secretParams secrets.Params) {
+			require.Equal(t, true, cliParams.strict)
+			require.Equal(t, false, cliParams.skipChecks)
+			require.Equal(t, false, secretParams.Enabled)
+		})
+}
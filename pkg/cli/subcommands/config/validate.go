@@ -0,0 +1,381 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/DataDog/datadog-agent/comp/core/config"
+	log "github.com/DataDog/datadog-agent/comp/core/log/def"
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
+)
+
+type severity string
+
+const (
+	severityError   severity = "error"
+	severityWarning severity = "warning"
+)
+
+// validationIssue is a problem found in a configuration file
+type validationIssue struct {
+	severity severity
+	line     int
+	message  string
+}
+
+// validationReport lists the issues found in each configuration file
+type validationReport struct {
+	files  []string
+	issues map[string][]validationIssue
+}
+
+func newValidationReport() *validationReport {
+	return &validationReport{issues: map[string][]validationIssue{}}
+}
+
+func (r *validationReport) add(file string, sev severity, line int, format string, args ...interface{}) {
+	if _, found := r.issues[file]; !found {
+		r.files = append(r.files, file)
+	}
+	r.issues[file] = append(r.issues[file], validationIssue{severity: sev, line: line, message: fmt.Sprintf(format, args...)})
+}
+
+func (r *validationReport) count(sev severity) int {
+	count := 0
+	for _, issues := range r.issues {
+		for _, issue := range issues {
+			if issue.severity == sev {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+func (r *validationReport) write(w io.Writer) {
+	for _, file := range r.files {
+		issues := r.issues[file]
+		sort.SliceStable(issues, func(i, j int) bool { return issues[i].line < issues[j].line })
+
+		fmt.Fprintf(w, "%s:\n", file)
+		for _, issue := range issues {
+			if issue.line > 0 {
+				fmt.Fprintf(w, "  line %d: %s: %s\n", issue.line, issue.severity, issue.message)
+			} else {
+				fmt.Fprintf(w, "  %s: %s\n", issue.severity, issue.message)
+			}
+		}
+	}
+	fmt.Fprintf(w, "Found %d error(s) and %d warning(s)\n", r.count(severityError), r.count(severityWarning))
+}
+
+func validateConfig(_ log.Component, config config.Component, cliParams *cliParams) error {
+	report := newValidationReport()
+
+	if configFile := config.ConfigFileUsed(); configFile != "" {
+		data, err := os.ReadFile(configFile)
+		if err != nil {
+			return fmt.Errorf("unable to read %s: %w", configFile, err)
+		}
+		validateAgentConfig(config, configFile, data, os.LookupEnv, report)
+	}
+
+	if !cliParams.skipChecks {
+		if err := validateCheckConfigs(config.GetString("confd_path"), report); err != nil {
+			return err
+		}
+	}
+
+	report.write(os.Stdout)
+
+	if report.count(severityError) > 0 || (cliParams.strict && report.count(severityWarning) > 0) {
+		return errors.New("the configuration is invalid")
+	}
+	return nil
+}
+
+// rawSections are the sections of the configuration consumed as raw
+// mappings, whose content is not registered as settings
+var rawSections = map[string]struct{}{
+	// passed as is to the OTLP receiver of the collector
+	"otlp_config.receiver": {},
+}
+
+// agentConfigValidator checks the settings of an agent configuration file
+// against the settings known to the configuration.
+type agentConfigValidator struct {
+	config model.Reader
+	file   string
+	report *validationReport
+	lookup func(string) (string, bool)
+
+	known      map[string]interface{}
+	deprecated map[string]string
+	// sections contains the prefixes of the known settings, like "logs_config"
+	// for "logs_config.container_collect_all"
+	sections map[string]struct{}
+}
+
+// validateAgentConfig adds the unknown, deprecated and mistyped settings of
+// the configuration file, and the settings overridden by environment
+// variables, to the report
+func validateAgentConfig(config model.Reader, file string, data []byte, lookupEnv func(string) (string, bool), report *validationReport) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		report.add(file, severityError, 0, "invalid YAML: %s", err)
+		return
+	}
+	if len(root.Content) == 0 {
+		return
+	}
+
+	v := &agentConfigValidator{
+		config:     config,
+		file:       file,
+		report:     report,
+		lookup:     lookupEnv,
+		known:      config.GetKnownKeysLowercased(),
+		deprecated: pkgconfigsetup.DeprecatedSettings(),
+		sections:   map[string]struct{}{},
+	}
+	for key := range v.known {
+		for i, c := range key {
+			if c == '.' {
+				v.sections[key[:i]] = struct{}{}
+			}
+		}
+	}
+
+	document := resolveAlias(root.Content[0])
+	if document.Kind != yaml.MappingNode {
+		report.add(file, severityError, document.Line, "the configuration must be a mapping of settings")
+		return
+	}
+	v.walk("", document)
+}
+
+func (v *agentConfigValidator) walk(prefix string, node *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], resolveAlias(node.Content[i+1])
+		if keyNode.Value == "<<" {
+			// merge keys of YAML anchors
+			continue
+		}
+
+		key := strings.ToLower(keyNode.Value)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		_, isKnown := v.known[key]
+		_, isSection := v.sections[key]
+		_, isRaw := rawSections[key]
+		switch {
+		case isRaw:
+			continue
+		case isSection && valueNode.Kind == yaml.MappingNode:
+			v.walk(key, valueNode)
+		case isKnown:
+			v.checkSetting(key, keyNode, valueNode)
+		case isSection:
+			if !isNull(valueNode) {
+				v.report.add(v.file, severityError, keyNode.Line, "%q expects a mapping, got %s", key, nodeKind(valueNode))
+			}
+		default:
+			candidates := make([]string, 0, len(v.known)+len(v.sections))
+			for known := range v.known {
+				candidates = append(candidates, known)
+			}
+			for section := range v.sections {
+				candidates = append(candidates, section)
+			}
+			v.report.add(v.file, severityError, keyNode.Line, "unknown setting %q%s", key, didYouMean(key, candidates))
+		}
+	}
+}
+
+func (v *agentConfigValidator) checkSetting(key string, keyNode *yaml.Node, valueNode *yaml.Node) {
+	if replacement, deprecated := v.deprecated[key]; deprecated {
+		v.report.add(v.file, severityWarning, keyNode.Line, "%q is deprecated, use %s instead", key, replacement)
+	}
+
+	var defaultValue, fileValue, envValue interface{}
+	for _, source := range v.config.GetAllSources(key) {
+		switch source.Source {
+		case model.SourceDefault:
+			defaultValue = source.Value
+		case model.SourceFile:
+			fileValue = source.Value
+		case model.SourceEnvVar:
+			envValue = source.Value
+		}
+	}
+
+	if expected, ok := expectedKind(defaultValue); ok && !matchesKind(valueNode, expected) {
+		v.report.add(v.file, severityError, keyNode.Line, "%q expects %s, got %s", key, expected, nodeKind(valueNode))
+	}
+
+	if envValue != nil && fmt.Sprint(envValue) != fmt.Sprint(fileValue) {
+		envVar := "DD_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if _, found := v.lookup(envVar); found {
+			v.report.add(v.file, severityWarning, keyNode.Line, "%q is overridden by the %s environment variable", key, envVar)
+		} else {
+			v.report.add(v.file, severityWarning, keyNode.Line, "%q is overridden by an environment variable", key)
+		}
+	}
+}
+
+// valueKind is the kind of value expected by a setting
+type valueKind string
+
+const (
+	kindBoolean  valueKind = "a boolean"
+	kindNumber   valueKind = "a number"
+	kindDuration valueKind = "a duration"
+	kindString   valueKind = "a string"
+	kindList     valueKind = "a list"
+	kindMapping  valueKind = "a mapping"
+)
+
+// expectedKind returns the kind of value expected by a setting from its
+// default value
+func expectedKind(defaultValue interface{}) (valueKind, bool) {
+	switch defaultValue.(type) {
+	case nil:
+		return "", false
+	case bool:
+		return kindBoolean, true
+	case time.Duration:
+		return kindDuration, true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return kindNumber, true
+	case string:
+		return kindString, true
+	case []string, []interface{}, []map[string]string, []map[string]interface{}:
+		return kindList, true
+	case map[string]string, map[string]interface{}, map[string][]string, map[interface{}]interface{}:
+		return kindMapping, true
+	}
+	return "", false
+}
+
+// matchesKind returns whether the value can be used for a setting expecting
+// the given kind of value, allowing the conversions done when reading the
+// configuration
+func matchesKind(node *yaml.Node, kind valueKind) bool {
+	if isNull(node) {
+		return true
+	}
+	switch kind {
+	case kindBoolean:
+		if node.Kind != yaml.ScalarNode {
+			return false
+		}
+		_, err := strconv.ParseBool(node.Value)
+		return node.Tag == "!!bool" || err == nil
+	case kindNumber:
+		if node.Kind != yaml.ScalarNode {
+			return false
+		}
+		_, err := strconv.ParseFloat(node.Value, 64)
+		return node.Tag == "!!int" || node.Tag == "!!float" || err == nil
+	case kindDuration:
+		if node.Kind != yaml.ScalarNode {
+			return false
+		}
+		_, err := time.ParseDuration(node.Value)
+		return node.Tag == "!!int" || node.Tag == "!!float" || err == nil
+	case kindString:
+		return node.Kind == yaml.ScalarNode
+	case kindList:
+		// lists can also be given as space separated strings
+		return node.Kind == yaml.SequenceNode || (node.Kind == yaml.ScalarNode && node.Tag == "!!str")
+	case kindMapping:
+		// mappings can also be given as JSON strings
+		return node.Kind == yaml.MappingNode || (node.Kind == yaml.ScalarNode && node.Tag == "!!str")
+	}
+	return true
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+// nodeKind describes the kind of a YAML value for error messages
+func nodeKind(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+	switch node.Tag {
+	case "!!bool":
+		return fmt.Sprintf("the boolean %s", node.Value)
+	case "!!int", "!!float":
+		return fmt.Sprintf("the number %s", node.Value)
+	case "!!null":
+		return "null"
+	}
+	return fmt.Sprintf("the string %q", node.Value)
+}
+
+// didYouMean returns a suggestion for a misspelled name among the candidates,
+// or an empty string when none is close enough
+func didYouMean(name string, candidates []string) string {
+	best := ""
+	bestDistance := 0
+	for _, candidate := range candidates {
+		distance := editDistance(name, candidate)
+		if best == "" || distance < bestDistance || (distance == bestDistance && candidate < best) {
+			best, bestDistance = candidate, distance
+		}
+	}
+	// only suggest names with a few typos
+	if best == "" || bestDistance > 3 || bestDistance*2 > len(name) {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %q?", best)
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
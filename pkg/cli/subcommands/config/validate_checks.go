@@ -0,0 +1,353 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkConfigKeys are the top-level settings of a check configuration file
+var checkConfigKeys = []string{
+	"ad_identifiers",
+	"advanced_ad_identifiers",
+	"check_tag_cardinality",
+	"cluster_check",
+	"docker_images",
+	"ignore_autodiscovery_tags",
+	"init_config",
+	"instances",
+	"jmx_metrics",
+	"logs",
+}
+
+// commonInstanceOptions are the instance options handled by the agent for
+// every check
+var commonInstanceOptions = []string{
+	"empty_default_hostname",
+	"min_collection_interval",
+	"name",
+	"namespace",
+	"no_index",
+	"service",
+	"tags",
+}
+
+// commonInitConfigOptions are the init_config options handled by the agent
+// for every check
+var commonInitConfigOptions = []string{
+	"service",
+}
+
+// specParam is an option documented in the conf.yaml.example file of an
+// integration
+type specParam struct {
+	name     string
+	kind     string
+	required bool
+}
+
+// integrationSpec lists the documented options of an integration
+type integrationSpec struct {
+	initConfig []specParam
+	instances  []specParam
+}
+
+// validateCheckConfigs adds the issues of the check configuration files found
+// in confdPath to the report. Instance and init_config options are validated
+// against the conf.yaml.example file of the integration, when there is one.
+func validateCheckConfigs(confdPath string, report *validationReport) error {
+	if confdPath == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(confdPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to list the check configurations in %s: %w", confdPath, err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			if name, ok := checkConfigName(entry.Name()); ok {
+				validateCheckConfig(filepath.Join(confdPath, entry.Name()), loadIntegrationSpec(filepath.Join(confdPath, name+".d")), report)
+			}
+			continue
+		}
+		if filepath.Ext(entry.Name()) != ".d" {
+			continue
+		}
+
+		dir := filepath.Join(confdPath, entry.Name())
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			report.add(dir, severityError, 0, "unable to list the check configurations: %s", err)
+			continue
+		}
+		spec := loadIntegrationSpec(dir)
+		for _, file := range files {
+			if _, ok := checkConfigName(file.Name()); ok && !file.IsDir() {
+				validateCheckConfig(filepath.Join(dir, file.Name()), spec, report)
+			}
+		}
+	}
+	return nil
+}
+
+// checkConfigName returns the name of the check configured by a file, and
+// whether the file is loaded by the agent as a check configuration
+func checkConfigName(fileName string) (string, bool) {
+	if fileName == "metrics.yaml" || fileName == "metrics.yml" {
+		return "", false
+	}
+	name := strings.TrimSuffix(fileName, ".default")
+	ext := filepath.Ext(name)
+	if ext != ".yaml" && ext != ".yml" {
+		return "", false
+	}
+	return strings.TrimSuffix(name, ext), true
+}
+
+func validateCheckConfig(file string, spec *integrationSpec, report *validationReport) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		report.add(file, severityError, 0, "unable to read the file: %s", err)
+		return
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		report.add(file, severityError, 0, "invalid YAML: %s", err)
+		return
+	}
+	if len(root.Content) == 0 {
+		report.add(file, severityError, 0, "the file is empty")
+		return
+	}
+	document := resolveAlias(root.Content[0])
+	if document.Kind != yaml.MappingNode {
+		report.add(file, severityError, document.Line, "the check configuration must be a mapping")
+		return
+	}
+
+	hasInstances := false
+	for i := 0; i+1 < len(document.Content); i += 2 {
+		keyNode, valueNode := document.Content[i], resolveAlias(document.Content[i+1])
+		switch keyNode.Value {
+		case "instances":
+			hasInstances = true
+			validateInstances(file, valueNode, spec, report)
+		case "init_config":
+			if valueNode.Kind == yaml.MappingNode && spec != nil {
+				validateOptions(file, "init_config", valueNode, spec.initConfig, commonInitConfigOptions, false, report)
+			} else if valueNode.Kind != yaml.MappingNode && !isNull(valueNode) {
+				report.add(file, severityError, keyNode.Line, "\"init_config\" expects a mapping, got %s", nodeKind(valueNode))
+			}
+		case "docker_images":
+			report.add(file, severityWarning, keyNode.Line, "\"docker_images\" is deprecated, use \"ad_identifiers\" instead")
+		default:
+			if !slices.Contains(checkConfigKeys, keyNode.Value) {
+				report.add(file, severityError, keyNode.Line, "unknown setting %q%s", keyNode.Value, didYouMean(keyNode.Value, checkConfigKeys))
+			}
+		}
+	}
+
+	if !hasInstances && !strings.HasSuffix(file, ".default") && !hasKey(document, "logs") {
+		report.add(file, severityError, 0, "no \"instances\" or \"logs\" configured")
+	}
+}
+
+func validateInstances(file string, node *yaml.Node, spec *integrationSpec, report *validationReport) {
+	if isNull(node) {
+		return
+	}
+	if node.Kind != yaml.SequenceNode {
+		report.add(file, severityError, node.Line, "\"instances\" expects a list, got %s", nodeKind(node))
+		return
+	}
+	for _, instance := range node.Content {
+		instance = resolveAlias(instance)
+		if instance.Kind != yaml.MappingNode {
+			report.add(file, severityError, instance.Line, "each instance must be a mapping, got %s", nodeKind(instance))
+			continue
+		}
+		if spec != nil {
+			validateOptions(file, "instances", instance, spec.instances, commonInstanceOptions, true, report)
+		}
+	}
+}
+
+// validateOptions checks the options of an instance or of init_config against
+// the documented ones. As the specs may not document every option, unknown
+// and missing options are only reported as warnings.
+func validateOptions(file string, section string, node *yaml.Node, params []specParam, common []string, checkRequired bool, report *validationReport) {
+	if len(params) == 0 {
+		return
+	}
+	candidates := append([]string{}, common...)
+	for _, param := range params {
+		candidates = append(candidates, param.name)
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], resolveAlias(node.Content[i+1])
+		if keyNode.Value == "<<" {
+			continue
+		}
+		param, found := findParam(params, keyNode.Value)
+		if !found {
+			if !slices.Contains(common, keyNode.Value) {
+				report.add(file, severityWarning, keyNode.Line, "unknown %s option %q%s", section, keyNode.Value, didYouMean(keyNode.Value, candidates))
+			}
+			continue
+		}
+		if expected, ok := matchesSpecType(valueNode, param.kind); !ok {
+			report.add(file, severityError, keyNode.Line, "%s option %q expects %s, got %s", section, keyNode.Value, expected, nodeKind(valueNode))
+		}
+	}
+
+	if !checkRequired {
+		return
+	}
+	for _, param := range params {
+		if param.required && !hasKey(node, param.name) {
+			report.add(file, severityWarning, node.Line, "missing required %s option %q", section, param.name)
+		}
+	}
+}
+
+// matchesSpecType returns whether a value matches the type of a documented
+// option, and the description of the expected type. Template variables and
+// secrets are accepted in place of any scalar.
+func matchesSpecType(node *yaml.Node, kind string) (string, bool) {
+	if isNull(node) {
+		return "", true
+	}
+	placeholder := node.Kind == yaml.ScalarNode && node.Tag == "!!str" &&
+		(strings.Contains(node.Value, "%%") || strings.Contains(node.Value, "ENC["))
+
+	switch {
+	case kind == "string":
+		return "a string", node.Kind == yaml.ScalarNode
+	case kind == "integer":
+		_, err := strconv.ParseInt(node.Value, 10, 64)
+		return "an integer", node.Kind == yaml.ScalarNode && (err == nil || placeholder)
+	case kind == "number" || kind == "float":
+		_, err := strconv.ParseFloat(node.Value, 64)
+		return "a number", node.Kind == yaml.ScalarNode && (err == nil || placeholder)
+	case kind == "boolean":
+		_, err := strconv.ParseBool(node.Value)
+		return "a boolean", node.Kind == yaml.ScalarNode && (node.Tag == "!!bool" || err == nil || placeholder)
+	case strings.HasPrefix(kind, "list") || strings.HasPrefix(kind, "array"):
+		return "a list", node.Kind == yaml.SequenceNode || placeholder
+	case kind == "mapping" || kind == "object" || kind == "dictionary":
+		return "a mapping", node.Kind == yaml.MappingNode || placeholder
+	}
+	return "", true
+}
+
+// loadIntegrationSpec parses the options documented with "@param" in the
+// conf.yaml.example file of an integration. It returns nil when there is no
+// such file.
+func loadIntegrationSpec(dir string) *integrationSpec {
+	f, err := os.Open(filepath.Join(dir, "conf.yaml.example"))
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	type indentedParam struct {
+		specParam
+		indent int
+	}
+	params := map[string][]indentedParam{}
+	section := ""
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" && line[0] != ' ' && line[0] != '#' {
+			section = strings.TrimSuffix(strings.TrimSpace(line), ":")
+			continue
+		}
+
+		// the first option of an instance follows the list marker
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+		if strings.HasPrefix(trimmed, "- ") {
+			trimmed = strings.TrimLeft(trimmed[2:], " ")
+			indent = len(line) - len(trimmed)
+		}
+		if !strings.HasPrefix(trimmed, "## @param ") {
+			continue
+		}
+
+		fields := strings.Split(strings.TrimPrefix(trimmed, "## @param "), " - ")
+		if len(fields) < 3 {
+			continue
+		}
+		params[section] = append(params[section], indentedParam{
+			specParam: specParam{
+				name:     strings.TrimSpace(fields[0]),
+				kind:     strings.TrimSpace(fields[1]),
+				required: strings.TrimSpace(fields[2]) == "required",
+			},
+			indent: indent,
+		})
+	}
+	if scanner.Err() != nil || len(params) == 0 {
+		return nil
+	}
+
+	// nested options are documented with a larger indentation than the
+	// options of the section
+	topLevel := func(params []indentedParam) []specParam {
+		minIndent := -1
+		for _, param := range params {
+			if minIndent == -1 || param.indent < minIndent {
+				minIndent = param.indent
+			}
+		}
+		var result []specParam
+		for _, param := range params {
+			if param.indent == minIndent {
+				result = append(result, param.specParam)
+			}
+		}
+		return result
+	}
+	return &integrationSpec{
+		initConfig: topLevel(params["init_config"]),
+		instances:  topLevel(params["instances"]),
+	}
+}
+
+func findParam(params []specParam, name string) (specParam, bool) {
+	for _, param := range params {
+		if param.name == name {
+			return param, true
+		}
+	}
+	return specParam{}, false
+}
+
+func hasKey(node *yaml.Node, key string) bool {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,244 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+)
+
+func noEnv(string) (string, bool) { return "", false }
+
+func issueMessages(report *validationReport, file string) []string {
+	var messages []string
+	for _, issue := range report.issues[file] {
+		messages = append(messages, string(issue.severity)+": "+issue.message)
+	}
+	return messages
+}
+
+func TestValidateAgentConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name: "valid",
+			content: `
+logs_enabled: true
+dogstatsd_port: "8126"
+logs_config:
+  container_collect_all: true
+`,
+		},
+		{
+			name:     "unknown setting",
+			content:  "dogstatsd_prot: 8126\n",
+			expected: []string{`error: unknown setting "dogstatsd_prot", did you mean "dogstatsd_port"?`},
+		},
+		{
+			name: "unknown nested setting",
+			content: `
+logs_config:
+  container_collect_al: true
+`,
+			expected: []string{`error: unknown setting "logs_config.container_collect_al", did you mean "logs_config.container_collect_all"?`},
+		},
+		{
+			name:     "unknown setting without suggestion",
+			content:  "something_else_entirely: 1\n",
+			expected: []string{`error: unknown setting "something_else_entirely"`},
+		},
+		{
+			name: "raw section",
+			content: `
+otlp_config:
+  receiver:
+    protocols:
+      grpc:
+        endpoint: 0.0.0.0:4317
+        max_recv_msg_size_mib: 16
+        auth:
+          authenticator: oidc
+`,
+		},
+		{
+			name:     "type mismatch",
+			content:  "check_runners: four\n",
+			expected: []string{`error: "check_runners" expects a number, got the string "four"`},
+		},
+		{
+			name:     "section with a scalar",
+			content:  "logs_config: true\n",
+			expected: []string{`error: "logs_config" expects a mapping, got the boolean true`},
+		},
+		{
+			name:     "deprecated setting",
+			content:  "log_enabled: true\n",
+			expected: []string{`warning: "log_enabled" is deprecated, use logs_enabled instead`},
+		},
+		{
+			name:     "invalid YAML",
+			content:  "logs_enabled: [true\n",
+			expected: []string{"error: invalid YAML: yaml: line 1: did not find expected ',' or ']'"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := mock.New(t)
+			report := newValidationReport()
+
+			validateAgentConfig(cfg, "datadog.yaml", []byte(test.content), noEnv, report)
+
+			assert.Equal(t, test.expected, issueMessages(report, "datadog.yaml"))
+		})
+	}
+}
+
+func TestValidateAgentConfigEnvOverride(t *testing.T) {
+	cfg := mock.New(t)
+	cfg.Set("dogstatsd_port", 8126, model.SourceFile)
+	cfg.Set("dogstatsd_port", 8127, model.SourceEnvVar)
+	cfg.Set("check_runners", 2, model.SourceFile)
+	cfg.Set("check_runners", 2, model.SourceEnvVar)
+
+	lookupEnv := func(name string) (string, bool) {
+		if name == "DD_DOGSTATSD_PORT" {
+			return "8127", true
+		}
+		return "", false
+	}
+	report := newValidationReport()
+	validateAgentConfig(cfg, "datadog.yaml", []byte("dogstatsd_port: 8126\ncheck_runners: 2\n"), lookupEnv, report)
+
+	assert.Equal(t, []string{`warning: "dogstatsd_port" is overridden by the DD_DOGSTATSD_PORT environment variable`}, issueMessages(report, "datadog.yaml"))
+}
+
+const redisExample = `init_config:
+
+    ## @param service - string - optional
+    ## Attach the tag ` + "`service:<SERVICE>`" + ` to every metric.
+    #
+    # service: <SERVICE>
+
+instances:
+
+  - ## @param host - string - required
+    ## Enter the host to connect to.
+    #
+    host: localhost
+
+    ## @param port - integer - required
+    ## Enter the port of the host to connect to.
+    #
+    port: 6379
+
+    ## @param keys - list of strings - optional
+    ## Enter the list of keys to collect the lengths from.
+    #
+    # keys:
+    #   - <KEY_1>
+
+    ## @param ssl_options - mapping - optional
+    ## Options of the TLS connections.
+    #
+    # ssl_options:
+    #
+    #   ## @param ssl_verify - boolean - optional
+    #   ## Verify the certificate of the server.
+    #   #
+    #   ssl_verify: true
+`
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestLoadIntegrationSpec(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "conf.yaml.example"), redisExample)
+
+	spec := loadIntegrationSpec(dir)
+
+	require.NotNil(t, spec)
+	assert.Equal(t, []specParam{{name: "service", kind: "string"}}, spec.initConfig)
+	assert.Equal(t, []specParam{
+		{name: "host", kind: "string", required: true},
+		{name: "port", kind: "integer", required: true},
+		{name: "keys", kind: "list of strings"},
+		{name: "ssl_options", kind: "mapping"},
+	}, spec.instances)
+
+	assert.Nil(t, loadIntegrationSpec(t.TempDir()))
+}
+
+func TestValidateCheckConfigs(t *testing.T) {
+	confd := t.TempDir()
+	writeFile(t, filepath.Join(confd, "redisdb.d", "conf.yaml.example"), redisExample)
+	writeFile(t, filepath.Join(confd, "redisdb.d", "conf.yaml"), `
+init_config:
+  sevice: redis
+instances:
+  - host: localhost
+    port: "%%port%%"
+    keys: mykey
+    tags:
+      - env:prod
+  - prot: 6379
+    host: localhost
+    port: six
+`)
+	writeFile(t, filepath.Join(confd, "redisdb.d", "metrics.yaml"), "not: [valid\n")
+	writeFile(t, filepath.Join(confd, "redisdb.d", "conf.yaml.default"), "ad_identifiers:\n  - redis\n")
+	writeFile(t, filepath.Join(confd, "custom.yaml"), `
+instance:
+  - foo: bar
+docker_images:
+  - custom
+`)
+	writeFile(t, filepath.Join(confd, "other.yaml"), "instances: foo\n")
+
+	report := newValidationReport()
+	require.NoError(t, validateCheckConfigs(confd, report))
+
+	assert.Equal(t, []string{
+		`warning: unknown init_config option "sevice", did you mean "service"?`,
+		`error: instances option "keys" expects a list, got the string "mykey"`,
+		`warning: unknown instances option "prot", did you mean "port"?`,
+		`error: instances option "port" expects an integer, got the string "six"`,
+	}, issueMessages(report, filepath.Join(confd, "redisdb.d", "conf.yaml")))
+	assert.Equal(t, []string{
+		`error: unknown setting "instance", did you mean "instances"?`,
+		`warning: "docker_images" is deprecated, use "ad_identifiers" instead`,
+		`error: no "instances" or "logs" configured`,
+	}, issueMessages(report, filepath.Join(confd, "custom.yaml")))
+	assert.Equal(t, []string{
+		`error: "instances" expects a list, got the string "foo"`,
+	}, issueMessages(report, filepath.Join(confd, "other.yaml")))
+	assert.Len(t, report.files, 3)
+
+	var output bytes.Buffer
+	report.write(&output)
+	assert.Contains(t, output.String(), "  line 3: warning: unknown init_config option \"sevice\"")
+	assert.Contains(t, output.String(), "Found 5 error(s) and 3 warning(s)\n")
+}
+
+func TestValidateCheckConfigsMissingDirectory(t *testing.T) {
+	report := newValidationReport()
+	require.NoError(t, validateCheckConfigs(filepath.Join(t.TempDir(), "missing"), report))
+	assert.Empty(t, report.files)
+}
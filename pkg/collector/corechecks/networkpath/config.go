@@ -43,6 +43,9 @@ type InstanceConfig struct {
 	Protocol  string `yaml:"protocol"`
 	TCPMethod string `yaml:"tcp_method"`
 
+	NumPaths     uint16 `yaml:"num_paths"`
+	ProbesPerHop uint8  `yaml:"probes_per_hop"`
+
 	SourceService      string `yaml:"source_service"`
 	DestinationService string `yaml:"destination_service"`
 
@@ -65,6 +68,8 @@ type CheckConfig struct {
 	MaxTTL                uint8
 	Protocol              payload.Protocol
 	TCPMethod             payload.TCPMethod
+	NumPaths              uint16
+	ProbesPerHop          uint8
 	Timeout               time.Duration
 	MinCollectionInterval time.Duration
 	Tags                  []string
@@ -94,6 +99,8 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 	c.DestinationService = instance.DestinationService
 	c.Protocol = payload.Protocol(strings.ToUpper(instance.Protocol))
 	c.TCPMethod = payload.MakeTCPMethod(instance.TCPMethod)
+	c.NumPaths = instance.NumPaths
+	c.ProbesPerHop = instance.ProbesPerHop
 
 	c.MinCollectionInterval = firstNonZero(
 		time.Duration(instance.MinCollectionInterval)*time.Second,
@@ -347,6 +347,27 @@ tcp_method: prefer_SACK
 				TCPMethod:             payload.TCPConfigPreferSACK,
 			},
 		},
+		{
+			name: "multipath ICMP",
+			rawInstance: []byte(`
+hostname: 1.2.3.4
+protocol: icmp
+num_paths: 8
+probes_per_hop: 3
+`),
+			rawInitConfig: []byte(``),
+			expectedConfig: &CheckConfig{
+				DestHostname:          "1.2.3.4",
+				MinCollectionInterval: time.Duration(60) * time.Second,
+				Namespace:             "my-namespace",
+				Protocol:              payload.ProtocolICMP,
+				Timeout:               setup.DefaultNetworkPathTimeout * time.Millisecond,
+				MaxTTL:                setup.DefaultNetworkPathMaxTTL,
+				TCPMethod:             payload.TCPMethod(""),
+				NumPaths:              8,
+				ProbesPerHop:          3,
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
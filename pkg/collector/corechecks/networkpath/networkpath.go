@@ -57,6 +57,8 @@ func (c *Check) Run() error {
 		Timeout:      c.config.Timeout,
 		Protocol:     c.config.Protocol,
 		TCPMethod:    c.config.TCPMethod,
+		NumPaths:     c.config.NumPaths,
+		ProbesPerHop: c.config.ProbesPerHop,
 	}
 
 	tr, err := traceroute.New(cfg, c.telemetryComp)
@@ -3072,6 +3072,54 @@ profiles:
                     "description": "RemoteDev2-Port1-Description"
                 }
             }
+        },
+        {
+            "id": "profile-metadata:1.2.3.4:1.5",
+            "source_type": "cdp",
+            "integration": "snmp",
+            "local": {
+                "device": {
+                    "dd_id": "profile-metadata:1.2.3.4"
+                },
+                "interface": {
+                    "dd_id": "profile-metadata:1.2.3.4:1",
+					"id": ""
+                }
+            },
+            "remote": {
+                "device": {
+                    "id": "K10-ITV.tine.no",
+                    "ip_address": "10.10.0.134"
+                },
+                "interface": {
+                    "id": "GE0/1",
+                    "id_type": "interface_name"
+                }
+            }
+        },
+        {
+            "id": "profile-metadata:1.2.3.4:2.3",
+            "source_type": "cdp",
+            "integration": "snmp",
+            "local": {
+                "device": {
+                    "dd_id": "profile-metadata:1.2.3.4"
+                },
+                "interface": {
+                    "dd_id": "profile-metadata:1.2.3.4:2",
+                    "id": ""
+                }
+            },
+            "remote": {
+                "device": {
+                    "id": "K06-ITV.tine.no",
+                    "ip_address": "10.10.0.132"
+                },
+                "interface": {
+                    "id": "GE0/2",
+                    "id_type": "interface_name"
+                }
+            }
         }
   ],
   "diagnoses": [
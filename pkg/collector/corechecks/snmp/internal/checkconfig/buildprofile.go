@@ -70,7 +70,7 @@ func (c *CheckConfig) BuildProfile(sysObjectID string) (profiledefinition.Profil
 		profile.MetricTags = append(profile.MetricTags, rootProfile.MetricTags...)
 		profile.Device.Vendor = rootProfile.Device.Vendor
 	}
-	profile.Metadata = updateMetadataDefinitionWithDefaults(profile.Metadata, c.CollectTopology)
+	profile.Metadata = updateMetadataDefinitionWithDefaults(profile.Metadata, c.CollectTopology, c.CollectCDPTopology)
 
 	return profile, profileErr
 }
@@ -12,6 +12,7 @@ import (
 	"github.com/DataDog/datadog-agent/comp/remote-config/rcclient"
 	"hash/fnv"
 	"net"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -90,6 +91,7 @@ type InitConfig struct {
 	BulkMaxRepetitions    Number                            `yaml:"bulk_max_repetitions"`
 	CollectDeviceMetadata Boolean                           `yaml:"collect_device_metadata"`
 	CollectTopology       Boolean                           `yaml:"collect_topology"`
+	CollectCDPTopology    Boolean                           `yaml:"collect_cdp_topology"`
 	UseDeviceIDAsHostname Boolean                           `yaml:"use_device_id_as_hostname"`
 	MinCollectionInterval int                               `yaml:"min_collection_interval"`
 	Namespace             string                            `yaml:"namespace"`
@@ -118,6 +120,7 @@ type InstanceConfig struct {
 	UseGlobalMetrics      bool                                `yaml:"use_global_metrics"`
 	CollectDeviceMetadata *Boolean                            `yaml:"collect_device_metadata"`
 	CollectTopology       *Boolean                            `yaml:"collect_topology"`
+	CollectCDPTopology    *Boolean                            `yaml:"collect_cdp_topology"`
 	UseDeviceIDAsHostname *Boolean                            `yaml:"use_device_id_as_hostname"`
 	PingConfig            snmpintegration.PackedPingConfig    `yaml:"ping"`
 	Loader                string                              `yaml:"loader"`
@@ -154,6 +157,11 @@ type InstanceConfig struct {
 	// `interface_configs` option is not supported by SNMP corecheck autodiscovery (`network_address`)
 	// it's only supported for single device instance (`ip_address`)
 	InterfaceConfigs InterfaceConfigs `yaml:"interface_configs"`
+
+	// WalkFile is an snmpwalk output or .snmprec file the device is served from,
+	// instead of being queried over the network. Relative paths are resolved
+	// from the `snmp.d` folder of `confd_path`.
+	WalkFile string `yaml:"walk_file"`
 }
 
 // CheckConfig holds config needed for an integration instance to run
@@ -171,6 +179,8 @@ type CheckConfig struct {
 	PrivProtocol    string
 	PrivKey         string
 	ContextName     string
+	// WalkFile is the recorded walk the device is served from, if any.
+	WalkFile string
 	// RequestedMetrics are the metrics explicitly requested by config.
 	RequestedMetrics []profiledefinition.MetricsConfig
 	// RequestedMetricTags are the tags explicitly requested by config.
@@ -183,6 +193,7 @@ type CheckConfig struct {
 	InstanceTags          []string
 	CollectDeviceMetadata bool
 	CollectTopology       bool
+	CollectCDPTopology    bool
 	UseDeviceIDAsHostname bool
 	DeviceID              string
 	DeviceIDTags          []string
@@ -279,6 +290,7 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 	instance.UseGlobalMetrics = true
 	initConfig.CollectDeviceMetadata = true
 	initConfig.CollectTopology = true
+	initConfig.CollectCDPTopology = true
 
 	err := yaml.Unmarshal(rawInitConfig, &initConfig)
 	if err != nil {
@@ -312,6 +324,13 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 		}
 	}
 
+	if instance.WalkFile != "" {
+		if c.Network != "" {
+			return nil, fmt.Errorf("`walk_file` and `network` cannot be used at the same time")
+		}
+		c.WalkFile = resolveWalkFilePath(instance.WalkFile)
+	}
+
 	if instance.CollectDeviceMetadata != nil {
 		c.CollectDeviceMetadata = bool(*instance.CollectDeviceMetadata)
 	} else {
@@ -324,6 +343,12 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 		c.CollectTopology = bool(initConfig.CollectTopology)
 	}
 
+	if instance.CollectCDPTopology != nil {
+		c.CollectCDPTopology = bool(*instance.CollectCDPTopology)
+	} else {
+		c.CollectCDPTopology = bool(initConfig.CollectCDPTopology)
+	}
+
 	if instance.UseDeviceIDAsHostname != nil {
 		c.UseDeviceIDAsHostname = bool(*instance.UseDeviceIDAsHostname)
 	} else {
@@ -499,6 +524,10 @@ func NewCheckConfig(rawInstance integration.Data, rawInitConfig integration.Data
 	} else if initConfig.PingConfig.Enabled != nil {
 		c.PingEnabled = bool(*initConfig.PingConfig.Enabled)
 	}
+	if c.WalkFile != "" && c.PingEnabled {
+		log.Debugf("ping is disabled for device %s since it is served from walk file %s", c.IPAddress, c.WalkFile)
+		c.PingEnabled = false
+	}
 
 	if instance.PingConfig.Interval != nil {
 		c.PingConfig.Interval = time.Duration(*instance.PingConfig.Interval) * time.Millisecond
@@ -604,6 +633,7 @@ func (c *CheckConfig) Copy() *CheckConfig {
 	newConfig.PrivKey = c.PrivKey
 	newConfig.ContextName = c.ContextName
 	newConfig.ContextName = c.ContextName
+	newConfig.WalkFile = c.WalkFile
 	newConfig.RequestedMetrics = make([]profiledefinition.MetricsConfig, len(c.RequestedMetrics))
 	copy(newConfig.RequestedMetrics, c.RequestedMetrics)
 
@@ -617,6 +647,7 @@ func (c *CheckConfig) Copy() *CheckConfig {
 	newConfig.InstanceTags = netutils.CopyStrings(c.InstanceTags)
 	newConfig.CollectDeviceMetadata = c.CollectDeviceMetadata
 	newConfig.CollectTopology = c.CollectTopology
+	newConfig.CollectCDPTopology = c.CollectCDPTopology
 	newConfig.UseDeviceIDAsHostname = c.UseDeviceIDAsHostname
 	newConfig.DeviceID = c.DeviceID
 
@@ -635,6 +666,15 @@ func (c *CheckConfig) Copy() *CheckConfig {
 	return &newConfig
 }
 
+// resolveWalkFilePath returns the path of a walk file, relative paths being
+// resolved from the `snmp.d` folder of `confd_path`.
+func resolveWalkFilePath(walkFile string) string {
+	if filepath.IsAbs(walkFile) {
+		return walkFile
+	}
+	return filepath.Join(pkgconfigsetup.Datadog().GetString("confd_path"), "snmp.d", walkFile)
+}
+
 // CopyWithNewIP makes a copy of CheckConfig with new IP
 func (c *CheckConfig) CopyWithNewIP(ipAddress string) *CheckConfig {
 	newConfig := c.Copy()
@@ -643,6 +683,19 @@ func (c *CheckConfig) CopyWithNewIP(ipAddress string) *CheckConfig {
 	return newConfig
 }
 
+// CopyWithContext makes a copy of CheckConfig polling the given SNMP context:
+// the context name is used as is with SNMPv3, and appended to the community
+// string (`community@context`) with SNMPv1/v2c. The device ID is unchanged.
+func (c *CheckConfig) CopyWithContext(contextName string) *CheckConfig {
+	newConfig := c.Copy()
+	if newConfig.CommunityString != "" {
+		newConfig.CommunityString = c.CommunityString + "@" + contextName
+	} else {
+		newConfig.ContextName = contextName
+	}
+	return newConfig
+}
+
 // IsDiscovery return weather it's a network/autodiscovery config or not
 func (c *CheckConfig) IsDiscovery() bool {
 	return c.Network != ""
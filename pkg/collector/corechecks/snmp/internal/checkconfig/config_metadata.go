@@ -181,6 +181,10 @@ var TopologyMetadataConfig = profiledefinition.MetadataConfig{
 			},
 		},
 	},
+}
+
+// CDPTopologyMetadataConfig represent the metadata needed for topology from CDP (CISCO-CDP-MIB) neighbors
+var CDPTopologyMetadataConfig = profiledefinition.MetadataConfig{
 	"cdp_remote": {
 		Fields: map[string]profiledefinition.MetadataField{
 			"device_desc": {
@@ -249,12 +253,15 @@ var TopologyMetadataConfig = profiledefinition.MetadataConfig{
 
 // updateMetadataDefinitionWithDefaults will add metadata config for resources
 // that does not have metadata definitions
-func updateMetadataDefinitionWithDefaults(metadataConfig profiledefinition.MetadataConfig, collectTopology bool) profiledefinition.MetadataConfig {
+func updateMetadataDefinitionWithDefaults(metadataConfig profiledefinition.MetadataConfig, collectTopology bool, collectCDPTopology bool) profiledefinition.MetadataConfig {
 	newConfig := make(profiledefinition.MetadataConfig)
 	mergeMetadata(newConfig, metadataConfig)
 	mergeMetadata(newConfig, LegacyMetadataConfig)
 	if collectTopology {
 		mergeMetadata(newConfig, TopologyMetadataConfig)
+		if collectCDPTopology {
+			mergeMetadata(newConfig, CDPTopologyMetadataConfig)
+		}
 	}
 	return newConfig
 }
@@ -941,6 +941,40 @@ collect_topology: true
 	assert.Equal(t, false, config.CollectTopology)
 }
 
+func Test_buildConfig_collectCDPTopology(t *testing.T) {
+	// language=yaml
+	rawInstanceConfig := []byte(`
+ip_address: 1.2.3.4
+community_string: "abc"
+`)
+	// language=yaml
+	rawInitConfig := []byte(`
+oid_batch_size: 10
+`)
+	config, err := NewCheckConfig(rawInstanceConfig, rawInitConfig, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, true, config.CollectCDPTopology)
+
+	// language=yaml
+	rawInitConfig = []byte(`
+oid_batch_size: 10
+collect_cdp_topology: false
+`)
+	config, err = NewCheckConfig(rawInstanceConfig, rawInitConfig, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, false, config.CollectCDPTopology)
+
+	// language=yaml
+	rawInstanceConfig = []byte(`
+ip_address: 1.2.3.4
+community_string: "abc"
+collect_cdp_topology: true
+`)
+	config, err = NewCheckConfig(rawInstanceConfig, rawInitConfig, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, true, config.CollectCDPTopology)
+}
+
 func Test_buildConfig_namespace(t *testing.T) {
 	mockConfig := configmock.New(t)
 
@@ -1444,6 +1478,81 @@ ip_address: 1.2.3.4
 	}
 }
 
+func Test_buildConfig_WalkFile(t *testing.T) {
+	mockConfig := configmock.New(t)
+	mockConfig.SetWithoutSource("confd_path", "/etc/datadog-agent/conf.d")
+
+	tests := []struct {
+		name                string
+		rawInstanceConfig   []byte
+		expectedWalkFile    string
+		expectedPingEnabled bool
+		expectedErr         string
+	}{
+		{
+			name: "absolute walk file",
+			// language=yaml
+			rawInstanceConfig: []byte(`
+ip_address: 1.2.3.4
+walk_file: /tmp/walks/device.snmprec
+`),
+			expectedWalkFile: "/tmp/walks/device.snmprec",
+		},
+		{
+			name: "relative walk file",
+			// language=yaml
+			rawInstanceConfig: []byte(`
+ip_address: 1.2.3.4
+walk_file: walks/device.snmprec
+`),
+			expectedWalkFile: "/etc/datadog-agent/conf.d/snmp.d/walks/device.snmprec",
+		},
+		{
+			name: "walk file disables ping",
+			// language=yaml
+			rawInstanceConfig: []byte(`
+ip_address: 1.2.3.4
+walk_file: /tmp/walks/device.snmprec
+ping:
+  enabled: true
+`),
+			expectedWalkFile:    "/tmp/walks/device.snmprec",
+			expectedPingEnabled: false,
+		},
+		{
+			name: "no walk file",
+			// language=yaml
+			rawInstanceConfig: []byte(`
+ip_address: 1.2.3.4
+ping:
+  enabled: true
+`),
+			expectedPingEnabled: true,
+		},
+		{
+			name: "walk file with network",
+			// language=yaml
+			rawInstanceConfig: []byte(`
+network_address: 10.0.0.0/30
+walk_file: /tmp/walks/device.snmprec
+`),
+			expectedErr: "`walk_file` and `network` cannot be used at the same time",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := NewCheckConfig(tt.rawInstanceConfig, []byte(``), nil)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedWalkFile, config.WalkFile)
+			assert.Equal(t, tt.expectedPingEnabled, config.PingEnabled)
+		})
+	}
+}
+
 func TestCheckConfig_DiscoveryDigest(t *testing.T) {
 	baseCaseHash := DeviceDigest("a1d0f0237ee2fe8f")
 	tests := []struct {
@@ -1669,6 +1778,7 @@ func TestCheckConfig_Copy(t *testing.T) {
 		PrivProtocol:    "des",
 		PrivKey:         "123",
 		ContextName:     "",
+		WalkFile:        "/tmp/device.snmprec",
 		RequestedMetrics: []profiledefinition.MetricsConfig{
 			{
 				Symbol: profiledefinition.SymbolConfig{
@@ -1692,6 +1802,7 @@ func TestCheckConfig_Copy(t *testing.T) {
 		InstanceTags:          []string{"InstanceTags:tag"},
 		CollectDeviceMetadata: true,
 		CollectTopology:       true,
+		CollectCDPTopology:    true,
 		UseDeviceIDAsHostname: true,
 		DeviceID:              "123",
 		DeviceIDTags:          []string{"DeviceIDTags:tag"},
@@ -1726,6 +1837,37 @@ func TestCheckConfig_CopyWithNewIP(t *testing.T) {
 	assert.NotEqual(t, config.DeviceID, configCopy.DeviceID)
 }
 
+func TestCheckConfig_CopyWithContext(t *testing.T) {
+	config := CheckConfig{
+		IPAddress:       "127.0.0.5",
+		Port:            161,
+		CommunityString: "public",
+	}
+	config.UpdateDeviceIDAndTags()
+
+	configCopy := config.CopyWithContext("vlan-10")
+
+	assert.Equal(t, "public@vlan-10", configCopy.CommunityString)
+	assert.Equal(t, "", configCopy.ContextName)
+	assert.Equal(t, config.DeviceID, configCopy.DeviceID)
+	assert.Equal(t, "public", config.CommunityString)
+
+	config = CheckConfig{
+		IPAddress:   "127.0.0.5",
+		Port:        161,
+		User:        "admin",
+		ContextName: "default",
+	}
+	config.UpdateDeviceIDAndTags()
+
+	configCopy = config.CopyWithContext("vrf-blue")
+
+	assert.Equal(t, "vrf-blue", configCopy.ContextName)
+	assert.Equal(t, "", configCopy.CommunityString)
+	assert.Equal(t, config.DeviceID, configCopy.DeviceID)
+	assert.Equal(t, "default", config.ContextName)
+}
+
 func TestCheckConfig_getResolvedSubnetName(t *testing.T) {
 	tests := []struct {
 		name               string
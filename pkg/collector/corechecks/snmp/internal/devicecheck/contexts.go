@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package devicecheck
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/utils"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/fetch"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/valuestore"
+)
+
+// contextsGroup holds the table metrics polled across the same contexts
+type contextsGroup struct {
+	contexts   profiledefinition.MetricContextsConfig
+	metrics    []profiledefinition.MetricsConfig
+	columnOIDs []string
+}
+
+type contextsKey struct {
+	oid          string
+	extractValue string
+	tag          string
+}
+
+// splitContextsMetrics returns the metrics polled in the default context, and
+// the metrics polled across contexts grouped by the contexts they are polled from.
+func splitContextsMetrics(metrics []profiledefinition.MetricsConfig) ([]profiledefinition.MetricsConfig, []contextsGroup) {
+	var defaultMetrics []profiledefinition.MetricsConfig
+	var groups []contextsGroup
+	groupIndexes := make(map[contextsKey]int)
+	for _, metric := range metrics {
+		if metric.Contexts == nil {
+			defaultMetrics = append(defaultMetrics, metric)
+			continue
+		}
+		contexts := *metric.Contexts
+		key := contextsKey{oid: contexts.Symbol.OID, extractValue: contexts.Symbol.ExtractValue, tag: contexts.Tag}
+		index, ok := groupIndexes[key]
+		if !ok {
+			index = len(groups)
+			groupIndexes[key] = index
+			groups = append(groups, contextsGroup{contexts: contexts})
+		}
+		// metrics are fetched in the context sessions as regular tables
+		metric = metric.Clone()
+		metric.Contexts = nil
+		groups[index].metrics = append(groups[index].metrics, metric)
+	}
+	for i := range groups {
+		profile := profiledefinition.ProfileDefinition{Metrics: groups[i].metrics}
+		_, groups[i].columnOIDs = profile.SplitOIDs(false)
+	}
+	return defaultMetrics, groups
+}
+
+// getContextNames returns the sorted names of the contexts listed by the
+// contexts column, filtered and extracted with its `extract_value` pattern.
+func getContextNames(contexts profiledefinition.MetricContextsConfig, values *valuestore.ResultValueStore) []string {
+	columnValues, err := values.GetColumnValues(contexts.Symbol.OID)
+	if err != nil {
+		log.Debugf("failed to get contexts from `%s`: %s", contexts.Symbol.Name, err)
+		return nil
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, value := range columnValues {
+		if contexts.Symbol.ExtractValueCompiled != nil {
+			value, err = value.ExtractStringValue(contexts.Symbol.ExtractValueCompiled)
+			if err != nil {
+				log.Tracef("skipping context from `%s`: %s", contexts.Symbol.Name, err)
+				continue
+			}
+		}
+		name, err := value.ToString()
+		if err != nil || name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// reportContextsMetrics polls the tables of each group in each of its contexts
+// and reports them tagged with the context name.
+func (d *DeviceCheck) reportContextsMetrics(groups []contextsGroup, values *valuestore.ResultValueStore, tags []string) {
+	for _, group := range groups {
+		for _, name := range getContextNames(group.contexts, values) {
+			contextValues, err := d.fetchContextValues(name, group.columnOIDs)
+			if err != nil {
+				log.Debugf("%s: failed to fetch values of context `%s`: %s", d.config.IPAddress, name, err)
+				continue
+			}
+			contextTags := append(utils.CopyStrings(tags), group.contexts.Tag+":"+name)
+			d.sender.ReportMetrics(group.metrics, contextValues, contextTags, d.config.DeviceID)
+		}
+	}
+}
+
+// fetchContextValues fetches the given columns in a new session to the given context
+func (d *DeviceCheck) fetchContextValues(contextName string, columnOIDs []string) (*valuestore.ResultValueStore, error) {
+	sess, err := d.sessionFactory(d.config.CopyWithContext(contextName))
+	if err != nil {
+		return nil, err
+	}
+	d.restoreUSMEngine(sess)
+	if err := sess.Connect(); err != nil {
+		return nil, fmt.Errorf("snmp connection error: %s", err)
+	}
+	defer func() {
+		d.saveUSMEngine(sess)
+		if err := sess.Close(); err != nil {
+			d.sessionCloseErrorCount.Inc()
+			log.Warnf("failed to close session (count: %d): %v", d.sessionCloseErrorCount.Load(), err)
+		}
+	}()
+	return fetch.Fetch(sess, nil, columnOIDs, d.config.OidBatchSize, d.config.BulkMaxRepetitions)
+}
@@ -0,0 +1,195 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2024-present Datadog, Inc.
+
+package devicecheck
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	agentconfig "github.com/DataDog/datadog-agent/comp/core/config"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/profile"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/report"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+)
+
+// engineSession is a FakeSession learning and restoring SNMPv3 engine parameters
+type engineSession struct {
+	*session.FakeSession
+	learnt   session.USMEngine
+	restored *session.USMEngine
+}
+
+func (s *engineSession) USMEngine() (session.USMEngine, bool) {
+	return s.learnt, true
+}
+
+func (s *engineSession) SetUSMEngine(engine session.USMEngine) {
+	s.restored = &engine
+}
+
+func TestRun_contexts(t *testing.T) {
+	profile.SetConfdPathAndCleanProfiles()
+	defaultSess := session.CreateFakeSession()
+	defaultSess.
+		SetInt("1.2.3.0", 10).
+		SetStr("1.3.6.1.4.1.9.9.46.1.3.1.1.4.1.1", "default").
+		SetStr("1.3.6.1.4.1.9.9.46.1.3.1.1.4.1.10", "vlan-10").
+		SetStr("1.3.6.1.4.1.9.9.46.1.3.1.1.4.1.20", "vlan-20").
+		SetStr("1.3.6.1.4.1.9.9.46.1.3.1.1.4.1.21", "vlan-20")
+	vlan10Sess := session.CreateFakeSession()
+	vlan10Sess.
+		SetInt("1.3.6.1.2.1.17.2.15.1.3.1", 5).
+		SetInt("1.3.6.1.2.1.17.2.15.1.3.2", 3)
+	vlan20Sess := session.CreateFakeSession()
+	vlan20Sess.
+		SetInt("1.3.6.1.2.1.17.2.15.1.3.1", 1)
+
+	var communities []string
+	sessions := map[string]*engineSession{}
+	sessionFactory := func(config *checkconfig.CheckConfig) (session.Session, error) {
+		communities = append(communities, config.CommunityString)
+		var sess *session.FakeSession
+		switch config.CommunityString {
+		case "public":
+			sess = defaultSess
+		case "public@vlan-10":
+			sess = vlan10Sess
+		case "public@vlan-20":
+			sess = vlan20Sess
+		default:
+			return nil, fmt.Errorf("unexpected community %s", config.CommunityString)
+		}
+		learnt := session.USMEngine{ID: "engine-" + config.CommunityString}
+		sessions[config.CommunityString] = &engineSession{FakeSession: sess, learnt: learnt}
+		return sessions[config.CommunityString], nil
+	}
+
+	// language=yaml
+	rawInstanceConfig := []byte(`
+collect_device_metadata: false
+ip_address: 1.2.3.4
+community_string: public
+metrics:
+- symbol:
+    OID: 1.2.3.0
+    name: myMetric
+- MIB: BRIDGE-MIB
+  table:
+    OID: 1.3.6.1.2.1.17.2.15
+    name: dot1dStpPortTable
+  symbols:
+  - OID: 1.3.6.1.2.1.17.2.15.1.3
+    name: dot1dStpPortState
+  metric_tags:
+  - index: 1
+    tag: port
+  contexts:
+    symbol:
+      OID: 1.3.6.1.4.1.9.9.46.1.3.1.1.4
+      name: vtpVlanName
+      extract_value: '^(vlan-\d+)$'
+    tag: vlan
+`)
+	// language=yaml
+	rawInitConfig := []byte(`
+profiles:
+ f5-big-ip:
+   definition_file: f5-big-ip.yaml
+`)
+
+	config, err := checkconfig.NewCheckConfig(rawInstanceConfig, rawInitConfig, nil)
+	require.NoError(t, err)
+
+	deviceCk, err := NewDeviceCheck(config, "1.2.3.4", sessionFactory, agentconfig.NewMock(t))
+	require.NoError(t, err)
+
+	sender := mocksender.NewMockSender("123") // required to initiate aggregator
+	sender.SetupAcceptAll()
+
+	deviceCk.SetSender(report.NewMetricSender(sender, "", nil, report.MakeInterfaceBandwidthState(), nil))
+
+	err = deviceCk.Run(time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"public", "public@vlan-10", "public@vlan-20"}, communities)
+
+	sender.AssertMetric(t, "Gauge", "snmp.myMetric", float64(10), "", nil)
+	sender.AssertMetric(t, "Gauge", "snmp.dot1dStpPortState", float64(5), "", []string{"port:1", "vlan:vlan-10"})
+	sender.AssertMetric(t, "Gauge", "snmp.dot1dStpPortState", float64(3), "", []string{"port:2", "vlan:vlan-10"})
+	sender.AssertMetric(t, "Gauge", "snmp.dot1dStpPortState", float64(1), "", []string{"port:1", "vlan:vlan-20"})
+	// contexts not matching the extract_value pattern are not polled
+	sender.AssertMetricNotTaggedWith(t, "Gauge", "snmp.dot1dStpPortState", []string{"vlan:default"})
+
+	// the engine parameters learnt by the last session are reused by the next ones
+	assert.Nil(t, sessions["public"].restored)
+	assert.Equal(t, "engine-public", sessions["public@vlan-10"].restored.ID)
+	assert.Equal(t, "engine-public@vlan-10", sessions["public@vlan-20"].restored.ID)
+
+	err = deviceCk.Run(time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "engine-public@vlan-20", sessions["public"].restored.ID)
+}
+
+func TestSplitContextsMetrics(t *testing.T) {
+	config, err := checkconfig.NewCheckConfig([]byte(`
+ip_address: 1.2.3.4
+community_string: public
+metrics:
+- symbol:
+    OID: 1.2.3.0
+    name: myMetric
+- table:
+    OID: 1.3.6.1.2.1.17.4.3
+    name: dot1dTpFdbTable
+  symbols:
+  - OID: 1.3.6.1.2.1.17.4.3.1.3
+    name: dot1dTpFdbStatus
+  metric_tags:
+  - symbol:
+      OID: 1.3.6.1.2.1.17.4.3.1.2
+      name: dot1dTpFdbPort
+    tag: port
+  contexts:
+    symbol:
+      OID: 1.3.6.1.4.1.9.9.46.1.3.1.1.4
+- table:
+    OID: 1.3.6.1.2.1.17.2.15
+    name: dot1dStpPortTable
+  symbols:
+  - OID: 1.3.6.1.2.1.17.2.15.1.3
+    name: dot1dStpPortState
+  metric_tags:
+  - index: 1
+    tag: port
+  contexts:
+    symbol:
+      OID: 1.3.6.1.4.1.9.9.46.1.3.1.1.4
+`), []byte(``), nil)
+	require.NoError(t, err)
+
+	metrics, groups := splitContextsMetrics(config.RequestedMetrics)
+
+	// sysUpTimeInstance is always added to the requested metrics
+	require.Len(t, metrics, 2)
+	assert.Equal(t, "myMetric", metrics[0].Symbol.Name)
+	assert.Equal(t, "sysUpTimeInstance", metrics[1].Symbol.Name)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "snmp_context", groups[0].contexts.Tag)
+	assert.Len(t, groups[0].metrics, 2)
+	for _, metric := range groups[0].metrics {
+		assert.Nil(t, metric.Contexts)
+	}
+	assert.Equal(t, []string{"1.3.6.1.2.1.17.2.15.1.3", "1.3.6.1.2.1.17.4.3.1.2", "1.3.6.1.2.1.17.4.3.1.3"}, groups[0].columnOIDs)
+	// the config metrics are unchanged
+	assert.NotNil(t, config.RequestedMetrics[1].Contexts)
+}
@@ -63,6 +63,9 @@ type profileCache struct {
 	err         error
 	scalarOIDs  []string
 	columnOIDs  []string
+	// metrics polled in the default context, and groups of metrics polled across contexts
+	metrics        []profiledefinition.MetricsConfig
+	contextsGroups []contextsGroup
 }
 
 // GetProfile returns the cached profile, or an empty profile if the cache is empty.
@@ -88,6 +91,7 @@ func (pc *profileCache) Update(sysObjectID string, now time.Time, config *checkc
 		pc.profile = &profile
 		pc.err = err
 		pc.scalarOIDs, pc.columnOIDs = pc.profile.SplitOIDs(config.CollectDeviceMetadata)
+		pc.metrics, pc.contextsGroups = splitContextsMetrics(pc.profile.Metrics)
 	}
 	return pc.GetProfile(), pc.err
 }
@@ -124,6 +128,7 @@ type DeviceCheck struct {
 	cacheKey                string
 	agentConfig             config.Component
 	profileCache            profileCache
+	usmEngine               *session.USMEngine
 }
 
 const cacheKeyPrefix = "snmp-tags"
@@ -217,6 +222,7 @@ func (d *DeviceCheck) Run(collectionTime time.Time) error {
 	if err != nil {
 		return err
 	}
+	d.restoreUSMEngine(d.session)
 
 	// Fetch and report metrics
 	var checkErr error
@@ -243,7 +249,8 @@ func (d *DeviceCheck) Run(collectionTime time.Time) error {
 	d.sender.Gauge(deviceReachableMetric, utils.BoolToFloat64(deviceReachable), metricTags)
 	d.sender.Gauge(deviceUnreachableMetric, utils.BoolToFloat64(!deviceReachable), metricTags)
 	if values != nil {
-		d.sender.ReportMetrics(profile.Metrics, values, metricTags, d.config.DeviceID)
+		d.sender.ReportMetrics(d.profileCache.metrics, values, metricTags, d.config.DeviceID)
+		d.reportContextsMetrics(d.profileCache.contextsGroups, values, metricTags)
 	}
 
 	// Get a system appropriate ping check
@@ -334,6 +341,7 @@ func (d *DeviceCheck) getValuesAndTags() (bool, profiledefinition.ProfileDefinit
 		return false, d.profileCache.GetProfile(), tags, nil, fmt.Errorf("snmp connection error: %s", connErr)
 	}
 	defer func() {
+		d.saveUSMEngine(d.session)
 		err := d.session.Close()
 		if err != nil {
 			d.sessionCloseErrorCount.Inc()
@@ -381,6 +389,24 @@ func (d *DeviceCheck) getValuesAndTags() (bool, profiledefinition.ProfileDefinit
 	return deviceReachable, profile, tags, valuesStore, joinedError
 }
 
+// restoreUSMEngine presets the SNMPv3 engine parameters learnt from the device
+// by a previous session, to skip the engine discovery.
+func (d *DeviceCheck) restoreUSMEngine(sess session.Session) {
+	if engineCache, ok := sess.(session.USMEngineCache); ok && d.usmEngine != nil {
+		engineCache.SetUSMEngine(*d.usmEngine)
+	}
+}
+
+// saveUSMEngine saves the SNMPv3 engine parameters learnt from the device, to
+// reuse them in the next sessions.
+func (d *DeviceCheck) saveUSMEngine(sess session.Session) {
+	if engineCache, ok := sess.(session.USMEngineCache); ok {
+		if engine, ok := engineCache.USMEngine(); ok {
+			d.usmEngine = &engine
+		}
+	}
+}
+
 func (d *DeviceCheck) getSysObjectID(sess session.Session) (string, error) {
 	if d.config.ProfileName == checkconfig.ProfileNameAuto {
 		// detect using sysObjectID
@@ -58,6 +58,10 @@ func getProfileDefinitions(profilesFolder string, isUserProfile bool) (ProfileCo
 	} else {
 		log.Debugf("Reading ootb profiles from %s", profilesRoot)
 	}
+	return readProfileDefinitions(profilesRoot, isUserProfile)
+}
+
+func readProfileDefinitions(profilesRoot string, isUserProfile bool) (ProfileConfigMap, bool, error) {
 	files, err := os.ReadDir(profilesRoot)
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to read profile dir %q: %w", profilesRoot, err)
@@ -149,3 +153,43 @@ func getYamlDefaultProfiles() ProfileConfigMap {
 	}
 	return userProfiles
 }
+
+// LoadProfileFile reads the profile at the given path and fully expands it.
+// The profiles it extends are looked up in the same folder first, then in the
+// user and default profiles of the agent. Unlike profiles loaded by the check,
+// validation errors are returned instead of logged.
+func LoadProfileFile(definitionFile string) (ProfileConfig, error) {
+	definitionFile, err := filepath.Abs(definitionFile)
+	if err != nil {
+		return ProfileConfig{}, err
+	}
+	definition, _, err := readProfileDefinition(definitionFile)
+	if err != nil {
+		return ProfileConfig{}, err
+	}
+	name := strings.TrimSuffix(filepath.Base(definitionFile), filepath.Ext(definitionFile))
+	if definition.Name == "" {
+		definition.Name = name
+	}
+
+	siblingProfiles, _, err := readProfileDefinitions(filepath.Dir(definitionFile), true)
+	if err != nil {
+		return ProfileConfig{}, err
+	}
+	userProfiles, _ := getYamlUserProfiles()
+	profiles := mergeProfiles(userProfiles, siblingProfiles)
+	defaultProfiles := getYamlDefaultProfiles()
+
+	err = recursivelyExpandBaseProfiles(name, definition, definition.Extends, []string{}, profiles, defaultProfiles)
+	if err != nil {
+		return ProfileConfig{}, fmt.Errorf("failed to expand profile %q: %w", name, err)
+	}
+	profiledefinition.NormalizeMetrics(definition.Metrics)
+	errs := profiledefinition.ValidateEnrichMetadata(definition.Metadata)
+	errs = append(errs, profiledefinition.ValidateEnrichMetrics(definition.Metrics)...)
+	errs = append(errs, profiledefinition.ValidateEnrichMetricTags(definition.MetricTags)...)
+	if len(errs) > 0 {
+		return ProfileConfig{}, fmt.Errorf("validation errors in profile %q: %s", name, strings.Join(errs, "; "))
+	}
+	return ProfileConfig{Definition: *definition, IsUserProfile: true}, nil
+}
@@ -7,6 +7,7 @@ package profile
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -234,3 +235,53 @@ func Test_loadYamlProfiles_legacyProfiles(t *testing.T) {
 	assert.True(t, haveLegacyProfile)
 	legacySymbolTypeLogs.AssertPresent(t, "found legacy symbol type in profile")
 }
+
+func TestLoadProfileFile(t *testing.T) {
+	mockConfig := configmock.New(t)
+	defaultTestConfdPath, _ := filepath.Abs(filepath.Join("..", "test", "user_profiles.d"))
+	mockConfig.SetWithoutSource("confd_path", defaultTestConfdPath)
+
+	dir := t.TempDir()
+	writeProfile := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	writeProfile("_sibling.yaml", `
+metrics:
+  - symbol:
+      OID: 1.2.3.4.0
+      name: sibling_metric
+`)
+	path := writeProfile("my-device.yaml", `
+extends:
+  - _base.yaml
+  - _sibling.yaml
+  - p3.yaml
+device:
+  vendor: acme
+metrics:
+  - symbol:
+      OID: 1.2.3.5.0
+      name: my_metric
+`)
+
+	profile, err := LoadProfileFile(path)
+	require.NoError(t, err)
+	assert.True(t, profile.IsUserProfile)
+	assert.Equal(t, "my-device", profile.Definition.Name)
+	assert.Equal(t, "acme", profile.Definition.Device.Vendor)
+	assert.NotNil(t, getMetricFromProfile(profile.Definition, "my_metric"))
+	assert.NotNil(t, getMetricFromProfile(profile.Definition, "sibling_metric")) // same folder
+	assert.NotNil(t, getMetricFromProfile(profile.Definition, "user_p3_metric")) // user profile
+	assert.Equal(t, "base_datadog", profile.Definition.MetricTags[0].Tag)        // default profile
+
+	_, err = LoadProfileFile(writeProfile("bad-extend.yaml", "extends:\n  - _missing.yaml\n"))
+	assert.ErrorContains(t, err, "extend does not exist: `_missing`")
+
+	_, err = LoadProfileFile(writeProfile("invalid.yaml", "metrics:\n  - symbol:\n      name: no_oid\n"))
+	assert.ErrorContains(t, err, "validation errors in profile \"invalid\"")
+
+	_, err = LoadProfileFile(filepath.Join(dir, "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -65,7 +65,24 @@ func (ms *MetricSender) ReportNetworkDeviceMetadata(config *checkconfig.CheckCon
 
 	interfaces := buildNetworkInterfacesMetadata(config.DeviceID, metadataStore)
 	ipAddresses := buildNetworkIPAddressesMetadata(config.DeviceID, metadataStore)
-	topologyLinks := buildNetworkTopologyMetadata(config.DeviceID, metadataStore, interfaces)
+	var topologyLinks []devicemetadata.TopologyLinkMetadata
+	if ms.devices != nil && store != nil {
+		ms.devices.Update(devicemetadata.MonitoredDevice{
+			ID:          config.DeviceID,
+			Namespace:   config.Namespace,
+			IPAddress:   config.IPAddress,
+			Name:        metadataStore.GetScalarAsString("device.name"),
+			IPAddresses: deviceIPAddresses(config.IPAddress, ipAddresses),
+			Interfaces:  interfaces,
+			LastUpdate:  collectTime,
+		})
+		topologyLinks = buildNetworkTopologyMetadata(config.DeviceID, metadataStore, interfaces,
+			newTopologyDevices(ms.devices.Devices(collectTime)))
+		// links seen by both of their ends are only reported by one of them
+		topologyLinks = ms.devices.ReportTopologyLinks(config.DeviceID, topologyLinks)
+	} else {
+		topologyLinks = buildNetworkTopologyMetadata(config.DeviceID, metadataStore, interfaces, nil)
+	}
 
 	metadataPayloads := devicemetadata.BatchPayloads(integrations.SNMP, config.Namespace, config.ResolvedSubnetName, collectTime, devicemetadata.PayloadMetadataBatchSize, devices, interfaces, ipAddresses, topologyLinks, nil, diagnoses)
 
@@ -325,20 +342,79 @@ func buildNetworkIPAddressesMetadata(deviceID string, store *metadata.Store) []d
 	return ipAddresses
 }
 
-func buildNetworkTopologyMetadata(deviceID string, store *metadata.Store, interfaces []devicemetadata.InterfaceMetadata) []devicemetadata.TopologyLinkMetadata {
+func buildNetworkTopologyMetadata(deviceID string, store *metadata.Store, interfaces []devicemetadata.InterfaceMetadata, devices topologyDevices) []devicemetadata.TopologyLinkMetadata {
 	if store == nil {
 		// it's expected that the value store is nil if we can't reach the device
 		// in that case, we just return a nil slice.
 		return nil
 	}
 
-	links := buildNetworkTopologyMetadataWithLLDP(deviceID, store, interfaces)
-	if len(links) == 0 {
-		links = buildNetworkTopologyMetadataWithCDP(deviceID, store, interfaces)
+	lldpLinks := buildNetworkTopologyMetadataWithLLDP(deviceID, store, interfaces)
+	cdpLinks := buildNetworkTopologyMetadataWithCDP(deviceID, store, interfaces)
+
+	links := append(lldpLinks, cdpLinks...)
+	for i := range links {
+		devices.resolveRemoteLinkSide(&links[i], deviceID)
+	}
+	links = append(links[:len(lldpLinks)], removeLinksSeenByLLDP(links[len(lldpLinks):], links[:len(lldpLinks)])...)
+	return devicemetadata.DeduplicateTopologyLinks(links)
+}
+
+// removeLinksSeenByLLDP removes the CDP links already reported by LLDP, i.e. the
+// links from the same local interface to the same remote device. CDP links whose
+// ID is already used by a LLDP link are removed as well, since both tables are
+// indexed by local interface and neighbor index.
+func removeLinksSeenByLLDP(cdpLinks []devicemetadata.TopologyLinkMetadata, lldpLinks []devicemetadata.TopologyLinkMetadata) []devicemetadata.TopologyLinkMetadata {
+	var links []devicemetadata.TopologyLinkMetadata
+	for _, cdpLink := range cdpLinks {
+		seen := false
+		for _, lldpLink := range lldpLinks {
+			if cdpLink.ID == lldpLink.ID || isSameNeighbor(cdpLink, lldpLink) {
+				log.Tracef("CDP link %s is already reported by LLDP link %s", cdpLink.ID, lldpLink.ID)
+				seen = true
+				break
+			}
+		}
+		if !seen {
+			links = append(links, cdpLink)
+		}
 	}
 	return links
 }
 
+// isSameNeighbor returns true if both links are from the same local interface to the same remote device.
+func isSameNeighbor(link devicemetadata.TopologyLinkMetadata, other devicemetadata.TopologyLinkMetadata) bool {
+	if link.Local.Interface.DDID == "" || link.Local.Interface.DDID != other.Local.Interface.DDID {
+		return false
+	}
+	remote, otherRemote := link.Remote.Device, other.Remote.Device
+	switch {
+	case remote.DDID != "" && otherRemote.DDID != "":
+		return remote.DDID == otherRemote.DDID
+	case remote.IPAddress != "" && remote.IPAddress == otherRemote.IPAddress:
+		return true
+	}
+	for _, name := range []string{remote.Name, remote.ID} {
+		for _, otherName := range []string{otherRemote.Name, otherRemote.ID} {
+			if name != "" && otherName != "" && shortHostname(name) == shortHostname(otherName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// deviceIPAddresses returns the IP addresses a device can be reached at.
+func deviceIPAddresses(deviceIP string, ipAddresses []devicemetadata.IPAddressMetadata) []string {
+	ips := []string{deviceIP}
+	for _, ipAddress := range ipAddresses {
+		if ipAddress.IPAddress != deviceIP {
+			ips = append(ips, ipAddress.IPAddress)
+		}
+	}
+	return ips
+}
+
 func buildNetworkTopologyMetadataWithLLDP(deviceID string, store *metadata.Store, interfaces []devicemetadata.InterfaceMetadata) []devicemetadata.TopologyLinkMetadata {
 	interfaceIndexByIDType := buildInterfaceIndexByIDType(interfaces)
 
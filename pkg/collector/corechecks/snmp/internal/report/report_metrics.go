@@ -12,6 +12,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
+	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
 	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
 	"github.com/DataDog/datadog-agent/pkg/networkdevice/utils"
 	"github.com/DataDog/datadog-agent/pkg/snmp/snmpintegration"
@@ -27,6 +28,9 @@ type MetricSender struct {
 	submittedMetrics        int
 	interfaceConfigs        []snmpintegration.InterfaceConfig
 	interfaceBandwidthState InterfaceBandwidthState
+	// devices holds the devices monitored by the agent, it is nil when the
+	// device isn't registered for topology and other components
+	devices *devicemetadata.DeviceRegistry
 }
 
 // MetricSample is a collected metric sample with its metadata, ready to be submitted through the metric sender
@@ -39,12 +43,15 @@ type MetricSample struct {
 }
 
 // NewMetricSender create a new MetricSender
-func NewMetricSender(sender sender.Sender, hostname string, interfaceConfigs []snmpintegration.InterfaceConfig, interfaceBandwidthState InterfaceBandwidthState) *MetricSender {
+// The device and its interfaces are registered in devices, if not nil, for the
+// topology links of other devices to be resolved to them.
+func NewMetricSender(sender sender.Sender, hostname string, interfaceConfigs []snmpintegration.InterfaceConfig, interfaceBandwidthState InterfaceBandwidthState, devices *devicemetadata.DeviceRegistry) *MetricSender {
 	return &MetricSender{
 		sender:                  sender,
 		hostname:                hostname,
 		interfaceConfigs:        interfaceConfigs,
 		interfaceBandwidthState: interfaceBandwidthState,
+		devices:                 devices,
 	}
 }
 
@@ -8,9 +8,11 @@ package report
 import (
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	sortutil "github.com/DataDog/datadog-agent/pkg/util/sort"
 
 	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
 	"github.com/DataDog/datadog-agent/pkg/snmp/snmpintegration"
@@ -216,3 +218,64 @@ func isInterfaceTableMetric(oid string) bool {
 func addInternalResourceTag(tags []string, resource string) []string {
 	return append(tags, "dd.internal.resource:"+resource)
 }
+
+// IndexTransformFailure is a table row for which a tag using `index_transform`
+// could not be resolved.
+type IndexTransformFailure struct {
+	// Table is the name of the table the row belongs to.
+	Table string
+	// Tag is the tag that could not be resolved.
+	Tag string
+	// Index is the index of the row.
+	Index string
+	// TransformedIndex is the result of the transform, empty if the transform
+	// rules don't fit the index.
+	TransformedIndex string
+}
+
+// FindIndexTransformFailures returns the table rows whose `index_transform`
+// tags don't match any row of the column they are read from. Tags whose column
+// returned no value at all are ignored.
+func FindIndexTransformFailures(metrics []profiledefinition.MetricsConfig, values *valuestore.ResultValueStore) []IndexTransformFailure {
+	var failures []IndexTransformFailure
+	for _, metric := range metrics {
+		if !metric.IsColumn() {
+			continue
+		}
+		var fullIndexes []string
+		for _, symbol := range metric.Symbols {
+			columnValues, err := values.GetColumnValues(symbol.OID)
+			if err != nil {
+				continue
+			}
+			for fullIndex := range columnValues {
+				fullIndexes = append(fullIndexes, fullIndex)
+			}
+		}
+		fullIndexes = sortutil.UniqInPlace(fullIndexes)
+		sort.Strings(fullIndexes)
+
+		for _, metricTag := range metric.MetricTags {
+			if len(metricTag.IndexTransform) == 0 || metricTag.Symbol.OID == "" {
+				continue
+			}
+			tagValues, err := values.GetColumnValues(metricTag.Symbol.OID)
+			if err != nil {
+				continue
+			}
+			for _, fullIndex := range fullIndexes {
+				newIndex := strings.Join(transformIndex(strings.Split(fullIndex, "."), metricTag.IndexTransform), ".")
+				if _, ok := tagValues[newIndex]; ok && newIndex != "" {
+					continue
+				}
+				failures = append(failures, IndexTransformFailure{
+					Table:            metric.Table.Name,
+					Tag:              metricTag.Tag,
+					Index:            fullIndex,
+					TransformedIndex: newIndex,
+				})
+			}
+		}
+	}
+	return failures
+}
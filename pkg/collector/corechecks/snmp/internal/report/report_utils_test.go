@@ -303,6 +303,54 @@ func Test_transformIndex(t *testing.T) {
 	}
 }
 
+func TestFindIndexTransformFailures(t *testing.T) {
+	metrics := []profiledefinition.MetricsConfig{
+		{
+			Table: profiledefinition.SymbolConfig{OID: "1.2.1", Name: "cpiPduBranchTable"},
+			Symbols: []profiledefinition.SymbolConfig{
+				{OID: "1.2.1.1.2", Name: "cpiPduBranchCurrent"},
+			},
+			MetricTags: []profiledefinition.MetricTagConfig{
+				{
+					Tag:            "pdu_name",
+					Symbol:         profiledefinition.SymbolConfigCompat{OID: "1.2.2.1.3", Name: "cpiPduName"},
+					IndexTransform: []profiledefinition.MetricIndexTransform{{Start: 1, End: 1}},
+				},
+				{
+					// the column of this tag returned nothing, which is reported as a missing OID instead
+					Tag:            "pdu_model",
+					Symbol:         profiledefinition.SymbolConfigCompat{OID: "1.2.2.1.4", Name: "cpiPduModel"},
+					IndexTransform: []profiledefinition.MetricIndexTransform{{Start: 1, End: 1}},
+				},
+				{
+					Tag:    "branch_id",
+					Symbol: profiledefinition.SymbolConfigCompat{OID: "1.2.1.1.1", Name: "cpiPduBranchId"},
+				},
+			},
+		},
+		{
+			Symbol: profiledefinition.SymbolConfig{OID: "1.2.3.0", Name: "scalar"},
+		},
+	}
+	values := &valuestore.ResultValueStore{
+		ColumnValues: valuestore.ColumnResultValuesType{
+			"1.2.1.1.2": {
+				"1.10": {Value: float64(1)},
+				"2.20": {Value: float64(2)},
+				"3":    {Value: float64(3)},
+			},
+			"1.2.2.1.3": {
+				"10": {Value: "pdu-10"},
+			},
+		},
+	}
+
+	assert.Equal(t, []IndexTransformFailure{
+		{Table: "cpiPduBranchTable", Tag: "pdu_name", Index: "2.20", TransformedIndex: "20"},
+		{Table: "cpiPduBranchTable", Tag: "pdu_name", Index: "3", TransformedIndex: ""},
+	}, FindIndexTransformFailures(metrics, values))
+}
+
 func Test_getTagsFromMetricTagConfigList(t *testing.T) {
 	type logCount struct {
 		log   string
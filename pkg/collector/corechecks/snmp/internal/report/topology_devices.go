@@ -0,0 +1,147 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package report
+
+import (
+	"net"
+	"strings"
+
+	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// topologyDevice holds what's needed to recognize a monitored device, and its
+// interfaces, as the remote end of a LLDP or CDP link.
+type topologyDevice struct {
+	deviceID               string
+	name                   string
+	ipAddresses            []string
+	interfaceIndexByIDType map[string]map[string][]int32
+}
+
+// topologyDevices are the devices the links reported by a device can be
+// resolved to, i.e. the other devices monitored by the agent.
+type topologyDevices []*topologyDevice
+
+func newTopologyDevices(devices []devicemetadata.MonitoredDevice) topologyDevices {
+	topologyDevices := make(topologyDevices, 0, len(devices))
+	for _, device := range devices {
+		topologyDevices = append(topologyDevices, &topologyDevice{
+			deviceID:               device.ID,
+			name:                   device.Name,
+			ipAddresses:            device.IPAddresses,
+			interfaceIndexByIDType: buildInterfaceIndexByIDType(device.Interfaces),
+		})
+	}
+	return topologyDevices
+}
+
+// findDevice returns the device matching the remote end of a link, other than
+// the device reporting the link. The remote device is looked up by IP address,
+// then by MAC address when the chassis ID is one, then by name. Nil is returned
+// when no device, or more than one, matches.
+func (d topologyDevices) findDevice(reportingDeviceID string, ipAddress string, chassisIDType string, chassisID string, names []string) *topologyDevice {
+	var candidates []*topologyDevice
+	for _, device := range d {
+		if device.deviceID != reportingDeviceID {
+			candidates = append(candidates, device)
+		}
+	}
+
+	if ipAddress != "" {
+		if device, found := findUniqueDevice(candidates, func(device *topologyDevice) bool {
+			for _, ip := range device.ipAddresses {
+				if ip == ipAddress {
+					return true
+				}
+			}
+			return false
+		}); found {
+			return device
+		}
+	}
+	if chassisIDType == devicemetadata.IDTypeMacAddress && chassisID != "" {
+		if device, found := findUniqueDevice(candidates, func(device *topologyDevice) bool {
+			return len(device.interfaceIndexByIDType[devicemetadata.IDTypeMacAddress][chassisID]) > 0
+		}); found {
+			return device
+		}
+	}
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if name == "" {
+			continue
+		}
+		if device, found := findUniqueDevice(candidates, func(device *topologyDevice) bool {
+			return strings.ToLower(device.name) == name
+		}); found {
+			return device
+		}
+		if device, found := findUniqueDevice(candidates, func(device *topologyDevice) bool {
+			return device.name != "" && shortHostname(device.name) == shortHostname(name)
+		}); found {
+			return device
+		}
+	}
+	return nil
+}
+
+// findUniqueDevice returns the device matching the predicate. found is true if
+// at least one device matched, in which case device is nil if the match is ambiguous.
+func findUniqueDevice(devices []*topologyDevice, matches func(*topologyDevice) bool) (device *topologyDevice, found bool) {
+	var matched []*topologyDevice
+	for _, candidate := range devices {
+		if matches(candidate) {
+			matched = append(matched, candidate)
+		}
+	}
+	if len(matched) > 1 {
+		log.Tracef("[remote device resolution] expected 1 matching device but found %d", len(matched))
+		return nil, true
+	}
+	if len(matched) == 1 {
+		return matched[0], true
+	}
+	return nil, false
+}
+
+// shortHostname returns the lower-cased name without its domain, e.g. `switch1` for `Switch1.example.com`.
+func shortHostname(name string) string {
+	name = strings.ToLower(name)
+	if net.ParseIP(name) != nil {
+		return name
+	}
+	shortName, _, _ := strings.Cut(name, ".")
+	return shortName
+}
+
+// resolveRemoteInterface returns the ID of the interface of the device matching
+// the remote interface of a link, or an empty string if there is none.
+func resolveRemoteInterface(device *topologyDevice, interfaceIDType string, interfaceID string) string {
+	if _, ok := device.interfaceIndexByIDType[interfaceIDType]; !ok {
+		// Port ID subtypes that can't be matched directly, e.g. `local`, go through the "smart resolution".
+		interfaceIDType = ""
+	}
+	return resolveLocalInterface(device.deviceID, device.interfaceIndexByIDType, interfaceIDType, interfaceID)
+}
+
+// resolveRemoteLinkSide fills the Datadog IDs of the remote device and interface
+// of the link when they are monitored. Once both ends of the link are resolved,
+// the link ID no longer depends on the device reporting it, so that a link
+// reported by both of its ends gets the same ID.
+func (d topologyDevices) resolveRemoteLinkSide(link *devicemetadata.TopologyLinkMetadata, reportingDeviceID string) {
+	remoteDevice := link.Remote.Device
+	device := d.findDevice(reportingDeviceID, remoteDevice.IPAddress, remoteDevice.IDType, remoteDevice.ID,
+		[]string{remoteDevice.Name, remoteDevice.ID})
+	if device == nil {
+		return
+	}
+	remoteDevice.DDID = device.deviceID
+	link.Remote.Interface.DDID = resolveRemoteInterface(device, link.Remote.Interface.IDType, link.Remote.Interface.ID)
+	if link.Local.Interface.DDID != "" && link.Remote.Interface.DDID != "" {
+		link.ID = devicemetadata.TopologyLinkID(link.Local.Interface.DDID, link.Remote.Interface.DDID)
+	}
+}
@@ -0,0 +1,202 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/metadata"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/valuestore"
+)
+
+func Test_topologyDevices_findDevice(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	registry := devicemetadata.NewDeviceRegistry(devicemetadata.DeviceRegistryTTL)
+	registry.Update(devicemetadata.MonitoredDevice{ID: "default:10.0.0.1", Name: "Switch-A", IPAddresses: []string{"10.0.0.1"}, Interfaces: []devicemetadata.InterfaceMetadata{
+		{Index: 1, Name: "Gi0/1", MacAddress: "00:00:00:00:0a:01"},
+	}, LastUpdate: now})
+	registry.Update(devicemetadata.MonitoredDevice{ID: "default:10.0.0.2", Name: "switch-b.example.com", IPAddresses: []string{"10.0.0.2", "192.168.0.2"}, Interfaces: []devicemetadata.InterfaceMetadata{
+		{Index: 7, Name: "Gi0/7", MacAddress: "00:00:00:00:0b:07"},
+	}, LastUpdate: now})
+	registry.Update(devicemetadata.MonitoredDevice{ID: "other:10.0.0.2", Name: "switch-b", IPAddresses: []string{"10.0.0.2"}, LastUpdate: now})
+	registry.Update(devicemetadata.MonitoredDevice{ID: "default:10.0.0.3", Name: "stale", IPAddresses: []string{"10.0.0.3"}, LastUpdate: now.Add(-2 * time.Hour)})
+	devices := newTopologyDevices(registry.Devices(now))
+
+	tests := []struct {
+		name             string
+		reportingID      string
+		ipAddress        string
+		chassisIDType    string
+		chassisID        string
+		names            []string
+		expectedDeviceID string
+	}{
+		{
+			name:             "by ip address",
+			reportingID:      "default:10.0.0.9",
+			ipAddress:        "10.0.0.1",
+			expectedDeviceID: "default:10.0.0.1",
+		},
+		{
+			name:             "by interface ip address",
+			reportingID:      "default:10.0.0.9",
+			ipAddress:        "192.168.0.2",
+			expectedDeviceID: "default:10.0.0.2",
+		},
+		{
+			name:        "ambiguous ip address",
+			reportingID: "default:10.0.0.9",
+			ipAddress:   "10.0.0.2",
+		},
+		{
+			name:             "by mac address",
+			reportingID:      "default:10.0.0.9",
+			chassisIDType:    "mac_address",
+			chassisID:        "00:00:00:00:0a:01",
+			expectedDeviceID: "default:10.0.0.1",
+		},
+		{
+			name:             "by name",
+			reportingID:      "default:10.0.0.9",
+			names:            []string{"switch-a"},
+			expectedDeviceID: "default:10.0.0.1",
+		},
+		{
+			name:             "by name with domain",
+			reportingID:      "default:10.0.0.9",
+			names:            []string{"", "Switch-A.example.com"},
+			expectedDeviceID: "default:10.0.0.1",
+		},
+		{
+			name:             "by exact name first",
+			reportingID:      "default:10.0.0.9",
+			names:            []string{"switch-b.example.com"},
+			expectedDeviceID: "default:10.0.0.2",
+		},
+		{
+			name:        "reporting device is excluded",
+			reportingID: "default:10.0.0.1",
+			ipAddress:   "10.0.0.1",
+		},
+		{
+			name:        "stale device is ignored",
+			reportingID: "default:10.0.0.9",
+			ipAddress:   "10.0.0.3",
+		},
+		{
+			name:        "unknown device",
+			reportingID: "default:10.0.0.9",
+			ipAddress:   "10.0.0.42",
+			names:       []string{"unknown"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			device := devices.findDevice(tt.reportingID, tt.ipAddress, tt.chassisIDType, tt.chassisID, tt.names)
+			if tt.expectedDeviceID == "" {
+				assert.Nil(t, device)
+				return
+			}
+			require.NotNil(t, device)
+			assert.Equal(t, tt.expectedDeviceID, device.deviceID)
+		})
+	}
+}
+
+func Test_buildNetworkTopologyMetadata_resolvedLinks(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	registry := devicemetadata.NewDeviceRegistry(devicemetadata.DeviceRegistryTTL)
+
+	interfacesA := []devicemetadata.InterfaceMetadata{
+		{DeviceID: "default:10.0.0.1", Index: 1, Name: "Gi0/1", MacAddress: "00:00:00:00:0a:01"},
+		{DeviceID: "default:10.0.0.1", Index: 2, Name: "Gi0/2", MacAddress: "00:00:00:00:0a:02"},
+	}
+	interfacesB := []devicemetadata.InterfaceMetadata{
+		{DeviceID: "default:10.0.0.2", Index: 7, Name: "Gi0/7", MacAddress: "00:00:00:00:0b:07"},
+	}
+	registry.Update(devicemetadata.MonitoredDevice{ID: "default:10.0.0.1", Name: "switch-a", IPAddresses: []string{"10.0.0.1"}, Interfaces: interfacesA, LastUpdate: now})
+	registry.Update(devicemetadata.MonitoredDevice{ID: "default:10.0.0.2", Name: "switch-b", IPAddresses: []string{"10.0.0.2"}, Interfaces: interfacesB, LastUpdate: now})
+	devices := newTopologyDevices(registry.Devices(now))
+
+	// switch-a sees switch-b with both LLDP and CDP on Gi0/1, and an unknown device with CDP on Gi0/2
+	storeA := metadata.NewMetadataStore()
+	storeA.AddColumnValue("lldp_remote.chassis_id_type", "0.1.1", valuestore.ResultValue{Value: float64(4)})
+	storeA.AddColumnValue("lldp_remote.chassis_id", "0.1.1", valuestore.ResultValue{Value: []byte{0, 0, 0, 0, 0x0b, 0}})
+	storeA.AddColumnValue("lldp_remote.interface_id_type", "0.1.1", valuestore.ResultValue{Value: float64(5)})
+	storeA.AddColumnValue("lldp_remote.interface_id", "0.1.1", valuestore.ResultValue{Value: "Gi0/7"})
+	storeA.AddColumnValue("lldp_remote.device_name", "0.1.1", valuestore.ResultValue{Value: "switch-b"})
+	storeA.AddColumnValue("lldp_local.interface_id_type", "1", valuestore.ResultValue{Value: float64(5)})
+	storeA.AddColumnValue("lldp_local.interface_id", "1", valuestore.ResultValue{Value: "Gi0/1"})
+	storeA.AddColumnValue("cdp_remote.interface_id", "1.3", valuestore.ResultValue{Value: "Gi0/7"})
+	storeA.AddColumnValue("cdp_remote.device_id", "1.3", valuestore.ResultValue{Value: "switch-b.example.com"})
+	storeA.AddColumnValue("cdp_remote.interface_id", "2.4", valuestore.ResultValue{Value: "Fa0/1"})
+	storeA.AddColumnValue("cdp_remote.device_id", "2.4", valuestore.ResultValue{Value: "phone.example.com"})
+
+	linksA := buildNetworkTopologyMetadata("default:10.0.0.1", storeA, interfacesA, devices)
+	require.Len(t, linksA, 2)
+
+	// the ID of links resolved on both ends doesn't depend on the reporting device
+	assert.Equal(t, "default:10.0.0.1:1|default:10.0.0.2:7", linksA[0].ID)
+	assert.Equal(t, topologyLinkSourceTypeLLDP, linksA[0].SourceType)
+	assert.Equal(t, "default:10.0.0.1:1", linksA[0].Local.Interface.DDID)
+	assert.Equal(t, "default:10.0.0.2", linksA[0].Remote.Device.DDID)
+	assert.Equal(t, "default:10.0.0.2:7", linksA[0].Remote.Interface.DDID)
+
+	assert.Equal(t, "default:10.0.0.1:2.4", linksA[1].ID)
+	assert.Equal(t, topologyLinkSourceTypeCDP, linksA[1].SourceType)
+	assert.Equal(t, "", linksA[1].Remote.Device.DDID)
+	assert.Equal(t, "", linksA[1].Remote.Interface.DDID)
+
+	// switch-b reports the same link with CDP, resolved to the interface of switch-a
+	storeB := metadata.NewMetadataStore()
+	storeB.AddColumnValue("cdp_remote.interface_id", "7.1", valuestore.ResultValue{Value: "Gi0/1"})
+	storeB.AddColumnValue("cdp_remote.device_id", "7.1", valuestore.ResultValue{Value: "switch-a"})
+
+	linksB := buildNetworkTopologyMetadata("default:10.0.0.2", storeB, interfacesB, devices)
+	require.Len(t, linksB, 1)
+	assert.Equal(t, "default:10.0.0.1:1|default:10.0.0.2:7", linksB[0].ID)
+	assert.Equal(t, "default:10.0.0.1", linksB[0].Remote.Device.DDID)
+	assert.Equal(t, "default:10.0.0.1:1", linksB[0].Remote.Interface.DDID)
+
+	// the link seen by both devices is only reported by one of them
+	assert.Equal(t, linksA, registry.ReportTopologyLinks("default:10.0.0.1", linksA))
+	assert.Empty(t, registry.ReportTopologyLinks("default:10.0.0.2", linksB))
+}
+
+func Test_removeLinksSeenByLLDP(t *testing.T) {
+	lldpLink := devicemetadata.TopologyLinkMetadata{
+		ID: "default:10.0.0.1:1.1",
+		Local: &devicemetadata.TopologyLinkSide{
+			Interface: &devicemetadata.TopologyLinkInterface{DDID: "default:10.0.0.1:1"},
+		},
+		Remote: &devicemetadata.TopologyLinkSide{
+			Device: &devicemetadata.TopologyLinkDevice{Name: "switch-b"},
+		},
+	}
+	newCDPLink := func(id string, localInterfaceDDID string, remoteName string) devicemetadata.TopologyLinkMetadata {
+		return devicemetadata.TopologyLinkMetadata{
+			ID: id,
+			Local: &devicemetadata.TopologyLinkSide{
+				Interface: &devicemetadata.TopologyLinkInterface{DDID: localInterfaceDDID},
+			},
+			Remote: &devicemetadata.TopologyLinkSide{
+				Device: &devicemetadata.TopologyLinkDevice{ID: remoteName},
+			},
+		}
+	}
+	sameNeighbor := newCDPLink("default:10.0.0.1:1.3", "default:10.0.0.1:1", "switch-b.example.com")
+	sameID := newCDPLink("default:10.0.0.1:1.1", "default:10.0.0.1:1", "phone")
+	otherNeighbor := newCDPLink("default:10.0.0.1:1.2", "default:10.0.0.1:1", "phone")
+
+	links := removeLinksSeenByLLDP([]devicemetadata.TopologyLinkMetadata{sameNeighbor, sameID, otherNeighbor}, []devicemetadata.TopologyLinkMetadata{lldpLink})
+	assert.Equal(t, []devicemetadata.TopologyLinkMetadata{otherNeighbor}, links)
+}
@@ -0,0 +1,452 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package session
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp/gosnmplib"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// recordedPDU is a PDU of a recorded walk along with its parsed OID.
+type recordedPDU struct {
+	oid []int
+	pdu gosnmp.SnmpPDU
+}
+
+// RecordedSession implements Session by serving the PDUs of a recorded walk
+// instead of querying a device.
+//
+// Supported formats are:
+//   - snmprec files, as used by snmpsim: `1.3.6.1.2.1.1.1.0|4|Linux`
+//   - snmpwalk output with numeric OIDs (`snmpwalk -On`), and the output of
+//     `agent snmp walk`: `.1.3.6.1.2.1.1.1.0 = STRING: Linux`
+type RecordedSession struct {
+	// pdus is sorted by OID.
+	pdus  []recordedPDU
+	index map[string]int
+}
+
+// NewRecordedSession loads the recorded walk at the given path.
+func NewRecordedSession(path string) (*RecordedSession, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open recorded walk: %w", err)
+	}
+	defer file.Close()
+
+	var pdus []gosnmp.SnmpPDU
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read recorded walk %q: %w", path, err)
+	}
+	if isSnmprec(lines) {
+		pdus, err = parseSnmprec(lines)
+	} else {
+		pdus, err = parseSnmpwalk(lines)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse recorded walk %q: %w", path, err)
+	}
+	return NewRecordedSessionFromPDUs(pdus)
+}
+
+// cachedRecordedSession is a loaded recorded walk, along with the state of
+// its file when it was loaded.
+type cachedRecordedSession struct {
+	modTime time.Time
+	size    int64
+	session *RecordedSession
+}
+
+// recordedSessions caches the recorded walks loaded by NewSession, since
+// sessions are created on every check run.
+var recordedSessions = struct {
+	sync.Mutex
+	byPath map[string]cachedRecordedSession
+}{byPath: make(map[string]cachedRecordedSession)}
+
+// loadRecordedSession returns the recorded walk at the given path, loading it
+// again only when the file changed since the last call.
+func loadRecordedSession(path string) (*RecordedSession, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open recorded walk: %w", err)
+	}
+
+	recordedSessions.Lock()
+	defer recordedSessions.Unlock()
+	cached, ok := recordedSessions.byPath[path]
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.session, nil
+	}
+	s, err := NewRecordedSession(path)
+	if err != nil {
+		return nil, err
+	}
+	recordedSessions.byPath[path] = cachedRecordedSession{
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		session: s,
+	}
+	return s, nil
+}
+
+// NewRecordedSessionFromPDUs creates a RecordedSession serving the given PDUs.
+// When several PDUs have the same OID, the last one wins.
+func NewRecordedSessionFromPDUs(pdus []gosnmp.SnmpPDU) (*RecordedSession, error) {
+	s := &RecordedSession{index: make(map[string]int, len(pdus))}
+	byOID := make(map[string]recordedPDU, len(pdus))
+	for _, pdu := range pdus {
+		oid, err := gosnmplib.OIDToInts(pdu.Name)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimLeft(pdu.Name, ".")
+		pdu.Name = "." + name
+		byOID[name] = recordedPDU{oid: oid, pdu: pdu}
+	}
+	for _, p := range byOID {
+		s.pdus = append(s.pdus, p)
+	}
+	sort.Slice(s.pdus, func(i, j int) bool {
+		return gosnmplib.CmpOIDs(s.pdus[i].oid, s.pdus[j].oid).IsBefore()
+	})
+	for i, p := range s.pdus {
+		s.index[strings.TrimLeft(p.pdu.Name, ".")] = i
+	}
+	return s, nil
+}
+
+// Len returns the number of recorded PDUs.
+func (s *RecordedSession) Len() int {
+	return len(s.pdus)
+}
+
+// Connect is a no-op.
+func (s *RecordedSession) Connect() error {
+	return nil
+}
+
+// Close is a no-op.
+func (s *RecordedSession) Close() error {
+	return nil
+}
+
+// GetVersion always returns 2c, since recorded walks don't support SNMPv3 contexts.
+func (s *RecordedSession) GetVersion() gosnmp.SnmpVersion {
+	return gosnmp.Version2c
+}
+
+// Get returns the recorded values of the given OIDs. OIDs that weren't
+// recorded return NoSuchObject PDUs.
+func (s *RecordedSession) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	vars := make([]gosnmp.SnmpPDU, len(oids))
+	for i, oid := range oids {
+		if idx, ok := s.index[strings.TrimLeft(oid, ".")]; ok {
+			vars[i] = s.pdus[idx].pdu
+		} else {
+			vars[i] = gosnmp.SnmpPDU{Name: oid, Type: gosnmp.NoSuchObject}
+		}
+	}
+	return &gosnmp.SnmpPacket{Variables: vars}, nil
+}
+
+// GetBulk returns the `count` recorded PDUs following each of the given OIDs,
+// in the same order as a device would. Running off the end of the walk returns
+// EndOfMibView PDUs.
+func (s *RecordedSession) GetBulk(oids []string, count uint32) (*gosnmp.SnmpPacket, error) {
+	return s.getNexts(oids, int(count))
+}
+
+// GetNext returns the recorded PDU following each of the given OIDs.
+func (s *RecordedSession) GetNext(oids []string) (*gosnmp.SnmpPacket, error) {
+	return s.getNexts(oids, 1)
+}
+
+func (s *RecordedSession) getNexts(oids []string, count int) (*gosnmp.SnmpPacket, error) {
+	vars := make([]gosnmp.SnmpPDU, len(oids)*count)
+	for i, oid := range oids {
+		nums, err := gosnmplib.OIDToInts(oid)
+		if err != nil {
+			return nil, err
+		}
+		next := sort.Search(len(s.pdus), func(j int) bool {
+			return gosnmplib.CmpOIDs(s.pdus[j].oid, nums).IsAfter()
+		})
+		for offset := 0; offset < count; offset++ {
+			if next+offset < len(s.pdus) {
+				vars[offset*len(oids)+i] = s.pdus[next+offset].pdu
+			} else {
+				vars[offset*len(oids)+i] = gosnmp.SnmpPDU{Name: oid, Type: gosnmp.EndOfMibView}
+			}
+		}
+	}
+	return &gosnmp.SnmpPacket{Variables: vars}, nil
+}
+
+// isSnmprec detects snmprec files by their first data line.
+func isSnmprec(lines []string) bool {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return !strings.Contains(line, " = ") && strings.Count(line, "|") >= 2
+	}
+	return false
+}
+
+// snmprecTypes maps snmprec type tags to their BER types.
+// See https://docs.lextudio.com/snmpsim/documentation/managed-objects.html
+var snmprecTypes = map[int]gosnmp.Asn1BER{
+	2:  gosnmp.Integer,
+	4:  gosnmp.OctetString,
+	5:  gosnmp.Null,
+	6:  gosnmp.ObjectIdentifier,
+	64: gosnmp.IPAddress,
+	65: gosnmp.Counter32,
+	66: gosnmp.Gauge32,
+	67: gosnmp.TimeTicks,
+	68: gosnmp.Opaque,
+	70: gosnmp.Counter64,
+}
+
+func parseSnmprec(lines []string) ([]gosnmp.SnmpPDU, error) {
+	var pdus []gosnmp.SnmpPDU
+	for lineNum, line := range lines {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("line %d: expected `oid|type|value`", lineNum+1)
+		}
+		oid, tag, rawValue := parts[0], parts[1], parts[2]
+		isHex := strings.HasSuffix(tag, "x")
+		tag = strings.TrimSuffix(tag, "x")
+		tagNum, err := strconv.Atoi(tag)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: unsupported type %q", lineNum+1, parts[1])
+		}
+		typ, ok := snmprecTypes[tagNum]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unsupported type %q", lineNum+1, parts[1])
+		}
+		var raw []byte
+		if isHex {
+			raw, err = hex.DecodeString(rawValue)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid hex value: %w", lineNum+1, err)
+			}
+		}
+		value, err := snmprecValue(typ, rawValue, raw, isHex)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum+1, err)
+		}
+		pdus = append(pdus, gosnmp.SnmpPDU{Name: oid, Type: typ, Value: value})
+	}
+	return pdus, nil
+}
+
+func snmprecValue(typ gosnmp.Asn1BER, value string, raw []byte, isHex bool) (any, error) {
+	switch typ {
+	case gosnmp.OctetString, gosnmp.Opaque:
+		if isHex {
+			return raw, nil
+		}
+		return []byte(value), nil
+	case gosnmp.IPAddress:
+		if isHex {
+			if len(raw) != 4 {
+				return nil, fmt.Errorf("invalid IpAddress %q", value)
+			}
+			return fmt.Sprintf("%d.%d.%d.%d", raw[0], raw[1], raw[2], raw[3]), nil
+		}
+		return value, nil
+	case gosnmp.Null:
+		return nil, nil
+	case gosnmp.ObjectIdentifier:
+		return "." + strings.TrimLeft(value, "."), nil
+	default:
+		return parseRecordedNumber(typ, value)
+	}
+}
+
+// parseRecordedNumber converts a numeric value to the Go type gosnmp uses for typ.
+func parseRecordedNumber(typ gosnmp.Asn1BER, value string) (any, error) {
+	switch typ {
+	case gosnmp.Integer:
+		return strconv.Atoi(value)
+	case gosnmp.Counter32, gosnmp.Gauge32:
+		n, err := strconv.ParseUint(value, 10, 32)
+		return uint(n), err
+	case gosnmp.TimeTicks:
+		n, err := strconv.ParseUint(value, 10, 32)
+		return uint32(n), err
+	case gosnmp.Counter64:
+		return strconv.ParseUint(value, 10, 64)
+	}
+	return nil, fmt.Errorf("unsupported numeric type %s", typ)
+}
+
+// snmpwalkLine matches the first line of a value in snmpwalk output, e.g. `.1.3.6.1.2.1.1.1.0 = STRING: "Linux"`.
+// The type is missing for empty strings (`= ""`) and for the timeticks printed by `agent snmp walk`.
+var snmpwalkLine = regexp.MustCompile(`^(?:iso)?(\.?[0-9]+(?:\.[0-9]+)*) = (?:([A-Za-z0-9 -]+): ?)?(.*)$`)
+
+// snmpwalkEnumValue matches enumerated integers, e.g. `up(1)`.
+var snmpwalkEnumValue = regexp.MustCompile(`^[^(]*\((-?[0-9]+)\)$`)
+
+// snmpwalkTimeticksValue matches timeticks printed by net-snmp, e.g. `(4202) 0:00:42.02`.
+var snmpwalkTimeticksValue = regexp.MustCompile(`^\(([0-9]+)\)`)
+
+// snmpwalkContinuation is the kind of value continued by the lines of snmpwalk
+// output which don't start a new value.
+type snmpwalkContinuation int
+
+const (
+	// snmpwalkNoContinuation means the previous value can't span several lines.
+	snmpwalkNoContinuation snmpwalkContinuation = iota
+	// snmpwalkStringContinuation continues a string with a new line.
+	snmpwalkStringContinuation
+	// snmpwalkHexContinuation continues a Hex-STRING with more bytes.
+	snmpwalkHexContinuation
+	// snmpwalkSkippedContinuation continues a value which was skipped.
+	snmpwalkSkippedContinuation
+)
+
+func parseSnmpwalk(lines []string) ([]gosnmp.SnmpPDU, error) {
+	var pdus []gosnmp.SnmpPDU
+	continuation := snmpwalkNoContinuation
+	for lineNum, line := range lines {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		match := snmpwalkLine.FindStringSubmatch(line)
+		if match == nil {
+			// values spanning several lines are continued on the following lines
+			switch continuation {
+			case snmpwalkStringContinuation:
+				last := &pdus[len(pdus)-1]
+				last.Value = append(last.Value.([]byte), []byte("\n"+strings.TrimSuffix(line, `"`))...)
+			case snmpwalkHexContinuation:
+				raw, err := decodeSnmpwalkHex(line)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: invalid Hex-STRING value %q: %w", lineNum+1, line, err)
+				}
+				last := &pdus[len(pdus)-1]
+				last.Value = append(last.Value.([]byte), raw...)
+			case snmpwalkSkippedContinuation:
+			default:
+				return nil, fmt.Errorf("line %d: expected `<numeric oid> = <type>: <value>`", lineNum+1)
+			}
+			continue
+		}
+		oid, typeName, value := match[1], match[2], match[3]
+		if strings.HasPrefix(line, "iso") {
+			oid = "1" + oid
+		}
+		continuation = snmpwalkNoContinuation
+		pdu, ok, err := snmpwalkPDU(oid, typeName, value)
+		if errors.Is(err, errUnsupportedSnmpwalkType) {
+			log.Warnf("Skipping OID %s of the recorded walk at line %d: %s", oid, lineNum+1, err)
+			continuation = snmpwalkSkippedContinuation
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum+1, err)
+		}
+		if !ok {
+			continue
+		}
+		pdus = append(pdus, pdu)
+		switch {
+		case typeName == "Hex-STRING":
+			continuation = snmpwalkHexContinuation
+		case pdu.Type == gosnmp.OctetString:
+			continuation = snmpwalkStringContinuation
+		}
+	}
+	return pdus, nil
+}
+
+// decodeSnmpwalkHex decodes the space separated bytes of a Hex-STRING value.
+func decodeSnmpwalkHex(value string) ([]byte, error) {
+	return hex.DecodeString(strings.ReplaceAll(strings.TrimSpace(value), " ", ""))
+}
+
+// errUnsupportedSnmpwalkType is returned by snmpwalkPDU for the types which
+// can't be served, such as BITS or Opaque.
+var errUnsupportedSnmpwalkType = errors.New("unsupported type")
+
+// snmpwalkPDU builds a PDU from an snmpwalk value. It returns false for
+// the values that denote missing objects.
+func snmpwalkPDU(oid string, typeName string, value string) (gosnmp.SnmpPDU, bool, error) {
+	pdu := gosnmp.SnmpPDU{Name: oid}
+	if typeName == "" && (strings.HasPrefix(value, "No Such ") || strings.HasPrefix(value, "No more variables")) {
+		return pdu, false, nil
+	}
+	var err error
+	switch typeName {
+	case "STRING", "":
+		if typeName == "" && value != `""` {
+			// `agent snmp walk` prints timeticks without a type
+			pdu.Type = gosnmp.TimeTicks
+			pdu.Value, err = parseRecordedNumber(gosnmp.TimeTicks, value)
+			break
+		}
+		pdu.Type = gosnmp.OctetString
+		pdu.Value = []byte(strings.TrimSuffix(strings.TrimPrefix(value, `"`), `"`))
+	case "Hex-STRING":
+		pdu.Type = gosnmp.OctetString
+		pdu.Value, err = decodeSnmpwalkHex(value)
+	case "OID":
+		pdu.Type = gosnmp.ObjectIdentifier
+		pdu.Value = "." + strings.TrimLeft(strings.Replace(value, "iso", "1", 1), ".")
+	case "INTEGER":
+		if m := snmpwalkEnumValue.FindStringSubmatch(value); m != nil {
+			value = m[1]
+		}
+		pdu.Type = gosnmp.Integer
+		pdu.Value, err = parseRecordedNumber(gosnmp.Integer, value)
+	case "Counter32", "Gauge32", "Counter64":
+		pdu.Type = map[string]gosnmp.Asn1BER{"Counter32": gosnmp.Counter32, "Gauge32": gosnmp.Gauge32, "Counter64": gosnmp.Counter64}[typeName]
+		pdu.Value, err = parseRecordedNumber(pdu.Type, strings.TrimSpace(value))
+	case "Timeticks":
+		if m := snmpwalkTimeticksValue.FindStringSubmatch(value); m != nil {
+			value = m[1]
+		}
+		pdu.Type = gosnmp.TimeTicks
+		pdu.Value, err = parseRecordedNumber(gosnmp.TimeTicks, value)
+	case "IpAddress":
+		pdu.Type = gosnmp.IPAddress
+		pdu.Value = value
+	default:
+		return pdu, false, fmt.Errorf("%w %q", errUnsupportedSnmpwalkType, typeName)
+	}
+	if err != nil {
+		return pdu, false, fmt.Errorf("invalid %s value %q: %w", typeName, value, err)
+	}
+	return pdu, true, nil
+}
@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+)
+
+func writeRecordedWalk(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestNewRecordedSessionSnmprec(t *testing.T) {
+	path := writeRecordedWalk(t, "device.snmprec", `# recorded with snmpsim
+1.3.6.1.2.1.1.5.0|4|switch-1
+1.3.6.1.2.1.1.2.0|6|1.3.6.1.4.1.9.1.1
+1.3.6.1.2.1.1.3.0|67|4202
+1.3.6.1.2.1.2.2.1.6.1|4x|00e0b0c0d0e0
+1.3.6.1.2.1.2.2.1.8.1|2|1
+1.3.6.1.2.1.2.2.1.10.1|65|100
+1.3.6.1.2.1.31.1.1.1.6.1|70|18446744073709551615
+1.3.6.1.2.1.4.20.1.1.10.0.0.1|64x|0a000001
+1.3.6.1.2.1.4.20.1.2.10.0.0.1|2|1
+`)
+	sess, err := NewRecordedSession(path)
+	require.NoError(t, err)
+	assert.Equal(t, 9, sess.Len())
+
+	packet, err := sess.Get([]string{"1.3.6.1.2.1.1.5.0", ".1.3.6.1.2.1.1.2.0", "1.3.6.1.2.1.1.3.0", "1.3.6.1.2.1.1.6.0"})
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.5.0", Type: gosnmp.OctetString, Value: []byte("switch-1")},
+		{Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.9.1.1"},
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(4202)},
+		{Name: "1.3.6.1.2.1.1.6.0", Type: gosnmp.NoSuchObject},
+	}, packet.Variables)
+
+	packet, err = sess.GetNext([]string{"1.3.6.1.2.1.2.2.1.6", "1.3.6.1.2.1.4.20.1.1"})
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.2.2.1.6.1", Type: gosnmp.OctetString, Value: []byte{0x00, 0xe0, 0xb0, 0xc0, 0xd0, 0xe0}},
+		{Name: ".1.3.6.1.2.1.4.20.1.1.10.0.0.1", Type: gosnmp.IPAddress, Value: "10.0.0.1"},
+	}, packet.Variables)
+
+	// rows are interleaved like a device would, and running off the end of the walk returns EndOfMibView
+	packet, err = sess.GetBulk([]string{"1.3.6.1.2.1.4.20.1.2", "1.3.6.1.2.1.31"}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.4.20.1.2.10.0.0.1", Type: gosnmp.Integer, Value: 1},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(18446744073709551615)},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(18446744073709551615)},
+		{Name: "1.3.6.1.2.1.31", Type: gosnmp.EndOfMibView},
+	}, packet.Variables)
+}
+
+func TestNewRecordedSessionSnmpwalk(t *testing.T) {
+	path := writeRecordedWalk(t, "device.walk", `.1.3.6.1.2.1.1.1.0 = STRING: "Cisco IOS Software,
+Version 15.2"
+.1.3.6.1.2.1.1.2.0 = OID: .1.3.6.1.4.1.9.1.1
+.1.3.6.1.2.1.1.3.0 = Timeticks: (4202) 0:00:42.02
+.1.3.6.1.2.1.1.4.0 = ""
+.1.3.6.1.2.1.1.7.0 = 72
+iso.3.6.1.2.1.2.2.1.6.1 = Hex-STRING: 00 E0 B0 C0 D0 E0
+.1.3.6.1.2.1.2.2.1.7.1 = INTEGER: up(1)
+.1.3.6.1.2.1.2.2.1.10.1 = Counter32: 100
+.1.3.6.1.2.1.2.2.1.5.1 = Gauge32: 1000000000
+.1.3.6.1.2.1.31.1.1.1.6.1 = Counter64: 200
+.1.3.6.1.2.1.4.20.1.1.10.0.0.1 = IpAddress: 10.0.0.1
+.1.3.6.1.2.1.4.20.1.3.10.0.0.1 = No Such Instance currently exists at this OID
+.1.3.6.1.2.1.47.1.1.1.1.2.1 = Opaque: Float: 1.0
+.1.3.6.1.2.1.47.1.1.1.1.3.1 = BITS: 80 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00
+00 00 poe(0)
+.1.3.6.1.2.1.47.1.1.1.1.4.1 = Network Address: 0A:00:00:01
+.1.3.6.1.2.1.47.1.1.1.1.5.1 = Hex-STRING: 00 01 02 03 04 05 06 07 08 09 0A 0B 0C 0D 0E 0F 
+10 11 12 
+`)
+	sess, err := NewRecordedSession(path)
+	require.NoError(t, err)
+	assert.Equal(t, 12, sess.Len())
+
+	packet, err := sess.Get([]string{
+		"1.3.6.1.2.1.1.1.0", "1.3.6.1.2.1.1.2.0", "1.3.6.1.2.1.1.3.0", "1.3.6.1.2.1.1.4.0", "1.3.6.1.2.1.1.7.0",
+		"1.3.6.1.2.1.2.2.1.6.1", "1.3.6.1.2.1.2.2.1.7.1", "1.3.6.1.2.1.2.2.1.10.1", "1.3.6.1.2.1.2.2.1.5.1",
+		"1.3.6.1.2.1.31.1.1.1.6.1", "1.3.6.1.2.1.4.20.1.1.10.0.0.1", "1.3.6.1.2.1.4.20.1.3.10.0.0.1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.1.1.0", Type: gosnmp.OctetString, Value: []byte("Cisco IOS Software,\nVersion 15.2")},
+		{Name: ".1.3.6.1.2.1.1.2.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.9.1.1"},
+		{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(4202)},
+		{Name: ".1.3.6.1.2.1.1.4.0", Type: gosnmp.OctetString, Value: []byte{}},
+		{Name: ".1.3.6.1.2.1.1.7.0", Type: gosnmp.TimeTicks, Value: uint32(72)},
+		{Name: ".1.3.6.1.2.1.2.2.1.6.1", Type: gosnmp.OctetString, Value: []byte{0x00, 0xe0, 0xb0, 0xc0, 0xd0, 0xe0}},
+		{Name: ".1.3.6.1.2.1.2.2.1.7.1", Type: gosnmp.Integer, Value: 1},
+		{Name: ".1.3.6.1.2.1.2.2.1.10.1", Type: gosnmp.Counter32, Value: uint(100)},
+		{Name: ".1.3.6.1.2.1.2.2.1.5.1", Type: gosnmp.Gauge32, Value: uint(1000000000)},
+		{Name: ".1.3.6.1.2.1.31.1.1.1.6.1", Type: gosnmp.Counter64, Value: uint64(200)},
+		{Name: ".1.3.6.1.2.1.4.20.1.1.10.0.0.1", Type: gosnmp.IPAddress, Value: "10.0.0.1"},
+		{Name: "1.3.6.1.2.1.4.20.1.3.10.0.0.1", Type: gosnmp.NoSuchObject},
+	}, packet.Variables)
+
+	// unsupported types are skipped, and wrapped Hex-STRING values are joined
+	packet, err = sess.GetNext([]string{"1.3.6.1.2.1.47"})
+	require.NoError(t, err)
+	assert.Equal(t, []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.2.1.47.1.1.1.1.5.1", Type: gosnmp.OctetString, Value: []byte{
+			0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10, 0x11, 0x12,
+		}},
+	}, packet.Variables)
+}
+
+func TestNewRecordedSessionErrors(t *testing.T) {
+	for name, content := range map[string]string{
+		"unknown snmprec type":  "1.3.6.1.2.1.1.5.0|99|foo\n",
+		"invalid snmprec value": "1.3.6.1.2.1.1.3.0|67|foo\n",
+		"invalid snmprec hex":   "1.3.6.1.2.1.1.5.0|4x|zz\n",
+		"symbolic oid":          "SNMPv2-MIB::sysName.0 = STRING: foo\n",
+		"invalid wrapped hex":   ".1.3.6.1.2.1.1.5.0 = Hex-STRING: 00 01\nzz\n",
+		"invalid walk value":    ".1.3.6.1.2.1.2.2.1.10.1 = Counter32: foo\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewRecordedSession(writeRecordedWalk(t, "device", content))
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := NewRecordedSession(filepath.Join(t.TempDir(), "missing.snmprec"))
+	assert.Error(t, err)
+}
+
+func TestNewSession_walkFile(t *testing.T) {
+	path := writeRecordedWalk(t, "device.snmprec", "1.3.6.1.2.1.1.5.0|4|foo_sys_name\n")
+	config := &checkconfig.CheckConfig{IPAddress: "1.2.3.4", WalkFile: path}
+
+	sess, err := NewSession(config)
+	require.NoError(t, err)
+	require.IsType(t, &RecordedSession{}, sess)
+	assert.Equal(t, 1, sess.(*RecordedSession).Len())
+
+	// the walk is only loaded again when the file changes
+	sameSess, err := NewSession(config)
+	require.NoError(t, err)
+	assert.Same(t, sess, sameSess)
+
+	require.NoError(t, os.WriteFile(path, []byte("1.3.6.1.2.1.1.5.0|4|foo_sys_name\n1.3.6.1.2.1.1.6.0|4|paris\n"), 0644))
+	newSess, err := NewSession(config)
+	require.NoError(t, err)
+	assert.NotSame(t, sess, newSess)
+	assert.Equal(t, 2, newSess.(*RecordedSession).Len())
+
+	missing, err := NewSession(&checkconfig.CheckConfig{IPAddress: "1.2.3.4", WalkFile: filepath.Join(t.TempDir(), "missing.snmprec")})
+	assert.ErrorContains(t, err, "unable to open recorded walk")
+	// callers check the session against nil, it must not be a typed nil
+	assert.True(t, missing == nil)
+}
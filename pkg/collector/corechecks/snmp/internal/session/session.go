@@ -40,6 +40,26 @@ type GosnmpSession struct {
 	gosnmpInst gosnmp.GoSNMP
 }
 
+// USMEngine holds the SNMPv3 engine parameters learnt from a device during
+// the engine discovery, along with the keys localized with its engine ID
+type USMEngine struct {
+	ID         string
+	Boots      uint32
+	Time       uint32
+	SecretKey  []byte
+	PrivacyKey []byte
+	UpdatedAt  time.Time
+}
+
+// USMEngineCache is implemented by sessions whose SNMPv3 engine parameters can
+// be reused across sessions to the same device, to skip the engine discovery
+type USMEngineCache interface {
+	// USMEngine returns the engine parameters learnt by the session, if any
+	USMEngine() (USMEngine, bool)
+	// SetUSMEngine presets the engine parameters, it must be called before Connect
+	SetUSMEngine(engine USMEngine)
+}
+
 // Connect is used to create a new connection
 func (s *GosnmpSession) Connect() error {
 	return s.gosnmpInst.Connect()
@@ -70,6 +90,54 @@ func (s *GosnmpSession) GetVersion() gosnmp.SnmpVersion {
 	return s.gosnmpInst.Version
 }
 
+// USMEngine returns the SNMPv3 engine parameters learnt by the session
+func (s *GosnmpSession) USMEngine() (USMEngine, bool) {
+	usm, ok := s.gosnmpInst.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok || usm.AuthoritativeEngineID == "" {
+		return USMEngine{}, false
+	}
+	return USMEngine{
+		ID:         usm.AuthoritativeEngineID,
+		Boots:      usm.AuthoritativeEngineBoots,
+		Time:       usm.AuthoritativeEngineTime,
+		SecretKey:  usm.SecretKey,
+		PrivacyKey: usm.PrivacyKey,
+		UpdatedAt:  time.Now(),
+	}, true
+}
+
+// SetUSMEngine presets the SNMPv3 engine parameters of the session, so that
+// the engine discovery is skipped. The engine time is extrapolated from the
+// time elapsed since the parameters were learnt; if the device rebooted in
+// the meantime, it replies with a report and the parameters are rediscovered.
+func (s *GosnmpSession) SetUSMEngine(engine USMEngine) {
+	usm, ok := s.gosnmpInst.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok || engine.ID == "" {
+		return
+	}
+	usm.AuthoritativeEngineID = engine.ID
+	usm.AuthoritativeEngineBoots = engine.Boots
+	usm.AuthoritativeEngineTime = engine.Time + uint32(time.Since(engine.UpdatedAt).Seconds())
+	usm.SecretKey = engine.SecretKey
+	usm.PrivacyKey = engine.PrivacyKey
+	if s.gosnmpInst.ContextEngineID == "" {
+		s.gosnmpInst.ContextEngineID = engine.ID
+	}
+}
+
+// NewSession creates a new session for the device of the config: a RecordedSession
+// when the device is served from a walk file, a GosnmpSession otherwise.
+func NewSession(config *checkconfig.CheckConfig) (Session, error) {
+	if config.WalkFile != "" {
+		recorded, err := loadRecordedSession(config.WalkFile)
+		if err != nil {
+			return nil, err
+		}
+		return recorded, nil
+	}
+	return NewGosnmpSession(config)
+}
+
 // NewGosnmpSession creates a new session
 func NewGosnmpSession(config *checkconfig.CheckConfig) (Session, error) {
 	s := &GosnmpSession{}
@@ -349,6 +349,56 @@ func Test_snmpSession_Connect_Logger(t *testing.T) {
 	assert.Equal(t, logger2, gosnmpSess.gosnmpInst.Logger)
 }
 
+func Test_snmpSession_USMEngine(t *testing.T) {
+	config := checkconfig.CheckConfig{
+		IPAddress:    "1.2.3.4",
+		User:         "admin",
+		AuthProtocol: "sha",
+		AuthKey:      "authkey123",
+	}
+	s, err := NewGosnmpSession(&config)
+	require.NoError(t, err)
+	gosnmpSess := s.(*GosnmpSession)
+
+	_, ok := gosnmpSess.USMEngine()
+	assert.False(t, ok)
+
+	gosnmpSess.SetUSMEngine(USMEngine{
+		ID:        "\x80\x00\x1f\x88\x04engine",
+		Boots:     3,
+		Time:      100,
+		SecretKey: []byte("secret"),
+		UpdatedAt: time.Now().Add(-10 * time.Second),
+	})
+	require.NoError(t, s.Connect())
+
+	usm := gosnmpSess.gosnmpInst.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	assert.Equal(t, "\x80\x00\x1f\x88\x04engine", usm.AuthoritativeEngineID)
+	assert.Equal(t, uint32(3), usm.AuthoritativeEngineBoots)
+	assert.GreaterOrEqual(t, usm.AuthoritativeEngineTime, uint32(110))
+	assert.Equal(t, []byte("secret"), usm.SecretKey)
+	assert.Equal(t, "\x80\x00\x1f\x88\x04engine", gosnmpSess.gosnmpInst.ContextEngineID)
+
+	engine, ok := gosnmpSess.USMEngine()
+	assert.True(t, ok)
+	assert.Equal(t, "\x80\x00\x1f\x88\x04engine", engine.ID)
+	assert.Equal(t, uint32(3), engine.Boots)
+	assert.Equal(t, []byte("secret"), engine.SecretKey)
+
+	// engine parameters are ignored by SNMPv1/v2c sessions
+	config = checkconfig.CheckConfig{
+		IPAddress:       "1.2.3.4",
+		CommunityString: "abc",
+	}
+	s, err = NewGosnmpSession(&config)
+	require.NoError(t, err)
+	gosnmpSess = s.(*GosnmpSession)
+	gosnmpSess.SetUSMEngine(USMEngine{ID: "engine", UpdatedAt: time.Now()})
+	_, ok = gosnmpSess.USMEngine()
+	assert.False(t, ok)
+	assert.Equal(t, "", gosnmpSess.gosnmpInst.ContextEngineID)
+}
+
 func TestFetchAllOIDsUsingGetNext(t *testing.T) {
 	sess := CreateMockSession()
 
@@ -0,0 +1,247 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+// Package profiletest runs a single SNMP profile against a device or a
+// recorded walk, and reports what the SNMP check would collect with it.
+// It is meant to help writing and debugging profiles without deploying them.
+package profiletest
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+	"github.com/DataDog/datadog-agent/pkg/networkdevice/profile/profiledefinition"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmpparse"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/checkconfig"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/fetch"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/profile"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/report"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/valuestore"
+)
+
+// recordedWalkIPAddress is the IP address reported for recorded walks.
+const recordedWalkIPAddress = "127.0.0.1"
+
+const defaultOidBatchSize = 5
+const defaultNamespace = "default"
+
+// Config holds the parameters of a profile test.
+// Exactly one of WalkFile and Device must be set.
+type Config struct {
+	// ProfilePath is the path of the profile to test.
+	ProfilePath string
+	// WalkFile is the path of an snmpwalk output or snmprec file to serve the device from.
+	WalkFile string
+	// Device holds the connection parameters of a live device.
+	Device *snmpparse.SNMPConfig
+	// Namespace is the namespace of the device, `default` if empty.
+	Namespace string
+	// OidBatchSize is the number of OIDs requested at once, 5 if zero.
+	OidBatchSize int
+	// BulkMaxRepetitions is the max repetitions of GETBULK requests, 10 if zero.
+	BulkMaxRepetitions uint32
+}
+
+// Metric is a metric the check would submit.
+type Metric struct {
+	Name  string   `json:"name"`
+	Type  string   `json:"type"`
+	Value float64  `json:"value"`
+	Tags  []string `json:"tags"`
+}
+
+// MissingOID is an OID of the profile for which the device returned nothing.
+type MissingOID struct {
+	OID string `json:"oid"`
+	// Names are the names the profile gives to the OID.
+	Names []string `json:"names,omitempty"`
+	// Column is true for table columns and false for scalars.
+	Column bool `json:"column"`
+}
+
+// IndexTransformFailure is a table row for which a tag using `index_transform`
+// could not be resolved.
+type IndexTransformFailure struct {
+	Table            string `json:"table"`
+	Tag              string `json:"tag"`
+	Index            string `json:"index"`
+	TransformedIndex string `json:"transformed_index"`
+}
+
+// Result is the outcome of a profile test.
+type Result struct {
+	Profile     string `json:"profile"`
+	SysObjectID string `json:"sys_object_id,omitempty"`
+	// Tags are the tags of every metric of the device.
+	Tags                   []string                                `json:"tags"`
+	Metrics                []Metric                                `json:"metrics"`
+	Metadata               []devicemetadata.NetworkDevicesMetadata `json:"metadata,omitempty"`
+	MissingOIDs            []MissingOID                            `json:"missing_oids,omitempty"`
+	IndexTransformFailures []IndexTransformFailure                 `json:"index_transform_failures,omitempty"`
+}
+
+// Run tests the profile of the config.
+func Run(config Config) (*Result, error) {
+	if (config.WalkFile == "") == (config.Device == nil) {
+		return nil, errors.New("either a walk file or a device must be provided")
+	}
+	profileConfig, err := profile.LoadProfileFile(config.ProfilePath)
+	if err != nil {
+		return nil, err
+	}
+	profileName := profileConfig.Definition.Name
+
+	checkConfig := newCheckConfig(config, profileName, profileConfig)
+	sess, err := session.NewSession(checkConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := sess.Connect(); err != nil {
+		return nil, fmt.Errorf("snmp connection error: %w", err)
+	}
+	defer sess.Close()
+
+	result := &Result{Profile: profileName}
+	// The sysObjectID is only informative, since the profile is forced.
+	result.SysObjectID, _ = session.FetchSysObjectID(sess)
+
+	checkProfile, err := checkConfig.BuildProfile(result.SysObjectID)
+	if err != nil {
+		return nil, err
+	}
+	scalarOIDs, columnOIDs := checkProfile.SplitOIDs(checkConfig.CollectDeviceMetadata)
+	values, err := fetch.Fetch(sess, scalarOIDs, columnOIDs, checkConfig.OidBatchSize, checkConfig.BulkMaxRepetitions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch values: %w", err)
+	}
+
+	recorder := &recordingSender{}
+	metricSender := report.NewMetricSender(recorder, "", nil, report.MakeInterfaceBandwidthState(), nil)
+	tags := append(checkConfig.GetStaticTags(), checkProfile.StaticTags...)
+	tags = append(tags, metricSender.GetCheckInstanceMetricTags(checkProfile.MetricTags, values)...)
+	metricSender.ReportMetrics(checkProfile.Metrics, values, tags, checkConfig.DeviceID)
+	metricSender.ReportNetworkDeviceMetadata(checkConfig, checkProfile, values, tags, tags, time.Now(),
+		devicemetadata.DeviceStatusReachable, 0, nil)
+
+	result.Tags = tags
+	result.Metrics = recorder.metrics
+	sort.SliceStable(result.Metrics, func(i, j int) bool {
+		if result.Metrics[i].Name != result.Metrics[j].Name {
+			return result.Metrics[i].Name < result.Metrics[j].Name
+		}
+		return strings.Join(result.Metrics[i].Tags, ",") < strings.Join(result.Metrics[j].Tags, ",")
+	})
+	result.Metadata = recorder.metadata
+	result.MissingOIDs = findMissingOIDs(&profileConfig.Definition, values)
+	for _, failure := range report.FindIndexTransformFailures(checkProfile.Metrics, values) {
+		result.IndexTransformFailures = append(result.IndexTransformFailures, IndexTransformFailure(failure))
+	}
+	return result, nil
+}
+
+func newCheckConfig(config Config, profileName string, profileConfig profile.ProfileConfig) *checkconfig.CheckConfig {
+	checkConfig := &checkconfig.CheckConfig{
+		IPAddress:             recordedWalkIPAddress,
+		WalkFile:              config.WalkFile,
+		Namespace:             config.Namespace,
+		OidBatchSize:          config.OidBatchSize,
+		BulkMaxRepetitions:    config.BulkMaxRepetitions,
+		ProfileName:           profileName,
+		ProfileProvider:       profile.StaticProvider(profile.ProfileConfigMap{profileName: profileConfig}),
+		CollectDeviceMetadata: true,
+		CollectTopology:       true,
+		CollectCDPTopology:    true,
+	}
+	if checkConfig.Namespace == "" {
+		checkConfig.Namespace = defaultNamespace
+	}
+	if checkConfig.OidBatchSize == 0 {
+		checkConfig.OidBatchSize = defaultOidBatchSize
+	}
+	if checkConfig.BulkMaxRepetitions == 0 {
+		checkConfig.BulkMaxRepetitions = checkconfig.DefaultBulkMaxRepetitions
+	}
+	if device := config.Device; device != nil {
+		checkConfig.IPAddress = device.IPAddress
+		checkConfig.Port = device.Port
+		checkConfig.SnmpVersion = device.Version
+		checkConfig.CommunityString = device.CommunityString
+		checkConfig.User = device.Username
+		checkConfig.AuthProtocol = device.AuthProtocol
+		checkConfig.AuthKey = device.AuthKey
+		checkConfig.PrivProtocol = device.PrivProtocol
+		checkConfig.PrivKey = device.PrivKey
+		checkConfig.ContextName = device.Context
+		checkConfig.Timeout = device.Timeout
+		checkConfig.Retries = device.Retries
+	}
+	checkConfig.UpdateDeviceIDAndTags()
+	return checkConfig
+}
+
+// findMissingOIDs returns the OIDs of the profile for which no value was fetched.
+func findMissingOIDs(definition *profiledefinition.ProfileDefinition, values *valuestore.ResultValueStore) []MissingOID {
+	names := oidNames(definition)
+	scalarOIDs, columnOIDs := definition.SplitOIDs(true)
+
+	var missing []MissingOID
+	for _, oid := range scalarOIDs {
+		if _, ok := values.ScalarValues[oid]; !ok {
+			missing = append(missing, MissingOID{OID: oid, Names: names[oid]})
+		}
+	}
+	for _, oid := range columnOIDs {
+		if len(values.ColumnValues[oid]) == 0 {
+			missing = append(missing, MissingOID{OID: oid, Names: names[oid], Column: true})
+		}
+	}
+	return missing
+}
+
+// oidNames returns the names given to each OID of the profile.
+func oidNames(definition *profiledefinition.ProfileDefinition) map[string][]string {
+	names := make(map[string][]string)
+	add := func(symbol profiledefinition.SymbolConfig) {
+		if symbol.OID == "" || symbol.Name == "" {
+			return
+		}
+		for _, name := range names[symbol.OID] {
+			if name == symbol.Name {
+				return
+			}
+		}
+		names[symbol.OID] = append(names[symbol.OID], symbol.Name)
+	}
+	for _, metric := range definition.Metrics {
+		add(metric.Symbol)
+		for _, symbol := range metric.Symbols {
+			add(symbol)
+		}
+		for _, metricTag := range metric.MetricTags {
+			add(profiledefinition.SymbolConfig(metricTag.Symbol))
+		}
+	}
+	for _, metricTag := range definition.MetricTags {
+		add(profiledefinition.SymbolConfig(metricTag.Symbol))
+	}
+	for _, resource := range definition.Metadata {
+		for _, field := range resource.Fields {
+			add(field.Symbol)
+			for _, symbol := range field.Symbols {
+				add(symbol)
+			}
+		}
+		for _, idTag := range resource.IDTags {
+			add(profiledefinition.SymbolConfig(idTag.Symbol))
+		}
+	}
+	return names
+}
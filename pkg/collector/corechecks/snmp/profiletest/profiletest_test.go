@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package profiletest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
+	"github.com/DataDog/datadog-agent/pkg/snmp/snmpparse"
+)
+
+func TestRun(t *testing.T) {
+	mockConfig := configmock.New(t)
+	mockConfig.SetWithoutSource("confd_path", t.TempDir())
+
+	result, err := Run(Config{
+		ProfilePath: "testdata/acme-switch.yaml",
+		WalkFile:    "testdata/acme-switch.snmprec",
+		Namespace:   "lab",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "acme-switch", result.Profile)
+	assert.Equal(t, "1.3.6.1.4.1.99999.1", result.SysObjectID)
+	assert.Subset(t, result.Tags, []string{"snmp_profile:acme-switch", "device_vendor:acme", "snmp_host:switch-1", "device_namespace:lab"})
+
+	metrics := make(map[string][]Metric)
+	for _, metric := range result.Metrics {
+		metrics[metric.Name] = append(metrics[metric.Name], metric)
+	}
+	require.Len(t, metrics["snmp.acmeTemperature"], 1)
+	assert.Equal(t, "gauge", metrics["snmp.acmeTemperature"][0].Type)
+	assert.Equal(t, float64(42), metrics["snmp.acmeTemperature"][0].Value)
+
+	ports := metrics["snmp.acmePortInOctets"]
+	require.Len(t, ports, 2)
+	assert.Equal(t, "rate", ports[0].Type)
+	assert.Equal(t, float64(100), ports[0].Value)
+	assert.Subset(t, ports[0].Tags, []string{"port_name:eth0", "slot_name:slot-a", "snmp_host:switch-1"})
+	assert.Equal(t, float64(200), ports[1].Value)
+	assert.Contains(t, ports[1].Tags, "port_name:eth1")
+	assert.NotContains(t, metrics, "snmp.acmeFanSpeed")
+
+	require.Len(t, result.Metadata, 1)
+	require.Len(t, result.Metadata[0].Devices, 1)
+	device := result.Metadata[0].Devices[0]
+	assert.Equal(t, "lab:127.0.0.1", device.ID)
+	assert.Equal(t, "switch-1", device.Name)
+	assert.Equal(t, "acme", device.Vendor)
+	assert.Equal(t, "acme-switch", device.Profile)
+
+	assert.Equal(t, []MissingOID{
+		{OID: "1.3.6.1.4.1.99999.1.2.0", Names: []string{"acmeFanSpeed"}},
+		{OID: "1.3.6.1.4.1.99999.1.9.0", Names: []string{"acmeModel"}},
+	}, result.MissingOIDs)
+	assert.Equal(t, []IndexTransformFailure{
+		{Table: "acmePortTable", Tag: "slot_name", Index: "2.1", TransformedIndex: "2"},
+	}, result.IndexTransformFailures)
+}
+
+func TestRunErrors(t *testing.T) {
+	mockConfig := configmock.New(t)
+	mockConfig.SetWithoutSource("confd_path", t.TempDir())
+
+	_, err := Run(Config{ProfilePath: "testdata/acme-switch.yaml"})
+	assert.EqualError(t, err, "either a walk file or a device must be provided")
+
+	_, err = Run(Config{ProfilePath: "testdata/acme-switch.yaml", WalkFile: "testdata/acme-switch.snmprec", Device: &snmpparse.SNMPConfig{}})
+	assert.EqualError(t, err, "either a walk file or a device must be provided")
+
+	_, err = Run(Config{ProfilePath: "testdata/missing.yaml", WalkFile: "testdata/acme-switch.snmprec"})
+	assert.ErrorContains(t, err, "unable to read file")
+
+	_, err = Run(Config{ProfilePath: "testdata/acme-switch.yaml", WalkFile: "testdata/missing.snmprec"})
+	assert.ErrorContains(t, err, "unable to open recorded walk")
+}
@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package profiletest
+
+import (
+	"encoding/json"
+
+	"github.com/DataDog/datadog-agent/comp/forwarder/eventplatform"
+	"github.com/DataDog/datadog-agent/pkg/aggregator/sender"
+	"github.com/DataDog/datadog-agent/pkg/collector/check/stats"
+	"github.com/DataDog/datadog-agent/pkg/metrics/event"
+	"github.com/DataDog/datadog-agent/pkg/metrics/servicecheck"
+	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
+	"github.com/DataDog/datadog-agent/pkg/serializer/types"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// recordingSender is a sender.Sender keeping the metrics and device metadata
+// it is given instead of forwarding them.
+type recordingSender struct {
+	metrics  []Metric
+	metadata []devicemetadata.NetworkDevicesMetadata
+}
+
+var _ sender.Sender = (*recordingSender)(nil)
+
+func (s *recordingSender) record(metricType string, metric string, value float64, tags []string) {
+	s.metrics = append(s.metrics, Metric{
+		Name:  metric,
+		Type:  metricType,
+		Value: value,
+		Tags:  append([]string(nil), tags...),
+	})
+}
+
+func (s *recordingSender) Commit() {}
+
+func (s *recordingSender) Gauge(metric string, value float64, _ string, tags []string) {
+	s.record("gauge", metric, value, tags)
+}
+
+func (s *recordingSender) GaugeNoIndex(metric string, value float64, _ string, tags []string) {
+	s.record("gauge", metric, value, tags)
+}
+
+func (s *recordingSender) Rate(metric string, value float64, _ string, tags []string) {
+	s.record("rate", metric, value, tags)
+}
+
+func (s *recordingSender) Count(metric string, value float64, _ string, tags []string) {
+	s.record("count", metric, value, tags)
+}
+
+func (s *recordingSender) MonotonicCount(metric string, value float64, _ string, tags []string) {
+	s.record("monotonic_count", metric, value, tags)
+}
+
+func (s *recordingSender) MonotonicCountWithFlushFirstValue(metric string, value float64, _ string, tags []string, _ bool) {
+	s.record("monotonic_count", metric, value, tags)
+}
+
+func (s *recordingSender) Counter(metric string, value float64, _ string, tags []string) {
+	s.record("counter", metric, value, tags)
+}
+
+func (s *recordingSender) Histogram(metric string, value float64, _ string, tags []string) {
+	s.record("histogram", metric, value, tags)
+}
+
+func (s *recordingSender) Historate(metric string, value float64, _ string, tags []string) {
+	s.record("historate", metric, value, tags)
+}
+
+func (s *recordingSender) Distribution(metric string, value float64, _ string, tags []string) {
+	s.record("distribution", metric, value, tags)
+}
+
+func (s *recordingSender) ServiceCheck(string, servicecheck.ServiceCheckStatus, string, []string, string) {
+}
+
+func (s *recordingSender) HistogramBucket(string, int64, float64, float64, bool, string, []string, bool) {
+}
+
+func (s *recordingSender) GaugeWithTimestamp(metric string, value float64, _ string, tags []string, _ float64) error {
+	s.record("gauge", metric, value, tags)
+	return nil
+}
+
+func (s *recordingSender) CountWithTimestamp(metric string, value float64, _ string, tags []string, _ float64) error {
+	s.record("count", metric, value, tags)
+	return nil
+}
+
+func (s *recordingSender) Event(event.Event) {}
+
+func (s *recordingSender) EventPlatformEvent(rawEvent []byte, eventType string) {
+	if eventType != eventplatform.EventTypeNetworkDevicesMetadata {
+		return
+	}
+	var payload devicemetadata.NetworkDevicesMetadata
+	if err := json.Unmarshal(rawEvent, &payload); err != nil {
+		log.Warnf("unable to decode device metadata: %s", err)
+		return
+	}
+	s.metadata = append(s.metadata, payload)
+}
+
+func (s *recordingSender) GetSenderStats() stats.SenderStats {
+	return stats.NewSenderStats()
+}
+
+func (s *recordingSender) DisableDefaultHostname(bool) {}
+
+func (s *recordingSender) SetCheckCustomTags([]string) {}
+
+func (s *recordingSender) SetCheckService(string) {}
+
+func (s *recordingSender) SetNoIndex(bool) {}
+
+func (s *recordingSender) FinalizeCheckServiceTag() {}
+
+func (s *recordingSender) OrchestratorMetadata([]types.ProcessMessageBody, string, int) {}
+
+func (s *recordingSender) OrchestratorManifest([]types.ProcessMessageBody, string) {}
@@ -28,6 +28,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/discovery"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/report"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/snmp/internal/session"
+	devicemetadata "github.com/DataDog/datadog-agent/pkg/networkdevice/metadata"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/util/option"
 )
@@ -49,6 +50,8 @@ type Check struct {
 	sessionFactory             session.Factory
 	workerRunDeviceCheckErrors *atomic.Uint64
 	agentConfig                config.Component
+	// devices holds the devices monitored by all the SNMP check instances
+	devices *devicemetadata.DeviceRegistry
 }
 
 // Run executes the check
@@ -79,7 +82,7 @@ func (c *Check) Run() error {
 				continue
 			}
 			// `interface_configs` option not supported by SNMP corecheck autodiscovery
-			deviceCk.SetSender(report.NewMetricSender(sender, hostname, nil, deviceCk.GetInterfaceBandwidthState()))
+			deviceCk.SetSender(report.NewMetricSender(sender, hostname, nil, deviceCk.GetInterfaceBandwidthState(), c.devices))
 			jobs <- deviceCk
 		}
 		close(jobs)
@@ -93,7 +96,7 @@ func (c *Check) Run() error {
 		if err != nil {
 			return err
 		}
-		c.singleDeviceCk.SetSender(report.NewMetricSender(sender, hostname, c.config.InterfaceConfigs, c.singleDeviceCk.GetInterfaceBandwidthState()))
+		c.singleDeviceCk.SetSender(report.NewMetricSender(sender, hostname, c.config.InterfaceConfigs, c.singleDeviceCk.GetInterfaceBandwidthState(), c.devices))
 		checkErr = c.runCheckDevice(c.singleDeviceCk)
 	}
 
@@ -215,8 +218,9 @@ func newCheck(agentConfig config.Component, rcClient rcclient.Component) check.C
 	return &Check{
 		rcClient:                   rcClient,
 		CheckBase:                  core.NewCheckBase(common.SnmpIntegrationName),
-		sessionFactory:             session.NewGosnmpSession,
+		sessionFactory:             session.NewSession,
 		workerRunDeviceCheckErrors: atomic.NewUint64(0),
 		agentConfig:                agentConfig,
+		devices:                    devicemetadata.MonitoredDevices,
 	}
 }
@@ -9,6 +9,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -2598,6 +2600,95 @@ community_string: public
 	chk.Cancel()
 }
 
+func TestCheck_Run_walkFile(t *testing.T) {
+	timeNow = common.MockTimeNow
+	deps := createDeps(t)
+	senderManager := deps.Demultiplexer
+	profile.SetConfdPathAndCleanProfiles()
+
+	walkFile := filepath.Join(t.TempDir(), "f5-big-ip.snmprec")
+	// language=text
+	walk := `1.3.6.1.2.1.1.1.0|4|my_desc
+1.3.6.1.2.1.1.2.0|6|1.3.6.1.4.1.3375.2.1.3.4.1
+1.3.6.1.2.1.1.3.0|67|20
+1.3.6.1.2.1.1.5.0|4|foo_sys_name
+1.3.6.1.2.1.2.2.1.6.1|4x|000000000001
+1.3.6.1.2.1.2.2.1.7.1|2|1
+1.3.6.1.2.1.2.2.1.8.1|2|1
+1.3.6.1.2.1.2.2.1.13.1|65|131
+1.3.6.1.2.1.2.2.1.14.1|65|141
+1.3.6.1.2.1.31.1.1.1.1.1|4|nameRow1
+1.3.6.1.2.1.31.1.1.1.18.1|4|descRow1
+1.3.6.1.4.1.3375.2.1.1.2.1.44.0|2|30
+`
+	assert.NoError(t, os.WriteFile(walkFile, []byte(walk), 0o644))
+
+	// The default session factory serves the device from the walk file.
+	chk := Check{sessionFactory: session.NewSession}
+	// language=yaml
+	rawInstanceConfig := []byte(fmt.Sprintf(`
+ip_address: 1.2.3.4
+community_string: public
+walk_file: %s
+profile: f5-big-ip
+collect_topology: false
+`, walkFile))
+	// language=yaml
+	rawInitConfig := []byte(`
+profiles:
+  f5-big-ip:
+    definition_file: f5-big-ip.yaml
+`)
+
+	err := chk.Configure(senderManager, integration.FakeConfigHash, rawInstanceConfig, rawInitConfig, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, walkFile, chk.config.WalkFile)
+
+	sender := mocksender.NewMockSenderWithSenderManager(chk.ID(), senderManager)
+	sender.On("Gauge", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("MonotonicCount", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("ServiceCheck", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+	sender.On("EventPlatformEvent", mock.Anything, mock.Anything).Return()
+	sender.On("Commit").Return()
+
+	err = chk.Run()
+	assert.NoError(t, err)
+
+	snmpTags := []string{
+		"device_namespace:default",
+		"snmp_device:1.2.3.4",
+		"device_ip:1.2.3.4",
+		"device_id:default:1.2.3.4",
+		"snmp_profile:f5-big-ip",
+		"device_vendor:f5",
+		"snmp_host:foo_sys_name",
+		"static_tag:from_profile_root",
+		"static_tag:from_base_profile",
+	}
+	row1Tags := append(utils.CopyStrings(snmpTags), "interface:nameRow1", "interface_alias:descRow1", "mac_address:00:00:00:00:00:01", "table_static_tag:val")
+
+	sender.AssertServiceCheck(t, "snmp.can_check", servicecheck.ServiceCheckOK, "", snmpTags, "")
+	sender.AssertMetric(t, "Gauge", "snmp.device.reachable", 1., "", snmpTags)
+	sender.AssertMetric(t, "Gauge", "snmp.sysUpTimeInstance", float64(20), "", snmpTags)
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ifInErrors", float64(70.5), "", row1Tags)
+	sender.AssertMetric(t, "MonotonicCount", "snmp.ifInDiscards", float64(131), "", row1Tags)
+}
+
+func TestCheck_Configure_walkFileErrors(t *testing.T) {
+	deps := createDeps(t)
+	profile.SetConfdPathAndCleanProfiles()
+	chk := Check{sessionFactory: session.NewSession}
+
+	// language=yaml
+	rawInstanceConfig := []byte(`
+network_address: 10.0.0.0/30
+community_string: public
+walk_file: /tmp/does-not-matter.snmprec
+`)
+	err := chk.Configure(deps.Demultiplexer, integration.FakeConfigHash, rawInstanceConfig, []byte(``), "test")
+	assert.ErrorContains(t, err, "`walk_file` and `network` cannot be used at the same time")
+}
+
 // Wait for discovery to be completed
 func waitForDiscoveredDevices(discovery *discovery.Discovery, expectedDeviceCount int, timeout time.Duration) ([]*devicecheck.DeviceCheck, error) {
 	timeoutTimer := time.After(timeout)
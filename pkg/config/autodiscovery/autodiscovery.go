@@ -41,6 +41,11 @@ func DiscoverComponentsFromConfig() ([]pkgconfigsetup.ConfigurationProviders, []
 		detectedListeners = append(detectedListeners, pkgconfigsetup.Listeners{Name: "database-monitoring-aurora"})
 		log.Info("Database monitoring aurora discovery is enabled: Adding the aurora listener")
 	}
+	// Add systemd listener if the systemd units are collected
+	if pkgconfigsetup.Datadog().GetBool("workloadmeta.systemd_collector.enabled") {
+		detectedListeners = append(detectedListeners, pkgconfigsetup.Listeners{Name: "systemd"})
+		log.Info("Systemd unit collection is enabled: Adding the systemd listener")
+	}
 
 	// Auto-add file-based kube service and endpoints config providers based on check config files.
 	if flavor.GetFlavor() == flavor.ClusterAgent {
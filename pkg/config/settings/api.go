@@ -20,6 +20,7 @@ type Client interface {
 	List() (map[string]settings.RuntimeSettingResponse, error)
 	FullConfig() (string, error)
 	FullConfigBySource() (string, error)
+	Explain(key string) (settings.SettingExplanation, error)
 	HTTPClient() *http.Client
 }
 
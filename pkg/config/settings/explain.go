@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/DataDog/datadog-agent/comp/core/settings"
+	"github.com/DataDog/datadog-agent/pkg/config/model"
+)
+
+// PrintExplanation prints every source defining a value for a setting, as returned by Client.Explain
+func PrintExplanation(w io.Writer, explanation settings.SettingExplanation) {
+	fmt.Fprintf(w, "%s is set to: %s\n", explanation.Setting, formatValue(explanation.Value))
+	fmt.Fprintf(w, "  source: %s\n", describeSource(explanation.Source))
+	if explanation.RawValue != nil {
+		fmt.Fprintf(w, "  value before secret resolution: %s\n", formatValue(explanation.RawValue))
+	}
+	if explanation.LastRuntimeChange != nil {
+		fmt.Fprintf(w, "  last changed at runtime: %s by %s\n", explanation.LastRuntimeChange.Format(time.RFC3339), describeSource(explanation.LastRuntimeChangeSource))
+	}
+
+	fmt.Fprintf(w, "sources defining a value, from the lowest to the highest priority:\n")
+	for _, layer := range explanation.Layers {
+		winner := ""
+		if layer.Source == explanation.Source {
+			winner = " (effective)"
+		}
+		fmt.Fprintf(w, "  %s: %s%s\n", layer.Source, formatValue(layer.Value), winner)
+	}
+}
+
+func describeSource(source model.Source) string {
+	if source == model.SourceLocalConfigProcess {
+		return fmt.Sprintf("%s (synchronized from the core agent)", source)
+	}
+	return string(source)
+}
+
+func formatValue(value interface{}) string {
+	formatted, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(formatted)
+}
@@ -45,6 +45,13 @@ func setupAPM(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("apm_config.obfuscation.valkey.remove_all_args", false, "DD_APM_OBFUSCATION_VALKEY_REMOVE_ALL_ARGS")
 	config.BindEnvAndSetDefault("apm_config.obfuscation.memcached.enabled", true, "DD_APM_OBFUSCATION_MEMCACHED_ENABLED")
 	config.BindEnvAndSetDefault("apm_config.obfuscation.memcached.keep_command", false, "DD_APM_OBFUSCATION_MEMCACHED_KEEP_COMMAND")
+	config.BindEnvAndSetDefault("apm_config.obfuscation.graphql.enabled", false, "DD_APM_OBFUSCATION_GRAPHQL_ENABLED")
+	config.BindEnvAndSetDefault("apm_config.obfuscation.cql.enabled", false, "DD_APM_OBFUSCATION_CQL_ENABLED")
+	config.BindEnvAndSetDefault("apm_config.obfuscation.cql.remove_keyspace", false, "DD_APM_OBFUSCATION_CQL_REMOVE_KEYSPACE")
+	config.BindEnvAndSetDefault("apm_config.obfuscation.dynamodb.enabled", false, "DD_APM_OBFUSCATION_DYNAMODB_ENABLED")
+	config.BindEnvAndSetDefault("apm_config.obfuscation.dynamodb.keep_values", []string{}, "DD_APM_OBFUSCATION_DYNAMODB_KEEP_VALUES")
+	config.BindEnvAndSetDefault("apm_config.obfuscation.kafka.enabled", false, "DD_APM_OBFUSCATION_KAFKA_ENABLED")
+	config.BindEnvAndSetDefault("apm_config.obfuscation.kafka.keep_headers", []string{}, "DD_APM_OBFUSCATION_KAFKA_KEEP_HEADERS")
 	config.BindEnvAndSetDefault("apm_config.obfuscation.cache.enabled", true, "DD_APM_OBFUSCATION_CACHE_ENABLED")
 	config.BindEnvAndSetDefault("apm_config.obfuscation.cache.max_size", 5000000, "DD_APM_OBFUSCATION_CACHE_MAX_SIZE")
 	config.SetKnown("apm_config.filter_tags.require")
@@ -133,6 +140,11 @@ func setupAPM(config pkgconfigmodel.Setup) {
 	config.BindEnv("apm_config.enable_rare_sampler", "DD_APM_ENABLE_RARE_SAMPLER")
 	config.BindEnv("apm_config.disable_rare_sampler", "DD_APM_DISABLE_RARE_SAMPLER") // Deprecated
 	config.BindEnv("apm_config.max_remote_traces_per_second", "DD_APM_MAX_REMOTE_TPS")
+	config.BindEnv("apm_config.latency_sampler.enabled", "DD_APM_LATENCY_SAMPLER_ENABLED")
+	config.BindEnv("apm_config.latency_sampler.percentile", "DD_APM_LATENCY_SAMPLER_PERCENTILE")
+	config.BindEnv("apm_config.latency_sampler.tps", "DD_APM_LATENCY_SAMPLER_TPS")
+	config.BindEnv("apm_config.latency_sampler.cardinality", "DD_APM_LATENCY_SAMPLER_CARDINALITY")
+	config.BindEnv("apm_config.bytes_budget_per_minute", "DD_APM_BYTES_BUDGET_PER_MINUTE")
 	config.BindEnv("apm_config.probabilistic_sampler.enabled", "DD_APM_PROBABILISTIC_SAMPLER_ENABLED")
 	config.BindEnv("apm_config.probabilistic_sampler.sampling_percentage", "DD_APM_PROBABILISTIC_SAMPLER_SAMPLING_PERCENTAGE")
 	config.BindEnv("apm_config.probabilistic_sampler.hash_seed", "DD_APM_PROBABILISTIC_SAMPLER_HASH_SEED")
@@ -448,7 +448,9 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("network_devices.snmp_traps.community_strings", []string{})
 	config.BindEnvAndSetDefault("network_devices.snmp_traps.bind_host", "0.0.0.0")
 	config.BindEnvAndSetDefault("network_devices.snmp_traps.stop_timeout", 5) // in seconds
+	config.BindEnvAndSetDefault("network_devices.snmp_traps.mibs_path", "")
 	config.SetKnown("network_devices.snmp_traps.users")
+	config.SetKnown("network_devices.snmp_traps.relay_targets")
 
 	// NetFlow
 	config.SetKnown("network_devices.netflow.listeners")
@@ -458,9 +460,18 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	config.SetKnown("network_devices.netflow.aggregator_flow_context_ttl")
 	config.SetKnown("network_devices.netflow.aggregator_port_rollup_threshold")
 	config.SetKnown("network_devices.netflow.aggregator_rollup_tracker_refresh_interval")
+	config.SetKnown("network_devices.netflow.aggregator_keys")
+	config.SetKnown("network_devices.netflow.aggregator_ipv4_prefix_length")
+	config.SetKnown("network_devices.netflow.aggregator_ipv6_prefix_length")
+	config.SetKnown("network_devices.netflow.aggregator_top_talkers")
+	config.SetKnown("network_devices.netflow.aggregator_top_talkers_capacity")
 	config.BindEnvAndSetDefault("network_devices.netflow.enabled", "false")
 	bindEnvAndSetLogsConfigKeys(config, "network_devices.netflow.forwarder.")
 	config.BindEnvAndSetDefault("network_devices.netflow.reverse_dns_enrichment_enabled", false)
+	config.SetKnown("network_devices.netflow.enrichment.geoip_database_path")
+	config.SetKnown("network_devices.netflow.enrichment.asn_database_path")
+	config.SetKnown("network_devices.netflow.enrichment.cidr_tags")
+	config.BindEnvAndSetDefault("network_devices.netflow.enrichment.interface_names_enabled", false)
 
 	// Network Path
 	config.BindEnvAndSetDefault("network_path.connections_monitoring.enabled", false)
@@ -691,6 +702,10 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("checks_tag_cardinality", "low")
 	config.BindEnvAndSetDefault("dogstatsd_tag_cardinality", "low")
 
+	// Rules deriving, renaming and dropping the tags of the entities, applied by the tagger to the tags of every
+	// pipeline. The environment variable takes the rules as a JSON list.
+	config.BindEnv("tagger_rules")
+
 	config.BindEnvAndSetDefault("hpa_watcher_polling_freq", 10)
 	config.BindEnvAndSetDefault("hpa_watcher_gc_period", 60*5) // 5 minutes
 	config.BindEnvAndSetDefault("hpa_configmap_name", "datadog-custom-metrics")
@@ -861,6 +876,10 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	// Remote process collector
 	config.BindEnvAndSetDefault("workloadmeta.local_process_collector.collection_interval", DefaultLocalProcessCollectorInterval)
 
+	// Systemd unit collector
+	config.BindEnvAndSetDefault("workloadmeta.systemd_collector.enabled", false)
+	config.BindEnvAndSetDefault("workloadmeta.systemd_collector.private_socket", "")
+
 	// SBOM configuration
 	config.BindEnvAndSetDefault("sbom.enabled", false)
 	bindEnvAndSetLogsConfigKeys(config, "sbom.")
@@ -900,6 +919,8 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	config.BindEnv("process_config.orchestrator_dd_url", "DD_PROCESS_CONFIG_ORCHESTRATOR_DD_URL", "DD_PROCESS_AGENT_ORCHESTRATOR_DD_URL")
 	// DEPRECATED in favor of `orchestrator_explorer.orchestrator_additional_endpoints` setting. If both are set `orchestrator_explorer.orchestrator_additional_endpoints` will take precedence.
 	config.SetKnown("process_config.orchestrator_additional_endpoints")
+	deprecateSetting("process_config.orchestrator_dd_url", "orchestrator_explorer.orchestrator_dd_url")
+	deprecateSetting("process_config.orchestrator_additional_endpoints", "orchestrator_explorer.orchestrator_additional_endpoints")
 	config.BindEnvAndSetDefault("orchestrator_explorer.extra_tags", []string{})
 
 	// Network
@@ -952,6 +973,7 @@ func InitConfig(config pkgconfigmodel.Setup) {
 	// Datadog security agent (compliance)
 	config.BindEnvAndSetDefault("compliance_config.enabled", false)
 	config.BindEnvAndSetDefault("compliance_config.xccdf.enabled", false) // deprecated, use host_benchmarks instead
+	deprecateSetting("compliance_config.xccdf.enabled", "compliance_config.host_benchmarks.enabled")
 	config.BindEnvAndSetDefault("compliance_config.host_benchmarks.enabled", true)
 	config.BindEnvAndSetDefault("compliance_config.database_benchmarks.enabled", false)
 	config.BindEnvAndSetDefault("compliance_config.check_interval", 20*time.Minute)
@@ -1115,6 +1137,7 @@ func agent(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("syslog_key", "")
 	config.BindEnvAndSetDefault("syslog_tls_verify", true)
 	config.BindEnv("ipc_address") // deprecated: use `cmd_host` instead
+	deprecateSetting("ipc_address", "cmd_host")
 	config.BindEnvAndSetDefault("cmd_host", "localhost")
 	config.BindEnvAndSetDefault("cmd_port", 5001)
 	config.BindEnvAndSetDefault("agent_ipc.host", "localhost")
@@ -1155,6 +1178,7 @@ func agent(config pkgconfigmodel.Setup) {
 
 	// Yaml keys which values are stripped from flare
 	config.BindEnvAndSetDefault("flare_stripped_keys", []string{})
+	deprecateSetting("flare_stripped_keys", "scrubber.additional_keys")
 	config.BindEnvAndSetDefault("scrubber.additional_keys", []string{})
 
 	// Duration during which the host tags will be submitted with metrics.
@@ -1307,6 +1331,8 @@ func debugging(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("tracemalloc_exclude", "")
 	config.BindEnvAndSetDefault("tracemalloc_whitelist", "") // deprecated
 	config.BindEnvAndSetDefault("tracemalloc_blacklist", "") // deprecated
+	deprecateSetting("tracemalloc_whitelist", "tracemalloc_include")
+	deprecateSetting("tracemalloc_blacklist", "tracemalloc_exclude")
 	config.BindEnvAndSetDefault("run_path", defaultRunPath)
 	config.BindEnv("no_proxy_nonexact_match")
 }
@@ -1389,6 +1415,7 @@ func forwarder(config pkgconfigmodel.Setup) {
 	// Forwarder
 	config.BindEnvAndSetDefault("additional_endpoints", map[string][]string{})
 	config.BindEnvAndSetDefault("forwarder_timeout", 20)
+	deprecateSetting("forwarder_retry_queue_max_size", "forwarder_retry_queue_payloads_max_size")
 	config.BindEnv("forwarder_retry_queue_max_size")                                                     // Deprecated in favor of `forwarder_retry_queue_payloads_max_size`
 	config.BindEnv("forwarder_retry_queue_payloads_max_size")                                            // Default value is defined inside `NewOptions` in pkg/forwarder/forwarder.go
 	config.BindEnvAndSetDefault("forwarder_connection_reset_interval", 0)                                // in seconds, 0 means disabled
@@ -1521,6 +1548,7 @@ func logsagent(config pkgconfigmodel.Setup) {
 	// enable the logs-agent:
 	config.BindEnvAndSetDefault("logs_enabled", false)
 	config.BindEnvAndSetDefault("log_enabled", false) // deprecated, use logs_enabled instead
+	deprecateSetting("log_enabled", "logs_enabled")
 	// collect all logs from all containers:
 	config.BindEnvAndSetDefault("logs_config.container_collect_all", false)
 	// add a socks5 proxy:
@@ -1579,9 +1607,11 @@ func logsagent(config pkgconfigmodel.Setup) {
 	config.BindEnvAndSetDefault("logs_config.run_path", defaultRunPath)
 	// DEPRECATED in favor of `logs_config.force_use_http`.
 	config.BindEnvAndSetDefault("logs_config.use_http", false)
+	deprecateSetting("logs_config.use_http", "logs_config.force_use_http")
 	config.BindEnvAndSetDefault("logs_config.force_use_http", false)
 	// DEPRECATED in favor of `logs_config.force_use_tcp`.
 	config.BindEnvAndSetDefault("logs_config.use_tcp", false)
+	deprecateSetting("logs_config.use_tcp", "logs_config.force_use_tcp")
 	config.BindEnvAndSetDefault("logs_config.force_use_tcp", false)
 
 	// Transport protocol for log payloads
@@ -1502,6 +1502,13 @@ func TestAPMObfuscationDefaultValue(t *testing.T) {
 	assert.False(t, conf.GetBool("apm_config.obfuscation.redis.remove_all_args"))
 	assert.True(t, conf.GetBool("apm_config.obfuscation.memcached.enabled"))
 	assert.False(t, conf.GetBool("apm_config.obfuscation.memcached.keep_command"))
+	assert.False(t, conf.GetBool("apm_config.obfuscation.graphql.enabled"))
+	assert.False(t, conf.GetBool("apm_config.obfuscation.cql.enabled"))
+	assert.False(t, conf.GetBool("apm_config.obfuscation.cql.remove_keyspace"))
+	assert.False(t, conf.GetBool("apm_config.obfuscation.dynamodb.enabled"))
+	assert.Len(t, conf.GetStringSlice("apm_config.obfuscation.dynamodb.keep_values"), 0)
+	assert.False(t, conf.GetBool("apm_config.obfuscation.kafka.enabled"))
+	assert.Len(t, conf.GetStringSlice("apm_config.obfuscation.kafka.keep_headers"), 0)
 	assert.True(t, conf.GetBool("apm_config.obfuscation.credit_cards.enabled"))
 	assert.False(t, conf.GetBool("apm_config.obfuscation.credit_cards.luhn"))
 	assert.Len(t, conf.GetStringSlice("apm_config.obfuscation.credit_cards.keep_values"), 0)
@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package setup
+
+import (
+	"maps"
+	"sync"
+)
+
+var (
+	// deprecatedSettings maps the settings of the agent configuration that are
+	// deprecated in favor of other ones to their replacement, as registered
+	// with deprecateSetting when binding them
+	deprecatedSettings      = map[string]string{}
+	deprecatedSettingsMutex sync.RWMutex
+)
+
+// deprecateSetting registers a setting as deprecated in favor of its
+// replacement, so that tools like `agent config validate` report it. It is
+// called next to the binding of the setting.
+func deprecateSetting(setting string, replacement string) {
+	deprecatedSettingsMutex.Lock()
+	defer deprecatedSettingsMutex.Unlock()
+	deprecatedSettings[setting] = replacement
+}
+
+// DeprecatedSettings returns the deprecated settings of the configuration,
+// mapped to the settings replacing them
+func DeprecatedSettings() map[string]string {
+	deprecatedSettingsMutex.RLock()
+	defer deprecatedSettingsMutex.RUnlock()
+	return maps.Clone(deprecatedSettings)
+}
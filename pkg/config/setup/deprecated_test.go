@@ -0,0 +1,21 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package setup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecatedSettingsAreKnown(t *testing.T) {
+	known := newTestConf(t).GetKnownKeysLowercased()
+	deprecated := DeprecatedSettings()
+	assert.Equal(t, "logs_enabled", deprecated["log_enabled"], "settings are deprecated when they are bound")
+	for setting := range deprecated {
+		assert.Contains(t, known, setting)
+	}
+}
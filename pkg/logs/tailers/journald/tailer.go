@@ -17,6 +17,7 @@ import (
 	"github.com/coreos/go-systemd/sdjournal"
 
 	tagger "github.com/DataDog/datadog-agent/comp/core/tagger/def"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/internal/decoder"
 	"github.com/DataDog/datadog-agent/pkg/logs/internal/tag"
@@ -441,6 +442,18 @@ func (t *Tailer) getTags(entry *sdjournal.JournalEntry) []string {
 	var tags []string
 	if t.isContainerEntry(entry) {
 		tags = t.getContainerTags(t.getContainerID(entry))
+	} else if unit, exists := entry.Fields[sdjournal.SD_JOURNAL_FIELD_SYSTEMD_UNIT]; exists {
+		tags = t.getSystemdUnitTags(unit)
+	}
+	return tags
+}
+
+// getSystemdUnitTags returns the tags of a systemd unit, they are only known
+// when the systemd units are collected.
+func (t *Tailer) getSystemdUnitTags(unit string) []string {
+	tags, err := t.tagger.Tag(types.NewEntityID(types.SystemdUnit, unit), types.HighCardinality)
+	if err != nil {
+		log.Warn(err)
 	}
 	return tags
 }
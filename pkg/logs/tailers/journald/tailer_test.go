@@ -17,6 +17,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	taggerfxmock "github.com/DataDog/datadog-agent/comp/core/tagger/fx-mock"
+	"github.com/DataDog/datadog-agent/comp/core/tagger/types"
 	"github.com/DataDog/datadog-agent/comp/logs/agent/config"
 	configmock "github.com/DataDog/datadog-agent/pkg/config/mock"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
@@ -442,6 +443,27 @@ func TestApplicationNameShouldBeDockerWhenTagNotFound(t *testing.T) {
 	assert.False(t, hit)
 }
 
+func TestSystemdUnitTags(t *testing.T) {
+	source := sources.NewLogSource("", &config.LogsConfig{})
+	fakeTagger := taggerfxmock.SetupFakeTagger(t)
+	fakeTagger.SetTags(types.NewEntityID(types.SystemdUnit, "nginx.service"), "workloadmeta-systemd_unit", []string{"systemd_unit:nginx.service", "service:web"}, nil, nil, nil)
+	tailer := NewTailer(source, nil, nil, true, fakeTagger)
+
+	assert.ElementsMatch(t, []string{"systemd_unit:nginx.service", "service:web"}, tailer.getTags(
+		&sdjournal.JournalEntry{
+			Fields: map[string]string{
+				sdjournal.SD_JOURNAL_FIELD_SYSTEMD_UNIT: "nginx.service",
+			},
+		}))
+
+	assert.Empty(t, tailer.getTags(
+		&sdjournal.JournalEntry{
+			Fields: map[string]string{
+				sdjournal.SD_JOURNAL_FIELD_SYSTEMD_UNIT: "cron.service",
+			},
+		}))
+}
+
 func TestWrongTypeFromCache(t *testing.T) {
 	containerID := "bar3"
 
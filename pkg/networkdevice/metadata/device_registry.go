@@ -0,0 +1,162 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package metadata
+
+import (
+	"sync"
+	"time"
+)
+
+// DeviceRegistryTTL is the duration after which a device that stopped being
+// reported is evicted from the registry.
+const DeviceRegistryTTL = 1 * time.Hour
+
+// MonitoredDevices holds the devices monitored by the SNMP integration, for the
+// check instances to resolve each other as topology link ends, and for other
+// components of the Agent (e.g. NetFlow) to look up their interfaces. It is only
+// meant to be passed to those components when they are created, which must not
+// use it directly so that their instances, and tests, can use their own registry.
+var MonitoredDevices = NewDeviceRegistry(DeviceRegistryTTL)
+
+// MonitoredDevice is a device monitored by the SNMP integration.
+type MonitoredDevice struct {
+	ID        string
+	Namespace string
+	// IPAddress is the address the device is monitored at.
+	IPAddress string
+	Name      string
+	// IPAddresses are all the addresses the device can be reached at.
+	IPAddresses []string
+	Interfaces  []InterfaceMetadata
+	LastUpdate  time.Time
+}
+
+// registeredDevice is a MonitoredDevice along with its interfaces by index, and
+// the IDs of the topology links it reported.
+type registeredDevice struct {
+	MonitoredDevice
+	interfacesByIndex map[uint32]InterfaceMetadata
+	linkIDs           map[string]struct{}
+}
+
+type deviceAddress struct {
+	namespace string
+	ipAddress string
+}
+
+// DeviceRegistry holds the last reported state of monitored devices, evicting
+// the devices that weren't reported for longer than its TTL.
+type DeviceRegistry struct {
+	mu        sync.RWMutex
+	ttl       time.Duration
+	devices   map[string]*registeredDevice
+	byAddress map[deviceAddress]*registeredDevice
+}
+
+// NewDeviceRegistry returns a new DeviceRegistry
+func NewDeviceRegistry(ttl time.Duration) *DeviceRegistry {
+	return &DeviceRegistry{
+		ttl:       ttl,
+		devices:   make(map[string]*registeredDevice),
+		byAddress: make(map[deviceAddress]*registeredDevice),
+	}
+}
+
+// Update registers the device, or replaces it if it is already known, and
+// evicts the devices that expired.
+func (r *DeviceRegistry) Update(device MonitoredDevice) {
+	registered := &registeredDevice{
+		MonitoredDevice:   device,
+		interfacesByIndex: make(map[uint32]InterfaceMetadata, len(device.Interfaces)),
+	}
+	for _, itf := range device.Interfaces {
+		registered.interfacesByIndex[uint32(itf.Index)] = itf
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if previous, ok := r.devices[device.ID]; ok {
+		registered.linkIDs = previous.linkIDs
+		delete(r.byAddress, previous.address())
+	}
+	r.devices[device.ID] = registered
+	r.byAddress[registered.address()] = registered
+	for id, other := range r.devices {
+		if r.expired(other.MonitoredDevice, device.LastUpdate) {
+			delete(r.devices, id)
+			delete(r.byAddress, other.address())
+		}
+	}
+}
+
+// Devices returns the devices that haven't expired.
+func (r *DeviceRegistry) Devices(now time.Time) []MonitoredDevice {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	devices := make([]MonitoredDevice, 0, len(r.devices))
+	for _, device := range r.devices {
+		if !r.expired(device.MonitoredDevice, now) {
+			devices = append(devices, device.MonitoredDevice)
+		}
+	}
+	return devices
+}
+
+// ReportTopologyLinks records the topology links seen by the device and returns
+// those it should report, i.e. the links that aren't already reported by
+// another device. A link seen by both of its ends has the same ID on both
+// sides, and is only reported by the first device reporting it, until that
+// device no longer sees it or expires. Links are returned unchanged if the
+// device isn't registered.
+func (r *DeviceRegistry) ReportTopologyLinks(deviceID string, links []TopologyLinkMetadata) []TopologyLinkMetadata {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	device, ok := r.devices[deviceID]
+	if !ok {
+		return links
+	}
+	var reported []TopologyLinkMetadata
+	device.linkIDs = make(map[string]struct{}, len(links))
+	for _, link := range links {
+		if r.reportedByOther(deviceID, link.ID) {
+			continue
+		}
+		device.linkIDs[link.ID] = struct{}{}
+		reported = append(reported, link)
+	}
+	return reported
+}
+
+// reportedByOther returns true if a device other than the given one reports the link.
+// Callers of reportedByOther must hold a lock on r.mu.
+func (r *DeviceRegistry) reportedByOther(deviceID string, linkID string) bool {
+	for id, other := range r.devices {
+		if _, ok := other.linkIDs[linkID]; ok && id != deviceID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetInterface returns the interface of the device monitored at the given
+// namespace and IP address, by index.
+func (r *DeviceRegistry) GetInterface(namespace string, ipAddress string, index uint32, now time.Time) (InterfaceMetadata, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	device, ok := r.byAddress[deviceAddress{namespace: namespace, ipAddress: ipAddress}]
+	if !ok || r.expired(device.MonitoredDevice, now) {
+		return InterfaceMetadata{}, false
+	}
+	itf, ok := device.interfacesByIndex[index]
+	return itf, ok
+}
+
+func (d *registeredDevice) address() deviceAddress {
+	return deviceAddress{namespace: d.Namespace, ipAddress: d.IPAddress}
+}
+
+func (r *DeviceRegistry) expired(device MonitoredDevice, now time.Time) bool {
+	return now.Sub(device.LastUpdate) > r.ttl
+}
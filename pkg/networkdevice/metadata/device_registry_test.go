@@ -0,0 +1,100 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package metadata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceRegistry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	registry := NewDeviceRegistry(time.Hour)
+
+	registry.Update(MonitoredDevice{ID: "default:10.0.0.1", Name: "switch-a", LastUpdate: now})
+	registry.Update(MonitoredDevice{ID: "default:10.0.0.2", Name: "switch-b", LastUpdate: now.Add(30 * time.Minute)})
+	assert.ElementsMatch(t, []string{"switch-a", "switch-b"}, deviceNames(registry.Devices(now.Add(30*time.Minute))))
+
+	// expired devices are no longer returned
+	assert.Equal(t, []string{"switch-b"}, deviceNames(registry.Devices(now.Add(90*time.Minute))))
+
+	// updates replace the device, and evict the expired ones
+	registry.Update(MonitoredDevice{ID: "default:10.0.0.2", Name: "switch-b2", LastUpdate: now.Add(2 * time.Hour)})
+	assert.Len(t, registry.devices, 1)
+	assert.Equal(t, []string{"switch-b2"}, deviceNames(registry.Devices(now.Add(2*time.Hour))))
+}
+
+func TestDeviceRegistry_GetInterface(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	registry := NewDeviceRegistry(time.Hour)
+	registry.Update(MonitoredDevice{ID: "default:10.0.0.1", Namespace: "default", IPAddress: "10.0.0.1", Interfaces: []InterfaceMetadata{
+		{Index: 1, Name: "eth0"},
+		{Index: 2, Name: "eth1"},
+	}, LastUpdate: now})
+
+	itf, ok := registry.GetInterface("default", "10.0.0.1", 2, now)
+	assert.True(t, ok)
+	assert.Equal(t, "eth1", itf.Name)
+
+	_, ok = registry.GetInterface("other", "10.0.0.1", 2, now)
+	assert.False(t, ok)
+	_, ok = registry.GetInterface("default", "10.0.0.1", 3, now)
+	assert.False(t, ok)
+	_, ok = registry.GetInterface("default", "10.0.0.1", 2, now.Add(2*time.Hour))
+	assert.False(t, ok)
+
+	registry.Update(MonitoredDevice{ID: "default:10.0.0.1", Namespace: "default", IPAddress: "10.0.0.1", Interfaces: []InterfaceMetadata{
+		{Index: 1, Name: "eth0"},
+	}, LastUpdate: now})
+	_, ok = registry.GetInterface("default", "10.0.0.1", 2, now)
+	assert.False(t, ok)
+
+	// expired devices are evicted
+	registry.Update(MonitoredDevice{ID: "default:10.0.0.2", Namespace: "default", IPAddress: "10.0.0.2", LastUpdate: now.Add(2 * time.Hour)})
+	assert.Len(t, registry.byAddress, 1)
+}
+
+func deviceNames(devices []MonitoredDevice) []string {
+	var names []string
+	for _, device := range devices {
+		names = append(names, device.Name)
+	}
+	return names
+}
+
+func TestDeviceRegistry_ReportTopologyLinks(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	registry := NewDeviceRegistry(time.Hour)
+	registry.Update(MonitoredDevice{ID: "default:10.0.0.1", LastUpdate: now})
+	registry.Update(MonitoredDevice{ID: "default:10.0.0.2", LastUpdate: now})
+
+	shared := TopologyLinkMetadata{ID: TopologyLinkID("default:10.0.0.1:1", "default:10.0.0.2:7")}
+	linkA := TopologyLinkMetadata{ID: "default:10.0.0.1:2.4"}
+	linkB := TopologyLinkMetadata{ID: "default:10.0.0.2:8.1"}
+
+	// the link seen by both devices is only reported by the first one
+	assert.Equal(t, []TopologyLinkMetadata{shared, linkA}, registry.ReportTopologyLinks("default:10.0.0.1", []TopologyLinkMetadata{shared, linkA}))
+	assert.Equal(t, []TopologyLinkMetadata{linkB}, registry.ReportTopologyLinks("default:10.0.0.2", []TopologyLinkMetadata{shared, linkB}))
+
+	// it keeps being reported by the same device, even after updates
+	registry.Update(MonitoredDevice{ID: "default:10.0.0.1", LastUpdate: now.Add(time.Minute)})
+	assert.Equal(t, []TopologyLinkMetadata{shared, linkA}, registry.ReportTopologyLinks("default:10.0.0.1", []TopologyLinkMetadata{shared, linkA}))
+	assert.Equal(t, []TopologyLinkMetadata{linkB}, registry.ReportTopologyLinks("default:10.0.0.2", []TopologyLinkMetadata{shared, linkB}))
+
+	// the other device takes over once the first one no longer sees the link
+	assert.Equal(t, []TopologyLinkMetadata{linkA}, registry.ReportTopologyLinks("default:10.0.0.1", []TopologyLinkMetadata{linkA}))
+	assert.Equal(t, []TopologyLinkMetadata{shared, linkB}, registry.ReportTopologyLinks("default:10.0.0.2", []TopologyLinkMetadata{shared, linkB}))
+
+	// or once it expired
+	registry.Update(MonitoredDevice{ID: "default:10.0.0.3", LastUpdate: now.Add(2 * time.Hour)})
+	registry.Update(MonitoredDevice{ID: "default:10.0.0.1", LastUpdate: now.Add(2 * time.Hour)})
+	assert.Equal(t, []TopologyLinkMetadata{shared}, registry.ReportTopologyLinks("default:10.0.0.1", []TopologyLinkMetadata{shared}))
+
+	// links of unknown devices are returned unchanged
+	assert.Equal(t, []TopologyLinkMetadata{shared}, registry.ReportTopologyLinks("default:10.0.0.4", []TopologyLinkMetadata{shared}))
+}
@@ -101,3 +101,26 @@ func DeviceOIDFromPDU(deviceID string, snmpPDU *gosnmp.SnmpPDU) (*DeviceOID, err
 		Value:    pdu.Value,
 	}, nil
 }
+
+// TopologyLinkID returns the ID of the link between two interfaces, given their
+// Datadog IDs. The ID is the same whichever end of the link reports it.
+func TopologyLinkID(interfaceDDID string, otherInterfaceDDID string) string {
+	if otherInterfaceDDID < interfaceDDID {
+		interfaceDDID, otherInterfaceDDID = otherInterfaceDDID, interfaceDDID
+	}
+	return interfaceDDID + "|" + otherInterfaceDDID
+}
+
+// DeduplicateTopologyLinks removes the links having the same ID as a previous link.
+func DeduplicateTopologyLinks(links []TopologyLinkMetadata) []TopologyLinkMetadata {
+	seen := make(map[string]struct{}, len(links))
+	var deduplicated []TopologyLinkMetadata
+	for _, link := range links {
+		if _, ok := seen[link.ID]; ok {
+			continue
+		}
+		seen[link.ID] = struct{}{}
+		deduplicated = append(deduplicated, link)
+	}
+	return deduplicated
+}
@@ -126,3 +126,21 @@ func Test_batchPayloads(t *testing.T) {
 	assert.Len(t, payloads[7].Diagnoses, 51)
 	assert.Equal(t, diagnoses[49:100], payloads[7].Diagnoses)
 }
+
+func TestTopologyLinkID(t *testing.T) {
+	assert.Equal(t, "default:10.0.0.1:3|default:10.0.0.2:7", TopologyLinkID("default:10.0.0.1:3", "default:10.0.0.2:7"))
+	assert.Equal(t, "default:10.0.0.1:3|default:10.0.0.2:7", TopologyLinkID("default:10.0.0.2:7", "default:10.0.0.1:3"))
+}
+
+func TestDeduplicateTopologyLinks(t *testing.T) {
+	links := []TopologyLinkMetadata{
+		{ID: "a|b", SourceType: "lldp"},
+		{ID: "default:10.0.0.1:1.5", SourceType: "cdp"},
+		{ID: "a|b", SourceType: "cdp"},
+	}
+	assert.Equal(t, []TopologyLinkMetadata{
+		{ID: "a|b", SourceType: "lldp"},
+		{ID: "default:10.0.0.1:1.5", SourceType: "cdp"},
+	}, DeduplicateTopologyLinks(links))
+	assert.Nil(t, DeduplicateTopologyLinks(nil))
+}
@@ -151,6 +151,16 @@ type MetricsConfigOption struct {
 	MetricSuffix string `yaml:"metric_suffix,omitempty" json:"metric_suffix,omitempty"`
 }
 
+// MetricContextsConfig holds the config used to poll a table across several
+// SNMP contexts (SNMPv3 context names, or `community@context` for SNMPv1/v2c)
+type MetricContextsConfig struct {
+	// Symbol is the column listing the names of the contexts to poll the table from,
+	// its `extract_value` can be used to filter the contexts and extract their names
+	Symbol SymbolConfig `yaml:"symbol" json:"symbol"`
+	// Tag is the name of the tag holding the context name, `snmp_context` by default
+	Tag string `yaml:"tag,omitempty" json:"tag,omitempty"`
+}
+
 // MetricsConfig holds configs for a metric
 type MetricsConfig struct {
 	// MIB the MIB used for this metric
@@ -174,6 +184,9 @@ type MetricsConfig struct {
 	StaticTags []string            `yaml:"static_tags,omitempty" json:"-"`
 	MetricTags MetricTagConfigList `yaml:"metric_tags,omitempty" json:"metric_tags,omitempty"`
 
+	// `contexts` is not exposed as json at the moment since it's only supported by the core check
+	Contexts *MetricContextsConfig `yaml:"contexts,omitempty" json:"-"`
+
 	// DEPRECATED: use Symbol.MetricType instead.
 	ForcedType ProfileMetricType `yaml:"forced_type,omitempty" json:"forced_type,omitempty" jsonschema:"-"`
 	// DEPRECATED: use Symbol.MetricType instead.
@@ -184,6 +197,13 @@ type MetricsConfig struct {
 
 // Clone duplicates this MetricsConfig
 func (m MetricsConfig) Clone() MetricsConfig {
+	var contexts *MetricContextsConfig
+	if m.Contexts != nil {
+		contexts = &MetricContextsConfig{
+			Symbol: m.Contexts.Symbol.Clone(),
+			Tag:    m.Contexts.Tag,
+		}
+	}
 	return MetricsConfig{
 		MIB:        m.MIB,
 		Table:      m.Table.Clone(),
@@ -193,6 +213,7 @@ func (m MetricsConfig) Clone() MetricsConfig {
 		Symbols:    CloneSlice(m.Symbols),
 		StaticTags: slices.Clone(m.StaticTags),
 		MetricTags: CloneSlice(m.MetricTags),
+		Contexts:   contexts,
 		ForcedType: m.ForcedType,
 		MetricType: m.MetricType,
 		Options:    m.Options,
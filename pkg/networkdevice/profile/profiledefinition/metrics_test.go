@@ -148,6 +148,13 @@ func TestCloneMetricsConfig(t *testing.T) {
 					IndexTransform: make([]MetricIndexTransform, 0),
 				},
 			},
+			Contexts: &MetricContextsConfig{
+				Symbol: SymbolConfig{
+					OID:  "1.2.3.5",
+					Name: "vrfName",
+				},
+				Tag: "vrf",
+			},
 			ForcedType: ProfileMetricTypeCounter,
 			MetricType: ProfileMetricTypeGauge,
 			Options: MetricsConfigOption{
@@ -165,6 +172,7 @@ func TestCloneMetricsConfig(t *testing.T) {
 	conf2.MetricTags[0].IndexTransform = []MetricIndexTransform{{5, 7}}
 	conf2.Options.Placement = 2
 	conf2.Options.MetricSuffix = ".bar"
+	conf2.Contexts.Tag = "context"
 	assert.Equal(t, unchanged, conf)
 	assert.NotEqual(t, conf, conf2)
 }
@@ -13,7 +13,13 @@ func splitOIDs(metrics []MetricsConfig, globalTags []MetricTagConfig, metadata M
 	columns := make(map[string]bool)
 	// Singular metric values are scalars; metrics with .Symbols are tables,
 	// and their symbols and tags are both expected to be columns.
+	// Tables polled across contexts are fetched separately in each context,
+	// only the column listing the contexts is fetched in the default one.
 	for _, metric := range metrics {
+		if metric.Contexts != nil {
+			columns[metric.Contexts.Symbol.OID] = true
+			continue
+		}
 		scalars[metric.Symbol.OID] = true
 		for _, symbolConfig := range metric.Symbols {
 			columns[symbolConfig.OID] = true
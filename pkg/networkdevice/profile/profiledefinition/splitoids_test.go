@@ -135,6 +135,20 @@ func TestSplitOIDs(t *testing.T) {
 				}},
 			expectedScalars: []string{"1.1", "1.2"},
 			expectedColumns: []string{"2.1", "2.2", "2.3", "2.4"},
+		}, {
+			name: "tabular metric polled across contexts",
+			metrics: []MetricsConfig{
+				{
+					Symbols: []SymbolConfig{{OID: "1.2.3.4"}},
+					MetricTags: []MetricTagConfig{
+						{Symbol: SymbolConfigCompat{OID: "2.3.4.5"}},
+					},
+					Contexts: &MetricContextsConfig{
+						Symbol: SymbolConfig{OID: "3.4.5.6"},
+					},
+				},
+			},
+			expectedColumns: []string{"3.4.5.6"},
 		},
 	}
 	for _, tc := range testCases {
@@ -47,6 +47,9 @@ const (
 	MetadataSymbol
 )
 
+// DefaultContextTag is the tag holding the context name of metrics polled across contexts
+const DefaultContextTag = "snmp_context"
+
 // IsLegacyMetrics returns true if one or more metrics config is written in the legacy Python syntax
 func IsLegacyMetrics(metrics []MetricsConfig) bool {
 	for i := range metrics {
@@ -124,6 +127,9 @@ func ValidateEnrichMetrics(metrics []MetricsConfig) []string {
 				errors = append(errors, validateEnrichMetricTag(metricTag)...)
 			}
 		}
+		if metricConfig.Contexts != nil {
+			errors = append(errors, validateEnrichContexts(metricConfig)...)
+		}
 		// Setting forced_type value to metric_type value for backward compatibility
 		if metricConfig.MetricType == "" && metricConfig.ForcedType != "" {
 			metricConfig.MetricType = metricConfig.ForcedType
@@ -170,6 +176,23 @@ func ValidateEnrichMetadata(metadata MetadataConfig) []string {
 	return errors
 }
 
+func validateEnrichContexts(metricConfig *MetricsConfig) []string {
+	var errors []string
+	if !metricConfig.IsColumn() {
+		errors = append(errors, fmt.Sprintf("`contexts` can only be used with table metrics: %#v", metricConfig))
+	}
+	contexts := metricConfig.Contexts
+	if contexts.Symbol.Name == "" {
+		// the symbol name is only used for logging
+		contexts.Symbol.Name = contexts.Symbol.OID
+	}
+	errors = append(errors, validateEnrichSymbol(&contexts.Symbol, MetricTagSymbol)...)
+	if contexts.Tag == "" {
+		contexts.Tag = DefaultContextTag
+	}
+	return errors
+}
+
 func validateEnrichSymbol(symbol *SymbolConfig, symbolContext SymbolContext) []string {
 	var errors []string
 	if symbol.Name == "" {
@@ -641,6 +641,100 @@ func Test_ValidateEnrichMetrics(t *testing.T) {
 			},
 			expectedErrors: []string{"`tag` must be provided if `mapping` (`map[1:abc 2:def]`) is defined"},
 		},
+		{
+			name: "contexts tag and symbol name are defaulted",
+			metrics: []MetricsConfig{
+				{
+					Symbols: []SymbolConfig{
+						{
+							OID:  "1.2",
+							Name: "abc",
+						},
+					},
+					MetricTags: MetricTagConfigList{
+						MetricTagConfig{
+							Index: 1,
+							Tag:   "index",
+						},
+					},
+					Contexts: &MetricContextsConfig{
+						Symbol: SymbolConfig{
+							OID:          "1.3",
+							ExtractValue: "^vlan-(\\d+)$",
+						},
+					},
+				},
+			},
+			expectedMetrics: []MetricsConfig{
+				{
+					Symbols: []SymbolConfig{
+						{
+							OID:  "1.2",
+							Name: "abc",
+						},
+					},
+					MetricTags: MetricTagConfigList{
+						MetricTagConfig{
+							Index: 1,
+							Tag:   "index",
+						},
+					},
+					Contexts: &MetricContextsConfig{
+						Symbol: SymbolConfig{
+							OID:                  "1.3",
+							Name:                 "1.3",
+							ExtractValue:         "^vlan-(\\d+)$",
+							ExtractValueCompiled: regexp.MustCompile("^vlan-(\\d+)$"),
+						},
+						Tag: DefaultContextTag,
+					},
+				},
+			},
+		},
+		{
+			name: "contexts used with scalar metric",
+			metrics: []MetricsConfig{
+				{
+					Symbol: SymbolConfig{
+						OID:  "1.2",
+						Name: "abc",
+					},
+					Contexts: &MetricContextsConfig{
+						Symbol: SymbolConfig{
+							OID:  "1.3",
+							Name: "vrfName",
+						},
+						Tag: "vrf",
+					},
+				},
+			},
+			expectedErrors: []string{"`contexts` can only be used with table metrics"},
+		},
+		{
+			name: "contexts symbol without oid",
+			metrics: []MetricsConfig{
+				{
+					Symbols: []SymbolConfig{
+						{
+							OID:  "1.2",
+							Name: "abc",
+						},
+					},
+					MetricTags: MetricTagConfigList{
+						MetricTagConfig{
+							Index: 1,
+							Tag:   "index",
+						},
+					},
+					Contexts: &MetricContextsConfig{
+						Symbol: SymbolConfig{
+							Name: "vrfName",
+						},
+					},
+				},
+			},
+			expectedErrors: []string{"symbol oid missing: name=`vrfName` oid=``"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
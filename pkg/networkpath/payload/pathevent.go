@@ -22,6 +22,8 @@ const (
 	ProtocolTCP Protocol = "TCP"
 	// ProtocolUDP is the UDP protocol.
 	ProtocolUDP Protocol = "UDP"
+	// ProtocolICMP is the ICMP protocol.
+	ProtocolICMP Protocol = "ICMP"
 )
 
 // TCPMethod is the method used to run a TCP traceroute.
@@ -66,6 +68,29 @@ type NetworkPathHop struct {
 
 	RTT       float64 `json:"rtt,omitempty"`
 	Reachable bool    `json:"reachable"`
+
+	// Stats is only set by multipath traceroutes, which send several probes per hop
+	Stats *NetworkPathHopStats `json:"stats,omitempty"`
+}
+
+// NetworkPathHopStats encapsulates the loss and RTT
+// distribution of the probes sent to a hop
+type NetworkPathHopStats struct {
+	ProbesSent           int     `json:"probes_sent"`
+	ProbesReceived       int     `json:"probes_received"`
+	PacketLossPercentage float64 `json:"packet_loss_percentage"`
+	RTTMin               float64 `json:"rtt_min,omitempty"`
+	RTTAvg               float64 `json:"rtt_avg,omitempty"`
+	RTTMax               float64 `json:"rtt_max,omitempty"`
+	RTTStdDev            float64 `json:"rtt_stddev,omitempty"`
+}
+
+// NetworkPathTrace encapsulates one of the distinct
+// paths discovered by a multipath traceroute
+type NetworkPathTrace struct {
+	// FlowIDs are the flows whose probes followed this path
+	FlowIDs []int            `json:"flow_ids"`
+	Hops    []NetworkPathHop `json:"hops"`
 }
 
 // NetworkPathSource encapsulates information
@@ -100,5 +125,6 @@ type NetworkPath struct {
 	Source       NetworkPathSource      `json:"source"`
 	Destination  NetworkPathDestination `json:"destination"`
 	Hops         []NetworkPathHop       `json:"hops"`
+	Paths        []NetworkPathTrace     `json:"paths,omitempty"` // only set by multipath traceroutes, Hops is the first path
 	Tags         []string               `json:"tags,omitempty"`
 }
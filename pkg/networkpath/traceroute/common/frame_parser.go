@@ -21,6 +21,7 @@ type FrameParser struct {
 	Ethernet layers.Ethernet
 	IP4      layers.IPv4
 	TCP      layers.TCP
+	UDP      layers.UDP
 	ICMP4    layers.ICMPv4
 	Payload  gopacket.Payload
 	Layers   []gopacket.LayerType
@@ -36,7 +37,7 @@ const expectedLayerCount = 3
 // NewFrameParser constructs a new FrameParser
 func NewFrameParser() *FrameParser {
 	p := &FrameParser{}
-	p.parser = gopacket.NewDecodingLayerParser(layers.LayerTypeEthernet, &p.Ethernet, &p.IP4, &p.TCP, &p.ICMP4, &p.Payload)
+	p.parser = gopacket.NewDecodingLayerParser(layers.LayerTypeEthernet, &p.Ethernet, &p.IP4, &p.TCP, &p.UDP, &p.ICMP4, &p.Payload)
 
 	return p
 }
@@ -158,6 +159,8 @@ type ICMPInfo struct {
 	ICMPType layers.ICMPv4TypeCode
 	// ICMPPair is the source/dest IPs from the wrapped IP payload
 	ICMPPair IPPair
+	// WrappedIPID is the IP ID of the wrapped IP packet
+	WrappedIPID uint16
 	// Payload is the payload from within the wrapped IP packet, typically containing the first 8 bytes of TCP/UDP.
 	Payload []byte
 }
@@ -177,10 +180,11 @@ func (p *FrameParser) GetICMPInfo() (ICMPInfo, error) {
 		}
 
 		icmpInfo := ICMPInfo{
-			IPPair:   ipPair,
-			ICMPType: p.ICMP4.TypeCode,
-			ICMPPair: getIPv4Pair(&innerPkt),
-			Payload:  slices.Clone(innerPkt.Payload),
+			IPPair:      ipPair,
+			ICMPType:    p.ICMP4.TypeCode,
+			ICMPPair:    getIPv4Pair(&innerPkt),
+			WrappedIPID: innerPkt.Id,
+			Payload:     slices.Clone(innerPkt.Payload),
 		}
 		return icmpInfo, nil
 	default:
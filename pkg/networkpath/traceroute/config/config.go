@@ -9,6 +9,7 @@ package config
 import (
 	"time"
 
+	pkgconfigsetup "github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
 )
 
@@ -35,4 +36,26 @@ type Config struct {
 	Protocol payload.Protocol
 	// TCPMethod is the method used to run a TCP traceroute.
 	TCPMethod payload.TCPMethod
+	// NumPaths is the number of flows used to discover
+	// ECMP paths, a multipath traceroute is run when > 1
+	NumPaths uint16
+	// ProbesPerHop is the number of probes sent per hop and
+	// per flow to measure loss, a multipath traceroute is run when > 1
+	ProbesPerHop uint8
+}
+
+// ResolvedMaxTTL returns the max number of hops, or its default if not set
+func (c Config) ResolvedMaxTTL() uint8 {
+	if c.MaxTTL == 0 {
+		return pkgconfigsetup.DefaultNetworkPathMaxTTL
+	}
+	return c.MaxTTL
+}
+
+// ResolvedTimeout returns the timeout of the traceroute, or its default if not set
+func (c Config) ResolvedTimeout() time.Duration {
+	if c.Timeout == 0 {
+		return pkgconfigsetup.DefaultNetworkPathTimeout * time.Duration(c.ResolvedMaxTTL()) * time.Millisecond
+	}
+	return c.Timeout
 }
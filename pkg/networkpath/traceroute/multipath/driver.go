@@ -0,0 +1,307 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package multipath
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// packetSink sends raw IPv4 packets, it is implemented by ipv4.RawConn
+type packetSink interface {
+	WriteTo(header *ipv4.Header, payload []byte, cm *ipv4.ControlMessage) error
+	Close() error
+}
+
+// probe is a probe sent by the multipath driver
+type probe struct {
+	flowID   uint16
+	ttl      uint8
+	sendTime time.Time
+
+	// set when a response is received
+	received bool
+	ip       netip.Addr
+	rtt      time.Duration
+	isDest   bool
+}
+
+var portUnreachable4 = layers.CreateICMPv4TypeCode(layers.ICMPv4TypeDestinationUnreachable, layers.ICMPv4CodePort)
+
+var errPacketDidNotMatchTraceroute = &common.ReceiveProbeNoPktError{Err: fmt.Errorf("packet did not match the traceroute")}
+
+type multipathDriver struct {
+	sink   packetSink
+	source common.PacketSource
+	buffer []byte
+	parser *common.FrameParser
+
+	params Params
+	gen    packetGen
+
+	mu sync.Mutex
+	// probes are indexed by their probe ID minus one
+	probes []probe
+	// destTTLs is the lowest TTL at which each flow reached the target
+	destTTLs map[uint16]uint8
+	// pending is the number of probes sent and not answered yet
+	pending int
+}
+
+func newMultipathDriver(params Params, localAddr netip.Addr, icmpID uint16, sink packetSink, source common.PacketSource) *multipathDriver {
+	return &multipathDriver{
+		sink:   sink,
+		source: source,
+		buffer: make([]byte, 1024),
+		parser: common.NewFrameParser(),
+		params: params,
+		gen: packetGen{
+			srcAddr:  localAddr,
+			target:   params.Target,
+			protocol: params.Protocol,
+			srcPort:  params.SrcPort,
+			icmpID:   icmpID,
+		},
+		probes:   make([]probe, 0, params.ProbeCount()),
+		destTTLs: make(map[uint16]uint8),
+	}
+}
+
+func (d *multipathDriver) Close() {
+	d.sink.Close()
+	d.source.Close()
+}
+
+// sendProbe sends a probe for the given flow and TTL. It returns false without
+// sending anything if the flow already reached the target with a lower TTL.
+func (d *multipathDriver) sendProbe(flowID uint16, ttl uint8) (bool, error) {
+	d.mu.Lock()
+	if destTTL, ok := d.destTTLs[flowID]; ok && ttl > destTTL {
+		d.mu.Unlock()
+		return false, nil
+	}
+	d.probes = append(d.probes, probe{flowID: flowID, ttl: ttl, sendTime: time.Now()})
+	probeID := uint16(len(d.probes))
+	d.pending++
+	d.mu.Unlock()
+
+	header, packet, err := d.gen.generateV4(probeID, flowID, ttl)
+	if err != nil {
+		return false, fmt.Errorf("multipathDriver failed to generate packet: %w", err)
+	}
+	log.TraceFunc(func() string {
+		return fmt.Sprintf("sending packet: %+v %s\n", header, hex.EncodeToString(packet))
+	})
+	if err := d.sink.WriteTo(header, packet, nil); err != nil {
+		return false, fmt.Errorf("multipathDriver failed to WriteTo: %w", err)
+	}
+	return true, nil
+}
+
+// receiveProbe polls to get a probe response with a timeout
+func (d *multipathDriver) receiveProbe(timeout time.Duration) (*probe, error) {
+	err := d.source.SetReadDeadline(time.Now().Add(timeout))
+	if err != nil {
+		return nil, fmt.Errorf("multipathDriver failed to SetReadDeadline: %w", err)
+	}
+	err = common.ReadAndParse(d.source, d.buffer, d.parser)
+	if err != nil {
+		return nil, err
+	}
+	return d.handleProbeLayers(d.parser)
+}
+
+// isDone returns whether all the sent probes were answered
+func (d *multipathDriver) isDone() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pending == 0
+}
+
+func (d *multipathDriver) handleProbeLayers(parser *common.FrameParser) (*probe, error) {
+	ipPair, err := parser.GetIPPair()
+	if err != nil {
+		return nil, fmt.Errorf("multipathDriver failed to get IP pair: %w", err)
+	}
+	target := d.params.Target
+
+	switch parser.GetTransportLayer() {
+	case layers.LayerTypeICMPv4:
+		if parser.ICMP4.TypeCode.Type() == layers.ICMPv4TypeEchoReply {
+			if d.params.Protocol != payload.ProtocolICMP || ipPair.SrcAddr != target.Addr() {
+				return nil, errPacketDidNotMatchTraceroute
+			}
+			flowID := parser.ICMP4.Id - d.gen.icmpID
+			return d.recordResponse(parser.ICMP4.Seq, flowID, ipPair.SrcAddr, true)
+		}
+
+		icmpInfo, err := parser.GetICMPInfo()
+		if err != nil {
+			return nil, &common.BadPacketError{Err: fmt.Errorf("multipathDriver failed to get ICMP info: %w", err)}
+		}
+		if icmpInfo.ICMPPair.DstAddr != target.Addr() {
+			return nil, errPacketDidNotMatchTraceroute
+		}
+		isDest := false
+		switch icmpInfo.ICMPType {
+		case common.TTLExceeded4:
+		case portUnreachable4:
+			// UDP probes reaching the target trigger a port unreachable
+			isDest = d.params.Protocol == payload.ProtocolUDP && icmpInfo.IPPair.SrcAddr == target.Addr()
+		default:
+			return nil, errPacketDidNotMatchTraceroute
+		}
+
+		// the wrapped packet contains at least the first 8 bytes of the probe
+		if len(icmpInfo.Payload) < 8 {
+			return nil, &common.BadPacketError{Err: fmt.Errorf("multipathDriver found truncated ICMP payload (%d bytes)", len(icmpInfo.Payload))}
+		}
+		switch d.params.Protocol {
+		case payload.ProtocolICMP:
+			if icmpInfo.Payload[0] != layers.ICMPv4TypeEchoRequest {
+				return nil, errPacketDidNotMatchTraceroute
+			}
+			flowID := binary.BigEndian.Uint16(icmpInfo.Payload[4:6]) - d.gen.icmpID
+			probeID := binary.BigEndian.Uint16(icmpInfo.Payload[6:8])
+			return d.recordResponse(probeID, flowID, icmpInfo.IPPair.SrcAddr, isDest)
+		default:
+			if binary.BigEndian.Uint16(icmpInfo.Payload[2:4]) != target.Port() {
+				return nil, errPacketDidNotMatchTraceroute
+			}
+			flowID := binary.BigEndian.Uint16(icmpInfo.Payload[0:2]) - d.gen.srcPort
+			return d.recordResponse(icmpInfo.WrappedIPID, flowID, icmpInfo.IPPair.SrcAddr, isDest)
+		}
+	case layers.LayerTypeUDP:
+		// DNS servers answer the queries carried by UDP probes
+		if d.params.Protocol != payload.ProtocolUDP || target.Port() != dnsPort ||
+			ipPair.SrcAddr != target.Addr() || uint16(parser.UDP.SrcPort) != dnsPort {
+			return nil, errPacketDidNotMatchTraceroute
+		}
+		if len(parser.UDP.Payload) < 2 {
+			return nil, &common.BadPacketError{Err: fmt.Errorf("multipathDriver found truncated DNS response")}
+		}
+		flowID := uint16(parser.UDP.DstPort) - d.gen.srcPort
+		probeID := binary.BigEndian.Uint16(parser.UDP.Payload[0:2])
+		return d.recordResponse(probeID, flowID, ipPair.SrcAddr, true)
+	default:
+		return nil, errPacketDidNotMatchTraceroute
+	}
+}
+
+// recordResponse records the response to a probe if it matches a sent probe
+func (d *multipathDriver) recordResponse(probeID uint16, flowID uint16, ip netip.Addr, isDest bool) (*probe, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if probeID == 0 || int(probeID) > len(d.probes) {
+		return nil, errPacketDidNotMatchTraceroute
+	}
+	p := &d.probes[probeID-1]
+	// packets can get delivered twice, only use the first received to avoid overestimating RTT
+	if p.flowID != flowID || p.received {
+		return nil, errPacketDidNotMatchTraceroute
+	}
+	p.received = true
+	p.ip = ip
+	p.rtt = time.Since(p.sendTime)
+	p.isDest = isDest
+	d.pending--
+
+	if isDest {
+		if destTTL, ok := d.destTTLs[flowID]; !ok || p.ttl < destTTL {
+			d.destTTLs[flowID] = p.ttl
+		}
+	}
+	result := *p
+	return &result, nil
+}
+
+// runMultipath sends the probes of every flow, TTL by TTL, while collecting
+// the responses. It returns once all probes were answered or after the timeout.
+func runMultipath(ctx context.Context, d *multipathDriver) ([]probe, error) {
+	p := d.params.ParallelParams
+	timeoutCtx, cancel := context.WithTimeout(ctx, d.params.MaxTimeout())
+	defer cancel()
+
+	g, groupCtx := errgroup.WithContext(timeoutCtx)
+	var sendDone sync.WaitGroup
+	sendDone.Add(1)
+
+	g.Go(func() error {
+		defer sendDone.Done()
+		for attempt := 0; attempt < int(d.params.ProbesPerHop); attempt++ {
+			for ttl := int(p.MinTTL); ttl <= int(p.MaxTTL); ttl++ {
+				for flowID := uint16(0); flowID < d.params.NumPaths; flowID++ {
+					select {
+					case <-groupCtx.Done():
+						return nil
+					default:
+					}
+
+					sent, err := d.sendProbe(flowID, uint8(ttl))
+					if err != nil {
+						return err
+					}
+					if sent {
+						time.Sleep(p.SendDelay)
+					}
+				}
+			}
+		}
+		return nil
+	})
+
+	sendFinished := make(chan struct{})
+	go func() {
+		sendDone.Wait()
+		close(sendFinished)
+	}()
+
+	g.Go(func() error {
+		for {
+			select {
+			case <-groupCtx.Done():
+				return nil
+			case <-sendFinished:
+				if d.isDone() {
+					return nil
+				}
+			default:
+			}
+
+			_, err := d.receiveProbe(p.PollFrequency)
+			if common.CheckParallelRetryable("receiveProbe", err) {
+				continue
+			} else if err != nil {
+				return err
+			}
+		}
+	})
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	// if we got externally cancelled, report that
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]probe(nil), d.probes...), nil
+}
@@ -0,0 +1,338 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package multipath
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/ipv4"
+
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/common"
+)
+
+var (
+	localAddr  = netip.MustParseAddr("10.0.0.1")
+	targetAddr = netip.MustParseAddr("10.9.9.9")
+	firstHop   = netip.MustParseAddr("10.1.0.1")
+	ecmpHops   = []netip.Addr{netip.MustParseAddr("10.2.0.1"), netip.MustParseAddr("10.2.0.2")}
+)
+
+// fakePacketSource is a common.PacketSource returning the frames written to its channel
+type fakePacketSource struct {
+	frames   chan []byte
+	deadline time.Time
+}
+
+func (s *fakePacketSource) SetReadDeadline(t time.Time) error {
+	s.deadline = t
+	return nil
+}
+
+func (s *fakePacketSource) Read(buf []byte) (int, error) {
+	select {
+	case frame := <-s.frames:
+		return copy(buf, frame), nil
+	case <-time.After(time.Until(s.deadline)):
+		return 0, os.ErrDeadlineExceeded
+	}
+}
+
+func (s *fakePacketSource) Close() error {
+	return nil
+}
+
+// fakeNetwork is a packetSink simulating the network between localAddr and
+// targetAddr: the first hop is followed by two ECMP branches, chosen by the
+// parity of the flow identifier, and the target is the third hop.
+type fakeNetwork struct {
+	t      *testing.T
+	source *fakePacketSource
+
+	mu sync.Mutex
+	// drop returns whether a probe sent with this TTL is lost
+	drop func(ttl uint8) bool
+	// sentFlows keeps the flow identifiers seen for each probe
+	sentFlows map[uint16]uint16
+	// checksums keeps the ICMP checksums of each flow
+	checksums map[uint16]map[uint16]struct{}
+}
+
+func newFakeNetwork(t *testing.T) *fakeNetwork {
+	return &fakeNetwork{
+		t:         t,
+		source:    &fakePacketSource{frames: make(chan []byte, 1000)},
+		sentFlows: make(map[uint16]uint16),
+		checksums: make(map[uint16]map[uint16]struct{}),
+	}
+}
+
+func (n *fakeNetwork) WriteTo(header *ipv4.Header, payload []byte, _ *ipv4.ControlMessage) error {
+	headerBytes, err := header.Marshal()
+	require.NoError(n.t, err)
+	packet := gopacket.NewPacket(append(headerBytes, payload...), layers.LayerTypeIPv4, gopacket.Default)
+	ip4 := packet.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+
+	var flow uint16
+	if icmp4, ok := packet.Layer(layers.LayerTypeICMPv4).(*layers.ICMPv4); ok {
+		flow = icmp4.Id
+		n.mu.Lock()
+		if n.checksums[flow] == nil {
+			n.checksums[flow] = make(map[uint16]struct{})
+		}
+		n.checksums[flow][icmp4.Checksum] = struct{}{}
+		n.mu.Unlock()
+	} else {
+		flow = uint16(packet.Layer(layers.LayerTypeUDP).(*layers.UDP).SrcPort)
+	}
+
+	n.mu.Lock()
+	n.sentFlows[ip4.Id] = flow
+	drop := n.drop != nil && n.drop(ip4.TTL)
+	n.mu.Unlock()
+	if drop {
+		return nil
+	}
+
+	switch {
+	case ip4.TTL == 1:
+		n.reply(firstHop, ttlExceeded(headerBytes, payload))
+	case ip4.TTL == 2:
+		n.reply(ecmpHops[flow%2], ttlExceeded(headerBytes, payload))
+	default:
+		n.replyFromTarget(packet, headerBytes, payload)
+	}
+	return nil
+}
+
+func (n *fakeNetwork) replyFromTarget(packet gopacket.Packet, headerBytes []byte, payload []byte) {
+	if icmp4, ok := packet.Layer(layers.LayerTypeICMPv4).(*layers.ICMPv4); ok {
+		n.reply(targetAddr,
+			&layers.ICMPv4{TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoReply, 0), Id: icmp4.Id, Seq: icmp4.Seq},
+			gopacket.Payload(icmp4.Payload),
+		)
+		return
+	}
+	udp := packet.Layer(layers.LayerTypeUDP).(*layers.UDP)
+	if dns, ok := packet.Layer(layers.LayerTypeDNS).(*layers.DNS); ok {
+		response := &layers.UDP{SrcPort: udp.DstPort, DstPort: udp.SrcPort}
+		n.reply(targetAddr, response, &layers.DNS{ID: dns.ID, QR: true, ResponseCode: layers.DNSResponseCodeRefused, Questions: dns.Questions})
+		return
+	}
+	n.reply(targetAddr,
+		&layers.ICMPv4{TypeCode: portUnreachable4},
+		gopacket.Payload(append(headerBytes, payload[:8]...)),
+	)
+}
+
+func ttlExceeded(headerBytes []byte, payload []byte) []gopacket.SerializableLayer {
+	return []gopacket.SerializableLayer{
+		&layers.ICMPv4{TypeCode: common.TTLExceeded4},
+		gopacket.Payload(append(append([]byte{}, headerBytes...), payload[:8]...)),
+	}
+}
+
+func (n *fakeNetwork) reply(src netip.Addr, replyLayers ...interface{}) {
+	var serializable []gopacket.SerializableLayer
+	for _, layer := range replyLayers {
+		switch layer := layer.(type) {
+		case []gopacket.SerializableLayer:
+			serializable = append(serializable, layer...)
+		case gopacket.SerializableLayer:
+			serializable = append(serializable, layer)
+		}
+	}
+	protocol := layers.IPProtocolICMPv4
+	if udp, ok := serializable[0].(*layers.UDP); ok {
+		protocol = layers.IPProtocolUDP
+		ip4 := &layers.IPv4{SrcIP: src.AsSlice(), DstIP: localAddr.AsSlice()}
+		require.NoError(n.t, udp.SetNetworkLayerForChecksum(ip4))
+	}
+	frame := append([]gopacket.SerializableLayer{
+		&layers.Ethernet{
+			SrcMAC:       net.HardwareAddr{0x01, 0x02, 0x03, 0x04, 0x05, 0x06},
+			DstMAC:       net.HardwareAddr{0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c},
+			EthernetType: layers.EthernetTypeIPv4,
+		},
+		&layers.IPv4{Version: 4, TTL: 64, Protocol: protocol, SrcIP: src.AsSlice(), DstIP: localAddr.AsSlice()},
+	}, serializable...)
+
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	require.NoError(n.t, gopacket.SerializeLayers(buf, opts, frame...))
+	n.source.frames <- buf.Bytes()
+}
+
+func (n *fakeNetwork) Close() error {
+	return nil
+}
+
+func testParams(protocol payload.Protocol, port uint16) Params {
+	return Params{
+		Target:       netip.AddrPortFrom(targetAddr, port),
+		Protocol:     protocol,
+		NumPaths:     4,
+		ProbesPerHop: 2,
+		SrcPort:      40000,
+		ParallelParams: common.TracerouteParallelParams{
+			MinTTL:            1,
+			MaxTTL:            10,
+			TracerouteTimeout: 500 * time.Millisecond,
+			PollFrequency:     time.Millisecond,
+			SendDelay:         0,
+		},
+	}
+}
+
+func runTestTraceroute(t *testing.T, network *fakeNetwork, params Params) []Path {
+	require.NoError(t, params.validate())
+	driver := newMultipathDriver(params, localAddr, 1000, network, network.source)
+	probes, err := runMultipath(context.Background(), driver)
+	require.NoError(t, err)
+	return buildPaths(probes, params)
+}
+
+func requirePathIPs(t *testing.T, path Path, ips ...netip.Addr) {
+	require.Len(t, path.Hops, len(ips))
+	for i, hop := range path.Hops {
+		assert.Equal(t, ips[i], hop.IP, "hop %d", i)
+		assert.Equal(t, uint8(i+1), hop.TTL)
+		assert.Equal(t, i == len(ips)-1, hop.IsDest, "hop %d", i)
+	}
+}
+
+func TestMultipathTraceroute(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol payload.Protocol
+		port     uint16
+	}{
+		{name: "icmp echo", protocol: payload.ProtocolICMP},
+		{name: "udp port unreachable", protocol: payload.ProtocolUDP, port: 33434},
+		{name: "udp dns", protocol: payload.ProtocolUDP, port: 53},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := newFakeNetwork(t)
+			paths := runTestTraceroute(t, network, testParams(tt.protocol, tt.port))
+
+			// flows 0 and 2 take the first ECMP branch, flows 1 and 3 the second one
+			require.Len(t, paths, 2)
+			assert.Equal(t, []uint16{0, 2}, paths[0].FlowIDs)
+			assert.Equal(t, []uint16{1, 3}, paths[1].FlowIDs)
+			requirePathIPs(t, paths[0], firstHop, ecmpHops[0], targetAddr)
+			requirePathIPs(t, paths[1], firstHop, ecmpHops[1], targetAddr)
+			for _, path := range paths {
+				for _, hop := range path.Hops {
+					// 2 flows with 2 probes each
+					assert.Equal(t, 4, hop.ProbesSent)
+					assert.Len(t, hop.RTTs, 4)
+				}
+			}
+		})
+	}
+}
+
+func TestMultipathTracerouteParisChecksum(t *testing.T) {
+	network := newFakeNetwork(t)
+	runTestTraceroute(t, network, testParams(payload.ProtocolICMP, 0))
+
+	// the ICMP checksum must be constant within a flow, and different between flows
+	require.Len(t, network.checksums, 4)
+	seen := make(map[uint16]struct{})
+	for flow, checksums := range network.checksums {
+		require.Len(t, checksums, 1, "flow %d", flow)
+		for checksum := range checksums {
+			seen[checksum] = struct{}{}
+		}
+	}
+	assert.Len(t, seen, 4)
+}
+
+func TestMultipathTracerouteLoss(t *testing.T) {
+	network := newFakeNetwork(t)
+	params := testParams(payload.ProtocolUDP, 33434)
+	params.NumPaths = 1
+	params.ProbesPerHop = 4
+	params.ParallelParams.TracerouteTimeout = 100 * time.Millisecond
+	// the first hop drops half of the probes
+	dropped := 0
+	network.drop = func(ttl uint8) bool {
+		if ttl == 1 && dropped < 2 {
+			dropped++
+			return true
+		}
+		return false
+	}
+
+	paths := runTestTraceroute(t, network, params)
+	require.Len(t, paths, 1)
+	requirePathIPs(t, paths[0], firstHop, ecmpHops[0], targetAddr)
+	assert.Equal(t, 4, paths[0].Hops[0].ProbesSent)
+	assert.Len(t, paths[0].Hops[0].RTTs, 2)
+	assert.Len(t, paths[0].Hops[1].RTTs, 4)
+}
+
+func TestMultipathTracerouteUnreachable(t *testing.T) {
+	network := newFakeNetwork(t)
+	params := testParams(payload.ProtocolICMP, 0)
+	params.NumPaths = 1
+	params.ProbesPerHop = 1
+	params.ParallelParams.MaxTTL = 4
+	params.ParallelParams.TracerouteTimeout = 100 * time.Millisecond
+	// nothing after the first hop answers
+	network.drop = func(ttl uint8) bool {
+		return ttl > 1
+	}
+
+	paths := runTestTraceroute(t, network, params)
+	require.Len(t, paths, 1)
+	require.Len(t, paths[0].Hops, 4)
+	assert.Equal(t, firstHop, paths[0].Hops[0].IP)
+	for _, hop := range paths[0].Hops[1:] {
+		assert.False(t, hop.IP.IsValid())
+		assert.Equal(t, 1, hop.ProbesSent)
+		assert.Empty(t, hop.RTTs)
+	}
+}
+
+func TestHandleProbeLayersIgnoresOtherTraffic(t *testing.T) {
+	network := newFakeNetwork(t)
+	params := testParams(payload.ProtocolICMP, 0)
+	driver := newMultipathDriver(params, localAddr, 1000, network, network.source)
+
+	sent, err := driver.sendProbe(0, 1)
+	require.NoError(t, err)
+	require.True(t, sent)
+	// the response to the probe is matched once, duplicates are ignored
+	frame := <-network.source.frames
+	require.NoError(t, driver.parser.Parse(frame))
+	response, err := driver.handleProbeLayers(driver.parser)
+	require.NoError(t, err)
+	assert.Equal(t, firstHop, response.ip)
+	assert.Equal(t, uint16(0), response.flowID)
+	assert.True(t, driver.isDone())
+
+	require.NoError(t, driver.parser.Parse(frame))
+	_, err = driver.handleProbeLayers(driver.parser)
+	assert.ErrorIs(t, err, errPacketDidNotMatchTraceroute)
+
+	// echo replies to unknown probes are ignored
+	network.reply(targetAddr, &layers.ICMPv4{TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoReply, 0), Id: 1000, Seq: 42})
+	require.NoError(t, driver.parser.Parse(<-network.source.frames))
+	_, err = driver.handleProbeLayers(driver.parser)
+	assert.ErrorIs(t, err, errPacketDidNotMatchTraceroute)
+}
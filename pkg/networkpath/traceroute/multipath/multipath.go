@@ -0,0 +1,167 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+// Package multipath has Paris/Dublin-style multipath tracerouting logic: probes
+// of a flow share the same flow identifier, so that ECMP routers forward them
+// along the same path, and each flow uses a different identifier to discover
+// the other ECMP branches.
+package multipath
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/common"
+)
+
+// dnsPort is the destination port for which UDP probes carry a DNS query
+const dnsPort = 53
+
+// NotSupportedError means multipath traceroutes are not supported on this platform
+type NotSupportedError struct {
+	Err error
+}
+
+func (e *NotSupportedError) Error() string {
+	return fmt.Sprintf("multipath traceroute not supported: %s", e.Err)
+}
+func (e *NotSupportedError) Unwrap() error {
+	return e.Err
+}
+
+// Params is the multipath traceroute parameters
+type Params struct {
+	// Target is the IP:port to traceroute, the port is ignored for ICMP
+	Target netip.AddrPort
+	// Protocol is the protocol of the probes, either ICMP (echo requests) or UDP.
+	// UDP probes sent to port 53 carry a DNS query, so that DNS servers answer them.
+	Protocol payload.Protocol
+	// NumPaths is the number of flows, each flow uses a different flow identifier
+	NumPaths uint16
+	// ProbesPerHop is the number of probes sent per flow and per TTL
+	ProbesPerHop uint8
+	// SrcPort is the source port of the first flow, the following flows use
+	// the next ports. Only used for UDP.
+	SrcPort uint16
+	// ParallelParams are the standard params for parallel traceroutes
+	ParallelParams common.TracerouteParallelParams
+}
+
+// NewParams returns the params of a multipath traceroute to the target, sending
+// at least one flow and one probe per hop, from TTL 1 to maxTTL.
+func NewParams(target netip.AddrPort, protocol payload.Protocol, numPaths uint16, probesPerHop uint8, srcPort uint16, maxTTL uint8, timeout time.Duration) Params {
+	if numPaths == 0 {
+		numPaths = 1
+	}
+	if probesPerHop == 0 {
+		probesPerHop = 1
+	}
+	return Params{
+		Target:       target,
+		Protocol:     protocol,
+		NumPaths:     numPaths,
+		ProbesPerHop: probesPerHop,
+		SrcPort:      srcPort,
+		ParallelParams: common.TracerouteParallelParams{
+			MinTTL:            1,
+			MaxTTL:            maxTTL,
+			TracerouteTimeout: timeout,
+			PollFrequency:     100 * time.Millisecond,
+			SendDelay:         10 * time.Millisecond,
+		},
+	}
+}
+
+// IsMultipath returns whether a traceroute is run as a multipath traceroute:
+// ICMP traceroutes always are, and UDP ones when they send several flows or
+// several probes per hop.
+func IsMultipath(protocol payload.Protocol, numPaths uint16, probesPerHop uint8) bool {
+	switch protocol {
+	case payload.ProtocolICMP:
+		return true
+	case payload.ProtocolUDP:
+		return numPaths > 1 || probesPerHop > 1
+	}
+	return false
+}
+
+// ProbeCount returns the maximum number of probes that will be sent
+func (p Params) ProbeCount() int {
+	return int(p.NumPaths) * int(p.ProbesPerHop) * p.ParallelParams.ProbeCount()
+}
+
+// MaxTimeout combines the timeout+probe delays into a total timeout for the traceroute
+func (p Params) MaxTimeout() time.Duration {
+	return p.ParallelParams.TracerouteTimeout + p.ParallelParams.SendDelay*time.Duration(p.ProbeCount())
+}
+
+func (p Params) validate() error {
+	addr := p.Target.Addr()
+	if !addr.IsValid() {
+		return fmt.Errorf("multipath traceroute provided invalid IP address")
+	}
+	if addr.Is6() {
+		return fmt.Errorf("multipath traceroute does not support IPv6")
+	}
+	switch p.Protocol {
+	case payload.ProtocolICMP:
+	case payload.ProtocolUDP:
+		if p.Target.Port() == 0 {
+			return fmt.Errorf("multipath UDP traceroute requires a destination port")
+		}
+		if int(p.SrcPort)+int(p.NumPaths)-1 > math.MaxUint16 {
+			return fmt.Errorf("multipath UDP traceroute source port %d is too high for %d paths", p.SrcPort, p.NumPaths)
+		}
+	default:
+		return fmt.Errorf("multipath traceroute does not support protocol %q", p.Protocol)
+	}
+	if p.NumPaths < 1 {
+		return errors.New("multipath traceroute requires at least 1 path")
+	}
+	if p.ProbesPerHop < 1 {
+		return errors.New("multipath traceroute requires at least 1 probe per hop")
+	}
+	if p.ParallelParams.MinTTL < 1 {
+		return errors.New("min TTL must be at least 1")
+	}
+	if p.ParallelParams.MinTTL > p.ParallelParams.MaxTTL {
+		return errors.New("min TTL must be less than or equal to max TTL")
+	}
+	// probes are identified by a 16 bits identifier, 0 is reserved
+	if p.ProbeCount() >= math.MaxUint16 {
+		return fmt.Errorf("multipath traceroute would send too many probes (%d)", p.ProbeCount())
+	}
+	return nil
+}
+
+// Results encapsulates the distinct paths found by a multipath traceroute
+type Results struct {
+	Source netip.Addr
+	Target netip.AddrPort
+	// Paths are the distinct paths, ordered by their lowest flow ID
+	Paths []Path
+}
+
+// Path is a distinct path followed by the probes of one or more flows
+type Path struct {
+	FlowIDs []uint16
+	Hops    []Hop
+}
+
+// Hop encapsulates the responses to the probes sent with a given TTL
+type Hop struct {
+	TTL uint8
+	// IP is the address which answered most of the probes, it is
+	// invalid if no probe was answered
+	IP         netip.Addr
+	IsDest     bool
+	ProbesSent int
+	// RTTs are the round-trip times of the answered probes
+	RTTs []time.Duration
+}
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package multipath
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+)
+
+func TestParamsValidate(t *testing.T) {
+	tests := []struct {
+		name          string
+		update        func(p *Params)
+		expectedError string
+	}{
+		{
+			name:   "valid",
+			update: func(_ *Params) {},
+		},
+		{
+			name: "ipv6",
+			update: func(p *Params) {
+				p.Target = netip.MustParseAddrPort("[2001:db8::1]:33434")
+			},
+			expectedError: "multipath traceroute does not support IPv6",
+		},
+		{
+			name: "tcp",
+			update: func(p *Params) {
+				p.Protocol = payload.ProtocolTCP
+			},
+			expectedError: `multipath traceroute does not support protocol "TCP"`,
+		},
+		{
+			name: "udp without port",
+			update: func(p *Params) {
+				p.Target = netip.AddrPortFrom(targetAddr, 0)
+			},
+			expectedError: "multipath UDP traceroute requires a destination port",
+		},
+		{
+			name: "icmp without port",
+			update: func(p *Params) {
+				p.Protocol = payload.ProtocolICMP
+				p.Target = netip.AddrPortFrom(targetAddr, 0)
+			},
+		},
+		{
+			name: "source port overflow",
+			update: func(p *Params) {
+				p.SrcPort = 65533
+			},
+			expectedError: "multipath UDP traceroute source port 65533 is too high for 4 paths",
+		},
+		{
+			name: "no paths",
+			update: func(p *Params) {
+				p.NumPaths = 0
+			},
+			expectedError: "multipath traceroute requires at least 1 path",
+		},
+		{
+			name: "too many probes",
+			update: func(p *Params) {
+				p.NumPaths = 2000
+				p.SrcPort = 1000
+				p.ProbesPerHop = 10
+			},
+			expectedError: "multipath traceroute would send too many probes (200000)",
+		},
+		{
+			name: "min TTL above max TTL",
+			update: func(p *Params) {
+				p.ParallelParams.MinTTL = 11
+			},
+			expectedError: "min TTL must be less than or equal to max TTL",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := testParams(payload.ProtocolUDP, 33434)
+			tt.update(&params)
+			err := params.validate()
+			if tt.expectedError == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.expectedError)
+			}
+		})
+	}
+}
+
+func TestIsMultipath(t *testing.T) {
+	assert.True(t, IsMultipath(payload.ProtocolICMP, 0, 0))
+	assert.True(t, IsMultipath(payload.ProtocolUDP, 2, 0))
+	assert.True(t, IsMultipath(payload.ProtocolUDP, 0, 2))
+	assert.False(t, IsMultipath(payload.ProtocolUDP, 1, 1))
+	assert.False(t, IsMultipath(payload.ProtocolTCP, 2, 2))
+}
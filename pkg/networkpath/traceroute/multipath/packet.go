@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package multipath
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/ipv4"
+
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+)
+
+const ipv4HeaderLength = 20
+
+// packetGen generates the probes of a multipath traceroute. Each probe is
+// identified by the IP ID of its packet (and the ICMP sequence number for
+// echo requests), and each flow by the source port for UDP or the ICMP ID
+// for echo requests.
+type packetGen struct {
+	srcAddr  netip.Addr
+	target   netip.AddrPort
+	protocol payload.Protocol
+	srcPort  uint16
+	icmpID   uint16
+}
+
+// flowSrcPort returns the UDP source port of a flow
+func (g *packetGen) flowSrcPort(flowID uint16) uint16 {
+	return g.srcPort + flowID
+}
+
+// flowICMPID returns the ICMP echo ID of a flow
+func (g *packetGen) flowICMPID(flowID uint16) uint16 {
+	return g.icmpID + flowID
+}
+
+// parisPayload returns the echo request payload which keeps the ICMP checksum
+// constant for all the probes of a flow, whatever their sequence number.
+// ECMP routers hashing the first bytes of the ICMP header forward them
+// along the same path.
+func parisPayload(seq uint16) []byte {
+	// the one's complement sum of seq and ^seq is 0xffff, which is
+	// a zero in one's complement arithmetic
+	return binary.BigEndian.AppendUint16(nil, ^seq)
+}
+
+// dnsQuery returns a query for the NS records of the root zone, which DNS
+// servers answer whether they are authoritative or recursive.
+func dnsQuery(probeID uint16) *layers.DNS {
+	return &layers.DNS{
+		ID:     probeID,
+		OpCode: layers.DNSOpCodeQuery,
+		RD:     true,
+		Questions: []layers.DNSQuestion{{
+			Name:  []byte{},
+			Type:  layers.DNSTypeNS,
+			Class: layers.DNSClassIN,
+		}},
+	}
+}
+
+func (g *packetGen) generateLayers(probeID uint16, flowID uint16, ttl uint8) ([]gopacket.SerializableLayer, error) {
+	ipLayer := &layers.IPv4{
+		Version: 4,
+		TTL:     ttl,
+		Id:      probeID,
+		SrcIP:   g.srcAddr.AsSlice(),
+		DstIP:   g.target.Addr().AsSlice(),
+	}
+	switch g.protocol {
+	case payload.ProtocolICMP:
+		ipLayer.Protocol = layers.IPProtocolICMPv4
+		icmpLayer := &layers.ICMPv4{
+			TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoRequest, 0),
+			Id:       g.flowICMPID(flowID),
+			Seq:      probeID,
+		}
+		return []gopacket.SerializableLayer{ipLayer, icmpLayer, gopacket.Payload(parisPayload(probeID))}, nil
+	case payload.ProtocolUDP:
+		ipLayer.Protocol = layers.IPProtocolUDP
+		udpLayer := &layers.UDP{
+			SrcPort: layers.UDPPort(g.flowSrcPort(flowID)),
+			DstPort: layers.UDPPort(g.target.Port()),
+		}
+		if err := udpLayer.SetNetworkLayerForChecksum(ipLayer); err != nil {
+			return nil, fmt.Errorf("failed to set network layer for checksum: %w", err)
+		}
+		if g.target.Port() == dnsPort {
+			return []gopacket.SerializableLayer{ipLayer, udpLayer, dnsQuery(probeID)}, nil
+		}
+		return []gopacket.SerializableLayer{ipLayer, udpLayer}, nil
+	default:
+		return nil, fmt.Errorf("unexpected protocol %s", g.protocol)
+	}
+}
+
+// generateV4 generates a probe, split into its IP header and payload for ipv4.RawConn
+func (g *packetGen) generateV4(probeID uint16, flowID uint16, ttl uint8) (*ipv4.Header, []byte, error) {
+	probeLayers, err := g.generateLayers(probeID, flowID, ttl)
+	if err != nil {
+		return nil, nil, err
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+	if err := gopacket.SerializeLayers(buf, opts, probeLayers...); err != nil {
+		return nil, nil, fmt.Errorf("failed to serialize packet: %w", err)
+	}
+	packet := buf.Bytes()
+
+	var ipHdr ipv4.Header
+	if err := ipHdr.Parse(packet[:ipv4HeaderLength]); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse IP header: %w", err)
+	}
+	return &ipHdr, packet[ipv4HeaderLength:], nil
+}
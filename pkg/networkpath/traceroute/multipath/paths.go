@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package multipath
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// flowHops returns the hops of a flow from MinTTL, up to the TTL at which the
+// flow reached the target (or MaxTTL if it never did)
+func flowHops(probes []probe, flowID uint16, params Params) []Hop {
+	minTTL, maxTTL := params.ParallelParams.MinTTL, params.ParallelParams.MaxTTL
+	hops := make([]Hop, int(maxTTL)-int(minTTL)+1)
+	// number of responses per TTL and responding IP, to pick the most frequent one
+	counts := make([]map[netip.Addr]int, len(hops))
+	for i := range hops {
+		hops[i].TTL = minTTL + uint8(i)
+		counts[i] = make(map[netip.Addr]int)
+	}
+
+	destIdx := -1
+	for _, p := range probes {
+		if p.flowID != flowID || p.ttl < minTTL || p.ttl > maxTTL {
+			continue
+		}
+		idx := int(p.ttl - minTTL)
+		hop := &hops[idx]
+		hop.ProbesSent++
+		if !p.received {
+			continue
+		}
+		hop.RTTs = append(hop.RTTs, p.rtt)
+		counts[idx][p.ip]++
+		// probes are in sending order, so ties are won by the first responding IP
+		if !hop.IP.IsValid() || counts[idx][p.ip] > counts[idx][hop.IP] {
+			hop.IP = p.ip
+		}
+		if p.isDest {
+			hop.IsDest = true
+			if destIdx == -1 || idx < destIdx {
+				destIdx = idx
+			}
+		}
+	}
+	if destIdx != -1 {
+		hops = hops[:destIdx+1]
+	}
+	return hops
+}
+
+// pathKey identifies a path by the IPs of its hops
+func pathKey(hops []Hop) string {
+	var key strings.Builder
+	for _, hop := range hops {
+		key.WriteString(hop.IP.String())
+		key.WriteByte(',')
+	}
+	return key.String()
+}
+
+// buildPaths groups the flows following the same hops into distinct paths
+func buildPaths(probes []probe, params Params) []Path {
+	var paths []Path
+	pathIndexes := make(map[string]int)
+	for flowID := uint16(0); flowID < params.NumPaths; flowID++ {
+		hops := flowHops(probes, flowID, params)
+		key := pathKey(hops)
+		idx, ok := pathIndexes[key]
+		if !ok {
+			pathIndexes[key] = len(paths)
+			paths = append(paths, Path{FlowIDs: []uint16{flowID}, Hops: hops})
+			continue
+		}
+		// merge the probes of this flow into the existing path
+		path := &paths[idx]
+		path.FlowIDs = append(path.FlowIDs, flowID)
+		for i := range path.Hops {
+			path.Hops[i].ProbesSent += hops[i].ProbesSent
+			path.Hops[i].IsDest = path.Hops[i].IsDest || hops[i].IsDest
+			path.Hops[i].RTTs = append(path.Hops[i].RTTs, hops[i].RTTs...)
+		}
+	}
+	for i := range paths {
+		for j := range paths[i].Hops {
+			slices.Sort(paths[i].Hops[j].RTTs)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+package multipath
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/common"
+)
+
+func answered(flowID uint16, ttl uint8, ip string, rtt time.Duration, isDest bool) probe {
+	return probe{flowID: flowID, ttl: ttl, received: true, ip: netip.MustParseAddr(ip), rtt: rtt, isDest: isDest}
+}
+
+func TestBuildPaths(t *testing.T) {
+	params := Params{
+		NumPaths: 3,
+		ParallelParams: common.TracerouteParallelParams{
+			MinTTL: 1,
+			MaxTTL: 4,
+		},
+	}
+	probes := []probe{
+		answered(0, 1, "10.1.0.1", 1*time.Millisecond, false),
+		answered(1, 1, "10.1.0.1", 2*time.Millisecond, false),
+		answered(2, 1, "10.1.0.1", 3*time.Millisecond, false),
+		// flow 0 sees a load balancer answering from two addresses, the most frequent is kept
+		answered(0, 2, "10.2.0.9", 4*time.Millisecond, false),
+		answered(1, 2, "10.2.0.1", 5*time.Millisecond, false),
+		{flowID: 2, ttl: 2},
+		answered(0, 3, "10.9.9.9", 7*time.Millisecond, true),
+		answered(1, 3, "10.9.9.9", 8*time.Millisecond, true),
+		{flowID: 2, ttl: 3},
+		// sent before the target was reached
+		{flowID: 0, ttl: 4},
+		answered(0, 2, "10.2.0.1", 6*time.Millisecond, false),
+		answered(0, 2, "10.2.0.1", 4*time.Millisecond, false),
+		{flowID: 2, ttl: 4},
+	}
+
+	paths := buildPaths(probes, params)
+	expected := []Path{
+		{
+			FlowIDs: []uint16{0, 1},
+			Hops: []Hop{
+				{TTL: 1, IP: netip.MustParseAddr("10.1.0.1"), ProbesSent: 2, RTTs: []time.Duration{1 * time.Millisecond, 2 * time.Millisecond}},
+				{TTL: 2, IP: netip.MustParseAddr("10.2.0.1"), ProbesSent: 4, RTTs: []time.Duration{4 * time.Millisecond, 4 * time.Millisecond, 5 * time.Millisecond, 6 * time.Millisecond}},
+				{TTL: 3, IP: netip.MustParseAddr("10.9.9.9"), IsDest: true, ProbesSent: 2, RTTs: []time.Duration{7 * time.Millisecond, 8 * time.Millisecond}},
+			},
+		},
+		{
+			FlowIDs: []uint16{2},
+			Hops: []Hop{
+				{TTL: 1, IP: netip.MustParseAddr("10.1.0.1"), ProbesSent: 1, RTTs: []time.Duration{3 * time.Millisecond}},
+				{TTL: 2, ProbesSent: 1},
+				{TTL: 3, ProbesSent: 1},
+				{TTL: 4, ProbesSent: 1},
+			},
+		},
+	}
+	assert.Equal(t, expected, paths)
+}
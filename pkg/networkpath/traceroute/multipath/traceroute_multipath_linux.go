@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build linux
+
+package multipath
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/common"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/filter"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// RunMultipathTraceroute fully executes a multipath traceroute using the given parameters
+func RunMultipathTraceroute(ctx context.Context, p Params) (*Results, error) {
+	err := p.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid multipath traceroute params: %w", err)
+	}
+
+	local, udpConn, err := common.LocalAddrForHost(p.Target.Addr().AsSlice(), p.Target.Port())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get local addr: %w", err)
+	}
+	udpConn.Close()
+	ctx, cancel := context.WithDeadline(ctx, time.Now().Add(p.MaxTimeout()))
+	defer cancel()
+
+	network := "ip4:udp"
+	if p.Protocol == payload.ProtocolICMP {
+		network = "ip4:icmp"
+	}
+	sink, err := filter.MakeRawConn(ctx, &net.ListenConfig{}, network, local.AddrPort().Addr())
+	if err != nil {
+		return nil, fmt.Errorf("failed to make raw conn: %w", err)
+	}
+	source, err := common.NewAFPacketSource()
+	if err != nil {
+		sink.Close()
+		return nil, fmt.Errorf("failed to make packet source: %w", err)
+	}
+
+	// a random ICMP ID base avoids mixing up concurrent ICMP traceroutes
+	driver := newMultipathDriver(p, local.AddrPort().Addr(), uint16(rand.Intn(1<<16)), sink, source)
+	defer driver.Close()
+
+	log.Debugf("multipath traceroute running %d paths to %s", p.NumPaths, p.Target)
+	probes, err := runMultipath(ctx, driver)
+	if err != nil {
+		return nil, fmt.Errorf("multipath traceroute failed: %w", err)
+	}
+
+	return &Results{
+		Source: local.AddrPort().Addr(),
+		Target: p.Target,
+		Paths:  buildPaths(probes, p),
+	}, nil
+}
@@ -0,0 +1,22 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2025-present Datadog, Inc.
+
+//go:build !linux
+
+package multipath
+
+import (
+	"context"
+	"errors"
+)
+
+var errPlatformNotSupported = &NotSupportedError{
+	Err: errors.New("multipath traceroute is not supported on this platform"),
+}
+
+// RunMultipathTraceroute is not supported
+func RunMultipathTraceroute(_ctx context.Context, _p Params) (*Results, error) {
+	return nil, errPlatformNotSupported
+}
@@ -10,19 +10,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"math/rand"
 	"net"
 	"net/netip"
+	"runtime"
 	"slices"
 	"time"
 
 	"github.com/DataDog/datadog-agent/comp/core/hostname"
 	telemetryComponent "github.com/DataDog/datadog-agent/comp/core/telemetry"
-	"github.com/DataDog/datadog-agent/pkg/config/setup"
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/common"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/config"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/multipath"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/sack"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/tcp"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
@@ -106,17 +108,8 @@ func (r *Runner) RunTraceroute(ctx context.Context, cfg config.Config) (payload.
 	// use first resolved IP for now
 	dest := dests[0]
 
-	maxTTL := cfg.MaxTTL
-	if maxTTL == 0 {
-		maxTTL = setup.DefaultNetworkPathMaxTTL
-	}
-
-	var timeout time.Duration
-	if cfg.Timeout == 0 {
-		timeout = setup.DefaultNetworkPathTimeout * time.Duration(maxTTL) * time.Millisecond
-	} else {
-		timeout = cfg.Timeout
-	}
+	maxTTL := cfg.ResolvedMaxTTL()
+	timeout := cfg.ResolvedTimeout()
 
 	hname, err := r.hostnameService.Get(ctx)
 	if err != nil {
@@ -132,15 +125,25 @@ func (r *Runner) RunTraceroute(ctx context.Context, cfg config.Config) (payload.
 	if protocol == "" {
 		protocol = payload.ProtocolUDP
 	}
-	switch protocol {
-	case payload.ProtocolTCP:
+	switch {
+	case protocol == payload.ProtocolICMP && runtime.GOOS != "linux":
+		tracerouteRunnerTelemetry.failedRuns.Inc()
+		return payload.NetworkPath{}, fmt.Errorf("failed to run traceroute, unsupported protocol on %s: %s", runtime.GOOS, protocol)
+	case multipath.IsMultipath(protocol, cfg.NumPaths, cfg.ProbesPerHop):
+		log.Tracef("Running multipath %s traceroute for: %+v", protocol, cfg)
+		pathResult, err = r.runMultipath(ctx, cfg, protocol, hname, dest, maxTTL, timeout)
+		if err != nil {
+			tracerouteRunnerTelemetry.failedRuns.Inc()
+			return payload.NetworkPath{}, err
+		}
+	case protocol == payload.ProtocolTCP:
 		log.Tracef("Running TCP traceroute for: %+v", cfg)
 		pathResult, err = r.runTCP(cfg, hname, dest, maxTTL, timeout)
 		if err != nil {
 			tracerouteRunnerTelemetry.failedRuns.Inc()
 			return payload.NetworkPath{}, err
 		}
-	case payload.ProtocolUDP:
+	case protocol == payload.ProtocolUDP:
 		log.Tracef("Running UDP traceroute for: %+v", cfg)
 		pathResult, err = r.runUDP(cfg, hname, dest, maxTTL, timeout)
 		if err != nil {
@@ -241,6 +244,116 @@ func (r *Runner) runTCP(cfg config.Config, hname string, target net.IP, maxTTL u
 	return pathResult, nil
 }
 
+func makeMultipathParams(cfg config.Config, protocol payload.Protocol, target net.IP, maxTTL uint8, timeout time.Duration) (multipath.Params, error) {
+	targetAddr, ok := netip.AddrFromSlice(target)
+	if !ok {
+		return multipath.Params{}, fmt.Errorf("invalid target IP")
+	}
+	var destPort, srcPort uint16
+	if protocol == payload.ProtocolUDP {
+		destPort, srcPort, _ = getPorts(cfg.DestPort)
+	}
+	return multipath.NewParams(netip.AddrPortFrom(targetAddr.Unmap(), destPort), protocol, cfg.NumPaths, cfg.ProbesPerHop, srcPort, maxTTL, timeout), nil
+}
+
+func (r *Runner) runMultipath(ctx context.Context, cfg config.Config, protocol payload.Protocol, hname string, target net.IP, maxTTL uint8, timeout time.Duration) (payload.NetworkPath, error) {
+	params, err := makeMultipathParams(cfg, protocol, target, maxTTL, timeout)
+	if err != nil {
+		return payload.NetworkPath{}, fmt.Errorf("failed to make multipath params: %w", err)
+	}
+	results, err := multipath.RunMultipathTraceroute(ctx, params)
+	if err != nil {
+		return payload.NetworkPath{}, err
+	}
+
+	pathResult := r.processMultipathResults(results, protocol, hname, cfg.DestHostname, cfg.DestPort)
+	log.Tracef("Multipath %s Results: %+v", protocol, pathResult)
+
+	return pathResult, nil
+}
+
+func (r *Runner) processMultipathResults(res *multipath.Results, protocol payload.Protocol, hname string, destinationHost string, destinationPort uint16) payload.NetworkPath {
+	traceroutePath := payload.NetworkPath{
+		AgentVersion: version.AgentVersion,
+		PathtraceID:  payload.NewPathtraceID(),
+		Protocol:     protocol,
+		Timestamp:    time.Now().UnixMilli(),
+		Source: payload.NetworkPathSource{
+			Hostname:  hname,
+			NetworkID: r.networkID,
+		},
+		Destination: payload.NetworkPathDestination{
+			Hostname:  destinationHost,
+			Port:      destinationPort,
+			IPAddress: res.Target.Addr().String(),
+		},
+	}
+
+	if r.gatewayLookup != nil {
+		src := util.AddressFromNetIP(res.Source.AsSlice())
+		dst := util.AddressFromNetIP(res.Target.Addr().AsSlice())
+
+		traceroutePath.Source.Via = r.gatewayLookup.LookupWithIPs(src, dst, r.nsIno)
+	}
+
+	for _, path := range res.Paths {
+		trace := payload.NetworkPathTrace{}
+		for _, flowID := range path.FlowIDs {
+			trace.FlowIDs = append(trace.FlowIDs, int(flowID))
+		}
+		for _, hop := range path.Hops {
+			trace.Hops = append(trace.Hops, makeMultipathHop(hop))
+		}
+		traceroutePath.Paths = append(traceroutePath.Paths, trace)
+	}
+	if len(traceroutePath.Paths) > 0 {
+		traceroutePath.Hops = traceroutePath.Paths[0].Hops
+	}
+
+	return traceroutePath
+}
+
+func makeMultipathHop(hop multipath.Hop) payload.NetworkPathHop {
+	npHop := payload.NetworkPathHop{
+		TTL:       int(hop.TTL),
+		IPAddress: fmt.Sprintf("unknown_hop_%d", hop.TTL),
+		Stats: &payload.NetworkPathHopStats{
+			ProbesSent:     hop.ProbesSent,
+			ProbesReceived: len(hop.RTTs),
+		},
+	}
+	npHop.Hostname = npHop.IPAddress
+	if hop.IP.IsValid() {
+		npHop.IPAddress = hop.IP.String()
+		npHop.Hostname = npHop.IPAddress // setting to ip address for now, reverse DNS lookup will override hostname field later
+		npHop.Reachable = true
+	}
+	if hop.ProbesSent > 0 {
+		npHop.Stats.PacketLossPercentage = 100 * float64(hop.ProbesSent-len(hop.RTTs)) / float64(hop.ProbesSent)
+	}
+	if len(hop.RTTs) == 0 {
+		return npHop
+	}
+
+	rtts := make([]float64, len(hop.RTTs))
+	var sum float64
+	for i, rtt := range hop.RTTs {
+		rtts[i] = float64(rtt.Microseconds()) / float64(1000)
+		sum += rtts[i]
+	}
+	avg := sum / float64(len(rtts))
+	var variance float64
+	for _, rtt := range rtts {
+		variance += (rtt - avg) * (rtt - avg)
+	}
+	npHop.RTT = avg
+	npHop.Stats.RTTMin = slices.Min(rtts)
+	npHop.Stats.RTTAvg = avg
+	npHop.Stats.RTTMax = slices.Max(rtts)
+	npHop.Stats.RTTStdDev = math.Sqrt(variance / float64(len(rtts)))
+	return npHop
+}
+
 func (r *Runner) processResults(res *common.Results, protocol payload.Protocol, hname string, destinationHost string, destinationPort uint16) (payload.NetworkPath, error) {
 	if res == nil {
 		return payload.NetworkPath{}, nil
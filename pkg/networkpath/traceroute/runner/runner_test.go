@@ -8,11 +8,15 @@ package runner
 import (
 	"fmt"
 	"net"
+	"net/netip"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/network"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/common"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/config"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/multipath"
 	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/sack"
 	"github.com/DataDog/datadog-agent/pkg/version"
 	"github.com/golang/mock/gomock"
@@ -403,3 +407,116 @@ func TestTCPFallback(t *testing.T) {
 		require.Nil(t, results)
 	})
 }
+
+func TestProcessMultipathResults(t *testing.T) {
+	runner := &Runner{networkID: "test-network"}
+	results := &multipath.Results{
+		Source: netip.MustParseAddr("10.0.0.1"),
+		Target: netip.MustParseAddrPort("10.9.9.9:53"),
+		Paths: []multipath.Path{
+			{
+				FlowIDs: []uint16{0, 2},
+				Hops: []multipath.Hop{
+					{TTL: 1, IP: netip.MustParseAddr("10.1.0.1"), ProbesSent: 4, RTTs: []time.Duration{1 * time.Millisecond, 3 * time.Millisecond}},
+					{TTL: 2, IP: netip.MustParseAddr("10.9.9.9"), IsDest: true, ProbesSent: 2, RTTs: []time.Duration{5 * time.Millisecond, 5 * time.Millisecond}},
+				},
+			},
+			{
+				FlowIDs: []uint16{1},
+				Hops: []multipath.Hop{
+					{TTL: 1, ProbesSent: 2},
+				},
+			},
+		},
+	}
+
+	firstPathHops := []payload.NetworkPathHop{
+		{
+			TTL:       1,
+			IPAddress: "10.1.0.1",
+			Hostname:  "10.1.0.1",
+			RTT:       2,
+			Reachable: true,
+			Stats: &payload.NetworkPathHopStats{
+				ProbesSent:           4,
+				ProbesReceived:       2,
+				PacketLossPercentage: 50,
+				RTTMin:               1,
+				RTTAvg:               2,
+				RTTMax:               3,
+				RTTStdDev:            1,
+			},
+		},
+		{
+			TTL:       2,
+			IPAddress: "10.9.9.9",
+			Hostname:  "10.9.9.9",
+			RTT:       5,
+			Reachable: true,
+			Stats: &payload.NetworkPathHopStats{
+				ProbesSent:     2,
+				ProbesReceived: 2,
+				RTTMin:         5,
+				RTTAvg:         5,
+				RTTMax:         5,
+			},
+		},
+	}
+	expected := payload.NetworkPath{
+		AgentVersion: version.AgentVersion,
+		Protocol:     payload.ProtocolUDP,
+		Source: payload.NetworkPathSource{
+			Hostname:  "test-hostname",
+			NetworkID: "test-network",
+		},
+		Destination: payload.NetworkPathDestination{
+			Hostname:  "test-destination-hostname",
+			Port:      53,
+			IPAddress: "10.9.9.9",
+		},
+		Hops: firstPathHops,
+		Paths: []payload.NetworkPathTrace{
+			{
+				FlowIDs: []int{0, 2},
+				Hops:    firstPathHops,
+			},
+			{
+				FlowIDs: []int{1},
+				Hops: []payload.NetworkPathHop{
+					{
+						TTL:       1,
+						IPAddress: "unknown_hop_1",
+						Hostname:  "unknown_hop_1",
+						Stats: &payload.NetworkPathHopStats{
+							ProbesSent:           2,
+							PacketLossPercentage: 100,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	actual := runner.processMultipathResults(results, payload.ProtocolUDP, "test-hostname", "test-destination-hostname", 53)
+	diff := cmp.Diff(expected, actual,
+		cmpopts.IgnoreFields(payload.NetworkPath{}, "Timestamp"),
+		cmpopts.IgnoreFields(payload.NetworkPath{}, "PathtraceID"),
+	)
+	assert.Empty(t, diff)
+}
+
+func TestMakeMultipathParams(t *testing.T) {
+	params, err := makeMultipathParams(config.Config{Protocol: payload.ProtocolICMP, NumPaths: 8}, payload.ProtocolICMP, net.ParseIP("10.9.9.9"), 20, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddrPort("10.9.9.9:0"), params.Target)
+	assert.Equal(t, uint16(8), params.NumPaths)
+	assert.Equal(t, uint8(1), params.ProbesPerHop)
+	assert.Equal(t, uint8(20), params.ParallelParams.MaxTTL)
+
+	params, err = makeMultipathParams(config.Config{DestPort: 53, ProbesPerHop: 3}, payload.ProtocolUDP, net.ParseIP("10.9.9.9"), 20, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddrPort("10.9.9.9:53"), params.Target)
+	assert.Equal(t, uint16(DefaultNumPaths), params.NumPaths)
+	assert.Equal(t, uint8(3), params.ProbesPerHop)
+	assert.GreaterOrEqual(t, params.SrcPort, uint16(DefaultSourcePort))
+}
@@ -9,21 +9,39 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/netip"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/config"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/multipath"
 	sysprobeclient "github.com/DataDog/datadog-agent/pkg/system-probe/api/client"
 	sysconfig "github.com/DataDog/datadog-agent/pkg/system-probe/config"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
-func getTraceroute(client *http.Client, clientID string, host string, port uint16, protocol payload.Protocol, tcpMethod payload.TCPMethod, maxTTL uint8, timeout time.Duration) ([]byte, error) {
-	httpTimeout := timeout*time.Duration(maxTTL) + 10*time.Second // allow extra time for the system probe communication overhead, calculate full timeout for TCP traceroute
+// getHTTPTimeout returns the timeout of the traceroute request to system-probe,
+// covering the traceroute it runs plus the system-probe communication overhead.
+func getHTTPTimeout(cfg config.Config) time.Duration {
+	tracerouteTimeout := cfg.Timeout * time.Duration(cfg.MaxTTL) // full timeout for TCP traceroute
+	protocol := cfg.Protocol
+	if protocol == "" {
+		protocol = payload.ProtocolUDP
+	}
+	if multipath.IsMultipath(protocol, cfg.NumPaths, cfg.ProbesPerHop) {
+		params := multipath.NewParams(netip.AddrPort{}, protocol, cfg.NumPaths, cfg.ProbesPerHop, 0, cfg.ResolvedMaxTTL(), cfg.ResolvedTimeout())
+		tracerouteTimeout = params.MaxTimeout()
+	}
+	return tracerouteTimeout + 10*time.Second
+}
+
+func getTraceroute(client *http.Client, clientID string, cfg config.Config) ([]byte, error) {
+	httpTimeout := getHTTPTimeout(cfg)
 	log.Tracef("Network Path traceroute HTTP request timeout: %s", httpTimeout)
 	ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
 	defer cancel()
 
-	url := sysprobeclient.ModuleURL(sysconfig.TracerouteModule, fmt.Sprintf("/traceroute/%s?client_id=%s&port=%d&max_ttl=%d&timeout=%d&protocol=%s&tcp_method=%s", host, clientID, port, maxTTL, timeout, protocol, tcpMethod))
+	url := sysprobeclient.ModuleURL(sysconfig.TracerouteModule, fmt.Sprintf("/traceroute/%s?client_id=%s&port=%d&max_ttl=%d&timeout=%d&protocol=%s&tcp_method=%s&num_paths=%d&probes_per_hop=%d", cfg.DestHostname, clientID, cfg.DestPort, cfg.MaxTTL, cfg.Timeout, cfg.Protocol, cfg.TCPMethod, cfg.NumPaths, cfg.ProbesPerHop))
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,32 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package traceroute
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/networkpath/payload"
+	"github.com/DataDog/datadog-agent/pkg/networkpath/traceroute/config"
+)
+
+func TestGetHTTPTimeout(t *testing.T) {
+	// TCP traceroutes wait for every hop
+	assert.Equal(t, 40*time.Second, getHTTPTimeout(config.Config{Protocol: payload.ProtocolTCP, MaxTTL: 30, Timeout: time.Second}))
+
+	// multipath traceroutes last until their timeout, plus the delays between their probes
+	assert.Equal(t, 11*time.Second+10*time.Millisecond*8*3*30, getHTTPTimeout(config.Config{
+		Protocol:     payload.ProtocolICMP,
+		MaxTTL:       30,
+		Timeout:      time.Second,
+		NumPaths:     8,
+		ProbesPerHop: 3,
+	}))
+	// with the default timeout
+	assert.Equal(t, 40*time.Second+10*time.Millisecond*30, getHTTPTimeout(config.Config{Protocol: payload.ProtocolICMP}))
+}
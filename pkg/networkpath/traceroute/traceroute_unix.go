@@ -51,7 +51,7 @@ func New(cfg config.Config, _ telemetry.Component) (*UnixTraceroute, error) {
 
 // Run executes a traceroute
 func (l *UnixTraceroute) Run(_ context.Context) (payload.NetworkPath, error) {
-	resp, err := getTraceroute(l.sysprobeClient, clientID, l.cfg.DestHostname, l.cfg.DestPort, l.cfg.Protocol, l.cfg.TCPMethod, l.cfg.MaxTTL, l.cfg.Timeout)
+	resp, err := getTraceroute(l.sysprobeClient, clientID, l.cfg)
 	if err != nil {
 		return payload.NetworkPath{}, err
 	}
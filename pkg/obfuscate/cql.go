@@ -0,0 +1,285 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cqlTableKeywords holds the keywords which are followed by a table name.
+var cqlTableKeywords = map[string]bool{
+	"FROM": true, "INTO": true, "UPDATE": true, "TABLE": true, "TRUNCATE": true,
+}
+
+// cqlOptions holds the options of a single CQL or PartiQL obfuscation.
+type cqlOptions struct {
+	// keyspace is the keyspace of the session which executed the statement. It is
+	// used to qualify table names which are not prefixed by a keyspace.
+	keyspace string
+	// removeKeyspace specifies whether keyspace qualifiers should be removed from
+	// table names in the obfuscated statement.
+	removeKeyspace bool
+	// tableNames specifies whether table names should be collected.
+	tableNames bool
+}
+
+// cacheKey returns the key of the obfuscated query cache for the given statement
+// obfuscated with opts. Any option added to cqlOptions must be part of the key.
+func (opts cqlOptions) cacheKey(query string) string {
+	flags := byte('0')
+	if opts.removeKeyspace {
+		flags |= 1 << 0
+	}
+	if opts.tableNames {
+		flags |= 1 << 1
+	}
+	// the keyspace is prefixed by its length so that it can't be confused with the query
+	return "cql:" + string(flags) + strconv.Itoa(len(opts.keyspace)) + ":" + opts.keyspace + query
+}
+
+// ObfuscateCQLString obfuscates and normalizes the given Cassandra Query Language statement.
+// String, numeric, UUID, blob and collection literals are replaced with "?" and lists of
+// values in IN clauses are grouped. The keyspace of the session which executed the statement,
+// if known, is used to qualify the table names reported in the metadata. When RemoveKeyspace
+// is set, keyspace qualifiers are removed from the table names in the resulting query, so that
+// a statement gets the same resource regardless of the keyspace it addresses.
+func (o *Obfuscator) ObfuscateCQLString(query, keyspace string) (*ObfuscatedQuery, error) {
+	opts := cqlOptions{
+		keyspace:       keyspace,
+		removeKeyspace: o.opts.CQL.RemoveKeyspace,
+		tableNames:     o.opts.SQL.TableNames,
+	}
+	cacheKey := opts.cacheKey(query)
+	if v, ok := o.queryCache.Get(cacheKey); ok {
+		return v.(*ObfuscatedQuery), nil
+	}
+	oq, err := obfuscateCQL(newCQLTokenizer(query), opts)
+	if err != nil {
+		return nil, err
+	}
+	o.queryCache.Set(cacheKey, oq, oq.Cost())
+	return oq, nil
+}
+
+// obfuscateCQL obfuscates the statement loaded into the tokenizer.
+func obfuscateCQL(t *cqlTokenizer, opts cqlOptions) (*ObfuscatedQuery, error) {
+	var (
+		out       []string // output tokens
+		tables    []string
+		inGroups  []int // indexes in out of the opening parenthesis of IN clauses
+		parens    []int // indexes in out of all open parentheses
+		lastTyp   cqlTokenType
+		lastTok   string
+		expectTbl bool // whether a table name is expected next
+	)
+	for {
+		typ, tok, err := t.scan()
+		if err != nil {
+			return nil, err
+		}
+		if typ == cqlEOF {
+			break
+		}
+		switch {
+		case typ.isLiteral():
+			tok = "?"
+		case typ == cqlPunct && (tok == "{" || tok == "<<" || tok == "[" && !isCQLOperand(lastTyp, lastTok)):
+			// collection literal
+			if err := skipCQLCollection(t, tok); err != nil {
+				return nil, err
+			}
+			typ, tok = cqlString, "?"
+		case typ == cqlPunct && tok == "(":
+			parens = append(parens, len(out))
+			if lastTyp == cqlIdent && strings.EqualFold(lastTok, "IN") {
+				inGroups = append(inGroups, len(out))
+			}
+		case typ == cqlPunct && tok == ")":
+			if len(parens) == 0 {
+				return nil, errors.New("unbalanced parentheses")
+			}
+			open := parens[len(parens)-1]
+			parens = parens[:len(parens)-1]
+			if n := len(inGroups); n > 0 && inGroups[n-1] == open {
+				inGroups = inGroups[:n-1]
+				if onlyCQLValues(out[open+1:]) {
+					// group all the values of an IN clause, e.g. "IN ( ?, ? )" becomes "IN ( ? )"
+					out = append(out[:open+1], "?")
+				}
+			}
+		case (typ == cqlIdent || typ == cqlQuotedIdent) && expectTbl:
+			if typ == cqlIdent && isCQLTableModifier(tok) {
+				// e.g. "CREATE TABLE IF NOT EXISTS ks.table"
+				break
+			}
+			expectTbl = false
+			table, err := scanCQLTableName(t, tok, opts)
+			if err != nil {
+				return nil, err
+			}
+			if opts.tableNames && table.qualified != "" {
+				tables = append(tables, table.qualified)
+			}
+			tok = table.name
+		case typ == cqlIdent && cqlTableKeywords[strings.ToUpper(tok)]:
+			expectTbl = true
+		}
+		if typ != cqlIdent {
+			expectTbl = false
+		}
+		out = append(out, tok)
+		lastTyp, lastTok = typ, tok
+	}
+	if len(parens) > 0 {
+		return nil, errors.New("unbalanced parentheses")
+	}
+	for len(out) > 0 && out[len(out)-1] == ";" {
+		out = out[:len(out)-1]
+	}
+	if len(out) == 0 {
+		return nil, errors.New("result is empty")
+	}
+	var meta SQLMetadata
+	if len(tables) > 0 {
+		meta.TablesCSV = strings.Join(dedupeStrings(tables), ",")
+		meta.Size = int64(len(meta.TablesCSV))
+	}
+	return &ObfuscatedQuery{
+		Query:    joinCQLTokens(out),
+		Metadata: meta,
+	}, nil
+}
+
+// cqlTableName holds a table name found in a statement.
+type cqlTableName struct {
+	// name is the table name as it should appear in the obfuscated statement.
+	name string
+	// qualified is the table name prefixed by its keyspace, if known.
+	qualified string
+}
+
+// scanCQLTableName scans a table name starting with the given token. In CQL, the table name
+// may be prefixed by a keyspace (keyspace.table) while in PartiQL it may be followed by an
+// index name (table.index).
+func scanCQLTableName(t *cqlTokenizer, first string, opts cqlOptions) (cqlTableName, error) {
+	save, last := t.off, t.last
+	if typ, tok, err := t.scan(); err != nil || typ != cqlPunct || tok != "." {
+		// not qualified, rewind
+		t.off, t.last = save, last
+		if opts.keyspace != "" {
+			return cqlTableName{name: first, qualified: opts.keyspace + "." + first}, nil
+		}
+		return cqlTableName{name: first, qualified: first}, nil
+	}
+	typ, second, err := t.scan()
+	if err != nil {
+		return cqlTableName{}, err
+	}
+	if typ != cqlIdent && typ != cqlQuotedIdent {
+		return cqlTableName{}, fmt.Errorf("unexpected %s after %s", typ, first)
+	}
+	if t.partiQL {
+		return cqlTableName{name: first + "." + second, qualified: first}, nil
+	}
+	if opts.removeKeyspace {
+		return cqlTableName{name: second, qualified: first + "." + second}, nil
+	}
+	return cqlTableName{name: first + "." + second, qualified: first + "." + second}, nil
+}
+
+// skipCQLCollection advances the tokenizer past the collection literal opened by the given token.
+func skipCQLCollection(t *cqlTokenizer, open string) error {
+	depth := 1
+	for depth > 0 {
+		typ, tok, err := t.scan()
+		if err != nil {
+			return err
+		}
+		if typ == cqlEOF {
+			return fmt.Errorf("unterminated collection literal %q", open)
+		}
+		if typ != cqlPunct {
+			continue
+		}
+		switch tok {
+		case "{", "[", "<<":
+			depth++
+		case "}", "]", ">>":
+			depth--
+		}
+	}
+	return nil
+}
+
+// cqlKeywordsBeforeValues holds the keywords which may be directly followed by a collection literal.
+var cqlKeywordsBeforeValues = map[string]bool{
+	"IN": true, "CONTAINS": true, "KEY": true, "VALUES": true, "AND": true, "OR": true,
+	"NOT": true, "SET": true, "SELECT": true, "WHERE": true, "VALUE": true,
+}
+
+// isCQLOperand reports whether the token can be indexed or subscripted, e.g. map['key'].
+func isCQLOperand(typ cqlTokenType, tok string) bool {
+	switch typ {
+	case cqlIdent:
+		return !cqlKeywordsBeforeValues[strings.ToUpper(tok)]
+	case cqlQuotedIdent:
+		return true
+	case cqlPunct:
+		return tok == "]" || tok == ")"
+	}
+	return false
+}
+
+// isCQLTableModifier reports whether tok can appear between a table keyword and a table name.
+func isCQLTableModifier(tok string) bool {
+	switch strings.ToUpper(tok) {
+	case "IF", "NOT", "EXISTS", "TABLE":
+		return true
+	}
+	return false
+}
+
+// onlyCQLValues reports whether the given tokens are a list of obfuscated values or bind markers.
+func onlyCQLValues(toks []string) bool {
+	if len(toks) == 0 {
+		return false
+	}
+	for _, tok := range toks {
+		if tok != "?" && tok != "," && !strings.HasPrefix(tok, ":") {
+			return false
+		}
+	}
+	return true
+}
+
+// joinCQLTokens joins the output tokens with a space, except around dots and before commas.
+func joinCQLTokens(toks []string) string {
+	var b strings.Builder
+	for i, tok := range toks {
+		if i > 0 && tok != "," && tok != "." && toks[i-1] != "." {
+			b.WriteByte(' ')
+		}
+		b.WriteString(tok)
+	}
+	return b.String()
+}
+
+// dedupeStrings returns s without duplicates, preserving the order of first appearance.
+func dedupeStrings(s []string) []string {
+	seen := make(map[string]struct{}, len(s))
+	out := s[:0]
+	for _, v := range s {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	return out
+}
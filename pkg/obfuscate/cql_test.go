@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var cqlSuite = []struct {
+	in, out string
+}{
+	{
+		"SELECT * FROM users WHERE id = 123",
+		"SELECT * FROM users WHERE id = ?",
+	},
+	{
+		"SELECT name, email FROM ks.users WHERE id = 62c36092-82a1-3a00-93d1-46196ee77204 AND name = 'O''Brien';",
+		"SELECT name, email FROM ks.users WHERE id = ? AND name = ?",
+	},
+	{
+		"INSERT INTO ks.users (id, tags, attrs, scores, avatar) VALUES (now(), {'a', 'b'}, {'k': 'v'}, [1, 2, 3], 0xcafe) USING TTL 86400 AND TIMESTAMP 1700000000",
+		"INSERT INTO ks.users ( id, tags, attrs, scores, avatar ) VALUES ( now ( ), ?, ?, ?, ? ) USING TTL ? AND TIMESTAMP ?",
+	},
+	{
+		"UPDATE users SET scores = scores + [4], attrs['k'] = 'v2' WHERE id IN (1, 2, 3) IF EXISTS",
+		"UPDATE users SET scores = scores + ?, attrs [ ? ] = ? WHERE id IN ( ? ) IF EXISTS",
+	},
+	{
+		"SELECT * FROM events WHERE (a, b) IN ((1, 2), (3, 4)) AND c > -1.5e3 LIMIT 10",
+		"SELECT * FROM events WHERE ( a, b ) IN ( ( ?, ? ), ( ?, ? ) ) AND c > ? LIMIT ?",
+	},
+	{
+		"SELECT * FROM \"Users\" WHERE tags CONTAINS 'x' AND id IN (?, ?) AND ts = :ts -- comment",
+		"SELECT * FROM \"Users\" WHERE tags CONTAINS ? AND id IN ( ? ) AND ts = :ts",
+	},
+	{
+		"/* batch */ BEGIN BATCH INSERT INTO t (a) VALUES ('x'); DELETE FROM t WHERE a = 'y'; APPLY BATCH",
+		"BEGIN BATCH INSERT INTO t ( a ) VALUES ( ? ) ; DELETE FROM t WHERE a = ? ; APPLY BATCH",
+	},
+	{
+		"CREATE FUNCTION f (x int) RETURNS NULL ON NULL INPUT RETURNS int LANGUAGE java AS $$ return x + 1; $$",
+		"CREATE FUNCTION f ( x int ) RETURNS NULL ON NULL INPUT RETURNS int LANGUAGE java AS ?",
+	},
+	{
+		"CREATE TABLE IF NOT EXISTS ks.t (id uuid PRIMARY KEY) WITH comment = 'secret'",
+		"CREATE TABLE IF NOT EXISTS ks.t ( id uuid PRIMARY KEY ) WITH comment = ?",
+	},
+}
+
+func TestObfuscateCQL(t *testing.T) {
+	o := NewObfuscator(Config{})
+	for _, tt := range cqlSuite {
+		t.Run(tt.in, func(t *testing.T) {
+			oq, err := o.ObfuscateCQLString(tt.in, "")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, oq.Query)
+			assert.Empty(t, oq.Metadata.TablesCSV)
+		})
+	}
+}
+
+func TestObfuscateCQLKeyspace(t *testing.T) {
+	for _, tt := range []struct {
+		in, keyspace   string
+		removeKeyspace bool
+		out, tables    string
+	}{
+		{
+			in:     "SELECT * FROM users WHERE id = 1",
+			out:    "SELECT * FROM users WHERE id = ?",
+			tables: "users",
+		},
+		{
+			in:       "SELECT * FROM users WHERE id = 1",
+			keyspace: "tenant_1",
+			out:      "SELECT * FROM users WHERE id = ?",
+			tables:   "tenant_1.users",
+		},
+		{
+			in:       "SELECT * FROM tenant_2.users WHERE id = 1",
+			keyspace: "tenant_1",
+			out:      "SELECT * FROM tenant_2.users WHERE id = ?",
+			tables:   "tenant_2.users",
+		},
+		{
+			in:             "SELECT * FROM tenant_2.users WHERE id = 1",
+			keyspace:       "tenant_1",
+			removeKeyspace: true,
+			out:            "SELECT * FROM users WHERE id = ?",
+			tables:         "tenant_2.users",
+		},
+		{
+			in:             "BEGIN BATCH UPDATE ks.a SET x = 1 WHERE id = 2; INSERT INTO b (id) VALUES (3); UPDATE ks.a SET x = 2 WHERE id = 3; APPLY BATCH",
+			keyspace:       "ks",
+			removeKeyspace: true,
+			out:            "BEGIN BATCH UPDATE a SET x = ? WHERE id = ? ; INSERT INTO b ( id ) VALUES ( ? ) ; UPDATE a SET x = ? WHERE id = ? ; APPLY BATCH",
+			tables:         "ks.a,ks.b",
+		},
+		{
+			in:             `TRUNCATE TABLE "Ks"."Users"`,
+			removeKeyspace: true,
+			out:            `TRUNCATE TABLE "Users"`,
+			tables:         `"Ks"."Users"`,
+		},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			o := NewObfuscator(Config{
+				SQL: SQLConfig{TableNames: true},
+				CQL: CQLConfig{Enabled: true, RemoveKeyspace: tt.removeKeyspace},
+			})
+			oq, err := o.ObfuscateCQLString(tt.in, tt.keyspace)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, oq.Query)
+			assert.Equal(t, tt.tables, oq.Metadata.TablesCSV)
+			assert.Equal(t, int64(len(tt.tables)), oq.Metadata.Size)
+		})
+	}
+}
+
+func TestObfuscateCQLErrors(t *testing.T) {
+	o := NewObfuscator(Config{})
+	for _, in := range []string{
+		"",
+		"SELECT * FROM t WHERE a = 'unterminated",
+		"SELECT * FROM t WHERE a IN (1, 2",
+		"SELECT * FROM t WHERE a = 1)",
+		"INSERT INTO t (a) VALUES ({'k': 'v')",
+		"SELECT * FROM t /* unterminated",
+		"SELECT * FROM t WHERE a = `b`",
+	} {
+		t.Run(in, func(t *testing.T) {
+			_, err := o.ObfuscateCQLString(in, "")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func FuzzObfuscateCQL(f *testing.F) {
+	for _, s := range cqlSuite {
+		f.Add(s.in, "ks")
+	}
+	o := NewObfuscator(Config{
+		SQL: SQLConfig{TableNames: true},
+		CQL: CQLConfig{Enabled: true, RemoveKeyspace: true},
+	})
+	f.Fuzz(func(_ *testing.T, query, keyspace string) {
+		o.ObfuscateCQLString(query, keyspace)
+	})
+}
+
+func BenchmarkObfuscateCQL(b *testing.B) {
+	o := NewObfuscator(Config{})
+	query := cqlSuite[2].in
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		o.ObfuscateCQLString(query, "ks")
+	}
+}
+
+func TestCQLCacheKey(t *testing.T) {
+	query := "SELECT * FROM users"
+	keys := map[string]cqlOptions{}
+	for _, opts := range []cqlOptions{
+		{},
+		{removeKeyspace: true},
+		{tableNames: true},
+		{removeKeyspace: true, tableNames: true},
+		{keyspace: "shop"},
+		{keyspace: "shop", removeKeyspace: true, tableNames: true},
+	} {
+		key := opts.cacheKey(query)
+		assert.NotContains(t, keys, key, "options %+v share a cache key with %+v", opts, keys[key])
+		keys[key] = opts
+	}
+	assert.Equal(t, cqlOptions{keyspace: "shop"}.cacheKey(query), cqlOptions{keyspace: "shop"}.cacheKey(query))
+	assert.NotEqual(t, cqlOptions{keyspace: "a:b"}.cacheKey("c"), cqlOptions{keyspace: "a"}.cacheKey("b:c"))
+}
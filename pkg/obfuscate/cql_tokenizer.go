@@ -0,0 +1,302 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// cqlTokenType specifies the token type returned by the CQL tokenizer.
+type cqlTokenType int
+
+const (
+	// cqlEOF is returned once the end of the input has been reached.
+	cqlEOF cqlTokenType = iota
+
+	// cqlIdent is an unquoted identifier or keyword.
+	cqlIdent
+
+	// cqlQuotedIdent is a double quoted identifier.
+	cqlQuotedIdent
+
+	// cqlString is a single quoted or dollar quoted ($$...$$) string literal.
+	cqlString
+
+	// cqlNumber is a numeric literal, including hexadecimal blobs (0x...).
+	cqlNumber
+
+	// cqlUUID is a UUID literal.
+	cqlUUID
+
+	// cqlBindMarker is an anonymous (?) or named (:name) bind marker.
+	cqlBindMarker
+
+	// cqlPunct is an operator or a punctuation character.
+	cqlPunct
+)
+
+// String implements fmt.Stringer.
+func (t cqlTokenType) String() string {
+	return map[cqlTokenType]string{
+		cqlEOF:         "EOF",
+		cqlIdent:       "identifier",
+		cqlQuotedIdent: "quoted identifier",
+		cqlString:      "string",
+		cqlNumber:      "number",
+		cqlUUID:        "uuid",
+		cqlBindMarker:  "bind marker",
+		cqlPunct:       "punctuation",
+	}[t]
+}
+
+// isLiteral reports whether the token type is a constant value.
+func (t cqlTokenType) isLiteral() bool {
+	return t == cqlString || t == cqlNumber || t == cqlUUID
+}
+
+// errCQLUnterminated is returned when a string, quoted identifier or comment is not closed.
+var errCQLUnterminated = errors.New("unterminated string, identifier or comment")
+
+// cqlTokenizer tokenizes Cassandra Query Language statements. The same
+// tokenizer is used for DynamoDB PartiQL statements, which share the same
+// lexical structure, with the addition of bag delimiters (<< and >>).
+// Comments and whitespace are skipped.
+type cqlTokenizer struct {
+	data []byte
+	off  int
+	last cqlTokenType // type of the last token returned
+	// partiQL specifies that the tokenizer scans PartiQL, in which double
+	// dollar signs do not delimit strings.
+	partiQL bool
+}
+
+// newCQLTokenizer returns a new tokenizer for the given CQL statement.
+func newCQLTokenizer(query string) *cqlTokenizer {
+	return &cqlTokenizer{data: []byte(query), last: cqlEOF}
+}
+
+// newPartiQLTokenizer returns a new tokenizer for the given PartiQL statement.
+func newPartiQLTokenizer(query string) *cqlTokenizer {
+	return &cqlTokenizer{data: []byte(query), last: cqlEOF, partiQL: true}
+}
+
+// scan returns the next token along with its type.
+func (t *cqlTokenizer) scan() (typ cqlTokenType, tok string, err error) {
+	typ, tok, err = t.next()
+	if err == nil {
+		t.last = typ
+	}
+	return typ, tok, err
+}
+
+func (t *cqlTokenizer) next() (cqlTokenType, string, error) {
+	if err := t.skipIgnored(); err != nil {
+		return cqlEOF, "", err
+	}
+	if t.off >= len(t.data) {
+		return cqlEOF, "", nil
+	}
+	start := t.off
+	ch := t.data[t.off]
+	switch {
+	case t.isUUIDAt(t.off):
+		t.off += 36
+		return cqlUUID, string(t.data[start:t.off]), nil
+	case isCQLLetter(ch):
+		for t.off < len(t.data) && isCQLIdentChar(t.data[t.off]) {
+			t.off++
+		}
+		return cqlIdent, string(t.data[start:t.off]), nil
+	case isDigit(rune(ch)):
+		t.scanNumber()
+		return cqlNumber, string(t.data[start:t.off]), nil
+	case ch == '-' && t.off+1 < len(t.data) && isDigit(rune(t.data[t.off+1])) && !t.afterValue():
+		// negative number
+		t.off++
+		t.scanNumber()
+		return cqlNumber, string(t.data[start:t.off]), nil
+	case ch == '\'':
+		if err := t.scanQuoted('\''); err != nil {
+			return cqlEOF, "", err
+		}
+		return cqlString, string(t.data[start:t.off]), nil
+	case ch == '"':
+		if err := t.scanQuoted('"'); err != nil {
+			return cqlEOF, "", err
+		}
+		return cqlQuotedIdent, string(t.data[start:t.off]), nil
+	case ch == '$' && !t.partiQL && t.off+1 < len(t.data) && t.data[t.off+1] == '$':
+		end := indexFrom(t.data, "$$", t.off+2)
+		if end < 0 {
+			return cqlEOF, "", errCQLUnterminated
+		}
+		t.off = end + 2
+		return cqlString, string(t.data[start:t.off]), nil
+	case ch == '?':
+		t.off++
+		return cqlBindMarker, "?", nil
+	case ch == ':' && t.off+1 < len(t.data) && isCQLLetter(t.data[t.off+1]):
+		t.off++
+		for t.off < len(t.data) && isCQLIdentChar(t.data[t.off]) {
+			t.off++
+		}
+		return cqlBindMarker, string(t.data[start:t.off]), nil
+	}
+	if t.off+1 < len(t.data) {
+		switch two := string(t.data[t.off : t.off+2]); two {
+		case "<=", ">=", "!=", "<>", "||":
+			t.off += 2
+			return cqlPunct, two, nil
+		case "<<", ">>":
+			if t.partiQL {
+				t.off += 2
+				return cqlPunct, two, nil
+			}
+		}
+	}
+	switch ch {
+	case '(', ')', '[', ']', '{', '}', ',', '.', ';', ':', '=', '<', '>', '+', '-', '*', '/', '%':
+		t.off++
+		return cqlPunct, string(ch), nil
+	}
+	return cqlEOF, "", fmt.Errorf("unexpected character %q at position %d", ch, t.off)
+}
+
+// afterValue reports whether the last token returned could end an operand, in
+// which case a following '-' is a binary operator.
+func (t *cqlTokenizer) afterValue() bool {
+	switch t.last {
+	case cqlIdent, cqlQuotedIdent, cqlString, cqlNumber, cqlUUID, cqlBindMarker:
+		return true
+	case cqlPunct:
+		prev := t.off - 1
+		for prev >= 0 && isSpace(t.data[prev]) {
+			prev--
+		}
+		return prev >= 0 && (t.data[prev] == ')' || t.data[prev] == ']')
+	}
+	return false
+}
+
+// skipIgnored advances the tokenizer past whitespace and comments.
+func (t *cqlTokenizer) skipIgnored() error {
+	for t.off < len(t.data) {
+		ch := t.data[t.off]
+		switch {
+		case isSpace(ch):
+			t.off++
+		case ch == '-' && t.off+1 < len(t.data) && t.data[t.off+1] == '-',
+			ch == '/' && t.off+1 < len(t.data) && t.data[t.off+1] == '/':
+			for t.off < len(t.data) && t.data[t.off] != '\n' {
+				t.off++
+			}
+		case ch == '/' && t.off+1 < len(t.data) && t.data[t.off+1] == '*':
+			end := indexFrom(t.data, "*/", t.off+2)
+			if end < 0 {
+				return errCQLUnterminated
+			}
+			t.off = end + 2
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// scanQuoted advances the tokenizer past a string or identifier delimited by
+// quote. Doubling the quote character escapes it.
+func (t *cqlTokenizer) scanQuoted(quote byte) error {
+	t.off++
+	for t.off < len(t.data) {
+		if t.data[t.off] == quote {
+			if t.off+1 < len(t.data) && t.data[t.off+1] == quote {
+				t.off += 2
+				continue
+			}
+			t.off++
+			return nil
+		}
+		t.off++
+	}
+	return errCQLUnterminated
+}
+
+// scanNumber advances the tokenizer past a number, which may be an integer,
+// a float with an optional exponent or a hexadecimal blob.
+func (t *cqlTokenizer) scanNumber() {
+	if t.off+1 < len(t.data) && t.data[t.off] == '0' && (t.data[t.off+1] == 'x' || t.data[t.off+1] == 'X') {
+		t.off += 2
+		for t.off < len(t.data) && isHex(t.data[t.off]) {
+			t.off++
+		}
+		return
+	}
+	for t.off < len(t.data) && isDigit(rune(t.data[t.off])) {
+		t.off++
+	}
+	if t.off+1 < len(t.data) && t.data[t.off] == '.' && isDigit(rune(t.data[t.off+1])) {
+		t.off++
+		for t.off < len(t.data) && isDigit(rune(t.data[t.off])) {
+			t.off++
+		}
+	}
+	if t.off < len(t.data) && (t.data[t.off] == 'e' || t.data[t.off] == 'E') {
+		exp := t.off + 1
+		if exp < len(t.data) && (t.data[exp] == '+' || t.data[exp] == '-') {
+			exp++
+		}
+		if exp < len(t.data) && isDigit(rune(t.data[exp])) {
+			t.off = exp
+			for t.off < len(t.data) && isDigit(rune(t.data[t.off])) {
+				t.off++
+			}
+		}
+	}
+}
+
+// isUUIDAt reports whether a UUID (8-4-4-4-12 hexadecimal digits) starts at
+// offset i and is not followed by an identifier character.
+func (t *cqlTokenizer) isUUIDAt(i int) bool {
+	if i+36 > len(t.data) {
+		return false
+	}
+	for j, c := range t.data[i : i+36] {
+		switch j {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !isHex(c) {
+				return false
+			}
+		}
+	}
+	return i+36 == len(t.data) || !isCQLIdentChar(t.data[i+36])
+}
+
+func isCQLLetter(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isCQLIdentChar(ch byte) bool {
+	return isCQLLetter(ch) || isDigit(rune(ch))
+}
+
+func isHex(ch byte) bool {
+	return digitVal(rune(ch)) < 16
+}
+
+// indexFrom returns the index of the first instance of sep in data, starting
+// at offset from, or -1 if it is not present.
+func indexFrom(data []byte, sep string, from int) int {
+	if i := bytes.Index(data[from:], []byte(sep)); i >= 0 {
+		return from + i
+	}
+	return -1
+}
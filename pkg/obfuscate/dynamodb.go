@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+// dynamoDBKeepKeys holds the DynamoDB request parameters which never contain attribute
+// values and are kept when obfuscating request bodies.
+var dynamoDBKeepKeys = []string{
+	"TableName",
+	"IndexName",
+	"Select",
+	"AttributesToGet",
+	"ProjectionExpression",
+	"KeyConditionExpression",
+	"FilterExpression",
+	"ConditionExpression",
+	"UpdateExpression",
+	"ExpressionAttributeNames",
+	"ConsistentRead",
+	"ScanIndexForward",
+	"Limit",
+	"Segment",
+	"TotalSegments",
+	"ReturnValues",
+	"ReturnConsumedCapacity",
+	"ReturnItemCollectionMetrics",
+	"ReturnValuesOnConditionCheckFailure",
+}
+
+// dynamoDBStatementKey is the request parameter holding PartiQL statements.
+const dynamoDBStatementKey = "Statement"
+
+// newDynamoDBObfuscator returns a JSON obfuscator for DynamoDB request bodies. Attribute values
+// are redacted everywhere (items, keys, expression attribute values and exclusive start keys)
+// while the structure, attribute names, expressions and request parameters are kept. PartiQL
+// statements are passed through the PartiQL obfuscator.
+func newDynamoDBObfuscator(cfg *DynamoDBConfig, o *Obfuscator) *jsonObfuscator {
+	keepKeys := make(map[string]bool, len(dynamoDBKeepKeys)+len(cfg.KeepValues))
+	for _, k := range dynamoDBKeepKeys {
+		keepKeys[k] = true
+	}
+	for _, k := range cfg.KeepValues {
+		keepKeys[k] = true
+	}
+	return &jsonObfuscator{
+		keepKeys:      keepKeys,
+		transformKeys: map[string]bool{dynamoDBStatementKey: true},
+		transformer:   partiQLObfuscationTransformer(o),
+		buffPool: sync.Pool{
+			New: func() any {
+				return new(bytes.Buffer)
+			},
+		},
+		statePool: sync.Pool{
+			New: func() any {
+				return &jsonObfuscatorState{
+					closures: []bool{},
+				}
+			},
+		},
+	}
+}
+
+// jsonStringEscaper escapes the characters of a PartiQL statement which can't appear as-is in a
+// JSON string.
+var jsonStringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+func partiQLObfuscationTransformer(o *Obfuscator) func(string) string {
+	return func(s string) string {
+		result, err := o.ObfuscatePartiQLString(s)
+		if err != nil {
+			o.log.Debugf("Failed to obfuscate PartiQL statement '%s': %s", s, err.Error())
+			return "?"
+		}
+		// the result is written as a JSON string, quoted identifiers must be escaped
+		return jsonStringEscaper.Replace(result.Query)
+	}
+}
+
+// ObfuscateDynamoDBString obfuscates the given DynamoDB JSON request body.
+func (o *Obfuscator) ObfuscateDynamoDBString(body string) string {
+	return obfuscateJSONString(body, o.dynamoDB)
+}
+
+// ObfuscatePartiQLString obfuscates the given DynamoDB PartiQL statement. String and numeric
+// literals, as well as tuple, list and bag literals, are replaced with "?". Parameters (?),
+// table names and attribute names are kept.
+func (o *Obfuscator) ObfuscatePartiQLString(statement string) (*ObfuscatedQuery, error) {
+	return obfuscateCQL(newPartiQLTokenizer(statement), cqlOptions{tableNames: o.opts.SQL.TableNames})
+}
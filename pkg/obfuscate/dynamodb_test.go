@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var partiQLSuite = []struct {
+	in, out string
+}{
+	{
+		`SELECT * FROM "Music" WHERE Artist = 'Acme Band' AND SongTitle = 'PartiQL Rocks'`,
+		`SELECT * FROM "Music" WHERE Artist = ? AND SongTitle = ?`,
+	},
+	{
+		`SELECT Artist FROM "Music"."ArtistIndex" WHERE Year >= 2020 AND Price < 1.5`,
+		`SELECT Artist FROM "Music"."ArtistIndex" WHERE Year >= ? AND Price < ?`,
+	},
+	{
+		`INSERT INTO "Music" VALUE {'Artist': 'Acme Band', 'Tags': <<'rock', 'pop'>>, 'Ratings': [1, 2]}`,
+		`INSERT INTO "Music" VALUE ?`,
+	},
+	{
+		`UPDATE "Music" SET AwardsWon = 1 SET AwardDetail = {'Grammys': [2020, 2018]} WHERE Artist = ?`,
+		`UPDATE "Music" SET AwardsWon = ? SET AwardDetail = ? WHERE Artist = ?`,
+	},
+	{
+		`SELECT * FROM "Music" WHERE Artist IN ['Acme Band', 'PartiQL Band'] AND Info.Genres[0] = 'rock'`,
+		`SELECT * FROM "Music" WHERE Artist IN ? AND Info.Genres [ ? ] = ?`,
+	},
+	{
+		`DELETE FROM "Music" WHERE Artist = 'Acme Band' AND SongTitle = 'PartiQL Rocks' RETURNING ALL OLD *`,
+		`DELETE FROM "Music" WHERE Artist = ? AND SongTitle = ? RETURNING ALL OLD *`,
+	},
+}
+
+func TestObfuscatePartiQL(t *testing.T) {
+	o := NewObfuscator(Config{})
+	for _, tt := range partiQLSuite {
+		t.Run(tt.in, func(t *testing.T) {
+			oq, err := o.ObfuscatePartiQLString(tt.in)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, oq.Query)
+		})
+	}
+
+	t.Run("tables", func(t *testing.T) {
+		o := NewObfuscator(Config{SQL: SQLConfig{TableNames: true}})
+		oq, err := o.ObfuscatePartiQLString(partiQLSuite[1].in)
+		assert.NoError(t, err)
+		assert.Equal(t, `"Music"`, oq.Metadata.TablesCSV)
+	})
+}
+
+func TestObfuscateDynamoDB(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		keepValues []string
+		in, out    string
+	}{
+		{
+			name: "get-item",
+			in:   `{"TableName":"Music","Key":{"Artist":{"S":"Acme Band"},"Year":{"N":"2020"}},"ProjectionExpression":"#t, Price","ExpressionAttributeNames":{"#t":"SongTitle"},"ConsistentRead":true}`,
+			out:  `{"TableName":"Music","Key":{"Artist":{"S":"?"},"Year":{"N":"?"}},"ProjectionExpression":"#t, Price","ExpressionAttributeNames":{"#t":"SongTitle"},"ConsistentRead":true}`,
+		},
+		{
+			name: "query",
+			in:   `{"TableName":"Music","KeyConditionExpression":"Artist = :a","ExpressionAttributeValues":{":a":{"S":"Acme Band"},":tags":{"SS":["a","b"]}},"Limit":10}`,
+			out:  `{"TableName":"Music","KeyConditionExpression":"Artist = :a","ExpressionAttributeValues":{":a":{"S":"?"},":tags":{"SS":["?","?"]}},"Limit":10}`,
+		},
+		{
+			name: "execute-statement",
+			in:   `{"Statement":"SELECT * FROM \"Music\" WHERE Artist = 'Acme Band' AND Year = ?","Parameters":[{"N":"2020"}]}`,
+			out:  `{"Statement":"SELECT * FROM \"Music\" WHERE Artist = ? AND Year = ?","Parameters":[{"N":"?"}]}`,
+		},
+		{
+			name: "execute-statement-backslash",
+			in:   `{"Statement":"SELECT * FROM \"Music\\\" WHERE Artist = 'Acme Band'"}`,
+			out:  `{"Statement":"SELECT * FROM \"Music\\\" WHERE Artist = ?"}`,
+		},
+		{
+			name: "batch-execute-statement",
+			in:   `{"Statements":[{"Statement":"DELETE FROM Music WHERE Artist = 'x'"},{"Statement":"not ' valid"}]}`,
+			out:  `{"Statements":[{"Statement":"DELETE FROM Music WHERE Artist = ?"},{"Statement":"?"}]}`,
+		},
+		{
+			name:       "keep-values",
+			keepValues: []string{"ExclusiveStartKey"},
+			in:         `{"TableName":"Music","ExclusiveStartKey":{"Artist":{"S":"Acme Band"}}}`,
+			out:        `{"TableName":"Music","ExclusiveStartKey":{"Artist":{"S":"Acme Band"}}}`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewObfuscator(Config{DynamoDB: DynamoDBConfig{Enabled: true, KeepValues: tt.keepValues}})
+			out := o.ObfuscateDynamoDBString(tt.in)
+			assert.Equal(t, tt.out, out)
+			assert.True(t, json.Valid([]byte(out)))
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		in := `{"Key":{"Artist":{"S":"Acme Band"}}}`
+		assert.Equal(t, in, NewObfuscator(Config{}).ObfuscateDynamoDBString(in))
+	})
+}
+
+func FuzzObfuscatePartiQL(f *testing.F) {
+	for _, s := range partiQLSuite {
+		f.Add(s.in)
+	}
+	o := NewObfuscator(Config{SQL: SQLConfig{TableNames: true}})
+	f.Fuzz(func(_ *testing.T, statement string) {
+		o.ObfuscatePartiQLString(statement)
+	})
+}
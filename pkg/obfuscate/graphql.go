@@ -0,0 +1,202 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strings"
+)
+
+// graphqlOperationTypes holds the keywords which start an operation definition.
+var graphqlOperationTypes = map[string]bool{
+	"query": true, "mutation": true, "subscription": true,
+}
+
+// graphqlContext is the kind of block opened by a bracket while obfuscating a query.
+type graphqlContext int
+
+const (
+	// graphqlSelectionSet is a selection set, or the body of another definition.
+	graphqlSelectionSet graphqlContext = iota
+	// graphqlVariableDefinitions is the list of variables of an operation.
+	graphqlVariableDefinitions
+	// graphqlArguments is the list of arguments of a field or a directive.
+	graphqlArguments
+	// graphqlObjectValue is an input object value.
+	graphqlObjectValue
+	// graphqlListValue is a list value.
+	graphqlListValue
+	// graphqlListType is a list type, such as [ID!].
+	graphqlListType
+)
+
+// ObfuscateGraphQLString obfuscates the given GraphQL document. String, integer, float,
+// boolean and enum literals found in arguments, variable default values, input objects
+// and lists are replaced with "?". Null literals, operation names, fields, aliases,
+// variable references, directives and fragments are kept. Comments are removed and
+// whitespace is compacted.
+func (*Obfuscator) ObfuscateGraphQLString(query string) (string, error) {
+	var (
+		out         strings.Builder
+		prev        string
+		prevPrev    string
+		stack       []graphqlContext
+		expectValue bool // whether the next token is a value
+		t           = newGraphQLTokenizer(query)
+	)
+	top := func() graphqlContext {
+		if len(stack) == 0 {
+			return graphqlSelectionSet
+		}
+		return stack[len(stack)-1]
+	}
+	out.Grow(len(query))
+	for {
+		typ, tok, err := t.scan()
+		if err != nil {
+			return "", err
+		}
+		isValue := expectValue || (len(stack) > 0 && top() == graphqlListValue)
+		expectValue = false
+		switch typ {
+		case graphqlEOF:
+			return out.String(), nil
+		case graphqlInt, graphqlFloat, graphqlString:
+			tok = "?"
+		case graphqlName:
+			if isValue && tok != "null" {
+				// booleans and enum values
+				tok = "?"
+			}
+		case graphqlPunctuator:
+			switch tok {
+			case "{":
+				if isValue {
+					stack = append(stack, graphqlObjectValue)
+				} else {
+					stack = append(stack, graphqlSelectionSet)
+				}
+			case "[":
+				if isValue {
+					stack = append(stack, graphqlListValue)
+				} else {
+					stack = append(stack, graphqlListType)
+				}
+			case "(":
+				if len(stack) == 0 && prevPrev != "@" {
+					// only operations take parentheses outside of any block
+					stack = append(stack, graphqlVariableDefinitions)
+				} else {
+					stack = append(stack, graphqlArguments)
+				}
+			case "}", ")", "]":
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+			case ":":
+				expectValue = top() == graphqlArguments || top() == graphqlObjectValue
+			case "=":
+				expectValue = top() == graphqlVariableDefinitions
+			}
+		}
+		if out.Len() > 0 && graphqlNeedsSpace(prev, tok) {
+			out.WriteByte(' ')
+		}
+		out.WriteString(tok)
+		prevPrev, prev = prev, tok
+	}
+}
+
+// graphqlNeedsSpace reports whether a space should be written between the
+// tokens prev and cur when rebuilding an obfuscated query.
+func graphqlNeedsSpace(prev, cur string) bool {
+	switch prev {
+	case "(", "[", "@", "$":
+		return false
+	case "...":
+		return cur == "on" || cur == "{" || cur == "@"
+	}
+	switch cur {
+	case "(", ")", "]", ":", "!", ",":
+		return false
+	}
+	return true
+}
+
+// QuantizeGraphQLString returns a quantized version of a GraphQL document, made of the
+// type and name of each operation it defines, e.g. "query GetUser" or "mutation".
+// Multiple operations are separated by "; ". Fragment and type system definitions
+// are not part of the result. An empty string is returned if the document could not
+// be tokenized or defines no operation.
+func (*Obfuscator) QuantizeGraphQLString(query string) string {
+	var (
+		ops     []string
+		opType  string // type of the operation being defined at the top level
+		opName  string // name of the operation being defined at the top level
+		inOther bool   // whether we are in a non-operation definition (e.g. a fragment)
+		prev    string // previous top level token
+		depth   int
+		t       = newGraphQLTokenizer(query)
+	)
+	for {
+		typ, tok, err := t.scan()
+		if err != nil {
+			return ""
+		}
+		if typ == graphqlEOF {
+			break
+		}
+		if depth > 0 {
+			switch tok {
+			case "{", "(", "[":
+				depth++
+			case "}", ")", "]":
+				depth--
+			}
+			continue
+		}
+		switch tok {
+		case "{":
+			if !inOther {
+				// shorthand queries have no operation type
+				op := opType
+				if op == "" {
+					op = "query"
+				}
+				if opName != "" {
+					op += " " + opName
+				}
+				ops = append(ops, op)
+			}
+			// the selection set (or body of another definition) ends the definition
+			opType, opName, inOther = "", "", false
+			depth++
+		case "(", "[":
+			depth++
+		case "}", ")", "]":
+			// unbalanced document
+			return ""
+		default:
+			if typ != graphqlName {
+				break
+			}
+			switch {
+			case opType == "" && !inOther:
+				if graphqlOperationTypes[tok] {
+					opType = tok
+				} else {
+					inOther = true
+				}
+			case opType != "" && prev == opType:
+				opName = tok
+			}
+		}
+		prev = tok
+	}
+	if depth != 0 {
+		return ""
+	}
+	return strings.Join(ops, "; ")
+}
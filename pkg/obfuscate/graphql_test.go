@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var graphqlSuite = []struct {
+	in, out, resource string
+}{
+	{
+		`{ user { name } }`,
+		`{ user { name } }`,
+		"query",
+	},
+	{
+		`query GetUser { user(id: 123) { name email } }`,
+		`query GetUser { user(id: ?) { name email } }`,
+		"query GetUser",
+	},
+	{
+		`query GetUser($id: ID!, $limit: Int = 10) {
+  # fetch the user
+  user(id: $id, name: "bob") {
+    name
+    friends(first: 5, after: "Y3Vyc29y") { ...UserFields }
+  }
+}
+
+fragment UserFields on User { id score(min: 1.5e3) }`,
+		`query GetUser($id: ID!, $limit: Int = ?) { user(id: $id, name: ?) { name friends(first: ?, after: ?) { ...UserFields } } } fragment UserFields on User { id score(min: ?) }`,
+		"query GetUser",
+	},
+	{
+		`mutation CreateUser { createUser(input: {name: "Alice", age: 30, tags: ["a", "b"], admin: false}) { id } }`,
+		`mutation CreateUser { createUser(input: { name: ?, age: ?, tags: [?, ?], admin: ? }) { id } }`,
+		"mutation CreateUser",
+	},
+	{
+		`subscription { messages(room: "secret", sort: ASC) @include(if: $live) { body } }`,
+		`subscription { messages(room: ?, sort: ?) @include(if: $live) { body } }`,
+		"subscription",
+	},
+	{
+		`query A { a(x: -1) } query B { b(y: """block
+string "with" quotes""") }`,
+		`query A { a(x: ?) } query B { b(y: ?) }`,
+		"query A; query B",
+	},
+	{
+		`query Search @cached(ttl: 60) { search(text: "a \"quoted\" value") { ... on User { name } ... @skip(if: true) { id } } }`,
+		`query Search @cached(ttl: ?) { search(text: ?) { ... on User { name } ... @skip(if: ?) { id } } }`,
+		"query Search",
+	},
+	{
+		`query Users($role: Role = ADMIN, $ids: [ID!] = [1, 2]) { users(role: $role, order: [NAME, AGE], filter: {active: true, team: null}) { id } }`,
+		`query Users($role: Role = ?, $ids: [ID!] = [?, ?]) { users(role: $role, order: [?, ?], filter: { active: ?, team: null }) { id } }`,
+		"query Users",
+	},
+	{
+		`fragment F on User { id }`,
+		`fragment F on User { id }`,
+		"",
+	},
+}
+
+func TestObfuscateGraphQL(t *testing.T) {
+	o := NewObfuscator(Config{})
+	for _, tt := range graphqlSuite {
+		t.Run(tt.resource, func(t *testing.T) {
+			out, err := o.ObfuscateGraphQLString(tt.in)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, out)
+			// obfuscating an already obfuscated query should not change it
+			out, err = o.ObfuscateGraphQLString(out)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.out, out)
+		})
+	}
+}
+
+func TestObfuscateGraphQLErrors(t *testing.T) {
+	o := NewObfuscator(Config{})
+	for _, in := range []string{
+		`{ user(name: "unterminated) { id } }`,
+		`{ user(name: """unterminated) { id } }`,
+		`{ user(id: 1.) { id } }`,
+		`{ user(id: -) { id } }`,
+		`{ user(id: 1) { id } } ;`,
+		`{ user(id: 1) { id } } ..`,
+	} {
+		t.Run(in, func(t *testing.T) {
+			out, err := o.ObfuscateGraphQLString(in)
+			assert.Error(t, err)
+			assert.Empty(t, out)
+		})
+	}
+}
+
+func TestQuantizeGraphQL(t *testing.T) {
+	o := NewObfuscator(Config{})
+	for _, tt := range graphqlSuite {
+		t.Run(tt.resource, func(t *testing.T) {
+			assert.Equal(t, tt.resource, o.QuantizeGraphQLString(tt.in))
+		})
+	}
+
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{`query($id: ID = {a: 1}) @live { a }`, "query"},
+		{`type Query { user(id: ID): User }`, ""},
+		{`query Q { a`, ""},
+		{`query Q { a } }`, ""},
+		{`query Q { a(x: "unterminated) }`, ""},
+	} {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.out, o.QuantizeGraphQLString(tt.in))
+		})
+	}
+}
+
+func FuzzObfuscateGraphQL(f *testing.F) {
+	for _, s := range graphqlSuite {
+		f.Add(s.in)
+	}
+	o := NewObfuscator(Config{})
+	f.Fuzz(func(t *testing.T, query string) {
+		out, err := o.ObfuscateGraphQLString(query)
+		if err != nil {
+			return
+		}
+		// obfuscation must be idempotent
+		again, err := o.ObfuscateGraphQLString(out)
+		if err != nil {
+			t.Fatalf("failed to re-obfuscate %q (from %q): %v", out, query, err)
+		}
+		if again != out {
+			t.Fatalf("obfuscation is not idempotent: %q != %q", again, out)
+		}
+	})
+}
+
+func FuzzQuantizeGraphQL(f *testing.F) {
+	for _, s := range graphqlSuite {
+		f.Add(s.in)
+	}
+	o := NewObfuscator(Config{})
+	f.Fuzz(func(_ *testing.T, query string) {
+		o.QuantizeGraphQLString(query)
+	})
+}
+
+func BenchmarkObfuscateGraphQL(b *testing.B) {
+	o := NewObfuscator(Config{})
+	query := graphqlSuite[2].in
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		o.ObfuscateGraphQLString(query)
+	}
+}
@@ -0,0 +1,226 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"errors"
+	"fmt"
+)
+
+// graphqlTokenType specifies the token type returned by the GraphQL tokenizer.
+type graphqlTokenType int
+
+const (
+	// graphqlEOF is returned once the end of the input has been reached.
+	graphqlEOF graphqlTokenType = iota
+
+	// graphqlPunctuator is one of ! $ & ( ) ... : = @ [ ] { | } or a comma.
+	graphqlPunctuator
+
+	// graphqlName is a name, such as a field, an operation name or a keyword.
+	graphqlName
+
+	// graphqlVariable is a variable reference, such as $id.
+	graphqlVariable
+
+	// graphqlInt is an integer literal.
+	graphqlInt
+
+	// graphqlFloat is a float literal.
+	graphqlFloat
+
+	// graphqlString is a string literal, quoted or block.
+	graphqlString
+
+	// graphqlPlaceholder is a "?" which replaced a literal in an already
+	// obfuscated query. It is not part of the GraphQL specification.
+	graphqlPlaceholder
+)
+
+// String implements fmt.Stringer.
+func (t graphqlTokenType) String() string {
+	return map[graphqlTokenType]string{
+		graphqlEOF:         "EOF",
+		graphqlPunctuator:  "punctuator",
+		graphqlName:        "name",
+		graphqlVariable:    "variable",
+		graphqlInt:         "int",
+		graphqlFloat:       "float",
+		graphqlString:      "string",
+		graphqlPlaceholder: "placeholder",
+	}[t]
+}
+
+// errGraphQLUnterminatedString is returned when a string literal is not closed.
+var errGraphQLUnterminatedString = errors.New("unterminated string")
+
+// graphqlTokenizer tokenizes a GraphQL document as described in the lexical
+// section of the specification: https://spec.graphql.org/October2021/#sec-Language.Source-Text
+// Ignored tokens (whitespace, line terminators, comments and the unicode BOM)
+// are skipped. Commas are insignificant in GraphQL but are returned as
+// punctuators so that the obfuscated output stays readable.
+type graphqlTokenizer struct {
+	data []byte
+	off  int
+}
+
+// newGraphQLTokenizer returns a new tokenizer for the given query.
+func newGraphQLTokenizer(query string) *graphqlTokenizer {
+	return &graphqlTokenizer{data: []byte(query)}
+}
+
+// scan returns the next token along with its type. An error is returned when
+// the input is not a valid GraphQL document.
+func (t *graphqlTokenizer) scan() (typ graphqlTokenType, tok string, err error) {
+	t.skipIgnored()
+	if t.off >= len(t.data) {
+		return graphqlEOF, "", nil
+	}
+	start := t.off
+	ch := t.data[t.off]
+	switch {
+	case isGraphQLNameStart(ch):
+		t.scanName()
+		return graphqlName, string(t.data[start:t.off]), nil
+	case ch == '$' && t.off+1 < len(t.data) && isGraphQLNameStart(t.data[t.off+1]):
+		t.off++
+		t.scanName()
+		return graphqlVariable, string(t.data[start:t.off]), nil
+	case ch == '-' || isDigit(rune(ch)):
+		return t.scanNumber()
+	case ch == '"':
+		if err := t.scanString(); err != nil {
+			return graphqlEOF, "", err
+		}
+		return graphqlString, string(t.data[start:t.off]), nil
+	case ch == '.':
+		if t.off+2 < len(t.data) && t.data[t.off+1] == '.' && t.data[t.off+2] == '.' {
+			t.off += 3
+			return graphqlPunctuator, "...", nil
+		}
+		return graphqlEOF, "", fmt.Errorf("unexpected character %q at position %d", ch, t.off)
+	}
+	switch ch {
+	case '?':
+		t.off++
+		return graphqlPlaceholder, "?", nil
+	case '!', '$', '&', '(', ')', ':', '=', '@', '[', ']', '{', '|', '}', ',':
+		t.off++
+		return graphqlPunctuator, string(ch), nil
+	}
+	return graphqlEOF, "", fmt.Errorf("unexpected character %q at position %d", ch, t.off)
+}
+
+// skipIgnored advances the tokenizer past any whitespace, line terminators,
+// comments and byte order marks.
+func (t *graphqlTokenizer) skipIgnored() {
+	for t.off < len(t.data) {
+		switch ch := t.data[t.off]; {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			t.off++
+		case ch == '#':
+			for t.off < len(t.data) && t.data[t.off] != '\n' && t.data[t.off] != '\r' {
+				t.off++
+			}
+		case ch == 0xEF && t.off+2 < len(t.data) && t.data[t.off+1] == 0xBB && t.data[t.off+2] == 0xBF:
+			// unicode BOM
+			t.off += 3
+		default:
+			return
+		}
+	}
+}
+
+// scanName advances the tokenizer past a name.
+func (t *graphqlTokenizer) scanName() {
+	for t.off < len(t.data) && isGraphQLNameContinue(t.data[t.off]) {
+		t.off++
+	}
+}
+
+// scanNumber scans an integer or float literal.
+func (t *graphqlTokenizer) scanNumber() (graphqlTokenType, string, error) {
+	start := t.off
+	typ := graphqlInt
+	if t.data[t.off] == '-' {
+		t.off++
+	}
+	if !t.scanDigits() {
+		return graphqlEOF, "", fmt.Errorf("invalid number at position %d", start)
+	}
+	if t.off < len(t.data) && t.data[t.off] == '.' {
+		typ = graphqlFloat
+		t.off++
+		if !t.scanDigits() {
+			return graphqlEOF, "", fmt.Errorf("invalid number at position %d", start)
+		}
+	}
+	if t.off < len(t.data) && (t.data[t.off] == 'e' || t.data[t.off] == 'E') {
+		typ = graphqlFloat
+		t.off++
+		if t.off < len(t.data) && (t.data[t.off] == '+' || t.data[t.off] == '-') {
+			t.off++
+		}
+		if !t.scanDigits() {
+			return graphqlEOF, "", fmt.Errorf("invalid number at position %d", start)
+		}
+	}
+	return typ, string(t.data[start:t.off]), nil
+}
+
+// scanDigits advances the tokenizer past a sequence of digits and reports
+// whether at least one digit was found.
+func (t *graphqlTokenizer) scanDigits() bool {
+	start := t.off
+	for t.off < len(t.data) && isDigit(rune(t.data[t.off])) {
+		t.off++
+	}
+	return t.off > start
+}
+
+// scanString advances the tokenizer past a quoted or block string.
+func (t *graphqlTokenizer) scanString() error {
+	if t.off+2 < len(t.data) && t.data[t.off+1] == '"' && t.data[t.off+2] == '"' {
+		// block string
+		t.off += 3
+		for t.off < len(t.data) {
+			switch {
+			case t.data[t.off] == '\\' && t.off+3 < len(t.data) && string(t.data[t.off+1:t.off+4]) == `"""`:
+				// escaped triple quote
+				t.off += 4
+			case t.off+2 < len(t.data) && string(t.data[t.off:t.off+3]) == `"""`:
+				t.off += 3
+				return nil
+			default:
+				t.off++
+			}
+		}
+		return errGraphQLUnterminatedString
+	}
+	t.off++
+	for t.off < len(t.data) {
+		switch t.data[t.off] {
+		case '\\':
+			t.off += 2
+		case '"':
+			t.off++
+			return nil
+		case '\n', '\r':
+			return errGraphQLUnterminatedString
+		default:
+			t.off++
+		}
+	}
+	return errGraphQLUnterminatedString
+}
+
+func isGraphQLNameStart(ch byte) bool {
+	return ch == '_' || (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+}
+
+func isGraphQLNameContinue(ch byte) bool {
+	return isGraphQLNameStart(ch) || (ch >= '0' && ch <= '9')
+}
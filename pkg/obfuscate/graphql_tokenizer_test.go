@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type graphqlToken struct {
+	typ graphqlTokenType
+	tok string
+}
+
+func TestGraphQLTokenizer(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		out []graphqlToken
+	}{
+		{
+			in: "\uFEFFquery Q($a: [Int!]! = [1, -2]) { f(b: 1.5e-3, c: \"x\\\"y\") }",
+			out: []graphqlToken{
+				{graphqlName, "query"},
+				{graphqlName, "Q"},
+				{graphqlPunctuator, "("},
+				{graphqlVariable, "$a"},
+				{graphqlPunctuator, ":"},
+				{graphqlPunctuator, "["},
+				{graphqlName, "Int"},
+				{graphqlPunctuator, "!"},
+				{graphqlPunctuator, "]"},
+				{graphqlPunctuator, "!"},
+				{graphqlPunctuator, "="},
+				{graphqlPunctuator, "["},
+				{graphqlInt, "1"},
+				{graphqlPunctuator, ","},
+				{graphqlInt, "-2"},
+				{graphqlPunctuator, "]"},
+				{graphqlPunctuator, ")"},
+				{graphqlPunctuator, "{"},
+				{graphqlName, "f"},
+				{graphqlPunctuator, "("},
+				{graphqlName, "b"},
+				{graphqlPunctuator, ":"},
+				{graphqlFloat, "1.5e-3"},
+				{graphqlPunctuator, ","},
+				{graphqlName, "c"},
+				{graphqlPunctuator, ":"},
+				{graphqlString, `"x\"y"`},
+				{graphqlPunctuator, ")"},
+				{graphqlPunctuator, "}"},
+			},
+		},
+		{
+			in: "# comment\n{ ...F ... on T @d { x } }\r\n",
+			out: []graphqlToken{
+				{graphqlPunctuator, "{"},
+				{graphqlPunctuator, "..."},
+				{graphqlName, "F"},
+				{graphqlPunctuator, "..."},
+				{graphqlName, "on"},
+				{graphqlName, "T"},
+				{graphqlPunctuator, "@"},
+				{graphqlName, "d"},
+				{graphqlPunctuator, "{"},
+				{graphqlName, "x"},
+				{graphqlPunctuator, "}"},
+				{graphqlPunctuator, "}"},
+			},
+		},
+		{
+			in: `{ f(s: """a \""" b "" c""") }`,
+			out: []graphqlToken{
+				{graphqlPunctuator, "{"},
+				{graphqlName, "f"},
+				{graphqlPunctuator, "("},
+				{graphqlName, "s"},
+				{graphqlPunctuator, ":"},
+				{graphqlString, `"""a \""" b "" c"""`},
+				{graphqlPunctuator, ")"},
+				{graphqlPunctuator, "}"},
+			},
+		},
+	} {
+		t.Run("", func(t *testing.T) {
+			var out []graphqlToken
+			tokenizer := newGraphQLTokenizer(tt.in)
+			for {
+				typ, tok, err := tokenizer.scan()
+				assert.NoError(t, err)
+				if err != nil || typ == graphqlEOF {
+					break
+				}
+				out = append(out, graphqlToken{typ, tok})
+			}
+			assert.Equal(t, tt.out, out)
+		})
+	}
+}
+
+func FuzzGraphQLTokenizeNumbers(f *testing.F) {
+	f.Add(int64(0), float64(0))
+	f.Add(int64(-1), float64(-0.123456789))
+	f.Add(int64(123456789), float64(12.3456789))
+	f.Fuzz(func(t *testing.T, i int64, fl float64) {
+		testGraphQLTokenizeNumber(t, strconv.FormatInt(i, 10), graphqlInt)
+		for _, format := range []byte{'e', 'E'} {
+			testGraphQLTokenizeNumber(t, strconv.FormatFloat(fl, format, -1, 64), graphqlFloat)
+		}
+	})
+}
+
+func testGraphQLTokenizeNumber(t *testing.T, input string, expected graphqlTokenType) {
+	typ, tok, err := newGraphQLTokenizer(input).scan()
+	if err != nil {
+		t.Fatalf("the value [%s] could not be tokenized: %v", input, err)
+	}
+	if typ != expected {
+		t.Errorf("the value [%s] was interpreted as %s instead of %s", input, typ, expected)
+	} else if input != tok {
+		t.Errorf("the value [%s] was incorrectly parsed to [%s]", input, tok)
+	}
+}
+
+func FuzzGraphQLTokenizer(f *testing.F) {
+	for _, s := range graphqlSuite {
+		f.Add(s.in)
+	}
+	f.Fuzz(func(t *testing.T, query string) {
+		tokenizer := newGraphQLTokenizer(query)
+		for i := 0; i <= len(query); i++ {
+			typ, _, err := tokenizer.scan()
+			if err != nil || typ == graphqlEOF {
+				return
+			}
+		}
+		t.Fatalf("tokenizer did not terminate on %q", query)
+	})
+}
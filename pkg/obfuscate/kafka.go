@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strings"
+)
+
+// kafkaPayloadTags holds the tags which hold the key or the value of a Kafka message.
+var kafkaPayloadTags = map[string]bool{
+	"messaging.kafka.message.key": true,
+	"messaging.kafka.message_key": true,
+	"kafka.message.key":           true,
+	"kafka.message_key":           true,
+	"messaging.message.body":      true,
+	"kafka.message.value":         true,
+}
+
+// kafkaHeaderTagPrefixes holds the prefixes of the tags which hold the value of a Kafka message header.
+var kafkaHeaderTagPrefixes = []string{
+	"messaging.kafka.message.header.",
+	"messaging.header.",
+	"kafka.header.",
+}
+
+// kafkaKeptHeaders holds the headers used for context propagation, which are never obfuscated.
+var kafkaKeptHeaders = map[string]bool{
+	"x-datadog-trace-id":          true,
+	"x-datadog-parent-id":         true,
+	"x-datadog-sampling-priority": true,
+	"x-datadog-origin":            true,
+	"x-datadog-tags":              true,
+	"traceparent":                 true,
+	"tracestate":                  true,
+	"dd-pathway-ctx":              true,
+	"dd-pathway-ctx-base64":       true,
+}
+
+type kafkaObfuscator struct {
+	keepHeaders map[string]bool
+}
+
+func newKafkaObfuscator(cfg *KafkaConfig) *kafkaObfuscator {
+	keep := make(map[string]bool, len(kafkaKeptHeaders)+len(cfg.KeepHeaders))
+	for h := range kafkaKeptHeaders {
+		keep[h] = true
+	}
+	for _, h := range cfg.KeepHeaders {
+		keep[strings.ToLower(h)] = true
+	}
+	return &kafkaObfuscator{keepHeaders: keep}
+}
+
+// ObfuscateKafkaTag obfuscates the value of the given tag if it holds the key or the value of a
+// Kafka message, or one of its headers. Headers used for context propagation, as well as those
+// listed in KafkaConfig.KeepHeaders, are kept. Other tags are returned unchanged.
+func (o *Obfuscator) ObfuscateKafkaTag(k, v string) string {
+	if o.kafka == nil || v == "" {
+		return v
+	}
+	if kafkaPayloadTags[k] {
+		return "?"
+	}
+	for _, prefix := range kafkaHeaderTagPrefixes {
+		if header, ok := strings.CutPrefix(k, prefix); ok {
+			if o.kafka.keepHeaders[strings.ToLower(header)] {
+				return v
+			}
+			return "?"
+		}
+	}
+	return v
+}
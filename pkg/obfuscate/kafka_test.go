@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateKafkaTag(t *testing.T) {
+	o := NewObfuscator(Config{Kafka: KafkaConfig{Enabled: true, KeepHeaders: []string{"Content-Type"}}})
+	for _, tt := range []struct {
+		k, v, out string
+	}{
+		{"messaging.kafka.message.key", "user-1234", "?"},
+		{"kafka.message_key", "user-1234", "?"},
+		{"messaging.message.body", `{"email":"dev@datadoghq.com"}`, "?"},
+		{"messaging.kafka.message.header.session", "abcdef", "?"},
+		{"messaging.header.session", "abcdef", "?"},
+		{"messaging.kafka.message.header.traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"},
+		{"kafka.header.x-datadog-trace-id", "1234", "1234"},
+		{"kafka.header.content-type", "application/json", "application/json"},
+		{"messaging.kafka.message.key", "", ""},
+		{"messaging.destination.name", "orders", "orders"},
+		{"messaging.kafka.partition", "3", "3"},
+	} {
+		t.Run(tt.k, func(t *testing.T) {
+			assert.Equal(t, tt.out, o.ObfuscateKafkaTag(tt.k, tt.v))
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		o := NewObfuscator(Config{})
+		assert.Equal(t, "user-1234", o.ObfuscateKafkaTag("messaging.kafka.message.key", "user-1234"))
+	})
+}
@@ -28,12 +28,14 @@ const Version = 1
 // concurrent use.
 type Obfuscator struct {
 	opts                 *Config
-	es                   *jsonObfuscator // nil if disabled
-	openSearch           *jsonObfuscator // nil if disabled
-	mongo                *jsonObfuscator // nil if disabled
-	sqlExecPlan          *jsonObfuscator // nil if disabled
-	sqlExecPlanNormalize *jsonObfuscator // nil if disabled
-	ccObfuscator         *creditCard     // nil if disabled
+	es                   *jsonObfuscator  // nil if disabled
+	openSearch           *jsonObfuscator  // nil if disabled
+	mongo                *jsonObfuscator  // nil if disabled
+	sqlExecPlan          *jsonObfuscator  // nil if disabled
+	sqlExecPlanNormalize *jsonObfuscator  // nil if disabled
+	ccObfuscator         *creditCard      // nil if disabled
+	dynamoDB             *jsonObfuscator  // nil if disabled
+	kafka                *kafkaObfuscator // nil if disabled
 	// sqlLiteralEscapes reports whether we should treat escape characters literally or as escape characters.
 	// Different SQL engines behave in different ways and the tokenizer needs to be generic.
 	sqlLiteralEscapes *atomic.Bool
@@ -101,6 +103,18 @@ type Config struct {
 	// Memcached holds the obfuscation settings for Memcached commands.
 	Memcached MemcachedConfig `mapstructure:"memcached"`
 
+	// GraphQL holds the obfuscation settings for GraphQL queries.
+	GraphQL GraphQLConfig `mapstructure:"graphql"`
+
+	// CQL holds the obfuscation settings for Cassandra Query Language statements.
+	CQL CQLConfig `mapstructure:"cql"`
+
+	// DynamoDB holds the obfuscation settings for DynamoDB PartiQL statements and request bodies.
+	DynamoDB DynamoDBConfig `mapstructure:"dynamodb"`
+
+	// Kafka holds the obfuscation settings for Kafka message keys, values and headers.
+	Kafka KafkaConfig `mapstructure:"kafka"`
+
 	// Memcached holds the obfuscation settings for obfuscation of CC numbers in meta.
 	CreditCard CreditCardsConfig `mapstructure:"credit_cards"`
 
@@ -259,6 +273,43 @@ type MemcachedConfig struct {
 	KeepCommand bool `mapstructure:"keep_command"`
 }
 
+// GraphQLConfig holds the configuration settings for GraphQL obfuscation
+type GraphQLConfig struct {
+	// Enabled specifies whether this feature should be enabled.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// CQLConfig holds the configuration settings for Cassandra Query Language obfuscation
+type CQLConfig struct {
+	// Enabled specifies whether this feature should be enabled.
+	Enabled bool `mapstructure:"enabled"`
+
+	// RemoveKeyspace specifies whether keyspace qualifiers should be removed from
+	// table names (e.g. "ks.users" becomes "users"), so that statements addressing
+	// the same table in different keyspaces are normalized to the same query.
+	RemoveKeyspace bool `mapstructure:"remove_keyspace"`
+}
+
+// DynamoDBConfig holds the configuration settings for DynamoDB obfuscation
+type DynamoDBConfig struct {
+	// Enabled specifies whether this feature should be enabled.
+	Enabled bool `mapstructure:"enabled"`
+
+	// KeepValues specifies request parameters for which values should not be
+	// obfuscated, in addition to those which never hold attribute values.
+	KeepValues []string `mapstructure:"keep_values"`
+}
+
+// KafkaConfig holds the configuration settings for Kafka message obfuscation
+type KafkaConfig struct {
+	// Enabled specifies whether this feature should be enabled.
+	Enabled bool `mapstructure:"enabled"`
+
+	// KeepHeaders specifies message headers for which values should not be
+	// obfuscated, in addition to those used for context propagation.
+	KeepHeaders []string `mapstructure:"keep_headers"`
+}
+
 // JSONConfig holds the obfuscation configuration for sensitive
 // data found in JSON objects.
 type JSONConfig struct {
@@ -328,6 +379,12 @@ func NewObfuscator(cfg Config) *Obfuscator {
 	if cfg.CreditCard.Enabled {
 		o.ccObfuscator = newCCObfuscator(&cfg.CreditCard)
 	}
+	if cfg.DynamoDB.Enabled {
+		o.dynamoDB = newDynamoDBObfuscator(&cfg.DynamoDB, &o)
+	}
+	if cfg.Kafka.Enabled {
+		o.kafka = newKafkaObfuscator(&cfg.Kafka)
+	}
 	if cfg.Statsd == nil {
 		cfg.Statsd = &statsd.NoOpClient{}
 	}
@@ -95,6 +95,8 @@ type Agent struct {
 	RareSampler           *sampler.RareSampler
 	NoPrioritySampler     *sampler.NoPrioritySampler
 	ProbabilisticSampler  *sampler.ProbabilisticSampler
+	LatencySampler        *sampler.LatencySampler
+	BytesBudget           *sampler.BytesBudget
 	SamplerMetrics        *sampler.Metrics
 	EventProcessor        *event.Processor
 	TraceWriter           TraceWriter
@@ -102,6 +104,7 @@ type Agent struct {
 	RemoteConfigHandler   *remoteconfighandler.RemoteConfigHandler
 	TelemetryCollector    telemetry.TelemetryCollector
 	DebugServer           *api.DebugServer
+	TraceInspector        *api.TraceInspector
 	Statsd                statsd.ClientInterface
 	Timing                timing.Reporter
 
@@ -128,6 +131,10 @@ type Agent struct {
 	ctx context.Context
 
 	firstSpanMap sync.Map
+
+	// inspections holds the chunks inspected by the TraceInspector while they
+	// go through the samplers, keyed by *pb.TraceChunk.
+	inspections sync.Map
 }
 
 // SpanModifier is an interface that allows to modify spans while they are
@@ -140,6 +147,7 @@ type SpanModifier interface {
 // which may be cancelled in order to gracefully stop the agent.
 func NewAgent(ctx context.Context, conf *config.AgentConfig, telemetryCollector telemetry.TelemetryCollector, statsd statsd.ClientInterface, comp compression.Component) *Agent {
 	dynConf := sampler.NewDynamicConfig()
+	dynConf.BytesBudget = sampler.NewBytesBudget(conf.BytesBudgetPerMinute)
 	log.Infof("Starting Agent with processor trace buffer of size %d", conf.TraceBuffer)
 	in := make(chan *api.Payload, conf.TraceBuffer)
 	oconf := conf.Obfuscation.Export(conf)
@@ -154,10 +162,12 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig, telemetryCollector
 		Blacklister:           filters.NewBlacklister(conf.Ignore["resource"]),
 		Replacer:              filters.NewReplacer(conf.ReplaceTags),
 		PrioritySampler:       sampler.NewPrioritySampler(conf, dynConf),
-		ErrorsSampler:         sampler.NewErrorsSampler(conf),
-		RareSampler:           sampler.NewRareSampler(conf),
-		NoPrioritySampler:     sampler.NewNoPrioritySampler(conf),
+		ErrorsSampler:         sampler.NewErrorsSampler(conf, dynConf),
+		RareSampler:           sampler.NewRareSampler(conf, dynConf),
+		NoPrioritySampler:     sampler.NewNoPrioritySampler(conf, dynConf),
 		ProbabilisticSampler:  sampler.NewProbabilisticSampler(conf),
+		LatencySampler:        sampler.NewLatencySampler(conf, dynConf),
+		BytesBudget:           dynConf.BytesBudget,
 		SamplerMetrics:        sampler.NewMetrics(statsd),
 		EventProcessor:        newEventProcessor(conf, statsd),
 		StatsWriter:           statsWriter,
@@ -166,10 +176,11 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig, telemetryCollector
 		conf:                  conf,
 		ctx:                   ctx,
 		DebugServer:           api.NewDebugServer(conf),
+		TraceInspector:        api.NewTraceInspector(),
 		Statsd:                statsd,
 		Timing:                timing,
 	}
-	agnt.SamplerMetrics.Add(agnt.PrioritySampler, agnt.ErrorsSampler, agnt.NoPrioritySampler, agnt.RareSampler)
+	agnt.SamplerMetrics.Add(agnt.PrioritySampler, agnt.ErrorsSampler, agnt.NoPrioritySampler, agnt.RareSampler, agnt.LatencySampler, agnt.BytesBudget)
 	agnt.Receiver = api.NewHTTPReceiver(conf, dynConf, in, agnt, telemetryCollector, statsd, timing)
 	agnt.OTLPReceiver = api.NewOTLPReceiver(in, conf, statsd, timing)
 	agnt.RemoteConfigHandler = remoteconfighandler.New(conf, agnt.PrioritySampler, agnt.RareSampler, agnt.ErrorsSampler)
@@ -330,13 +341,16 @@ func (a *Agent) Process(p *api.Payload) {
 
 		tracen := int64(len(chunk.Spans))
 		ts.SpansReceived.Add(tracen)
+		insp := a.startInspection(now, chunk)
 		err := a.normalizeTrace(p.Source, chunk.Spans)
 		if err != nil {
 			log.Debugf("Dropping invalid trace: %s", err)
 			ts.SpansDropped.Add(tracen)
+			a.dropInspected(insp, nil, "invalid", err.Error())
 			p.RemoveChunk(i)
 			continue
 		}
+		insp.normalized(chunk.Spans)
 
 		// Root span is used to carry some trace-level metadata, such as sampling rate and priority.
 		root := traceutil.GetRoot(chunk.Spans)
@@ -345,6 +359,7 @@ func (a *Agent) Process(p *api.Payload) {
 			log.Debugf("Trace rejected by ignore resources rules. root: %v matching rule: \"%s\"", root, denyingRule.String())
 			ts.TracesFiltered.Inc()
 			ts.SpansFiltered.Add(tracen)
+			a.dropInspected(insp, root, "ignore_resources", denyingRule.String())
 			p.RemoveChunk(i)
 			continue
 		}
@@ -353,6 +368,7 @@ func (a *Agent) Process(p *api.Payload) {
 			log.Debugf("Trace rejected as it fails to meet tag requirements. root: %v", root)
 			ts.TracesFiltered.Inc()
 			ts.SpansFiltered.Add(tracen)
+			a.dropInspected(insp, root, "filter_tags", "trace does not meet the tag requirements")
 			p.RemoveChunk(i)
 			continue
 		}
@@ -370,6 +386,7 @@ func (a *Agent) Process(p *api.Payload) {
 				a.SpanModifier.ModifySpan(chunk, span)
 			}
 			a.obfuscateSpan(span)
+			insp.truncating(a, span)
 			a.Truncate(span)
 			if p.ClientComputedTopLevel {
 				traceutil.UpdateTracerTopLevel(span)
@@ -391,7 +408,9 @@ func (a *Agent) Process(p *api.Payload) {
 			statsInput.Traces = append(statsInput.Traces, *pt.Clone())
 		}
 
+		insp = a.beforeSampling(insp, pt)
 		keep, numEvents := a.sample(now, ts, pt)
+		a.afterSampling(insp, pt, keep)
 		if !keep && len(pt.TraceChunk.Spans) == 0 {
 			// The entire trace was dropped and no spans were kept.
 			p.RemoveChunk(i)
@@ -408,7 +427,9 @@ func (a *Agent) Process(p *api.Payload) {
 			sampledChunks.SpanCount += int64(len(pt.TraceChunk.Spans))
 		}
 		sampledChunks.EventCount += int64(numEvents)
-		sampledChunks.Size += pt.TraceChunk.Msgsize()
+		size := pt.TraceChunk.Msgsize()
+		sampledChunks.Size += size
+		a.BytesBudget.Count(now, size)
 		i++
 
 		if sampledChunks.Size > writer.MaxPayloadSize {
@@ -634,26 +655,32 @@ func (a *Agent) getAnalyzedEvents(pt *traceutil.ProcessedTrace, ts *info.TagStat
 // Otherwise, the rare sampler is run first, catching all rare traces early. If the probabilistic sampler is
 // enabled, it is run on the trace, followed by the error sampler. Otherwise, If the trace has a
 // priority set, the sampling priority is used with the Priority Sampler. When there is no priority
-// set, the NoPrioritySampler is run. Finally, if the trace has not been sampled by the other
-// samplers, the error sampler is run.
+// set, the NoPrioritySampler is run. If the latency sampler is enabled, slow traces which were not
+// sampled are then kept by it. Finally, if the trace has not been sampled by the other samplers,
+// the error sampler is run.
 func (a *Agent) runSamplers(now time.Time, ts *info.TagStats, pt traceutil.ProcessedTrace) (keep bool, checkAnalyticsEvents bool) {
 	samplerName := sampler.NameUnknown
 	samplingPriority := sampler.PriorityNone
 	defer func() {
 		a.SamplerMetrics.RecordMetricsKey(keep, sampler.NewMetricsKey(pt.Root.Service, pt.TracerEnv, samplerName, samplingPriority))
+		if a.TraceInspector.Active() {
+			a.inspectSamplingDecision(pt.TraceChunk, samplerName, keep)
+		}
 	}()
 	// ETS: chunks that don't contain errors (or spans with exception span events) are all dropped.
 	if a.conf.ErrorTrackingStandalone {
 		samplerName = sampler.NameError
 		if traceContainsError(pt.TraceChunk.Spans, true) {
 			pt.TraceChunk.Tags["_dd.error_tracking_standalone.error"] = "true"
-			return a.ErrorsSampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv), false
+			return a.inspectVerdict(pt.TraceChunk, sampler.NameError, a.ErrorsSampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv)), false
 		}
 		return false, false
 	}
 
 	// Run this early to make sure the signature gets counted by the RareSampler.
-	rare := a.RareSampler.Sample(now, pt.TraceChunk, pt.TracerEnv)
+	rare := a.inspectVerdict(pt.TraceChunk, sampler.NameRare, a.RareSampler.Sample(now, pt.TraceChunk, pt.TracerEnv))
+	// Likewise, the latency of every chunk must be recorded by the LatencySampler.
+	slow := a.conf.LatencySamplerEnabled && a.LatencySampler.IsSlow(now, pt.Root, pt.TracerEnv)
 
 	if a.conf.ProbabilisticSamplerEnabled {
 		samplerName = sampler.NameProbabilistic
@@ -661,13 +688,17 @@ func (a *Agent) runSamplers(now time.Time, ts *info.TagStats, pt traceutil.Proce
 			samplerName = sampler.NameRare
 			return true, true
 		}
-		if a.ProbabilisticSampler.Sample(pt.Root) {
+		if a.inspectVerdict(pt.TraceChunk, sampler.NameProbabilistic, a.ProbabilisticSampler.Sample(pt.Root)) {
 			pt.TraceChunk.Tags[tagDecisionMaker] = probabilitySampling
 			return true, true
 		}
+		if slow && a.inspectVerdict(pt.TraceChunk, sampler.NameLatency, a.LatencySampler.Sample(now, pt.Root)) {
+			samplerName = sampler.NameLatency
+			return true, true
+		}
 		if traceContainsError(pt.TraceChunk.Spans, false) {
 			samplerName = sampler.NameError
-			return a.ErrorsSampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv), true
+			return a.inspectVerdict(pt.TraceChunk, sampler.NameError, a.ErrorsSampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv)), true
 		}
 		return false, true
 	}
@@ -703,16 +734,21 @@ func (a *Agent) runSamplers(now time.Time, ts *info.TagStats, pt traceutil.Proce
 	}
 
 	if hasPriority {
-		if a.PrioritySampler.Sample(now, pt.TraceChunk, pt.Root, pt.TracerEnv, pt.ClientDroppedP0sWeight) {
+		if a.inspectVerdict(pt.TraceChunk, sampler.NamePriority, a.PrioritySampler.Sample(now, pt.TraceChunk, pt.Root, pt.TracerEnv, pt.ClientDroppedP0sWeight)) {
 			return true, true
 		}
-	} else if a.NoPrioritySampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv) {
+	} else if a.inspectVerdict(pt.TraceChunk, sampler.NameNoPriority, a.NoPrioritySampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv)) {
+		return true, true
+	}
+
+	if slow && a.inspectVerdict(pt.TraceChunk, sampler.NameLatency, a.LatencySampler.Sample(now, pt.Root)) {
+		samplerName = sampler.NameLatency
 		return true, true
 	}
 
 	if traceContainsError(pt.TraceChunk.Spans, false) {
 		samplerName = sampler.NameError
-		return a.ErrorsSampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv), true
+		return a.inspectVerdict(pt.TraceChunk, sampler.NameError, a.ErrorsSampler.Sample(now, pt.TraceChunk.Spans, pt.Root, pt.TracerEnv)), true
 	}
 
 	return false, true
@@ -731,6 +767,11 @@ func spanContainsExceptionSpanEvent(span *pb.Span) bool {
 	if hasExceptionSpanEvents, ok := span.Meta["_dd.span_events.has_exception"]; ok && hasExceptionSpanEvents == "true" {
 		return true
 	}
+	for _, event := range span.SpanEvents {
+		if event.Name == "exception" {
+			return true
+		}
+	}
 	return false
 }
 
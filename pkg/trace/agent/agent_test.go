@@ -1041,20 +1041,20 @@ func TestSampling(t *testing.T) {
 		}
 
 		a := &Agent{
-			NoPrioritySampler:    sampler.NewNoPrioritySampler(cfg),
-			ErrorsSampler:        sampler.NewErrorsSampler(cfg),
+			NoPrioritySampler:    sampler.NewNoPrioritySampler(cfg, sampler.NewDynamicConfig()),
+			ErrorsSampler:        sampler.NewErrorsSampler(cfg, sampler.NewDynamicConfig()),
 			PrioritySampler:      sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
-			RareSampler:          sampler.NewRareSampler(cfg),
+			RareSampler:          sampler.NewRareSampler(cfg, sampler.NewDynamicConfig()),
 			ProbabilisticSampler: sampler.NewProbabilisticSampler(cfg),
 			SamplerMetrics:       sampler.NewMetrics(statsd),
 			conf:                 cfg,
 		}
 		a.SamplerMetrics.Add(a.NoPrioritySampler, a.ErrorsSampler, a.PrioritySampler, a.RareSampler)
 		if ac.errorsSampled {
-			a.ErrorsSampler = sampler.NewErrorsSampler(sampledCfg)
+			a.ErrorsSampler = sampler.NewErrorsSampler(sampledCfg, sampler.NewDynamicConfig())
 		}
 		if ac.noPrioritySampled {
-			a.NoPrioritySampler = sampler.NewNoPrioritySampler(sampledCfg)
+			a.NoPrioritySampler = sampler.NewNoPrioritySampler(sampledCfg, sampler.NewDynamicConfig())
 		}
 		return a
 	}
@@ -1810,10 +1810,10 @@ func TestSampleTrace(t *testing.T) {
 			statsd := mockStatsd.NewMockClientInterface(ctrl)
 			metrics := sampler.NewMetrics(statsd)
 			a := &Agent{
-				NoPrioritySampler: sampler.NewNoPrioritySampler(cfg),
-				ErrorsSampler:     sampler.NewErrorsSampler(cfg),
+				NoPrioritySampler: sampler.NewNoPrioritySampler(cfg, sampler.NewDynamicConfig()),
+				ErrorsSampler:     sampler.NewErrorsSampler(cfg, sampler.NewDynamicConfig()),
 				PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
-				RareSampler:       sampler.NewRareSampler(config.New()),
+				RareSampler:       sampler.NewRareSampler(config.New(), sampler.NewDynamicConfig()),
 				EventProcessor:    newEventProcessor(cfg, statsd),
 				SamplerMetrics:    metrics,
 				conf:              cfg,
@@ -1943,10 +1943,10 @@ func TestSample(t *testing.T) {
 	for name, tt := range tests {
 		cfg.ErrorTrackingStandalone = tt.etsEnabled
 		a := &Agent{
-			NoPrioritySampler: sampler.NewNoPrioritySampler(cfg),
-			ErrorsSampler:     sampler.NewErrorsSampler(cfg),
+			NoPrioritySampler: sampler.NewNoPrioritySampler(cfg, sampler.NewDynamicConfig()),
+			ErrorsSampler:     sampler.NewErrorsSampler(cfg, sampler.NewDynamicConfig()),
 			PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
-			RareSampler:       sampler.NewRareSampler(config.New()),
+			RareSampler:       sampler.NewRareSampler(config.New(), sampler.NewDynamicConfig()),
 			EventProcessor:    newEventProcessor(cfg, statsd),
 			SamplerMetrics:    sampler.NewMetrics(statsd),
 			conf:              cfg,
@@ -1981,10 +1981,10 @@ func TestSampleManualUserDropNoAnalyticsEvents(t *testing.T) {
 	pt.TraceChunk.Priority = -1
 	statsd := &statsd.NoOpClient{}
 	a := &Agent{
-		NoPrioritySampler: sampler.NewNoPrioritySampler(cfg),
-		ErrorsSampler:     sampler.NewErrorsSampler(cfg),
+		NoPrioritySampler: sampler.NewNoPrioritySampler(cfg, sampler.NewDynamicConfig()),
+		ErrorsSampler:     sampler.NewErrorsSampler(cfg, sampler.NewDynamicConfig()),
 		PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
-		RareSampler:       sampler.NewRareSampler(config.New()),
+		RareSampler:       sampler.NewRareSampler(config.New(), sampler.NewDynamicConfig()),
 		EventProcessor:    newEventProcessor(cfg, statsd),
 		SamplerMetrics:    sampler.NewMetrics(statsd),
 		conf:              cfg,
@@ -1994,6 +1994,57 @@ func TestSampleManualUserDropNoAnalyticsEvents(t *testing.T) {
 	assert.Empty(t, pt.Root.Metrics["_dd.analyzed"])
 }
 
+func TestSampleLatency(t *testing.T) {
+	now := time.Now()
+	cfg := &config.AgentConfig{
+		TargetTPS:                 5,
+		ErrorTPS:                  1000,
+		Features:                  make(map[string]struct{}),
+		LatencySamplerEnabled:     true,
+		LatencySamplerPercentile:  0.9,
+		LatencySamplerTPS:         10,
+		LatencySamplerCardinality: 10,
+	}
+	statsd := &statsd.NoOpClient{}
+	a := &Agent{
+		NoPrioritySampler: sampler.NewNoPrioritySampler(cfg, sampler.NewDynamicConfig()),
+		ErrorsSampler:     sampler.NewErrorsSampler(cfg, sampler.NewDynamicConfig()),
+		PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
+		RareSampler:       sampler.NewRareSampler(config.New(), sampler.NewDynamicConfig()),
+		LatencySampler:    sampler.NewLatencySampler(cfg, &sampler.DynamicConfig{}),
+		EventProcessor:    newEventProcessor(cfg, statsd),
+		SamplerMetrics:    sampler.NewMetrics(statsd),
+		conf:              cfg,
+	}
+	chunk := func(d time.Duration) *traceutil.ProcessedTrace {
+		root := &pb.Span{
+			Service:  "serv1",
+			Resource: "GET /",
+			Start:    now.UnixNano(),
+			Duration: d.Nanoseconds(),
+			Metrics:  map[string]float64{"_top_level": 1},
+		}
+		pt := &traceutil.ProcessedTrace{TraceChunk: testutil.TraceChunkWithSpan(root), Root: root}
+		pt.TraceChunk.Priority = int32(sampler.PriorityAutoDrop)
+		return pt
+	}
+	for i := 100; i > 0; i-- {
+		keep, _ := a.sample(now, info.NewReceiverStats().GetTagStats(info.Tags{}), chunk(time.Duration(i)*time.Millisecond))
+		assert.False(t, keep)
+	}
+	pt := chunk(time.Second)
+	keep, _ := a.sample(now, info.NewReceiverStats().GetTagStats(info.Tags{}), pt)
+	assert.True(t, keep)
+	assert.False(t, pt.TraceChunk.DroppedTrace)
+	assert.Equal(t, 1.0, pt.Root.Metrics["_dd.latency"])
+
+	// user drops are respected
+	pt = chunk(time.Second)
+	pt.TraceChunk.Priority = int32(sampler.PriorityUserDrop)
+	keep, _ = a.sample(now, info.NewReceiverStats().GetTagStats(info.Tags{}), pt)
+	assert.False(t, keep)
+}
+
 func TestPartialSamplingFree(t *testing.T) {
 	cfg := &config.AgentConfig{RareSamplerEnabled: false, BucketInterval: 10 * time.Second}
 	dynConf := sampler.NewDynamicConfig()
@@ -2003,11 +2054,11 @@ func TestPartialSamplingFree(t *testing.T) {
 		Concentrator:      &mockConcentrator{},
 		Blacklister:       filters.NewBlacklister(cfg.Ignore["resource"]),
 		Replacer:          filters.NewReplacer(cfg.ReplaceTags),
-		NoPrioritySampler: sampler.NewNoPrioritySampler(cfg),
-		ErrorsSampler:     sampler.NewErrorsSampler(cfg),
+		NoPrioritySampler: sampler.NewNoPrioritySampler(cfg, sampler.NewDynamicConfig()),
+		ErrorsSampler:     sampler.NewErrorsSampler(cfg, sampler.NewDynamicConfig()),
 		PrioritySampler:   sampler.NewPrioritySampler(cfg, &sampler.DynamicConfig{}),
 		EventProcessor:    newEventProcessor(cfg, statsd),
-		RareSampler:       sampler.NewRareSampler(config.New()),
+		RareSampler:       sampler.NewRareSampler(config.New(), sampler.NewDynamicConfig()),
 		SamplerMetrics:    sampler.NewMetrics(statsd),
 		TraceWriter:       &mockTraceWriter{},
 		conf:              cfg,
@@ -3435,3 +3486,10 @@ func TestUpdateAPIKey(t *testing.T) {
 	tw := agnt.TraceWriter.(*mockTraceWriter)
 	assert.Equal(t, "foo", tw.apiKey)
 }
+
+func TestSpanContainsExceptionSpanEvent(t *testing.T) {
+	assert.False(t, spanContainsExceptionSpanEvent(&pb.Span{Meta: map[string]string{}}))
+	assert.True(t, spanContainsExceptionSpanEvent(&pb.Span{Meta: map[string]string{"_dd.span_events.has_exception": "true"}}))
+	assert.True(t, spanContainsExceptionSpanEvent(&pb.Span{SpanEvents: []*pb.SpanEvent{{Name: "retry"}, {Name: "exception"}}}))
+	assert.False(t, spanContainsExceptionSpanEvent(&pb.Span{SpanEvents: []*pb.SpanEvent{{Name: "retry"}}}))
+}
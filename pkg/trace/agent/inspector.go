@@ -0,0 +1,216 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"fmt"
+	"time"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/api"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+// inspection collects the decisions taken by the agent while processing a trace chunk,
+// in order to publish them to the trace inspector. Inspections are only created while
+// a client is streaming from the trace inspector.
+type inspection struct {
+	chunk *api.InspectedChunk
+	// spans holds the spans of the chunk as they were received, before normalization.
+	spans []spanSnapshot
+}
+
+// spanSnapshot holds the fields of a span which may be changed by the normalizer.
+type spanSnapshot struct {
+	spanID        uint64
+	service       string
+	name          string
+	typ           string
+	emptyResource bool
+	start         int64
+	duration      int64
+}
+
+// newInspection returns an inspection for the given chunk, taking a snapshot of its spans.
+func newInspection(now time.Time, spans []*pb.Span) *inspection {
+	in := &inspection{
+		chunk: &api.InspectedChunk{Time: now, Spans: len(spans)},
+		spans: make([]spanSnapshot, len(spans)),
+	}
+	for i, s := range spans {
+		in.spans[i] = spanSnapshot{
+			spanID:        s.SpanID,
+			service:       s.Service,
+			name:          s.Name,
+			typ:           s.Type,
+			emptyResource: s.Resource == "",
+			start:         s.Start,
+			duration:      s.Duration,
+		}
+	}
+	return in
+}
+
+// normalized records the changes made by the normalizer to the given spans.
+func (in *inspection) normalized(spans []*pb.Span) {
+	if in == nil {
+		return
+	}
+	for i, s := range spans {
+		if i >= len(in.spans) {
+			break
+		}
+		before := in.spans[i]
+		if before.service != s.Service {
+			in.note(&in.chunk.Normalized, "span %d: service %q -> %q", s.SpanID, before.service, s.Service)
+		}
+		if before.name != s.Name {
+			in.note(&in.chunk.Normalized, "span %d: name %q -> %q", s.SpanID, before.name, s.Name)
+		}
+		if before.typ != s.Type {
+			in.note(&in.chunk.Normalized, "span %d: type %q -> %q", s.SpanID, before.typ, s.Type)
+		}
+		if before.emptyResource && s.Resource != "" {
+			in.note(&in.chunk.Normalized, "span %d: empty resource set to %q", s.SpanID, s.Resource)
+		}
+		if before.start != s.Start {
+			in.note(&in.chunk.Normalized, "span %d: invalid start %d -> %d", s.SpanID, before.start, s.Start)
+		}
+		if before.duration != s.Duration {
+			in.note(&in.chunk.Normalized, "span %d: invalid duration %d -> %d", s.SpanID, before.duration, s.Duration)
+		}
+	}
+}
+
+// truncating records the values of s which are about to be truncated by Agent.Truncate.
+func (in *inspection) truncating(a *Agent, s *pb.Span) {
+	if in == nil {
+		return
+	}
+	if len(s.Resource) > a.conf.MaxResourceLen {
+		in.note(&in.chunk.Truncated, "span %d: resource truncated from %d to %d bytes", s.SpanID, len(s.Resource), a.conf.MaxResourceLen)
+	}
+	for k, v := range s.Meta {
+		if isStructuredMetaKey(k) {
+			continue
+		}
+		if len(k) > MaxMetaKeyLen {
+			in.note(&in.chunk.Truncated, "span %d: meta key %q... truncated to %d bytes", s.SpanID, k[:MaxMetaKeyLen], MaxMetaKeyLen)
+		}
+		if len(v) > MaxMetaValLen {
+			in.note(&in.chunk.Truncated, "span %d: meta %q truncated from %d to %d bytes", s.SpanID, k, len(v), MaxMetaValLen)
+		}
+	}
+	for k := range s.Metrics {
+		if len(k) > MaxMetricsKeyLen {
+			in.note(&in.chunk.Truncated, "span %d: metric key %q... truncated to %d bytes", s.SpanID, k[:MaxMetricsKeyLen], MaxMetricsKeyLen)
+		}
+	}
+}
+
+// note appends a formatted message to the given list.
+func (in *inspection) note(list *[]string, format string, args ...interface{}) {
+	*list = append(*list, fmt.Sprintf(format, args...))
+}
+
+// setRoot sets the fields describing the root span of the chunk.
+func (in *inspection) setRoot(root *pb.Span) {
+	in.chunk.TraceID = root.TraceID
+	in.chunk.Service = root.Service
+	in.chunk.Name = root.Name
+}
+
+// startInspection returns a new inspection of the given chunk if a client is streaming
+// from the trace inspector, nil otherwise.
+func (a *Agent) startInspection(now time.Time, chunk *pb.TraceChunk) *inspection {
+	if !a.TraceInspector.Active() {
+		return nil
+	}
+	in := newInspection(now, chunk.Spans)
+	// empty chunks have no root and are dropped by the normalizer
+	if root := traceutil.GetRoot(chunk.Spans); root != nil {
+		in.setRoot(root)
+	}
+	return in
+}
+
+// dropInspected publishes an inspected chunk which was dropped before reaching the samplers.
+func (a *Agent) dropInspected(in *inspection, root *pb.Span, dropped, reason string) {
+	if in == nil {
+		return
+	}
+	if root != nil {
+		in.setRoot(root)
+	}
+	in.chunk.Dropped = dropped
+	in.chunk.Reason = reason
+	a.TraceInspector.Publish(in.chunk)
+}
+
+// beforeSampling registers an inspected chunk right before it reaches the samplers, so
+// that the sampling decision is recorded by inspectSamplingDecision. It returns nil if no
+// client of the trace inspector is interested in the chunk.
+func (a *Agent) beforeSampling(in *inspection, pt *traceutil.ProcessedTrace) *inspection {
+	if in == nil || !a.TraceInspector.Wants(pt.Root.Service, pt.Root.Resource) {
+		return nil
+	}
+	in.setRoot(pt.Root)
+	in.chunk.Resource = pt.Root.Resource
+	in.chunk.Env = pt.TracerEnv
+	if a.conf.ProbabilisticSamplerEnabled {
+		if hash, threshold, ok := a.ProbabilisticSampler.HashBucket(pt.Root); ok {
+			in.chunk.Probabilistic = &api.ProbabilisticVerdict{
+				Hash:      hash,
+				Threshold: threshold,
+				Keep:      hash < threshold,
+			}
+		}
+	}
+	a.inspections.Store(pt.TraceChunk, in)
+	return in
+}
+
+// inspectSamplingDecision records the sampling decision taken on the chunk, if it is inspected.
+func (a *Agent) inspectSamplingDecision(chunk *pb.TraceChunk, samplerName sampler.Name, keep bool) {
+	v, ok := a.inspections.Load(chunk)
+	if !ok {
+		return
+	}
+	in := v.(*inspection)
+	in.chunk.Sampler = samplerName.String()
+	in.chunk.Kept = keep
+}
+
+// inspectVerdict records the decision of a single sampler on the chunk, if it is
+// inspected, and returns keep.
+func (a *Agent) inspectVerdict(chunk *pb.TraceChunk, samplerName sampler.Name, keep bool) bool {
+	if !a.TraceInspector.Active() {
+		return keep
+	}
+	if v, ok := a.inspections.Load(chunk); ok {
+		in := v.(*inspection)
+		in.chunk.Verdicts = append(in.chunk.Verdicts, api.SamplerVerdict{Sampler: samplerName.String(), Keep: keep})
+	}
+	return keep
+}
+
+// afterSampling publishes an inspected chunk once it went through the samplers.
+func (a *Agent) afterSampling(in *inspection, pt *traceutil.ProcessedTrace, keep bool) {
+	if in == nil {
+		return
+	}
+	a.inspections.Delete(pt.TraceChunk)
+	in.chunk.Kept = keep
+	in.chunk.KeptSpans = len(pt.TraceChunk.Spans)
+	if p, ok := sampler.GetSamplingPriority(pt.TraceChunk); ok {
+		priority := int32(p)
+		in.chunk.Priority = &priority
+	}
+	in.chunk.DecisionMaker = pt.TraceChunk.Tags[tagDecisionMaker]
+	in.chunk.Rates = sampler.SamplingRates(pt.Root)
+	a.TraceInspector.Publish(in.chunk)
+}
@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package agent
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/api"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
+	"github.com/DataDog/datadog-agent/pkg/trace/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/trace/testutil"
+)
+
+func TestTraceInspection(t *testing.T) {
+	cfg := config.New()
+	cfg.Endpoints[0].APIKey = "test"
+	cfg.Ignore["resource"] = []string{"^GET /health"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	agnt := NewTestAgent(ctx, cfg, telemetry.NewNoopCollector())
+
+	srv := httptest.NewServer(agnt.TraceInspector)
+	t.Cleanup(srv.Close)
+	resp, err := srv.Client().Post(srv.URL, "application/json", strings.NewReader(`{"service":"web"}`))
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Eventually(t, agnt.TraceInspector.Active, 5*time.Second, 10*time.Millisecond)
+	lines := bufio.NewScanner(resp.Body)
+	lines.Buffer(nil, 1<<20)
+	next := func() *api.InspectedChunk {
+		require.True(t, lines.Scan())
+		var c api.InspectedChunk
+		require.NoError(t, json.Unmarshal(lines.Bytes(), &c))
+		return &c
+	}
+
+	process := func(span *pb.Span, priority sampler.SamplingPriority) {
+		span.TraceID, span.SpanID = 1, 2
+		span.Start = time.Now().Add(-time.Second).UnixNano()
+		span.Duration = (500 * time.Millisecond).Nanoseconds()
+		chunk := testutil.TraceChunkWithSpan(span)
+		chunk.Priority = int32(priority)
+		agnt.Process(&api.Payload{
+			TracerPayload: testutil.TracerPayloadWithChunk(chunk),
+			Source:        info.NewReceiverStats().GetTagStats(info.Tags{}),
+		})
+	}
+
+	t.Run("sampled", func(t *testing.T) {
+		process(&pb.Span{
+			Service:  "Web",
+			Name:     "http.request",
+			Resource: "GET /users",
+			Meta:     map[string]string{"long": strings.Repeat("a", MaxMetaValLen+1)},
+		}, sampler.PriorityUserKeep)
+		c := next()
+		assert.Equal(t, uint64(1), c.TraceID)
+		assert.Equal(t, "web", c.Service)
+		assert.Equal(t, "GET /users", c.Resource)
+		assert.Equal(t, 1, c.Spans)
+		assert.Empty(t, c.Dropped)
+		assert.Equal(t, []string{`span 2: service "Web" -> "web"`}, c.Normalized)
+		assert.Len(t, c.Truncated, 1)
+		assert.Equal(t, "priority", c.Sampler)
+		assert.Equal(t, []api.SamplerVerdict{{Sampler: "rare", Keep: false}, {Sampler: "priority", Keep: true}}, c.Verdicts)
+		require.NotNil(t, c.Priority)
+		assert.EqualValues(t, sampler.PriorityUserKeep, *c.Priority)
+		assert.True(t, c.Kept)
+		assert.Equal(t, 1, c.KeptSpans)
+	})
+
+	t.Run("filtered", func(t *testing.T) {
+		// not matching the service filter
+		process(&pb.Span{Service: "db", Name: "query", Resource: "SELECT"}, sampler.PriorityUserKeep)
+		process(&pb.Span{Service: "web", Name: "http.request", Resource: "GET /health"}, sampler.PriorityAutoKeep)
+		c := next()
+		assert.Equal(t, "web", c.Service)
+		assert.Empty(t, c.Resource)
+		assert.Equal(t, "ignore_resources", c.Dropped)
+		assert.Equal(t, "^GET /health", c.Reason)
+		assert.Empty(t, c.Sampler)
+		assert.False(t, c.Kept)
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		process(&pb.Span{Service: "web", Name: "http.request", Resource: "GET /users"}, sampler.PriorityUserDrop)
+		c := next()
+		assert.Equal(t, "GET /users", c.Resource)
+		assert.False(t, c.Kept)
+		require.NotNil(t, c.Priority)
+		assert.EqualValues(t, sampler.PriorityUserDrop, *c.Priority)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		agnt.Process(&api.Payload{
+			TracerPayload: testutil.TracerPayloadWithChunk(testutil.TraceChunkWithSpans(nil)),
+			Source:        info.NewReceiverStats().GetTagStats(info.Tags{}),
+		})
+	})
+
+	// inspected chunks are forgotten once published
+	agnt.inspections.Range(func(_, _ any) bool {
+		t.Error("inspection was not removed")
+		return false
+	})
+}
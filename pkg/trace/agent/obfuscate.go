@@ -19,6 +19,7 @@ const (
 	tagRedisRawCommand  = transform.TagRedisRawCommand
 	tagMemcachedCommand = transform.TagMemcachedCommand
 	tagMongoDBQuery     = transform.TagMongoDBQuery
+	tagGraphQLQuery     = transform.TagGraphQLQuery
 	tagElasticBody      = transform.TagElasticBody
 	tagOpenSearchBody   = transform.TagOpenSearchBody
 	tagSQLQuery         = transform.TagSQLQuery
@@ -27,7 +28,8 @@ const (
 )
 
 const (
-	textNonParsable = transform.TextNonParsable
+	textNonParsable        = transform.TextNonParsable
+	textNonParsableGraphQL = transform.TextNonParsableGraphQL
 )
 
 func (a *Agent) obfuscateSpan(span *pb.Span) {
@@ -47,21 +49,29 @@ func (a *Agent) obfuscateSpan(span *pb.Span) {
 		}
 	}
 
+	if a.conf.Obfuscation != nil && a.conf.Obfuscation.DynamoDB.Enabled && transform.IsDynamoDBSpan(span) {
+		transform.ObfuscateDynamoDBSpan(o, span)
+	}
+
+	if a.conf.Obfuscation != nil && a.conf.Obfuscation.Kafka.Enabled && transform.IsKafkaSpan(span) {
+		transform.ObfuscateKafkaSpan(o, span)
+	}
+
 	switch span.Type {
-	case "sql", "cassandra":
-		if span.Resource == "" {
+	case "cassandra":
+		if !a.conf.Obfuscation.CQL.Enabled {
+			a.obfuscateSQLSpan(o, span)
 			return
 		}
-		oq, err := transform.ObfuscateSQLSpan(o, span)
-		if err != nil {
-			// we have an error, discard the SQL to avoid polluting user resources.
-			log.Debugf("Error parsing SQL query: %v. Resource: %q", err, span.Resource)
+		if span.Resource == "" {
 			return
 		}
-		if oq == nil {
-			// no error was thrown but no query was found/sanitized either
-			return
+		if _, err := transform.ObfuscateCQLSpan(o, span); err != nil {
+			// we have an error, discard the CQL to avoid polluting user resources.
+			log.Debugf("Error parsing CQL query: %v. Resource: %q", err, span.Resource)
 		}
+	case "sql":
+		a.obfuscateSQLSpan(o, span)
 	case "redis", "valkey":
 		// if a span is redis/valkey type, it should be quantized regardless of obfuscation setting.
 		// valkey is a folk of redis, so we can use the same logic for both.
@@ -85,6 +95,13 @@ func (a *Agent) obfuscateSpan(span *pb.Span) {
 			return
 		}
 		span.Meta[tagHTTPURL] = o.ObfuscateURLString(span.Meta[tagHTTPURL])
+	case "graphql":
+		if !a.conf.Obfuscation.GraphQL.Enabled {
+			return
+		}
+		if err := transform.ObfuscateGraphQLSpan(o, span); err != nil {
+			log.Debugf("Error parsing GraphQL query: %v. Resource: %q", err, span.Resource)
+		}
 	case "mongodb":
 		if !a.conf.Obfuscation.Mongo.Enabled {
 			return
@@ -110,6 +127,17 @@ func (a *Agent) obfuscateSpan(span *pb.Span) {
 	}
 }
 
+// obfuscateSQLSpan obfuscates the resource and SQL query of the given span.
+func (a *Agent) obfuscateSQLSpan(o *obfuscate.Obfuscator, span *pb.Span) {
+	if span.Resource == "" {
+		return
+	}
+	if _, err := transform.ObfuscateSQLSpan(o, span); err != nil {
+		// we have an error, discard the SQL to avoid polluting user resources.
+		log.Debugf("Error parsing SQL query: %v. Resource: %q", err, span.Resource)
+	}
+}
+
 // obfuscateSpanEvent uses the pre-configured agent obfuscator to do limited obfuscation of span events
 // For now, we only obfuscate any credit-card like when enabled.
 func (a *Agent) obfuscateSpanEvent(spanEvent *pb.SpanEvent) {
@@ -161,7 +189,16 @@ func (a *Agent) obfuscateStatsGroup(b *pb.ClientGroupedStats) {
 
 	switch b.Type {
 	case "sql", "cassandra":
-		oq, err := o.ObfuscateSQLStringForDBMS(b.Resource, b.DBType)
+		var (
+			oq  *obfuscate.ObfuscatedQuery
+			err error
+		)
+		if b.Type == "cassandra" && a.conf.Obfuscation != nil && a.conf.Obfuscation.CQL.Enabled {
+			// the keyspace is not known in stats groups, it only affects the collected tables
+			oq, err = o.ObfuscateCQLString(b.Resource, "")
+		} else {
+			oq, err = o.ObfuscateSQLStringForDBMS(b.Resource, b.DBType)
+		}
 		if err != nil {
 			log.Errorf("Error obfuscating stats group resource %q: %v", b.Resource, err)
 			b.Resource = textNonParsable
@@ -170,6 +207,10 @@ func (a *Agent) obfuscateStatsGroup(b *pb.ClientGroupedStats) {
 		}
 	case "redis", "valkey":
 		b.Resource = o.QuantizeRedisString(b.Resource)
+	case "graphql":
+		if a.conf.Obfuscation != nil && a.conf.Obfuscation.GraphQL.Enabled {
+			b.Resource = transform.QuantizeGraphQLResource(o, b.Resource)
+		}
 	}
 }
 
@@ -45,15 +45,37 @@ func TestObfuscateStatsGroup(t *testing.T) {
 		{statsGroup("sql", "SELECT 1\nFROM Blogs AS [b\nORDER BY [b]"), textNonParsable},
 		{statsGroup("redis", "ADD 1, 2"), "ADD"},
 		{statsGroup("valkey", "ADD 1, 2"), "ADD"},
+		{statsGroup("graphql", `query Q { user(id: 1) { name } }`), "query Q"},
+		{statsGroup("graphql", `query Q { user(id: 1) { name }`), textNonParsableGraphQL},
+		{statsGroup("graphql", "GetUser"), "GetUser"},
 		{statsGroup("other", "ADD 1, 2"), "ADD 1, 2"},
 	} {
 		agnt, stop := agentWithDefaults()
 		defer stop()
+		agnt.conf.Obfuscation.GraphQL.Enabled = true
 		agnt.obfuscateStatsGroup(tt.in)
 		assert.Equal(t, tt.in.Resource, tt.out)
 	}
 }
 
+func TestObfuscateStatsGroupCQL(t *testing.T) {
+	for _, tt := range []struct {
+		enabled bool
+		in, out string
+	}{
+		{false, "SELECT * FROM ks.users WHERE id = 1", "SELECT * FROM ks.users WHERE id = ?"},
+		{true, "SELECT * FROM ks.users WHERE id = 1 AND tags CONTAINS 'a'", "SELECT * FROM ks.users WHERE id = ? AND tags CONTAINS ?"},
+		{true, "SELECT * FROM ks.users WHERE name = 'unterminated", textNonParsable},
+	} {
+		agnt, stop := agentWithDefaults()
+		defer stop()
+		agnt.conf.Obfuscation.CQL.Enabled = tt.enabled
+		b := &pb.ClientGroupedStats{Type: "cassandra", Resource: tt.in}
+		agnt.obfuscateStatsGroup(b)
+		assert.Equal(t, tt.out, b.Resource)
+	}
+}
+
 // TestObfuscateDefaults ensures that running the obfuscator with no config continues to obfuscate/quantize
 // SQL queries and Redis commands in span resources.
 func TestObfuscateDefaults(t *testing.T) {
@@ -296,6 +318,182 @@ func TestObfuscateConfig(t *testing.T) {
 		&config.ObfuscationConfig{},
 	))
 
+	t.Run("graphql/enabled", testConfig(
+		"graphql",
+		"graphql.query",
+		`query GetUser { user(id: 123, name: "bob") { name } }`,
+		`query GetUser { user(id: ?, name: ?) { name } }`,
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}},
+	))
+
+	t.Run("graphql/non-parsable", testConfig(
+		"graphql",
+		"graphql.query",
+		`query GetUser { user(name: "bob) { name } }`,
+		textNonParsableGraphQL,
+		&config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}},
+	))
+
+	t.Run("graphql/resource", func(t *testing.T) {
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		defer cancelFunc()
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.Obfuscation = &config.ObfuscationConfig{GraphQL: obfuscate.GraphQLConfig{Enabled: true}}
+		agnt := NewAgent(ctx, cfg, telemetry.NewNoopCollector(), &statsd.NoOpClient{}, gzip.NewComponent())
+		for _, tt := range []struct {
+			in, out string
+		}{
+			{`query GetUser { user(id: 123) { name } }`, "query GetUser"},
+			{`mutation { like(id: 1) }`, "mutation"},
+			{`query GetUser { user(id: 123) { name }`, textNonParsableGraphQL},
+			{"GetUser", "GetUser"},
+		} {
+			span := &pb.Span{Type: "graphql", Resource: tt.in}
+			agnt.obfuscateSpan(span)
+			assert.Equal(t, tt.out, span.Resource)
+		}
+	})
+
+	t.Run("graphql/disabled", testConfig(
+		"graphql",
+		"graphql.query",
+		`query GetUser { user(id: 123) { name } }`,
+		`query GetUser { user(id: 123) { name } }`,
+		&config.ObfuscationConfig{},
+	))
+
+	t.Run("cql/enabled", func(t *testing.T) {
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		defer cancelFunc()
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.Features["table_names"] = struct{}{}
+		cfg.Obfuscation = &config.ObfuscationConfig{CQL: obfuscate.CQLConfig{Enabled: true, RemoveKeyspace: true}}
+		agnt := NewAgent(ctx, cfg, telemetry.NewNoopCollector(), &statsd.NoOpClient{}, gzip.NewComponent())
+		span := &pb.Span{
+			Type:     "cassandra",
+			Resource: "SELECT * FROM users WHERE id = 5d1c2b3a-1111-4222-8333-944455556666 AND tags CONTAINS 'vip'",
+			Meta:     map[string]string{"cassandra.keyspace": "shop"},
+		}
+		agnt.obfuscateSpan(span)
+		assert.Equal(t, "SELECT * FROM users WHERE id = ? AND tags CONTAINS ?", span.Resource)
+		assert.Equal(t, span.Resource, span.Meta["sql.query"])
+		assert.Equal(t, "shop.users", span.Meta["sql.tables"])
+	})
+
+	t.Run("cql/non-parsable", func(t *testing.T) {
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		defer cancelFunc()
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.Obfuscation = &config.ObfuscationConfig{CQL: obfuscate.CQLConfig{Enabled: true}}
+		agnt := NewAgent(ctx, cfg, telemetry.NewNoopCollector(), &statsd.NoOpClient{}, gzip.NewComponent())
+		span := &pb.Span{Type: "cassandra", Resource: "SELECT * FROM users WHERE name = 'bob"}
+		agnt.obfuscateSpan(span)
+		assert.Equal(t, textNonParsable, span.Resource)
+		assert.Equal(t, textNonParsable, span.Meta["sql.query"])
+	})
+
+	t.Run("dynamodb/enabled", func(t *testing.T) {
+		testConfig(
+			"dynamodb",
+			"aws.dynamodb.statement",
+			`SELECT * FROM "Orders" WHERE OrderID = 'abc' AND Total > 10`,
+			`SELECT * FROM "Orders" WHERE OrderID = ? AND Total > ?`,
+			&config.ObfuscationConfig{DynamoDB: obfuscate.DynamoDBConfig{Enabled: true}},
+		)(t)
+		testConfig(
+			"dynamodb",
+			"aws.dynamodb.request_body",
+			`{"TableName":"Orders","Key":{"OrderID":{"S":"abc"}}}`,
+			`{"TableName":"Orders","Key":{"OrderID":{"S":"?"}}}`,
+			&config.ObfuscationConfig{DynamoDB: obfuscate.DynamoDBConfig{Enabled: true}},
+		)(t)
+	})
+
+	t.Run("dynamodb/disabled", testConfig(
+		"dynamodb",
+		"aws.dynamodb.statement",
+		`SELECT * FROM "Orders" WHERE OrderID = 'abc'`,
+		`SELECT * FROM "Orders" WHERE OrderID = 'abc'`,
+		&config.ObfuscationConfig{},
+	))
+
+	t.Run("kafka", func(t *testing.T) {
+		for _, tt := range []struct {
+			k, v string
+			out  string
+		}{
+			{"messaging.kafka.message.key", "user-1234", "?"},
+			{"messaging.kafka.message.header.authorization", "Bearer secret", "?"},
+			{"messaging.kafka.message.header.traceparent", "00-abc-def-01", "00-abc-def-01"},
+			{"messaging.kafka.message.header.content-type", "application/json", "application/json"},
+			{"messaging.destination.name", "orders", "orders"},
+		} {
+			t.Run(tt.k, testConfig("kafka",
+				tt.k,
+				tt.v,
+				tt.out,
+				&config.ObfuscationConfig{
+					Kafka: obfuscate.KafkaConfig{Enabled: true, KeepHeaders: []string{"Content-Type"}},
+				}))
+		}
+	})
+
+	t.Run("dynamodb-kafka/span-kinds", func(t *testing.T) {
+		ctx, cancelFunc := context.WithCancel(context.Background())
+		defer cancelFunc()
+		cfg := config.New()
+		cfg.Endpoints[0].APIKey = "test"
+		cfg.Obfuscation = &config.ObfuscationConfig{
+			DynamoDB: obfuscate.DynamoDBConfig{Enabled: true},
+			Kafka:    obfuscate.KafkaConfig{Enabled: true},
+		}
+		agnt := NewAgent(ctx, cfg, telemetry.NewNoopCollector(), &statsd.NoOpClient{}, gzip.NewComponent())
+		for _, tt := range []struct {
+			name     string
+			span     *pb.Span
+			key, out string
+		}{
+			{
+				name: "aws sdk dynamodb",
+				span: &pb.Span{Type: "http", Meta: map[string]string{"component": "aws-sdk", "aws.service": "DynamoDB", "aws.dynamodb.statement": "SELECT * FROM Orders WHERE id = 'abc'"}},
+				key:  "aws.dynamodb.statement",
+				out:  "SELECT * FROM Orders WHERE id = ?",
+			},
+			{
+				name: "aws sdk s3",
+				span: &pb.Span{Type: "http", Meta: map[string]string{"component": "aws-sdk", "aws.service": "S3", "aws.dynamodb.statement": "SELECT * FROM Orders WHERE id = 'abc'"}},
+				key:  "aws.dynamodb.statement",
+				out:  "SELECT * FROM Orders WHERE id = 'abc'",
+			},
+			{
+				name: "kafka component",
+				span: &pb.Span{Type: "queue", Meta: map[string]string{"component": "confluentinc/confluent-kafka-go/kafka", "messaging.kafka.message.key": "user-1234"}},
+				key:  "messaging.kafka.message.key",
+				out:  "?",
+			},
+			{
+				name: "kafka messaging system",
+				span: &pb.Span{Meta: map[string]string{"messaging.system": "kafka", "messaging.message.body": "secret"}},
+				key:  "messaging.message.body",
+				out:  "?",
+			},
+			{
+				name: "rabbitmq",
+				span: &pb.Span{Type: "queue", Meta: map[string]string{"messaging.system": "rabbitmq", "messaging.message.body": "hello"}},
+				key:  "messaging.message.body",
+				out:  "hello",
+			},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				agnt.obfuscateSpan(tt.span)
+				assert.Equal(t, tt.out, tt.span.Meta[tt.key])
+			})
+		}
+	})
+
 	t.Run("creditcard", func(t *testing.T) {
 		for _, tt := range []struct {
 			k, v string
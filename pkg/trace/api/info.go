@@ -37,6 +37,10 @@ func (r *HTTPReceiver) makeInfoHandler() (hash string, handler http.HandlerFunc)
 		Redis                obfuscate.RedisConfig     `json:"redis"`
 		Valkey               obfuscate.ValkeyConfig    `json:"valkey"`
 		Memcached            obfuscate.MemcachedConfig `json:"memcached"`
+		GraphQL              obfuscate.GraphQLConfig   `json:"graphql"`
+		CQL                  obfuscate.CQLConfig       `json:"cql"`
+		DynamoDB             bool                      `json:"dynamodb"`
+		Kafka                bool                      `json:"kafka"`
 	}
 	type reducedConfig struct {
 		DefaultEnv             string                        `json:"default_env"`
@@ -64,6 +68,10 @@ func (r *HTTPReceiver) makeInfoHandler() (hash string, handler http.HandlerFunc)
 		oconf.Redis = o.Redis
 		oconf.Valkey = o.Valkey
 		oconf.Memcached = o.Memcached
+		oconf.GraphQL = o.GraphQL
+		oconf.CQL = o.CQL
+		oconf.DynamoDB = o.DynamoDB.Enabled
+		oconf.Kafka = o.Kafka.Enabled
 	}
 
 	// We check that endpoints contains stats, even though we know this version of the
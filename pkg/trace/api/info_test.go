@@ -232,6 +232,7 @@ func TestInfoHandler(t *testing.T) {
 		Redis:             obfuscate.RedisConfig{Enabled: true},
 		Valkey:            obfuscate.ValkeyConfig{Enabled: true},
 		Memcached:         obfuscate.MemcachedConfig{Enabled: false},
+		GraphQL:           obfuscate.GraphQLConfig{Enabled: true},
 	}
 	conf := &config.AgentConfig{
 		Enabled:      true,
@@ -332,6 +333,10 @@ func TestInfoHandler(t *testing.T) {
 				"redis":               nil,
 				"valkey":              nil,
 				"memcached":           nil,
+				"graphql":             nil,
+				"cql":                 nil,
+				"dynamodb":            nil,
+				"kafka":               nil,
 			},
 		},
 	}
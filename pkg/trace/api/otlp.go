@@ -33,7 +33,6 @@ import (
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
-	semconv117 "go.opentelemetry.io/collector/semconv/v1.17.0"
 	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
@@ -568,13 +567,7 @@ func (o *OTLPReceiver) convertSpan(rattr map[string]string, lib pcommon.Instrume
 			transform.SetMetaOTLP(span, "version", ver)
 		}
 	}
-	if in.Events().Len() > 0 {
-		transform.SetMetaOTLP(span, "events", transform.MarshalEvents(in.Events()))
-	}
-	transform.TagSpanIfContainsExceptionEvent(in, span)
-	if in.Links().Len() > 0 {
-		transform.SetMetaOTLP(span, "_dd.span_links", transform.MarshalLinks(in.Links()))
-	}
+	transform.SetEventsAndLinks(in, span, o.conf)
 
 	var gotMethodFromNewConv bool
 	var gotStatusCodeFromNewConv bool
@@ -619,6 +612,7 @@ func (o *OTLPReceiver) convertSpan(rattr map[string]string, lib pcommon.Instrume
 
 		return true
 	})
+	traceutil.SetPeerTagPrecursorsFromMeta(span.Meta)
 	if _, ok := span.Meta["env"]; !ok {
 		// TODO(songy23): use AttributeDeploymentEnvironmentName once collector version upgrade is unblocked
 		if _, env := transform.GetFirstFromMap(span.Meta, "deployment.environment.name", semconv.AttributeDeploymentEnvironment); env != "" {
@@ -685,31 +679,32 @@ func (o *OTLPReceiver) convertSpan(rattr map[string]string, lib pcommon.Instrume
 // resourceFromTags attempts to deduce a more accurate span resource from the given list of tags meta.
 // If this is not possible, it returns an empty string.
 func resourceFromTags(meta map[string]string) string {
-	// `http.method` was renamed to `http.request.method` in the HTTP stabilization from v1.23.
-	// See https://opentelemetry.io/docs/specs/semconv/http/migration-guide/#summary-of-changes
-	if _, m := transform.GetFirstFromMap(meta, "http.request.method", "http.method"); m != "" {
+	if m := traceutil.GetSemConvAttrFromMeta(meta, traceutil.SemConvHTTPMethod); m != "" {
 		// use the HTTP method + route (if available)
-		if _, route := transform.GetFirstFromMap(meta, semconv.AttributeHTTPRoute, "grpc.path"); route != "" {
+		if route := traceutil.GetSemConvAttrFromMeta(meta, traceutil.SemConvHTTPRoute); route != "" {
+			return m + " " + route
+		}
+		if route := meta["grpc.path"]; route != "" {
 			return m + " " + route
 		}
 		return m
-	} else if m := meta[string(semconv.AttributeMessagingOperation)]; m != "" {
+	} else if m := traceutil.GetSemConvAttrFromMeta(meta, traceutil.SemConvMessagingOperation); m != "" {
 		// use the messaging operation
-		if _, dest := transform.GetFirstFromMap(meta, semconv.AttributeMessagingDestination, semconv117.AttributeMessagingDestinationName); dest != "" {
+		if dest := traceutil.GetSemConvAttrFromMeta(meta, traceutil.SemConvMessagingDestination); dest != "" {
 			return m + " " + dest
 		}
 		return m
-	} else if m := meta[string(semconv.AttributeRPCMethod)]; m != "" {
+	} else if m := traceutil.GetSemConvAttrFromMeta(meta, traceutil.SemConvRPCMethod); m != "" {
 		// use the RPC method
-		if svc := meta[string(semconv.AttributeRPCService)]; svc != "" {
+		if svc := traceutil.GetSemConvAttrFromMeta(meta, traceutil.SemConvRPCService); svc != "" {
 			// ...and service if available
 			return m + " " + svc
 		}
 		return m
-	} else if typ := meta[semconv117.AttributeGraphqlOperationType]; typ != "" {
+	} else if typ := traceutil.GetSemConvAttrFromMeta(meta, traceutil.SemConvGraphQLOperationType); typ != "" {
 		// Enrich GraphQL query resource names.
 		// See https://github.com/open-telemetry/semantic-conventions/blob/v1.29.0/docs/graphql/graphql-spans.md
-		if name := meta[semconv117.AttributeGraphqlOperationName]; name != "" {
+		if name := traceutil.GetSemConvAttrFromMeta(meta, traceutil.SemConvGraphQLOperationName); name != "" {
 			return typ + " " + name
 		}
 		return typ
@@ -0,0 +1,288 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+)
+
+const (
+	// defaultInspectorRate is the number of chunks per second streamed to a client
+	// which does not specify a rate.
+	defaultInspectorRate = 5
+
+	// maxInspectorRate is the maximum number of chunks per second streamed to a client.
+	maxInspectorRate = 50
+
+	// maxInspectorSessions is the maximum number of clients streaming at the same time.
+	maxInspectorSessions = 4
+
+	// inspectorBufferSize is the number of chunks buffered for each client.
+	inspectorBufferSize = 100
+
+	// inspectorWriteTimeout is the maximum duration of a single write to a client.
+	inspectorWriteTimeout = 5 * time.Second
+)
+
+// TraceInspectorFilters holds the parameters of a trace inspector session. They are
+// sent by the client as the JSON body of its request.
+type TraceInspectorFilters struct {
+	// Service only matches chunks whose root span has the given service.
+	Service string `json:"service"`
+
+	// Resource only matches chunks whose root span resource contains the given string.
+	// Chunks dropped before obfuscation have no resource and never match it.
+	Resource string `json:"resource"`
+
+	// Rate is the maximum number of chunks streamed per second. It defaults to 5
+	// and is capped to 50.
+	Rate float64 `json:"rate"`
+}
+
+// matches reports whether a chunk having a root span with the given service and
+// resource matches the filters.
+func (f *TraceInspectorFilters) matches(service, resource string) bool {
+	if f.Service != "" && f.Service != service {
+		return false
+	}
+	return f.Resource == "" || strings.Contains(resource, f.Resource)
+}
+
+// InspectedChunk describes a trace chunk processed by the agent, along with all the
+// decisions which were taken on it.
+type InspectedChunk struct {
+	// Time is the time at which the chunk was processed.
+	Time time.Time `json:"time"`
+
+	// TraceID is the ID of the trace the chunk belongs to.
+	TraceID uint64 `json:"trace_id"`
+
+	// Service, Name and Resource are the ones of the root span of the chunk. Resource
+	// is only set once the chunk has been obfuscated.
+	Service  string `json:"service"`
+	Name     string `json:"name"`
+	Resource string `json:"resource,omitempty"`
+
+	// Env is the environment of the chunk.
+	Env string `json:"env,omitempty"`
+
+	// Spans is the number of spans received in the chunk.
+	Spans int `json:"spans"`
+
+	// Dropped is set when the chunk was dropped before reaching the samplers. It
+	// is one of "invalid", "ignore_resources" or "filter_tags".
+	Dropped string `json:"dropped,omitempty"`
+
+	// Reason explains why the chunk was dropped before reaching the samplers.
+	Reason string `json:"reason,omitempty"`
+
+	// Normalized lists the changes made to the spans by the normalizer.
+	Normalized []string `json:"normalized,omitempty"`
+
+	// Truncated lists the values which were truncated.
+	Truncated []string `json:"truncated,omitempty"`
+
+	// Sampler is the name of the sampler which took the sampling decision.
+	Sampler string `json:"sampler,omitempty"`
+
+	// Verdicts lists, in order, the decision of every sampler which ran on the chunk.
+	Verdicts []SamplerVerdict `json:"verdicts,omitempty"`
+
+	// Priority is the sampling priority of the chunk, if any.
+	Priority *int32 `json:"priority,omitempty"`
+
+	// DecisionMaker is the sampling mechanism which set the priority of the chunk.
+	DecisionMaker string `json:"decision_maker,omitempty"`
+
+	// Kept reports whether the chunk was kept by the samplers.
+	Kept bool `json:"kept"`
+
+	// KeptSpans is the number of spans sent to the intake. When the chunk is not kept,
+	// it counts the spans kept by single span sampling or as analyzed events.
+	KeptSpans int `json:"kept_spans"`
+
+	// Rates holds the sampling rates set by the samplers on the root span.
+	Rates map[string]float64 `json:"rates,omitempty"`
+
+	// Probabilistic holds the decision of the probabilistic sampler, when enabled.
+	Probabilistic *ProbabilisticVerdict `json:"probabilistic,omitempty"`
+}
+
+// SamplerVerdict describes the decision of a single sampler on a chunk.
+type SamplerVerdict struct {
+	// Sampler is the name of the sampler.
+	Sampler string `json:"sampler"`
+
+	// Keep reports whether the sampler kept the chunk.
+	Keep bool `json:"keep"`
+}
+
+// ProbabilisticVerdict describes the decision of the probabilistic sampler on a chunk.
+type ProbabilisticVerdict struct {
+	// Hash is the hash bucket of the trace ID.
+	Hash uint32 `json:"hash"`
+
+	// Threshold is the bucket under which traces are kept.
+	Threshold uint32 `json:"threshold"`
+
+	// Keep reports whether the hash is below the threshold.
+	Keep bool `json:"keep"`
+}
+
+// TraceInspector streams the chunks processed by the agent to clients of the debug
+// server, allowing to understand why a given trace was kept or dropped.
+type TraceInspector struct {
+	mu       sync.RWMutex
+	sessions map[*inspectorSession]struct{}
+	active   atomic.Bool
+}
+
+// inspectorSession holds a client streaming from the trace inspector.
+type inspectorSession struct {
+	filters TraceInspectorFilters
+	limiter *rate.Limiter
+	out     chan *InspectedChunk
+	dropped atomic.Int64 // number of matching chunks dropped because of rate limits
+}
+
+// NewTraceInspector returns a new TraceInspector.
+func NewTraceInspector() *TraceInspector {
+	return &TraceInspector{sessions: make(map[*inspectorSession]struct{})}
+}
+
+// Active reports whether at least one client is streaming. It is meant to be called
+// for each processed chunk, so that inspection does not cost anything when unused.
+func (ti *TraceInspector) Active() bool {
+	return ti != nil && ti.active.Load()
+}
+
+// Wants reports whether at least one client is interested in chunks having a root
+// span with the given service and resource.
+func (ti *TraceInspector) Wants(service, resource string) bool {
+	if !ti.Active() {
+		return false
+	}
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+	for s := range ti.sessions {
+		if s.filters.matches(service, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// Publish sends the chunk to the clients whose filters match it, within their rate limits.
+// It never blocks.
+func (ti *TraceInspector) Publish(c *InspectedChunk) {
+	if !ti.Active() {
+		return
+	}
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+	for s := range ti.sessions {
+		if !s.filters.matches(c.Service, c.Resource) {
+			continue
+		}
+		if !s.limiter.Allow() {
+			s.dropped.Add(1)
+			continue
+		}
+		select {
+		case s.out <- c:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+func (ti *TraceInspector) addSession(s *inspectorSession) bool {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	if len(ti.sessions) >= maxInspectorSessions {
+		return false
+	}
+	ti.sessions[s] = struct{}{}
+	ti.active.Store(true)
+	return true
+}
+
+func (ti *TraceInspector) removeSession(s *inspectorSession) {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	delete(ti.sessions, s)
+	ti.active.Store(len(ti.sessions) > 0)
+}
+
+// ServeHTTP streams the inspected chunks matching the filters found in the request
+// body as newline-delimited JSON, until the client disconnects.
+func (ti *TraceInspector) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	var filters TraceInspectorFilters
+	if err := json.NewDecoder(req.Body).Decode(&filters); err != nil && err != io.EOF {
+		http.Error(w, "invalid filters: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filters.Rate <= 0 {
+		filters.Rate = defaultInspectorRate
+	}
+	filters.Rate = min(filters.Rate, maxInspectorRate)
+
+	s := &inspectorSession{
+		filters: filters,
+		limiter: rate.NewLimiter(rate.Limit(filters.Rate), int(filters.Rate)+1),
+		out:     make(chan *InspectedChunk, inspectorBufferSize),
+	}
+	if !ti.addSession(s) {
+		http.Error(w, "too many clients are already inspecting traces", http.StatusTooManyRequests)
+		return
+	}
+	defer ti.removeSession(s)
+	log.Infof("Trace inspector session started (service: %q, resource: %q, rate: %.2f/s).", filters.Service, filters.Resource, filters.Rate)
+	defer func() {
+		log.Infof("Trace inspector session ended, %d chunks were dropped due to rate limits.", s.dropped.Load())
+	}()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// The debug server write timeout applies to the whole response, which
+	// would end the stream early. Each write has its own deadline instead.
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	for {
+		select {
+		case <-req.Context().Done():
+			return
+		case c := <-s.out:
+			_ = rc.SetWriteDeadline(time.Now().Add(inspectorWriteTimeout))
+			if err := enc.Encode(c); err != nil {
+				log.Debugf("Trace inspector client went away: %v", err)
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
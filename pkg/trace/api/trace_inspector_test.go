@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startInspection starts a trace inspector session with the given filters, returning
+// a reader of the streamed chunks.
+func startInspection(t *testing.T, srv *httptest.Server, filters string) *bufio.Scanner {
+	t.Helper()
+	resp, err := srv.Client().Post(srv.URL, "application/json", strings.NewReader(filters))
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	return bufio.NewScanner(resp.Body)
+}
+
+func waitForSessions(t *testing.T, ti *TraceInspector, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		ti.mu.RLock()
+		defer ti.mu.RUnlock()
+		return len(ti.sessions) == n
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTraceInspector(t *testing.T) {
+	ti := NewTraceInspector()
+	assert.False(t, ti.Active())
+	srv := httptest.NewServer(ti)
+	// registered first so that it runs after the clients are closed
+	t.Cleanup(srv.Close)
+
+	scanner := startInspection(t, srv, `{"service":"web","resource":"users"}`)
+	waitForSessions(t, ti, 1)
+	assert.True(t, ti.Active())
+	assert.True(t, ti.Wants("web", "GET /users"))
+	assert.False(t, ti.Wants("web", "GET /health"))
+	assert.False(t, ti.Wants("db", "GET /users"))
+
+	ti.Publish(&InspectedChunk{TraceID: 1, Service: "db", Resource: "GET /users"})
+	ti.Publish(&InspectedChunk{TraceID: 2, Service: "web", Resource: "GET /health"})
+	ti.Publish(&InspectedChunk{TraceID: 3, Service: "web", Resource: "GET /users", Sampler: "priority", Kept: true})
+
+	require.True(t, scanner.Scan())
+	var c InspectedChunk
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &c))
+	assert.Equal(t, uint64(3), c.TraceID)
+	assert.Equal(t, "priority", c.Sampler)
+	assert.True(t, c.Kept)
+
+	srv.CloseClientConnections()
+	waitForSessions(t, ti, 0)
+	assert.False(t, ti.Active())
+}
+
+func TestTraceInspectorRateLimit(t *testing.T) {
+	ti := NewTraceInspector()
+	srv := httptest.NewServer(ti)
+	// registered first so that it runs after the clients are closed
+	t.Cleanup(srv.Close)
+
+	startInspection(t, srv, `{"rate":1}`)
+	waitForSessions(t, ti, 1)
+	for i := 0; i < 10; i++ {
+		ti.Publish(&InspectedChunk{TraceID: uint64(i)})
+	}
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+	for s := range ti.sessions {
+		assert.Equal(t, 1.0, s.filters.Rate)
+		// the burst allows rate+1 chunks
+		assert.EqualValues(t, 8, s.dropped.Load())
+	}
+}
+
+func TestTraceInspectorLimits(t *testing.T) {
+	ti := NewTraceInspector()
+	srv := httptest.NewServer(ti)
+	// registered first so that it runs after the clients are closed
+	t.Cleanup(srv.Close)
+
+	t.Run("method", func(t *testing.T) {
+		resp, err := srv.Client().Get(srv.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		resp, err := srv.Client().Post(srv.URL, "application/json", strings.NewReader(`{"service":`))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("sessions", func(t *testing.T) {
+		for i := 0; i < maxInspectorSessions; i++ {
+			startInspection(t, srv, `{"rate":1000}`)
+		}
+		waitForSessions(t, ti, maxInspectorSessions)
+		ti.mu.RLock()
+		for s := range ti.sessions {
+			assert.Equal(t, float64(maxInspectorRate), s.filters.Rate)
+		}
+		ti.mu.RUnlock()
+
+		resp, err := srv.Client().Post(srv.URL, "application/json", nil)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	})
+}
@@ -132,6 +132,22 @@ type ObfuscationConfig struct {
 	// for spans of type "memcached".
 	Memcached obfuscate.MemcachedConfig `mapstructure:"memcached"`
 
+	// GraphQL holds the configuration for obfuscating the "graphql.query" tag
+	// and quantizing the resource for spans of type "graphql".
+	GraphQL obfuscate.GraphQLConfig `mapstructure:"graphql"`
+
+	// CQL holds the configuration for obfuscating Cassandra Query Language
+	// statements for spans of type "cassandra".
+	CQL obfuscate.CQLConfig `mapstructure:"cql"`
+
+	// DynamoDB holds the configuration for obfuscating the PartiQL statements
+	// and request bodies of DynamoDB spans.
+	DynamoDB obfuscate.DynamoDBConfig `mapstructure:"dynamodb"`
+
+	// Kafka holds the configuration for obfuscating Kafka message headers
+	// and payloads.
+	Kafka obfuscate.KafkaConfig `mapstructure:"kafka"`
+
 	// CreditCards holds the configuration for obfuscating credit cards.
 	CreditCards obfuscate.CreditCardsConfig `mapstructure:"credit_cards"`
 
@@ -172,6 +188,10 @@ func (o *ObfuscationConfig) Export(conf *AgentConfig) obfuscate.Config {
 		Redis:                o.Redis,
 		Valkey:               o.Valkey,
 		Memcached:            o.Memcached,
+		GraphQL:              o.GraphQL,
+		CQL:                  o.CQL,
+		DynamoDB:             o.DynamoDB,
+		Kafka:                o.Kafka,
 		CreditCard:           o.CreditCards,
 		Logger:               new(debugLogger),
 		Cache:                o.Cache,
@@ -356,6 +376,16 @@ type AgentConfig struct {
 	RareSamplerCooldownPeriod time.Duration
 	RareSamplerCardinality    int
 
+	// Latency Sampler configuration
+	LatencySamplerEnabled     bool
+	LatencySamplerPercentile  float64
+	LatencySamplerTPS         float64
+	LatencySamplerCardinality int
+
+	// BytesBudgetPerMinute is the maximum number of bytes of trace chunks kept by the
+	// samplers every minute. A value of 0 disables the budget.
+	BytesBudgetPerMinute int64
+
 	// Probabilistic Sampler configuration
 	ProbabilisticSamplerEnabled            bool
 	ProbabilisticSamplerHashSeed           uint32
@@ -565,6 +595,11 @@ func New() *AgentConfig {
 		RareSamplerCooldownPeriod: 5 * time.Minute,
 		RareSamplerCardinality:    200,
 
+		LatencySamplerEnabled:     false,
+		LatencySamplerPercentile:  0.99,
+		LatencySamplerTPS:         5,
+		LatencySamplerCardinality: 500,
+
 		ErrorTrackingStandalone: false,
 
 		ReceiverEnabled:        true,
@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+)
+
+const (
+	// budgetPeriod is the period over which the bytes budget applies.
+	budgetPeriod = time.Minute
+	// budgetMaxRateIncrease caps the increase of the budget rate between two periods.
+	budgetMaxRateIncrease = 2
+	// budgetMinRate is the lowest rate the bytes budget may apply, so that traffic
+	// never stops completely.
+	budgetMinRate = 0.001
+
+	// MetricBytesBudgetRate is the metric name for the rate applied to meet the bytes budget.
+	MetricBytesBudgetRate = "datadog.trace_agent.sampler.bytes_budget.rate"
+	// MetricBytesBudgetKept is the metric name for the number of bytes of trace chunks kept by the samplers.
+	MetricBytesBudgetKept = "datadog.trace_agent.sampler.bytes_budget.kept_bytes"
+)
+
+// BytesBudget limits the number of bytes of trace chunks kept by the samplers every minute.
+// It counts the size of the chunks kept during the current minute and, at the end of each
+// minute, adjusts a rate so that the kept bytes match the budget. The rate scales the rates
+// computed by the PrioritySampler, both those applied by the agent and those sent back to
+// tracers through the service catalog, so that all of them stay consistent. It also scales
+// the rates of the ErrorsSampler and the NoPrioritySampler and the rate limit of the
+// LatencySampler, and the RareSampler and the LatencySampler keep no chunk once the budget
+// of the current minute is spent.
+//
+// Chunks kept by the user (manual keep) and by the ProbabilisticSampler are counted but not
+// limited, as their sampling decision must stay consistent with the one of the tracers.
+type BytesBudget struct {
+	bytesPerMinute int64
+
+	mu     sync.Mutex
+	period int64 // index of the current period
+	bytes  int64 // bytes kept during the current period
+
+	rate *atomic.Float64
+	kept *atomic.Int64 // bytes kept since the last report
+}
+
+// NewBytesBudget returns a BytesBudget allowing bytesPerMinute bytes of trace chunks to be kept
+// every minute. A budget of 0 or less disables it.
+func NewBytesBudget(bytesPerMinute int64) *BytesBudget {
+	return &BytesBudget{
+		bytesPerMinute: bytesPerMinute,
+		rate:           atomic.NewFloat64(1),
+		kept:           atomic.NewInt64(0),
+	}
+}
+
+// enabled reports whether the budget applies.
+func (b *BytesBudget) enabled() bool {
+	return b != nil && b.bytesPerMinute > 0
+}
+
+// Count records that a trace chunk of the given size in bytes was kept at time now.
+func (b *BytesBudget) Count(now time.Time, size int) {
+	if !b.enabled() {
+		return
+	}
+	b.kept.Add(int64(size))
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rotate(now)
+	b.bytes += int64(size)
+}
+
+// Exceeded reports whether the bytes kept during the current minute exceed the budget.
+func (b *BytesBudget) Exceeded(now time.Time) bool {
+	if !b.enabled() {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.rotate(now)
+	return b.bytes >= b.bytesPerMinute
+}
+
+// Rate returns the rate to apply to sampling rates in order to meet the budget.
+func (b *BytesBudget) Rate() float64 {
+	if !b.enabled() {
+		return 1
+	}
+	return b.rate.Load()
+}
+
+// rotate starts a new period if now is past the current one, and adjusts the rate
+// based on the bytes kept during the previous period.
+// Callers of rotate must hold a lock on b.mu.
+func (b *BytesBudget) rotate(now time.Time) {
+	period := now.Unix() / int64(budgetPeriod.Seconds())
+	if period == b.period {
+		return
+	}
+	var kept int64
+	if period == b.period+1 {
+		kept = b.bytes
+	}
+	b.period = period
+	b.bytes = 0
+
+	prevRate := b.rate.Load()
+	rate := math.Min(prevRate*budgetMaxRateIncrease, 1)
+	if kept > 0 {
+		rate = math.Min(rate, prevRate*float64(b.bytesPerMinute)/float64(kept))
+	}
+	b.rate.Store(math.Max(rate, budgetMinRate))
+}
+
+var _ AdditionalMetricsReporter = (*BytesBudget)(nil)
+
+func (b *BytesBudget) report(statsd statsd.ClientInterface) {
+	if !b.enabled() {
+		return
+	}
+	_ = statsd.Gauge(MetricBytesBudgetRate, b.Rate(), nil, 1)
+	_ = statsd.Count(MetricBytesBudgetKept, b.kept.Swap(0), nil, 1)
+}
@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+func TestBytesBudgetDisabled(t *testing.T) {
+	now := time.Now()
+	for _, b := range []*BytesBudget{nil, NewBytesBudget(0)} {
+		b.Count(now, 1<<30)
+		b.Count(now.Add(budgetPeriod), 1<<30)
+		assert.False(t, b.Exceeded(now.Add(budgetPeriod)))
+		assert.Equal(t, 1.0, b.Rate())
+	}
+}
+
+func TestBytesBudget(t *testing.T) {
+	assert := assert.New(t)
+	b := NewBytesBudget(1000)
+	now := time.Unix(1700000000, 0).Truncate(budgetPeriod)
+
+	b.Count(now, 600)
+	assert.False(b.Exceeded(now))
+	b.Count(now.Add(time.Second), 600)
+	assert.True(b.Exceeded(now.Add(time.Second)))
+	assert.Equal(1.0, b.Rate())
+
+	// 1200 bytes were kept during the previous minute
+	now = now.Add(budgetPeriod)
+	assert.False(b.Exceeded(now))
+	assert.InDelta(1000.0/1200, b.Rate(), 1e-9)
+
+	// way above budget
+	b.Count(now, 100000)
+	now = now.Add(budgetPeriod)
+	assert.False(b.Exceeded(now))
+	assert.InDelta(1000.0/1200*1000/100000, b.Rate(), 1e-9)
+
+	// the rate increase is capped
+	b.Count(now, 10)
+	now = now.Add(budgetPeriod)
+	b.Count(now, 10)
+	assert.InDelta(1000.0/1200*1000/100000*budgetMaxRateIncrease, b.Rate(), 1e-9)
+
+	// without traffic for more than a period, the rate recovers progressively
+	now = now.Add(5 * budgetPeriod)
+	b.Count(now, 10)
+	assert.InDelta(1000.0/1200*1000/100000*budgetMaxRateIncrease*budgetMaxRateIncrease, b.Rate(), 1e-9)
+}
+
+func TestBytesBudgetMinRate(t *testing.T) {
+	b := NewBytesBudget(1)
+	now := time.Unix(1700000000, 0).Truncate(budgetPeriod)
+	b.Count(now, 1<<30)
+	b.Count(now.Add(budgetPeriod), 0)
+	assert.Equal(t, budgetMinRate, b.Rate())
+}
+
+func TestBytesBudgetSamplers(t *testing.T) {
+	now := time.Unix(1700000000, 0).Truncate(budgetPeriod)
+	conf := &config.AgentConfig{
+		ExtraSampleRate:           1,
+		TargetTPS:                 1000,
+		ErrorTPS:                  1000,
+		RareSamplerEnabled:        true,
+		RareSamplerTPS:            1000,
+		RareSamplerCardinality:    200,
+		RareSamplerCooldownPeriod: time.Minute,
+	}
+	// keeps sampling 1000 chunks with the errors and the no priority samplers, and a rare chunk
+	run := func(dynConf *DynamicConfig) (kept int, rare bool, userKept bool) {
+		errorsSampler := NewErrorsSampler(conf, dynConf)
+		noPrioritySampler := NewNoPrioritySampler(conf, dynConf)
+		for i := 0; i < 1000; i++ {
+			trace, root := getTestTrace()
+			if errorsSampler.Sample(now, trace, root, defaultEnv) {
+				kept++
+			}
+			if noPrioritySampler.Sample(now, trace, root, defaultEnv) {
+				kept++
+			}
+		}
+		span := &pb.Span{Service: "s1", Resource: "r1", Metrics: map[string]float64{"_top_level": 1}}
+		rare = NewRareSampler(conf, dynConf).Sample(now, getTraceChunkWithSpanAndPriority(span, PriorityNone), "")
+
+		trace, root := getTestTrace()
+		chunk := &pb.TraceChunk{Priority: int32(PriorityUserKeep), Spans: trace}
+		userKept = NewPrioritySampler(conf, dynConf).Sample(now, chunk, root, defaultEnv, 0)
+		return kept, rare, userKept
+	}
+
+	kept, rare, userKept := run(NewDynamicConfig())
+	assert.Equal(t, 2000, kept)
+	assert.True(t, rare)
+	assert.True(t, userKept)
+
+	// the budget was largely exceeded during the previous minute and is spent for the current one
+	dynConf := NewDynamicConfig()
+	dynConf.BytesBudget = NewBytesBudget(1000)
+	dynConf.BytesBudget.Count(now.Add(-budgetPeriod), 1<<30)
+	dynConf.BytesBudget.Count(now, 1000)
+	require.Equal(t, budgetMinRate, dynConf.BytesBudget.Rate())
+	require.True(t, dynConf.BytesBudget.Exceeded(now))
+
+	kept, rare, userKept = run(dynConf)
+	assert.Less(t, kept, 50)
+	assert.False(t, rare)
+	// chunks kept by the user are not limited by the budget
+	assert.True(t, userKept)
+}
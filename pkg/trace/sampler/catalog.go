@@ -26,6 +26,9 @@ type serviceKeyCatalog struct {
 	items      map[ServiceSignature]*list.Element
 	ll         *list.List
 	maxEntries int
+	// budget, when set, scales the rates returned by ratesByService so that the rates
+	// sent to tracers stay consistent with the bytes budget.
+	budget *BytesBudget
 }
 
 type catalogEntry struct {
@@ -72,12 +75,13 @@ func (cat *serviceKeyCatalog) register(svcSig ServiceSignature) Signature {
 // the signatures.
 func (cat *serviceKeyCatalog) ratesByService(agentEnv string, rates map[Signature]float64, defaultRate float64) map[ServiceSignature]float64 {
 	rbs := make(map[ServiceSignature]float64, len(rates)+1)
+	budgetRate := cat.budget.Rate()
 	cat.mu.Lock()
 	defer cat.mu.Unlock()
 	for key, el := range cat.items {
 		sig := el.Value.(catalogEntry).sig
 		if rate, ok := rates[sig]; ok {
-			rbs[key] = rate
+			rbs[key] = rate * budgetRate
 		} else {
 			cat.ll.Remove(el)
 			delete(cat.items, key)
@@ -88,6 +92,6 @@ func (cat *serviceKeyCatalog) ratesByService(agentEnv string, rates map[Signatur
 			rbs[ServiceSignature{Name: key.Name}] = rbs[key]
 		}
 	}
-	rbs[ServiceSignature{}] = defaultRate
+	rbs[ServiceSignature{}] = defaultRate * budgetRate
 	return rbs
 }
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -204,6 +205,27 @@ func TestServiceKeyCatalogRatesByService(t *testing.T) {
 	}, rateByService)
 }
 
+func TestServiceKeyCatalogRatesByServiceBudget(t *testing.T) {
+	cat := newServiceLookup(0)
+	cat.budget = NewBytesBudget(1000)
+	sig := cat.register(ServiceSignature{"service1", defaultEnv})
+	rates := map[Signature]float64{sig: 0.5}
+
+	now := time.Unix(1700000000, 0).Truncate(budgetPeriod)
+	cat.budget.Count(now, 4000)
+	// the budget rate is only updated once the minute is over
+	assert.Equal(t, map[ServiceSignature]float64{
+		{"service1", defaultEnv}: 0.5,
+		{}:                       0.2,
+	}, cat.ratesByService("", rates, 0.2))
+
+	cat.budget.Count(now.Add(budgetPeriod), 100)
+	assert.Equal(t, map[ServiceSignature]float64{
+		{"service1", defaultEnv}: 0.125,
+		{}:                       0.05,
+	}, cat.ratesByService("", rates, 0.2))
+}
+
 func BenchmarkServiceKeyCatalog(b *testing.B) {
 	b.ReportAllocs()
 
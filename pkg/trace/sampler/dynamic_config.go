@@ -20,6 +20,11 @@ type DynamicConfig struct {
 	// RateByService contains the rate for each service/env tuple,
 	// used in priority sampling by client libs.
 	RateByService RateByService
+
+	// BytesBudget limits the number of bytes of trace chunks kept every minute. It is
+	// shared by the samplers so that the rates they apply and send to client libs stay
+	// consistent. A nil BytesBudget applies no limit.
+	BytesBudget *BytesBudget
 }
 
 // NewDynamicConfig creates a new dynamic config object which maps service signatures
@@ -0,0 +1,211 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/sketches-go/ddsketch"
+	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/log"
+	"github.com/DataDog/datadog-go/v5/statsd"
+)
+
+const (
+	latencyKey = "_dd.latency"
+	// latencyWindow is the period over which latencies are aggregated to compute thresholds.
+	latencyWindow = time.Minute
+	// latencyMinCount is the number of traces of a signature which must be seen before
+	// its latency threshold is considered accurate.
+	latencyMinCount = 50
+	// latencySketchAccuracy and latencySketchMaxBins size the latency sketches. As the lowest
+	// bins are collapsed first, the accuracy of the slow tail is preserved.
+	latencySketchAccuracy = 0.02
+	latencySketchMaxBins  = 256
+	// latencySamplerBurst sizes the token store used by the rate limiter.
+	latencySamplerBurst = 10
+
+	// MetricsLatencyHits is the metric name for the number of traces kept by the latency sampler.
+	MetricsLatencyHits = "datadog.trace_agent.sampler.latency.hits"
+	// MetricsLatencyMisses is the metric name for the number of slow traces the latency sampler
+	// could not keep because of its rate limit or of the bytes budget.
+	MetricsLatencyMisses = "datadog.trace_agent.sampler.latency.misses"
+)
+
+// LatencySampler keeps the slowest traces of each signature. For each combination of
+// (env, service, name, resource) of root spans, it maintains a sketch of the latencies seen
+// over the last minute and flags the traces above a configured percentile as slow. Slow
+// traces which were not kept by the other samplers are then kept within a rate limit and
+// the bytes budget shared with the other samplers.
+type LatencySampler struct {
+	enabled     bool
+	percentile  float64
+	cardinality int
+	tps         float64
+	limiter     *rate.Limiter
+	budget      *BytesBudget
+	hits        *atomic.Int64
+	misses      *atomic.Int64
+
+	mu       sync.Mutex
+	window   int64 // index of the current window
+	sketches map[Signature]*latencySketch
+}
+
+// latencySketch holds the latencies of a signature.
+type latencySketch struct {
+	sketch *ddsketch.DDSketch
+	// threshold is the latency above which traces are slow, computed at the end of the
+	// last window having enough traces. It is 0 until then.
+	threshold float64
+}
+
+// NewLatencySampler returns a LatencySampler keeping the traces whose latency is above
+// the configured percentile of their signature.
+func NewLatencySampler(conf *config.AgentConfig, dynConf *DynamicConfig) *LatencySampler {
+	return &LatencySampler{
+		enabled:     conf.LatencySamplerEnabled,
+		percentile:  conf.LatencySamplerPercentile,
+		cardinality: conf.LatencySamplerCardinality,
+		tps:         conf.LatencySamplerTPS,
+		limiter:     rate.NewLimiter(rate.Limit(conf.LatencySamplerTPS), latencySamplerBurst),
+		budget:      dynConf.BytesBudget,
+		hits:        atomic.NewInt64(0),
+		misses:      atomic.NewInt64(0),
+		sketches:    make(map[Signature]*latencySketch),
+	}
+}
+
+// IsSlow records the latency of the chunk with the given root and reports whether it is
+// above the configured percentile of its signature. It must be called for every chunk so
+// that the latency distributions are not biased by the decisions of the other samplers.
+func (s *LatencySampler) IsSlow(now time.Time, root *pb.Span, env string) bool {
+	if !s.enabled || root.Duration <= 0 {
+		return false
+	}
+	sig := latencySignature(root, env)
+	latency := float64(root.Duration)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rotate(now)
+	ls, ok := s.sketches[sig]
+	if !ok {
+		if len(s.sketches) >= s.cardinality {
+			// signatures above the cardinality limit are not tracked
+			return false
+		}
+		sketch, err := ddsketch.LogCollapsingLowestDenseDDSketch(latencySketchAccuracy, latencySketchMaxBins)
+		if err != nil {
+			log.Errorf("Unable to create latency sketch: %v", err)
+			return false
+		}
+		ls = &latencySketch{sketch: sketch}
+		s.sketches[sig] = ls
+	}
+	if err := ls.sketch.Add(latency); err != nil {
+		return false
+	}
+	threshold := ls.threshold
+	if threshold == 0 {
+		// no complete window yet, rely on the current one if it has enough traces
+		if ls.sketch.GetCount() < latencyMinCount {
+			return false
+		}
+		if threshold, ok = ls.quantile(s.percentile); !ok {
+			return false
+		}
+	}
+	return latency > threshold
+}
+
+// Sample reports whether a slow trace should be kept. It must only be called for traces
+// reported as slow by IsSlow which were not kept by the other samplers.
+func (s *LatencySampler) Sample(now time.Time, root *pb.Span) bool {
+	if !s.enabled {
+		return false
+	}
+	if s.budget.Exceeded(now) || !s.limiter.Allow() {
+		s.misses.Inc()
+		return false
+	}
+	s.hits.Inc()
+	setMetric(root, latencyKey, 1)
+	return true
+}
+
+// rotate computes the thresholds of all signatures when a new window starts, and
+// updates the rate limit to match the bytes budget.
+// Callers of rotate must hold a lock on s.mu.
+func (s *LatencySampler) rotate(now time.Time) {
+	window := now.Unix() / int64(latencyWindow.Seconds())
+	if window == s.window {
+		return
+	}
+	s.window = window
+	for sig, ls := range s.sketches {
+		count := ls.sketch.GetCount()
+		if count == 0 {
+			// no traffic on this signature during the last window
+			delete(s.sketches, sig)
+			continue
+		}
+		if count < latencyMinCount {
+			// keep accumulating latencies of low traffic signatures
+			continue
+		}
+		if threshold, ok := ls.quantile(s.percentile); ok {
+			ls.threshold = threshold
+		}
+		ls.sketch.Clear()
+	}
+	s.limiter.SetLimit(rate.Limit(s.tps * s.budget.Rate()))
+}
+
+// quantile returns the latency at the given quantile of the sketch.
+func (ls *latencySketch) quantile(q float64) (float64, bool) {
+	v, err := ls.sketch.GetValueAtQuantile(q)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// size returns the number of signatures tracked by the sampler.
+func (s *LatencySampler) size() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sketches)
+}
+
+// latencySignature returns the signature of the root span used to group latencies.
+func latencySignature(root *pb.Span, env string) Signature {
+	h := new32a()
+	h.Write([]byte(env))
+	h.WriteChar(',')
+	h.Write([]byte(root.Service))
+	h.WriteChar(',')
+	h.Write([]byte(root.Name))
+	h.WriteChar(',')
+	h.Write([]byte(root.Resource))
+	return Signature(h.Sum32())
+}
+
+var _ AdditionalMetricsReporter = (*LatencySampler)(nil)
+
+func (s *LatencySampler) report(statsd statsd.ClientInterface) {
+	if !s.enabled {
+		return
+	}
+	_ = statsd.Count(MetricsLatencyHits, s.hits.Swap(0), nil, 1)
+	_ = statsd.Count(MetricsLatencyMisses, s.misses.Swap(0), nil, 1)
+	_ = statsd.Gauge(MetricSamplerSize, float64(s.size()), []string{"sampler:" + NameLatency.String()}, 1)
+}
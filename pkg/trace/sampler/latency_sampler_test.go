@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package sampler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+func newTestLatencySampler(dynConf *DynamicConfig) *LatencySampler {
+	conf := config.New()
+	conf.LatencySamplerEnabled = true
+	conf.LatencySamplerPercentile = 0.9
+	conf.LatencySamplerTPS = 1
+	conf.LatencySamplerCardinality = 2
+	return NewLatencySampler(conf, dynConf)
+}
+
+func latencyRoot(resource string, d time.Duration) *pb.Span {
+	return &pb.Span{Service: "web", Name: "http.request", Resource: resource, Duration: int64(d)}
+}
+
+func TestLatencySamplerIsSlow(t *testing.T) {
+	assert := assert.New(t)
+	s := newTestLatencySampler(NewDynamicConfig())
+	now := time.Unix(1700000000, 0).Truncate(latencyWindow)
+
+	// not enough traces to know the latency distribution
+	for i := 1; i < latencyMinCount; i++ {
+		assert.False(s.IsSlow(now, latencyRoot("GET /", time.Millisecond), "prod"))
+	}
+	// the current window is used until a complete one is available
+	for i := 1; i <= 100; i++ {
+		s.IsSlow(now, latencyRoot("GET /", time.Duration(i)*time.Millisecond), "prod")
+	}
+	assert.True(s.IsSlow(now, latencyRoot("GET /", 2*time.Second), "prod"))
+	assert.False(s.IsSlow(now, latencyRoot("GET /", time.Millisecond), "prod"))
+
+	// thresholds are computed for each resource and env
+	for i := 1; i <= 100; i++ {
+		s.IsSlow(now, latencyRoot("GET /", time.Duration(i)*time.Second), "staging")
+	}
+	assert.False(s.IsSlow(now, latencyRoot("GET /", 2*time.Second), "staging"))
+
+	// the threshold of the last complete window applies
+	now = now.Add(latencyWindow)
+	assert.False(s.IsSlow(now, latencyRoot("GET /", 50*time.Millisecond), "prod"))
+	assert.True(s.IsSlow(now, latencyRoot("GET /", 150*time.Millisecond), "prod"))
+
+	// signatures above the cardinality limit are not tracked
+	for i := 0; i < 2*latencyMinCount; i++ {
+		assert.False(s.IsSlow(now, latencyRoot("GET /users", time.Duration(i)*time.Hour), "prod"))
+	}
+	assert.Equal(2, s.size())
+
+	// signatures without traffic are removed
+	now = now.Add(latencyWindow)
+	s.IsSlow(now, latencyRoot("GET /", time.Millisecond), "prod")
+	now = now.Add(latencyWindow)
+	s.IsSlow(now, latencyRoot("GET /", time.Millisecond), "prod")
+	assert.Equal(1, s.size())
+}
+
+func TestLatencySamplerDisabled(t *testing.T) {
+	s := NewLatencySampler(config.New(), NewDynamicConfig())
+	now := time.Now()
+	for i := 0; i < 2*latencyMinCount; i++ {
+		assert.False(t, s.IsSlow(now, latencyRoot("GET /", time.Duration(i)*time.Second), "prod"))
+	}
+	assert.False(t, s.Sample(now, latencyRoot("GET /", time.Hour)))
+	assert.Equal(t, 0, s.size())
+}
+
+func TestLatencySamplerSample(t *testing.T) {
+	assert := assert.New(t)
+	dynConf := NewDynamicConfig()
+	dynConf.BytesBudget = NewBytesBudget(1000)
+	s := newTestLatencySampler(dynConf)
+	now := time.Unix(1700000000, 0).Truncate(latencyWindow)
+
+	for i := 0; i < latencySamplerBurst; i++ {
+		root := latencyRoot("GET /", time.Second)
+		assert.True(s.Sample(now, root))
+		assert.Equal(1.0, root.Metrics[latencyKey])
+	}
+	assert.False(s.Sample(now, latencyRoot("GET /", time.Second)))
+	assert.EqualValues(latencySamplerBurst, s.hits.Load())
+	assert.EqualValues(1, s.misses.Load())
+
+	// no slow trace is kept once the bytes budget is exceeded
+	now = now.Add(time.Minute)
+	dynConf.BytesBudget.Count(now, 1000)
+	assert.False(s.Sample(now.Add(10*time.Second), latencyRoot("GET /", time.Second)))
+	assert.EqualValues(2, s.misses.Load())
+}
@@ -44,9 +44,9 @@ func Test_Metrics(t *testing.T) {
 		metrics := sampler.NewMetrics(statsdClient)
 		metrics.Add(
 			sampler.NewPrioritySampler(&config.AgentConfig{}, &sampler.DynamicConfig{}),
-			sampler.NewNoPrioritySampler(&config.AgentConfig{}),
-			sampler.NewErrorsSampler(&config.AgentConfig{}),
-			sampler.NewRareSampler(&config.AgentConfig{}),
+			sampler.NewNoPrioritySampler(&config.AgentConfig{}, sampler.NewDynamicConfig()),
+			sampler.NewErrorsSampler(&config.AgentConfig{}, sampler.NewDynamicConfig()),
+			sampler.NewRareSampler(&config.AgentConfig{}, sampler.NewDynamicConfig()),
 		)
 		statsdClient.EXPECT().Gauge(sampler.MetricSamplerSize, float64(0), []string{"sampler:priority"}, float64(1)).Times(1)
 		statsdClient.EXPECT().Gauge(sampler.MetricSamplerSize, float64(0), []string{"sampler:no_priority"}, float64(1)).Times(1)
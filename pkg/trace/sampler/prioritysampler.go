@@ -55,6 +55,7 @@ func NewPrioritySampler(conf *config.AgentConfig, dynConf *DynamicConfig) *Prior
 		rateByService: &dynConf.RateByService,
 		catalog:       newServiceLookup(conf.MaxCatalogEntries),
 	}
+	s.catalog.budget = dynConf.BytesBudget
 	return s
 }
 
@@ -133,7 +134,7 @@ func (s *PrioritySampler) applyRate(root *pb.Span, signature Signature) float64
 	if rate, ok := getMetric(root, deprecatedRateKey); ok {
 		return rate
 	}
-	rate := s.sampler.getSignatureSampleRate(signature)
+	rate := s.sampler.getSignatureSampleRate(signature) * s.catalog.budget.Rate()
 
 	setMetric(root, deprecatedRateKey, rate)
 
@@ -62,7 +62,34 @@ func (ps *ProbabilisticSampler) Sample(root *trace.Span) bool {
 	if !ps.enabled {
 		return false
 	}
+	hash, err := ps.hash(root)
+	if err != nil {
+		log.Errorf("Unable to probabilistically sample, failed to determine 128-bit trace ID from incoming span: %v", err)
+		return false
+	}
+	keep := hash < ps.scaledSamplingPercentage
+	if keep {
+		setMetric(root, probRateKey, ps.samplingPercentage)
+	}
+	return keep
+}
 
+// HashBucket returns the hash bucket of the trace of the given root span, along with the
+// threshold under which traces are kept. ok is false if the sampler is disabled or if the
+// trace ID can not be determined.
+func (ps *ProbabilisticSampler) HashBucket(root *trace.Span) (hash, threshold uint32, ok bool) {
+	if !ps.enabled {
+		return 0, 0, false
+	}
+	hash, err := ps.hash(root)
+	if err != nil {
+		return 0, 0, false
+	}
+	return hash, ps.scaledSamplingPercentage, true
+}
+
+// hash returns the hash bucket of the trace ID of the given root span.
+func (ps *ProbabilisticSampler) hash(root *trace.Span) (uint32, error) {
 	tid := make([]byte, 16)
 	var err error
 	if !ps.fullTraceIDMode {
@@ -71,19 +98,13 @@ func (ps *ProbabilisticSampler) Sample(root *trace.Span) bool {
 		tid, err = get128BitTraceID(root)
 	}
 	if err != nil {
-		log.Errorf("Unable to probabilistically sample, failed to determine 128-bit trace ID from incoming span: %v", err)
-		return false
+		return 0, err
 	}
 
 	hasher := fnv.New32a()
 	_, _ = hasher.Write(ps.hashSeed)
 	_, _ = hasher.Write(tid)
-	hash := hasher.Sum32()
-	keep := hash&bitMaskHashBuckets < ps.scaledSamplingPercentage
-	if keep {
-		setMetric(root, probRateKey, ps.samplingPercentage)
-	}
-	return keep
+	return hasher.Sum32() & bitMaskHashBuckets, nil
 }
 
 func get128BitTraceID(span *trace.Span) ([]byte, error) {
@@ -47,6 +47,7 @@ type RareSampler struct {
 	mu      sync.RWMutex
 
 	limiter     *rate.Limiter
+	budget      *BytesBudget
 	ttl         time.Duration
 	cardinality int
 	seen        map[Signature]*seenSpans
@@ -54,13 +55,14 @@ type RareSampler struct {
 
 // NewRareSampler returns a NewRareSampler that ensures that we sample combinations
 // of env, service, name, resource, http-status, error type for each top level or measured spans
-func NewRareSampler(conf *config.AgentConfig) *RareSampler {
+func NewRareSampler(conf *config.AgentConfig, dynConf *DynamicConfig) *RareSampler {
 	e := &RareSampler{
 		enabled:     atomic.NewBool(conf.RareSamplerEnabled),
 		hits:        atomic.NewInt64(0),
 		misses:      atomic.NewInt64(0),
 		shrinks:     atomic.NewInt64(0),
 		limiter:     rate.NewLimiter(rate.Limit(conf.RareSamplerTPS), rareSamplerBurst),
+		budget:      dynConf.BytesBudget,
 		ttl:         conf.RareSamplerCooldownPeriod,
 		cardinality: conf.RareSamplerCardinality,
 		seen:        make(map[Signature]*seenSpans),
@@ -130,7 +132,7 @@ func (e *RareSampler) sampleSpan(now time.Time, env string, s *pb.Span) bool {
 	sig := ss.sign(s)
 	expire, ok := ss.getExpire(sig)
 	if now.After(expire) || !ok {
-		sampled = e.limiter.Allow()
+		sampled = !e.budget.Exceeded(now) && e.limiter.Allow()
 		if sampled {
 			ss.add(now.Add(e.ttl), s)
 			e.hits.Inc()
@@ -36,7 +36,7 @@ func TestSpanSeenTTLExpiration(t *testing.T) {
 		{"p0-ttl-active", false, testTime.Add(ttl + time.Nanosecond), map[string]float64{"_top_level": 1}, PriorityNone},
 	}
 
-	e := NewRareSampler(c)
+	e := NewRareSampler(c, NewDynamicConfig())
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -65,7 +65,7 @@ func TestConsideredSpans(t *testing.T) {
 
 	c := config.New()
 	c.RareSamplerEnabled = true
-	e := NewRareSampler(c)
+	e := NewRareSampler(c, NewDynamicConfig())
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -77,7 +77,7 @@ func TestConsideredSpans(t *testing.T) {
 }
 
 func TestRareSamplerRace(_ *testing.T) {
-	e := NewRareSampler(config.New())
+	e := NewRareSampler(config.New(), NewDynamicConfig())
 	for i := 0; i < 2; i++ {
 		go func() {
 			for j := 0; j < 100; j++ {
@@ -92,7 +92,7 @@ func TestCardinalityLimit(t *testing.T) {
 	assert := assert.New(t)
 	c := config.New()
 	c.RareSamplerEnabled = true
-	e := NewRareSampler(c)
+	e := NewRareSampler(c, NewDynamicConfig())
 	for j := 1; j <= c.RareSamplerCardinality; j++ {
 		span := &pb.Span{Resource: strconv.Itoa(j), Metrics: map[string]float64{"_top_level": 1}}
 		e.Sample(time.Now(), getTraceChunkWithSpanAndPriority(span, PriorityAutoKeep), "")
@@ -115,7 +115,7 @@ func TestMultipleTopeLevels(t *testing.T) {
 	assert := assert.New(t)
 	c := config.New()
 	c.RareSamplerEnabled = true
-	e := NewRareSampler(c)
+	e := NewRareSampler(c, NewDynamicConfig())
 	now := time.Unix(13829192398, 0)
 	trace1 := getTraceChunkWithSpansAndPriority(
 		[]*pb.Span{
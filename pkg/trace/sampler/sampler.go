@@ -216,6 +216,33 @@ func getMetricDefault(s *pb.Span, k string, def float64) float64 {
 	return def
 }
 
+// samplingRateKeys holds the metric keys of the rates set by the agent samplers.
+var samplingRateKeys = []string{
+	agentRateKey,
+	ruleRateKey,
+	probRateKey,
+	rareKey,
+	latencyKey,
+	errorsRateKey,
+	noPriorityRateKey,
+	KeySamplingRateGlobal,
+}
+
+// SamplingRates returns the sampling rates set by the samplers on the given span, keyed
+// by metric name. It returns nil if no rate was found.
+func SamplingRates(s *pb.Span) map[string]float64 {
+	var rates map[string]float64
+	for _, k := range samplingRateKeys {
+		if v, ok := getMetric(s, k); ok {
+			if rates == nil {
+				rates = make(map[string]float64)
+			}
+			rates[k] = v
+		}
+	}
+	return rates
+}
+
 // setMetric sets a value in the span Metrics map.
 func setMetric(s *pb.Span, key string, val float64) {
 	if s.Metrics == nil {
@@ -35,15 +35,17 @@ type ScoreSampler struct {
 	*Sampler
 	samplingRateKey string
 	disabled        bool
+	// budget scales the sampling rates so that the kept chunks stay within the bytes budget
+	budget          *BytesBudget
 	mu              sync.Mutex
 	shrinkAllowList map[Signature]float64
 }
 
 // NewNoPrioritySampler returns an initialized Sampler dedicated to traces with
 // no priority set.
-func NewNoPrioritySampler(conf *config.AgentConfig) *NoPrioritySampler {
+func NewNoPrioritySampler(conf *config.AgentConfig, dynConf *DynamicConfig) *NoPrioritySampler {
 	s := newSampler(conf.ExtraSampleRate, conf.TargetTPS)
-	return &NoPrioritySampler{ScoreSampler{Sampler: s, samplingRateKey: noPriorityRateKey}}
+	return &NoPrioritySampler{ScoreSampler{Sampler: s, samplingRateKey: noPriorityRateKey, budget: dynConf.BytesBudget}}
 }
 
 var _ AdditionalMetricsReporter = (*NoPrioritySampler)(nil)
@@ -55,9 +57,9 @@ func (s *NoPrioritySampler) report(statsd statsd.ClientInterface) {
 // NewErrorsSampler returns an initialized Sampler dedicate to errors. It behaves
 // just like the normal ScoreEngine except for its GetType method (useful
 // for reporting).
-func NewErrorsSampler(conf *config.AgentConfig) *ErrorsSampler {
+func NewErrorsSampler(conf *config.AgentConfig, dynConf *DynamicConfig) *ErrorsSampler {
 	s := newSampler(conf.ExtraSampleRate, conf.ErrorTPS)
-	return &ErrorsSampler{ScoreSampler{Sampler: s, samplingRateKey: errorsRateKey, disabled: conf.ErrorTPS == 0}}
+	return &ErrorsSampler{ScoreSampler{Sampler: s, samplingRateKey: errorsRateKey, disabled: conf.ErrorTPS == 0, budget: dynConf.BytesBudget}}
 }
 
 var _ AdditionalMetricsReporter = (*ErrorsSampler)(nil)
@@ -81,7 +83,7 @@ func (s *ScoreSampler) Sample(now time.Time, trace pb.Trace, root *pb.Span, env
 	// Update sampler state by counting this trace
 	s.countWeightedSig(now, signature, weightRoot(root))
 
-	rate := s.getSignatureSampleRate(signature)
+	rate := s.getSignatureSampleRate(signature) * s.budget.Rate()
 
 	sampled := s.applySampleRate(root, rate)
 	return sampled
@@ -27,7 +27,7 @@ func getTestErrorsSampler(tps float64) *ErrorsSampler {
 		ExtraSampleRate: 1,
 		ErrorTPS:        tps,
 	}
-	return NewErrorsSampler(conf)
+	return NewErrorsSampler(conf, NewDynamicConfig())
 }
 
 func getTestTrace() (pb.Trace, *pb.Span) {
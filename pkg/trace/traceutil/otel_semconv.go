@@ -0,0 +1,204 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package traceutil
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	semconv117 "go.opentelemetry.io/collector/semconv/v1.17.0"
+	semconv126 "go.opentelemetry.io/collector/semconv/v1.26.0"
+	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+// SemConvAttr is a concept of the OpenTelemetry semantic conventions which is used to derive
+// Datadog semantics, and whose attribute key may differ across semantic conventions versions.
+type SemConvAttr int
+
+const (
+	// SemConvHTTPMethod is the HTTP request method.
+	SemConvHTTPMethod SemConvAttr = iota
+	// SemConvHTTPRoute is the matched route of an HTTP server request.
+	SemConvHTTPRoute
+	// SemConvHTTPStatusCode is the HTTP response status code.
+	SemConvHTTPStatusCode
+	// SemConvDBSystem is the database management system.
+	SemConvDBSystem
+	// SemConvDBQuery is the database query text.
+	SemConvDBQuery
+	// SemConvMessagingSystem is the messaging system.
+	SemConvMessagingSystem
+	// SemConvMessagingOperation is the type of messaging operation.
+	SemConvMessagingOperation
+	// SemConvMessagingDestination is the messaging destination name.
+	SemConvMessagingDestination
+	// SemConvRPCSystem is the RPC system.
+	SemConvRPCSystem
+	// SemConvRPCService is the RPC service.
+	SemConvRPCService
+	// SemConvRPCMethod is the RPC method.
+	SemConvRPCMethod
+	// SemConvGraphQLOperationType is the type of GraphQL operation.
+	SemConvGraphQLOperationType
+	// SemConvGraphQLOperationName is the name of the GraphQL operation.
+	SemConvGraphQLOperationName
+	// SemConvErrorType is the class of error the operation ended with.
+	SemConvErrorType
+)
+
+// SemConvKey is an attribute key of the semantic conventions, along with the version
+// which introduced it.
+type SemConvKey struct {
+	// Key is the attribute key.
+	Key string
+	// Since is the semantic conventions version which introduced the key.
+	Since string
+}
+
+// semConvMappings holds the attribute keys of each concept across semantic conventions versions,
+// by order of precedence. Keys are mostly ordered from the newest to the oldest version: when several
+// of them are present, as happens when instrumentations emit both the stable and the deprecated
+// attributes during a migration, the newest one takes precedence.
+var semConvMappings = map[SemConvAttr][]SemConvKey{
+	// The HTTP conventions were stabilized in v1.23.
+	// See https://opentelemetry.io/docs/specs/semconv/http/migration-guide/#summary-of-changes
+	SemConvHTTPMethod: {
+		{Key: semconv126.AttributeHTTPRequestMethod, Since: "1.23.0"},
+		{Key: semconv.AttributeHTTPMethod, Since: "1.6.1"},
+	},
+	SemConvHTTPRoute: {
+		{Key: semconv.AttributeHTTPRoute, Since: "1.6.1"},
+	},
+	SemConvHTTPStatusCode: {
+		{Key: semconv126.AttributeHTTPResponseStatusCode, Since: "1.23.0"},
+		{Key: semconv.AttributeHTTPStatusCode, Since: "1.6.1"},
+	},
+	// The database conventions were reworked in v1.26 and stabilized in v1.33.
+	// See https://opentelemetry.io/docs/specs/semconv/database/database-spans/
+	SemConvDBSystem: {
+		{Key: "db.system.name", Since: "1.33.0"},
+		{Key: semconv.AttributeDBSystem, Since: "1.6.1"},
+	},
+	SemConvDBQuery: {
+		{Key: semconv126.AttributeDBQueryText, Since: "1.26.0"},
+		{Key: semconv.AttributeDBStatement, Since: "1.6.1"},
+	},
+	// The messaging conventions renamed the operation and destination attributes in v1.26 and v1.17.
+	// See https://opentelemetry.io/docs/specs/semconv/messaging/messaging-spans/
+	SemConvMessagingSystem: {
+		{Key: semconv.AttributeMessagingSystem, Since: "1.6.1"},
+	},
+	SemConvMessagingOperation: {
+		{Key: semconv126.AttributeMessagingOperationType, Since: "1.26.0"},
+		{Key: semconv.AttributeMessagingOperation, Since: "1.6.1"},
+	},
+	// messaging.destination became a namespace in v1.17, so it is only set by older instrumentations
+	// and keeps precedence over messaging.destination.name.
+	SemConvMessagingDestination: {
+		{Key: semconv.AttributeMessagingDestination, Since: "1.6.1"},
+		{Key: semconv117.AttributeMessagingDestinationName, Since: "1.17.0"},
+	},
+	SemConvRPCSystem: {
+		{Key: semconv.AttributeRPCSystem, Since: "1.6.1"},
+	},
+	SemConvRPCService: {
+		{Key: semconv.AttributeRPCService, Since: "1.6.1"},
+	},
+	SemConvRPCMethod: {
+		{Key: semconv.AttributeRPCMethod, Since: "1.6.1"},
+	},
+	SemConvGraphQLOperationType: {
+		{Key: semconv117.AttributeGraphqlOperationType, Since: "1.17.0"},
+	},
+	SemConvGraphQLOperationName: {
+		{Key: semconv117.AttributeGraphqlOperationName, Since: "1.17.0"},
+	},
+	SemConvErrorType: {
+		{Key: semconv126.AttributeErrorType, Since: "1.21.0"},
+	},
+}
+
+// semConvPeerTagPrecursors maps the concepts from which peer tags are derived to the peer tag
+// precursor key they are aggregated under. The precursor keys (see pkg/trace/config/peer_tags.ini)
+// do not include the attribute keys introduced by the newest semantic conventions versions, whose
+// values must be reported under the older key for peer tags to be derived.
+var semConvPeerTagPrecursors = map[SemConvAttr]string{
+	SemConvDBSystem: semconv.AttributeDBSystem,
+}
+
+// semConvKeys caches the attribute keys of semConvMappings.
+var semConvKeys = func() map[SemConvAttr][]string {
+	m := make(map[SemConvAttr][]string, len(semConvMappings))
+	for attr, keys := range semConvMappings {
+		for _, k := range keys {
+			m[attr] = append(m[attr], k.Key)
+		}
+	}
+	return m
+}()
+
+// SemConvMappings returns the attribute keys of the given concept along with the semantic
+// conventions versions which introduced them, by order of precedence.
+func SemConvMappings(attr SemConvAttr) []SemConvKey {
+	return semConvMappings[attr]
+}
+
+// SemConvKeys returns the attribute keys of the given concept, by order of precedence.
+func SemConvKeys(attr SemConvAttr) []string {
+	return semConvKeys[attr]
+}
+
+// GetOTelSemConvAttr returns the value of the given concept in the input map, whichever semantic
+// conventions version it follows.
+// If normalize is true, normalize the return value with NormalizeTagValue.
+func GetOTelSemConvAttr(attrs pcommon.Map, normalize bool, attr SemConvAttr) string {
+	return GetOTelAttrVal(attrs, normalize, SemConvKeys(attr)...)
+}
+
+// GetOTelSemConvAttrInResAndSpanAttrs returns the value of the given concept in the OTel resource
+// attributes and span attributes, whichever semantic conventions version it follows.
+// If the concept is present in both resource attributes and span attributes, resource attributes take precedence.
+// If normalize is true, normalize the return value with NormalizeTagValue.
+func GetOTelSemConvAttrInResAndSpanAttrs(span ptrace.Span, res pcommon.Resource, normalize bool, attr SemConvAttr) string {
+	return GetOTelAttrValInResAndSpanAttrs(span, res, normalize, SemConvKeys(attr)...)
+}
+
+// GetSemConvAttrFromMeta returns the value of the given concept in the given span meta,
+// whichever semantic conventions version it follows.
+func GetSemConvAttrFromMeta(meta map[string]string, attr SemConvAttr) string {
+	for _, key := range SemConvKeys(attr) {
+		if val := meta[key]; val != "" {
+			return val
+		}
+	}
+	return ""
+}
+
+// SetOTelPeerTagPrecursors sets in meta the peer tag precursors of the span which are only
+// found under the keys of newer semantic conventions versions in the OTel resource attributes
+// and span attributes. Precursors already set in meta are kept.
+func SetOTelPeerTagPrecursors(span ptrace.Span, res pcommon.Resource, meta map[string]string) {
+	for attr, precursor := range semConvPeerTagPrecursors {
+		if meta[precursor] != "" {
+			continue
+		}
+		if val := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, attr); val != "" {
+			meta[precursor] = val
+		}
+	}
+}
+
+// SetPeerTagPrecursorsFromMeta sets in meta the peer tag precursors which are only found
+// under the keys of newer semantic conventions versions in meta. Precursors already set are kept.
+func SetPeerTagPrecursorsFromMeta(meta map[string]string) {
+	for attr, precursor := range semConvPeerTagPrecursors {
+		if meta[precursor] != "" {
+			continue
+		}
+		if val := GetSemConvAttrFromMeta(meta, attr); val != "" {
+			meta[precursor] = val
+		}
+	}
+}
@@ -15,7 +15,6 @@ import (
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 	semconv117 "go.opentelemetry.io/collector/semconv/v1.17.0"
-	semconv126 "go.opentelemetry.io/collector/semconv/v1.26.0"
 	semconv "go.opentelemetry.io/collector/semconv/v1.6.1"
 	"go.opentelemetry.io/otel/attribute"
 
@@ -230,7 +229,7 @@ func SpanKind2Type(span ptrace.Span, res pcommon.Resource) string {
 		typ = "web"
 	case ptrace.SpanKindClient:
 		typ = "http"
-		db := GetOTelAttrValInResAndSpanAttrs(span, res, true, semconv.AttributeDBSystem)
+		db := GetOTelSemConvAttrInResAndSpanAttrs(span, res, true, SemConvDBSystem)
 		if db == "" {
 			break
 		}
@@ -257,7 +256,7 @@ func GetOTelSpanType(span ptrace.Span, res pcommon.Resource) string {
 	case ptrace.SpanKindServer:
 		typ = "web"
 	case ptrace.SpanKindClient:
-		db := GetOTelAttrValInResAndSpanAttrs(span, res, true, semconv.AttributeDBSystem)
+		db := GetOTelSemConvAttrInResAndSpanAttrs(span, res, true, SemConvDBSystem)
 		if db == "" {
 			typ = "http"
 		} else {
@@ -293,30 +292,30 @@ func GetOTelService(res pcommon.Resource, normalize bool) string {
 func GetOTelResourceV1(span ptrace.Span, res pcommon.Resource) (resName string) {
 	resName = GetOTelAttrValInResAndSpanAttrs(span, res, false, "resource.name")
 	if resName == "" {
-		if m := GetOTelAttrValInResAndSpanAttrs(span, res, false, "http.request.method", semconv.AttributeHTTPMethod); m != "" {
+		if m := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvHTTPMethod); m != "" {
 			// use the HTTP method + route (if available)
 			resName = m
-			if route := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv.AttributeHTTPRoute); route != "" {
+			if route := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvHTTPRoute); route != "" {
 				resName = resName + " " + route
 			}
-		} else if m := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv.AttributeMessagingOperation); m != "" {
+		} else if m := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvMessagingOperation); m != "" {
 			resName = m
 			// use the messaging operation
-			if dest := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv.AttributeMessagingDestination, semconv117.AttributeMessagingDestinationName); dest != "" {
+			if dest := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvMessagingDestination); dest != "" {
 				resName = resName + " " + dest
 			}
-		} else if m := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv.AttributeRPCMethod); m != "" {
+		} else if m := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvRPCMethod); m != "" {
 			resName = m
 			// use the RPC method
-			if svc := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv.AttributeRPCService); m != "" {
+			if svc := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvRPCService); m != "" {
 				// ...and service if available
 				resName = resName + " " + svc
 			}
-		} else if m := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv117.AttributeGraphqlOperationType); m != "" {
+		} else if m := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvGraphQLOperationType); m != "" {
 			// Enrich GraphQL query resource names.
 			// See https://github.com/open-telemetry/semantic-conventions/blob/v1.29.0/docs/graphql/graphql-spans.md
 			resName = m
-			if name := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv117.AttributeGraphqlOperationName); name != "" {
+			if name := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvGraphQLOperationName); name != "" {
 				resName = resName + " " + name
 			}
 		} else {
@@ -341,57 +340,53 @@ func GetOTelResourceV2(span ptrace.Span, res pcommon.Resource) (resName string)
 		return
 	}
 
-	if m := GetOTelAttrValInResAndSpanAttrs(span, res, false, "http.request.method", semconv.AttributeHTTPMethod); m != "" {
+	if m := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvHTTPMethod); m != "" {
 		if m == "_OTHER" {
 			m = "HTTP"
 		}
 		// use the HTTP method + route (if available)
 		resName = m
 		if span.Kind() == ptrace.SpanKindServer {
-			if route := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv.AttributeHTTPRoute); route != "" {
+			if route := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvHTTPRoute); route != "" {
 				resName = resName + " " + route
 			}
 		}
 		return
 	}
 
-	if m := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv.AttributeMessagingOperation); m != "" {
+	if m := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvMessagingOperation); m != "" {
 		resName = m
 		// use the messaging operation
-		if dest := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv.AttributeMessagingDestination, semconv117.AttributeMessagingDestinationName); dest != "" {
+		if dest := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvMessagingDestination); dest != "" {
 			resName = resName + " " + dest
 		}
 		return
 	}
 
-	if m := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv.AttributeRPCMethod); m != "" {
+	if m := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvRPCMethod); m != "" {
 		resName = m
 		// use the RPC method
-		if svc := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv.AttributeRPCService); m != "" {
+		if svc := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvRPCService); m != "" {
 			// ...and service if available
 			resName = resName + " " + svc
 		}
 		return
 	}
 
-	if m := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv117.AttributeGraphqlOperationType); m != "" {
+	if m := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvGraphQLOperationType); m != "" {
 		// Enrich GraphQL query resource names.
 		// See https://github.com/open-telemetry/semantic-conventions/blob/v1.29.0/docs/graphql/graphql-spans.md
 		resName = m
-		if name := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv117.AttributeGraphqlOperationName); name != "" {
+		if name := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvGraphQLOperationName); name != "" {
 			resName = resName + " " + name
 		}
 		return
 	}
 
-	if m := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv.AttributeDBSystem); m != "" {
+	if m := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvDBSystem); m != "" {
 		// Since traces are obfuscated by span.Resource in pkg/trace/agent/obfuscate.go, we should use span.Resource as the resource name.
 		// https://github.com/DataDog/datadog-agent/blob/62619a69cff9863f5b17215847b853681e36ff15/pkg/trace/agent/obfuscate.go#L32
-		if dbStatement := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv.AttributeDBStatement); dbStatement != "" {
-			resName = dbStatement
-			return
-		}
-		if dbQuery := GetOTelAttrValInResAndSpanAttrs(span, res, false, semconv126.AttributeDBQueryText); dbQuery != "" {
+		if dbQuery := GetOTelSemConvAttrInResAndSpanAttrs(span, res, false, SemConvDBQuery); dbQuery != "" {
 			resName = dbQuery
 			return
 		}
@@ -414,7 +409,7 @@ func GetOTelOperationNameV2(
 	isServer := span.Kind() == ptrace.SpanKindServer
 
 	// http
-	if method := GetOTelAttrVal(span.Attributes(), false, "http.request.method", semconv.AttributeHTTPMethod); method != "" {
+	if method := GetOTelSemConvAttr(span.Attributes(), false, SemConvHTTPMethod); method != "" {
 		if isServer {
 			return "http.server.request"
 		}
@@ -424,13 +419,13 @@ func GetOTelOperationNameV2(
 	}
 
 	// database
-	if v := GetOTelAttrVal(span.Attributes(), true, semconv.AttributeDBSystem); v != "" && isClient {
+	if v := GetOTelSemConvAttr(span.Attributes(), true, SemConvDBSystem); v != "" && isClient {
 		return v + ".query"
 	}
 
 	// messaging
-	system := GetOTelAttrVal(span.Attributes(), true, semconv.AttributeMessagingSystem)
-	op := GetOTelAttrVal(span.Attributes(), true, semconv.AttributeMessagingOperation)
+	system := GetOTelSemConvAttr(span.Attributes(), true, SemConvMessagingSystem)
+	op := GetOTelSemConvAttr(span.Attributes(), true, SemConvMessagingOperation)
 	if system != "" && op != "" {
 		switch span.Kind() {
 		case ptrace.SpanKindClient, ptrace.SpanKindServer, ptrace.SpanKindConsumer, ptrace.SpanKindProducer:
@@ -439,12 +434,12 @@ func GetOTelOperationNameV2(
 	}
 
 	// RPC & AWS
-	rpcValue := GetOTelAttrVal(span.Attributes(), true, semconv.AttributeRPCSystem)
+	rpcValue := GetOTelSemConvAttr(span.Attributes(), true, SemConvRPCSystem)
 	isRPC := rpcValue != ""
 	isAws := isRPC && (rpcValue == "aws-api")
 	// AWS client
 	if isAws && isClient {
-		if service := GetOTelAttrVal(span.Attributes(), true, semconv.AttributeRPCService); service != "" {
+		if service := GetOTelSemConvAttr(span.Attributes(), true, SemConvRPCService); service != "" {
 			return "aws." + service + ".request"
 		}
 		return "aws.client.request"
@@ -472,7 +467,7 @@ func GetOTelOperationNameV2(
 	}
 
 	// GraphQL
-	if GetOTelAttrVal(span.Attributes(), true, "graphql.operation.type") != "" {
+	if GetOTelSemConvAttr(span.Attributes(), true, SemConvGraphQLOperationType) != "" {
 		return "graphql.server.request"
 	}
 
@@ -570,11 +565,10 @@ func GetOTelHostname(span ptrace.Span, res pcommon.Resource, tr *attributes.Tran
 
 // GetOTelStatusCode returns the DD status code of the OTel span.
 func GetOTelStatusCode(span ptrace.Span) uint32 {
-	if code, ok := span.Attributes().Get("http.response.status_code"); ok {
-		return uint32(code.Int())
-	}
-	if code, ok := span.Attributes().Get(semconv.AttributeHTTPStatusCode); ok {
-		return uint32(code.Int())
+	for _, key := range SemConvKeys(SemConvHTTPStatusCode) {
+		if code, ok := span.Attributes().Get(key); ok {
+			return uint32(code.Int())
+		}
 	}
 	return 0
 }
@@ -6,6 +6,8 @@
 package transform
 
 import (
+	"strings"
+
 	"github.com/DataDog/datadog-agent/pkg/obfuscate"
 	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
 	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
@@ -18,6 +20,8 @@ const (
 	TagValkeyRawCommand = "valkey.raw_command"
 	// TagMemcachedCommand represents a memcached command tag
 	TagMemcachedCommand = "memcached.command"
+	// TagGraphQLQuery represents a GraphQL query tag
+	TagGraphQLQuery = "graphql.query"
 	// TagMongoDBQuery represents a MongoDB query tag
 	TagMongoDBQuery = "mongodb.query"
 	// TagElasticBody represents an Elasticsearch body tag
@@ -30,11 +34,31 @@ const (
 	TagHTTPURL = "http.url"
 	// TagDBMS represents a DBMS tag
 	TagDBMS = "db.type"
+	// TagCassandraKeyspace represents a Cassandra keyspace tag
+	TagCassandraKeyspace = "cassandra.keyspace"
+	// TagDBCassandraKeyspace represents the OpenTelemetry Cassandra keyspace tag
+	TagDBCassandraKeyspace = "db.cassandra.keyspace"
+	// TagDynamoDBStatement represents a DynamoDB PartiQL statement tag
+	TagDynamoDBStatement = "aws.dynamodb.statement"
+	// TagDynamoDBRequestBody represents a DynamoDB request body tag
+	TagDynamoDBRequestBody = "aws.dynamodb.request_body"
+	// TagComponent represents the tag holding the name of the instrumented library
+	TagComponent = "component"
+	// TagDBSystem represents the OpenTelemetry database system tag
+	TagDBSystem = "db.system"
+	// TagMessagingSystem represents the OpenTelemetry messaging system tag
+	TagMessagingSystem = "messaging.system"
+	// TagAWSService represents the AWS service tag set by the AWS SDK integrations
+	TagAWSService = "aws.service"
+	// TagAWSServiceLegacy represents the AWS service tag set by older AWS SDK integrations
+	TagAWSServiceLegacy = "aws_service"
 )
 
 const (
 	// TextNonParsable is the error text used when a query is non-parsable
 	TextNonParsable = "Non-parsable SQL query"
+	// TextNonParsableGraphQL is the error text used when a GraphQL query is non-parsable
+	TextNonParsableGraphQL = "Non-parsable GraphQL query"
 )
 
 // ObfuscateSQLSpan obfuscates a SQL span using pkg/obfuscate logic
@@ -80,3 +104,110 @@ func ObfuscateValkeySpan(o *obfuscate.Obfuscator, span *pb.Span, removeAllArgs b
 	}
 	span.Meta[TagValkeyRawCommand] = o.ObfuscateRedisString(span.Meta[TagValkeyRawCommand])
 }
+
+// ObfuscateGraphQLSpan obfuscates a GraphQL span using pkg/obfuscate logic. The "graphql.query"
+// tag is obfuscated and, if the resource holds a GraphQL document, it is quantized.
+func ObfuscateGraphQLSpan(o *obfuscate.Obfuscator, span *pb.Span) error {
+	span.Resource = QuantizeGraphQLResource(o, span.Resource)
+	if span.Meta == nil || span.Meta[TagGraphQLQuery] == "" {
+		return nil
+	}
+	oq, err := o.ObfuscateGraphQLString(span.Meta[TagGraphQLQuery])
+	if err != nil {
+		// we have an error, discard the query to avoid leaking sensitive data.
+		span.Meta[TagGraphQLQuery] = TextNonParsableGraphQL
+		return err
+	}
+	span.Meta[TagGraphQLQuery] = oq
+	return nil
+}
+
+// QuantizeGraphQLResource quantizes the resource of a GraphQL span or stats group. Some tracers
+// use the full query as resource, others only the operation name: resources which do not look
+// like a GraphQL document are returned unchanged.
+func QuantizeGraphQLResource(o *obfuscate.Obfuscator, resource string) string {
+	if !strings.ContainsRune(resource, '{') {
+		return resource
+	}
+	if quantized := o.QuantizeGraphQLString(resource); quantized != "" {
+		return quantized
+	}
+	return TextNonParsableGraphQL
+}
+
+// ObfuscateCQLSpan obfuscates a Cassandra span using pkg/obfuscate CQL logic
+func ObfuscateCQLSpan(o *obfuscate.Obfuscator, span *pb.Span) (*obfuscate.ObfuscatedQuery, error) {
+	if span.Resource == "" {
+		return nil, nil
+	}
+	keyspace := span.Meta[TagCassandraKeyspace]
+	if keyspace == "" {
+		keyspace = span.Meta[TagDBCassandraKeyspace]
+	}
+	oq, err := o.ObfuscateCQLString(span.Resource, keyspace)
+	if err != nil {
+		// we have an error, discard the query to avoid polluting user resources.
+		span.Resource = TextNonParsable
+		traceutil.SetMeta(span, TagSQLQuery, TextNonParsable)
+		return nil, err
+	}
+	span.Resource = oq.Query
+	if len(oq.Metadata.TablesCSV) > 0 {
+		traceutil.SetMeta(span, "sql.tables", oq.Metadata.TablesCSV)
+	}
+	traceutil.SetMeta(span, TagSQLQuery, oq.Query)
+	return oq, nil
+}
+
+// IsDynamoDBSpan returns whether the span is a DynamoDB client span. The AWS SDK integrations
+// share a single component for every AWS service, the service tag tells DynamoDB calls apart.
+func IsDynamoDBSpan(span *pb.Span) bool {
+	if span.Type == "dynamodb" {
+		return true
+	}
+	if span.Meta == nil {
+		return false
+	}
+	return span.Meta[TagDBSystem] == "dynamodb" ||
+		strings.EqualFold(span.Meta[TagAWSService], "dynamodb") ||
+		strings.EqualFold(span.Meta[TagAWSServiceLegacy], "dynamodb")
+}
+
+// IsKafkaSpan returns whether the span is a Kafka producer or consumer span.
+func IsKafkaSpan(span *pb.Span) bool {
+	if span.Type == "kafka" {
+		return true
+	}
+	if span.Meta == nil {
+		return false
+	}
+	return span.Meta[TagMessagingSystem] == "kafka" || strings.Contains(span.Meta[TagComponent], "kafka")
+}
+
+// ObfuscateDynamoDBSpan obfuscates the DynamoDB PartiQL statement and request body tags of a span
+// using pkg/obfuscate logic
+func ObfuscateDynamoDBSpan(o *obfuscate.Obfuscator, span *pb.Span) {
+	if span.Meta == nil {
+		return
+	}
+	if stmt := span.Meta[TagDynamoDBStatement]; stmt != "" {
+		if oq, err := o.ObfuscatePartiQLString(stmt); err != nil {
+			span.Meta[TagDynamoDBStatement] = "?"
+		} else {
+			span.Meta[TagDynamoDBStatement] = oq.Query
+		}
+	}
+	if body := span.Meta[TagDynamoDBRequestBody]; body != "" {
+		span.Meta[TagDynamoDBRequestBody] = o.ObfuscateDynamoDBString(body)
+	}
+}
+
+// ObfuscateKafkaSpan obfuscates the Kafka message key, value and header tags of a span using
+// pkg/obfuscate logic
+func ObfuscateKafkaSpan(o *obfuscate.Obfuscator, span *pb.Span) {
+	for k, v := range span.Meta {
+		if newV := o.ObfuscateKafkaTag(k, v); newV != v {
+			span.Meta[k] = newV
+		}
+	}
+}
@@ -7,6 +7,7 @@
 package transform
 
 import (
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
@@ -161,6 +162,9 @@ func OtelSpanToDDSpanMinimal(
 			ddspan.Meta[peerTagKey] = peerTagVal
 		}
 	}
+	if len(peerTagKeys) > 0 {
+		traceutil.SetOTelPeerTagPrecursors(otelspan, otelres, ddspan.Meta)
+	}
 	return ddspan
 }
 
@@ -271,13 +275,7 @@ func OtelSpanToDDSpan(
 		}
 	}
 
-	if otelspan.Events().Len() > 0 {
-		ddspan.Meta["events"] = MarshalEvents(otelspan.Events())
-	}
-	TagSpanIfContainsExceptionEvent(otelspan, ddspan)
-	if otelspan.Links().Len() > 0 {
-		ddspan.Meta["_dd.span_links"] = MarshalLinks(otelspan.Links())
-	}
+	SetEventsAndLinks(otelspan, ddspan, conf)
 
 	otelspan.Attributes().Range(func(k string, v pcommon.Value) bool {
 		if strings.HasPrefix(k, "datadog.") {
@@ -295,6 +293,8 @@ func OtelSpanToDDSpan(
 		return true
 	})
 
+	traceutil.SetPeerTagPrecursorsFromMeta(ddspan.Meta)
+
 	if otelspan.TraceState().AsRaw() != "" {
 		ddspan.Meta["w3c.tracestate"] = otelspan.TraceState().AsRaw()
 	}
@@ -324,6 +324,27 @@ func OtelSpanToDDSpan(
 	return ddspan
 }
 
+// SetEventsAndLinks sets the events and links of otelspan on ddspan. When the enable_otlp_native_span_events
+// and enable_otlp_native_span_links features are set, they are converted to the SpanEvents and SpanLinks
+// fields of ddspan. Otherwise, they are marshalled into the "events" and "_dd.span_links" tags.
+func SetEventsAndLinks(otelspan ptrace.Span, ddspan *pb.Span, conf *config.AgentConfig) {
+	if otelspan.Events().Len() > 0 {
+		if conf.HasFeature("enable_otlp_native_span_events") {
+			ddspan.SpanEvents = OTelSpanEventsToDD(otelspan.Events())
+		} else {
+			SetMetaOTLP(ddspan, "events", MarshalEvents(otelspan.Events()))
+		}
+	}
+	TagSpanIfContainsExceptionEvent(otelspan, ddspan)
+	if otelspan.Links().Len() > 0 {
+		if conf.HasFeature("enable_otlp_native_span_links") {
+			ddspan.SpanLinks = OTelSpanLinksToDD(otelspan.Links())
+		} else {
+			SetMetaOTLP(ddspan, "_dd.span_links", MarshalLinks(otelspan.Links()))
+		}
+	}
+}
+
 // TagSpanIfContainsExceptionEvent tags spans that contain at least on exception span event.
 func TagSpanIfContainsExceptionEvent(otelspan ptrace.Span, ddspan *pb.Span) {
 	for i := range otelspan.Events().Len() {
@@ -408,6 +429,90 @@ func MarshalEvents(events ptrace.SpanEventSlice) string {
 	return str.String()
 }
 
+// OTelSpanEventsToDD converts OTel span events into Datadog span events.
+func OTelSpanEventsToDD(events ptrace.SpanEventSlice) []*pb.SpanEvent {
+	ddevents := make([]*pb.SpanEvent, 0, events.Len())
+	for i := 0; i < events.Len(); i++ {
+		e := events.At(i)
+		ddevent := &pb.SpanEvent{
+			TimeUnixNano: uint64(e.Timestamp()),
+			Name:         e.Name(),
+		}
+		if e.Attributes().Len() > 0 {
+			ddevent.Attributes = make(map[string]*pb.AttributeAnyValue, e.Attributes().Len())
+			e.Attributes().Range(func(k string, v pcommon.Value) bool {
+				ddevent.Attributes[k] = otelValueToAnyValue(v)
+				return true
+			})
+		}
+		ddevents = append(ddevents, ddevent)
+	}
+	return ddevents
+}
+
+// otelValueToAnyValue converts an OTel attribute value into a span event attribute value.
+// Maps, bytes and nested arrays have no counterpart and are converted to their string representation.
+func otelValueToAnyValue(v pcommon.Value) *pb.AttributeAnyValue {
+	switch v.Type() {
+	case pcommon.ValueTypeBool:
+		return &pb.AttributeAnyValue{Type: pb.AttributeAnyValue_BOOL_VALUE, BoolValue: v.Bool()}
+	case pcommon.ValueTypeInt:
+		return &pb.AttributeAnyValue{Type: pb.AttributeAnyValue_INT_VALUE, IntValue: v.Int()}
+	case pcommon.ValueTypeDouble:
+		return &pb.AttributeAnyValue{Type: pb.AttributeAnyValue_DOUBLE_VALUE, DoubleValue: v.Double()}
+	case pcommon.ValueTypeSlice:
+		values := make([]*pb.AttributeArrayValue, 0, v.Slice().Len())
+		for i := 0; i < v.Slice().Len(); i++ {
+			values = append(values, otelValueToArrayValue(v.Slice().At(i)))
+		}
+		return &pb.AttributeAnyValue{Type: pb.AttributeAnyValue_ARRAY_VALUE, ArrayValue: &pb.AttributeArray{Values: values}}
+	default:
+		return &pb.AttributeAnyValue{Type: pb.AttributeAnyValue_STRING_VALUE, StringValue: v.AsString()}
+	}
+}
+
+// otelValueToArrayValue converts an element of an OTel slice attribute into a span event array value.
+func otelValueToArrayValue(v pcommon.Value) *pb.AttributeArrayValue {
+	switch v.Type() {
+	case pcommon.ValueTypeBool:
+		return &pb.AttributeArrayValue{Type: pb.AttributeArrayValue_BOOL_VALUE, BoolValue: v.Bool()}
+	case pcommon.ValueTypeInt:
+		return &pb.AttributeArrayValue{Type: pb.AttributeArrayValue_INT_VALUE, IntValue: v.Int()}
+	case pcommon.ValueTypeDouble:
+		return &pb.AttributeArrayValue{Type: pb.AttributeArrayValue_DOUBLE_VALUE, DoubleValue: v.Double()}
+	default:
+		return &pb.AttributeArrayValue{Type: pb.AttributeArrayValue_STRING_VALUE, StringValue: v.AsString()}
+	}
+}
+
+// OTelSpanLinksToDD converts OTel span links into Datadog span links.
+func OTelSpanLinksToDD(links ptrace.SpanLinkSlice) []*pb.SpanLink {
+	ddlinks := make([]*pb.SpanLink, 0, links.Len())
+	for i := 0; i < links.Len(); i++ {
+		l := links.At(i)
+		traceID := [16]byte(l.TraceID())
+		ddlink := &pb.SpanLink{
+			TraceID:     traceutil.OTelTraceIDToUint64(traceID),
+			TraceIDHigh: binary.BigEndian.Uint64(traceID[:8]),
+			SpanID:      traceutil.OTelSpanIDToUint64(l.SpanID()),
+			Tracestate:  l.TraceState().AsRaw(),
+		}
+		if flags := l.Flags(); flags != 0 {
+			// the high bit signals that the flags are set
+			ddlink.Flags = flags | 1<<31
+		}
+		if l.Attributes().Len() > 0 {
+			ddlink.Attributes = make(map[string]string, l.Attributes().Len())
+			l.Attributes().Range(func(k string, v pcommon.Value) bool {
+				ddlink.Attributes[k] = v.AsString()
+				return true
+			})
+		}
+		ddlinks = append(ddlinks, ddlink)
+	}
+	return ddlinks
+}
+
 // MarshalLinks marshals span links into JSON.
 func MarshalLinks(links ptrace.SpanLinkSlice) string {
 	var str strings.Builder
@@ -517,7 +622,7 @@ func Status2Error(status ptrace.Status, events ptrace.SpanEventSlice, metaMap ma
 		if status.Message() != "" {
 			// use the status message
 			metaMap["error.msg"] = status.Message()
-		} else if _, httpcode := GetFirstFromMap(metaMap, "http.response.status_code", "http.status_code"); httpcode != "" {
+		} else if _, httpcode := GetFirstFromMap(metaMap, traceutil.SemConvKeys(traceutil.SemConvHTTPStatusCode)...); httpcode != "" {
 			// `http.status_code` was renamed to `http.response.status_code` in the HTTP stabilization from v1.23.
 			// See https://opentelemetry.io/docs/specs/semconv/http/migration-guide/#summary-of-changes
 
@@ -528,6 +633,10 @@ func Status2Error(status ptrace.Status, events ptrace.SpanEventSlice, metaMap ma
 			} else {
 				metaMap["error.msg"] = httpcode
 			}
+		} else if errType := traceutil.GetSemConvAttrFromMeta(metaMap, traceutil.SemConvErrorType); errType != "" {
+			// `error.type` describes the class of error the operation ended with since v1.21.
+			// See https://opentelemetry.io/docs/specs/semconv/attributes-registry/error/
+			metaMap["error.msg"] = errType
 		}
 	}
 	return 1
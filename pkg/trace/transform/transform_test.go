@@ -0,0 +1,211 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+package transform
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	pb "github.com/DataDog/datadog-agent/pkg/proto/pbgo/trace"
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// semConvSpan describes an OTel span used to check the semantic conventions mappings.
+type semConvSpan struct {
+	name       string
+	kind       ptrace.SpanKind
+	status     ptrace.StatusCode
+	attributes map[string]any
+}
+
+// semConvGolden holds the Datadog semantics derived from a semConvSpan.
+type semConvGolden struct {
+	Span       string  `json:"span"`
+	Name       string  `json:"name"`
+	Resource   string  `json:"resource"`
+	Type       string  `json:"type"`
+	Error      int32   `json:"error"`
+	ErrorMsg   string  `json:"error.msg,omitempty"`
+	ErrorType  string  `json:"error.type,omitempty"`
+	StatusCode float64 `json:"http.status_code,omitempty"`
+	// PeerTags holds the peer tag precursors found on the span.
+	PeerTags map[string]string `json:"peer_tags,omitempty"`
+}
+
+// semConvSpans holds the same operations following each semantic conventions version.
+var semConvSpans = map[string][]semConvSpan{
+	"v1.6.1": {
+		{name: "http-server", kind: ptrace.SpanKindServer, attributes: map[string]any{"http.method": "GET", "http.route": "/users/{id}", "http.status_code": 200}},
+		{name: "http-client", kind: ptrace.SpanKindClient, status: ptrace.StatusCodeError, attributes: map[string]any{"http.method": "POST", "http.status_code": 503}},
+		{name: "db-client", kind: ptrace.SpanKindClient, attributes: map[string]any{"db.system": "postgresql", "db.statement": "SELECT * FROM users", "db.name": "app"}},
+		{name: "messaging-producer", kind: ptrace.SpanKindProducer, attributes: map[string]any{"messaging.system": "kafka", "messaging.operation": "publish", "messaging.destination": "orders"}},
+		{name: "rpc-client", kind: ptrace.SpanKindClient, attributes: map[string]any{"rpc.system": "grpc", "rpc.service": "Users", "rpc.method": "Get"}},
+	},
+	"v1.17.0": {
+		{name: "http-server", kind: ptrace.SpanKindServer, attributes: map[string]any{"http.method": "GET", "http.route": "/users/{id}", "http.status_code": 200}},
+		{name: "http-client", kind: ptrace.SpanKindClient, status: ptrace.StatusCodeError, attributes: map[string]any{"http.method": "POST", "http.status_code": 503}},
+		{name: "db-client", kind: ptrace.SpanKindClient, attributes: map[string]any{"db.system": "postgresql", "db.statement": "SELECT * FROM users", "db.name": "app"}},
+		{name: "messaging-producer", kind: ptrace.SpanKindProducer, attributes: map[string]any{"messaging.system": "kafka", "messaging.operation": "publish", "messaging.destination.name": "orders"}},
+		{name: "rpc-client", kind: ptrace.SpanKindClient, attributes: map[string]any{"rpc.system": "grpc", "rpc.service": "Users", "rpc.method": "Get"}},
+		{name: "graphql-server", kind: ptrace.SpanKindServer, attributes: map[string]any{"graphql.operation.type": "query", "graphql.operation.name": "GetUser"}},
+	},
+	"v1.23.0": {
+		{name: "http-server", kind: ptrace.SpanKindServer, attributes: map[string]any{"http.request.method": "GET", "http.route": "/users/{id}", "http.response.status_code": 200}},
+		{name: "http-client", kind: ptrace.SpanKindClient, status: ptrace.StatusCodeError, attributes: map[string]any{"http.request.method": "POST", "http.response.status_code": 503, "error.type": "503"}},
+		{name: "http-client-error-type", kind: ptrace.SpanKindClient, status: ptrace.StatusCodeError, attributes: map[string]any{"http.request.method": "POST", "error.type": "java.net.UnknownHostException"}},
+		{name: "db-client", kind: ptrace.SpanKindClient, attributes: map[string]any{"db.system": "postgresql", "db.statement": "SELECT * FROM users", "db.name": "app"}},
+		{name: "messaging-producer", kind: ptrace.SpanKindProducer, attributes: map[string]any{"messaging.system": "kafka", "messaging.operation": "publish", "messaging.destination.name": "orders"}},
+		{name: "rpc-client", kind: ptrace.SpanKindClient, attributes: map[string]any{"rpc.system": "grpc", "rpc.service": "Users", "rpc.method": "Get"}},
+	},
+	"v1.26.0": {
+		{name: "http-server", kind: ptrace.SpanKindServer, attributes: map[string]any{"http.request.method": "GET", "http.route": "/users/{id}", "http.response.status_code": 200}},
+		{name: "http-client", kind: ptrace.SpanKindClient, status: ptrace.StatusCodeError, attributes: map[string]any{"http.request.method": "POST", "http.response.status_code": 503, "error.type": "503"}},
+		{name: "http-client-error-type", kind: ptrace.SpanKindClient, status: ptrace.StatusCodeError, attributes: map[string]any{"http.request.method": "POST", "error.type": "java.net.UnknownHostException"}},
+		{name: "db-client", kind: ptrace.SpanKindClient, attributes: map[string]any{"db.system": "postgresql", "db.query.text": "SELECT * FROM users", "db.namespace": "app"}},
+		{name: "db-client-migration", kind: ptrace.SpanKindClient, attributes: map[string]any{"db.system": "postgresql", "db.query.text": "SELECT * FROM users", "db.statement": "SELECT * FROM legacy_users"}},
+		{name: "messaging-producer", kind: ptrace.SpanKindProducer, attributes: map[string]any{"messaging.system": "kafka", "messaging.operation.type": "publish", "messaging.destination.name": "orders"}},
+		{name: "rpc-client", kind: ptrace.SpanKindClient, attributes: map[string]any{"rpc.system": "grpc", "rpc.service": "Users", "rpc.method": "Get"}},
+		{name: "graphql-server", kind: ptrace.SpanKindServer, attributes: map[string]any{"graphql.operation.type": "query", "graphql.operation.name": "GetUser"}},
+	},
+	"v1.33.0": {
+		{name: "db-client", kind: ptrace.SpanKindClient, attributes: map[string]any{"db.system.name": "postgresql", "db.query.text": "SELECT * FROM users", "db.namespace": "app"}},
+		{name: "db-client-cache", kind: ptrace.SpanKindClient, attributes: map[string]any{"db.system.name": "redis", "db.query.text": "GET key"}},
+	},
+}
+
+func TestOtelSpanToDDSpanSemConv(t *testing.T) {
+	conf := config.New()
+	conf.PeerTagsAggregation = true
+	peerTagKeys := conf.ConfiguredPeerTags()
+	for version, spans := range semConvSpans {
+		t.Run(version, func(t *testing.T) {
+			var out []semConvGolden
+			for _, s := range spans {
+				span := ptrace.NewSpan()
+				span.SetName(s.name)
+				span.SetKind(s.kind)
+				span.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+				span.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8})
+				span.Status().SetCode(s.status)
+				require.NoError(t, span.Attributes().FromRaw(s.attributes))
+				res := pcommon.NewResource()
+				res.Attributes().PutStr("service.name", "svc")
+
+				ddspan := OtelSpanToDDSpan(span, res, pcommon.NewInstrumentationScope(), conf)
+				var peerTags map[string]string
+				for _, k := range peerTagKeys {
+					if v := ddspan.Meta[k]; v != "" {
+						if peerTags == nil {
+							peerTags = make(map[string]string)
+						}
+						peerTags[k] = v
+					}
+				}
+				// the spans converted for stats computation must get the same peer tags
+				statsSpan := OtelSpanToDDSpanMinimal(span, res, pcommon.NewInstrumentationScope(), false, false, conf, peerTagKeys)
+				for _, k := range peerTagKeys {
+					assert.Equal(t, peerTags[k], statsSpan.Meta[k], "peer tag %s of span %s", k, s.name)
+				}
+				out = append(out, semConvGolden{
+					Span:       s.name,
+					Name:       ddspan.Name,
+					Resource:   ddspan.Resource,
+					Type:       ddspan.Type,
+					Error:      ddspan.Error,
+					ErrorMsg:   ddspan.Meta["error.msg"],
+					ErrorType:  ddspan.Meta["error.type"],
+					StatusCode: ddspan.Metrics["http.status_code"],
+					PeerTags:   peerTags,
+				})
+			}
+			got, err := json.MarshalIndent(out, "", "  ")
+			require.NoError(t, err)
+
+			golden := filepath.Join("testdata", "semconv", version+".json")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, append(got, '\n'), 0644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(want), string(got))
+		})
+	}
+}
+
+func TestSetEventsAndLinks(t *testing.T) {
+	newSpan := func() ptrace.Span {
+		span := ptrace.NewSpan()
+		event := span.Events().AppendEmpty()
+		event.SetName("exception")
+		event.SetTimestamp(1727401040000000000)
+		event.Attributes().PutStr("exception.message", "boom")
+		event.Attributes().PutInt("retries", 3)
+		event.Attributes().PutBool("escaped", true)
+		event.Attributes().PutEmptySlice("codes").FromRaw([]any{1.5, "x"})
+		link := span.Links().AppendEmpty()
+		link.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+		link.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8})
+		link.TraceState().FromRaw("dd=s:1")
+		link.SetFlags(1)
+		link.Attributes().PutStr("link.kind", "follows_from")
+		return span
+	}
+
+	t.Run("tags", func(t *testing.T) {
+		ddspan := &pb.Span{Meta: map[string]string{}}
+		SetEventsAndLinks(newSpan(), ddspan, config.New())
+		assert.Empty(t, ddspan.SpanEvents)
+		assert.Empty(t, ddspan.SpanLinks)
+		assert.Contains(t, ddspan.Meta["events"], `"name":"exception"`)
+		assert.Contains(t, ddspan.Meta["_dd.span_links"], `"span_id":"0102030405060708"`)
+		assert.Equal(t, "true", ddspan.Meta["_dd.span_events.has_exception"])
+	})
+
+	t.Run("native", func(t *testing.T) {
+		conf := config.New()
+		conf.Features["enable_otlp_native_span_events"] = struct{}{}
+		conf.Features["enable_otlp_native_span_links"] = struct{}{}
+		ddspan := &pb.Span{Meta: map[string]string{}}
+		SetEventsAndLinks(newSpan(), ddspan, conf)
+		assert.NotContains(t, ddspan.Meta, "events")
+		assert.NotContains(t, ddspan.Meta, "_dd.span_links")
+		assert.Equal(t, "true", ddspan.Meta["_dd.span_events.has_exception"])
+
+		require.Len(t, ddspan.SpanEvents, 1)
+		event := ddspan.SpanEvents[0]
+		assert.Equal(t, "exception", event.Name)
+		assert.Equal(t, uint64(1727401040000000000), event.TimeUnixNano)
+		assert.Equal(t, &pb.AttributeAnyValue{Type: pb.AttributeAnyValue_STRING_VALUE, StringValue: "boom"}, event.Attributes["exception.message"])
+		assert.Equal(t, &pb.AttributeAnyValue{Type: pb.AttributeAnyValue_INT_VALUE, IntValue: 3}, event.Attributes["retries"])
+		assert.Equal(t, &pb.AttributeAnyValue{Type: pb.AttributeAnyValue_BOOL_VALUE, BoolValue: true}, event.Attributes["escaped"])
+		assert.Equal(t, &pb.AttributeAnyValue{
+			Type: pb.AttributeAnyValue_ARRAY_VALUE,
+			ArrayValue: &pb.AttributeArray{Values: []*pb.AttributeArrayValue{
+				{Type: pb.AttributeArrayValue_DOUBLE_VALUE, DoubleValue: 1.5},
+				{Type: pb.AttributeArrayValue_STRING_VALUE, StringValue: "x"},
+			}},
+		}, event.Attributes["codes"])
+
+		require.Len(t, ddspan.SpanLinks, 1)
+		link := ddspan.SpanLinks[0]
+		assert.Equal(t, uint64(0x090a0b0c0d0e0f10), link.TraceID)
+		assert.Equal(t, uint64(0x0102030405060708), link.TraceIDHigh)
+		assert.Equal(t, uint64(0x0102030405060708), link.SpanID)
+		assert.Equal(t, "dd=s:1", link.Tracestate)
+		assert.Equal(t, uint32(1|1<<31), link.Flags)
+		assert.Equal(t, map[string]string{"link.kind": "follows_from"}, link.Attributes)
+	})
+}
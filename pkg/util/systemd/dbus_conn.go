@@ -14,20 +14,46 @@
 package systemd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strconv"
 
 	"github.com/coreos/go-systemd/v22/dbus"
 	godbus "github.com/godbus/dbus/v5"
+
+	"github.com/DataDog/datadog-agent/pkg/config/env"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
-// NewSystemdConnection establishes a private, direct connection to systemd.
+// DefaultPrivateSocket is the path of the private socket of systemd
+const DefaultPrivateSocket = "/run/systemd/private"
+
+// NewConnection connects to systemd using privateSocket when it is set.
+// Otherwise it uses the private socket of the host when the agent is
+// containerized, and the system bus with a fallback on the private socket
+// when it is not.
+func NewConnection(privateSocket string) (*dbus.Conn, error) {
+	if privateSocket != "" {
+		return NewPrivateConnection(privateSocket)
+	}
+	if env.IsContainerized() {
+		return NewPrivateConnection("/host" + DefaultPrivateSocket)
+	}
+	conn, err := dbus.NewSystemConnectionContext(context.Background())
+	if err != nil {
+		log.Debugf("Error getting new connection using system bus socket: %v", err)
+		return NewPrivateConnection(DefaultPrivateSocket)
+	}
+	return conn, nil
+}
+
+// NewPrivateConnection establishes a private, direct connection to systemd.
 // This can be used for communicating with systemd without a dbus daemon.
 // Callers should call Close() when done with the connection.
 // Note: method borrowed from `go-systemd/dbus` to provide custom path for systemd private socket
 // Source: https://github.com/coreos/go-systemd/blob/master/dbus/dbus.go
-func NewSystemdConnection(privateSocket string) (*dbus.Conn, error) {
+func NewPrivateConnection(privateSocket string) (*dbus.Conn, error) {
 	return dbus.NewConnection(func() (*godbus.Conn, error) {
 		// We skip Hello when talking directly to systemd.
 		return dbusAuthConnection(func() (*godbus.Conn, error) {
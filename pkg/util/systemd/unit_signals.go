@@ -0,0 +1,166 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+
+package systemd
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+
+	godbus "github.com/godbus/dbus/v5"
+
+	"github.com/DataDog/datadog-agent/pkg/config/env"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	unitPathPrefix = "/org/freedesktop/systemd1/unit/"
+
+	signalUnitNew           = "org.freedesktop.systemd1.Manager.UnitNew"
+	signalUnitRemoved       = "org.freedesktop.systemd1.Manager.UnitRemoved"
+	signalPropertiesChanged = "org.freedesktop.DBus.Properties.PropertiesChanged"
+)
+
+// UnitSignals records the units that systemd reports as created, removed or
+// changed, like when their state or their main process changes, through the
+// UnitNew, UnitRemoved and PropertiesChanged D-Bus signals.
+type UnitSignals struct {
+	conn *godbus.Conn
+
+	mu      sync.Mutex
+	changed map[string]struct{}
+	closed  bool
+}
+
+// SubscribeToUnitSignals connects to systemd like NewConnection and subscribes
+// to the signals about its units. Callers should call Close() when done.
+func SubscribeToUnitSignals(privateSocket string) (*UnitSignals, error) {
+	conn, err := newSignalConnection(privateSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	// the match rules are only used by the system bus, systemd sends its
+	// signals to all the subscribed clients of its private socket
+	matches := [][]godbus.MatchOption{
+		{godbus.WithMatchInterface("org.freedesktop.systemd1.Manager"), godbus.WithMatchMember("UnitNew")},
+		{godbus.WithMatchInterface("org.freedesktop.systemd1.Manager"), godbus.WithMatchMember("UnitRemoved")},
+		{godbus.WithMatchInterface("org.freedesktop.DBus.Properties"), godbus.WithMatchMember("PropertiesChanged"), godbus.WithMatchPathNamespace("/org/freedesktop/systemd1/unit")},
+	}
+	for _, match := range matches {
+		if err := conn.AddMatchSignal(match...); err != nil {
+			log.Debugf("Unable to add a match rule for the systemd signals: %v", err)
+		}
+	}
+
+	s := &UnitSignals{
+		conn:    conn,
+		changed: make(map[string]struct{}),
+	}
+	signals := make(chan *godbus.Signal, 256)
+	conn.Signal(signals)
+	go s.receive(signals)
+
+	if err := conn.Object("org.freedesktop.systemd1", "/org/freedesktop/systemd1").Call("org.freedesktop.systemd1.Manager.Subscribe", 0).Store(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// ChangedUnits returns the names of the units created, removed or changed
+// since the previous call. It returns an error once the connection is lost, as
+// the signals sent after it are missed.
+func (s *UnitSignals) ChangedUnits() (map[string]struct{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	changed := s.changed
+	s.changed = make(map[string]struct{})
+	if s.closed {
+		return changed, errors.New("the connection to systemd was closed")
+	}
+	return changed, nil
+}
+
+// Close closes the connection, which ends the subscription
+func (s *UnitSignals) Close() {
+	s.conn.Close()
+}
+
+func (s *UnitSignals) receive(signals <-chan *godbus.Signal) {
+	// the channel is closed with the connection
+	for signal := range signals {
+		if unit, ok := signalUnit(signal); ok {
+			s.mu.Lock()
+			s.changed[unit] = struct{}{}
+			s.mu.Unlock()
+		}
+	}
+
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+}
+
+// signalUnit returns the name of the unit a signal is about
+func signalUnit(signal *godbus.Signal) (string, bool) {
+	switch signal.Name {
+	case signalUnitNew, signalUnitRemoved:
+		if len(signal.Body) > 0 {
+			unit, ok := signal.Body[0].(string)
+			return unit, ok
+		}
+	case signalPropertiesChanged:
+		if unit, found := strings.CutPrefix(string(signal.Path), unitPathPrefix); found {
+			return pathBusUnescape(unit), true
+		}
+	}
+	return "", false
+}
+
+// pathBusUnescape returns the name of a unit from the last element of its
+// object path, where systemd escapes the special characters as "_xx", like
+// "nginx_2eservice" for "nginx.service"
+func pathBusUnescape(path string) string {
+	if path == "_" {
+		return ""
+	}
+	var unescaped strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '_' && i+2 < len(path) {
+			if decoded, err := hex.DecodeString(path[i+1 : i+3]); err == nil {
+				unescaped.Write(decoded)
+				i += 2
+				continue
+			}
+		}
+		unescaped.WriteByte(path[i])
+	}
+	return unescaped.String()
+}
+
+// newSignalConnection connects to the bus systemd sends its signals on, with
+// the same fallbacks as NewConnection
+func newSignalConnection(privateSocket string) (*godbus.Conn, error) {
+	if privateSocket == "" && env.IsContainerized() {
+		privateSocket = "/host" + DefaultPrivateSocket
+	}
+	if privateSocket == "" {
+		conn, err := godbus.ConnectSystemBus()
+		if err == nil {
+			return conn, nil
+		}
+		log.Debugf("Error getting new connection using system bus socket: %v", err)
+		privateSocket = DefaultPrivateSocket
+	}
+	return dbusAuthConnection(func() (*godbus.Conn, error) {
+		return godbus.Dial("unix:path=" + privateSocket)
+	})
+}
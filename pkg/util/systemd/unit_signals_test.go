@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-present Datadog, Inc.
+
+//go:build systemd
+
+package systemd
+
+import (
+	"testing"
+
+	godbus "github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignalUnit(t *testing.T) {
+	tests := []struct {
+		name         string
+		signal       *godbus.Signal
+		expectedUnit string
+		expectedOK   bool
+	}{
+		{
+			name:         "unit created",
+			signal:       &godbus.Signal{Name: signalUnitNew, Body: []interface{}{"nginx.service", godbus.ObjectPath("/org/freedesktop/systemd1/unit/nginx_2eservice")}},
+			expectedUnit: "nginx.service",
+			expectedOK:   true,
+		},
+		{
+			name:         "unit removed",
+			signal:       &godbus.Signal{Name: signalUnitRemoved, Body: []interface{}{"redis.service", godbus.ObjectPath("/org/freedesktop/systemd1/unit/redis_2eservice")}},
+			expectedUnit: "redis.service",
+			expectedOK:   true,
+		},
+		{
+			name:         "properties changed",
+			signal:       &godbus.Signal{Name: signalPropertiesChanged, Path: "/org/freedesktop/systemd1/unit/systemd_2djournald_2eservice"},
+			expectedUnit: "systemd-journald.service",
+			expectedOK:   true,
+		},
+		{
+			name:   "properties of another object changed",
+			signal: &godbus.Signal{Name: signalPropertiesChanged, Path: "/org/freedesktop/systemd1/job/42"},
+		},
+		{
+			name:   "other signal",
+			signal: &godbus.Signal{Name: "org.freedesktop.systemd1.Manager.JobRemoved", Body: []interface{}{uint32(42)}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			unit, ok := signalUnit(test.signal)
+			assert.Equal(t, test.expectedUnit, unit)
+			assert.Equal(t, test.expectedOK, ok)
+		})
+	}
+}